@@ -153,7 +153,8 @@ func init() {
 	maxSupportedFormat[SnapDeclarationType.Name] = 4
 
 	// 1: support to limit to device serials
-	maxSupportedFormat[SystemUserType.Name] = 1
+	// 2: support for recovery-only users
+	maxSupportedFormat[SystemUserType.Name] = 2
 }
 
 func MockMaxSupportedFormat(assertType *AssertionType, maxFormat int) (restore func()) {
@@ -285,6 +285,21 @@ func RSAPrivateKey(privk *rsa.PrivateKey) PrivateKey {
 	return openpgpPrivateKey{intPrivk}
 }
 
+// CryptoSigner returns a crypto.Signer backed by the given private key,
+// for use outside of assertions, e.g. for TLS client authentication. It
+// is only supported for keys held in memory.
+func CryptoSigner(privKey PrivateKey) (crypto.Signer, error) {
+	opgPrivK, ok := privKey.(openpgpPrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("cannot use %T as a crypto signer", privKey)
+	}
+	signer, ok := opgPrivK.privk.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("cannot use %T as a crypto signer", opgPrivK.privk.PrivateKey)
+	}
+	return signer, nil
+}
+
 // GenerateKey generates a private/public key pair.
 func GenerateKey() (PrivateKey, error) {
 	priv, err := rsa.GenerateKey(rand.Reader, 4096)
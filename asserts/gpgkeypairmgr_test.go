@@ -124,6 +124,18 @@ func (gkms *gpgKeypairMgrSuite) TestUseInSigning(c *C) {
 	c.Check(err, IsNil)
 }
 
+func (gkms *gpgKeypairMgrSuite) TestCryptoSigner(c *C) {
+	signer, err := asserts.CryptoSigner(testPrivKey1)
+	c.Assert(err, IsNil)
+	c.Check(signer.Public(), DeepEquals, &testPrivKey1RSA.PublicKey)
+
+	// keys held externally by gpg cannot be used
+	privKey, err := gkms.keypairMgr.Get(assertstest.DevKeyID)
+	c.Assert(err, IsNil)
+	_, err = asserts.CryptoSigner(privKey)
+	c.Check(err, ErrorMatches, `cannot use \*asserts.extPGPPrivateKey as a crypto signer`)
+}
+
 func (gkms *gpgKeypairMgrSuite) TestGetNotUnique(c *C) {
 	mockGPG := func(prev asserts.GPGRunner, input []byte, args ...string) ([]byte, error) {
 		if args[1] == "--list-secret-keys" {
@@ -395,6 +395,8 @@ type Model struct {
 
 	storageSafety StorageSafety
 
+	recoveryRemoteAccess bool
+
 	allSnaps []*ModelSnap
 	// consumers of this info should care only about snap identity =>
 	// snapRef
@@ -453,6 +455,13 @@ func (mod *Model) StorageSafety() StorageSafety {
 	return mod.storageSafety
 }
 
+// RecoveryRemoteAccess returns whether SSH can be started in recover mode
+// for the users created from system-user assertions. Always false for Core
+// 16/18 models.
+func (mod *Model) RecoveryRemoteAccess() bool {
+	return mod.recoveryRemoteAccess
+}
+
 // GadgetSnap returns the details of the gadget snap the model uses.
 func (mod *Model) GadgetSnap() *ModelSnap {
 	return mod.gadgetSnap
@@ -673,6 +682,9 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 		if _, ok := assert.headers["storage-safety"]; ok {
 			return nil, fmt.Errorf("cannot specify storage-safety for model without the extended snaps header")
 		}
+		if _, ok := assert.headers["recovery-remote-access"]; ok {
+			return nil, fmt.Errorf("cannot specify recovery-remote-access for model without the extended snaps header")
+		}
 	}
 
 	if classic {
@@ -725,6 +737,7 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 	var modSnaps *modelSnaps
 	grade := ModelGradeUnset
 	storageSafety := StorageSafetyUnset
+	recoveryRemoteAccess := false
 	if extended {
 		gradeStr, err := checkOptionalString(assert.headers, "grade")
 		if err != nil {
@@ -759,6 +772,11 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 			return nil, fmt.Errorf(`secured grade model must not have storage-safety overridden, only "encrypted" is valid`)
 		}
 
+		recoveryRemoteAccess, err = checkOptionalBool(assert.headers, "recovery-remote-access")
+		if err != nil {
+			return nil, err
+		}
+
 		modSnaps, err = checkExtendedSnaps(extendedSnaps, base, grade)
 		if err != nil {
 			return nil, err
@@ -847,6 +865,7 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 		kernelSnap:                 modSnaps.kernel,
 		grade:                      grade,
 		storageSafety:              storageSafety,
+		recoveryRemoteAccess:       recoveryRemoteAccess,
 		allSnaps:                   allSnaps,
 		requiredWithEssentialSnaps: requiredWithEssentialSnaps,
 		numEssentialSnaps:          numEssentialSnaps,
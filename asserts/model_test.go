@@ -481,6 +481,7 @@ func (mods *modelSuite) TestDecodeInvalid(c *C) {
 		{sysUserAuths, "system-user-authority:\n  a: 1\n", `"system-user-authority" header must be '\*' or a list of account ids`},
 		{sysUserAuths, "system-user-authority:\n  - 5_6\n", `"system-user-authority" header must be '\*' or a list of account ids`},
 		{reqSnaps, "grade: dangerous\n", `cannot specify a grade for model without the extended snaps header`},
+		{reqSnaps, "recovery-remote-access: true\n", `cannot specify recovery-remote-access for model without the extended snaps header`},
 	}
 
 	for _, test := range invalidTests {
@@ -892,6 +893,25 @@ func (mods *modelSuite) TestCore20DefaultStorageSafetySignedDangerous(c *C) {
 	}
 }
 
+func (mods *modelSuite) TestCore20RecoveryRemoteAccess(c *C) {
+	encoded := strings.Replace(core20ModelExample, "TSLINE", mods.tsLine, 1)
+
+	for _, tc := range []struct {
+		other string
+		exp   bool
+	}{
+		{"", false},
+		{"recovery-remote-access: false\n", false},
+		{"recovery-remote-access: true\n", true},
+	} {
+		ex := strings.Replace(encoded, "OTHER", tc.other, 1)
+		a, err := asserts.Decode([]byte(ex))
+		c.Assert(err, IsNil)
+		model := a.(*asserts.Model)
+		c.Check(model.RecoveryRemoteAccess(), Equals, tc.exp)
+	}
+}
+
 func (mods *modelSuite) TestCore20DecodeInvalid(c *C) {
 	encoded := strings.Replace(core20ModelExample, "TSLINE", mods.tsLine, 1)
 
@@ -932,6 +952,7 @@ func (mods *modelSuite) TestCore20DecodeInvalid(c *C) {
 		{"grade: secured\n", "grade: foo\n", `grade for model must be secured|signed|dangerous`},
 		{"storage-safety: encrypted\n", "storage-safety: foo\n", `storage-safety for model must be encrypted\|prefer-encrypted\|prefer-unencrypted, not "foo"`},
 		{"storage-safety: encrypted\n", "storage-safety: prefer-unencrypted\n", `secured grade model must not have storage-safety overridden, only "encrypted" is valid`},
+		{"OTHER", "recovery-remote-access: maybe\n", `"recovery-remote-access" header must be 'true' or 'false'`},
 	}
 	for _, test := range invalidTests {
 		invalid := strings.Replace(encoded, test.original, test.invalid, 1)
@@ -42,6 +42,7 @@ type SystemUser struct {
 	until   time.Time
 
 	forcePasswordChange bool
+	recoveryOnly        bool
 }
 
 // BrandID returns the brand identifier that signed this assertion.
@@ -91,6 +92,12 @@ func (su *SystemUser) ForcePasswordChange() bool {
 	return su.forcePasswordChange
 }
 
+// RecoveryOnly returns true if the user can only be created when the
+// system is in recover mode.
+func (su *SystemUser) RecoveryOnly() bool {
+	return su.recoveryOnly
+}
+
 // SSHKeys returns the ssh keys for the user.
 func (su *SystemUser) SSHKeys() []string {
 	return su.sshKeys
@@ -265,6 +272,14 @@ func assembleSystemUser(assert assertionBase) (Assertion, error) {
 		return nil, fmt.Errorf(`cannot use "force-password-change" with an empty "password"`)
 	}
 
+	recoveryOnly, err := checkOptionalBool(assert.headers, "recovery-only")
+	if err != nil {
+		return nil, err
+	}
+	if recoveryOnly && assert.Format() < 2 {
+		return nil, fmt.Errorf(`the "recovery-only" header is only supported for format 2 or greater`)
+	}
+
 	sshKeys, err := checkStringList(assert.headers, "ssh-keys")
 	if err != nil {
 		return nil, err
@@ -295,6 +310,7 @@ func assembleSystemUser(assert assertionBase) (Assertion, error) {
 		since:               since,
 		until:               until,
 		forcePasswordChange: forcePasswordChange,
+		recoveryOnly:        recoveryOnly,
 	}, nil
 }
 
@@ -309,5 +325,13 @@ func systemUserFormatAnalyze(headers map[string]interface{}, body []byte) (forma
 		formatnum = 1
 	}
 
+	recoveryOnly, err := checkOptionalBool(headers, "recovery-only")
+	if err != nil {
+		return 0, err
+	}
+	if recoveryOnly {
+		formatnum = 2
+	}
+
 	return formatnum, nil
 }
@@ -254,6 +254,37 @@ func (s *systemUserSuite) TestDecodeOKFormat1Serials(c *C) {
 
 }
 
+func (s *systemUserSuite) TestDecodeInvalidFormat2RecoveryOnly(c *C) {
+	for _, format := range []string{"format: 0\n", "format: 1\n"} {
+		sysUserStr := strings.Replace(s.systemUserStr, s.formatLine, format, 1)
+		invalid := strings.Replace(sysUserStr, s.modelsLine, s.modelsLine+"recovery-only: true\n", 1)
+		_, err := asserts.Decode([]byte(invalid))
+		c.Check(err, ErrorMatches, systemUserErrPrefix+`the "recovery-only" header is only supported for format 2 or greater`)
+	}
+
+	s.systemUserStr = strings.Replace(s.systemUserStr, s.formatLine, "format: 2\n", 1)
+	invalid := strings.Replace(s.systemUserStr, s.modelsLine, s.modelsLine+"recovery-only: xxx\n", 1)
+	_, err := asserts.Decode([]byte(invalid))
+	c.Check(err, ErrorMatches, systemUserErrPrefix+`"recovery-only" header must be 'true' or 'false'`)
+}
+
+func (s *systemUserSuite) TestDecodeOKFormat2RecoveryOnly(c *C) {
+	s.systemUserStr = strings.Replace(s.systemUserStr, s.formatLine, "format: 2\n", 1)
+
+	a, err := asserts.Decode([]byte(s.systemUserStr))
+	c.Assert(err, IsNil)
+	c.Check(a.(*asserts.SystemUser).RecoveryOnly(), Equals, false)
+
+	s.systemUserStr = strings.Replace(s.systemUserStr, s.modelsLine, s.modelsLine+"recovery-only: true\n", 1)
+	a, err = asserts.Decode([]byte(s.systemUserStr))
+	c.Assert(err, IsNil)
+	c.Check(a.Type(), Equals, asserts.SystemUserType)
+	systemUser := a.(*asserts.SystemUser)
+	c.Check(systemUser.BrandID(), Equals, "canonical")
+	// new in "format: 2"
+	c.Check(systemUser.RecoveryOnly(), Equals, true)
+}
+
 func (s *systemUserSuite) TestSuggestedFormat(c *C) {
 	fmtnum, err := asserts.SuggestFormat(asserts.SystemUserType, nil, nil)
 	c.Assert(err, IsNil)
@@ -266,4 +297,11 @@ func (s *systemUserSuite) TestSuggestedFormat(c *C) {
 	c.Assert(err, IsNil)
 	c.Check(fmtnum, Equals, 1)
 
+	headers = map[string]interface{}{
+		"recovery-only": "true",
+	}
+	fmtnum, err = asserts.SuggestFormat(asserts.SystemUserType, headers, nil)
+	c.Assert(err, IsNil)
+	c.Check(fmtnum, Equals, 2)
+
 }
@@ -2648,13 +2648,16 @@ func (s *assetsSuite) TestUpdateObserverCanceledReseal(c *C) {
 		}
 		switch resealCalls {
 		case 1:
+			// the recovery chain carries both the recovery and
+			// the factory-reset command lines, thus it is ordered
+			// after the run mode one
 			c.Check(mp.EFILoadChains, DeepEquals, []*secboot.LoadChain{
 				secboot.NewLoadChain(shimBf,
 					secboot.NewLoadChain(assetBf,
-						secboot.NewLoadChain(recoveryKernelBf))),
+						secboot.NewLoadChain(runKernelBf))),
 				secboot.NewLoadChain(shimBf,
 					secboot.NewLoadChain(assetBf,
-						secboot.NewLoadChain(runKernelBf))),
+						secboot.NewLoadChain(recoveryKernelBf))),
 			})
 		case 2:
 			c.Check(mp.EFILoadChains, DeepEquals, []*secboot.LoadChain{
@@ -25,6 +25,7 @@ import (
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/bootloader"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/snap"
 )
 
@@ -304,6 +305,26 @@ type bootStateUpdate interface {
 	commit() error
 }
 
+// IsTryingBoot returns true if a new kernel or base is being tried in the
+// current boot. Unless the boot is marked as successful, the previous kernel
+// and base are used on the next boot.
+func IsTryingBoot(dev Device) (bool, error) {
+	for _, t := range []snap.Type{snap.TypeBase, snap.TypeKernel} {
+		s, err := bootStateFor(t, dev)
+		if err != nil {
+			return false, err
+		}
+		_, _, status, err := s.revisions()
+		if err != nil {
+			return false, err
+		}
+		if status == TryingStatus {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // MarkBootSuccessful marks the current boot as successful. This means
 // that snappy will consider this combination of kernel/os a valid
 // target for rollback.
@@ -357,6 +378,11 @@ func MarkBootSuccessful(dev Device) error {
 			return fmt.Errorf(errPrefix, err)
 		}
 	}
+
+	if err := markBootHistorySuccessful(); err != nil {
+		// the boot history is informational only
+		logger.Noticef("cannot record successful boot in boot history: %v", err)
+	}
 	return nil
 }
 
@@ -407,6 +407,48 @@ func (s *bootenvSuite) TestCurrentBootNameAndRevision(c *C) {
 	c.Check(err, Equals, boot.ErrBootNameAndRevisionNotReady)
 }
 
+func (s *bootenvSuite) TestIsTryingBoot(c *C) {
+	coreDev := boottest.MockDevice("some-snap")
+
+	s.bootloader.BootVars["snap_core"] = "core_2.snap"
+	s.bootloader.BootVars["snap_kernel"] = "canonical-pc-linux_2.snap"
+
+	trying, err := boot.IsTryingBoot(coreDev)
+	c.Assert(err, IsNil)
+	c.Check(trying, Equals, false)
+
+	s.bootloader.BootVars["snap_mode"] = boot.TryingStatus
+	s.bootloader.BootVars["snap_try_core"] = "core_3.snap"
+	trying, err = boot.IsTryingBoot(coreDev)
+	c.Assert(err, IsNil)
+	c.Check(trying, Equals, true)
+
+	s.bootloader.GetErr = errors.New("zap")
+	_, err = boot.IsTryingBoot(coreDev)
+	c.Check(err, ErrorMatches, `cannot get boot variables: zap`)
+}
+
+func (s *bootenv20Suite) TestIsTryingBoot20(c *C) {
+	coreDev := boottest.MockUC20Device("", nil)
+	c.Assert(coreDev.HasModeenv(), Equals, true)
+
+	r := setupUC20Bootenv(
+		c,
+		s.bootloader,
+		s.normalDefaultState,
+	)
+	defer r()
+
+	trying, err := boot.IsTryingBoot(coreDev)
+	c.Assert(err, IsNil)
+	c.Check(trying, Equals, false)
+
+	s.bootloader.BootVars["kernel_status"] = boot.TryingStatus
+	trying, err = boot.IsTryingBoot(coreDev)
+	c.Assert(err, IsNil)
+	c.Check(trying, Equals, true)
+}
+
 func (s *bootenv20Suite) TestCurrentBoot20NameAndRevision(c *C) {
 	coreDev := boottest.MockUC20Device("", nil)
 	c.Assert(coreDev.HasModeenv(), Equals, true)
@@ -2310,7 +2352,7 @@ func (s *bootenv20Suite) TestMarkBootSuccessful20BootAssetsStableStateHappy(c *C
 		}},
 		Kernel:         "pc-kernel-recovery",
 		KernelRevision: "1",
-		KernelCmdlines: []string{"snapd_recovery_mode=recover snapd_recovery_system=system"},
+		KernelCmdlines: []string{"snapd_recovery_mode=factory-reset snapd_recovery_system=system", "snapd_recovery_mode=recover snapd_recovery_system=system"},
 	}}
 
 	recoveryBootChains := []boot.BootChain{bootChains[1]}
@@ -2474,7 +2516,7 @@ func (s *bootenv20Suite) TestMarkBootSuccessful20BootUnassertedKernelAssetsStabl
 		}},
 		Kernel:         "pc-kernel-recovery",
 		KernelRevision: "1",
-		KernelCmdlines: []string{"snapd_recovery_mode=recover snapd_recovery_system=system"},
+		KernelCmdlines: []string{"snapd_recovery_mode=factory-reset snapd_recovery_system=system", "snapd_recovery_mode=recover snapd_recovery_system=system"},
 	}}
 
 	recoveryBootChains := []boot.BootChain{bootChains[1]}
@@ -2735,6 +2777,94 @@ func (s *bootenv20Suite) TestMarkBootSuccessful20CommandLineUpdatedFallbackOnBoo
 	c.Assert(err, ErrorMatches, `cannot mark boot successful: cannot mark successful boot command line: unexpected current command line: "snapd_recovery_mode=run panic=-1 unexpected"`)
 }
 
+func (s *bootenv20Suite) TestMarkBootSuccessful20CommandLineAppendTried(c *C) {
+	s.mockCmdline(c, "snapd_recovery_mode=run panic=-1 isolcpus=1")
+	tab := s.bootloaderWithTrustedAssets(c, []string{"asset"})
+	m := s.setupMarkBootSuccessful20CommandLine(c, "run", boot.BootCommandLines{
+		"snapd_recovery_mode=run panic=-1",
+		"snapd_recovery_mode=run panic=-1 isolcpus=1",
+	})
+	r := setupUC20Bootenv(
+		c,
+		tab.MockBootloader,
+		&bootenv20Setup{
+			modeenv:    m,
+			kern:       s.kern1,
+			kernStatus: boot.DefaultStatus,
+		},
+	)
+	defer r()
+	// the bootloader booted with the new arguments
+	c.Assert(tab.SetBootVars(map[string]string{
+		"snapd_try_extra_cmdline_args": "isolcpus=1",
+		"cmdline_status":               boot.TryingStatus,
+	}), IsNil)
+
+	coreDev := boottest.MockUC20Device("", nil)
+	c.Assert(coreDev.HasModeenv(), Equals, true)
+	// mark successful
+	err := boot.MarkBootSuccessful(coreDev)
+	c.Assert(err, IsNil)
+
+	// the new arguments are now the known good ones
+	vars, err := tab.GetBootVars("snapd_extra_cmdline_args", "snapd_try_extra_cmdline_args", "cmdline_status")
+	c.Assert(err, IsNil)
+	c.Check(vars, DeepEquals, map[string]string{
+		"snapd_extra_cmdline_args":     "isolcpus=1",
+		"snapd_try_extra_cmdline_args": "",
+		"cmdline_status":               boot.DefaultStatus,
+	})
+	m2, err := boot.ReadModeenv("")
+	c.Assert(err, IsNil)
+	c.Check(m2.CurrentKernelCommandLines, DeepEquals, boot.BootCommandLines{
+		"snapd_recovery_mode=run panic=-1 isolcpus=1",
+	})
+}
+
+func (s *bootenv20Suite) TestMarkBootSuccessful20CommandLineAppendRolledBack(c *C) {
+	s.mockCmdline(c, "snapd_recovery_mode=run panic=-1")
+	tab := s.bootloaderWithTrustedAssets(c, []string{"asset"})
+	m := s.setupMarkBootSuccessful20CommandLine(c, "run", boot.BootCommandLines{
+		"snapd_recovery_mode=run panic=-1",
+		"snapd_recovery_mode=run panic=-1 isolcpus=1",
+	})
+	r := setupUC20Bootenv(
+		c,
+		tab.MockBootloader,
+		&bootenv20Setup{
+			modeenv:    m,
+			kern:       s.kern1,
+			kernStatus: boot.DefaultStatus,
+		},
+	)
+	defer r()
+	// booting with the new arguments failed, the bootloader reset the
+	// status and booted with the old ones
+	c.Assert(tab.SetBootVars(map[string]string{
+		"snapd_try_extra_cmdline_args": "isolcpus=1",
+		"cmdline_status":               boot.DefaultStatus,
+	}), IsNil)
+
+	coreDev := boottest.MockUC20Device("", nil)
+	c.Assert(coreDev.HasModeenv(), Equals, true)
+	// mark successful
+	err := boot.MarkBootSuccessful(coreDev)
+	c.Assert(err, IsNil)
+
+	vars, err := tab.GetBootVars("snapd_extra_cmdline_args", "snapd_try_extra_cmdline_args", "cmdline_status")
+	c.Assert(err, IsNil)
+	c.Check(vars, DeepEquals, map[string]string{
+		"snapd_extra_cmdline_args":     "",
+		"snapd_try_extra_cmdline_args": "",
+		"cmdline_status":               boot.DefaultStatus,
+	})
+	m2, err := boot.ReadModeenv("")
+	c.Assert(err, IsNil)
+	c.Check(m2.CurrentKernelCommandLines, DeepEquals, boot.BootCommandLines{
+		"snapd_recovery_mode=run panic=-1",
+	})
+}
+
 func (s *bootenv20Suite) TestMarkBootSuccessful20CommandLineNonRunMode(c *C) {
 	// recover mode
 	s.mockCmdline(c, "snapd_recovery_mode=recover snapd_recovery_system=1234 panic=-1")
@@ -732,13 +732,18 @@ func (ba20 *bootState20CommandLine) markSuccessful(update bootStateUpdate) (boot
 	if err != nil {
 		return nil, err
 	}
-	if len(u20.modeenv.CurrentTrustedBootAssets) == 0 && len(u20.modeenv.CurrentTrustedRecoveryBootAssets) == 0 {
-		// XXX: does this change when we expose the ability to add
-		// things to the command line?
+	if u20.modeenv.Mode == ModeRun {
+		if err := ba20.markSuccessfulCommandLineAppend(u20); err != nil {
+			return nil, fmt.Errorf("cannot mark successful boot command line: %v", err)
+		}
+	}
 
+	if len(u20.modeenv.CurrentTrustedBootAssets) == 0 && len(u20.modeenv.CurrentTrustedRecoveryBootAssets) == 0 &&
+		len(u20.modeenv.CurrentKernelCommandLines) < 2 {
 		// not using trusted boot assets, bootloader config is not
-		// managed and command line can be manipulated externally
-		return update, nil
+		// managed and command line can be manipulated externally,
+		// unless there is a pending change of the command line
+		return u20, nil
 	}
 
 	newM, err := observeSuccessfulCommandLine(ba20.dev.Model(), u20.writeModeenv)
@@ -749,6 +754,31 @@ func (ba20 *bootState20CommandLine) markSuccessful(update bootStateUpdate) (boot
 	return u20, nil
 }
 
+// markSuccessfulCommandLineAppend commits or drops the extra kernel command
+// line arguments that were tried during this boot, the bootloader environment
+// is updated before the modeenv is written so that the known good arguments
+// always match one of the command lines listed there.
+func (ba20 *bootState20CommandLine) markSuccessfulCommandLineAppend(u20 *bootStateUpdate20) error {
+	mbl, err := runModeBootloaderManagingItsAssets()
+	if err != nil {
+		if err == errBootConfigNotManaged {
+			return nil
+		}
+		return err
+	}
+	vars, err := observeSuccessfulCommandLineAppend(mbl)
+	if err != nil {
+		return err
+	}
+	if len(vars) == 0 {
+		return nil
+	}
+	u20.preModeenv(func() error {
+		return mbl.SetBootVars(vars)
+	})
+	return nil
+}
+
 func trustedCommandLineBootState(dev Device) *bootState20CommandLine {
 	return &bootState20CommandLine{
 		dev: dev,
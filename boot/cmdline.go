@@ -22,9 +22,11 @@ package boot
 import (
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/bootloader"
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/strutil"
@@ -39,10 +41,13 @@ const (
 	// ModeRecover is a mode in which the device boots into the recovery
 	// system.
 	ModeRecover = "recover"
+	// ModeFactoryReset is a mode in which the device re-creates the run
+	// system from a recovery system, keeping ubuntu-seed intact.
+	ModeFactoryReset = "factory-reset"
 )
 
 var (
-	validModes = []string{ModeInstall, ModeRecover, ModeRun}
+	validModes = []string{ModeInstall, ModeRecover, ModeFactoryReset, ModeRun}
 )
 
 // ModeAndRecoverySystemFromKernelCommandLine returns the current system mode
@@ -72,8 +77,8 @@ func ModeAndRecoverySystemFromKernelCommandLine() (mode, sysLabel string, err er
 		return "", "", fmt.Errorf("cannot detect mode nor recovery system to use")
 	case mode == "" && sysLabel != "":
 		return "", "", fmt.Errorf("cannot specify system label without a mode")
-	case mode == ModeInstall && sysLabel == "":
-		return "", "", fmt.Errorf("cannot specify install mode without system label")
+	case (mode == ModeInstall || mode == ModeFactoryReset) && sysLabel == "":
+		return "", "", fmt.Errorf("cannot specify %s mode without system label", mode)
 	case mode == ModeRun && sysLabel != "":
 		// XXX: should we silently ignore the label? at least log for now
 		logger.Noticef(`ignoring recovery system label %q in "run" mode`, sysLabel)
@@ -102,11 +107,46 @@ const (
 	candidateEdition
 )
 
+const (
+	// extraCmdlineArgsVar is the run mode bootloader environment variable
+	// carrying the known good extra kernel command line arguments
+	extraCmdlineArgsVar = "snapd_extra_cmdline_args"
+	// tryExtraCmdlineArgsVar is the run mode bootloader environment
+	// variable carrying the extra kernel command line arguments that are
+	// being tried
+	tryExtraCmdlineArgsVar = "snapd_try_extra_cmdline_args"
+	// cmdlineStatusVar is the run mode bootloader environment variable
+	// carrying the status of trying new extra kernel command line
+	// arguments, follows the same semantics as kernel_status
+	cmdlineStatusVar = "cmdline_status"
+)
+
+// extraCommandLineArgs returns the extra kernel command line arguments stored
+// in the environment of the given bootloader.
+func extraCommandLineArgs(bl bootloader.Bootloader) (string, error) {
+	vars, err := bl.GetBootVars(extraCmdlineArgsVar)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// no environment yet, eg. during install
+			return "", nil
+		}
+		return "", fmt.Errorf("cannot obtain extra command line arguments: %v", err)
+	}
+	return vars[extraCmdlineArgsVar], nil
+}
+
 func composeCommandLine(model *asserts.Model, currentOrCandidate int, mode, system string) (string, error) {
+	return composeCommandLineWithExtraArgs(model, currentOrCandidate, mode, system, nil)
+}
+
+// composeCommandLineWithExtraArgs composes the kernel command line. For run
+// mode, when extraArgs is nil the extra arguments are obtained from the
+// bootloader environment.
+func composeCommandLineWithExtraArgs(model *asserts.Model, currentOrCandidate int, mode, system string, extraArgs *string) (string, error) {
 	if model.Grade() == asserts.ModelGradeUnset {
 		return "", nil
 	}
-	if mode != ModeRun && mode != ModeRecover {
+	if mode != ModeRun && mode != ModeRecover && mode != ModeFactoryReset {
 		return "", fmt.Errorf("internal error: unsupported command line mode %q", mode)
 	}
 	// get the run mode bootloader under the native run partition layout
@@ -117,7 +157,7 @@ func composeCommandLine(model *asserts.Model, currentOrCandidate int, mode, syst
 	bootloaderRootDir := InitramfsUbuntuBootDir
 	modeArg := "snapd_recovery_mode=run"
 	systemArg := ""
-	if mode == ModeRecover {
+	if mode == ModeRecover || mode == ModeFactoryReset {
 		if system == "" {
 			return "", fmt.Errorf("internal error: system is unset")
 		}
@@ -125,7 +165,7 @@ func composeCommandLine(model *asserts.Model, currentOrCandidate int, mode, syst
 		opts.Role = bootloader.RoleRecovery
 		bootloaderRootDir = InitramfsUbuntuSeedDir
 		// recovery mode & system command line arguments
-		modeArg = "snapd_recovery_mode=recover"
+		modeArg = fmt.Sprintf("snapd_recovery_mode=%v", mode)
 		systemArg = fmt.Sprintf("snapd_recovery_system=%v", system)
 	}
 	mbl, err := getBootloaderManagingItsAssets(bootloaderRootDir, opts)
@@ -136,11 +176,21 @@ func composeCommandLine(model *asserts.Model, currentOrCandidate int, mode, syst
 		return "", err
 	}
 	// TODO:UC20: fetch extra args from gadget
-	extraArgs := ""
+	args := ""
+	if mode == ModeRun {
+		if extraArgs != nil {
+			args = *extraArgs
+		} else {
+			args, err = extraCommandLineArgs(mbl)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
 	if currentOrCandidate == currentEdition {
-		return mbl.CommandLine(modeArg, systemArg, extraArgs)
+		return mbl.CommandLine(modeArg, systemArg, args)
 	} else {
-		return mbl.CandidateCommandLine(modeArg, systemArg, extraArgs)
+		return mbl.CandidateCommandLine(modeArg, systemArg, args)
 	}
 }
 
@@ -150,6 +200,12 @@ func ComposeRecoveryCommandLine(model *asserts.Model, system string) (string, er
 	return composeCommandLine(model, currentEdition, ModeRecover, system)
 }
 
+// ComposeFactoryResetCommandLine composes the kernel command line used when
+// booting a given system in factory-reset mode.
+func ComposeFactoryResetCommandLine(model *asserts.Model, system string) (string, error) {
+	return composeCommandLine(model, currentEdition, ModeFactoryReset, system)
+}
+
 // ComposeCommandLine composes the kernel command line used when booting the
 // system in run mode.
 func ComposeCommandLine(model *asserts.Model) (string, error) {
@@ -170,6 +226,154 @@ func ComposeCandidateRecoveryCommandLine(model *asserts.Model, system string) (s
 	return composeCommandLine(model, candidateEdition, ModeRecover, system)
 }
 
+// runModeBootloaderManagingItsAssets returns the run mode bootloader, provided
+// that it manages its boot config.
+func runModeBootloaderManagingItsAssets() (bootloader.TrustedAssetsBootloader, error) {
+	return getBootloaderManagingItsAssets(InitramfsUbuntuBootDir, &bootloader.Options{
+		Role:        bootloader.RoleRunMode,
+		NoSlashBoot: true,
+	})
+}
+
+// CommandLineAppend returns the extra arguments currently appended to the run
+// mode kernel command line and whether a change of those arguments is pending
+// a reboot. Nothing is appended when the boot config is not managed by snapd.
+func CommandLineAppend(dev Device) (args string, pending bool, err error) {
+	if !dev.HasModeenv() || !dev.RunMode() {
+		return "", false, fmt.Errorf("cannot obtain kernel command line arguments outside of UC20+ run mode")
+	}
+	mbl, err := runModeBootloaderManagingItsAssets()
+	if err != nil {
+		if err == errBootConfigNotManaged {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	vars, err := mbl.GetBootVars(extraCmdlineArgsVar, cmdlineStatusVar)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return vars[extraCmdlineArgsVar], vars[cmdlineStatusVar] != DefaultStatus, nil
+}
+
+// SetTryCommandLineAppend sets up the run mode bootloader to try booting with
+// the given extra kernel command line arguments on the next boot. When the
+// kernel command lines are tracked in the modeenv, the new command line is
+// added to the list and the encryption keys are resealed, such that booting
+// with either the current or the new arguments is possible. The new arguments
+// become the known good ones once the boot is marked as successful, a failed
+// boot makes the bootloader fall back to the previous arguments. Returns true
+// when a reboot is required for the change to take effect.
+func SetTryCommandLineAppend(dev Device, args string) (rebootRequired bool, err error) {
+	if !dev.HasModeenv() || !dev.RunMode() {
+		return false, fmt.Errorf("cannot set kernel command line arguments outside of UC20+ run mode")
+	}
+	mbl, err := runModeBootloaderManagingItsAssets()
+	if err != nil {
+		if err == errBootConfigNotManaged {
+			return false, fmt.Errorf("cannot set kernel command line arguments: %v", err)
+		}
+		return false, err
+	}
+	vars, err := mbl.GetBootVars(extraCmdlineArgsVar, cmdlineStatusVar)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if vars[cmdlineStatusVar] != DefaultStatus {
+		return false, fmt.Errorf("cannot set kernel command line arguments while a previous change is pending a reboot")
+	}
+	if vars[extraCmdlineArgsVar] == args {
+		// nothing to do
+		return false, nil
+	}
+
+	modeenv, err := ReadModeenv("")
+	if err != nil {
+		return false, err
+	}
+	if len(modeenv.CurrentKernelCommandLines) > 1 {
+		return false, fmt.Errorf("cannot set kernel command line arguments while a boot config update is pending a reboot")
+	}
+	if len(modeenv.CurrentKernelCommandLines) > 0 {
+		cmdline, err := composeCommandLineWithExtraArgs(dev.Model(), currentEdition, ModeRun, "", &args)
+		if err != nil {
+			return false, fmt.Errorf("cannot compose the candidate command line: %v", err)
+		}
+		newModeenv, err := modeenv.Copy()
+		if err != nil {
+			return false, err
+		}
+		newModeenv.CurrentKernelCommandLines = append(newModeenv.CurrentKernelCommandLines, cmdline)
+
+		// reseal first, the keys being sealed to a superset of the
+		// command lines listed in the modeenv is harmless
+		const expectReseal = true
+		if err := resealKeyToModeenv(dirs.GlobalRootDir, dev.Model(), newModeenv, expectReseal); err != nil {
+			return false, fmt.Errorf("cannot reseal the encryption key: %v", err)
+		}
+		if err := newModeenv.Write(); err != nil {
+			return false, err
+		}
+	}
+
+	// finally make the bootloader try the new arguments on next boot
+	err = mbl.SetBootVars(map[string]string{
+		tryExtraCmdlineArgsVar: args,
+		cmdlineStatusVar:       TryStatus,
+	})
+	if err != nil {
+		return false, fmt.Errorf("cannot set kernel command line arguments: %v", err)
+	}
+	return true, nil
+}
+
+// observeSuccessfulCommandLineAppend observes a successful boot with regard to
+// the extra kernel command line arguments being tried, and returns the
+// bootloader environment variables that need to be updated as a result, or nil
+// if there is nothing to update.
+func observeSuccessfulCommandLineAppend(bl bootloader.Bootloader) (map[string]string, error) {
+	vars, err := bl.GetBootVars(cmdlineStatusVar, tryExtraCmdlineArgsVar)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	switch vars[cmdlineStatusVar] {
+	case TryingStatus:
+		// booted with the new arguments, those are now the known good
+		// ones
+		return map[string]string{
+			extraCmdlineArgsVar:    vars[tryExtraCmdlineArgsVar],
+			tryExtraCmdlineArgsVar: "",
+			cmdlineStatusVar:       DefaultStatus,
+		}, nil
+	case DefaultStatus:
+		if vars[tryExtraCmdlineArgsVar] == "" {
+			// nothing was tried
+			return nil, nil
+		}
+		// the bootloader reset the status, meaning that booting
+		// with the new arguments failed and we are now running with
+		// the previous ones
+		logger.Noticef("booting with kernel command line arguments %q failed, reverted to previous ones",
+			vars[tryExtraCmdlineArgsVar])
+	default:
+		// the bootloader did not act on the status, possibly an old
+		// boot config, in any case we booted with the previous
+		// arguments
+		logger.Noticef("unexpected %q status %q, reverted to previous kernel command line arguments",
+			cmdlineStatusVar, vars[cmdlineStatusVar])
+	}
+	return map[string]string{
+		tryExtraCmdlineArgsVar: "",
+		cmdlineStatusVar:       DefaultStatus,
+	}, nil
+}
+
 // observeSuccessfulCommandLine observes a successful boot with a command line
 // and takes an action based on the contents of the modeenv. The current kernel
 // command lines in the modeenv can have up to 2 entries when the managed
@@ -26,10 +26,12 @@ import (
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/boot/boottest"
 	"github.com/snapcore/snapd/bootloader"
 	"github.com/snapcore/snapd/bootloader/bootloadertest"
+	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -85,6 +87,13 @@ func (s *kernelCommandLineSuite) TestModeAndLabel(c *C) {
 		// no recovery system label
 		cmd: "snapd_recovery_mode=install foo=bar",
 		err: `cannot specify install mode without system label`,
+	}, {
+		cmd:   "snapd_recovery_mode=factory-reset snapd_recovery_system=1234",
+		mode:  boot.ModeFactoryReset,
+		label: "1234",
+	}, {
+		cmd: "snapd_recovery_mode=factory-reset foo=bar",
+		err: `cannot specify factory-reset mode without system label`,
 	}, {
 		cmd: "snapd_recovery_system=1234",
 		err: `cannot specify system label without a mode`,
@@ -178,6 +187,10 @@ func (s *kernelCommandLineSuite) TestComposeCommandLineManagedHappy(c *C) {
 	cmdline, err = boot.ComposeCommandLine(model)
 	c.Assert(err, IsNil)
 	c.Assert(cmdline, Equals, "snapd_recovery_mode=run panic=-1")
+
+	cmdline, err = boot.ComposeFactoryResetCommandLine(model, "20200314")
+	c.Assert(err, IsNil)
+	c.Assert(cmdline, Equals, "snapd_recovery_mode=factory-reset snapd_recovery_system=20200314 panic=-1")
 }
 
 func (s *kernelCommandLineSuite) TestComposeCandidateCommandLineManagedHappy(c *C) {
@@ -213,3 +226,128 @@ func (s *kernelCommandLineSuite) TestComposeCandidateRecoveryCommandLineManagedH
 	c.Assert(err, ErrorMatches, "internal error: system is unset")
 	c.Check(cmdline, Equals, "")
 }
+
+type commandLineAppendSuite struct {
+	baseBootenvSuite
+
+	uc20dev boot.Device
+	tbl     *bootloadertest.MockTrustedAssetsBootloader
+
+	resealCalls    int
+	resealCmdlines [][]string
+}
+
+var _ = Suite(&commandLineAppendSuite{})
+
+func (s *commandLineAppendSuite) SetUpTest(c *C) {
+	s.baseBootenvSuite.SetUpTest(c)
+
+	s.uc20dev = boottest.MockUC20Device("run", boottest.MakeMockUC20Model())
+	s.tbl = bootloadertest.Mock("trusted", c.MkDir()).WithTrustedAssets()
+	s.tbl.StaticCommandLine = "panic=-1"
+	s.forceBootloader(s.tbl)
+
+	s.resealCalls = 0
+	s.resealCmdlines = nil
+	restore := boot.MockResealKeyToModeenvUsingFDESetupHook(func(rootdir string, model *asserts.Model, modeenv *boot.Modeenv, expectReseal bool) error {
+		s.resealCalls++
+		c.Check(expectReseal, Equals, true)
+		s.resealCmdlines = append(s.resealCmdlines, modeenv.CurrentKernelCommandLines)
+		return nil
+	})
+	s.AddCleanup(restore)
+
+	marker := filepath.Join(dirs.SnapFDEDirUnder(dirs.GlobalRootDir), "sealed-keys")
+	c.Assert(os.MkdirAll(filepath.Dir(marker), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(marker, []byte("fde-setup-hook"), 0644), IsNil)
+
+	m := &boot.Modeenv{
+		Mode:                      "run",
+		CurrentKernelCommandLines: boot.BootCommandLines{"snapd_recovery_mode=run panic=-1"},
+	}
+	c.Assert(m.WriteTo(""), IsNil)
+}
+
+func (s *commandLineAppendSuite) TestComposeCommandLineWithExtraArgs(c *C) {
+	s.tbl.BootVars["snapd_extra_cmdline_args"] = "isolcpus=1"
+
+	cmdline, err := boot.ComposeCommandLine(s.uc20dev.Model())
+	c.Assert(err, IsNil)
+	c.Check(cmdline, Equals, "snapd_recovery_mode=run panic=-1 isolcpus=1")
+
+	// extra arguments are not used in recover mode
+	cmdline, err = boot.ComposeRecoveryCommandLine(s.uc20dev.Model(), "1234")
+	c.Assert(err, IsNil)
+	c.Check(cmdline, Equals, "snapd_recovery_mode=recover snapd_recovery_system=1234 panic=-1")
+}
+
+func (s *commandLineAppendSuite) TestSetTryCommandLineAppendHappy(c *C) {
+	rebootRequired, err := boot.SetTryCommandLineAppend(s.uc20dev, "isolcpus=1 console=ttyS0")
+	c.Assert(err, IsNil)
+	c.Check(rebootRequired, Equals, true)
+
+	c.Check(s.resealCalls, Equals, 1)
+	c.Check(s.resealCmdlines, DeepEquals, [][]string{{
+		"snapd_recovery_mode=run panic=-1",
+		"snapd_recovery_mode=run panic=-1 isolcpus=1 console=ttyS0",
+	}})
+	m, err := boot.ReadModeenv("")
+	c.Assert(err, IsNil)
+	c.Check(m.CurrentKernelCommandLines, DeepEquals, boot.BootCommandLines{
+		"snapd_recovery_mode=run panic=-1",
+		"snapd_recovery_mode=run panic=-1 isolcpus=1 console=ttyS0",
+	})
+	c.Check(s.tbl.BootVars, DeepEquals, map[string]string{
+		"snapd_try_extra_cmdline_args": "isolcpus=1 console=ttyS0",
+		"cmdline_status":               "try",
+	})
+
+	args, pending, err := boot.CommandLineAppend(s.uc20dev)
+	c.Assert(err, IsNil)
+	c.Check(args, Equals, "")
+	c.Check(pending, Equals, true)
+
+	// no new change while one is pending
+	_, err = boot.SetTryCommandLineAppend(s.uc20dev, "isolcpus=2")
+	c.Assert(err, ErrorMatches, "cannot set kernel command line arguments while a previous change is pending a reboot")
+}
+
+func (s *commandLineAppendSuite) TestSetTryCommandLineAppendUnchanged(c *C) {
+	s.tbl.BootVars["snapd_extra_cmdline_args"] = "isolcpus=1"
+
+	rebootRequired, err := boot.SetTryCommandLineAppend(s.uc20dev, "isolcpus=1")
+	c.Assert(err, IsNil)
+	c.Check(rebootRequired, Equals, false)
+	c.Check(s.resealCalls, Equals, 0)
+	c.Check(s.tbl.SetBootVarsCalls, Equals, 0)
+
+	args, pending, err := boot.CommandLineAppend(s.uc20dev)
+	c.Assert(err, IsNil)
+	c.Check(args, Equals, "isolcpus=1")
+	c.Check(pending, Equals, false)
+}
+
+func (s *commandLineAppendSuite) TestSetTryCommandLineAppendErrors(c *C) {
+	_, err := boot.SetTryCommandLineAppend(boottest.MockUC20Device("recover", nil), "isolcpus=1")
+	c.Assert(err, ErrorMatches, "cannot set kernel command line arguments outside of UC20\\+ run mode")
+
+	// boot config update pending
+	m := &boot.Modeenv{
+		Mode:                      "run",
+		CurrentKernelCommandLines: boot.BootCommandLines{"snapd_recovery_mode=run panic=-1", "snapd_recovery_mode=run candidate"},
+	}
+	c.Assert(m.WriteTo(""), IsNil)
+	_, err = boot.SetTryCommandLineAppend(s.uc20dev, "isolcpus=1")
+	c.Assert(err, ErrorMatches, "cannot set kernel command line arguments while a boot config update is pending a reboot")
+
+	// bootloader does not manage its config
+	bootloader.Force(bootloadertest.Mock("mock", c.MkDir()))
+	_, err = boot.SetTryCommandLineAppend(s.uc20dev, "isolcpus=1")
+	c.Assert(err, ErrorMatches, "cannot set kernel command line arguments: boot config is not managed")
+	c.Check(s.resealCalls, Equals, 0)
+	// in which case nothing is appended
+	args, pending, err := boot.CommandLineAppend(s.uc20dev)
+	c.Assert(err, IsNil)
+	c.Check(args, Equals, "")
+	c.Check(pending, Equals, false)
+}
@@ -20,12 +20,21 @@
 package boot
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/bootloader"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
 )
 
 // DumpBootVars writes a dump of the snapd bootvars to the given writer
@@ -83,3 +92,259 @@ func DumpBootVars(w io.Writer, dir string, uc20 bool) error {
 	}
 	return nil
 }
+
+// DumpBootChains writes to the given writer the boot chains the run and
+// fallback encryption keys are currently sealed to, the boot chains a reseal
+// would use with the current modeenv, the differences between the two and
+// the PCR protection profile built from the latter. If candidateKernel is
+// set, it is the file name of a kernel snap that is considered to be added
+// by a refresh, as it would be listed in the modeenv while the kernel is
+// being tried. If candidateGadgetDir is set, it is the directory of a gadget
+// snap that is considered to be refreshed to, its trusted boot assets are
+// tracked in the modeenv alongside the current ones, as they would be while
+// the gadget assets are being updated.
+func DumpBootChains(w io.Writer, model *asserts.Model, candidateKernel, candidateGadgetDir string) error {
+	method, err := sealedKeysMethod(dirs.GlobalRootDir)
+	if err == errNoSealedKeys {
+		fmt.Fprintf(w, "encryption keys are not sealed\n")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch method {
+	case sealingMethodFDESetupHook:
+		fmt.Fprintf(w, "sealing method: %s\n", method)
+		fmt.Fprintf(w, "boot chains are not used with the fde-setup hook\n")
+		return nil
+	case sealingMethodLegacyTPM:
+		fmt.Fprintf(w, "sealing method: tpm (legacy)\n")
+	default:
+		fmt.Fprintf(w, "sealing method: %s\n", method)
+	}
+
+	modeenv, err := ReadModeenv("")
+	if err != nil {
+		return err
+	}
+	if candidateKernel != "" {
+		candidateKernel = filepath.Base(candidateKernel)
+		if _, err := snap.ParsePlaceInfoFromSnapFileName(candidateKernel); err != nil {
+			return fmt.Errorf("cannot use candidate kernel: %v", err)
+		}
+		if !strutil.ListContains(modeenv.CurrentKernels, candidateKernel) {
+			modeenv.CurrentKernels = append(modeenv.CurrentKernels, candidateKernel)
+		}
+		fmt.Fprintf(w, "candidate kernel: %s\n", candidateKernel)
+	}
+	if candidateGadgetDir != "" {
+		if err := observeCandidateGadgetAssets(modeenv, model, candidateGadgetDir); err != nil {
+			return fmt.Errorf("cannot use candidate gadget: %v", err)
+		}
+		fmt.Fprintf(w, "candidate gadget: %s\n", candidateGadgetDir)
+	}
+
+	runModeBootChains, recoveryBootChains, roleToBlName, err := bootChainsForReseal(model, modeenv)
+	if err != nil {
+		return err
+	}
+	pbc := toPredictableBootChains(append(runModeBootChains, recoveryBootChains...))
+	if err := dumpBootChainsForObject(w, "run object", pbc, bootChainsFileUnder(dirs.GlobalRootDir), roleToBlName); err != nil {
+		return err
+	}
+	rpbc := toPredictableBootChains(recoveryBootChains)
+	return dumpBootChainsForObject(w, "fallback object", rpbc, recoveryBootChainsFileUnder(dirs.GlobalRootDir), roleToBlName)
+}
+
+// candidateGadgetAssetsObserver tracks in the modeenv the trusted boot assets
+// of a candidate gadget, the same way TrustedAssetsUpdateObserver does during
+// a gadget refresh. The assets are added to the cache so that the PCR
+// protection profile can be built, but nothing else is written.
+type candidateGadgetAssetsObserver struct {
+	modeenv *Modeenv
+	cache   *trustedAssetsCache
+
+	bootBootloader    bootloader.Bootloader
+	bootTrustedAssets []string
+
+	seedBootloader    bootloader.Bootloader
+	seedTrustedAssets []string
+}
+
+func (o *candidateGadgetAssetsObserver) Observe(op gadget.ContentOperation, affectedStruct *gadget.LaidOutStructure, root, relativeTarget string, data *gadget.ContentChange) (gadget.ContentChangeAction, error) {
+	var whichBootloader bootloader.Bootloader
+	var whichTrustedAssets []string
+	var trustedAssets *bootAssetsMap
+	switch affectedStruct.Role {
+	case gadget.SystemBoot:
+		whichBootloader = o.bootBootloader
+		whichTrustedAssets = o.bootTrustedAssets
+		trustedAssets = &o.modeenv.CurrentTrustedBootAssets
+	case gadget.SystemSeed:
+		whichBootloader = o.seedBootloader
+		whichTrustedAssets = o.seedTrustedAssets
+		trustedAssets = &o.modeenv.CurrentTrustedRecoveryBootAssets
+	default:
+		return gadget.ChangeIgnore, nil
+	}
+	if !strutil.ListContains(whichTrustedAssets, relativeTarget) {
+		return gadget.ChangeIgnore, nil
+	}
+	ta, err := o.cache.Add(data.After, whichBootloader.Name(), filepath.Base(relativeTarget))
+	if err != nil {
+		return gadget.ChangeAbort, err
+	}
+	if *trustedAssets == nil {
+		*trustedAssets = bootAssetsMap{}
+	}
+	if !isAssetAlreadyTracked(*trustedAssets, ta) {
+		(*trustedAssets)[ta.name] = append((*trustedAssets)[ta.name], ta.hash)
+	}
+	// the content is never written
+	return gadget.ChangeIgnore, nil
+}
+
+// observeCandidateGadgetAssets walks the content of the system-boot and
+// system-seed structures of the gadget in the given directory and tracks the
+// trusted boot assets found there in the modeenv.
+func observeCandidateGadgetAssets(modeenv *Modeenv, model *asserts.Model, gadgetDir string) error {
+	runBl, runTrusted, _, err := gadgetMaybeTrustedBootloaderAndAssets(gadgetDir, InitramfsUbuntuBootDir,
+		&bootloader.Options{
+			Role:        bootloader.RoleRunMode,
+			NoSlashBoot: true,
+		})
+	if err != nil {
+		return err
+	}
+	seedBl, seedTrusted, _, err := gadgetMaybeTrustedBootloaderAndAssets(gadgetDir, InitramfsUbuntuSeedDir,
+		&bootloader.Options{
+			Role: bootloader.RoleRecovery,
+		})
+	if err != nil {
+		return err
+	}
+	lv, err := gadget.LaidOutVolumeFromGadget(gadgetDir, model)
+	if err != nil {
+		return err
+	}
+
+	obs := &candidateGadgetAssetsObserver{
+		modeenv:           modeenv,
+		cache:             newTrustedAssetsCache(dirs.SnapBootAssetsDir),
+		bootBootloader:    runBl,
+		bootTrustedAssets: runTrusted,
+		seedBootloader:    seedBl,
+		seedTrustedAssets: seedTrusted,
+	}
+	// the observer makes the writer skip all files, only the directories
+	// of the content are created there
+	scratchDir, err := ioutil.TempDir("", "snapd-boot-chains-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(scratchDir)
+	for i := range lv.LaidOutStructure {
+		ps := &lv.LaidOutStructure[i]
+		if ps.Role != gadget.SystemBoot && ps.Role != gadget.SystemSeed || !ps.HasFilesystem() {
+			continue
+		}
+		fw, err := gadget.NewMountedFilesystemWriter(gadgetDir, ps, obs)
+		if err != nil {
+			return err
+		}
+		if err := fw.Write(filepath.Join(scratchDir, ps.Role), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dumpBootChainsForObject(w io.Writer, object string, pbc predictableBootChains, bootChainsFile string, roleToBlName map[bootloader.Role]string) error {
+	sealedPbc, resealCount, err := readBootChains(bootChainsFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%s:\n", object)
+	fmt.Fprintf(w, "  reseal count: %d\n", resealCount)
+	fmt.Fprintf(w, "  sealed boot chains:\n")
+	for i := range sealedPbc {
+		writeBootChain(w, "    ", "-", &sealedPbc[i])
+	}
+	fmt.Fprintf(w, "  predicted boot chains:\n")
+	for i := range pbc {
+		writeBootChain(w, "    ", "-", &pbc[i])
+	}
+
+	var resealNeeded string
+	switch predictableBootChainsEqualForReseal(pbc, sealedPbc) {
+	case bootChainEquivalent:
+		resealNeeded = "no"
+	case bootChainUnrevisioned:
+		resealNeeded = "unknown, unasserted kernels are in use"
+	case bootChainDifferent:
+		resealNeeded = "yes"
+		fmt.Fprintf(w, "  changes:\n")
+		for i := range sealedPbc {
+			if !bootChainsContain(pbc, &sealedPbc[i]) {
+				writeBootChain(w, "    ", "-", &sealedPbc[i])
+			}
+		}
+		for i := range pbc {
+			if !bootChainsContain(sealedPbc, &pbc[i]) {
+				writeBootChain(w, "    ", "+", &pbc[i])
+			}
+		}
+	}
+	fmt.Fprintf(w, "  reseal needed: %s\n", resealNeeded)
+
+	fmt.Fprintf(w, "  PCR protection profile:\n")
+	profile, err := pcrProtectionProfileForBootChains(pbc, roleToBlName)
+	if err != nil {
+		fmt.Fprintf(w, "    cannot build: %v\n", err)
+		return nil
+	}
+	for _, line := range strings.Split(strings.TrimRight(profile, "\n"), "\n") {
+		fmt.Fprintf(w, "    %s\n", line)
+	}
+	return nil
+}
+
+func pcrProtectionProfileForBootChains(pbc predictableBootChains, roleToBlName map[bootloader.Role]string) (string, error) {
+	modelParams, err := sealKeyModelParams(pbc, roleToBlName)
+	if err != nil {
+		return "", err
+	}
+	return secbootDescribePCRProtectionProfile(modelParams)
+}
+
+func bootChainsContain(pbc predictableBootChains, bc *bootChain) bool {
+	bcJSON, err := json.Marshal(bc)
+	if err != nil {
+		return false
+	}
+	for i := range pbc {
+		otherJSON, err := json.Marshal(&pbc[i])
+		if err == nil && string(otherJSON) == string(bcJSON) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeBootChain(w io.Writer, indent, marker string, bc *bootChain) {
+	kernelRev := bc.KernelRevision
+	if kernelRev == "" {
+		kernelRev = "unasserted"
+	}
+	fmt.Fprintf(w, "%s%s kernel: %s (%s)\n", indent, marker, bc.Kernel, kernelRev)
+	fmt.Fprintf(w, "%s  model: %s/%s (%s)\n", indent, bc.BrandID, bc.Model, bc.Grade)
+	fmt.Fprintf(w, "%s  asset chain:\n", indent)
+	for _, asset := range bc.AssetChain {
+		fmt.Fprintf(w, "%s    %s %s: %s\n", indent, asset.Role, asset.Name, strings.Join(asset.Hashes, ", "))
+	}
+	fmt.Fprintf(w, "%s  kernel command lines:\n", indent)
+	for _, cmdline := range bc.KernelCmdlines {
+		fmt.Fprintf(w, "%s    %s\n", indent, cmdline)
+	}
+}
@@ -0,0 +1,429 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package boot_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/boot/boottest"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/seed"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+	"github.com/snapcore/snapd/timings"
+)
+
+type debugSuite struct {
+	testutil.BaseTest
+
+	rootdir string
+}
+
+var _ = Suite(&debugSuite{})
+
+func (s *debugSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+
+	s.rootdir = c.MkDir()
+	dirs.SetRootDir(s.rootdir)
+	s.AddCleanup(func() { dirs.SetRootDir("/") })
+}
+
+func (s *debugSuite) TestDumpBootChainsNotSealed(c *C) {
+	buf := bytes.NewBuffer(nil)
+	err := boot.DumpBootChains(buf, boottest.MakeMockUC20Model(), "", "")
+	c.Assert(err, IsNil)
+	c.Check(buf.String(), Equals, "encryption keys are not sealed\n")
+}
+
+func (s *debugSuite) TestDumpBootChainsFDESetupHook(c *C) {
+	c.Assert(os.MkdirAll(dirs.SnapFDEDir, 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dirs.SnapFDEDir, "sealed-keys"), []byte("fde-setup-hook"), 0644), IsNil)
+
+	buf := bytes.NewBuffer(nil)
+	err := boot.DumpBootChains(buf, boottest.MakeMockUC20Model(), "", "")
+	c.Assert(err, IsNil)
+	c.Check(buf.String(), Equals, `sealing method: fde-setup-hook
+boot chains are not used with the fde-setup hook
+`)
+}
+
+func (s *debugSuite) TestDumpBootChainsWithCandidateKernel(c *C) {
+	c.Assert(os.MkdirAll(dirs.SnapFDEDir, 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dirs.SnapFDEDir, "sealed-keys"), []byte("tpm"), 0644), IsNil)
+	c.Assert(createMockGrubCfg(filepath.Join(s.rootdir, "run/mnt/ubuntu-seed")), IsNil)
+	c.Assert(createMockGrubCfg(filepath.Join(s.rootdir, "run/mnt/ubuntu-boot")), IsNil)
+
+	modeenv := &boot.Modeenv{
+		Mode:                   "run",
+		CurrentRecoverySystems: []string{"20200825"},
+		CurrentTrustedRecoveryBootAssets: boot.BootAssetsMap{
+			"grubx64.efi": []string{"grub-hash-1"},
+			"bootx64.efi": []string{"shim-hash-1"},
+		},
+		CurrentTrustedBootAssets: boot.BootAssetsMap{
+			"grubx64.efi": []string{"run-grub-hash-1"},
+		},
+		CurrentKernels: []string{"pc-kernel_500.snap"},
+		CurrentKernelCommandLines: boot.BootCommandLines{
+			"snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1",
+		},
+	}
+	c.Assert(modeenv.WriteTo(""), IsNil)
+
+	// mock asset cache
+	for _, name := range []string{"bootx64.efi-shim-hash-1", "grubx64.efi-grub-hash-1", "grubx64.efi-run-grub-hash-1"} {
+		p := filepath.Join(dirs.SnapBootAssetsDir, "grub", name)
+		c.Assert(os.MkdirAll(filepath.Dir(p), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(p, nil, 0644), IsNil)
+	}
+
+	model := boottest.MakeMockUC20Model()
+	restore := boot.MockSeedReadSystemEssential(func(seedDir, label string, essentialTypes []snap.Type, tm timings.Measurer) (*asserts.Model, []*seed.Snap, error) {
+		kernelSnap := &seed.Snap{
+			Path: "/var/lib/snapd/seed/snaps/pc-kernel_1.snap",
+			SideInfo: &snap.SideInfo{
+				RealName: "pc-kernel",
+				Revision: snap.Revision{N: 1},
+			},
+		}
+		return model, []*seed.Snap{kernelSnap}, nil
+	})
+	defer restore()
+
+	var loadChains []int
+	restore = boot.MockSecbootDescribePCRProtectionProfile(func(modelParams []*secboot.SealKeyModelParams) (string, error) {
+		c.Assert(modelParams, HasLen, 1)
+		loadChains = append(loadChains, len(modelParams[0].EFILoadChains))
+		return "mock profile\n  with details\n", nil
+	})
+	defer restore()
+
+	recoveryChain := boot.BootChain{
+		BrandID:        "my-brand",
+		Model:          "my-model-uc20",
+		Grade:          "dangerous",
+		ModelSignKeyID: "Jv8_JiHiIzJVcO9M55pPdqSDWUvuhfDIBJUS-3VW7F_idjix7Ffn5qMxB21ZQuij",
+		AssetChain: []boot.BootAsset{
+			{Role: "recovery", Name: "bootx64.efi", Hashes: []string{"shim-hash-1"}},
+			{Role: "recovery", Name: "grubx64.efi", Hashes: []string{"grub-hash-1"}},
+		},
+		Kernel:         "pc-kernel",
+		KernelRevision: "1",
+		KernelCmdlines: []string{
+			"snapd_recovery_mode=factory-reset snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
+			"snapd_recovery_mode=recover snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
+		},
+	}
+	runChain := boot.BootChain{
+		BrandID:        "my-brand",
+		Model:          "my-model-uc20",
+		Grade:          "dangerous",
+		ModelSignKeyID: "Jv8_JiHiIzJVcO9M55pPdqSDWUvuhfDIBJUS-3VW7F_idjix7Ffn5qMxB21ZQuij",
+		AssetChain: []boot.BootAsset{
+			{Role: "recovery", Name: "bootx64.efi", Hashes: []string{"shim-hash-1"}},
+			{Role: "recovery", Name: "grubx64.efi", Hashes: []string{"grub-hash-1"}},
+			{Role: "run-mode", Name: "grubx64.efi", Hashes: []string{"run-grub-hash-1"}},
+		},
+		Kernel:         "pc-kernel",
+		KernelRevision: "500",
+		KernelCmdlines: []string{
+			"snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1",
+		},
+	}
+	err := boot.WriteBootChains(boot.ToPredictableBootChains([]boot.BootChain{recoveryChain, runChain}),
+		filepath.Join(dirs.SnapFDEDir, "boot-chains"), 2)
+	c.Assert(err, IsNil)
+	err = boot.WriteBootChains(boot.ToPredictableBootChains([]boot.BootChain{recoveryChain}),
+		filepath.Join(dirs.SnapFDEDir, "recovery-boot-chains"), 1)
+	c.Assert(err, IsNil)
+
+	buf := bytes.NewBuffer(nil)
+	err = boot.DumpBootChains(buf, model, "/var/lib/snapd/snaps/pc-kernel_600.snap", "")
+	c.Assert(err, IsNil)
+
+	const recoveryChainDump = `kernel: pc-kernel (1)
+      model: my-brand/my-model-uc20 (dangerous)
+      asset chain:
+        recovery bootx64.efi: shim-hash-1
+        recovery grubx64.efi: grub-hash-1
+      kernel command lines:
+        snapd_recovery_mode=factory-reset snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1
+        snapd_recovery_mode=recover snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1
+`
+	const runChainDumpFmt = `kernel: pc-kernel (%s)
+      model: my-brand/my-model-uc20 (dangerous)
+      asset chain:
+        recovery bootx64.efi: shim-hash-1
+        recovery grubx64.efi: grub-hash-1
+        run-mode grubx64.efi: run-grub-hash-1
+      kernel command lines:
+        snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1
+`
+	c.Check(buf.String(), Equals, "sealing method: tpm\n"+
+		"candidate kernel: pc-kernel_600.snap\n"+
+		"run object:\n"+
+		"  reseal count: 2\n"+
+		"  sealed boot chains:\n"+
+		"    - "+recoveryChainDump+
+		"    - "+fmt.Sprintf(runChainDumpFmt, "500")+
+		"  predicted boot chains:\n"+
+		"    - "+recoveryChainDump+
+		"    - "+fmt.Sprintf(runChainDumpFmt, "500")+
+		"    - "+fmt.Sprintf(runChainDumpFmt, "600")+
+		"  changes:\n"+
+		"    + "+fmt.Sprintf(runChainDumpFmt, "600")+
+		"  reseal needed: yes\n"+
+		"  PCR protection profile:\n"+
+		"    mock profile\n"+
+		"      with details\n"+
+		"fallback object:\n"+
+		"  reseal count: 1\n"+
+		"  sealed boot chains:\n"+
+		"    - "+recoveryChainDump+
+		"  predicted boot chains:\n"+
+		"    - "+recoveryChainDump+
+		"  reseal needed: no\n"+
+		"  PCR protection profile:\n"+
+		"    mock profile\n"+
+		"      with details\n")
+	// one load chain for each of the recovery and run mode chains
+	c.Check(loadChains, DeepEquals, []int{3, 1})
+
+	// the modeenv is left untouched
+	m, err := boot.ReadModeenv("")
+	c.Assert(err, IsNil)
+	c.Check(m.CurrentKernels, DeepEquals, []string{"pc-kernel_500.snap"})
+}
+
+const debugGadgetYaml = `
+volumes:
+  pc:
+    bootloader: grub
+    structure:
+      - name: ubuntu-seed
+        role: system-seed
+        filesystem: vfat
+        type: EF,C12A7328-F81F-11D2-BA4B-00A0C93EC93B
+        size: 1200M
+      - name: ubuntu-boot
+        role: system-boot
+        filesystem: ext4
+        type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 750M
+        content:
+          - source: grubx64.efi
+            target: EFI/boot/grubx64.efi
+      - name: ubuntu-data
+        role: system-data
+        filesystem: ext4
+        type: 83,0FC63DAF-8483-4772-8E79-3D69D8477DE4
+        size: 1G
+`
+
+func (s *debugSuite) TestDumpBootChainsWithCandidateGadget(c *C) {
+	c.Assert(os.MkdirAll(dirs.SnapFDEDir, 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dirs.SnapFDEDir, "sealed-keys"), []byte("tpm"), 0644), IsNil)
+	c.Assert(createMockGrubCfg(filepath.Join(s.rootdir, "run/mnt/ubuntu-seed")), IsNil)
+	c.Assert(createMockGrubCfg(filepath.Join(s.rootdir, "run/mnt/ubuntu-boot")), IsNil)
+
+	modeenv := &boot.Modeenv{
+		Mode:                   "run",
+		CurrentRecoverySystems: []string{"20200825"},
+		CurrentTrustedRecoveryBootAssets: boot.BootAssetsMap{
+			"grubx64.efi": []string{"grub-hash-1"},
+			"bootx64.efi": []string{"shim-hash-1"},
+		},
+		CurrentTrustedBootAssets: boot.BootAssetsMap{
+			"grubx64.efi": []string{"run-grub-hash-1"},
+		},
+		CurrentKernels: []string{"pc-kernel_500.snap"},
+		CurrentKernelCommandLines: boot.BootCommandLines{
+			"snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1",
+		},
+	}
+	c.Assert(modeenv.WriteTo(""), IsNil)
+
+	// mock asset cache
+	for _, name := range []string{"bootx64.efi-shim-hash-1", "grubx64.efi-grub-hash-1", "grubx64.efi-run-grub-hash-1"} {
+		p := filepath.Join(dirs.SnapBootAssetsDir, "grub", name)
+		c.Assert(os.MkdirAll(filepath.Dir(p), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(p, nil, 0644), IsNil)
+	}
+
+	// the candidate gadget carries an updated run mode grub
+	gadgetDir := c.MkDir()
+	c.Assert(os.MkdirAll(filepath.Join(gadgetDir, "meta"), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(gadgetDir, "meta/gadget.yaml"), []byte(debugGadgetYaml), 0644), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(gadgetDir, "grub.conf"), nil, 0644), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(gadgetDir, "grubx64.efi"), []byte("foobar"), 0644), IsNil)
+	// SHA3-384
+	const newGrubHash = "0fa8abfbdaf924ad307b74dd2ed183b9a4a398891a2f6bac8fd2db7041b77f068580f9c6c66f699b496c2da1cbcc7ed8"
+
+	model := boottest.MakeMockUC20Model()
+	restore := boot.MockSeedReadSystemEssential(func(seedDir, label string, essentialTypes []snap.Type, tm timings.Measurer) (*asserts.Model, []*seed.Snap, error) {
+		kernelSnap := &seed.Snap{
+			Path: "/var/lib/snapd/seed/snaps/pc-kernel_1.snap",
+			SideInfo: &snap.SideInfo{
+				RealName: "pc-kernel",
+				Revision: snap.Revision{N: 1},
+			},
+		}
+		return model, []*seed.Snap{kernelSnap}, nil
+	})
+	defer restore()
+
+	var loadChains []int
+	restore = boot.MockSecbootDescribePCRProtectionProfile(func(modelParams []*secboot.SealKeyModelParams) (string, error) {
+		c.Assert(modelParams, HasLen, 1)
+		loadChains = append(loadChains, len(modelParams[0].EFILoadChains))
+		return "mock profile\n", nil
+	})
+	defer restore()
+
+	recoveryChain := boot.BootChain{
+		BrandID:        "my-brand",
+		Model:          "my-model-uc20",
+		Grade:          "dangerous",
+		ModelSignKeyID: "Jv8_JiHiIzJVcO9M55pPdqSDWUvuhfDIBJUS-3VW7F_idjix7Ffn5qMxB21ZQuij",
+		AssetChain: []boot.BootAsset{
+			{Role: "recovery", Name: "bootx64.efi", Hashes: []string{"shim-hash-1"}},
+			{Role: "recovery", Name: "grubx64.efi", Hashes: []string{"grub-hash-1"}},
+		},
+		Kernel:         "pc-kernel",
+		KernelRevision: "1",
+		KernelCmdlines: []string{
+			"snapd_recovery_mode=factory-reset snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
+			"snapd_recovery_mode=recover snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
+		},
+	}
+	runChain := boot.BootChain{
+		BrandID:        "my-brand",
+		Model:          "my-model-uc20",
+		Grade:          "dangerous",
+		ModelSignKeyID: "Jv8_JiHiIzJVcO9M55pPdqSDWUvuhfDIBJUS-3VW7F_idjix7Ffn5qMxB21ZQuij",
+		AssetChain: []boot.BootAsset{
+			{Role: "recovery", Name: "bootx64.efi", Hashes: []string{"shim-hash-1"}},
+			{Role: "recovery", Name: "grubx64.efi", Hashes: []string{"grub-hash-1"}},
+			{Role: "run-mode", Name: "grubx64.efi", Hashes: []string{"run-grub-hash-1"}},
+		},
+		Kernel:         "pc-kernel",
+		KernelRevision: "500",
+		KernelCmdlines: []string{
+			"snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1",
+		},
+	}
+	err := boot.WriteBootChains(boot.ToPredictableBootChains([]boot.BootChain{recoveryChain, runChain}),
+		filepath.Join(dirs.SnapFDEDir, "boot-chains"), 2)
+	c.Assert(err, IsNil)
+	err = boot.WriteBootChains(boot.ToPredictableBootChains([]boot.BootChain{recoveryChain}),
+		filepath.Join(dirs.SnapFDEDir, "recovery-boot-chains"), 1)
+	c.Assert(err, IsNil)
+
+	buf := bytes.NewBuffer(nil)
+	err = boot.DumpBootChains(buf, model, "", gadgetDir)
+	c.Assert(err, IsNil)
+
+	const recoveryChainDump = `kernel: pc-kernel (1)
+      model: my-brand/my-model-uc20 (dangerous)
+      asset chain:
+        recovery bootx64.efi: shim-hash-1
+        recovery grubx64.efi: grub-hash-1
+      kernel command lines:
+        snapd_recovery_mode=factory-reset snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1
+        snapd_recovery_mode=recover snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1
+`
+	const runChainDumpFmt = `kernel: pc-kernel (500)
+      model: my-brand/my-model-uc20 (dangerous)
+      asset chain:
+        recovery bootx64.efi: shim-hash-1
+        recovery grubx64.efi: grub-hash-1
+        run-mode grubx64.efi: %s
+      kernel command lines:
+        snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1
+`
+	c.Check(buf.String(), Equals, "sealing method: tpm\n"+
+		"candidate gadget: "+gadgetDir+"\n"+
+		"run object:\n"+
+		"  reseal count: 2\n"+
+		"  sealed boot chains:\n"+
+		"    - "+recoveryChainDump+
+		"    - "+fmt.Sprintf(runChainDumpFmt, "run-grub-hash-1")+
+		"  predicted boot chains:\n"+
+		"    - "+recoveryChainDump+
+		"    - "+fmt.Sprintf(runChainDumpFmt, newGrubHash+", run-grub-hash-1")+
+		"  changes:\n"+
+		"    - "+fmt.Sprintf(runChainDumpFmt, "run-grub-hash-1")+
+		"    + "+fmt.Sprintf(runChainDumpFmt, newGrubHash+", run-grub-hash-1")+
+		"  reseal needed: yes\n"+
+		"  PCR protection profile:\n"+
+		"    mock profile\n"+
+		"fallback object:\n"+
+		"  reseal count: 1\n"+
+		"  sealed boot chains:\n"+
+		"    - "+recoveryChainDump+
+		"  predicted boot chains:\n"+
+		"    - "+recoveryChainDump+
+		"  reseal needed: no\n"+
+		"  PCR protection profile:\n"+
+		"    mock profile\n")
+	// one load chain for each of the recovery and run mode chains
+	c.Check(loadChains, DeepEquals, []int{2, 1})
+
+	// the new asset is cached, so that the profile can be built
+	c.Check(filepath.Join(dirs.SnapBootAssetsDir, "grub", "grubx64.efi-"+newGrubHash), testutil.FileEquals, "foobar")
+	// but the modeenv is left untouched
+	m, err := boot.ReadModeenv("")
+	c.Assert(err, IsNil)
+	c.Check(m.CurrentTrustedBootAssets, DeepEquals, boot.BootAssetsMap{
+		"grubx64.efi": []string{"run-grub-hash-1"},
+	})
+}
+
+func (s *debugSuite) TestDumpBootChainsInvalidCandidateGadget(c *C) {
+	c.Assert(os.MkdirAll(dirs.SnapFDEDir, 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dirs.SnapFDEDir, "sealed-keys"), []byte("tpm"), 0644), IsNil)
+	modeenv := &boot.Modeenv{Mode: "run"}
+	c.Assert(modeenv.WriteTo(""), IsNil)
+
+	buf := bytes.NewBuffer(nil)
+	err := boot.DumpBootChains(buf, boottest.MakeMockUC20Model(), "", c.MkDir())
+	c.Assert(err, ErrorMatches, `cannot use candidate gadget: cannot find bootloader: .*`)
+}
+
+func (s *debugSuite) TestDumpBootChainsInvalidCandidateKernel(c *C) {
+	c.Assert(os.MkdirAll(dirs.SnapFDEDir, 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dirs.SnapFDEDir, "sealed-keys"), []byte("tpm"), 0644), IsNil)
+	modeenv := &boot.Modeenv{Mode: "run"}
+	c.Assert(modeenv.WriteTo(""), IsNil)
+
+	buf := bytes.NewBuffer(nil)
+	err := boot.DumpBootChains(buf, boottest.MakeMockUC20Model(), "pc-kernel.snap", "")
+	c.Assert(err, ErrorMatches, `cannot use candidate kernel: .*`)
+}
@@ -21,6 +21,7 @@ package boot
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/bootloader"
@@ -113,6 +114,14 @@ func MockSecbootResealKeys(f func(params *secboot.ResealKeysParams) error) (rest
 	}
 }
 
+func MockSecbootDescribePCRProtectionProfile(f func(modelParams []*secboot.SealKeyModelParams) (string, error)) (restore func()) {
+	old := secbootDescribePCRProtectionProfile
+	secbootDescribePCRProtectionProfile = f
+	return func() {
+		secbootDescribePCRProtectionProfile = old
+	}
+}
+
 func MockSeedReadSystemEssential(f func(seedDir, label string, essentialTypes []snap.Type, tm timings.Measurer) (*asserts.Model, []*seed.Snap, error)) (restore func()) {
 	old := seedReadSystemEssential
 	seedReadSystemEssential = f
@@ -189,3 +198,27 @@ func MockResealKeyToModeenvUsingFDESetupHook(f func(string, *asserts.Model, *Mod
 		resealKeyToModeenvUsingFDESetupHook = old
 	}
 }
+
+func MockBootID(f func() (string, error)) (restore func()) {
+	old := osutilBootID
+	osutilBootID = f
+	return func() {
+		osutilBootID = old
+	}
+}
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	old := timeNow
+	timeNow = f
+	return func() {
+		timeNow = old
+	}
+}
+
+func MockMaxBootHistoryEntries(n int) (restore func()) {
+	old := maxBootHistoryEntries
+	maxBootHistoryEntries = n
+	return func() {
+		maxBootHistoryEntries = old
+	}
+}
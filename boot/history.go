@@ -0,0 +1,204 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package boot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+)
+
+const (
+	// BootOutcomeBooted is the outcome of a boot that was not (yet) marked
+	// as successful.
+	BootOutcomeBooted = "booted"
+	// BootOutcomeTrying is the outcome of a boot trying a new kernel or
+	// base that was not (yet) marked as successful.
+	BootOutcomeTrying = "trying"
+	// BootOutcomeSuccess is the outcome of a boot that was marked as
+	// successful.
+	BootOutcomeSuccess = "success"
+	// BootOutcomeReverted is the outcome of a boot trying a new kernel or
+	// base that was never marked as successful, the next boot used the
+	// previous kernel and base again.
+	BootOutcomeReverted = "reverted"
+)
+
+// BootHistoryEntry records a boot of the system.
+type BootHistoryEntry struct {
+	BootID string    `json:"boot-id"`
+	Time   time.Time `json:"time"`
+	Mode   string    `json:"mode"`
+	// Kernel and Base are the snaps booted in run mode.
+	Kernel string `json:"kernel,omitempty"`
+	Base   string `json:"base,omitempty"`
+	// RecoverySystem is the recovery system booted in modes other than
+	// run.
+	RecoverySystem string `json:"recovery-system,omitempty"`
+	// Try is set when a new kernel or base was tried.
+	Try     bool   `json:"try,omitempty"`
+	Outcome string `json:"outcome"`
+}
+
+var (
+	// maxBootHistoryEntries is the number of boots kept in the history,
+	// older ones are dropped.
+	maxBootHistoryEntries = 100
+
+	osutilBootID = osutil.BootID
+	timeNow      = time.Now
+)
+
+// bootHistoryFile returns the path of the boot history file, it is kept on
+// ubuntu-save when available so that boots in all modes are recorded in the
+// same place. The mount of ubuntu-save set up by the initramfs is preferred,
+// as it is available in all modes before snapd starts, while outside of run
+// mode ubuntu-data is ephemeral.
+func bootHistoryFile() string {
+	for _, saveDir := range []string{InitramfsUbuntuSaveDir, dirs.SnapSaveDir} {
+		if osutil.IsDirectory(saveDir) {
+			return filepath.Join(saveDir, "boot-history.json")
+		}
+	}
+	return filepath.Join(dirs.SnapHistoryDir, "boot-history.json")
+}
+
+// BootHistory returns the recorded boots of the system, from oldest to
+// newest.
+func BootHistory() ([]*BootHistoryEntry, error) {
+	data, err := ioutil.ReadFile(bootHistoryFile())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read boot history: %v", err)
+	}
+	var entries []*BootHistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("cannot decode boot history: %v", err)
+	}
+	return entries, nil
+}
+
+func writeBootHistory(entries []*BootHistoryEntry) error {
+	if len(entries) > maxBootHistoryEntries {
+		entries = entries[len(entries)-maxBootHistoryEntries:]
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	fn := bootHistoryFile()
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+		return err
+	}
+	return osutil.AtomicWriteFile(fn, data, 0644, 0)
+}
+
+// RecordBoot records the current boot of the system in the given mode in the
+// boot history, unless it was already recorded. A previous boot that tried a
+// new kernel or base but was never marked as successful is recorded as
+// reverted.
+func RecordBoot(dev Device, mode string) error {
+	bootID, err := osutilBootID()
+	if err != nil {
+		return fmt.Errorf("cannot record boot: %v", err)
+	}
+	entries, err := BootHistory()
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		if last.BootID == bootID {
+			// snapd restarted, already recorded
+			return nil
+		}
+		if last.Outcome == BootOutcomeTrying {
+			last.Outcome = BootOutcomeReverted
+		}
+	}
+
+	e := &BootHistoryEntry{
+		BootID:  bootID,
+		Time:    timeNow(),
+		Mode:    mode,
+		Outcome: BootOutcomeBooted,
+	}
+	if mode == "run" {
+		for _, t := range []snap.Type{snap.TypeKernel, snap.TypeBase} {
+			s, err := bootStateFor(t, dev)
+			if err != nil {
+				return err
+			}
+			cur, try, status, err := s.revisions()
+			if err != nil {
+				return fmt.Errorf("cannot record boot: %v", err)
+			}
+			if status == TryingStatus && try != nil {
+				cur = try
+				e.Try = true
+				e.Outcome = BootOutcomeTrying
+			}
+			if t == snap.TypeKernel {
+				e.Kernel = cur.Filename()
+			} else {
+				e.Base = cur.Filename()
+			}
+		}
+	} else if dev.HasModeenv() {
+		m, err := ReadModeenv("")
+		if err != nil {
+			return fmt.Errorf("cannot record boot: %v", err)
+		}
+		e.RecoverySystem = m.RecoverySystem
+	}
+
+	return writeBootHistory(append(entries, e))
+}
+
+// markBootHistorySuccessful records that the current boot, if it is in the
+// boot history, was successful.
+func markBootHistorySuccessful() error {
+	bootID, err := osutilBootID()
+	if err != nil {
+		return err
+	}
+	entries, err := BootHistory()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	last := entries[len(entries)-1]
+	if last.BootID != bootID || last.Outcome == BootOutcomeSuccess {
+		return nil
+	}
+	last.Outcome = BootOutcomeSuccess
+	return writeBootHistory(entries)
+}
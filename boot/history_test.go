@@ -0,0 +1,242 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package boot_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/boot/boottest"
+	"github.com/snapcore/snapd/bootloader/bootloadertest"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type bootHistorySuite struct {
+	baseBootenvSuite
+
+	bootloader *bootloadertest.MockBootloader
+
+	bootID string
+	now    time.Time
+}
+
+var _ = Suite(&bootHistorySuite{})
+
+func (s *bootHistorySuite) SetUpTest(c *C) {
+	s.baseBootenvSuite.SetUpTest(c)
+
+	s.bootloader = bootloadertest.Mock("mock", c.MkDir())
+	s.forceBootloader(s.bootloader)
+
+	s.bootID = "boot-id-1"
+	s.AddCleanup(boot.MockBootID(func() (string, error) { return s.bootID, nil }))
+	s.now = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.AddCleanup(boot.MockTimeNow(func() time.Time { return s.now }))
+
+	s.bootloader.BootVars["snap_core"] = "core_1.snap"
+	s.bootloader.BootVars["snap_kernel"] = "pc-kernel_1.snap"
+}
+
+func (s *bootHistorySuite) nextBoot(bootID string) {
+	s.bootID = bootID
+	s.now = s.now.Add(time.Hour)
+}
+
+func (s *bootHistorySuite) TestBootHistoryEmpty(c *C) {
+	entries, err := boot.BootHistory()
+	c.Assert(err, IsNil)
+	c.Check(entries, HasLen, 0)
+}
+
+func (s *bootHistorySuite) TestRecordBootSuccessfulTry(c *C) {
+	coreDev := boottest.MockDevice("some-snap")
+
+	err := boot.RecordBoot(coreDev, "run")
+	c.Assert(err, IsNil)
+	c.Assert(boot.MarkBootSuccessful(coreDev), IsNil)
+	// snapd restarting in the same boot does not record it again
+	err = boot.RecordBoot(coreDev, "run")
+	c.Assert(err, IsNil)
+
+	// core_2 is being tried
+	s.nextBoot("boot-id-2")
+	s.bootloader.BootVars["snap_mode"] = boot.TryingStatus
+	s.bootloader.BootVars["snap_try_core"] = "core_2.snap"
+	err = boot.RecordBoot(coreDev, "run")
+	c.Assert(err, IsNil)
+
+	entries, err := boot.BootHistory()
+	c.Assert(err, IsNil)
+	c.Check(entries, DeepEquals, []*boot.BootHistoryEntry{{
+		BootID:  "boot-id-1",
+		Time:    time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Mode:    "run",
+		Kernel:  "pc-kernel_1.snap",
+		Base:    "core_1.snap",
+		Outcome: boot.BootOutcomeSuccess,
+	}, {
+		BootID:  "boot-id-2",
+		Time:    time.Date(2026, 10, 1, 13, 0, 0, 0, time.UTC),
+		Mode:    "run",
+		Kernel:  "pc-kernel_1.snap",
+		Base:    "core_2.snap",
+		Try:     true,
+		Outcome: boot.BootOutcomeTrying,
+	}})
+
+	c.Assert(boot.MarkBootSuccessful(coreDev), IsNil)
+	entries, err = boot.BootHistory()
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Check(entries[1].Outcome, Equals, boot.BootOutcomeSuccess)
+}
+
+func (s *bootHistorySuite) TestRecordBootReverted(c *C) {
+	coreDev := boottest.MockDevice("some-snap")
+
+	s.bootloader.BootVars["snap_mode"] = boot.TryingStatus
+	s.bootloader.BootVars["snap_try_kernel"] = "pc-kernel_2.snap"
+	err := boot.RecordBoot(coreDev, "run")
+	c.Assert(err, IsNil)
+
+	// the boot was never marked as successful, the bootloader went
+	// back to pc-kernel_1
+	s.nextBoot("boot-id-2")
+	s.bootloader.BootVars["snap_mode"] = ""
+	s.bootloader.BootVars["snap_try_kernel"] = ""
+	err = boot.RecordBoot(coreDev, "run")
+	c.Assert(err, IsNil)
+
+	entries, err := boot.BootHistory()
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Check(entries[0].Kernel, Equals, "pc-kernel_2.snap")
+	c.Check(entries[0].Try, Equals, true)
+	c.Check(entries[0].Outcome, Equals, boot.BootOutcomeReverted)
+	c.Check(entries[1].Kernel, Equals, "pc-kernel_1.snap")
+	c.Check(entries[1].Try, Equals, false)
+	c.Check(entries[1].Outcome, Equals, boot.BootOutcomeBooted)
+}
+
+func (s *bootHistorySuite) TestRecordBootRotates(c *C) {
+	s.AddCleanup(boot.MockMaxBootHistoryEntries(2))
+	coreDev := boottest.MockDevice("some-snap")
+
+	for _, bootID := range []string{"boot-id-1", "boot-id-2", "boot-id-3"} {
+		s.nextBoot(bootID)
+		c.Assert(boot.RecordBoot(coreDev, "run"), IsNil)
+	}
+
+	entries, err := boot.BootHistory()
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Check(entries[0].BootID, Equals, "boot-id-2")
+	c.Check(entries[1].BootID, Equals, "boot-id-3")
+	c.Check(filepath.Join(dirs.SnapHistoryDir, "boot-history.json"), testutil.FilePresent)
+}
+
+func (s *bootHistorySuite) TestBootHistoryCorrupted(c *C) {
+	c.Assert(os.MkdirAll(dirs.SnapHistoryDir, 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dirs.SnapHistoryDir, "boot-history.json"), []byte("{"), 0644), IsNil)
+
+	_, err := boot.BootHistory()
+	c.Assert(err, ErrorMatches, "cannot decode boot history: .*")
+	err = boot.RecordBoot(boottest.MockDevice("some-snap"), "run")
+	c.Assert(err, ErrorMatches, "cannot decode boot history: .*")
+}
+
+func (s *bootenv20Suite) TestRecordBootRecoverMode(c *C) {
+	s.AddCleanup(boot.MockBootID(func() (string, error) { return "boot-id-1", nil }))
+	// ubuntu-save is available
+	c.Assert(os.MkdirAll(dirs.SnapSaveDir, 0755), IsNil)
+
+	m := &boot.Modeenv{
+		Mode:           "recover",
+		RecoverySystem: "20201212",
+	}
+	c.Assert(m.WriteTo(""), IsNil)
+	coreDev := boottest.MockUC20Device("recover", nil)
+
+	err := boot.RecordBoot(coreDev, "recover")
+	c.Assert(err, IsNil)
+
+	entries, err := boot.BootHistory()
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Check(entries[0].Mode, Equals, "recover")
+	c.Check(entries[0].RecoverySystem, Equals, "20201212")
+	c.Check(entries[0].Kernel, Equals, "")
+	c.Check(entries[0].Base, Equals, "")
+	c.Check(entries[0].Outcome, Equals, boot.BootOutcomeBooted)
+	c.Check(filepath.Join(dirs.SnapSaveDir, "boot-history.json"), testutil.FilePresent)
+}
+
+func (s *bootenv20Suite) TestRecordBootRecoverModeNoSaveBindMount(c *C) {
+	s.AddCleanup(boot.MockBootID(func() (string, error) { return "boot-id-1", nil }))
+	// ubuntu-save is mounted by the initramfs, but not yet bind mounted
+	// on the ephemeral ubuntu-data
+	c.Assert(os.MkdirAll(boot.InitramfsUbuntuSaveDir, 0755), IsNil)
+
+	m := &boot.Modeenv{
+		Mode:           "recover",
+		RecoverySystem: "20201212",
+	}
+	c.Assert(m.WriteTo(""), IsNil)
+	coreDev := boottest.MockUC20Device("recover", nil)
+
+	err := boot.RecordBoot(coreDev, "recover")
+	c.Assert(err, IsNil)
+
+	entries, err := boot.BootHistory()
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Check(entries[0].Mode, Equals, "recover")
+	c.Check(filepath.Join(boot.InitramfsUbuntuSaveDir, "boot-history.json"), testutil.FilePresent)
+	c.Check(filepath.Join(dirs.SnapHistoryDir, "boot-history.json"), testutil.FileAbsent)
+}
+
+func (s *bootenv20Suite) TestRecordBootTryingKernel20(c *C) {
+	s.AddCleanup(boot.MockBootID(func() (string, error) { return "boot-id-1", nil }))
+	coreDev := boottest.MockUC20Device("", nil)
+
+	r := setupUC20Bootenv(
+		c,
+		s.bootloader,
+		s.normalTryingKernelState,
+	)
+	defer r()
+
+	err := boot.RecordBoot(coreDev, "run")
+	c.Assert(err, IsNil)
+
+	entries, err := boot.BootHistory()
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Check(entries[0].Kernel, Equals, s.kern2.Filename())
+	c.Check(entries[0].Base, Equals, s.base1.Filename())
+	c.Check(entries[0].Try, Equals, true)
+	c.Check(entries[0].Outcome, Equals, boot.BootOutcomeTrying)
+}
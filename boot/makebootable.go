@@ -188,19 +188,43 @@ func makeBootable20(model *asserts.Model, rootdir string, bootWith *BootableSet)
 		return fmt.Errorf("cannot set recovery environment: %v", err)
 	}
 
+	return makeRecoverySystemBootable(bl, rootdir, bootWith.RecoverySystemDir, bootWith.Kernel, bootWith.KernelPath)
+}
+
+// RecoverySystemBootableSet holds the kernel of a recovery system that is
+// added to an already bootable seed.
+type RecoverySystemBootableSet struct {
+	Kernel     *snap.Info
+	KernelPath string
+}
+
+// MakeRecoverySystemBootable prepares a recovery system under the given
+// relative directory of an already bootable seed at rootdir, such that it
+// can be booted by the recovery bootloader.
+func MakeRecoverySystemBootable(rootdir string, relativeRecoverySystemDir string, bootWith *RecoverySystemBootableSet) error {
+	opts := &bootloader.Options{
+		Role: bootloader.RoleRecovery,
+	}
+	bl, err := bootloader.Find(rootdir, opts)
+	if err != nil {
+		return fmt.Errorf("internal error: cannot find bootloader: %v", err)
+	}
+	return makeRecoverySystemBootable(bl, rootdir, relativeRecoverySystemDir, bootWith.Kernel, bootWith.KernelPath)
+}
+
+func makeRecoverySystemBootable(bl bootloader.Bootloader, rootdir, relativeRecoverySystemDir string, kernel *snap.Info, kernelPath string) error {
 	// on e.g. ARM we need to extract the kernel assets on the recovery
 	// system as well, but the bootloader does not load any environment from
 	// the recovery system
 	erkbl, ok := bl.(bootloader.ExtractedRecoveryKernelImageBootloader)
 	if ok {
-		kernelf, err := snapfile.Open(bootWith.KernelPath)
+		kernelf, err := snapfile.Open(kernelPath)
 		if err != nil {
 			return err
 		}
-
 		err = erkbl.ExtractRecoveryKernelAssets(
-			bootWith.RecoverySystemDir,
-			bootWith.Kernel,
+			relativeRecoverySystemDir,
+			kernel,
 			kernelf,
 		)
 		if err != nil {
@@ -214,14 +238,14 @@ func makeBootable20(model *asserts.Model, rootdir string, bootWith *BootableSet)
 	if !ok {
 		return fmt.Errorf("cannot use %s bootloader: does not support recovery systems", bl.Name())
 	}
-	kernelPath, err := filepath.Rel(rootdir, bootWith.KernelPath)
+	relKernelPath, err := filepath.Rel(rootdir, kernelPath)
 	if err != nil {
 		return fmt.Errorf("cannot construct kernel boot path: %v", err)
 	}
 	recoveryBlVars := map[string]string{
-		"snapd_recovery_kernel": filepath.Join("/", kernelPath),
+		"snapd_recovery_kernel": filepath.Join("/", relKernelPath),
 	}
-	if err := rbl.SetRecoverySystemEnv(bootWith.RecoverySystemDir, recoveryBlVars); err != nil {
+	if err := rbl.SetRecoverySystemEnv(relativeRecoverySystemDir, recoveryBlVars); err != nil {
 		return fmt.Errorf("cannot set recovery system environment: %v", err)
 	}
 	return nil
@@ -247,6 +247,35 @@ version: 5.0
 	c.Check(systemGenv.Get("snapd_recovery_kernel"), Equals, "/snaps/pc-kernel_5.snap")
 }
 
+func (s *makeBootable20Suite) TestMakeRecoverySystemBootable(c *C) {
+	bootloader.Force(nil)
+
+	// the seed is already bootable with grub
+	err := os.MkdirAll(filepath.Join(s.rootdir, "EFI/ubuntu"), 0755)
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(filepath.Join(s.rootdir, "EFI/ubuntu/grub.cfg"), nil, 0644)
+	c.Assert(err, IsNil)
+
+	label := "20261018"
+	recoverySystemDir := filepath.Join("/systems", label)
+	err = os.MkdirAll(filepath.Join(s.rootdir, recoverySystemDir), 0755)
+	c.Assert(err, IsNil)
+
+	bootWith := &boot.RecoverySystemBootableSet{
+		Kernel:     snaptest.MockInfo(c, "name: pc-kernel\ntype: kernel\nversion: 5.0\n", &snap.SideInfo{Revision: snap.R(7)}),
+		KernelPath: filepath.Join(s.rootdir, "snaps/pc-kernel_7.snap"),
+	}
+	err = boot.MakeRecoverySystemBootable(s.rootdir, recoverySystemDir, bootWith)
+	c.Assert(err, IsNil)
+
+	systemGenv := grubenv.NewEnv(filepath.Join(s.rootdir, recoverySystemDir, "grubenv"))
+	c.Assert(systemGenv.Load(), IsNil)
+	c.Check(systemGenv.Get("snapd_recovery_kernel"), Equals, "/snaps/pc-kernel_7.snap")
+
+	// the main recovery bootloader environment was not touched
+	c.Check(filepath.Join(s.rootdir, "EFI/ubuntu/grubenv"), testutil.FileAbsent)
+}
+
 func (s *makeBootable20Suite) TestMakeBootable20UnsetRecoverySystemLabelError(c *C) {
 	model := boottest.MakeMockUC20Model()
 
@@ -449,6 +478,7 @@ version: 5.0
 				secboot.NewLoadChain(shim, secboot.NewLoadChain(grub, secboot.NewLoadChain(runGrub, secboot.NewLoadChain(runKernel)))),
 			})
 			c.Assert(params.ModelParams[0].KernelCmdlines, DeepEquals, []string{
+				"snapd_recovery_mode=factory-reset snapd_recovery_system=20191216 console=ttyS0 console=tty1 panic=-1",
 				"snapd_recovery_mode=recover snapd_recovery_system=20191216 console=ttyS0 console=tty1 panic=-1",
 				"snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1",
 			})
@@ -457,6 +487,7 @@ version: 5.0
 				secboot.NewLoadChain(shim, secboot.NewLoadChain(grub, secboot.NewLoadChain(kernel))),
 			})
 			c.Assert(params.ModelParams[0].KernelCmdlines, DeepEquals, []string{
+				"snapd_recovery_mode=factory-reset snapd_recovery_system=20191216 console=ttyS0 console=tty1 panic=-1",
 				"snapd_recovery_mode=recover snapd_recovery_system=20191216 console=ttyS0 console=tty1 panic=-1",
 			})
 		default:
@@ -790,6 +821,7 @@ version: 5.0
 			secboot.NewLoadChain(shim, secboot.NewLoadChain(grub, secboot.NewLoadChain(runGrub, secboot.NewLoadChain(runKernel)))),
 		})
 		c.Assert(params.ModelParams[0].KernelCmdlines, DeepEquals, []string{
+			"snapd_recovery_mode=factory-reset snapd_recovery_system=20191216 console=ttyS0 console=tty1 panic=-1",
 			"snapd_recovery_mode=recover snapd_recovery_system=20191216 console=ttyS0 console=tty1 panic=-1",
 			"snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1",
 		})
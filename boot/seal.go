@@ -46,6 +46,8 @@ var (
 	secbootSealKeys   = secboot.SealKeys
 	secbootResealKeys = secboot.ResealKeys
 
+	secbootDescribePCRProtectionProfile = secboot.DescribePCRProtectionProfile
+
 	seedReadSystemEssential = seed.ReadSystemEssential
 )
 
@@ -351,22 +353,41 @@ func resealKeyToModeenvUsingFDESetupHookImpl(rootdir string, model *asserts.Mode
 	return nil
 }
 
-func resealKeyToModeenvSecboot(rootdir string, model *asserts.Model, modeenv *Modeenv, expectReseal bool) error {
+// kernelCommandLinesForResealWithFallback returns the run mode kernel command
+// lines listed in the modeenv, or falls back to the current command line if
+// the modeenv does not track any.
+func kernelCommandLinesForResealWithFallback(model *asserts.Model, modeenv *Modeenv) ([]string, error) {
+	if len(modeenv.CurrentKernelCommandLines) > 0 {
+		return []string(modeenv.CurrentKernelCommandLines), nil
+	}
+	// fallback for when reseal is called before mark boot successful
+	// has populated the command lines in the modeenv
+	cmdline, err := ComposeCommandLine(model)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compose the run mode command line: %v", err)
+	}
+	return []string{cmdline}, nil
+}
+
+// bootChainsForReseal returns the run mode and recovery boot chains that the
+// keys are resealed to given the modeenv, along with the names of the
+// bootloaders by their role.
+func bootChainsForReseal(model *asserts.Model, modeenv *Modeenv) (runChains, recoveryChains []bootChain, roleToBlName map[bootloader.Role]string, err error) {
 	// build the recovery mode boot chain
 	rbl, err := bootloader.Find(InitramfsUbuntuSeedDir, &bootloader.Options{
 		Role: bootloader.RoleRecovery,
 	})
 	if err != nil {
-		return fmt.Errorf("cannot find the recovery bootloader: %v", err)
+		return nil, nil, nil, fmt.Errorf("cannot find the recovery bootloader: %v", err)
 	}
 	tbl, ok := rbl.(bootloader.TrustedAssetsBootloader)
 	if !ok {
 		// TODO:UC20: later the exact kind of bootloaders we expect here might change
-		return fmt.Errorf("internal error: sealed keys but not a trusted assets bootloader")
+		return nil, nil, nil, fmt.Errorf("internal error: sealed keys but not a trusted assets bootloader")
 	}
-	recoveryBootChains, err := recoveryBootChainsForSystems(modeenv.CurrentRecoverySystems, tbl, model, modeenv)
+	recoveryChains, err = recoveryBootChainsForSystems(modeenv.CurrentRecoverySystems, tbl, model, modeenv)
 	if err != nil {
-		return fmt.Errorf("cannot compose recovery boot chains: %v", err)
+		return nil, nil, nil, fmt.Errorf("cannot compose recovery boot chains: %v", err)
 	}
 
 	// build the run mode boot chains
@@ -375,16 +396,29 @@ func resealKeyToModeenvSecboot(rootdir string, model *asserts.Model, modeenv *Mo
 		NoSlashBoot: true,
 	})
 	if err != nil {
-		return fmt.Errorf("cannot find the bootloader: %v", err)
+		return nil, nil, nil, fmt.Errorf("cannot find the bootloader: %v", err)
 	}
-	cmdline, err := ComposeCommandLine(model)
+	cmdlines, err := kernelCommandLinesForResealWithFallback(model, modeenv)
 	if err != nil {
-		return fmt.Errorf("cannot compose the run mode command line: %v", err)
+		return nil, nil, nil, err
 	}
 
-	runModeBootChains, err := runModeBootChains(rbl, bl, model, modeenv, []string{cmdline})
+	runChains, err = runModeBootChains(rbl, bl, model, modeenv, cmdlines)
 	if err != nil {
-		return fmt.Errorf("cannot compose run mode boot chains: %v", err)
+		return nil, nil, nil, fmt.Errorf("cannot compose run mode boot chains: %v", err)
+	}
+
+	roleToBlName = map[bootloader.Role]string{
+		bootloader.RoleRecovery: rbl.Name(),
+		bootloader.RoleRunMode:  bl.Name(),
+	}
+	return runChains, recoveryChains, roleToBlName, nil
+}
+
+func resealKeyToModeenvSecboot(rootdir string, model *asserts.Model, modeenv *Modeenv, expectReseal bool) error {
+	runModeBootChains, recoveryBootChains, roleToBlName, err := bootChainsForReseal(model, modeenv)
+	if err != nil {
+		return err
 	}
 
 	// reseal the run object
@@ -401,11 +435,6 @@ func resealKeyToModeenvSecboot(rootdir string, model *asserts.Model, modeenv *Mo
 	pbcJSON, _ := json.Marshal(pbc)
 	logger.Debugf("resealing (%d) to boot chains: %s", nextCount, pbcJSON)
 
-	roleToBlName := map[bootloader.Role]string{
-		bootloader.RoleRecovery: rbl.Name(),
-		bootloader.RoleRunMode:  bl.Name(),
-	}
-
 	saveFDEDir := dirs.SnapFDEDirUnderSave(dirs.SnapSaveDirUnder(rootdir))
 	authKeyFile := filepath.Join(saveFDEDir, "tpm-policy-auth-key")
 	if err := resealRunObjectKeys(pbc, authKeyFile, roleToBlName); err != nil {
@@ -494,11 +523,22 @@ func resealFallbackObjectKeys(pbc predictableBootChains, authKeyFile string, rol
 
 func recoveryBootChainsForSystems(systems []string, trbl bootloader.TrustedAssetsBootloader, model *asserts.Model, modeenv *Modeenv) (chains []bootChain, err error) {
 	for _, system := range systems {
-		// get the command line
+		// get the command lines, the recovery keys are also used to
+		// access ubuntu-save when factory resetting the device
 		cmdline, err := ComposeRecoveryCommandLine(model, system)
 		if err != nil {
 			return nil, fmt.Errorf("cannot obtain recovery kernel command line: %v", err)
 		}
+		factoryResetCmdline, err := ComposeFactoryResetCommandLine(model, system)
+		if err != nil {
+			return nil, fmt.Errorf("cannot obtain factory-reset kernel command line: %v", err)
+		}
+		cmdlines := []string{cmdline}
+		// both command lines are empty when the boot config is not
+		// managed
+		if factoryResetCmdline != cmdline {
+			cmdlines = append(cmdlines, factoryResetCmdline)
+		}
 
 		// get kernel information from seed
 		perf := timings.New(nil)
@@ -535,7 +575,7 @@ func recoveryBootChainsForSystems(systems []string, trbl bootloader.TrustedAsset
 			AssetChain:     assetChain,
 			Kernel:         seedKernel.SnapName(),
 			KernelRevision: kernelRev,
-			KernelCmdlines: []string{cmdline},
+			KernelCmdlines: cmdlines,
 			model:          model,
 			kernelBootFile: kbf,
 		})
@@ -175,6 +175,7 @@ func (s *sealSuite) TestSealKeyToModeenv(c *C) {
 								secboot.NewLoadChain(runKernel)))),
 				})
 				c.Assert(params.ModelParams[0].KernelCmdlines, DeepEquals, []string{
+					"snapd_recovery_mode=factory-reset snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 					"snapd_recovery_mode=recover snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 					"snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1",
 				})
@@ -185,6 +186,7 @@ func (s *sealSuite) TestSealKeyToModeenv(c *C) {
 							secboot.NewLoadChain(kernel))),
 				})
 				c.Assert(params.ModelParams[0].KernelCmdlines, DeepEquals, []string{
+					"snapd_recovery_mode=factory-reset snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 					"snapd_recovery_mode=recover snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 				})
 			default:
@@ -234,6 +236,7 @@ func (s *sealSuite) TestSealKeyToModeenv(c *C) {
 				Kernel:         "pc-kernel",
 				KernelRevision: "1",
 				KernelCmdlines: []string{
+					"snapd_recovery_mode=factory-reset snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 					"snapd_recovery_mode=recover snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 				},
 			},
@@ -292,6 +295,7 @@ func (s *sealSuite) TestSealKeyToModeenv(c *C) {
 				Kernel:         "pc-kernel",
 				KernelRevision: "1",
 				KernelCmdlines: []string{
+					"snapd_recovery_mode=factory-reset snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 					"snapd_recovery_mode=recover snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 				},
 			},
@@ -399,6 +403,7 @@ func (s *sealSuite) TestResealKeyToModeenv(c *C) {
 			switch resealKeysCalls {
 			case 1:
 				c.Assert(params.ModelParams[0].KernelCmdlines, DeepEquals, []string{
+					"snapd_recovery_mode=factory-reset snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 					"snapd_recovery_mode=recover snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 					"snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1",
 				})
@@ -406,6 +411,7 @@ func (s *sealSuite) TestResealKeyToModeenv(c *C) {
 				c.Assert(params.ModelParams[0].EFILoadChains, HasLen, 6)
 			case 2:
 				c.Assert(params.ModelParams[0].KernelCmdlines, DeepEquals, []string{
+					"snapd_recovery_mode=factory-reset snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 					"snapd_recovery_mode=recover snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 				})
 				// load chains
@@ -529,6 +535,7 @@ func (s *sealSuite) TestResealKeyToModeenv(c *C) {
 				Kernel:         "pc-kernel",
 				KernelRevision: "1",
 				KernelCmdlines: []string{
+					"snapd_recovery_mode=factory-reset snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 					"snapd_recovery_mode=recover snapd_recovery_system=20200825 console=ttyS0 console=tty1 panic=-1",
 				},
 			},
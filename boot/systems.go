@@ -0,0 +1,94 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package boot
+
+import (
+	"fmt"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/strutil"
+)
+
+// AddRecoverySystem records the given recovery system, which must already be
+// present in the seed and made bootable, as one of the current recovery
+// systems in the modeenv. The encryption keys are resealed so that the new
+// system is part of the recovery boot chains before it gets recorded.
+func AddRecoverySystem(dev Device, systemLabel string) error {
+	if !dev.HasModeenv() {
+		return fmt.Errorf("cannot add a recovery system on a system without modeenv")
+	}
+	if systemLabel == "" {
+		return fmt.Errorf("internal error: system label is unset")
+	}
+	modeenv, err := ReadModeenv("")
+	if err != nil {
+		return err
+	}
+	if strutil.ListContains(modeenv.CurrentRecoverySystems, systemLabel) {
+		return nil
+	}
+	newModeenv, err := modeenv.Copy()
+	if err != nil {
+		return err
+	}
+	newModeenv.CurrentRecoverySystems = append(newModeenv.CurrentRecoverySystems, systemLabel)
+
+	// reseal first, the keys being sealed to a superset of the systems
+	// listed in the modeenv is harmless, the reverse is not
+	const expectReseal = true
+	if err := resealKeyToModeenv(dirs.GlobalRootDir, dev.Model(), newModeenv, expectReseal); err != nil {
+		return fmt.Errorf("cannot reseal the encryption key: %v", err)
+	}
+	return newModeenv.Write()
+}
+
+// DropRecoverySystem removes the given recovery system from the list of
+// current recovery systems in the modeenv and reseals the encryption keys
+// accordingly. It is the reverse of AddRecoverySystem.
+func DropRecoverySystem(dev Device, systemLabel string) error {
+	if !dev.HasModeenv() {
+		return fmt.Errorf("cannot drop a recovery system on a system without modeenv")
+	}
+	modeenv, err := ReadModeenv("")
+	if err != nil {
+		return err
+	}
+	if !strutil.ListContains(modeenv.CurrentRecoverySystems, systemLabel) {
+		return nil
+	}
+	systems := make([]string, 0, len(modeenv.CurrentRecoverySystems)-1)
+	for _, label := range modeenv.CurrentRecoverySystems {
+		if label != systemLabel {
+			systems = append(systems, label)
+		}
+	}
+	modeenv.CurrentRecoverySystems = systems
+
+	// update the modeenv first, so that the keys remain sealed to a
+	// superset of the listed systems if resealing fails
+	if err := modeenv.Write(); err != nil {
+		return err
+	}
+	const expectReseal = true
+	if err := resealKeyToModeenv(dirs.GlobalRootDir, dev.Model(), modeenv, expectReseal); err != nil {
+		return fmt.Errorf("cannot reseal the encryption key: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package boot_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/boot/boottest"
+	"github.com/snapcore/snapd/dirs"
+)
+
+type systemsSuite struct {
+	baseBootenvSuite
+
+	uc20dev boot.Device
+
+	resealCalls   int
+	resealSystems [][]string
+	resealErr     error
+}
+
+var _ = Suite(&systemsSuite{})
+
+func (s *systemsSuite) SetUpTest(c *C) {
+	s.baseBootenvSuite.SetUpTest(c)
+
+	s.uc20dev = boottest.MockUC20Device("run", boottest.MakeMockUC20Model())
+
+	s.resealCalls = 0
+	s.resealSystems = nil
+	s.resealErr = nil
+	restore := boot.MockResealKeyToModeenvUsingFDESetupHook(func(rootdir string, model *asserts.Model, modeenv *boot.Modeenv, expectReseal bool) error {
+		s.resealCalls++
+		c.Check(expectReseal, Equals, true)
+		s.resealSystems = append(s.resealSystems, modeenv.CurrentRecoverySystems)
+		return s.resealErr
+	})
+	s.AddCleanup(restore)
+
+	m := &boot.Modeenv{
+		Mode:                   "run",
+		CurrentRecoverySystems: []string{"20200825"},
+	}
+	c.Assert(m.WriteTo(""), IsNil)
+}
+
+func (s *systemsSuite) stampSealedKeys(c *C) {
+	marker := filepath.Join(dirs.SnapFDEDirUnder(dirs.GlobalRootDir), "sealed-keys")
+	c.Assert(os.MkdirAll(filepath.Dir(marker), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(marker, []byte("fde-setup-hook"), 0644), IsNil)
+}
+
+func (s *systemsSuite) currentRecoverySystems(c *C) []string {
+	m, err := boot.ReadModeenv("")
+	c.Assert(err, IsNil)
+	return m.CurrentRecoverySystems
+}
+
+func (s *systemsSuite) TestAddRecoverySystemNoSealedKeys(c *C) {
+	err := boot.AddRecoverySystem(s.uc20dev, "20261018")
+	c.Assert(err, IsNil)
+	c.Check(s.currentRecoverySystems(c), DeepEquals, []string{"20200825", "20261018"})
+	c.Check(s.resealCalls, Equals, 0)
+
+	// adding it again is a noop
+	err = boot.AddRecoverySystem(s.uc20dev, "20261018")
+	c.Assert(err, IsNil)
+	c.Check(s.currentRecoverySystems(c), DeepEquals, []string{"20200825", "20261018"})
+}
+
+func (s *systemsSuite) TestAddRecoverySystemReseals(c *C) {
+	s.stampSealedKeys(c)
+
+	err := boot.AddRecoverySystem(s.uc20dev, "20261018")
+	c.Assert(err, IsNil)
+	c.Check(s.currentRecoverySystems(c), DeepEquals, []string{"20200825", "20261018"})
+	c.Check(s.resealCalls, Equals, 1)
+	c.Check(s.resealSystems, DeepEquals, [][]string{{"20200825", "20261018"}})
+}
+
+func (s *systemsSuite) TestAddRecoverySystemResealError(c *C) {
+	s.stampSealedKeys(c)
+	s.resealErr = fmt.Errorf("reseal failed")
+
+	err := boot.AddRecoverySystem(s.uc20dev, "20261018")
+	c.Assert(err, ErrorMatches, "cannot reseal the encryption key: reseal failed")
+	// the modeenv is left untouched
+	c.Check(s.currentRecoverySystems(c), DeepEquals, []string{"20200825"})
+}
+
+func (s *systemsSuite) TestAddRecoverySystemNotUC20(c *C) {
+	err := boot.AddRecoverySystem(boottest.MockDevice("pc-kernel"), "20261018")
+	c.Assert(err, ErrorMatches, "cannot add a recovery system on a system without modeenv")
+}
+
+func (s *systemsSuite) TestDropRecoverySystem(c *C) {
+	s.stampSealedKeys(c)
+
+	err := boot.AddRecoverySystem(s.uc20dev, "20261018")
+	c.Assert(err, IsNil)
+
+	err = boot.DropRecoverySystem(s.uc20dev, "20261018")
+	c.Assert(err, IsNil)
+	c.Check(s.currentRecoverySystems(c), DeepEquals, []string{"20200825"})
+	c.Check(s.resealSystems, DeepEquals, [][]string{
+		{"20200825", "20261018"},
+		{"20200825"},
+	})
+
+	// dropping an unknown system is a noop
+	err = boot.DropRecoverySystem(s.uc20dev, "20261018")
+	c.Assert(err, IsNil)
+	c.Check(s.resealCalls, Equals, 2)
+}
+
+func (s *systemsSuite) TestDropRecoverySystemResealError(c *C) {
+	s.stampSealedKeys(c)
+
+	err := boot.AddRecoverySystem(s.uc20dev, "20261018")
+	c.Assert(err, IsNil)
+
+	s.resealErr = fmt.Errorf("reseal failed")
+	err = boot.DropRecoverySystem(s.uc20dev, "20261018")
+	c.Assert(err, ErrorMatches, "cannot reseal the encryption key: reseal failed")
+	// the system is no longer listed even though the keys still cover it
+	c.Check(s.currentRecoverySystems(c), DeepEquals, []string{"20200825"})
+}
@@ -0,0 +1,56 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package assets_test
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/bootloader/assets"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type sdbootAssetsTestSuite struct{}
+
+var _ = Suite(&sdbootAssetsTestSuite{})
+
+func (s *sdbootAssetsTestSuite) TestLoaderConf(c *C) {
+	a := assets.Internal("systemd-boot-loader.conf")
+	c.Assert(a, NotNil)
+	c.Check(bytes.HasPrefix(a, []byte("# Snapd-Boot-Config-Edition: 1\n")), Equals, true)
+	// the kernel command line must not be editable from the boot menu
+	c.Check(string(a), testutil.Contains, "\neditor no\n")
+}
+
+func (s *sdbootAssetsTestSuite) TestCmdlineSnippetEditions(c *C) {
+	snip := assets.SnippetForEdition("systemd-boot-loader.conf:static-cmdline", 1)
+	c.Assert(snip, NotNil)
+	c.Check(snip, DeepEquals, []byte("console=ttyS0 console=tty1 panic=-1"))
+}
+
+func (s *sdbootAssetsTestSuite) TestAssetsWereRegenerated(c *C) {
+	assetData := assets.Internal("systemd-boot-loader.conf")
+	c.Assert(assetData, NotNil)
+	data, err := ioutil.ReadFile("data/systemd-boot-loader.conf")
+	c.Assert(err, IsNil)
+	c.Check(assetData, DeepEquals, data)
+}
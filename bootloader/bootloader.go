@@ -268,6 +268,7 @@ var (
 		newGrub,
 		newAndroidBoot,
 		newLk,
+		newSdboot,
 	}
 )
 
@@ -118,6 +118,12 @@ func (s *bootenvTestSuite) TestInstallBootloaderConfigFromGadget(c *C) {
 		},
 		{name: "androidboot", gadgetFile: "androidboot.conf", sysFile: "/boot/androidboot/androidboot.env"},
 		{name: "lk", gadgetFile: "lk.conf", sysFile: "/boot/lk/snapbootsel.bin", opts: &bootloader.Options{PrepareImageTime: true}},
+		{
+			name:       "systemd-boot",
+			gadgetFile: "systemd-boot.conf",
+			sysFile:    "/loader/loader.conf",
+			opts:       &bootloader.Options{Role: bootloader.RoleRecovery},
+		},
 	} {
 		mockGadgetDir := c.MkDir()
 		rootDir := c.MkDir()
@@ -312,6 +318,16 @@ func (s *bootenvTestSuite) TestBootloaderFind(c *C) {
 			name: "lk", sysFile: "/boot/lk/snapbootsel.bin",
 			expName: "lk", opts: &bootloader.Options{PrepareImageTime: true},
 		},
+		{
+			name: "systemd-boot", sysFile: "/loader/loader.conf",
+			opts:    &bootloader.Options{Role: bootloader.RoleRecovery},
+			expName: "systemd-boot",
+		},
+		{
+			name: "systemd-boot", sysFile: "/loader/entries.srel",
+			opts:    &bootloader.Options{Role: bootloader.RoleRunMode, NoSlashBoot: true},
+			expName: "systemd-boot",
+		},
 	} {
 		c.Logf("tc: %v", tc.name)
 		rootDir := c.MkDir()
@@ -339,6 +355,7 @@ func (s *bootenvTestSuite) TestBootloaderForGadget(c *C) {
 		{name: "uboot", gadgetFile: "uboot.conf", expName: "uboot"},
 		{name: "androidboot", gadgetFile: "androidboot.conf", expName: "androidboot"},
 		{name: "lk", gadgetFile: "lk.conf", expName: "lk"},
+		{name: "systemd-boot", gadgetFile: "systemd-boot.conf", opts: &bootloader.Options{Role: bootloader.RoleRecovery}, expName: "systemd-boot"},
 	} {
 		c.Logf("tc: %v", tc.name)
 		gadgetDir := c.MkDir()
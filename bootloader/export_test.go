@@ -74,6 +74,10 @@ func MockGrubFiles(c *C, rootdir string) {
 	c.Assert(err, IsNil)
 }
 
+func NewSdboot(rootdir string, opts *Options) Bootloader {
+	return newSdboot(rootdir, opts)
+}
+
 func NewLk(rootdir string, opts *Options) ExtractedRecoveryKernelImageBootloader {
 	if opts == nil {
 		opts = &Options{
@@ -0,0 +1,818 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package bootloader
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/snapcore/snapd/bootloader/efi"
+	"github.com/snapcore/snapd/bootloader/grubenv"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+)
+
+// sanity - sdboot implements the required interfaces
+var (
+	_ Bootloader                             = (*sdboot)(nil)
+	_ RecoveryAwareBootloader                = (*sdboot)(nil)
+	_ ExtractedRecoveryKernelImageBootloader = (*sdboot)(nil)
+	_ ExtractedRunKernelImageBootloader      = (*sdboot)(nil)
+	_ TrustedAssetsBootloader                = (*sdboot)(nil)
+)
+
+const (
+	// sdbootLoaderConfAsset is the name of the internal asset carrying the
+	// loader.conf installed on ubuntu-seed
+	sdbootLoaderConfAsset = "systemd-boot-loader.conf"
+
+	// sdbootEntrySelectedVar is the EFI variable in which systemd-boot
+	// records the identifier of the boot entry it has booted
+	sdbootEntrySelectedVar = "LoaderEntrySelected-4a67b082-0a4c-41cf-b6c7-440b29bb8c4f"
+
+	sdbootRunEntry      = "snapd-run"
+	sdbootTryRunEntry   = "snapd-try-run"
+	sdbootRecoveryEntry = "snapd-recovery"
+
+	// sdbootTryKernelVar is the environment variable holding the file name
+	// of the kernel snap enabled for trying
+	sdbootTryKernelVar = "snap_try_kernel"
+)
+
+// sdbootTryRunEntryRe matches the file name of the try boot entry, including
+// the boot counting suffix maintained by systemd-boot, ie.
+// snapd-try-run+<tries-left>[-<tries-done>].conf
+var sdbootTryRunEntryRe = regexp.MustCompile(`^` + sdbootTryRunEntry + `(\+([0-9]+)(-[0-9]+)?)?\.conf$`)
+
+// sdboot implements a bootloader on top of systemd-boot and unified kernel
+// images, that is kernel.efi files carrying the kernel, the initrd and the
+// EFI stub.
+//
+// systemd-boot and its loader.conf live on ubuntu-seed which is the ESP,
+// the recovery systems are booted through a snapd managed boot entry on
+// ubuntu-seed. The run mode kernels and their boot entries live on
+// ubuntu-boot which needs to be an XBOOTLDR partition so that the boot
+// entries are discovered by systemd-boot. Trying a new kernel relies on the
+// boot counting support of systemd-boot.
+type sdboot struct {
+	rootdir string
+
+	basedir string
+
+	recovery              bool
+	nativePartitionLayout bool
+}
+
+// newSdboot creates a new systemd-boot bootloader object
+func newSdboot(rootdir string, opts *Options) Bootloader {
+	s := &sdboot{rootdir: rootdir}
+	if opts != nil {
+		s.recovery = opts.Role == RoleRecovery
+		s.nativePartitionLayout = opts.NoSlashBoot || s.recovery
+	}
+	if !s.nativePartitionLayout {
+		// unlike grub, there is nothing of ubuntu-boot bind mounted
+		// under /boot, use the partition where it is mounted by the
+		// initramfs
+		s.basedir = "run/mnt/ubuntu-boot"
+	}
+	return s
+}
+
+func (s *sdboot) Name() string {
+	return "systemd-boot"
+}
+
+func (s *sdboot) dir() string {
+	if s.rootdir == "" {
+		panic("internal error: unset rootdir")
+	}
+	return filepath.Join(s.rootdir, s.basedir)
+}
+
+func (s *sdboot) loaderConfFile() string {
+	return filepath.Join(s.dir(), "loader/loader.conf")
+}
+
+func (s *sdboot) entriesDir() string {
+	return filepath.Join(s.dir(), "loader/entries")
+}
+
+func (s *sdboot) entryFile(name string) string {
+	return filepath.Join(s.entriesDir(), name+".conf")
+}
+
+func (s *sdboot) envFile() string {
+	return filepath.Join(s.dir(), "loader/snapdenv")
+}
+
+func (s *sdboot) kernelsDir() string {
+	return filepath.Join(s.dir(), "EFI/ubuntu")
+}
+
+func (s *sdboot) Present() (bool, error) {
+	if s.recovery {
+		return osutil.FileExists(s.loaderConfFile()), nil
+	}
+	// the XBOOTLDR partition carries no loader.conf, but is marked as
+	// holding boot loader specification type #1 entries
+	return osutil.FileExists(filepath.Join(s.dir(), "loader/entries.srel")), nil
+}
+
+func (s *sdboot) InstallBootConfig(gadgetDir string, opts *Options) error {
+	if opts == nil || (opts.Role != RoleRecovery && opts.Role != RoleRunMode) {
+		return fmt.Errorf("cannot install %s boot config outside of recovery and run mode", s.Name())
+	}
+	if opts.Role == RoleRecovery {
+		// install managed loader config on the ESP
+		if err := genericSetBootConfigFromAsset(s.loaderConfFile(), sdbootLoaderConfAsset); err != nil {
+			return err
+		}
+	} else {
+		if err := os.MkdirAll(s.entriesDir(), 0755); err != nil {
+			return err
+		}
+		srel := filepath.Join(s.dir(), "loader/entries.srel")
+		if err := osutil.AtomicWriteFile(srel, []byte("type1\n"), 0644, 0); err != nil {
+			return err
+		}
+	}
+	// start with an empty environment
+	return grubenv.NewEnv(s.envFile()).Save()
+}
+
+func (s *sdboot) SetRecoverySystemEnv(recoverySystemDir string, values map[string]string) error {
+	if recoverySystemDir == "" {
+		return fmt.Errorf("internal error: recoverySystemDir unset")
+	}
+	recoverySystemEnv := filepath.Join(s.rootdir, recoverySystemDir, "snapdenv")
+	if err := os.MkdirAll(filepath.Dir(recoverySystemEnv), 0755); err != nil {
+		return err
+	}
+	env := grubenv.NewEnv(recoverySystemEnv)
+	for k, v := range values {
+		env.Set(k, v)
+	}
+	return env.Save()
+}
+
+func (s *sdboot) GetRecoverySystemEnv(recoverySystemDir string, key string) (string, error) {
+	if recoverySystemDir == "" {
+		return "", fmt.Errorf("internal error: recoverySystemDir unset")
+	}
+	recoverySystemEnv := filepath.Join(s.rootdir, recoverySystemDir, "snapdenv")
+	env := grubenv.NewEnv(recoverySystemEnv)
+	if err := env.Load(); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return env.Get(key), nil
+}
+
+func (s *sdboot) loadEnv() (*grubenv.Env, error) {
+	env := grubenv.NewEnv(s.envFile())
+	if err := env.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return env, nil
+}
+
+// GetBootVars returns the values of the given variables. In run mode,
+// kernel_status is not stored in the environment, but derived from the state
+// of the try boot entry.
+func (s *sdboot) GetBootVars(names ...string) (map[string]string, error) {
+	out := make(map[string]string)
+
+	env := grubenv.NewEnv(s.envFile())
+	if err := env.Load(); err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		if !s.recovery && name == "kernel_status" {
+			status, err := s.kernelStatus()
+			if err != nil {
+				return nil, err
+			}
+			out[name] = status
+			continue
+		}
+		out[name] = env.Get(name)
+	}
+
+	return out, nil
+}
+
+// SetBootVars sets the given variables. In run mode, setting kernel_status to
+// "try" adds a try boot entry for the kernel enabled with EnableTryKernel,
+// while setting it to "" removes the entry. In recovery mode, setting the
+// recovery mode or system updates the boot entry of the recovery system.
+func (s *sdboot) SetBootVars(values map[string]string) error {
+	env, err := s.loadEnv()
+	if err != nil {
+		return err
+	}
+	var kernelStatus *string
+	runEntriesChanged := false
+	recoveryEntryChanged := false
+	for k, v := range values {
+		switch {
+		case !s.recovery && k == "kernel_status":
+			v := v
+			kernelStatus = &v
+			continue
+		case !s.recovery && k == "cmdline_status" && v == "try":
+			return fmt.Errorf("cannot try kernel command line arguments with %s", s.Name())
+		case !s.recovery && k == "snapd_extra_cmdline_args":
+			runEntriesChanged = runEntriesChanged || env.Get(k) != v
+		case s.recovery && (k == "snapd_recovery_mode" || k == "snapd_recovery_system"):
+			recoveryEntryChanged = recoveryEntryChanged || env.Get(k) != v
+		}
+		env.Set(k, v)
+	}
+	if err := env.Save(); err != nil {
+		return err
+	}
+
+	if runEntriesChanged {
+		if err := s.rewriteRunEntries(env); err != nil {
+			return err
+		}
+	}
+	if kernelStatus != nil {
+		switch *kernelStatus {
+		case "try":
+			if err := s.writeTryRunEntry(env); err != nil {
+				return err
+			}
+		case "":
+			if err := s.removeTryRunEntry(); err != nil {
+				return err
+			}
+		case "trying":
+			// the try entry has been booted already, nothing to do
+		default:
+			return fmt.Errorf("cannot set kernel_status to unsupported value %q", *kernelStatus)
+		}
+	}
+	if recoveryEntryChanged {
+		return s.writeRecoveryEntry(env)
+	}
+	return nil
+}
+
+// kernelStatus derives the kernel_status from the try boot entry. The entry
+// is created with a single try left, which systemd-boot consumes when booting
+// it. If the try entry was booted, the kernel is being tried, otherwise either
+// the entry was not booted yet, or the boot fell back to the regular entry.
+func (s *sdboot) kernelStatus() (string, error) {
+	tryEntry, triesLeft, err := s.tryRunEntry()
+	if err != nil {
+		return "", err
+	}
+	if tryEntry == "" {
+		return "", nil
+	}
+	selected, _, err := efi.ReadVarString(sdbootEntrySelectedVar)
+	if err == nil && strings.HasPrefix(selected, sdbootTryRunEntry) {
+		return "trying", nil
+	}
+	if triesLeft > 0 {
+		return "try", nil
+	}
+	return "", nil
+}
+
+// tryRunEntry returns the file name of the try boot entry and the number of
+// tries left, or an empty name if there is no such entry.
+func (s *sdboot) tryRunEntry() (name string, triesLeft int, err error) {
+	matches, err := filepath.Glob(filepath.Join(s.entriesDir(), sdbootTryRunEntry+"*.conf"))
+	if err != nil {
+		return "", 0, err
+	}
+	for _, m := range matches {
+		match := sdbootTryRunEntryRe.FindStringSubmatch(filepath.Base(m))
+		if match == nil {
+			continue
+		}
+		if match[2] != "" {
+			triesLeft, err = strconv.Atoi(match[2])
+			if err != nil {
+				return "", 0, fmt.Errorf("cannot parse boot counter of %q: %v", filepath.Base(m), err)
+			}
+		}
+		return filepath.Base(m), triesLeft, nil
+	}
+	return "", 0, nil
+}
+
+func (s *sdboot) removeTryRunEntry() error {
+	for {
+		name, _, err := s.tryRunEntry()
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return nil
+		}
+		if err := os.Remove(filepath.Join(s.entriesDir(), name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+}
+
+// sdbootEntry is a boot loader specification type #1 entry.
+type sdbootEntry struct {
+	edition uint
+	title   string
+	sortKey string
+	efi     string
+	options string
+}
+
+func (e *sdbootEntry) bytes() []byte {
+	var buf bytes.Buffer
+	if e.edition != 0 {
+		fmt.Fprintf(&buf, "%s%d\n", editionHeader, e.edition)
+	}
+	fmt.Fprintf(&buf, "title %s\n", e.title)
+	fmt.Fprintf(&buf, "sort-key %s\n", e.sortKey)
+	fmt.Fprintf(&buf, "efi %s\n", e.efi)
+	fmt.Fprintf(&buf, "options %s\n", e.options)
+	return buf.Bytes()
+}
+
+func (s *sdboot) writeEntry(file string, e *sdbootEntry) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return osutil.AtomicWriteFile(file, e.bytes(), 0644, 0)
+}
+
+// readEntryEfi returns the value of the efi key of the given boot entry.
+func readEntryEfi(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "efi" {
+			return fields[1], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no efi image in boot entry %s", filepath.Base(file))
+}
+
+func (s *sdboot) kernelEfi(sn snap.PlaceInfo) string {
+	return filepath.Join(s.kernelsDir(), sn.Filename(), "kernel.efi")
+}
+
+// runEntryEdition returns the edition of the static kernel command line used
+// by the run mode boot entries.
+func (s *sdboot) runEntryEdition() (uint, error) {
+	edition, err := editionFromDiskConfigAsset(s.entryFile(sdbootRunEntry))
+	if err == errNoEdition {
+		if osutil.FileExists(s.entryFile(sdbootRunEntry)) {
+			return 1, nil
+		}
+		// no run entry yet, it will be written using the built-in
+		// edition
+		return editionFromInternalConfigAsset(sdbootLoaderConfAsset)
+	}
+	return edition, err
+}
+
+func (s *sdboot) runEntry(kernel snap.PlaceInfo, edition uint, env *grubenv.Env) (*sdbootEntry, error) {
+	cmdline, err := s.commandLineForEdition(edition, "snapd_recovery_mode=run", "", env.Get("snapd_extra_cmdline_args"))
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(s.dir(), s.kernelEfi(kernel))
+	if err != nil {
+		return nil, err
+	}
+	return &sdbootEntry{
+		edition: edition,
+		title:   "Ubuntu Core",
+		sortKey: "snapd-3",
+		efi:     "/" + filepath.ToSlash(rel),
+		options: cmdline,
+	}, nil
+}
+
+func (s *sdboot) writeRunEntry(kernel snap.PlaceInfo, edition uint, env *grubenv.Env) error {
+	e, err := s.runEntry(kernel, edition, env)
+	if err != nil {
+		return err
+	}
+	return s.writeEntry(s.entryFile(sdbootRunEntry), e)
+}
+
+func (s *sdboot) writeTryRunEntry(env *grubenv.Env) error {
+	tryKernel, err := s.TryKernel()
+	if err != nil {
+		return fmt.Errorf("cannot write try boot entry: %v", err)
+	}
+	edition, err := s.runEntryEdition()
+	if err != nil {
+		return err
+	}
+	e, err := s.runEntry(tryKernel, edition, env)
+	if err != nil {
+		return err
+	}
+	e.title = "Ubuntu Core (try)"
+	e.sortKey = "snapd-2"
+	if err := s.removeTryRunEntry(); err != nil {
+		return err
+	}
+	// a single try, systemd-boot falls back to the run entry afterwards
+	return s.writeEntry(filepath.Join(s.entriesDir(), sdbootTryRunEntry+"+1.conf"), e)
+}
+
+// rewriteRunEntries updates the existing run mode boot entries after a change
+// of the environment they are built from.
+func (s *sdboot) rewriteRunEntries(env *grubenv.Env) error {
+	return s.rewriteRunEntriesForEdition(0, env)
+}
+
+func (s *sdboot) rewriteRunEntriesForEdition(edition uint, env *grubenv.Env) error {
+	if !osutil.FileExists(s.entryFile(sdbootRunEntry)) {
+		return nil
+	}
+	if edition == 0 {
+		var err error
+		edition, err = s.runEntryEdition()
+		if err != nil {
+			return err
+		}
+	}
+	kernel, err := s.Kernel()
+	if err != nil {
+		return err
+	}
+	if err := s.writeRunEntry(kernel, edition, env); err != nil {
+		return err
+	}
+	tryEntry, _, err := s.tryRunEntry()
+	if err != nil {
+		return err
+	}
+	if tryEntry == "" {
+		return nil
+	}
+	tryKernel, err := s.TryKernel()
+	if err != nil {
+		return err
+	}
+	e, err := s.runEntry(tryKernel, edition, env)
+	if err != nil {
+		return err
+	}
+	e.title = "Ubuntu Core (try)"
+	e.sortKey = "snapd-2"
+	// keep the boot counter state
+	return s.writeEntry(filepath.Join(s.entriesDir(), tryEntry), e)
+}
+
+// writeRecoveryEntry writes the boot entry of the current recovery system on
+// ubuntu-seed. When the device is to boot into a recovery mode the entry takes
+// precedence over the run mode ones, otherwise it is listed last so that the
+// recover mode can still be chosen from the boot menu.
+func (s *sdboot) writeRecoveryEntry(env *grubenv.Env) error {
+	system := env.Get("snapd_recovery_system")
+	if system == "" {
+		err := os.Remove(s.entryFile(sdbootRecoveryEntry))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	mode := env.Get("snapd_recovery_mode")
+	sortKey := "snapd-1"
+	switch mode {
+	case "":
+		mode = "install"
+	case "run":
+		mode = "recover"
+		sortKey = "snapd-4"
+	}
+	edition, err := s.loaderConfEdition()
+	if err != nil {
+		return err
+	}
+	cmdline, err := s.commandLineForEdition(edition,
+		"snapd_recovery_mode="+mode, "snapd_recovery_system="+system, "")
+	if err != nil {
+		return err
+	}
+	e := &sdbootEntry{
+		title:   fmt.Sprintf("Ubuntu Core %s %s", mode, system),
+		sortKey: sortKey,
+		efi:     "/" + filepath.ToSlash(filepath.Join("systems", system, "kernel/kernel.efi")),
+		options: cmdline,
+	}
+	return s.writeEntry(s.entryFile(sdbootRecoveryEntry), e)
+}
+
+func (s *sdboot) ExtractKernelAssets(sn snap.PlaceInfo, snapf snap.Container) error {
+	if s.recovery {
+		return fmt.Errorf("internal error: cannot extract run kernel assets on recovery bootloader")
+	}
+	return extractKernelAssetsToBootDir(filepath.Dir(s.kernelEfi(sn)), snapf, []string{"kernel.efi"})
+}
+
+func (s *sdboot) ExtractRecoveryKernelAssets(recoverySystemDir string, sn snap.PlaceInfo, snapf snap.Container) error {
+	if recoverySystemDir == "" {
+		return fmt.Errorf("internal error: recoverySystemDir unset")
+	}
+	recoverySystemKernelDir := filepath.Join(s.rootdir, recoverySystemDir, "kernel")
+	return extractKernelAssetsToBootDir(recoverySystemKernelDir, snapf, []string{"kernel.efi"})
+}
+
+func (s *sdboot) RemoveKernelAssets(sn snap.PlaceInfo) error {
+	return removeKernelAssetsFromBootDir(s.kernelsDir(), sn)
+}
+
+func (s *sdboot) checkKernelExtracted(sn snap.PlaceInfo) error {
+	if !osutil.FileExists(s.kernelEfi(sn)) {
+		return fmt.Errorf("cannot use kernel %s: %v", sn.Filename(), os.ErrNotExist)
+	}
+	return nil
+}
+
+// EnableKernel writes the run mode boot entry booting the referenced kernel
+// snap. EnableKernel() will fail if the referenced kernel snap has not been
+// extracted.
+func (s *sdboot) EnableKernel(sn snap.PlaceInfo) error {
+	if err := s.checkKernelExtracted(sn); err != nil {
+		return err
+	}
+	env, err := s.loadEnv()
+	if err != nil {
+		return err
+	}
+	edition, err := s.runEntryEdition()
+	if err != nil {
+		return err
+	}
+	return s.writeRunEntry(sn, edition, env)
+}
+
+// EnableTryKernel records the referenced kernel snap as the one to try, the
+// try boot entry is written once kernel_status is set to "try".
+// EnableTryKernel() will fail if the referenced kernel snap has not been
+// extracted.
+func (s *sdboot) EnableTryKernel(sn snap.PlaceInfo) error {
+	if err := s.checkKernelExtracted(sn); err != nil {
+		return err
+	}
+	env, err := s.loadEnv()
+	if err != nil {
+		return err
+	}
+	env.Set(sdbootTryKernelVar, sn.Filename())
+	return env.Save()
+}
+
+// DisableTryKernel forgets the kernel being tried and removes the try boot
+// entry if it exists.
+func (s *sdboot) DisableTryKernel() error {
+	env, err := s.loadEnv()
+	if err != nil {
+		return err
+	}
+	env.Set(sdbootTryKernelVar, "")
+	if err := env.Save(); err != nil {
+		return err
+	}
+	return s.removeTryRunEntry()
+}
+
+// Kernel returns the kernel snap booted by the run mode boot entry.
+func (s *sdboot) Kernel() (snap.PlaceInfo, error) {
+	efiPath, err := readEntryEfi(s.entryFile(sdbootRunEntry))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read run mode boot entry: %v", err)
+	}
+	kernelSnapFileName := filepath.Base(filepath.Dir(efiPath))
+	sn, err := snap.ParsePlaceInfoFromSnapFileName(kernelSnapFileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse kernel snap file name from boot entry %q: %v", kernelSnapFileName, err)
+	}
+	return sn, nil
+}
+
+// TryKernel returns the kernel snap enabled for trying, or ErrNoTryKernelRef if
+// there is none. An error is returned if the kernel has been enabled, but is no
+// longer extracted.
+func (s *sdboot) TryKernel() (snap.PlaceInfo, error) {
+	env, err := s.loadEnv()
+	if err != nil {
+		return nil, err
+	}
+	kernelSnapFileName := env.Get(sdbootTryKernelVar)
+	if kernelSnapFileName == "" {
+		return nil, ErrNoTryKernelRef
+	}
+	sn, err := snap.ParsePlaceInfoFromSnapFileName(kernelSnapFileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse try kernel snap file name %q: %v", kernelSnapFileName, err)
+	}
+	if err := s.checkKernelExtracted(sn); err != nil {
+		return nil, err
+	}
+	return sn, nil
+}
+
+// UpdateBootConfig updates the loader config of the recovery bootloader, or
+// the static kernel command line of the run mode boot entries, only if they
+// are managed and have a lower edition.
+//
+// Implements TrustedAssetsBootloader for the systemd-boot bootloader.
+func (s *sdboot) UpdateBootConfig() (bool, error) {
+	if s.recovery {
+		return genericUpdateBootConfigFromAssets(s.loaderConfFile(), sdbootLoaderConfAsset)
+	}
+	current, err := editionFromDiskConfigAsset(s.entryFile(sdbootRunEntry))
+	if err != nil {
+		if err == errNoEdition {
+			return false, nil
+		}
+		return false, err
+	}
+	candidate, err := editionFromInternalConfigAsset(sdbootLoaderConfAsset)
+	if err != nil {
+		return false, err
+	}
+	if candidate <= current {
+		return false, nil
+	}
+	env, err := s.loadEnv()
+	if err != nil {
+		return false, err
+	}
+	if err := s.rewriteRunEntriesForEdition(candidate, env); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ManagedAssets returns a list relative paths to boot assets inside the root
+// directory of the filesystem.
+//
+// Implements TrustedAssetsBootloader for the systemd-boot bootloader.
+func (s *sdboot) ManagedAssets() []string {
+	if s.recovery {
+		return []string{"loader/loader.conf"}
+	}
+	return []string{
+		filepath.Join(s.basedir, "loader/entries", sdbootRunEntry+".conf"),
+	}
+}
+
+func (s *sdboot) commandLineForEdition(edition uint, modeArg, systemArg, extraArgs string) (string, error) {
+	staticCmdline := staticCommandLineForGrubAssetEdition(sdbootLoaderConfAsset, edition)
+	args, err := osutil.KernelCommandLineSplit(staticCmdline + " " + extraArgs)
+	if err != nil {
+		return "", fmt.Errorf("cannot use badly formatted kernel command line: %v", err)
+	}
+	snapdArgs := make([]string, 0, 2)
+	if modeArg != "" {
+		snapdArgs = append(snapdArgs, modeArg)
+	}
+	if systemArg != "" {
+		snapdArgs = append(snapdArgs, systemArg)
+	}
+	return strings.Join(append(snapdArgs, args...), " "), nil
+}
+
+func (s *sdboot) loaderConfEdition() (uint, error) {
+	edition, err := editionFromDiskConfigAsset(s.loaderConfFile())
+	if err != nil {
+		if err != errNoEdition {
+			return 0, fmt.Errorf("cannot obtain edition number of current boot config: %v", err)
+		}
+		edition = 1
+	}
+	return edition, nil
+}
+
+// CommandLine returns the kernel command line composed of mode and
+// system arguments, built-in bootloader specific static arguments
+// corresponding to the edition of the on-disk loader config for recovery, or
+// of the run mode boot entry, followed by any extra arguments.
+//
+// Implements TrustedAssetsBootloader for the systemd-boot bootloader.
+func (s *sdboot) CommandLine(modeArg, systemArg, extraArgs string) (string, error) {
+	var edition uint
+	var err error
+	if s.recovery {
+		edition, err = s.loaderConfEdition()
+	} else {
+		edition, err = s.runEntryEdition()
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.commandLineForEdition(edition, modeArg, systemArg, extraArgs)
+}
+
+// CandidateCommandLine is similar to CommandLine, but uses the current
+// edition of managed built-in boot assets as reference.
+//
+// Implements TrustedAssetsBootloader for the systemd-boot bootloader.
+func (s *sdboot) CandidateCommandLine(modeArg, systemArg, extraArgs string) (string, error) {
+	edition, err := editionFromInternalConfigAsset(sdbootLoaderConfAsset)
+	if err != nil {
+		return "", err
+	}
+	return s.commandLineForEdition(edition, modeArg, systemArg, extraArgs)
+}
+
+var sdbootRecoveryModeTrustedAssets = []string{
+	// systemd-boot EFI binary
+	"EFI/boot/bootx64.efi",
+}
+
+// TrustedAssets returns the list of relative paths to assets inside
+// the bootloader's rootdir that are measured in the boot process in the
+// order of loading during the boot. The run mode kernels are loaded from
+// ubuntu-boot directly by systemd-boot living on ubuntu-seed, thus there are
+// no trusted assets on ubuntu-boot.
+func (s *sdboot) TrustedAssets() ([]string, error) {
+	if !s.nativePartitionLayout {
+		return nil, fmt.Errorf("internal error: trusted assets called without native host-partition layout")
+	}
+	if s.recovery {
+		return sdbootRecoveryModeTrustedAssets, nil
+	}
+	return nil, nil
+}
+
+// RecoveryBootChain returns the load chain for recovery modes.
+// It should be called on a RoleRecovery bootloader.
+func (s *sdboot) RecoveryBootChain(kernelPath string) ([]BootFile, error) {
+	if !s.recovery {
+		return nil, fmt.Errorf("not a recovery bootloader")
+	}
+
+	chain := make([]BootFile, 0, len(sdbootRecoveryModeTrustedAssets)+1)
+	for _, ta := range sdbootRecoveryModeTrustedAssets {
+		chain = append(chain, NewBootFile("", ta, RoleRecovery))
+	}
+	chain = append(chain, NewBootFile(kernelPath, "kernel.efi", RoleRecovery))
+
+	return chain, nil
+}
+
+// BootChain returns the load chain for run mode.
+// It should be called on a RoleRecovery bootloader passing the
+// RoleRunMode bootloader.
+func (s *sdboot) BootChain(runBl Bootloader, kernelPath string) ([]BootFile, error) {
+	if !s.recovery {
+		return nil, fmt.Errorf("not a recovery bootloader")
+	}
+	if runBl.Name() != s.Name() {
+		return nil, fmt.Errorf("run mode bootloader must be %s", s.Name())
+	}
+
+	chain := make([]BootFile, 0, len(sdbootRecoveryModeTrustedAssets)+1)
+	for _, ta := range sdbootRecoveryModeTrustedAssets {
+		chain = append(chain, NewBootFile("", ta, RoleRecovery))
+	}
+	chain = append(chain, NewBootFile(kernelPath, "kernel.efi", RoleRunMode))
+
+	return chain, nil
+}
@@ -0,0 +1,507 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package bootloader_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/bootloader"
+	"github.com/snapcore/snapd/bootloader/assets"
+	"github.com/snapcore/snapd/bootloader/bootloadertest"
+	"github.com/snapcore/snapd/bootloader/efi"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snapfile"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type sdbootTestSuite struct {
+	baseBootenvTestSuite
+
+	// fake ESP, ie. ubuntu-seed
+	seedDir string
+	// fake XBOOTLDR, ie. ubuntu-boot
+	bootDir string
+}
+
+var _ = Suite(&sdbootTestSuite{})
+
+const loaderEntrySelectedVar = "LoaderEntrySelected-4a67b082-0a4c-41cf-b6c7-440b29bb8c4f"
+
+func (s *sdbootTestSuite) SetUpTest(c *C) {
+	s.baseBootenvTestSuite.SetUpTest(c)
+	s.seedDir = filepath.Join(s.rootdir, "run/mnt/ubuntu-seed")
+	s.bootDir = filepath.Join(s.rootdir, "run/mnt/ubuntu-boot")
+
+	// not booted by systemd-boot unless a test says so
+	s.AddCleanup(efi.MockVars(map[string][]byte{}, nil))
+}
+
+func (s *sdbootTestSuite) recoveryBootloader(c *C) bootloader.Bootloader {
+	opts := &bootloader.Options{Role: bootloader.RoleRecovery}
+	err := bootloader.InstallBootConfig(s.gadgetDir(c), s.seedDir, opts)
+	c.Assert(err, IsNil)
+	bl, err := bootloader.Find(s.seedDir, opts)
+	c.Assert(err, IsNil)
+	c.Assert(bl.Name(), Equals, "systemd-boot")
+	return bl
+}
+
+func (s *sdbootTestSuite) runBootloader(c *C) bootloader.ExtractedRunKernelImageBootloader {
+	opts := &bootloader.Options{Role: bootloader.RoleRunMode, NoSlashBoot: true}
+	err := bootloader.InstallBootConfig(s.gadgetDir(c), s.bootDir, opts)
+	c.Assert(err, IsNil)
+	bl, err := bootloader.Find(s.bootDir, opts)
+	c.Assert(err, IsNil)
+	c.Assert(bl.Name(), Equals, "systemd-boot")
+	ebl, ok := bl.(bootloader.ExtractedRunKernelImageBootloader)
+	c.Assert(ok, Equals, true)
+	return ebl
+}
+
+func (s *sdbootTestSuite) gadgetDir(c *C) string {
+	gadgetDir := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(gadgetDir, "systemd-boot.conf"), nil, 0644)
+	c.Assert(err, IsNil)
+	return gadgetDir
+}
+
+func (s *sdbootTestSuite) makeKernelAssetSnap(c *C, snapFileName string) snap.PlaceInfo {
+	kernelSnap, err := snap.ParsePlaceInfoFromSnapFileName(snapFileName)
+	c.Assert(err, IsNil)
+
+	// make a kernel.efi as it would be by ExtractKernelAssets()
+	err = os.MkdirAll(filepath.Join(s.bootDir, "EFI/ubuntu", snapFileName), 0755)
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(filepath.Join(s.bootDir, "EFI/ubuntu", snapFileName, "kernel.efi"), nil, 0644)
+	c.Assert(err, IsNil)
+
+	return kernelSnap
+}
+
+func (s *sdbootTestSuite) entry(name string) string {
+	return filepath.Join(s.bootDir, "loader/entries", name)
+}
+
+func (s *sdbootTestSuite) kernelStatus(c *C, bl bootloader.Bootloader) string {
+	m, err := bl.GetBootVars("kernel_status")
+	c.Assert(err, IsNil)
+	return m["kernel_status"]
+}
+
+func (s *sdbootTestSuite) TestNewSdboot(c *C) {
+	bl := bootloader.NewSdboot(s.seedDir, &bootloader.Options{Role: bootloader.RoleRecovery})
+	c.Assert(bl, NotNil)
+	c.Check(bl.Name(), Equals, "systemd-boot")
+
+	present, err := bl.Present()
+	c.Assert(err, IsNil)
+	c.Check(present, Equals, false)
+
+	bl = bootloader.NewSdboot(s.bootDir, &bootloader.Options{Role: bootloader.RoleRunMode, NoSlashBoot: true})
+	present, err = bl.Present()
+	c.Assert(err, IsNil)
+	c.Check(present, Equals, false)
+}
+
+func (s *sdbootTestSuite) TestInstallBootConfig(c *C) {
+	s.recoveryBootloader(c)
+	c.Check(filepath.Join(s.seedDir, "loader/loader.conf"), testutil.FileEquals,
+		string(assets.Internal("systemd-boot-loader.conf")))
+	c.Check(filepath.Join(s.seedDir, "loader/snapdenv"), testutil.FilePresent)
+
+	s.runBootloader(c)
+	c.Check(filepath.Join(s.bootDir, "loader/entries.srel"), testutil.FileEquals, "type1\n")
+	c.Check(filepath.Join(s.bootDir, "loader/snapdenv"), testutil.FilePresent)
+	c.Check(filepath.Join(s.bootDir, "loader/loader.conf"), testutil.FileAbsent)
+
+	// there is no support outside of UC20
+	bl := bootloader.NewSdboot(s.rootdir, nil)
+	err := bl.InstallBootConfig(s.gadgetDir(c), nil)
+	c.Assert(err, ErrorMatches, "cannot install systemd-boot boot config outside of recovery and run mode")
+}
+
+func (s *sdbootTestSuite) TestRunModeFoundUnderSlash(c *C) {
+	s.runBootloader(c)
+
+	// when not using the native layout, ubuntu-boot is used where it is
+	// mounted
+	bl, err := bootloader.Find(s.rootdir, &bootloader.Options{Role: bootloader.RoleRunMode})
+	c.Assert(err, IsNil)
+	c.Check(bl.Name(), Equals, "systemd-boot")
+	tbl, ok := bl.(bootloader.TrustedAssetsBootloader)
+	c.Assert(ok, Equals, true)
+	_, err = tbl.TrustedAssets()
+	c.Assert(err, ErrorMatches, "internal error: trusted assets called without native host-partition layout")
+}
+
+func (s *sdbootTestSuite) TestExtractKernelAssetsAndEnableKernel(c *C) {
+	bl := s.runBootloader(c)
+
+	files := [][]string{
+		{"kernel.efi", "I'm a kernel"},
+		{"another-kernel-file", "another kernel file"},
+		{"meta/kernel.yaml", "version: 4.2"},
+	}
+	si := &snap.SideInfo{
+		RealName: "ubuntu-kernel",
+		Revision: snap.R(42),
+	}
+	fn := snaptest.MakeTestSnapWithFiles(c, packageKernel, files)
+	snapf, err := snapfile.Open(fn)
+	c.Assert(err, IsNil)
+	info, err := snap.ReadInfoFromSnapFile(snapf, si)
+	c.Assert(err, IsNil)
+
+	err = bl.ExtractKernelAssets(info, snapf)
+	c.Assert(err, IsNil)
+	kernelEfi := filepath.Join(s.bootDir, "EFI/ubuntu/ubuntu-kernel_42.snap/kernel.efi")
+	c.Check(kernelEfi, testutil.FileEquals, "I'm a kernel")
+	c.Check(filepath.Join(s.bootDir, "EFI/ubuntu/ubuntu-kernel_42.snap/another-kernel-file"), testutil.FileAbsent)
+
+	err = bl.EnableKernel(info)
+	c.Assert(err, IsNil)
+	c.Check(s.entry("snapd-run.conf"), testutil.FileEquals, `# Snapd-Boot-Config-Edition: 1
+title Ubuntu Core
+sort-key snapd-3
+efi /EFI/ubuntu/ubuntu-kernel_42.snap/kernel.efi
+options snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1
+`)
+
+	kernel, err := bl.Kernel()
+	c.Assert(err, IsNil)
+	c.Check(kernel.Filename(), Equals, "ubuntu-kernel_42.snap")
+
+	err = bl.RemoveKernelAssets(info)
+	c.Assert(err, IsNil)
+	c.Check(filepath.Dir(kernelEfi), testutil.FileAbsent)
+}
+
+func (s *sdbootTestSuite) TestEnableKernelNotExtracted(c *C) {
+	bl := s.runBootloader(c)
+
+	kernel, err := snap.ParsePlaceInfoFromSnapFileName("pc-kernel_1.snap")
+	c.Assert(err, IsNil)
+	err = bl.EnableKernel(kernel)
+	c.Assert(err, ErrorMatches, "cannot use kernel pc-kernel_1.snap: file does not exist")
+	err = bl.EnableTryKernel(kernel)
+	c.Assert(err, ErrorMatches, "cannot use kernel pc-kernel_1.snap: file does not exist")
+
+	_, err = bl.Kernel()
+	c.Assert(err, ErrorMatches, "cannot read run mode boot entry: .*no such file or directory")
+	_, err = bl.TryKernel()
+	c.Assert(err, Equals, bootloader.ErrNoTryKernelRef)
+}
+
+func (s *sdbootTestSuite) TestTryKernelSuccessful(c *C) {
+	bl := s.runBootloader(c)
+	kernel := s.makeKernelAssetSnap(c, "pc-kernel_1.snap")
+	tryKernel := s.makeKernelAssetSnap(c, "pc-kernel_2.snap")
+	c.Assert(bl.EnableKernel(kernel), IsNil)
+
+	// set up the try kernel like snapd does
+	c.Assert(bl.EnableTryKernel(tryKernel), IsNil)
+	c.Check(s.kernelStatus(c, bl), Equals, "")
+	c.Assert(bl.SetBootVars(map[string]string{"kernel_status": "try"}), IsNil)
+
+	c.Check(s.entry("snapd-try-run+1.conf"), testutil.FileEquals, `# Snapd-Boot-Config-Edition: 1
+title Ubuntu Core (try)
+sort-key snapd-2
+efi /EFI/ubuntu/pc-kernel_2.snap/kernel.efi
+options snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1
+`)
+	c.Check(s.kernelStatus(c, bl), Equals, "try")
+	k, err := bl.TryKernel()
+	c.Assert(err, IsNil)
+	c.Check(k.Filename(), Equals, "pc-kernel_2.snap")
+
+	// systemd-boot consumes the try when booting the entry
+	err = os.Rename(s.entry("snapd-try-run+1.conf"), s.entry("snapd-try-run+0-1.conf"))
+	c.Assert(err, IsNil)
+	restore := efi.MockVars(map[string][]byte{
+		loaderEntrySelectedVar: bootloadertest.UTF16Bytes("snapd-try-run.conf"),
+	}, nil)
+	defer restore()
+	c.Check(s.kernelStatus(c, bl), Equals, "trying")
+
+	// and snapd marks the boot as successful
+	c.Assert(bl.EnableKernel(tryKernel), IsNil)
+	c.Assert(bl.DisableTryKernel(), IsNil)
+	c.Assert(bl.SetBootVars(map[string]string{"kernel_status": ""}), IsNil)
+
+	c.Check(s.entry("snapd-try-run+0-1.conf"), testutil.FileAbsent)
+	c.Check(s.kernelStatus(c, bl), Equals, "")
+	k, err = bl.Kernel()
+	c.Assert(err, IsNil)
+	c.Check(k.Filename(), Equals, "pc-kernel_2.snap")
+	_, err = bl.TryKernel()
+	c.Assert(err, Equals, bootloader.ErrNoTryKernelRef)
+}
+
+func (s *sdbootTestSuite) TestTryKernelFallback(c *C) {
+	bl := s.runBootloader(c)
+	kernel := s.makeKernelAssetSnap(c, "pc-kernel_1.snap")
+	tryKernel := s.makeKernelAssetSnap(c, "pc-kernel_2.snap")
+	c.Assert(bl.EnableKernel(kernel), IsNil)
+	c.Assert(bl.EnableTryKernel(tryKernel), IsNil)
+	c.Assert(bl.SetBootVars(map[string]string{"kernel_status": "try"}), IsNil)
+
+	// the try entry was booted, but the boot failed, and systemd-boot
+	// fell back to the run entry
+	err := os.Rename(s.entry("snapd-try-run+1.conf"), s.entry("snapd-try-run+0-1.conf"))
+	c.Assert(err, IsNil)
+	restore := efi.MockVars(map[string][]byte{
+		loaderEntrySelectedVar: bootloadertest.UTF16Bytes("snapd-run.conf"),
+	}, nil)
+	defer restore()
+	c.Check(s.kernelStatus(c, bl), Equals, "")
+
+	k, err := bl.Kernel()
+	c.Assert(err, IsNil)
+	c.Check(k.Filename(), Equals, "pc-kernel_1.snap")
+}
+
+func (s *sdbootTestSuite) TestKernelStatusNoEFI(c *C) {
+	restore := efi.MockVars(nil, nil)
+	defer restore()
+
+	bl := s.runBootloader(c)
+	kernel := s.makeKernelAssetSnap(c, "pc-kernel_1.snap")
+	tryKernel := s.makeKernelAssetSnap(c, "pc-kernel_2.snap")
+	c.Assert(bl.EnableKernel(kernel), IsNil)
+	c.Assert(bl.EnableTryKernel(tryKernel), IsNil)
+	c.Assert(bl.SetBootVars(map[string]string{"kernel_status": "try"}), IsNil)
+
+	c.Check(s.kernelStatus(c, bl), Equals, "try")
+
+	err := bl.SetBootVars(map[string]string{"kernel_status": "foo"})
+	c.Assert(err, ErrorMatches, `cannot set kernel_status to unsupported value "foo"`)
+}
+
+func (s *sdbootTestSuite) TestTryWithoutTryKernel(c *C) {
+	bl := s.runBootloader(c)
+	err := bl.SetBootVars(map[string]string{"kernel_status": "try"})
+	c.Assert(err, ErrorMatches, "cannot write try boot entry: no try-kernel referenced")
+}
+
+func (s *sdbootTestSuite) TestExtraCommandLineArgs(c *C) {
+	bl := s.runBootloader(c)
+	kernel := s.makeKernelAssetSnap(c, "pc-kernel_1.snap")
+	tryKernel := s.makeKernelAssetSnap(c, "pc-kernel_2.snap")
+	c.Assert(bl.EnableKernel(kernel), IsNil)
+	c.Assert(bl.EnableTryKernel(tryKernel), IsNil)
+	c.Assert(bl.SetBootVars(map[string]string{"kernel_status": "try"}), IsNil)
+
+	err := bl.SetBootVars(map[string]string{"snapd_extra_cmdline_args": "foo=bar"})
+	c.Assert(err, IsNil)
+	c.Check(s.entry("snapd-run.conf"), testutil.FileContains,
+		"options snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1 foo=bar\n")
+	c.Check(s.entry("snapd-try-run+1.conf"), testutil.FileContains,
+		"options snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1 foo=bar\n")
+
+	m, err := bl.GetBootVars("snapd_extra_cmdline_args")
+	c.Assert(err, IsNil)
+	c.Check(m, DeepEquals, map[string]string{"snapd_extra_cmdline_args": "foo=bar"})
+
+	tbl := bl.(bootloader.TrustedAssetsBootloader)
+	cmdline, err := tbl.CommandLine("snapd_recovery_mode=run", "", "foo=bar")
+	c.Assert(err, IsNil)
+	c.Check(cmdline, Equals, "snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1 foo=bar")
+
+	// trying extra arguments is not supported yet
+	err = bl.SetBootVars(map[string]string{"cmdline_status": "try"})
+	c.Assert(err, ErrorMatches, "cannot try kernel command line arguments with systemd-boot")
+}
+
+func (s *sdbootTestSuite) TestRecoveryEntry(c *C) {
+	bl := s.recoveryBootloader(c)
+
+	err := bl.SetBootVars(map[string]string{
+		"snapd_recovery_system": "20210512",
+		"snapd_recovery_mode":   "install",
+	})
+	c.Assert(err, IsNil)
+	recoveryEntry := filepath.Join(s.seedDir, "loader/entries/snapd-recovery.conf")
+	c.Check(recoveryEntry, testutil.FileEquals, `title Ubuntu Core install 20210512
+sort-key snapd-1
+efi /systems/20210512/kernel/kernel.efi
+options snapd_recovery_mode=install snapd_recovery_system=20210512 console=ttyS0 console=tty1 panic=-1
+`)
+
+	m, err := bl.GetBootVars("snapd_recovery_system", "snapd_recovery_mode")
+	c.Assert(err, IsNil)
+	c.Check(m, DeepEquals, map[string]string{
+		"snapd_recovery_system": "20210512",
+		"snapd_recovery_mode":   "install",
+	})
+
+	// once installed, the recovery system is listed last
+	err = bl.SetBootVars(map[string]string{"snapd_recovery_mode": "run"})
+	c.Assert(err, IsNil)
+	c.Check(recoveryEntry, testutil.FileEquals, `title Ubuntu Core recover 20210512
+sort-key snapd-4
+efi /systems/20210512/kernel/kernel.efi
+options snapd_recovery_mode=recover snapd_recovery_system=20210512 console=ttyS0 console=tty1 panic=-1
+`)
+
+	err = bl.SetBootVars(map[string]string{"snapd_recovery_system": ""})
+	c.Assert(err, IsNil)
+	c.Check(recoveryEntry, testutil.FileAbsent)
+}
+
+func (s *sdbootTestSuite) TestExtractRecoveryKernelAssets(c *C) {
+	bl := s.recoveryBootloader(c)
+	erbl, ok := bl.(bootloader.ExtractedRecoveryKernelImageBootloader)
+	c.Assert(ok, Equals, true)
+
+	files := [][]string{
+		{"kernel.efi", "I'm a kernel"},
+		{"meta/kernel.yaml", "version: 4.2"},
+	}
+	fn := snaptest.MakeTestSnapWithFiles(c, packageKernel, files)
+	snapf, err := snapfile.Open(fn)
+	c.Assert(err, IsNil)
+	info, err := snap.ReadInfoFromSnapFile(snapf, &snap.SideInfo{RealName: "ubuntu-kernel", Revision: snap.R(42)})
+	c.Assert(err, IsNil)
+
+	err = erbl.ExtractRecoveryKernelAssets("", info, snapf)
+	c.Assert(err, ErrorMatches, "internal error: recoverySystemDir unset")
+
+	err = erbl.ExtractRecoveryKernelAssets("systems/20210512", info, snapf)
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(s.seedDir, "systems/20210512/kernel/kernel.efi"), testutil.FileEquals, "I'm a kernel")
+}
+
+func (s *sdbootTestSuite) TestRecoverySystemEnv(c *C) {
+	bl := s.recoveryBootloader(c)
+	rbl, ok := bl.(bootloader.RecoveryAwareBootloader)
+	c.Assert(ok, Equals, true)
+
+	v, err := rbl.GetRecoverySystemEnv("systems/20210512", "foo")
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, "")
+
+	err = rbl.SetRecoverySystemEnv("systems/20210512", map[string]string{"foo": "bar"})
+	c.Assert(err, IsNil)
+	c.Check(filepath.Join(s.seedDir, "systems/20210512/snapdenv"), testutil.FilePresent)
+	v, err = rbl.GetRecoverySystemEnv("systems/20210512", "foo")
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, "bar")
+}
+
+func (s *sdbootTestSuite) TestTrustedAssetsAndBootChains(c *C) {
+	rbl := s.recoveryBootloader(c).(bootloader.TrustedAssetsBootloader)
+	runBl := s.runBootloader(c).(bootloader.TrustedAssetsBootloader)
+
+	ta, err := rbl.TrustedAssets()
+	c.Assert(err, IsNil)
+	c.Check(ta, DeepEquals, []string{"EFI/boot/bootx64.efi"})
+	ta, err = runBl.TrustedAssets()
+	c.Assert(err, IsNil)
+	c.Check(ta, HasLen, 0)
+
+	c.Check(rbl.ManagedAssets(), DeepEquals, []string{"loader/loader.conf"})
+	c.Check(runBl.ManagedAssets(), DeepEquals, []string{"loader/entries/snapd-run.conf"})
+
+	chain, err := rbl.RecoveryBootChain("kernel.snap")
+	c.Assert(err, IsNil)
+	c.Check(chain, DeepEquals, []bootloader.BootFile{
+		bootloader.NewBootFile("", "EFI/boot/bootx64.efi", bootloader.RoleRecovery),
+		bootloader.NewBootFile("kernel.snap", "kernel.efi", bootloader.RoleRecovery),
+	})
+	chain, err = rbl.BootChain(runBl, "kernel.snap")
+	c.Assert(err, IsNil)
+	c.Check(chain, DeepEquals, []bootloader.BootFile{
+		bootloader.NewBootFile("", "EFI/boot/bootx64.efi", bootloader.RoleRecovery),
+		bootloader.NewBootFile("kernel.snap", "kernel.efi", bootloader.RoleRunMode),
+	})
+
+	_, err = runBl.RecoveryBootChain("kernel.snap")
+	c.Assert(err, ErrorMatches, "not a recovery bootloader")
+	_, err = rbl.BootChain(bootloadertest.Mock("mock", c.MkDir()), "kernel.snap")
+	c.Assert(err, ErrorMatches, "run mode bootloader must be systemd-boot")
+}
+
+func (s *sdbootTestSuite) TestCommandLine(c *C) {
+	rbl := s.recoveryBootloader(c).(bootloader.TrustedAssetsBootloader)
+
+	cmdline, err := rbl.CommandLine("snapd_recovery_mode=recover", "snapd_recovery_system=20210512", "")
+	c.Assert(err, IsNil)
+	c.Check(cmdline, Equals, "snapd_recovery_mode=recover snapd_recovery_system=20210512 console=ttyS0 console=tty1 panic=-1")
+	cmdline, err = rbl.CandidateCommandLine("snapd_recovery_mode=recover", "snapd_recovery_system=20210512", "")
+	c.Assert(err, IsNil)
+	c.Check(cmdline, Equals, "snapd_recovery_mode=recover snapd_recovery_system=20210512 console=ttyS0 console=tty1 panic=-1")
+
+	_, err = rbl.CommandLine("snapd_recovery_mode=recover", "", `foo="bar`)
+	c.Assert(err, ErrorMatches, "cannot use badly formatted kernel command line: .*")
+}
+
+func (s *sdbootTestSuite) TestUpdateBootConfig(c *C) {
+	rbl := s.recoveryBootloader(c).(bootloader.TrustedAssetsBootloader)
+	bl := s.runBootloader(c)
+	runBl := bl.(bootloader.TrustedAssetsBootloader)
+	c.Assert(bl.EnableKernel(s.makeKernelAssetSnap(c, "pc-kernel_1.snap")), IsNil)
+
+	// already up to date
+	updated, err := rbl.UpdateBootConfig()
+	c.Assert(err, IsNil)
+	c.Check(updated, Equals, false)
+	updated, err = runBl.UpdateBootConfig()
+	c.Assert(err, IsNil)
+	c.Check(updated, Equals, false)
+
+	// a newer edition of the built-in config updates both
+	restore := assets.MockInternal("systemd-boot-loader.conf", []byte("# Snapd-Boot-Config-Edition: 2\ntimeout 1\n"))
+	defer restore()
+	restore = assets.MockSnippetsForEdition("systemd-boot-loader.conf:static-cmdline", []assets.ForEditions{
+		{FirstEdition: 1, Snippet: []byte("console=ttyS0 console=tty1 panic=-1")},
+		{FirstEdition: 2, Snippet: []byte("panic=-1")},
+	})
+	defer restore()
+
+	updated, err = rbl.UpdateBootConfig()
+	c.Assert(err, IsNil)
+	c.Check(updated, Equals, true)
+	c.Check(filepath.Join(s.seedDir, "loader/loader.conf"), testutil.FileEquals, "# Snapd-Boot-Config-Edition: 2\ntimeout 1\n")
+
+	cmdline, err := runBl.CommandLine("snapd_recovery_mode=run", "", "")
+	c.Assert(err, IsNil)
+	c.Check(cmdline, Equals, "snapd_recovery_mode=run console=ttyS0 console=tty1 panic=-1")
+	cmdline, err = runBl.CandidateCommandLine("snapd_recovery_mode=run", "", "")
+	c.Assert(err, IsNil)
+	c.Check(cmdline, Equals, "snapd_recovery_mode=run panic=-1")
+
+	updated, err = runBl.UpdateBootConfig()
+	c.Assert(err, IsNil)
+	c.Check(updated, Equals, true)
+	c.Check(s.entry("snapd-run.conf"), testutil.FileEquals, `# Snapd-Boot-Config-Edition: 2
+title Ubuntu Core
+sort-key snapd-3
+efi /EFI/ubuntu/pc-kernel_1.snap/kernel.efi
+options snapd_recovery_mode=run panic=-1
+`)
+	cmdline, err = runBl.CommandLine("snapd_recovery_mode=run", "", "")
+	c.Assert(err, IsNil)
+	c.Check(cmdline, Equals, "snapd_recovery_mode=run panic=-1")
+}
@@ -747,3 +747,51 @@ func (client *Client) SystemRecoveryKeys(result interface{}) error {
 	_, err := client.doSync("GET", "/v2/system-recovery-keys", nil, nil, nil, &result)
 	return err
 }
+
+type recoveryKeysAction struct {
+	Action     string `json:"action"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+func (client *Client) recoveryKeysAction(action, passphrase string) (changeID string, err error) {
+	body, err := json.Marshal(recoveryKeysAction{
+		Action:     action,
+		Passphrase: passphrase,
+	})
+	if err != nil {
+		return "", err
+	}
+	return client.doAsync("POST", "/v2/system-recovery-keys", nil, nil, bytes.NewReader(body))
+}
+
+// RotateSystemRecoveryKeys replaces the recovery keys of the encrypted
+// partitions with newly generated ones, it returns the ID of the change
+// doing it.
+func (client *Client) RotateSystemRecoveryKeys() (changeID string, err error) {
+	changeID, err = client.recoveryKeysAction("rotate", "")
+	if err != nil {
+		return "", fmt.Errorf("cannot rotate recovery keys: %v", err)
+	}
+	return changeID, nil
+}
+
+// AddSystemPassphrase adds the passphrase as an alternative way to unlock the
+// encrypted partitions, it returns the ID of the change doing it.
+func (client *Client) AddSystemPassphrase(passphrase string) (changeID string, err error) {
+	changeID, err = client.recoveryKeysAction("add-passphrase", passphrase)
+	if err != nil {
+		return "", fmt.Errorf("cannot add passphrase: %v", err)
+	}
+	return changeID, nil
+}
+
+// RemoveSystemPassphrase removes the passphrase previously added with
+// AddSystemPassphrase from the encrypted partitions, it returns the ID of the
+// change doing it.
+func (client *Client) RemoveSystemPassphrase(passphrase string) (changeID string, err error) {
+	changeID, err = client.recoveryKeysAction("remove-passphrase", passphrase)
+	if err != nil {
+		return "", fmt.Errorf("cannot remove passphrase: %v", err)
+	}
+	return changeID, nil
+}
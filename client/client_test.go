@@ -641,3 +641,37 @@ func (cs *clientSuite) TestClientSystemRecoveryKeys(c *C) {
 	c.Check(cs.reqs[0].URL.Path, Equals, "/v2/system-recovery-keys")
 	c.Check(key.RecoveryKey, Equals, "42")
 }
+
+func (cs *clientSuite) TestClientSystemRecoveryKeysActions(c *C) {
+	for _, tc := range []struct {
+		do   func() (string, error)
+		body string
+	}{
+		{cs.cli.RotateSystemRecoveryKeys, `{"action":"rotate"}`},
+		{func() (string, error) { return cs.cli.AddSystemPassphrase("open sesame") }, `{"action":"add-passphrase","passphrase":"open sesame"}`},
+		{func() (string, error) { return cs.cli.RemoveSystemPassphrase("open sesame") }, `{"action":"remove-passphrase","passphrase":"open sesame"}`},
+	} {
+		cs.reqs = nil
+		cs.status = 202
+		cs.rsp = `{"type":"async", "status-code":202, "change":"42"}`
+		chgID, err := tc.do()
+		c.Assert(err, IsNil)
+		c.Check(chgID, Equals, "42")
+		c.Assert(cs.reqs, HasLen, 1)
+		c.Check(cs.reqs[0].Method, Equals, "POST")
+		c.Check(cs.reqs[0].URL.Path, Equals, "/v2/system-recovery-keys")
+		data, err := ioutil.ReadAll(cs.reqs[0].Body)
+		c.Assert(err, IsNil)
+		c.Check(string(data), Equals, tc.body)
+	}
+}
+
+func (cs *clientSuite) TestClientSystemRecoveryKeysActionsError(c *C) {
+	cs.rsp = `{"type":"error", "status-code":400, "result":{"message":"no encrypted volumes with recovery keys"}}`
+	_, err := cs.cli.RotateSystemRecoveryKeys()
+	c.Check(err, ErrorMatches, "cannot rotate recovery keys: no encrypted volumes with recovery keys")
+	_, err = cs.cli.AddSystemPassphrase("open sesame")
+	c.Check(err, ErrorMatches, "cannot add passphrase: no encrypted volumes with recovery keys")
+	_, err = cs.cli.RemoveSystemPassphrase("open sesame")
+	c.Check(err, ErrorMatches, "cannot remove passphrase: no encrypted volumes with recovery keys")
+}
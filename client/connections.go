@@ -21,6 +21,7 @@ package client
 
 import (
 	"net/url"
+	"time"
 )
 
 // Connection describes a connection between a plug and a slot.
@@ -36,6 +37,9 @@ type Connection struct {
 	SlotAttrs map[string]interface{} `json:"slot-attrs,omitempty"`
 	// PlugAttrs is the list of attributes of the plug side of the connection.
 	PlugAttrs map[string]interface{} `json:"plug-attrs,omitempty"`
+	// Expiry is the time after which a time-limited connection is
+	// removed, it is zero for other connections.
+	Expiry time.Time `json:"expiry,omitempty"`
 }
 
 // Connections contains information about connections, as well as related plugs
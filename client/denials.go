@@ -0,0 +1,53 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"time"
+)
+
+// SandboxDenial is an aggregation of the identical AppArmor or seccomp
+// denials logged for a snap application or hook.
+type SandboxDenial struct {
+	Snap string `json:"snap"`
+	// App is the name of the application, or "hook.<name>" for hooks.
+	App string `json:"app,omitempty"`
+	// Class is one of file, capability, network, dbus, signal, ptrace
+	// or other for AppArmor denials, or syscall for seccomp denials.
+	Class          string    `json:"class"`
+	Operation      string    `json:"operation,omitempty"`
+	Target         string    `json:"target"`
+	Permissions    string    `json:"permissions,omitempty"`
+	Count          int       `json:"count"`
+	FirstSeen      time.Time `json:"first-seen"`
+	LastSeen       time.Time `json:"last-seen"`
+	SuggestedPlugs []string  `json:"suggested-plugs,omitempty"`
+}
+
+// SandboxDenials returns the sandbox denials logged for the given snap
+// since boot, together with the interfaces whose plugs would allow the
+// denied accesses.
+func (client *Client) SandboxDenials(snapName string) ([]*SandboxDenial, error) {
+	var denials []*SandboxDenial
+	if _, err := client.doSync("GET", "/v2/snaps/"+snapName+"/denials", nil, nil, nil, &denials); err != nil {
+		return nil, err
+	}
+	return denials, nil
+}
@@ -0,0 +1,75 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"errors"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+)
+
+func (cs *clientSuite) TestClientSandboxDenials(c *check.C) {
+	cs.rsp = `{
+		"result": [
+		    {
+			"snap": "foo",
+			"app": "app",
+			"class": "file",
+			"operation": "open",
+			"target": "/dev/video0",
+			"permissions": "r",
+			"count": 3,
+			"first-seen": "2026-10-01T12:00:00Z",
+			"last-seen": "2026-10-01T12:05:00Z",
+			"suggested-plugs": ["camera"]
+		    }
+		],
+		"status": "OK",
+		"status-code": 200,
+		"type": "sync"
+	}`
+
+	denials, err := cs.cli.SandboxDenials("foo")
+	c.Assert(err, check.IsNil)
+	c.Check(denials, check.DeepEquals, []*client.SandboxDenial{{
+		Snap:           "foo",
+		App:            "app",
+		Class:          "file",
+		Operation:      "open",
+		Target:         "/dev/video0",
+		Permissions:    "r",
+		Count:          3,
+		FirstSeen:      time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		LastSeen:       time.Date(2026, 10, 1, 12, 5, 0, 0, time.UTC),
+		SuggestedPlugs: []string{"camera"},
+	}})
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps/foo/denials")
+}
+
+func (cs *clientSuite) TestClientSandboxDenialsError(c *check.C) {
+	cs.err = errors.New("boom")
+
+	_, err := cs.cli.SandboxDenials("foo")
+	c.Check(err, check.ErrorMatches, `.*boom`)
+}
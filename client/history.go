@@ -0,0 +1,81 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/snapcore/snapd/snap"
+)
+
+// HistoryEntry records the outcome of an install, refresh, revert or
+// removal of a snap.
+type HistoryEntry struct {
+	Snap         string        `json:"snap"`
+	Action       string        `json:"action"`
+	FromRevision snap.Revision `json:"from-revision"`
+	ToRevision   snap.Revision `json:"to-revision"`
+	Channel      string        `json:"channel,omitempty"`
+	Trigger      string        `json:"trigger"`
+	Time         time.Time     `json:"time"`
+	Outcome      string        `json:"outcome"`
+	ChangeID     string        `json:"change-id"`
+}
+
+// History returns the recorded install, refresh, revert and removal
+// history of the given snap, or of all snaps if snapName is empty,
+// from oldest to newest.
+func (client *Client) History(snapName string) ([]*HistoryEntry, error) {
+	q := make(url.Values)
+	if snapName != "" {
+		q.Set("snap", snapName)
+	}
+
+	var entries []*HistoryEntry
+	if _, err := client.doSync("GET", "/v2/history", q, nil, nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// BootHistoryEntry records a boot of the system, the kernel and base booted
+// or the recovery system, whether a new kernel or base was tried and how the
+// boot ended, one of booted, trying, success or reverted.
+type BootHistoryEntry struct {
+	BootID         string    `json:"boot-id"`
+	Time           time.Time `json:"time"`
+	Mode           string    `json:"mode"`
+	Kernel         string    `json:"kernel,omitempty"`
+	Base           string    `json:"base,omitempty"`
+	RecoverySystem string    `json:"recovery-system,omitempty"`
+	Try            bool      `json:"try,omitempty"`
+	Outcome        string    `json:"outcome"`
+}
+
+// BootHistory returns the recorded boots of the system, from oldest to
+// newest.
+func (client *Client) BootHistory() ([]*BootHistoryEntry, error) {
+	var entries []*BootHistoryEntry
+	if _, err := client.doSync("GET", "/v2/systems/boot-history", nil, nil, nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
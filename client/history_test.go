@@ -0,0 +1,137 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"errors"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/snap"
+)
+
+func (cs *clientSuite) TestClientHistory(c *check.C) {
+	cs.rsp = `{
+		"result": [
+		    {
+			"snap": "foo",
+			"action": "refresh",
+			"from-revision": "1",
+			"to-revision": "2",
+			"channel": "latest/stable",
+			"trigger": "auto",
+			"time": "2026-10-01T12:00:00Z",
+			"outcome": "done",
+			"change-id": "42"
+		    }
+		],
+		"status": "OK",
+		"status-code": 200,
+		"type": "sync"
+	}`
+
+	entries, err := cs.cli.History("foo")
+	c.Assert(err, check.IsNil)
+	c.Check(entries, check.DeepEquals, []*client.HistoryEntry{{
+		Snap:         "foo",
+		Action:       "refresh",
+		FromRevision: snap.R(1),
+		ToRevision:   snap.R(2),
+		Channel:      "latest/stable",
+		Trigger:      "auto",
+		Time:         time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Outcome:      "done",
+		ChangeID:     "42",
+	}})
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/history")
+	c.Check(cs.req.URL.Query().Get("snap"), check.Equals, "foo")
+}
+
+func (cs *clientSuite) TestClientHistoryAll(c *check.C) {
+	cs.rsp = `{"result": [], "status": "OK", "status-code": 200, "type": "sync"}`
+
+	entries, err := cs.cli.History("")
+	c.Assert(err, check.IsNil)
+	c.Check(entries, check.HasLen, 0)
+	c.Check(cs.req.URL.Query(), check.HasLen, 0)
+}
+
+func (cs *clientSuite) TestClientHistoryError(c *check.C) {
+	cs.err = errors.New("boom")
+
+	_, err := cs.cli.History("foo")
+	c.Check(err, check.ErrorMatches, `.*boom`)
+}
+
+func (cs *clientSuite) TestClientBootHistory(c *check.C) {
+	cs.rsp = `{
+		"result": [
+		    {
+			"boot-id": "boot-id-1",
+			"time": "2026-10-01T12:00:00Z",
+			"mode": "run",
+			"kernel": "pc-kernel_2.snap",
+			"base": "core20_1.snap",
+			"try": true,
+			"outcome": "reverted"
+		    },
+		    {
+			"boot-id": "boot-id-2",
+			"time": "2026-10-01T12:05:00Z",
+			"mode": "recover",
+			"recovery-system": "20201212",
+			"outcome": "booted"
+		    }
+		],
+		"status": "OK",
+		"status-code": 200,
+		"type": "sync"
+	}`
+
+	entries, err := cs.cli.BootHistory()
+	c.Assert(err, check.IsNil)
+	c.Check(entries, check.DeepEquals, []*client.BootHistoryEntry{{
+		BootID:  "boot-id-1",
+		Time:    time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Mode:    "run",
+		Kernel:  "pc-kernel_2.snap",
+		Base:    "core20_1.snap",
+		Try:     true,
+		Outcome: "reverted",
+	}, {
+		BootID:         "boot-id-2",
+		Time:           time.Date(2026, 10, 1, 12, 5, 0, 0, time.UTC),
+		Mode:           "recover",
+		RecoverySystem: "20201212",
+		Outcome:        "booted",
+	}})
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/systems/boot-history")
+}
+
+func (cs *clientSuite) TestClientBootHistoryError(c *check.C) {
+	cs.err = errors.New("boom")
+
+	_, err := cs.cli.BootHistory()
+	c.Check(err, check.ErrorMatches, `.*boom`)
+}
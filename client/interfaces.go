@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // Plug represents the potential of a given snap to connect to a slot.
@@ -52,6 +53,16 @@ type Slot struct {
 	Apps        []string               `json:"apps,omitempty"`
 	Label       string                 `json:"label,omitempty"`
 	Connections []PlugRef              `json:"connections,omitempty"`
+	// Hotplug is only set for hotplug slots, and only when requested
+	// with InterfaceOptions.Hotplug.
+	Hotplug *HotplugInfo `json:"hotplug,omitempty"`
+}
+
+// HotplugInfo describes the device backing a hotplug slot.
+type HotplugInfo struct {
+	Key string `json:"key"`
+	// Device holds the udev properties of the device.
+	Device map[string]string `json:"device,omitempty"`
 }
 
 // SlotRef is a reference to a slot.
@@ -65,14 +76,31 @@ type Interface struct {
 	Name    string `json:"name,omitempty"`
 	Summary string `json:"summary,omitempty"`
 	DocURL  string `json:"doc-url,omitempty"`
-	Plugs   []Plug `json:"plugs,omitempty"`
-	Slots   []Slot `json:"slots,omitempty"`
+	// PlugAttrs and SlotAttrs describe the attributes of plugs and
+	// slots, they are only set when requested with
+	// InterfaceOptions.Attrs and declared by the interface.
+	PlugAttrs map[string]AttrSpec `json:"plug-attrs,omitempty"`
+	SlotAttrs map[string]AttrSpec `json:"slot-attrs,omitempty"`
+	Plugs     []Plug              `json:"plugs,omitempty"`
+	Slots     []Slot              `json:"slots,omitempty"`
+}
+
+// AttrSpec describes a plug or slot attribute accepted by an interface.
+type AttrSpec struct {
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Enum     []string `json:"enum,omitempty"`
+	Path     string   `json:"path,omitempty"`
+	// Attrs describes the keys of map attributes.
+	Attrs map[string]AttrSpec `json:"attrs,omitempty"`
 }
 
 // InterfaceAction represents an action performed on the interface system.
 type InterfaceAction struct {
 	Action string `json:"action"`
 	Forget bool   `json:"forget,omitempty"`
+	DryRun bool   `json:"dry-run,omitempty"`
+	For    string `json:"for,omitempty"`
 	Plugs  []Plug `json:"plugs,omitempty"`
 	Slots  []Slot `json:"slots,omitempty"`
 }
@@ -81,9 +109,11 @@ type InterfaceAction struct {
 type InterfaceOptions struct {
 	Names     []string
 	Doc       bool
+	Attrs     bool
 	Plugs     bool
 	Slots     bool
 	Connected bool
+	Hotplug   bool
 }
 
 // DisconnectOptions represents extra options for disconnect op
@@ -100,12 +130,18 @@ func (client *Client) Interfaces(opts *InterfaceOptions) ([]*Interface, error) {
 		if opts.Doc {
 			query.Set("doc", "true") // Return documentation of each selected interface.
 		}
+		if opts.Attrs {
+			query.Set("attrs", "true") // Return the attribute schema of each selected interface.
+		}
 		if opts.Plugs {
 			query.Set("plugs", "true") // Return plugs of each selected interface.
 		}
 		if opts.Slots {
 			query.Set("slots", "true") // Return slots of each selected interface.
 		}
+		if opts.Hotplug {
+			query.Set("hotplug", "true") // Return the devices backing hotplug slots.
+		}
 	}
 	// NOTE: Presence of "select" triggers the use of the new response format.
 	if opts != nil && opts.Connected {
@@ -138,6 +174,112 @@ func (client *Client) Connect(plugSnapName, plugName, slotSnapName, slotName str
 	})
 }
 
+// ConnectFor establishes a connection between a plug and a slot that
+// is removed automatically after the given duration.
+func (client *Client) ConnectFor(plugSnapName, plugName, slotSnapName, slotName string, duration time.Duration) (changeID string, err error) {
+	return client.performInterfaceAction(&InterfaceAction{
+		Action: "connect",
+		For:    duration.String(),
+		Plugs:  []Plug{{Snap: plugSnapName, Name: plugName}},
+		Slots:  []Slot{{Snap: slotSnapName, Name: slotName}},
+	})
+}
+
+// SecurityProfile is a security profile file of a snap as rendered by
+// a security backend.
+type SecurityProfile struct {
+	Backend string `json:"backend"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// ProfileChange describes how a security profile file of a snap would
+// change. Old is empty for new files and New is empty for removed ones.
+type ProfileChange struct {
+	Snap    string `json:"snap"`
+	Backend string `json:"backend"`
+	Path    string `json:"path"`
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
+
+// PolicyRule identifies the declaration rule used to decide about a
+// connection.
+type PolicyRule struct {
+	Assertion string `json:"assertion"`
+	SnapID    string `json:"snap-id,omitempty"`
+	SnapName  string `json:"snap-name,omitempty"`
+	Revision  int    `json:"revision,omitempty"`
+	Side      string `json:"side"`
+	Interface string `json:"interface"`
+}
+
+// PolicyCheck is the outcome of checking one alternative of the
+// constraints of a declaration rule.
+type PolicyCheck struct {
+	Constraint  string `json:"constraint"`
+	Alternative int    `json:"alternative"`
+	Matched     bool   `json:"matched"`
+	Error       string `json:"error,omitempty"`
+}
+
+// PolicyExplanation describes how the declaration rules were evaluated
+// for a connection or an auto-connection.
+type PolicyExplanation struct {
+	Kind    string         `json:"kind"`
+	Allowed bool           `json:"allowed"`
+	Rule    *PolicyRule    `json:"rule,omitempty"`
+	Checks  []*PolicyCheck `json:"checks,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// ConnectionExplanation explains whether a plug can be connected to a
+// slot, manually or automatically.
+type ConnectionExplanation struct {
+	Plug                 PlugRef            `json:"plug"`
+	Slot                 SlotRef            `json:"slot"`
+	Interface            string             `json:"interface"`
+	Connection           *PolicyExplanation `json:"connection"`
+	AutoConnection       *PolicyExplanation `json:"auto-connection"`
+	InterfaceAutoConnect bool               `json:"interface-auto-connect"`
+	Notes                []string           `json:"notes,omitempty"`
+}
+
+// ExplainConnection asks snapd to explain which declaration rules
+// allow or prevent connecting the given plug and slot. The slot may be
+// left empty to let snapd pick it as for connect.
+func (client *Client) ExplainConnection(plugSnapName, plugName, slotSnapName, slotName string) (*ConnectionExplanation, error) {
+	params := map[string]string{"plug": plugSnapName + ":" + plugName}
+	if slotSnapName != "" || slotName != "" {
+		params["slot"] = slotSnapName + ":" + slotName
+	}
+	var ex ConnectionExplanation
+	if err := client.DebugGet("policy", &ex, params); err != nil {
+		return nil, err
+	}
+	return &ex, nil
+}
+
+// ConnectDryRun returns how the security profiles of the snaps
+// involved would change if the plug and the slot were connected,
+// without connecting them.
+func (client *Client) ConnectDryRun(plugSnapName, plugName, slotSnapName, slotName string) ([]*ProfileChange, error) {
+	b, err := json.Marshal(&InterfaceAction{
+		Action: "connect",
+		DryRun: true,
+		Plugs:  []Plug{{Snap: plugSnapName, Name: plugName}},
+		Slots:  []Slot{{Snap: slotSnapName, Name: slotName}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var changes []*ProfileChange
+	if _, err := client.doSync("POST", "/v2/interfaces", nil, nil, bytes.NewReader(b), &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
 // Disconnect breaks the connection between a plug and a slot.
 func (client *Client) Disconnect(plugSnapName, plugName, slotSnapName, slotName string, opts *DisconnectOptions) (changeID string, err error) {
 	return client.performInterfaceAction(&InterfaceAction{
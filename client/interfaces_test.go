@@ -21,6 +21,8 @@ package client_test
 
 import (
 	"encoding/json"
+	"net/url"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -42,6 +44,75 @@ func (cs *clientSuite) TestClientInterfacesOptionEncoding(c *check.C) {
 		"doc=true&names=a%2Cb&plugs=true&select=connected&slots=true")
 }
 
+func (cs *clientSuite) TestClientInterfacesHotplug(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [{
+			"name": "serial-port",
+			"slots": [{
+				"snap": "core",
+				"slot": "serial-ft232r-a50285bi",
+				"attrs": {"path": "/dev/ttyUSB0"},
+				"hotplug": {
+					"key": "1234",
+					"device": {"DEVNAME": "/dev/ttyUSB0", "SUBSYSTEM": "tty"}
+				}
+			}]
+		}]
+	}`
+	ifaces, err := cs.cli.Interfaces(&client.InterfaceOptions{
+		Names:   []string{"serial-port"},
+		Slots:   true,
+		Hotplug: true,
+	})
+	c.Check(cs.req.URL.RawQuery, check.Equals, "hotplug=true&names=serial-port&select=all&slots=true")
+	c.Assert(err, check.IsNil)
+	c.Check(ifaces, check.DeepEquals, []*client.Interface{{
+		Name: "serial-port",
+		Slots: []client.Slot{{
+			Snap:  "core",
+			Name:  "serial-ft232r-a50285bi",
+			Attrs: map[string]interface{}{"path": "/dev/ttyUSB0"},
+			Hotplug: &client.HotplugInfo{
+				Key:    "1234",
+				Device: map[string]string{"DEVNAME": "/dev/ttyUSB0", "SUBSYSTEM": "tty"},
+			},
+		}},
+	}})
+}
+
+func (cs *clientSuite) TestClientInterfacesAttrs(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [{
+			"name": "content",
+			"plug-attrs": {
+				"target": {"type": "string", "required": true, "path": "clean"}
+			},
+			"slot-attrs": {
+				"source": {"type": "map", "attrs": {"read": {"type": "list", "path": "clean"}}}
+			}
+		}]
+	}`
+	ifaces, err := cs.cli.Interfaces(&client.InterfaceOptions{
+		Names: []string{"content"},
+		Attrs: true,
+	})
+	c.Check(cs.req.URL.RawQuery, check.Equals, "attrs=true&names=content&select=all")
+	c.Assert(err, check.IsNil)
+	c.Check(ifaces, check.DeepEquals, []*client.Interface{{
+		Name: "content",
+		PlugAttrs: map[string]client.AttrSpec{
+			"target": {Type: "string", Required: true, Path: "clean"},
+		},
+		SlotAttrs: map[string]client.AttrSpec{
+			"source": {Type: "map", Attrs: map[string]client.AttrSpec{
+				"read": {Type: "list", Path: "clean"},
+			}},
+		},
+	}})
+}
+
 func (cs *clientSuite) TestClientInterfacesAll(c *check.C) {
 	// Ask for a summary of all interfaces.
 	cs.rsp = `{
@@ -194,6 +265,116 @@ func (cs *clientSuite) TestClientConnect(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestClientConnectFor(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+                "status-code": 202,
+		"result": { },
+                "change": "foo"
+	}`
+	id, err := cs.cli.ConnectFor("producer", "plug", "consumer", "slot", 2*time.Hour)
+	c.Assert(err, check.IsNil)
+	c.Check(id, check.Equals, "foo")
+	var body map[string]interface{}
+	decoder := json.NewDecoder(cs.req.Body)
+	err = decoder.Decode(&body)
+	c.Check(err, check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]interface{}{
+		"action": "connect",
+		"for":    "2h0m0s",
+		"plugs": []interface{}{
+			map[string]interface{}{
+				"snap": "producer",
+				"plug": "plug",
+			},
+		},
+		"slots": []interface{}{
+			map[string]interface{}{
+				"snap": "consumer",
+				"slot": "slot",
+			},
+		},
+	})
+}
+
+func (cs *clientSuite) TestClientConnectDryRun(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [
+			{"snap": "producer", "backend": "apparmor", "path": "/profile", "old": "a\n", "new": "a\nb\n"}
+		]
+	}`
+	changes, err := cs.cli.ConnectDryRun("producer", "plug", "consumer", "slot")
+	c.Assert(err, check.IsNil)
+	c.Check(changes, check.DeepEquals, []*client.ProfileChange{
+		{Snap: "producer", Backend: "apparmor", Path: "/profile", Old: "a\n", New: "a\nb\n"},
+	})
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/interfaces")
+	var body map[string]interface{}
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&body), check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]interface{}{
+		"action":  "connect",
+		"dry-run": true,
+		"plugs": []interface{}{
+			map[string]interface{}{
+				"snap": "producer",
+				"plug": "plug",
+			},
+		},
+		"slots": []interface{}{
+			map[string]interface{}{
+				"snap": "consumer",
+				"slot": "slot",
+			},
+		},
+	})
+}
+
+func (cs *clientSuite) TestClientExplainConnection(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": {
+			"plug": {"snap": "consumer", "plug": "plug"},
+			"slot": {"snap": "producer", "slot": "slot"},
+			"interface": "test",
+			"connection": {
+				"kind": "connection",
+				"allowed": true,
+				"rule": {"assertion": "base-declaration", "side": "slot", "interface": "test"},
+				"checks": [{"constraint": "allow-connection", "alternative": 0, "matched": true}]
+			},
+			"auto-connection": {"kind": "auto-connection", "allowed": false, "error": "denied"},
+			"interface-auto-connect": true,
+			"notes": ["a note"]
+		}
+	}`
+	ex, err := cs.cli.ExplainConnection("consumer", "plug", "producer", "")
+	c.Assert(err, check.IsNil)
+	c.Check(ex, check.DeepEquals, &client.ConnectionExplanation{
+		Plug:      client.PlugRef{Snap: "consumer", Name: "plug"},
+		Slot:      client.SlotRef{Snap: "producer", Name: "slot"},
+		Interface: "test",
+		Connection: &client.PolicyExplanation{
+			Kind:    "connection",
+			Allowed: true,
+			Rule:    &client.PolicyRule{Assertion: "base-declaration", Side: "slot", Interface: "test"},
+			Checks:  []*client.PolicyCheck{{Constraint: "allow-connection", Matched: true}},
+		},
+		AutoConnection:       &client.PolicyExplanation{Kind: "auto-connection", Error: "denied"},
+		InterfaceAutoConnect: true,
+		Notes:                []string{"a note"},
+	})
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/debug")
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"aspect": {"policy"},
+		"plug":   {"consumer:plug"},
+		"slot":   {"producer:"},
+	})
+}
+
 func (cs *clientSuite) TestClientDisconnectCallsEndpoint(c *check.C) {
 	cs.cli.Disconnect("producer", "plug", "consumer", "slot", nil)
 	c.Check(cs.req.Method, check.Equals, "POST")
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -125,6 +126,9 @@ const (
 
 type ResultInfo struct {
 	SuggestedCurrency string `json:"suggested-currency"`
+	// NextCursor is set when more results are available; pass it as
+	// FindOptions.Cursor to retrieve them.
+	NextCursor string `json:"next-cursor"`
 }
 
 // FindOptions supports exactly one of the following options:
@@ -143,6 +147,27 @@ type FindOptions struct {
 	Scope   string
 
 	Refresh bool
+
+	// Publisher, Verified, Confinement, Architecture, License and
+	// Base further restrict the results returned by the store. The
+	// store does not support Verified, License and Base, so these only
+	// filter the results it returned and cannot be used with Cursor.
+	Publisher    string
+	Verified     bool
+	Confinement  string
+	Architecture string
+	License      string
+	Base         string
+
+	// Sort is one of "name", "popularity" or "last-updated".
+	Sort string
+
+	// Limit is the maximum number of results to return, Cursor the
+	// point from which to continue a previous search. Only the first
+	// page of results returned by the store can be browsed this way,
+	// matching snaps beyond it are never returned.
+	Limit  int
+	Cursor string
 }
 
 var ErrNoSnapsInstalled = errors.New("no snaps installed")
@@ -222,6 +247,33 @@ func (client *Client) Find(opts *FindOptions) ([]*Snap, *ResultInfo, error) {
 	if opts.Scope != "" {
 		q.Set("scope", opts.Scope)
 	}
+	if opts.Publisher != "" {
+		q.Set("publisher", opts.Publisher)
+	}
+	if opts.Verified {
+		q.Set("verified", "true")
+	}
+	if opts.Confinement != "" {
+		q.Set("confinement", opts.Confinement)
+	}
+	if opts.Architecture != "" {
+		q.Set("architecture", opts.Architecture)
+	}
+	if opts.License != "" {
+		q.Set("license", opts.License)
+	}
+	if opts.Base != "" {
+		q.Set("base", opts.Base)
+	}
+	if opts.Sort != "" {
+		q.Set("sort", opts.Sort)
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		q.Set("cursor", opts.Cursor)
+	}
 
 	return client.snapsFromPath("/v2/find", q)
 }
@@ -97,6 +97,41 @@ func (cs *clientSuite) TestClientFindWithScopeSetsQuery(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestClientFindWithFiltersSetsQuery(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [],
+		"next-cursor": "20"
+	}`
+	_, ri, err := cs.cli.Find(&client.FindOptions{
+		Query:        "foo",
+		Publisher:    "acme",
+		Verified:     true,
+		Confinement:  "strict",
+		Architecture: "arm64",
+		License:      "MIT",
+		Base:         "core20",
+		Sort:         "last-updated",
+		Limit:        10,
+		Cursor:       "10",
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(ri.NextCursor, check.Equals, "20")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/find")
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"q":            []string{"foo"},
+		"publisher":    []string{"acme"},
+		"verified":     []string{"true"},
+		"confinement":  []string{"strict"},
+		"architecture": []string{"arm64"},
+		"license":      []string{"MIT"},
+		"base":         []string{"core20"},
+		"sort":         []string{"last-updated"},
+		"limit":        []string{"10"},
+		"cursor":       []string{"10"},
+	})
+}
+
 func (cs *clientSuite) TestClientSnapsInvalidSnapsJSON(c *check.C) {
 	cs.rsp = `{
 		"type": "sync",
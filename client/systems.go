@@ -116,14 +116,37 @@ func (client *Client) DoSystemAction(systemLabel string, action *SystemAction) e
 // Note that "recover" and "run" modes are only available for the
 // current system.
 func (client *Client) RebootToSystem(systemLabel, mode string) error {
+	return client.rebootToSystem(systemLabel, mode, false)
+}
+
+// FactoryResetOptions holds the options of a factory reset.
+type FactoryResetOptions struct {
+	// DiscardIdentity requests the device identity kept in ubuntu-save
+	// to not be preserved by the factory reset.
+	DiscardIdentity bool
+}
+
+// FactoryReset issues a request to reboot into the factory-reset mode of
+// the system with the given label, or of the current system when no label
+// is given.
+func (client *Client) FactoryReset(systemLabel string, opts *FactoryResetOptions) error {
+	if opts == nil {
+		opts = &FactoryResetOptions{}
+	}
+	return client.rebootToSystem(systemLabel, "factory-reset", opts.DiscardIdentity)
+}
+
+func (client *Client) rebootToSystem(systemLabel, mode string, discardIdentity bool) error {
 	// verification is done by the backend
 
 	req := struct {
-		Action string `json:"action"`
-		Mode   string `json:"mode"`
+		Action          string `json:"action"`
+		Mode            string `json:"mode"`
+		DiscardIdentity bool   `json:"discard-identity,omitempty"`
 	}{
-		Action: "reboot",
-		Mode:   mode,
+		Action:          "reboot",
+		Mode:            mode,
+		DiscardIdentity: discardIdentity,
 	}
 
 	var body bytes.Buffer
@@ -138,3 +161,30 @@ func (client *Client) RebootToSystem(systemLabel, mode string) error {
 	}
 	return nil
 }
+
+// CreateSystem issues a request to create a new recovery system with the
+// given label out of the currently installed snaps. It returns the ID of the
+// change tracking the operation.
+func (client *Client) CreateSystem(systemLabel string) (changeID string, err error) {
+	if systemLabel == "" {
+		return "", fmt.Errorf("cannot create a recovery system without a label")
+	}
+
+	req := struct {
+		Action string `json:"action"`
+		Label  string `json:"label"`
+	}{
+		Action: "create",
+		Label:  systemLabel,
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(&req); err != nil {
+		return "", err
+	}
+	changeID, err = client.doAsync("POST", "/v2/systems", nil, nil, &body)
+	if err != nil {
+		return "", xerrors.Errorf("cannot create recovery system %q: %v", systemLabel, err)
+	}
+	return changeID, nil
+}
@@ -192,6 +192,40 @@ func (cs *clientSuite) TestRequestSystemRebootHappy(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestRequestFactoryReset(c *check.C) {
+	for _, tc := range []struct {
+		opts     *client.FactoryResetOptions
+		expected map[string]interface{}
+	}{
+		{nil, map[string]interface{}{
+			"action": "reboot",
+			"mode":   "factory-reset",
+		}},
+		{&client.FactoryResetOptions{DiscardIdentity: true}, map[string]interface{}{
+			"action":           "reboot",
+			"mode":             "factory-reset",
+			"discard-identity": true,
+		}},
+	} {
+		cs.rsp = `{
+		    "type": "sync",
+		    "status-code": 200,
+		    "result": {}
+		}`
+		err := cs.cli.FactoryReset("20201212", tc.opts)
+		c.Assert(err, check.IsNil)
+		c.Check(cs.req.Method, check.Equals, "POST")
+		c.Check(cs.req.URL.Path, check.Equals, "/v2/systems/20201212")
+
+		body, err := ioutil.ReadAll(cs.req.Body)
+		c.Assert(err, check.IsNil)
+		var req map[string]interface{}
+		err = json.Unmarshal(body, &req)
+		c.Assert(err, check.IsNil)
+		c.Check(req, check.DeepEquals, tc.expected)
+	}
+}
+
 func (cs *clientSuite) TestRequestSystemRebootErrorNoSystem(c *check.C) {
 	cs.rsp = `{
 	    "type": "error",
@@ -215,3 +249,44 @@ func (cs *clientSuite) TestRequestSystemRebootErrorWithSystem(c *check.C) {
 	c.Check(cs.req.Method, check.Equals, "POST")
 	c.Check(cs.req.URL.Path, check.Equals, "/v2/systems/1234")
 }
+
+func (cs *clientSuite) TestCreateSystemHappy(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+	    "type": "async",
+	    "status-code": 202,
+	    "result": {},
+	    "change": "42"
+	}`
+	chgID, err := cs.cli.CreateSystem("20261018")
+	c.Assert(err, check.IsNil)
+	c.Check(chgID, check.Equals, "42")
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/systems")
+
+	body, err := ioutil.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+	var req map[string]interface{}
+	err = json.Unmarshal(body, &req)
+	c.Assert(err, check.IsNil)
+	c.Assert(req, check.DeepEquals, map[string]interface{}{
+		"action": "create",
+		"label":  "20261018",
+	})
+}
+
+func (cs *clientSuite) TestCreateSystemError(c *check.C) {
+	cs.rsp = `{
+	    "type": "error",
+	    "status-code": 400,
+	    "result": {"message": "failed"}
+	}`
+	_, err := cs.cli.CreateSystem("20261018")
+	c.Assert(err, check.ErrorMatches, `cannot create recovery system "20261018": failed`)
+}
+
+func (cs *clientSuite) TestCreateSystemNoLabel(c *check.C) {
+	_, err := cs.cli.CreateSystem("")
+	c.Assert(err, check.ErrorMatches, `cannot create a recovery system without a label`)
+	c.Check(cs.req, check.IsNil)
+}
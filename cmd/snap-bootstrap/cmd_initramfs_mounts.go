@@ -139,6 +139,8 @@ func generateInitramfsMounts() (err error) {
 		return generateMountsModeRecover(mst)
 	case "install":
 		return generateMountsModeInstall(mst)
+	case "factory-reset":
+		return generateMountsModeFactoryReset(mst)
 	case "run":
 		return generateMountsModeRun(mst)
 	}
@@ -169,6 +171,50 @@ func generateMountsModeInstall(mst *initramfsMountsState) error {
 	return nil
 }
 
+func generateMountsModeFactoryReset(mst *initramfsMountsState) error {
+	// steps 1 and 2 are shared with install mode
+	if _, err := generateMountsCommonInstallRecover(mst); err != nil {
+		return err
+	}
+
+	// 3. try to unlock and mount ubuntu-save using the fallback key so that
+	//    the device identity kept there can be carried over to the new
+	//    system, ubuntu-data is going to be wiped so it is not unlocked
+	disk, err := disks.DiskFromMountPoint(boot.InitramfsUbuntuSeedDir, nil)
+	if err != nil {
+		return err
+	}
+	unlockOpts := &secboot.UnlockVolumeUsingSealedKeyOptions{
+		AllowRecoveryKey: true,
+		AllowPassphrase:  hasPassphrase("ubuntu-save"),
+	}
+	saveFallbackKey := filepath.Join(boot.InitramfsSeedEncryptionKeyDir, "ubuntu-save.recovery.sealed-key")
+	unlockRes, err := secbootUnlockVolumeUsingSealedKeyIfEncrypted(disk, "ubuntu-save", saveFallbackKey, unlockOpts)
+	switch {
+	case err != nil:
+		// not fatal, the system can still be reset, albeit without
+		// preserving its identity
+		logger.Noticef("cannot unlock ubuntu-save, device identity will not be preserved: %v", err)
+	case unlockRes.FsDevice != "":
+		if err := doSystemdMount(unlockRes.FsDevice, boot.InitramfsUbuntuSaveDir, nil); err != nil {
+			return err
+		}
+	}
+
+	// 4. final step: write modeenv to tmpfs data dir
+	modeEnv := &boot.Modeenv{
+		Mode:           "factory-reset",
+		RecoverySystem: mst.recoverySystem,
+	}
+	if err := modeEnv.WriteTo(boot.InitramfsWritableDir); err != nil {
+		return err
+	}
+
+	// done, no output, no error indicates to initramfs we are done with
+	// mounting stuff
+	return nil
+}
+
 // copyNetworkConfig copies the network configuration to the target
 // directory. This is used to copy the network configuration
 // data from a real uc20 ubuntu-data partition into a ephemeral one.
@@ -315,9 +361,10 @@ const (
 	partitionUnlocked     = "unlocked"
 	partitionErrUnlocking = "error-unlocking"
 	// keys used to unlock for UnlockKey
-	keyRun      = "run"
-	keyFallback = "fallback"
-	keyRecovery = "recovery"
+	keyRun        = "run"
+	keyFallback   = "fallback"
+	keyRecovery   = "recovery"
+	keyPassphrase = "passphrase"
 )
 
 // partitionState is the state of a partition after recover mode has completed
@@ -652,6 +699,8 @@ func (m *recoverModeStateMachine) setUnlockStateWithFallbackKey(partName string,
 			part.UnlockKey = keyFallback
 		case secboot.UnlockedWithRecoveryKey:
 			part.UnlockKey = keyRecovery
+		case secboot.UnlockedWithPassphrase:
+			part.UnlockKey = keyPassphrase
 
 			// TODO: should we fail with internal error for default case here?
 		}
@@ -825,6 +874,12 @@ func (m *recoverModeStateMachine) unlockDataRunKey() (stateFunc, error) {
 	return m.mountData, nil
 }
 
+// hasPassphrase returns whether a passphrase was added to the given encrypted
+// partition, as recorded on ubuntu-seed which must be mounted at this point.
+func hasPassphrase(partName string) bool {
+	return osutil.FileExists(filepath.Join(boot.InitramfsSeedEncryptionKeyDir, partName+".passphrase"))
+}
+
 func (m *recoverModeStateMachine) unlockDataFallbackKey() (stateFunc, error) {
 	// try to unlock data with the fallback key on ubuntu-seed, which must have
 	// been mounted at this point
@@ -833,6 +888,7 @@ func (m *recoverModeStateMachine) unlockDataFallbackKey() (stateFunc, error) {
 		// using the fallback object is the last chance before we give up trying
 		// to unlock data
 		AllowRecoveryKey: true,
+		AllowPassphrase:  hasPassphrase("ubuntu-data"),
 	}
 	// TODO: this prompts for a recovery key
 	// TODO: we should somehow customize the prompt to mention what key we need
@@ -915,6 +971,7 @@ func (m *recoverModeStateMachine) unlockSaveFallbackKey() (stateFunc, error) {
 		// using the fallback object is the last chance before we give up trying
 		// to unlock save
 		AllowRecoveryKey: true,
+		AllowPassphrase:  hasPassphrase("ubuntu-save"),
 	}
 	saveFallbackKey := filepath.Join(boot.InitramfsSeedEncryptionKeyDir, "ubuntu-save.recovery.sealed-key")
 	// TODO: this prompts again for a recover key, but really this is the
@@ -1294,6 +1351,7 @@ func generateMountsModeRun(mst *initramfsMountsState) error {
 	runModeKey := filepath.Join(boot.InitramfsBootEncryptionKeyDir, "ubuntu-data.sealed-key")
 	opts := &secboot.UnlockVolumeUsingSealedKeyOptions{
 		AllowRecoveryKey: true,
+		AllowPassphrase:  hasPassphrase("ubuntu-data"),
 	}
 	unlockRes, err := secbootUnlockVolumeUsingSealedKeyIfEncrypted(disk, "ubuntu-data", runModeKey, opts)
 	if err != nil {
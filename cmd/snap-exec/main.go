@@ -23,13 +23,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"syscall"
 
 	"github.com/jessevdk/go-flags"
 
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/sandbox/landlock"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snapenv"
 )
@@ -37,6 +40,7 @@ import (
 // for the tests
 var syscallExec = syscall.Exec
 var osReadlink = os.Readlink
+var landlockRestrict = (*landlock.Ruleset).Restrict
 
 // commandline args
 var opts struct {
@@ -229,6 +233,9 @@ func execApp(snapApp, revision, command string, args []string) error {
 
 	fullCmd = append(absoluteCommandChain(app.Snap, app.CommandChain), fullCmd...)
 
+	if err := restrictWithLandlock(app.SecurityTag()); err != nil {
+		return err
+	}
 	if err := syscallExec(fullCmd[0], fullCmd, env.ForExec()); err != nil {
 		return fmt.Errorf("cannot exec %q: %s", fullCmd[0], err)
 	}
@@ -268,5 +275,34 @@ func execHook(snapName, revision, hookName string) error {
 
 	// run the hook
 	cmd := append(absoluteCommandChain(hook.Snap, hook.CommandChain), filepath.Join(hook.Snap.HooksDir(), hook.Name))
+	if err := restrictWithLandlock(hook.SecurityTag()); err != nil {
+		return err
+	}
 	return syscallExec(cmd[0], cmd, env.ForExec())
 }
+
+// restrictWithLandlock applies the Landlock ruleset of the given
+// security tag, if the landlock security backend wrote one. The ruleset
+// is not enforced if landlock is not available on the running kernel,
+// otherwise the application is not executed unconfined if the ruleset
+// cannot be applied.
+func restrictWithLandlock(securityTag string) error {
+	rs, err := landlock.LoadRuleset(filepath.Join(dirs.SnapLandlockDir, securityTag+".json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if landlock.ABI() == 0 {
+		logger.Noticef("WARNING: cannot restrict %q: landlock is not available", securityTag)
+		return nil
+	}
+	// the ruleset applies to the thread that executes the
+	// application, it must not change from now on
+	runtime.LockOSThread()
+	if err := landlockRestrict(rs); err != nil {
+		return fmt.Errorf("cannot restrict %q: %v", securityTag, err)
+	}
+	return nil
+}
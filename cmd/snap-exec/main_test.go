@@ -30,8 +30,10 @@ import (
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/release"
+	"github.com/snapcore/snapd/sandbox/landlock"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/testutil"
@@ -251,6 +253,72 @@ func (s *snapExecSuite) TestSnapExecHookIntegration(c *C) {
 	c.Check(execArgs, DeepEquals, []string{execArgv0})
 }
 
+func (s *snapExecSuite) TestSnapExecAppLandlockIntegration(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	snaptest.MockSnap(c, string(mockYaml), &snap.SideInfo{
+		Revision: snap.R("42"),
+	})
+	c.Assert(os.MkdirAll(dirs.SnapLandlockDir, 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dirs.SnapLandlockDir, "snap.snapname.app.json"),
+		[]byte(`{"rules":[{"path":"/usr","access":["read","execute"]}]}`), 0644), IsNil)
+	restore := landlock.MockABI(1)
+	defer restore()
+
+	var events []string
+	restore = snapExec.MockLandlockRestrict(func(rs *landlock.Ruleset) error {
+		c.Check(rs.Rules, DeepEquals, []landlock.Rule{{Path: "/usr", Access: []landlock.Access{"read", "execute"}}})
+		events = append(events, "restrict")
+		return nil
+	})
+	defer restore()
+	restore = snapExec.MockSyscallExec(func(argv0 string, argv []string, env []string) error {
+		events = append(events, "exec")
+		return nil
+	})
+	defer restore()
+
+	c.Assert(snapExec.ExecApp("snapname.app", "42", "", nil), IsNil)
+	c.Check(events, DeepEquals, []string{"restrict", "exec"})
+
+	// no ruleset for the other apps
+	events = nil
+	c.Assert(snapExec.ExecApp("snapname.app2", "42", "", nil), IsNil)
+	c.Check(events, DeepEquals, []string{"exec"})
+
+	// the ruleset is not enforced if landlock is not available
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+	restore = landlock.MockABI(0)
+	defer restore()
+	events = nil
+	c.Assert(snapExec.ExecApp("snapname.app", "42", "", nil), IsNil)
+	c.Check(events, DeepEquals, []string{"exec"})
+	c.Check(logbuf.String(), testutil.Contains, `WARNING: cannot restrict "snap.snapname.app": landlock is not available`)
+}
+
+func (s *snapExecSuite) TestSnapExecHookLandlockError(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	snaptest.MockSnap(c, string(mockHookYaml), &snap.SideInfo{
+		Revision: snap.R("42"),
+	})
+	c.Assert(os.MkdirAll(dirs.SnapLandlockDir, 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dirs.SnapLandlockDir, "snap.snapname.hook.configure.json"), []byte(`{"rules":[]}`), 0644), IsNil)
+	restore := landlock.MockABI(1)
+	defer restore()
+	restore = snapExec.MockLandlockRestrict(func(rs *landlock.Ruleset) error {
+		return fmt.Errorf("boom")
+	})
+	defer restore()
+	restore = snapExec.MockSyscallExec(func(argv0 string, argv []string, env []string) error {
+		c.Fatalf("unexpected exec")
+		return nil
+	})
+	defer restore()
+
+	err := snapExec.ExecHook("snapname", "42", "configure")
+	c.Check(err, ErrorMatches, `cannot restrict "snap.snapname.hook.configure": boom`)
+}
+
 func (s *snapExecSuite) TestSnapExecHookCommandChainIntegration(c *C) {
 	dirs.SetRootDir(c.MkDir())
 	snaptest.MockSnap(c, string(mockHookCommandChainYaml), &snap.SideInfo{
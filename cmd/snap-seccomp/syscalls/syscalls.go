@@ -192,6 +192,9 @@ var SeccompSyscalls = []string{
 	"kexec_load",
 	"keyctl",
 	"kill",
+	"landlock_add_rule",
+	"landlock_create_ruleset",
+	"landlock_restrict_self",
 	"lchown",
 	"lchown32",
 	"lgetxattr",
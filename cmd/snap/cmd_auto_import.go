@@ -120,8 +120,23 @@ func autoImportCandidates() ([]string, error) {
 			cands = append(cands, cand)
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
 
-	return cands, scanner.Err()
+	// in recover mode there are no users and ubuntu-data may not be
+	// usable, the brand can ship system-user assertions on ubuntu-seed
+	// (or ubuntu-save) so that users can be created to repair the device
+	if inRecoverMode() {
+		for _, dir := range []string{boot.InitramfsUbuntuSeedDir, boot.InitramfsUbuntuSaveDir} {
+			cand := filepath.Join(dir, autoImportsName)
+			if osutil.FileExists(cand) {
+				cands = append(cands, cand)
+			}
+		}
+	}
+
+	return cands, nil
 }
 
 func queueFile(src string) error {
@@ -261,6 +276,9 @@ consider all available mounted devices for inspection.
 
 Assertions to be imported must be made available in the auto-import.assert file
 in the root of the filesystem.
+
+In recover mode, the auto-import.assert file in the root of the ubuntu-seed and
+ubuntu-save partitions is considered as well.
 `)
 
 func init() {
@@ -330,13 +348,23 @@ func removableBlockDevices() (removableDevices []string) {
 	return removableDevices
 }
 
-// inInstallmode returns true if it's UC20 system in install mode
+// inInstallmode returns true if it's UC20 system in install mode, or in
+// factory-reset mode which installs the run system as well
 func inInstallMode() bool {
 	mode, _, err := boot.ModeAndRecoverySystemFromKernelCommandLine()
 	if err != nil {
 		return false
 	}
-	return mode == "install"
+	return mode == boot.ModeInstall || mode == boot.ModeFactoryReset
+}
+
+// inRecoverMode returns true if it's UC20 system in recover mode
+func inRecoverMode() bool {
+	mode, _, err := boot.ModeAndRecoverySystemFromKernelCommandLine()
+	if err != nil {
+		return false
+	}
+	return mode == boot.ModeRecover
 }
 
 func (x *cmdAutoImport) Execute(args []string) error {
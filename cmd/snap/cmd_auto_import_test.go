@@ -508,6 +508,51 @@ func (s *SnapSuite) TestAutoImportUC20CandidatesIgnoresSystemPartitions(c *C) {
 	c.Check(l, DeepEquals, []string{filepath.Join(rootDir, "/mnt/real-device", "auto-import.assert")})
 }
 
+func (s *SnapSuite) TestAutoImportUC20CandidatesRecoverModeSeedAndSave(c *C) {
+	rootDir := c.MkDir()
+	dirs.SetRootDir(rootDir)
+	defer func() { dirs.SetRootDir("") }()
+
+	for _, dir := range []string{"/run/mnt/ubuntu-seed", "/run/mnt/ubuntu-save", "/run/mnt/ubuntu-data", "/mnt/real-device"} {
+		file := filepath.Join(rootDir, dir, "auto-import.assert")
+		c.Assert(os.MkdirAll(filepath.Dir(file), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(file, nil, 0644), IsNil)
+	}
+
+	content := fmt.Sprintf(`
+24 0 8:18 / %[1]s/run/mnt/ubuntu-seed rw,relatime - vfat /dev/meep2 rw
+24 0 8:18 / %[1]s/run/mnt/ubuntu-save rw,relatime - ext4 /dev/meep3 rw
+24 0 8:18 / %[1]s/run/mnt/ubuntu-data rw,relatime - ext4 /dev/meep4 rw
+24 0 8:18 / %[1]s/mnt/real-device rw,relatime - ext4 /dev/meep78 rw
+`, rootDir)
+	restore := snap.MockMountInfoPath(makeMockMountInfo(c, content))
+	defer restore()
+
+	mockProcCmdlinePath := filepath.Join(c.MkDir(), "cmdline")
+	err := ioutil.WriteFile(mockProcCmdlinePath, []byte("snapd_recovery_mode=recover snapd_recovery_system=20191118"), 0644)
+	c.Assert(err, IsNil)
+	restore = osutil.MockProcCmdline(mockProcCmdlinePath)
+	defer restore()
+
+	l, err := snap.AutoImportCandidates()
+	c.Check(err, IsNil)
+	c.Check(l, DeepEquals, []string{
+		filepath.Join(rootDir, "/mnt/real-device", "auto-import.assert"),
+		filepath.Join(rootDir, "/run/mnt/ubuntu-seed", "auto-import.assert"),
+		filepath.Join(rootDir, "/run/mnt/ubuntu-save", "auto-import.assert"),
+	})
+
+	// but not in run mode
+	err = ioutil.WriteFile(mockProcCmdlinePath, []byte("snapd_recovery_mode=run"), 0644)
+	c.Assert(err, IsNil)
+
+	l, err = snap.AutoImportCandidates()
+	c.Check(err, IsNil)
+	c.Check(l, DeepEquals, []string{
+		filepath.Join(rootDir, "/mnt/real-device", "auto-import.assert"),
+	})
+}
+
 func (s *SnapSuite) TestAutoImportAssertsManagedEmptyReply(c *C) {
 	restore := release.MockOnClassic(false)
 	defer restore()
@@ -20,6 +20,9 @@
 package main
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/snapcore/snapd/i18n"
 
 	"github.com/jessevdk/go-flags"
@@ -27,6 +30,8 @@ import (
 
 type cmdConnect struct {
 	waitMixin
+	DryRun      bool          `long:"dry-run"`
+	For         time.Duration `long:"for"`
 	Positionals struct {
 		PlugSpec connectPlugSpec `required:"yes"`
 		SlotSpec connectSlotSpec
@@ -52,12 +57,25 @@ $ snap connect <snap>:<plug>
 
 Connects the provided plug to the slot in the core snap with a name matching
 the plug name.
+
+With --for the connection is time-limited: snapd disconnects it
+automatically once the given duration, such as 2h or 30m, has passed.
+Connecting a time-limited connection again with a new duration changes its
+time limit, connecting it again without --for makes it permanent.
+
+With --dry-run the connection is not made, instead the changes it would
+make to the security profiles of the snaps are shown as unified diffs.
 `)
 
 func init() {
 	addCommand("connect", shortConnectHelp, longConnectHelp, func() flags.Commander {
 		return &cmdConnect{}
-	}, waitDescs, []argDesc{
+	}, waitDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"dry-run": i18n.G("Show how the security profiles would change without connecting"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"for": i18n.G("Disconnect automatically after the given duration"),
+	}), []argDesc{
 		// TRANSLATORS: This needs to begin with < and end with >
 		{name: i18n.G("<snap>:<plug>")},
 		// TRANSLATORS: This needs to begin with < and end with >
@@ -77,7 +95,21 @@ func (x *cmdConnect) Execute(args []string) error {
 		x.Positionals.PlugSpec.Snap = ""
 	}
 
-	id, err := x.client.Connect(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name)
+	if x.For < 0 {
+		return fmt.Errorf(i18n.G("cannot connect for a negative duration"))
+	}
+
+	if x.DryRun {
+		return x.showDryRun()
+	}
+
+	var id string
+	var err error
+	if x.For > 0 {
+		id, err = x.client.ConnectFor(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name, x.For)
+	} else {
+		id, err = x.client.Connect(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name)
+	}
 	if err != nil {
 		return err
 	}
@@ -91,3 +123,25 @@ func (x *cmdConnect) Execute(args []string) error {
 
 	return nil
 }
+
+func (x *cmdConnect) showDryRun() error {
+	changes, err := x.client.ConnectDryRun(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(Stdout, i18n.G("No security profile would change."))
+		return nil
+	}
+	for _, change := range changes {
+		oldName, newName := change.Path, change.Path
+		if change.Old == "" {
+			oldName = "/dev/null"
+		}
+		if change.New == "" {
+			newName = "/dev/null"
+		}
+		fmt.Fprint(Stdout, unifiedDiff(oldName, newName, change.Old, change.New))
+	}
+	return nil
+}
@@ -53,9 +53,20 @@ $ snap connect <snap>:<plug>
 Connects the provided plug to the slot in the core snap with a name matching
 the plug name.
 
+With --for the connection is time-limited: snapd disconnects it
+automatically once the given duration, such as 2h or 30m, has passed.
+Connecting a time-limited connection again with a new duration changes its
+time limit, connecting it again without --for makes it permanent.
+
+With --dry-run the connection is not made, instead the changes it would
+make to the security profiles of the snaps are shown as unified diffs.
+
 [connect command options]
       --no-wait          Do not wait for the operation to finish but just print
                          the change id.
+      --dry-run          Show how the security profiles would change without
+                         connecting
+      --for=             Disconnect automatically after the given duration
 `
 	s.testSubCommandHelp(c, "connect", msg)
 }
@@ -94,6 +105,102 @@ func (s *SnapSuite) TestConnectExplicitEverything(c *C) {
 	c.Assert(rest, DeepEquals, []string{})
 }
 
+func (s *SnapSuite) TestConnectFor(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]interface{}{
+				"action": "connect",
+				"for":    "2h0m0s",
+				"plugs": []interface{}{
+					map[string]interface{}{
+						"snap": "producer",
+						"plug": "plug",
+					},
+				},
+				"slots": []interface{}{
+					map[string]interface{}{
+						"snap": "consumer",
+						"slot": "slot",
+					},
+				},
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"connect", "--for=2h", "producer:plug", "consumer:slot"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+}
+
+func (s *SnapSuite) TestConnectForNegative(c *C) {
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "--for=-1h", "producer:plug", "consumer:slot"})
+	c.Assert(err, ErrorMatches, "cannot connect for a negative duration")
+}
+
+func (s *SnapSuite) TestConnectDryRun(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]interface{}{
+				"action":  "connect",
+				"dry-run": true,
+				"plugs": []interface{}{
+					map[string]interface{}{
+						"snap": "producer",
+						"plug": "plug",
+					},
+				},
+				"slots": []interface{}{
+					map[string]interface{}{
+						"snap": "consumer",
+						"slot": "slot",
+					},
+				},
+			})
+			fmt.Fprintln(w, `{"type":"sync", "result":[
+				{"snap": "consumer", "backend": "kmod", "path": "/etc/modules-load.d/snap.consumer.conf", "new": "mod\n"},
+				{"snap": "producer", "backend": "apparmor", "path": "/profile", "old": "a\nb\n", "new": "a\nc\nb\n"}
+			]}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"connect", "--dry-run", "producer:plug", "consumer:slot"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, `--- /dev/null
++++ /etc/modules-load.d/snap.consumer.conf
+@@ -0,0 +1 @@
++mod
+--- /profile
++++ /profile
+@@ -1,2 +1,3 @@
+ a
++c
+ b
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectDryRunNoChanges(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/interfaces")
+		fmt.Fprintln(w, `{"type":"sync", "result":[]}`)
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "--dry-run", "producer:plug", "consumer:slot"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "No security profile would change.\n")
+}
+
 func (s *SnapSuite) TestConnectExplicitPlugImplicitSlot(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
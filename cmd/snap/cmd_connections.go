@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 
@@ -51,6 +52,9 @@ $ snap connections <snap>
 
 Lists connected and unconnected plugs and slots for the specified
 snap.
+
+The notes of time-limited connections, see 'snap connect --for', show
+when they expire.
 `)
 
 func init() {
@@ -84,6 +88,7 @@ type connection struct {
 	interfaceDeterminant string
 	manual               bool
 	gadget               bool
+	expiry               time.Time
 }
 
 func (cn connection) String() string {
@@ -94,6 +99,9 @@ func (cn connection) String() string {
 	if cn.gadget {
 		opts = append(opts, "gadget")
 	}
+	if !cn.expiry.IsZero() {
+		opts = append(opts, "expires="+cn.expiry.Format(time.RFC3339))
+	}
 	if len(opts) == 0 {
 		return "-"
 	}
@@ -169,6 +177,7 @@ func (x *cmdConnections) Execute(args []string) error {
 			slot:                 endpoint(conn.Slot.Snap, conn.Slot.Name),
 			manual:               conn.Manual,
 			gadget:               conn.Gadget,
+			expiry:               conn.Expiry,
 			interfaceName:        conn.Interface,
 			interfaceDeterminant: interfaceDeterminant(&conn),
 		})
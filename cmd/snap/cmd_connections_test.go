@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -277,6 +278,47 @@ func (s *SnapSuite) TestConnectionsSomeConnected(c *C) {
 	c.Assert(s.Stderr(), Equals, "")
 }
 
+func (s *SnapSuite) TestConnectionsExpiry(c *C) {
+	result := client.Connections{
+		Established: []client.Connection{
+			{
+				Plug:      client.PlugRef{Snap: "diagnostics", Name: "log-observe"},
+				Slot:      client.SlotRef{Snap: "core", Name: "log-observe"},
+				Interface: "log-observe",
+				Manual:    true,
+				Expiry:    time.Date(2026, 10, 18, 16, 0, 0, 0, time.UTC),
+			},
+		},
+		Plugs: []client.Plug{
+			{
+				Snap:      "diagnostics",
+				Name:      "log-observe",
+				Interface: "log-observe",
+				Connections: []client.SlotRef{{
+					Snap: "core",
+					Name: "log-observe",
+				}},
+			},
+		},
+	}
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections")
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type":   "sync",
+			"result": result,
+		})
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"connections"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	expectedStdout := "" +
+		"Interface    Plug                     Slot          Notes\n" +
+		"log-observe  diagnostics:log-observe  :log-observe  manual,expires=2026-10-18T16:00:00Z\n"
+	c.Assert(s.Stdout(), Equals, expectedStdout)
+	c.Assert(s.Stderr(), Equals, "")
+}
+
 func (s *SnapSuite) TestConnectionsSomeDisconnected(c *C) {
 	result := client.Connections{
 		Established: []client.Connection{
@@ -0,0 +1,66 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/release"
+)
+
+type cmdBootChains struct {
+	clientMixin
+	Kernel string `long:"kernel" value-name:"<snap-file>"`
+	Gadget string `long:"gadget" value-name:"<gadget-dir>"`
+}
+
+func init() {
+	cmd := addDebugCommand("boot-chains",
+		"(internal) inspect the boot chains the encryption keys are sealed to",
+		"(internal) inspect the boot chains the encryption keys are sealed to, "+
+			"the boot chains a reseal would use and the resulting PCR protection profile",
+		func() flags.Commander {
+			return &cmdBootChains{}
+		}, map[string]string{
+			"kernel": i18n.G("Include the given kernel snap file as if it was being refreshed to"),
+			"gadget": i18n.G("Include the boot assets of the gadget in the given directory as if it was being refreshed to"),
+		}, nil)
+	if release.OnClassic {
+		cmd.hidden = true
+	}
+}
+
+func (x *cmdBootChains) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	if release.OnClassic {
+		return errors.New(`the "boot-chains" command is not available on classic systems`)
+	}
+	model, err := x.client.CurrentModelAssertion()
+	if err != nil {
+		return err
+	}
+	return boot.DumpBootChains(Stdout, model, x.Kernel, x.Gadget)
+}
@@ -0,0 +1,78 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+type cmdBootHistory struct {
+	clientMixin
+	timeMixin
+}
+
+func init() {
+	addDebugCommand("boot-history",
+		"(internal) list the recorded boots of the system",
+		"(internal) list the recorded boots of the system, the kernel and base "+
+			"or the recovery system that were booted, whether a new kernel or base "+
+			"was tried and whether it was reverted",
+		func() flags.Commander {
+			return &cmdBootHistory{}
+		}, timeDescs, nil)
+}
+
+func (x *cmdBootHistory) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	entries, err := x.client.BootHistory()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf(i18n.G("no boot history found"))
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintf(w, i18n.G("Time\tBoot ID\tMode\tKernel\tBase\tRecovery system\tTry\tOutcome\n"))
+	dash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	for _, e := range entries {
+		try := "-"
+		if e.Try {
+			try = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", x.fmtTime(e.Time), e.BootID, e.Mode, dash(e.Kernel), dash(e.Base), dash(e.RecoverySystem), try, e.Outcome)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+const mockBootHistoryJSON = `{"type": "sync", "status-code": 200, "result": [
+  {"boot-id": "11111111-1111", "time": "2026-10-01T12:00:00Z", "mode": "run", "kernel": "pc-kernel_1.snap", "base": "core20_1.snap", "outcome": "success"},
+  {"boot-id": "22222222-2222", "time": "2026-10-02T12:00:00Z", "mode": "run", "kernel": "pc-kernel_2.snap", "base": "core20_1.snap", "try": true, "outcome": "reverted"},
+  {"boot-id": "33333333-3333", "time": "2026-10-02T12:05:00Z", "mode": "recover", "recovery-system": "20201212", "outcome": "booted"}
+]}`
+
+func (s *SnapSuite) TestDebugBootHistory(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(n, check.Equals, 0)
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/systems/boot-history")
+		fmt.Fprintln(w, mockBootHistoryJSON)
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "boot-history", "--abs-time"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `
+Time                  Boot ID        Mode     Kernel            Base           Recovery system  Try  Outcome
+2026-10-01T12:00:00Z  11111111-1111  run      pc-kernel_1.snap  core20_1.snap  -                -    success
+2026-10-02T12:00:00Z  22222222-2222  run      pc-kernel_2.snap  core20_1.snap  -                yes  reverted
+2026-10-02T12:05:00Z  33333333-3333  recover  -                 -              20201212         -    booted
+`[1:])
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestDebugBootHistoryNone(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": []}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "boot-history"})
+	c.Assert(err, check.ErrorMatches, "no boot history found")
+}
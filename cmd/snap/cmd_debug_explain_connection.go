@@ -0,0 +1,130 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+)
+
+var shortDebugExplainConnectionHelp = i18n.G("Explain the policy decision about a connection")
+var longDebugExplainConnectionHelp = i18n.G(`
+The explain-connection command shows how the base declaration and the
+snap declarations are evaluated to allow or deny connecting the given
+plug and slot, both manually and automatically. For each decision it
+shows the rule that was used, the assertion it comes from, and which
+alternatives of its constraints matched or failed and why.
+
+The plug and slot are resolved as for snap connect.
+`)
+
+type cmdDebugExplainConnection struct {
+	clientMixin
+	Positionals struct {
+		PlugSpec connectPlugSpec `required:"yes"`
+		SlotSpec connectSlotSpec
+	} `positional-args:"true"`
+}
+
+func init() {
+	addDebugCommand("explain-connection", shortDebugExplainConnectionHelp, longDebugExplainConnectionHelp,
+		func() flags.Commander { return &cmdDebugExplainConnection{} }, nil, []argDesc{
+			// TRANSLATORS: This needs to begin with < and end with >
+			{name: i18n.G("<snap>:<plug>")},
+			// TRANSLATORS: This needs to begin with < and end with >
+			{name: i18n.G("<snap>:<slot>")},
+		})
+}
+
+func (x *cmdDebugExplainConnection) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	plug := x.Positionals.PlugSpec.SnapAndName
+	// snap debug explain-connection <plug> <snap>[:<slot>]
+	if plug.Snap != "" && plug.Name == "" {
+		plug.Name = plug.Snap
+		plug.Snap = ""
+	}
+	slot := x.Positionals.SlotSpec.SnapAndName
+	ex, err := x.client.ExplainConnection(plug.Snap, plug.Name, slot.Snap, slot.Name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(Stdout, "plug: %s:%s\n", ex.Plug.Snap, ex.Plug.Name)
+	fmt.Fprintf(Stdout, "slot: %s:%s\n", ex.Slot.Snap, ex.Slot.Name)
+	fmt.Fprintf(Stdout, "interface: %s\n", ex.Interface)
+	printPolicyExplanation(ex.Connection)
+	printPolicyExplanation(ex.AutoConnection)
+	fmt.Fprintf(Stdout, "interface-auto-connect: %t\n", ex.InterfaceAutoConnect)
+	if len(ex.Notes) > 0 {
+		fmt.Fprintln(Stdout, "notes:")
+		for _, note := range ex.Notes {
+			fmt.Fprintf(Stdout, "  - %s\n", note)
+		}
+	}
+	return nil
+}
+
+func printPolicyExplanation(ex *client.PolicyExplanation) {
+	if ex == nil {
+		return
+	}
+	fmt.Fprintf(Stdout, "%s:\n", ex.Kind)
+	if ex.Allowed {
+		fmt.Fprintln(Stdout, "  allowed: true")
+	} else {
+		fmt.Fprintln(Stdout, "  allowed: false")
+		if ex.Error != "" {
+			fmt.Fprintf(Stdout, "  reason: %s\n", ex.Error)
+		}
+	}
+	if ex.Rule == nil {
+		fmt.Fprintln(Stdout, "  rule: none")
+	} else {
+		rule := ex.Rule
+		if rule.Assertion == "snap-declaration" {
+			fmt.Fprintf(Stdout, "  rule: %s of %s (%s, revision %d), %s side of %s\n",
+				rule.Assertion, rule.SnapName, rule.SnapID, rule.Revision, rule.Side, rule.Interface)
+		} else {
+			fmt.Fprintf(Stdout, "  rule: %s, %s side of %s\n", rule.Assertion, rule.Side, rule.Interface)
+		}
+	}
+	if len(ex.Checks) > 0 {
+		fmt.Fprintln(Stdout, "  checks:")
+		for _, check := range ex.Checks {
+			outcome := "matched"
+			if !check.Matched {
+				outcome = "failed"
+			}
+			fmt.Fprintf(Stdout, "    - %s[%d]: %s", check.Constraint, check.Alternative, outcome)
+			if check.Error != "" {
+				fmt.Fprintf(Stdout, ": %s", check.Error)
+			}
+			fmt.Fprintln(Stdout)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+func (s *SnapSuite) TestDebugExplainConnection(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/debug")
+			c.Check(r.URL.Query(), check.DeepEquals, url.Values{
+				"aspect": {"policy"},
+				"plug":   {"consumer:plug"},
+				"slot":   {"producer:"},
+			})
+			fmt.Fprintln(w, `{"type": "sync", "result": {
+				"plug": {"snap": "consumer", "plug": "plug"},
+				"slot": {"snap": "producer", "slot": "slot"},
+				"interface": "test",
+				"connection": {
+					"kind": "connection",
+					"allowed": true,
+					"rule": {"assertion": "snap-declaration", "snap-id": "producer-id", "snap-name": "producer", "revision": 3, "side": "slot", "interface": "test"},
+					"checks": [
+						{"constraint": "allow-connection", "alternative": 0, "matched": false, "error": "plug attribute \"foo\" does not match"},
+						{"constraint": "allow-connection", "alternative": 1, "matched": true}
+					]
+				},
+				"auto-connection": {
+					"kind": "auto-connection",
+					"allowed": false,
+					"rule": {"assertion": "base-declaration", "side": "slot", "interface": "test"},
+					"error": "auto-connection denied by slot rule of interface \"test\""
+				},
+				"interface-auto-connect": true,
+				"notes": ["snap \"consumer\" has no snap declaration"]
+			}}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "explain-connection", "consumer:plug", "producer"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `plug: consumer:plug
+slot: producer:slot
+interface: test
+connection:
+  allowed: true
+  rule: snap-declaration of producer (producer-id, revision 3), slot side of test
+  checks:
+    - allow-connection[0]: failed: plug attribute "foo" does not match
+    - allow-connection[1]: matched
+auto-connection:
+  allowed: false
+  reason: auto-connection denied by slot rule of interface "test"
+  rule: base-declaration, slot side of test
+interface-auto-connect: true
+notes:
+  - snap "consumer" has no snap declaration
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestDebugExplainConnectionImplicitSnap(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Query(), check.DeepEquals, url.Values{
+			"aspect": {"policy"},
+			"plug":   {":network"},
+		})
+		fmt.Fprintln(w, `{"type": "error", "status-code": 400, "result": {"message": "no such plug"}}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "explain-connection", "network"})
+	c.Assert(err, check.ErrorMatches, "no such plug")
+}
@@ -0,0 +1,85 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+)
+
+var shortDebugProfileHelp = i18n.G("Show the security profiles of a snap")
+var longDebugProfileHelp = i18n.G(`
+The profile command displays the security profiles of the given
+application, as they are rendered from the current interface connections.
+When only a snap name is given the profiles of all its applications and
+hooks are displayed. The profiles on disk are not read nor modified.
+`)
+
+type cmdDebugProfile struct {
+	clientMixin
+	Backend    string `long:"backend"`
+	Positional struct {
+		SnapApp string `positional-arg-name:"<snap.app>" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+func init() {
+	addDebugCommand("profile", shortDebugProfileHelp, longDebugProfileHelp,
+		func() flags.Commander { return &cmdDebugProfile{} }, map[string]string{
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"backend": i18n.G("Only show the profiles of the given security backend"),
+		}, nil)
+}
+
+func (x *cmdDebugProfile) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	params := map[string]string{"snap": x.Positional.SnapApp}
+	if x.Backend != "" {
+		params["backend"] = x.Backend
+	}
+	var profiles []*client.SecurityProfile
+	if err := x.client.DebugGet("profiles", &profiles, params); err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		fmt.Fprintf(Stderr, i18n.G("No security profiles found for %q.\n"), x.Positional.SnapApp)
+		return nil
+	}
+
+	for i, p := range profiles {
+		if i > 0 {
+			fmt.Fprintln(Stdout)
+		}
+		fmt.Fprintf(Stdout, "==> %s (%s) <==\n", p.Path, p.Backend)
+		fmt.Fprint(Stdout, p.Content)
+		if !strings.HasSuffix(p.Content, "\n") {
+			fmt.Fprintln(Stdout)
+		}
+	}
+	return nil
+}
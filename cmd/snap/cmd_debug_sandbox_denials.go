@@ -0,0 +1,85 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+var shortSandboxDenialsHelp = i18n.G("List the sandbox denials of a snap")
+var longSandboxDenialsHelp = i18n.G(`
+The sandbox-denials command displays the AppArmor and seccomp denials
+logged since boot for the applications and hooks of the given snap, along
+with the interfaces whose plugs would allow the denied accesses.
+
+Suggested interfaces are only hints: connecting them may grant more than
+what is needed, and a denial may also be expected.
+`)
+
+type cmdSandboxDenials struct {
+	clientMixin
+	timeMixin
+	Positional struct {
+		Snap installedSnapName `positional-arg-name:"<snap>" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+func init() {
+	addDebugCommand("sandbox-denials", shortSandboxDenialsHelp, longSandboxDenialsHelp,
+		func() flags.Commander { return &cmdSandboxDenials{} }, timeDescs, nil)
+}
+
+func (x *cmdSandboxDenials) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	snapName := string(x.Positional.Snap)
+	denials, err := x.client.SandboxDenials(snapName)
+	if err != nil {
+		return err
+	}
+	if len(denials) == 0 {
+		fmt.Fprintf(Stderr, i18n.G("No sandbox denials found for snap %q.\n"), snapName)
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintln(w, i18n.G("Last seen\tCount\tApp\tClass\tOperation\tTarget\tPermissions\tSuggested plugs"))
+	for _, d := range denials {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n", x.fmtTime(d.LastSeen), d.Count, dashIfEmpty(d.App), d.Class,
+			dashIfEmpty(d.Operation), d.Target, dashIfEmpty(d.Permissions), dashIfEmpty(strings.Join(d.SuggestedPlugs, ",")))
+	}
+	return nil
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+func (s *SnapSuite) TestSandboxDenials(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(n, check.Equals, 0)
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps/foo/denials")
+		fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": [
+  {"snap": "foo", "app": "app", "class": "file", "operation": "open", "target": "/dev/video0", "permissions": "wr", "count": 2, "first-seen": "2026-10-01T12:00:00Z", "last-seen": "2026-10-01T12:05:00Z", "suggested-plugs": ["camera"]},
+  {"snap": "foo", "class": "syscall", "target": "mount", "count": 1, "first-seen": "2026-10-01T12:01:00Z", "last-seen": "2026-10-01T12:01:00Z"}
+]}`)
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "sandbox-denials", "--abs-time", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `
+Last seen             Count  App  Class    Operation  Target       Permissions  Suggested plugs
+2026-10-01T12:05:00Z  2      app  file     open       /dev/video0  wr           camera
+2026-10-01T12:01:00Z  1      -    syscall  -          mount        -            -
+`[1:])
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestSandboxDenialsNone(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": []}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "sandbox-denials", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Check(s.Stdout(), check.Equals, "")
+	c.Check(s.Stderr(), check.Equals, "No sandbox denials found for snap \"foo\".\n")
+}
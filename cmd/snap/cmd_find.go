@@ -47,6 +47,11 @@ feature.
 
 A green check mark (given color and unicode support) after a publisher name
 indicates that the publisher has been verified.
+
+With --limit, at most the given number of results is shown, and if more are
+available a cursor to pass to --cursor to see them is printed. The store only
+returns its first page of results for a search, and only that page can be
+browsed this way: use a more specific query to find snaps beyond it.
 `)
 
 func getPrice(prices map[string]float64, currency string) (float64, string, error) {
@@ -157,6 +162,16 @@ type cmdFind struct {
 	Positional struct {
 		Query string
 	} `positional-args:"yes"`
+
+	Publisher    string `long:"publisher"`
+	Verified     bool   `long:"verified"`
+	Confinement  string `long:"confinement" choice:"strict" choice:"classic" choice:"devmode"`
+	Architecture string `long:"arch"`
+	License      string `long:"license"`
+	Base         string `long:"base"`
+	Sort         string `long:"sort" choice:"name" choice:"popularity" choice:"last-updated"`
+	Limit        int    `long:"limit"`
+	Cursor       string `long:"cursor"`
 	colorMixin
 }
 
@@ -170,6 +185,24 @@ func init() {
 		"narrow": i18n.G("Only search for snaps in “stable”."),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"section": i18n.G("Restrict the search to a given section."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"publisher": i18n.G("Only show snaps from the given publisher."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"verified": i18n.G("Only show snaps from verified publishers, among the results returned by the store."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"confinement": i18n.G("Only show snaps with the given confinement."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"arch": i18n.G("Show snaps available for the given architecture instead of this system's."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"license": i18n.G("Only show snaps with the given license, among the results returned by the store."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"base": i18n.G("Only show snaps using the given base, among the results returned by the store."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"sort": i18n.G("Sort the results by name, popularity or last update."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"limit": i18n.G("Show at most this many results."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"cursor": i18n.G("Continue a previous search from the given point, within the first page of store results."),
 	}), []argDesc{{
 		// TRANSLATORS: This needs to begin with < and end with >
 		name: i18n.G("<query>"),
@@ -199,6 +232,10 @@ func (x *cmdFind) Execute(args []string) error {
 		x.Section = ""
 	}
 
+	if x.Limit < 0 {
+		return fmt.Errorf(i18n.G("cannot use a negative --limit"))
+	}
+
 	// magic! `snap find` returns the featured snaps
 	showFeatured := (x.Positional.Query == "" && x.Section == "")
 	if showFeatured {
@@ -224,9 +261,18 @@ func (x *cmdFind) Execute(args []string) error {
 	}
 
 	opts := &client.FindOptions{
-		Query:   x.Positional.Query,
-		Section: string(x.Section),
-		Private: x.Private,
+		Query:        x.Positional.Query,
+		Section:      string(x.Section),
+		Private:      x.Private,
+		Publisher:    x.Publisher,
+		Verified:     x.Verified,
+		Confinement:  x.Confinement,
+		Architecture: x.Architecture,
+		License:      x.License,
+		Base:         x.Base,
+		Sort:         x.Sort,
+		Limit:        x.Limit,
+		Cursor:       x.Cursor,
 	}
 
 	if !x.Narrow {
@@ -267,6 +313,10 @@ func (x *cmdFind) Execute(args []string) error {
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", snap.Name, snap.Version, shortPublisher(esc, snap.Publisher), NotesFromRemote(snap, resInfo), snap.Summary)
 	}
 	w.Flush()
+	if resInfo != nil && resInfo.NextCursor != "" {
+		// TRANSLATORS: the %s is the cursor to pass to --cursor
+		fmt.Fprintf(Stdout, i18n.G("\nMore results are available, use --cursor=%s to see them.\n"), resInfo.NextCursor)
+	}
 	if showFeatured {
 		fmt.Fprint(Stdout, i18n.G("\nProvide a search term for more specific results.\n"))
 	}
@@ -23,8 +23,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
 	"gopkg.in/check.v1"
@@ -267,6 +269,50 @@ hello-huge +1.0 +noise +- +a really big snap
 	c.Check(s.Stderr(), check.Equals, "")
 }
 
+func (s *SnapSuite) TestFindHelloFiltersAndPagination(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/find")
+			c.Check(r.URL.Query(), check.DeepEquals, url.Values{
+				"q":            []string{"hello"},
+				"scope":        []string{"wide"},
+				"publisher":    []string{"canonical"},
+				"verified":     []string{"true"},
+				"confinement":  []string{"strict"},
+				"architecture": []string{"arm64"},
+				"license":      []string{"GPL-3.0"},
+				"base":         []string{"core20"},
+				"sort":         []string{"name"},
+				"limit":        []string{"2"},
+				"cursor":       []string{"4"},
+			})
+			fmt.Fprintln(w, strings.Replace(findHelloJSON, `"suggested-currency": "GBP"`, `"suggested-currency": "GBP", "next-cursor": "6"`, 1))
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"find", "--publisher=canonical", "--verified", "--confinement=strict", "--arch=arm64", "--license=GPL-3.0", "--base=core20", "--sort=name", "--limit=2", "--cursor=4", "hello"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Matches, `Name +Version +Publisher +Notes +Summary
+hello +2.10 +canonical\* +- +GNU Hello, the "hello world" snap
+hello-huge +1.0 +noise +- +a really big snap
+
+More results are available, use --cursor=6 to see them.
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestFindBadSort(c *check.C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"find", "--sort=size", "hello"})
+	c.Assert(err, check.ErrorMatches, `Invalid value .size. for option .--sort.*`)
+}
+
 const findPricedJSON = `
 {
   "type": "sync",
@@ -196,7 +196,7 @@ var helpCategories = []helpCategory{
 	}, {
 		Label:       i18n.G("History"),
 		Description: i18n.G("manage system change transactions"),
-		Commands:    []string{"changes", "tasks", "abort", "watch"},
+		Commands:    []string{"changes", "tasks", "abort", "watch", "history"},
 	}, {
 		Label:       i18n.G("Daemons"),
 		Description: i18n.G("manage services"),
@@ -0,0 +1,90 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+var shortHistoryHelp = i18n.G("List the install, refresh and removal history of snaps")
+var longHistoryHelp = i18n.G(`
+The history command displays when snaps were installed, refreshed, reverted
+or removed, from which revision to which, what triggered it and how it
+ended, oldest first.
+
+If a snap name is given, only the history of that snap is displayed.
+`)
+
+type cmdHistory struct {
+	clientMixin
+	timeMixin
+	Positional struct {
+		Snap installedSnapName `positional-arg-name:"<snap>"`
+	} `positional-args:"yes"`
+}
+
+func init() {
+	addCommand("history", shortHistoryHelp, longHistoryHelp,
+		func() flags.Commander { return &cmdHistory{} }, timeDescs, nil)
+}
+
+func (c *cmdHistory) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	snapName := string(c.Positional.Snap)
+	entries, err := c.client.History(snapName)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		if snapName != "" {
+			return fmt.Errorf(i18n.G("no history found for snap %q"), snapName)
+		}
+		return fmt.Errorf(i18n.G("no history found"))
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintf(w, i18n.G("Time\tSnap\tAction\tFrom\tTo\tChannel\tTrigger\tOutcome\tChange\n"))
+	for _, e := range entries {
+		from := "-"
+		if !e.FromRevision.Unset() {
+			from = e.FromRevision.String()
+		}
+		to := "-"
+		if !e.ToRevision.Unset() {
+			to = e.ToRevision.String()
+		}
+		channel := e.Channel
+		if channel == "" {
+			channel = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.fmtTime(e.Time), e.Snap, e.Action, from, to, channel, e.Trigger, e.Outcome, e.ChangeID)
+	}
+
+	return nil
+}
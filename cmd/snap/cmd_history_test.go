@@ -0,0 +1,69 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+const mockHistoryJSON = `{"type": "sync", "status-code": 200, "result": [
+  {"snap": "foo", "action": "install", "from-revision": "unset", "to-revision": "1", "channel": "latest/stable", "trigger": "manual", "time": "2026-10-01T12:00:00Z", "outcome": "done", "change-id": "1"},
+  {"snap": "foo", "action": "refresh", "from-revision": "1", "to-revision": "2", "channel": "latest/stable", "trigger": "auto", "time": "2026-10-02T12:00:00Z", "outcome": "undone", "change-id": "2"},
+  {"snap": "foo", "action": "remove", "from-revision": "1", "to-revision": "unset", "trigger": "manual", "time": "2026-10-03T12:00:00Z", "outcome": "done", "change-id": "3"}
+]}`
+
+func (s *SnapSuite) TestHistory(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(n, check.Equals, 0)
+		c.Check(r.Method, check.Equals, "GET")
+		c.Check(r.URL.Path, check.Equals, "/v2/history")
+		c.Check(r.URL.Query().Get("snap"), check.Equals, "foo")
+		fmt.Fprintln(w, mockHistoryJSON)
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"history", "--abs-time", "foo"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `
+Time                  Snap  Action   From  To   Channel        Trigger  Outcome  Change
+2026-10-01T12:00:00Z  foo   install  -     1    latest/stable  manual   done     1
+2026-10-02T12:00:00Z  foo   refresh  1     2    latest/stable  auto     undone   2
+2026-10-03T12:00:00Z  foo   remove   1     -    -              manual   done     3
+`[1:])
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestHistoryNone(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Query().Get("snap"), check.Equals, "")
+		fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": []}`)
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"history"})
+	c.Assert(err, check.ErrorMatches, "no history found")
+}
@@ -23,11 +23,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"path"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 
 	"github.com/jessevdk/go-flags"
 )
@@ -36,6 +39,7 @@ type cmdInterface struct {
 	clientMixin
 	ShowAttrs   bool `long:"attrs"`
 	ShowAll     bool `long:"all"`
+	ShowHotplug bool `long:"hotplug"`
 	Positionals struct {
 		Interface interfaceName `skip-help:"true"`
 	} `positional-args:"true"`
@@ -57,6 +61,8 @@ func init() {
 		"attrs": i18n.G("Show interface attributes"),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"all": i18n.G("Include unused interfaces"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"hotplug": i18n.G("Show the devices backing hotplug slots"),
 	}, []argDesc{{
 		// TRANSLATORS: This needs to begin with < and end with >
 		name: i18n.G("<interface>"),
@@ -74,10 +80,12 @@ func (x *cmdInterface) Execute(args []string) error {
 		// Show one interface in detail.
 		name := string(x.Positionals.Interface)
 		ifaces, err := x.client.Interfaces(&client.InterfaceOptions{
-			Names: []string{name},
-			Doc:   true,
-			Plugs: true,
-			Slots: true,
+			Names:   []string{name},
+			Doc:     true,
+			Attrs:   x.ShowAttrs,
+			Plugs:   true,
+			Slots:   true,
+			Hotplug: x.ShowHotplug,
 		})
 		if err != nil {
 			return err
@@ -116,6 +124,14 @@ func (x *cmdInterface) showOneInterface(iface *client.Interface) {
 	if iface.DocURL != "" {
 		fmt.Fprintf(w, "documentation:\t%s\n", iface.DocURL)
 	}
+	if len(iface.PlugAttrs) > 0 {
+		fmt.Fprintf(w, "plug-attributes:\n")
+		x.showAttrSchema(w, iface.PlugAttrs, "")
+	}
+	if len(iface.SlotAttrs) > 0 {
+		fmt.Fprintf(w, "slot-attributes:\n")
+		x.showAttrSchema(w, iface.SlotAttrs, "")
+	}
 	if len(iface.Plugs) > 0 {
 		fmt.Fprintf(w, "plugs:\n")
 		for _, plug := range iface.Plugs {
@@ -152,9 +168,16 @@ func (x *cmdInterface) showOneInterface(iface *client.Interface) {
 			}
 			// Print a colon which will make the snap:slot element a key-value
 			// yaml object so that we can write the attributes.
-			if len(slot.Attrs) > 0 && x.ShowAttrs {
+			showAttrs := len(slot.Attrs) > 0 && x.ShowAttrs
+			showHotplug := slot.Hotplug != nil && x.ShowHotplug
+			if showAttrs || showHotplug {
 				fmt.Fprintf(w, ":\n")
-				x.showAttrs(w, slot.Attrs, "    ")
+				if showAttrs {
+					x.showAttrs(w, slot.Attrs, "    ")
+				}
+				if showHotplug {
+					x.showHotplug(w, slot.Hotplug, "    ")
+				}
 			} else {
 				fmt.Fprintf(w, "\n")
 			}
@@ -188,3 +211,61 @@ func (x *cmdInterface) showAttrs(w io.Writer, attrs map[string]interface{}, inde
 		}
 	}
 }
+
+func (x *cmdInterface) showAttrSchema(w io.Writer, schema map[string]client.AttrSpec, indent string) {
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		spec := schema[name]
+		desc := []string{spec.Type}
+		if spec.Required {
+			desc = append(desc, "required")
+		}
+		if len(spec.Enum) > 0 {
+			desc = append(desc, fmt.Sprintf("one of: %s", strings.Join(spec.Enum, "|")))
+		}
+		if spec.Path != "" {
+			desc = append(desc, fmt.Sprintf("%s path", spec.Path))
+		}
+		fmt.Fprintf(w, "%s  %s:\t%s\n", indent, name, strings.Join(desc, ", "))
+		if len(spec.Attrs) > 0 {
+			x.showAttrSchema(w, spec.Attrs, indent+"  ")
+		}
+	}
+}
+
+// hotplugDeviceAttrs lists the udev properties of hotplugged devices that
+// are shown, along with their display names.
+var hotplugDeviceAttrs = []struct {
+	property string
+	name     string
+}{
+	{"ID_VENDOR_ID", "vendor-id"},
+	{"ID_MODEL_ID", "model-id"},
+	{"ID_SERIAL_SHORT", "serial"},
+}
+
+func (x *cmdInterface) showHotplug(w io.Writer, info *client.HotplugInfo, indent string) {
+	fmt.Fprintf(w, "%s  hotplug:\n", indent)
+	fmt.Fprintf(w, "%s    key:\t%s\n", indent, info.Key)
+	devinfo, err := hotplug.NewHotplugDeviceInfo(info.Device)
+	if err != nil {
+		// the device was added by an older snapd that did not keep track
+		// of device information
+		return
+	}
+	fmt.Fprintf(w, "%s    device:\t%s\n", indent, devinfo)
+	fmt.Fprintf(w, "%s    subsystem:\t%s\n", indent, devinfo.Subsystem())
+	// DevicePath() is relative to the local sysfs mount point, the device
+	// may have been seen by snapd running elsewhere
+	devpath, _ := devinfo.Attribute("DEVPATH")
+	fmt.Fprintf(w, "%s    sysfs-path:\t%s\n", indent, path.Join("/sys", devpath))
+	for _, attr := range hotplugDeviceAttrs {
+		if value, ok := devinfo.Attribute(attr.property); ok {
+			fmt.Fprintf(w, "%s    %s:\t%s\n", indent, attr.name, value)
+		}
+	}
+}
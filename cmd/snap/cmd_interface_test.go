@@ -43,6 +43,7 @@ one connection is shown, or a list of all interfaces if --all is provided.
 [interface command options]
       --attrs          Show interface attributes
       --all            Include unused interfaces
+      --hotplug        Show the devices backing hotplug slots
 
 [interface command arguments]
   <interface>:         Show details of a specific interface
@@ -196,7 +197,7 @@ func (s *SnapSuite) TestInterfaceDetailsAndAttrs(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		c.Check(r.Method, Equals, "GET")
 		c.Check(r.URL.Path, Equals, "/v2/interfaces")
-		c.Check(r.URL.RawQuery, Equals, "doc=true&names=serial-port&plugs=true&select=all&slots=true")
+		c.Check(r.URL.RawQuery, Equals, "attrs=true&doc=true&names=serial-port&plugs=true&select=all&slots=true")
 		body, err := ioutil.ReadAll(r.Body)
 		c.Check(err, IsNil)
 		c.Check(body, DeepEquals, []byte{})
@@ -240,6 +241,110 @@ func (s *SnapSuite) TestInterfaceDetailsAndAttrs(c *C) {
 	c.Assert(s.Stderr(), Equals, "")
 }
 
+func (s *SnapSuite) TestInterfaceDetailsAttrSchema(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/interfaces")
+		c.Check(r.URL.RawQuery, Equals, "attrs=true&doc=true&names=content&plugs=true&select=all&slots=true")
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type": "sync",
+			"result": []*client.Interface{{
+				Name:    "content",
+				Summary: "allows sharing code and data with other snaps",
+				PlugAttrs: map[string]client.AttrSpec{
+					"content":          {Type: "string"},
+					"default-provider": {Type: "string"},
+					"target":           {Type: "string", Required: true, Path: "clean"},
+				},
+				SlotAttrs: map[string]client.AttrSpec{
+					"mode": {Type: "string", Enum: []string{"ro", "rw"}},
+					"source": {Type: "map", Attrs: map[string]client.AttrSpec{
+						"read": {Type: "list", Path: "clean"},
+					}},
+				},
+			}},
+		})
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"interface", "--attrs", "content"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	expectedStdout := "" +
+		"name:    content\n" +
+		"summary: allows sharing code and data with other snaps\n" +
+		"plug-attributes:\n" +
+		"  content:          string\n" +
+		"  default-provider: string\n" +
+		"  target:           string, required, clean path\n" +
+		"slot-attributes:\n" +
+		"  mode:   string, one of: ro|rw\n" +
+		"  source: map\n" +
+		"    read: list, clean path\n"
+	c.Check(s.Stdout(), Equals, expectedStdout)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestInterfaceDetailsHotplug(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/interfaces")
+		c.Check(r.URL.RawQuery, Equals, "doc=true&hotplug=true&names=serial-port&plugs=true&select=all&slots=true")
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type": "sync",
+			"result": []*client.Interface{{
+				Name:    "serial-port",
+				Summary: "allows accessing a specific serial port",
+				Slots: []client.Slot{{
+					Snap:  "gizmo-gadget",
+					Name:  "debug-serial-port",
+					Attrs: map[string]interface{}{"path": "/dev/ttyS0"},
+				}, {
+					Snap:  "core",
+					Name:  "serial-ft232r-usb-uart-a50285bi",
+					Attrs: map[string]interface{}{"path": "/dev/ttyUSB0"},
+					Hotplug: &client.HotplugInfo{
+						Key: "0123456789abcdef",
+						Device: map[string]string{
+							"DEVPATH":         "/devices/pci0000:00/usb1/1-2/1-2:1.0/ttyUSB0/tty/ttyUSB0",
+							"DEVNAME":         "/dev/ttyUSB0",
+							"SUBSYSTEM":       "tty",
+							"ID_VENDOR_ID":    "0403",
+							"ID_MODEL_ID":     "6001",
+							"ID_MODEL":        "FT232R_USB_UART",
+							"ID_SERIAL_SHORT": "A50285BI",
+						},
+					},
+				}, {
+					Snap:    "core",
+					Name:    "serial-1234-5678",
+					Hotplug: &client.HotplugInfo{Key: "fedcba9876543210"},
+				}},
+			}},
+		})
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"interface", "--hotplug", "serial-port"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	expectedStdout := "" +
+		"name:    serial-port\n" +
+		"summary: allows accessing a specific serial port\n" +
+		"slots:\n" +
+		"  - gizmo-gadget:debug-serial-port\n" +
+		"  - core:serial-ft232r-usb-uart-a50285bi:\n" +
+		"      hotplug:\n" +
+		"        key:        0123456789abcdef\n" +
+		"        device:     /dev/ttyUSB0 (FT232R_USB_UART; serial: A50285BI)\n" +
+		"        subsystem:  tty\n" +
+		"        sysfs-path: /sys/devices/pci0000:00/usb1/1-2/1-2:1.0/ttyUSB0/tty/ttyUSB0\n" +
+		"        vendor-id:  0403\n" +
+		"        model-id:   6001\n" +
+		"        serial:     A50285BI\n" +
+		"  - core:serial-1234-5678:\n" +
+		"      hotplug:\n" +
+		"        key: fedcba9876543210\n"
+	c.Assert(s.Stdout(), Equals, expectedStdout)
+	c.Assert(s.Stderr(), Equals, "")
+}
+
 func (s *SnapSuite) TestInterfaceCompletion(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		c.Assert(r.Method, Equals, "GET")
@@ -24,6 +24,7 @@ import (
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
 )
 
@@ -33,9 +34,11 @@ type cmdReboot struct {
 		Label string
 	} `positional-args:"true"`
 
-	RunMode     bool `long:"run"`
-	InstallMode bool `long:"install"`
-	RecoverMode bool `long:"recover"`
+	RunMode          bool `long:"run"`
+	InstallMode      bool `long:"install"`
+	RecoverMode      bool `long:"recover"`
+	FactoryResetMode bool `long:"factory-reset"`
+	DiscardIdentity  bool `long:"discard-identity"`
 }
 
 var shortRebootHelp = i18n.G("Reboot into selected system and mode")
@@ -49,8 +52,11 @@ trigger a regular reboot.
 When called without a system label but with a mode it will use the
 current system to enter the given mode.
 
-Note that "recover" and "run" modes are only available for the
-current system.
+Note that "recover", "factory-reset" and "run" modes are only available
+for the current system.
+
+A factory reset preserves the device identity, unless --discard-identity
+is given.
 `)
 
 func init() {
@@ -63,6 +69,10 @@ func init() {
 		"install": i18n.G("Boot into install mode"),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"recover": i18n.G("Boot into recover mode"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"factory-reset": i18n.G("Boot into factory-reset mode"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"discard-identity": i18n.G("Do not preserve the device identity when factory resetting"),
 	}, []argDesc{
 		{
 			// TRANSLATORS: This needs to begin with < and end with >
@@ -83,6 +93,7 @@ func (x *cmdReboot) modeFromCommandline() (string, error) {
 		{x.RunMode, "run"},
 		{x.RecoverMode, "recover"},
 		{x.InstallMode, "install"},
+		{x.FactoryResetMode, "factory-reset"},
 	} {
 		if !arg.enabled {
 			continue
@@ -106,7 +117,16 @@ func (x *cmdReboot) Execute(args []string) error {
 		return err
 	}
 
-	if err := x.client.RebootToSystem(x.Positional.Label, mode); err != nil {
+	if x.DiscardIdentity && mode != "factory-reset" {
+		return fmt.Errorf(i18n.G("--discard-identity can only be used with --factory-reset"))
+	}
+
+	if mode == "factory-reset" {
+		opts := &client.FactoryResetOptions{DiscardIdentity: x.DiscardIdentity}
+		if err := x.client.FactoryReset(x.Positional.Label, opts); err != nil {
+			return err
+		}
+	} else if err := x.client.RebootToSystem(x.Positional.Label, mode); err != nil {
 		return err
 	}
 
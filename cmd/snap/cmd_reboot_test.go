@@ -43,16 +43,22 @@ trigger a regular reboot.
 When called without a system label but with a mode it will use the
 current system to enter the given mode.
 
-Note that "recover" and "run" modes are only available for the
-current system.
+Note that "recover", "factory-reset" and "run" modes are only available
+for the current system.
+
+A factory reset preserves the device identity, unless --discard-identity
+is given.
 
 [reboot command options]
-      --run        Boot into run mode
-      --install    Boot into install mode
-      --recover    Boot into recover mode
+      --run                 Boot into run mode
+      --install             Boot into install mode
+      --recover             Boot into recover mode
+      --factory-reset       Boot into factory-reset mode
+      --discard-identity    Do not preserve the device identity when factory
+                            resetting
 
 [reboot command arguments]
-  <label>:         The recovery system label
+  <label>:                  The recovery system label
 `
 	s.testSubCommandHelp(c, "reboot", msg)
 }
@@ -89,6 +95,18 @@ func (s *SnapSuite) TestRebootHappy(c *C) {
 			expectedJSON:     `{"action":"reboot","mode":"recover"}`,
 			expectedMsg:      `Reboot into "20200101" "recover" mode.`,
 		},
+		{
+			cmdline:          []string{"reboot", "--factory-reset", "20200101"},
+			expectedEndpoint: "/v2/systems/20200101",
+			expectedJSON:     `{"action":"reboot","mode":"factory-reset"}`,
+			expectedMsg:      `Reboot into "20200101" "factory-reset" mode.`,
+		},
+		{
+			cmdline:          []string{"reboot", "--factory-reset", "--discard-identity"},
+			expectedEndpoint: "/v2/systems",
+			expectedJSON:     `{"action":"reboot","mode":"factory-reset","discard-identity":true}`,
+			expectedMsg:      `Reboot into "factory-reset" mode.`,
+		},
 	} {
 
 		n := 0
@@ -137,6 +155,10 @@ func (s *SnapSuite) TestRebootUnhappy(c *C) {
 			args:   []string{"reboot", "--unknown-mode", "20200101"},
 			errStr: "unknown flag `unknown-mode'",
 		},
+		{
+			args:   []string{"reboot", "--recover", "--discard-identity"},
+			errStr: "--discard-identity can only be used with --factory-reset",
+		},
 	}
 
 	for _, t := range tc {
@@ -33,10 +33,14 @@ import (
 )
 
 type cmdRecovery struct {
-	clientMixin
+	waitMixin
 	colorMixin
 
-	ShowKeys bool `long:"show-keys"`
+	ShowKeys         bool   `long:"show-keys"`
+	Create           string `long:"create" value-name:"<label>"`
+	RotateKeys       bool   `long:"rotate-keys"`
+	AddPassphrase    bool   `long:"add-passphrase"`
+	RemovePassphrase bool   `long:"remove-passphrase"`
 }
 
 var shortRecoveryHelp = i18n.G("List available recovery systems")
@@ -44,16 +48,36 @@ var longRecoveryHelp = i18n.G(`
 The recovery command lists the available recovery systems.
 
 With --show-keys it displays recovery keys that can be used to unlock the encrypted partitions if the device-specific automatic unlocking does not work.
+
+With --create it creates a new recovery system with the given label out of the
+snaps currently installed for the device model, and adds it to the recovery
+systems that the device can boot.
+
+With --rotate-keys it replaces the recovery keys of the encrypted partitions
+with newly generated ones. The old keys stop working once the new ones are in
+place, use --show-keys to display the new keys.
+
+With --add-passphrase it prompts for a passphrase that can be used to unlock
+the encrypted partitions in addition to the recovery keys, --remove-passphrase
+removes it again.
 `)
 
 func init() {
 	addCommand("recovery", shortRecoveryHelp, longRecoveryHelp, func() flags.Commander {
 		// XXX: if we want more/nicer details we can add `snap recovery <system>` later
 		return &cmdRecovery{}
-	}, colorDescs.also(
+	}, colorDescs.also(waitDescs).also(
 		map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"show-keys": i18n.G("Show recovery keys (if available) to unlock encrypted partitions."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"create": i18n.G("Create a new recovery system with the given label from the installed snaps."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"rotate-keys": i18n.G("Replace the recovery keys of the encrypted partitions with new ones."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"add-passphrase": i18n.G("Add a passphrase to unlock the encrypted partitions."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"remove-passphrase": i18n.G("Remove a passphrase used to unlock the encrypted partitions."),
 		}), nil)
 }
 
@@ -78,10 +102,110 @@ func (x *cmdRecovery) showKeys(w io.Writer) error {
 	return nil
 }
 
+func (x *cmdRecovery) createSystem(label string) error {
+	if release.OnClassic {
+		return errors.New(`command "create" is not available on classic systems`)
+	}
+	changeID, err := x.client.CreateSystem(label)
+	if err != nil {
+		return err
+	}
+	if _, err := x.wait(changeID); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+	fmt.Fprintf(Stdout, i18n.G("Recovery system %q created\n"), label)
+	return nil
+}
+
+func readPassphrase(confirm bool) (string, error) {
+	fmt.Fprint(Stdout, i18n.G("Passphrase: "))
+	passphrase, err := ReadPassword(0)
+	fmt.Fprint(Stdout, "\n")
+	if err != nil {
+		return "", err
+	}
+	if len(passphrase) == 0 {
+		return "", errors.New(i18n.G("passphrase cannot be empty"))
+	}
+	if confirm {
+		fmt.Fprint(Stdout, i18n.G("Confirm passphrase: "))
+		confirmPassphrase, err := ReadPassword(0)
+		fmt.Fprint(Stdout, "\n")
+		if err != nil {
+			return "", err
+		}
+		if string(passphrase) != string(confirmPassphrase) {
+			return "", errors.New(i18n.G("passphrases do not match"))
+		}
+	}
+	return string(passphrase), nil
+}
+
+func (x *cmdRecovery) manageKeys() error {
+	if release.OnClassic {
+		return errors.New(`managing encryption keys is not available on classic systems`)
+	}
+	var changeID, done string
+	var err error
+	switch {
+	case x.RotateKeys:
+		changeID, err = x.client.RotateSystemRecoveryKeys()
+		done = i18n.G("Recovery keys rotated, use --show-keys to display the new keys\n")
+	case x.AddPassphrase:
+		var passphrase string
+		passphrase, err = readPassphrase(true)
+		if err != nil {
+			return err
+		}
+		changeID, err = x.client.AddSystemPassphrase(passphrase)
+		done = i18n.G("Passphrase added\n")
+	case x.RemovePassphrase:
+		var passphrase string
+		passphrase, err = readPassphrase(false)
+		if err != nil {
+			return err
+		}
+		changeID, err = x.client.RemoveSystemPassphrase(passphrase)
+		done = i18n.G("Passphrase removed\n")
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := x.wait(changeID); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+	fmt.Fprint(Stdout, done)
+	return nil
+}
+
 func (x *cmdRecovery) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
 	}
+	if x.ShowKeys && x.Create != "" {
+		return errors.New(i18n.G("cannot use --show-keys and --create together"))
+	}
+	actions := 0
+	for _, set := range []bool{x.ShowKeys, x.Create != "", x.RotateKeys, x.AddPassphrase, x.RemovePassphrase} {
+		if set {
+			actions++
+		}
+	}
+	if actions > 1 {
+		return errors.New(i18n.G("cannot use more than one of --show-keys, --create, --rotate-keys, --add-passphrase and --remove-passphrase"))
+	}
+	if x.Create != "" {
+		return x.createSystem(x.Create)
+	}
+	if x.RotateKeys || x.AddPassphrase || x.RemovePassphrase {
+		return x.manageKeys()
+	}
 
 	esc := x.getEscapes()
 	w := tabWriter()
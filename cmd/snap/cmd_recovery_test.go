@@ -38,13 +38,35 @@ The recovery command lists the available recovery systems.
 With --show-keys it displays recovery keys that can be used to unlock the
 encrypted partitions if the device-specific automatic unlocking does not work.
 
+With --create it creates a new recovery system with the given label out of the
+snaps currently installed for the device model, and adds it to the recovery
+systems that the device can boot.
+
+With --rotate-keys it replaces the recovery keys of the encrypted partitions
+with newly generated ones. The old keys stop working once the new ones are in
+place, use --show-keys to display the new keys.
+
+With --add-passphrase it prompts for a passphrase that can be used to unlock
+the encrypted partitions in addition to the recovery keys, --remove-passphrase
+removes it again.
+
 [recovery command options]
-      --color=[auto|never|always]     Use a little bit of color to highlight
-                                      some things. (default: auto)
-      --unicode=[auto|never|always]   Use a little bit of Unicode to improve
-                                      legibility. (default: auto)
-      --show-keys                     Show recovery keys (if available) to
-                                      unlock encrypted partitions.
+      --no-wait                          Do not wait for the operation to
+                                         finish but just print the change id.
+      --color=[auto|never|always]        Use a little bit of color to highlight
+                                         some things. (default: auto)
+      --unicode=[auto|never|always]      Use a little bit of Unicode to improve
+                                         legibility. (default: auto)
+      --show-keys                        Show recovery keys (if available) to
+                                         unlock encrypted partitions.
+      --create=<label>                   Create a new recovery system with the
+                                         given label from the installed snaps.
+      --rotate-keys                      Replace the recovery keys of the
+                                         encrypted partitions with new ones.
+      --add-passphrase                   Add a passphrase to unlock the
+                                         encrypted partitions.
+      --remove-passphrase                Remove a passphrase used to unlock the
+                                         encrypted partitions.
 `
 	s.testSubCommandHelp(c, "recovery", msg)
 }
@@ -192,3 +214,169 @@ reinstall:  1234
 	c.Check(s.Stderr(), Equals, "")
 	c.Check(n, Equals, 1)
 }
+
+func (s *SnapSuite) TestRecoveryCreateOnClassicErrors(c *C) {
+	restore := release.MockOnClassic(true)
+	defer restore()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected server call")
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"recovery", "--create", "20260101"})
+	c.Assert(err, ErrorMatches, `command "create" is not available on classic systems`)
+}
+
+func (s *SnapSuite) TestRecoveryCreateWithShowKeysErrors(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected server call")
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"recovery", "--create", "20260101", "--show-keys"})
+	c.Assert(err, ErrorMatches, `cannot use --show-keys and --create together`)
+}
+
+func (s *SnapSuite) TestRecoveryCreateHappy(c *C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/systems")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]interface{}{
+				"action": "create",
+				"label":  "20260101",
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "42"}`)
+		case 1:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/changes/42")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("expected to get 2 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"recovery", "--create", "20260101"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, "Recovery system \"20260101\" created\n")
+	c.Check(s.Stderr(), Equals, "")
+	c.Check(n, Equals, 2)
+}
+
+func (s *SnapSuite) TestRecoveryCreateNoWait(c *C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/systems")
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "42"}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"recovery", "--create", "20260101", "--no-wait"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "42\n")
+	c.Check(n, Equals, 1)
+}
+
+func (s *SnapSuite) TestRecoveryManageKeysOnClassicErrors(c *C) {
+	restore := release.MockOnClassic(true)
+	defer restore()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected server call")
+	})
+	for _, flag := range []string{"--rotate-keys", "--add-passphrase", "--remove-passphrase"} {
+		_, err := snap.Parser(snap.Client()).ParseArgs([]string{"recovery", flag})
+		c.Assert(err, ErrorMatches, `managing encryption keys is not available on classic systems`)
+	}
+}
+
+func (s *SnapSuite) TestRecoveryManageKeysTogetherErrors(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected server call")
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"recovery", "--rotate-keys", "--add-passphrase"})
+	c.Assert(err, ErrorMatches, `cannot use more than one of --show-keys, --create, --rotate-keys, --add-passphrase and --remove-passphrase`)
+}
+
+func (s *SnapSuite) TestRecoveryManageKeysHappy(c *C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+
+	s.password = "open sesame"
+	for _, tc := range []struct {
+		flag   string
+		body   map[string]interface{}
+		stdout string
+	}{
+		{"--rotate-keys", map[string]interface{}{"action": "rotate"},
+			"Recovery keys rotated, use --show-keys to display the new keys\n"},
+		{"--add-passphrase", map[string]interface{}{"action": "add-passphrase", "passphrase": "open sesame"},
+			"Passphrase: \nConfirm passphrase: \nPassphrase added\n"},
+		{"--remove-passphrase", map[string]interface{}{"action": "remove-passphrase", "passphrase": "open sesame"},
+			"Passphrase: \nPassphrase removed\n"},
+	} {
+		s.ResetStdStreams()
+		n := 0
+		s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+			switch n {
+			case 0:
+				c.Check(r.Method, Equals, "POST")
+				c.Check(r.URL.Path, Equals, "/v2/system-recovery-keys")
+				c.Check(DecodedRequestBody(c, r), DeepEquals, tc.body)
+				w.WriteHeader(202)
+				fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "42"}`)
+			case 1:
+				c.Check(r.Method, Equals, "GET")
+				c.Check(r.URL.Path, Equals, "/v2/changes/42")
+				fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done"}}`)
+			default:
+				c.Fatalf("expected to get 2 requests, now on %d", n+1)
+			}
+
+			n++
+		})
+		rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"recovery", tc.flag})
+		c.Assert(err, IsNil)
+		c.Assert(rest, DeepEquals, []string{})
+		c.Check(s.Stdout(), Equals, tc.stdout)
+		c.Check(s.Stderr(), Equals, "")
+		c.Check(n, Equals, 2)
+	}
+}
+
+func (s *SnapSuite) TestRecoveryRotateKeysError(c *C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "error", "result": {"message": "no encrypted volumes with recovery keys"}, "status-code": 400}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"recovery", "--rotate-keys"})
+	c.Assert(err, ErrorMatches, `cannot rotate recovery keys: no encrypted volumes with recovery keys`)
+}
+
+func (s *SnapSuite) TestRecoveryAddPassphraseEmptyErrors(c *C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected server call")
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"recovery", "--add-passphrase"})
+	c.Assert(err, ErrorMatches, `passphrase cannot be empty`)
+}
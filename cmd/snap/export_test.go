@@ -400,3 +400,5 @@ func MockSnapdWaitForFullSystemReboot(t time.Duration) (restore func()) {
 		snapdWaitForFullSystemReboot = old
 	}
 }
+
+var UnifiedDiff = unifiedDiff
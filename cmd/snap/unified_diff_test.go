@@ -0,0 +1,92 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+type unifiedDiffSuite struct{}
+
+var _ = check.Suite(&unifiedDiffSuite{})
+
+func (s *unifiedDiffSuite) TestNoChanges(c *check.C) {
+	c.Check(snap.UnifiedDiff("a", "b", "1\n2\n", "1\n2\n"), check.Equals, "")
+	c.Check(snap.UnifiedDiff("a", "b", "", ""), check.Equals, "")
+}
+
+func (s *unifiedDiffSuite) TestNewAndRemoved(c *check.C) {
+	c.Check(snap.UnifiedDiff("/dev/null", "b", "", "1\n2\n"), check.Equals, `--- /dev/null
++++ b
+@@ -0,0 +1,2 @@
++1
++2
+`)
+	c.Check(snap.UnifiedDiff("a", "/dev/null", "1\n", ""), check.Equals, `--- a
++++ /dev/null
+@@ -1 +0,0 @@
+-1
+`)
+}
+
+func (s *unifiedDiffSuite) TestHunks(c *check.C) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+	new := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n16\n17\n"
+	c.Check(snap.UnifiedDiff("a", "b", old, new), check.Equals, `--- a
++++ b
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -12,5 +12,5 @@
+ 12
+ 13
+ 14
+-15
+ 16
++17
+`)
+
+	// changes with up to twice the context lines between them share
+	// a hunk
+	old = "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	new = "1\ntwo\n3\n4\n5\n6\n7\n8\nnine\n"
+	c.Check(snap.UnifiedDiff("a", "b", old, new), check.Equals, `--- a
++++ b
+@@ -1,9 +1,9 @@
+ 1
+-2
++two
+ 3
+ 4
+ 5
+ 6
+ 7
+ 8
+-9
++nine
+`)
+}
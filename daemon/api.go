@@ -67,6 +67,7 @@ var api = []*Command{
 	snapsCmd,
 	snapCmd,
 	snapFileCmd,
+	snapDenialsCmd,
 	snapDownloadCmd,
 	snapConfCmd,
 	interfacesCmd,
@@ -93,11 +94,15 @@ var api = []*Command{
 	cohortsCmd,
 	serialModelCmd,
 	systemsCmd,
+	// needs to be before systemsActionCmd so that it takes precedence
+	// over the label
+	systemsBootHistoryCmd,
 	systemsActionCmd,
 	validationSetsListCmd,
 	validationSetsCmd,
 	routineConsoleConfStartCmd,
 	systemRecoveryKeysCmd,
+	historyCmd,
 }
 
 var (
@@ -0,0 +1,46 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"net/http"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/overlord/auth"
+)
+
+var systemsBootHistoryCmd = &Command{
+	Path:   "/v2/systems/boot-history",
+	UserOK: true,
+	GET:    getSystemsBootHistory,
+}
+
+var bootBootHistory = boot.BootHistory
+
+func getSystemsBootHistory(c *Command, r *http.Request, user *auth.UserState) Response {
+	entries, err := bootBootHistory()
+	if err != nil {
+		return InternalError("cannot get boot history: %v", err)
+	}
+	if entries == nil {
+		entries = []*boot.BootHistoryEntry{}
+	}
+	return SyncResponse(entries, nil)
+}
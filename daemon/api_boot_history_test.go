@@ -0,0 +1,95 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/daemon"
+)
+
+var _ = check.Suite(&bootHistorySuite{})
+
+type bootHistorySuite struct{}
+
+func (s *bootHistorySuite) TestBootHistory(c *check.C) {
+	entries := []*boot.BootHistoryEntry{{
+		BootID:  "boot-id-1",
+		Time:    time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Mode:    "run",
+		Kernel:  "pc-kernel_2.snap",
+		Base:    "core20_1.snap",
+		Try:     true,
+		Outcome: boot.BootOutcomeReverted,
+	}}
+	restore := daemon.MockBootBootHistory(func() ([]*boot.BootHistoryEntry, error) {
+		return entries, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/systems/boot-history", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := daemon.SystemsBootHistoryCmd.GET(daemon.SystemsBootHistoryCmd, req, nil)
+	c.Check(rsp, check.DeepEquals, &daemon.Resp{
+		Status: 200,
+		Type:   "sync",
+		Result: entries,
+	})
+}
+
+func (s *bootHistorySuite) TestBootHistoryEmpty(c *check.C) {
+	restore := daemon.MockBootBootHistory(func() ([]*boot.BootHistoryEntry, error) {
+		return nil, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/systems/boot-history", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := daemon.SystemsBootHistoryCmd.GET(daemon.SystemsBootHistoryCmd, req, nil)
+	c.Check(rsp, check.DeepEquals, &daemon.Resp{
+		Status: 200,
+		Type:   "sync",
+		Result: []*boot.BootHistoryEntry{},
+	})
+}
+
+func (s *bootHistorySuite) TestBootHistoryError(c *check.C) {
+	restore := daemon.MockBootBootHistory(func() ([]*boot.BootHistoryEntry, error) {
+		return nil, errors.New("boom")
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/systems/boot-history", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := daemon.SystemsBootHistoryCmd.GET(daemon.SystemsBootHistoryCmd, req, nil)
+	c.Check(rsp, check.DeepEquals, &daemon.Resp{
+		Status: 500,
+		Type:   "error",
+		Result: &daemon.ErrorResult{Message: "cannot get boot history: boom"},
+	})
+}
@@ -290,6 +290,10 @@ func getDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 		return getChangeTimings(st, chgID, ensureTag, startupTag, all == "true")
 	case "seeding":
 		return getSeedingInfo(st)
+	case "profiles":
+		return getSnapProfiles(c.d.overlord.InterfaceManager(), query.Get("snap"), query.Get("backend"))
+	case "policy":
+		return explainConnectionPolicy(c.d.overlord.InterfaceManager(), query.Get("plug"), query.Get("slot"))
 	default:
 		return BadRequest("unknown debug aspect %q", aspect)
 	}
@@ -0,0 +1,58 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"strings"
+
+	"github.com/snapcore/snapd/overlord/ifacestate"
+)
+
+// splitPlugOrSlot splits a <snap>:<plug or slot> reference, the snap
+// name or the plug or slot name can be omitted.
+func splitPlugOrSlot(ref string) (snapName, name string) {
+	if i := strings.IndexByte(ref, ':'); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// explainConnectionPolicy reports how the declaration rules are
+// evaluated for connecting the given plug and slot, resolved like
+// when connecting them.
+func explainConnectionPolicy(ifaceMgr *ifacestate.InterfaceManager, plug, slot string) Response {
+	if plug == "" {
+		return BadRequest("plug is required")
+	}
+	plugSnap, plugName := splitPlugOrSlot(plug)
+	slotSnap, slotName := splitPlugOrSlot(slot)
+	plugSnap = ifacestate.RemapSnapFromRequest(plugSnap)
+	slotSnap = ifacestate.RemapSnapFromRequest(slotSnap)
+
+	connRef, err := ifaceMgr.Repository().ResolveConnect(plugSnap, plugName, slotSnap, slotName)
+	if err != nil {
+		return BadRequest("%v", err)
+	}
+	ex, err := ifaceMgr.ExplainConnection(connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
+	if err != nil {
+		return BadRequest("cannot explain connection: %v", err)
+	}
+	return SyncResponse(ex, nil)
+}
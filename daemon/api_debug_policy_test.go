@@ -0,0 +1,86 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/policy"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+)
+
+var _ = check.Suite(&debugPolicySuite{})
+
+type debugPolicySuite struct {
+	apiBaseSuite
+}
+
+func (s *debugPolicySuite) TestExplainConnection(c *check.C) {
+	d := s.daemon(c)
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	// the slot name is resolved
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=policy&plug=consumer:plug&slot=producer", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.req(c, req, nil).(*daemon.Resp)
+	c.Assert(rsp.Type, check.Equals, daemon.ResponseTypeSync)
+	c.Check(rsp.Result, check.DeepEquals, &ifacestate.ConnectionExplanation{
+		Plug:                 interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		Slot:                 interfaces.SlotRef{Snap: "producer", Name: "slot"},
+		Interface:            "test",
+		Connection:           &policy.Explanation{Kind: "connection", Allowed: true},
+		AutoConnection:       &policy.Explanation{Kind: "auto-connection", Allowed: true},
+		InterfaceAutoConnect: true,
+		Notes: []string{
+			`snap "consumer" has no snap declaration, manual connections are not checked against the rules`,
+			`snap "producer" has no snap declaration, manual connections are not checked against the rules`,
+		},
+	})
+}
+
+func (s *debugPolicySuite) TestExplainConnectionErrors(c *check.C) {
+	d := s.daemon(c)
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	for _, t := range []struct {
+		query   string
+		message string
+	}{
+		{"", "plug is required"},
+		{"plug=consumer:missing&slot=producer:slot", `snap "consumer" has no plug named "missing"`},
+		{"plug=consumer:plug&slot=producer:missing", `snap "producer" has no slot named "missing"`},
+	} {
+		req, err := http.NewRequest("GET", "/v2/debug?aspect=policy&"+t.query, nil)
+		c.Assert(err, check.IsNil)
+		rsp := s.req(c, req, nil).(*daemon.Resp)
+		c.Check(rsp.Type, check.Equals, daemon.ResponseTypeError, check.Commentf(t.query))
+		c.Check(rsp.Status, check.Equals, 400, check.Commentf(t.query))
+		c.Check(rsp.ErrorResult().Message, check.Equals, t.message, check.Commentf(t.query))
+	}
+}
@@ -0,0 +1,54 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+// getSnapProfiles returns the security profiles of the snap, or of an
+// app of it when given as <snap>.<app>, as rendered from the current
+// state of the interfaces repository.
+func getSnapProfiles(ifaceMgr *ifacestate.InterfaceManager, snapApp, backend string) Response {
+	if snapApp == "" {
+		return BadRequest("snap name is required")
+	}
+	snapName, appName := snapApp, ""
+	if i := strings.IndexByte(snapApp, '.'); i >= 0 {
+		snapName, appName = snapApp[:i], snapApp[i+1:]
+	}
+
+	profiles, err := ifaceMgr.Profiles(snapName, appName, interfaces.SecuritySystem(backend))
+	if err == state.ErrNoState {
+		return SnapNotFound(snapName, &snap.NotInstalledError{Snap: snapName})
+	}
+	if err != nil {
+		return BadRequest("cannot get security profiles: %v", err)
+	}
+	if profiles == nil {
+		profiles = []*ifacestate.Profile{}
+	}
+	return SyncResponse(profiles, nil)
+}
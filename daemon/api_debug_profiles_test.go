@@ -0,0 +1,118 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"net/http"
+	"strings"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/snap"
+)
+
+var _ = check.Suite(&debugProfilesSuite{})
+
+type debugProfilesSuite struct {
+	apiBaseSuite
+}
+
+// profilesBackend renders one profile per snap app with the snippets
+// of the test specification
+type profilesBackend struct {
+	ifacetest.TestSecurityBackend
+}
+
+func (b *profilesBackend) Profiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string][]byte, error) {
+	spec, err := repo.SnapSpecification(b.Name(), snapInfo.InstanceName())
+	if err != nil {
+		return nil, err
+	}
+	snippets := spec.(*ifacetest.Specification).Snippets
+	profiles := make(map[string][]byte)
+	for _, app := range snapInfo.Apps {
+		profiles["/profiles/"+app.SecurityTag()] = []byte(strings.Join(snippets, "\n") + "\n")
+	}
+	return profiles, nil
+}
+
+func mockProfilesBackend(c *check.C, d *daemon.Daemon) {
+	repo := d.Overlord().InterfaceManager().Repository()
+	c.Assert(repo.AddBackend(&profilesBackend{ifacetest.TestSecurityBackend{BackendName: "profiles"}}), check.IsNil)
+	mockIface(c, d, &ifacetest.TestInterface{
+		InterfaceName: "test",
+		TestConnectedPlugCallback: func(spec *ifacetest.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AddSnippet("connected-plug")
+			return nil
+		},
+		TestPermanentSlotCallback: func(spec *ifacetest.Specification, slot *snap.SlotInfo) error {
+			spec.AddSnippet("permanent-slot")
+			return nil
+		},
+	})
+}
+
+func (s *debugProfilesSuite) TestGetProfiles(c *check.C) {
+	d := s.daemon(c)
+	mockProfilesBackend(c, d)
+	s.mockSnap(c, producerYaml)
+
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=profiles&snap=producer", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.req(c, req, nil).(*daemon.Resp)
+	c.Assert(rsp.Type, check.Equals, daemon.ResponseTypeSync)
+	c.Check(rsp.Result, check.DeepEquals, []*ifacestate.Profile{
+		{Backend: "profiles", Path: "/profiles/snap.producer.app", Content: "permanent-slot\n"},
+	})
+
+	req, err = http.NewRequest("GET", "/v2/debug?aspect=profiles&snap=producer.app&backend=profiles", nil)
+	c.Assert(err, check.IsNil)
+	rsp = s.req(c, req, nil).(*daemon.Resp)
+	c.Assert(rsp.Type, check.Equals, daemon.ResponseTypeSync)
+	c.Check(rsp.Result, check.HasLen, 1)
+}
+
+func (s *debugProfilesSuite) TestGetProfilesErrors(c *check.C) {
+	d := s.daemon(c)
+	mockProfilesBackend(c, d)
+	s.mockSnap(c, producerYaml)
+
+	for _, t := range []struct {
+		query   string
+		status  int
+		message string
+	}{
+		{"", 400, "snap name is required"},
+		{"snap=missing", 404, `snap "missing" is not installed`},
+		{"snap=producer.missing", 400, `cannot get security profiles: snap "producer" has no app "missing"`},
+		{"snap=producer&backend=missing", 400, `cannot get security profiles: unknown security backend "missing"`},
+	} {
+		req, err := http.NewRequest("GET", "/v2/debug?aspect=profiles&"+t.query, nil)
+		c.Assert(err, check.IsNil)
+		rsp := s.req(c, req, nil).(*daemon.Resp)
+		c.Check(rsp.Type, check.Equals, daemon.ResponseTypeError, check.Commentf(t.query))
+		c.Check(rsp.Status, check.Equals, t.status, check.Commentf(t.query))
+		c.Check(rsp.ErrorResult().Message, check.Equals, t.message, check.Commentf(t.query))
+	}
+}
@@ -0,0 +1,66 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"net/http"
+
+	"github.com/snapcore/snapd/interfaces/denials"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/snap"
+)
+
+var snapDenialsCmd = &Command{
+	Path: "/v2/snaps/{name}/denials",
+	GET:  getSnapDenials,
+}
+
+var (
+	denialsCollect = denials.Collect
+	denialsSuggest = denials.Suggest
+)
+
+func getSnapDenials(c *Command, r *http.Request, user *auth.UserState) Response {
+	vars := muxVars(r)
+	name := vars["name"]
+
+	st := c.d.overlord.State()
+	st.Lock()
+	info, err := snapstate.CurrentInfo(st, name)
+	st.Unlock()
+	if err != nil {
+		if _, ok := err.(*snap.NotInstalledError); ok {
+			return SnapNotFound(name, err)
+		}
+		return InternalError("cannot get denials for snap %q: %v", name, err)
+	}
+
+	ds, err := denialsCollect(name)
+	if err != nil {
+		return InternalError("cannot get denials for snap %q: %v", name, err)
+	}
+	if ds == nil {
+		ds = []*denials.Denial{}
+	}
+	denialsSuggest(info, ds)
+
+	return SyncResponse(ds, nil)
+}
@@ -0,0 +1,124 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"errors"
+	"net/http"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces/denials"
+	"github.com/snapcore/snapd/overlord"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+var _ = check.Suite(&denialsSuite{})
+
+type denialsSuite struct {
+	o *overlord.Overlord
+}
+
+func (s *denialsSuite) SetUpTest(c *check.C) {
+	dirs.SetRootDir(c.MkDir())
+	s.o = overlord.Mock()
+	daemon.NewWithOverlord(s.o)
+}
+
+func (s *denialsSuite) TearDownTest(c *check.C) {
+	dirs.SetRootDir("")
+}
+
+func (s *denialsSuite) mockFoo(c *check.C) {
+	si := &snap.SideInfo{RealName: "foo", Revision: snap.R(1)}
+	snaptest.MockSnap(c, "name: foo\nversion: 1\napps:\n  app:\n", si)
+
+	st := s.o.State()
+	st.Lock()
+	defer st.Unlock()
+	snapstate.Set(st, "foo", &snapstate.SnapState{
+		Active:   true,
+		Sequence: []*snap.SideInfo{si},
+		Current:  si.Revision,
+	})
+}
+
+func (s *denialsSuite) TestGetDenials(c *check.C) {
+	defer daemon.MockMuxVars(func(*http.Request) map[string]string {
+		return map[string]string{"name": "foo"}
+	})()
+	s.mockFoo(c)
+
+	ds := []*denials.Denial{{Snap: "foo", App: "app", Class: "file", Target: "/dev/video0", Permissions: "r", Count: 1}}
+	defer daemon.MockDenials(func(name string) ([]*denials.Denial, error) {
+		c.Check(name, check.Equals, "foo")
+		return ds, nil
+	}, func(info *snap.Info, ds []*denials.Denial) {
+		c.Check(info.InstanceName(), check.Equals, "foo")
+		for _, d := range ds {
+			d.SuggestedPlugs = []string{"camera"}
+		}
+	})()
+
+	req, err := http.NewRequest("GET", "/v2/snaps/foo/denials", nil)
+	c.Assert(err, check.IsNil)
+	rsp := daemon.SnapDenialsCmd.GET(daemon.SnapDenialsCmd, req, nil)
+	c.Check(rsp, check.DeepEquals, &daemon.Resp{
+		Status: 200,
+		Type:   "sync",
+		Result: []*denials.Denial{{Snap: "foo", App: "app", Class: "file", Target: "/dev/video0", Permissions: "r", Count: 1, SuggestedPlugs: []string{"camera"}}},
+	})
+}
+
+func (s *denialsSuite) TestGetDenialsNotInstalled(c *check.C) {
+	defer daemon.MockMuxVars(func(*http.Request) map[string]string {
+		return map[string]string{"name": "foo"}
+	})()
+
+	req, err := http.NewRequest("GET", "/v2/snaps/foo/denials", nil)
+	c.Assert(err, check.IsNil)
+	rsp := daemon.SnapDenialsCmd.GET(daemon.SnapDenialsCmd, req, nil).(*daemon.Resp)
+	c.Check(rsp.Status, check.Equals, 404)
+	c.Check(rsp.Result.(*daemon.ErrorResult).Message, check.Equals, `snap "foo" is not installed`)
+}
+
+func (s *denialsSuite) TestGetDenialsError(c *check.C) {
+	defer daemon.MockMuxVars(func(*http.Request) map[string]string {
+		return map[string]string{"name": "foo"}
+	})()
+	s.mockFoo(c)
+
+	defer daemon.MockDenials(func(name string) ([]*denials.Denial, error) {
+		return nil, errors.New("boom")
+	}, nil)()
+
+	req, err := http.NewRequest("GET", "/v2/snaps/foo/denials", nil)
+	c.Assert(err, check.IsNil)
+	rsp := daemon.SnapDenialsCmd.GET(daemon.SnapDenialsCmd, req, nil)
+	c.Check(rsp, check.DeepEquals, &daemon.Resp{
+		Status: 500,
+		Type:   "error",
+		Result: &daemon.ErrorResult{Message: `cannot get denials for snap "foo": boom`},
+	})
+}
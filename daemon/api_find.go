@@ -21,9 +21,11 @@ package daemon
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/gorilla/mux"
 
@@ -59,6 +61,27 @@ func searchStore(c *Command, r *http.Request, user *auth.UserState) Response {
 	private := false
 	prefix := false
 
+	verified := false
+	if v := query.Get("verified"); v != "" {
+		var err error
+		verified, err = strconv.ParseBool(v)
+		if err != nil {
+			return BadRequest("invalid value for 'verified': %q", v)
+		}
+	}
+	limit := 0
+	if l := query.Get("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			return BadRequest("invalid value for 'limit': %q", l)
+		}
+	}
+	// the cursor is the next-cursor of a previous response, it only
+	// pages through the first page of results of the store, see
+	// paginateFound
+	cursor := query.Get("cursor")
+
 	if sel := query.Get("select"); sel != "" {
 		switch sel {
 		case "refresh":
@@ -97,19 +120,29 @@ func searchStore(c *Command, r *http.Request, user *auth.UserState) Response {
 		return BadRequest("cannot use 'common-id' and 'q' together")
 	}
 
+	search := &store.Search{
+		Query:             q,
+		Prefix:            prefix,
+		CommonID:          commonID,
+		Category:          section,
+		Private:           private,
+		Scope:             scope,
+		Publisher:         query.Get("publisher"),
+		VerifiedPublisher: verified,
+		Confinement:       query.Get("confinement"),
+		Architecture:      query.Get("architecture"),
+		License:           query.Get("license"),
+		Base:              query.Get("base"),
+		Sort:              query.Get("sort"),
+	}
 	theStore := getStore(c)
 	ctx := store.WithClientUserAgent(r.Context(), r)
-	found, err := theStore.Find(ctx, &store.Search{
-		Query:    q,
-		Prefix:   prefix,
-		CommonID: commonID,
-		Category: section,
-		Private:  private,
-		Scope:    scope,
-	}, user)
+	found, err := theStore.Find(ctx, search, user)
 	switch err {
 	case nil:
 		// pass
+	case store.ErrInvalidSort, store.ErrInvalidConfinement:
+		return BadRequest(err.Error())
 	case store.ErrBadQuery:
 		return SyncResponse(&resp{
 			Type:   ResponseTypeError,
@@ -148,14 +181,41 @@ func searchStore(c *Command, r *http.Request, user *auth.UserState) Response {
 		return InternalError("%v", err)
 	}
 
+	found, nextCursor, err := paginateFound(found, cursor, limit)
+	if err != nil {
+		return BadRequest(err.Error())
+	}
+
 	meta := &Meta{
 		SuggestedCurrency: theStore.SuggestedCurrency(),
 		Sources:           []string{"store"},
+		NextCursor:        nextCursor,
 	}
 
 	return sendStorePackages(route, meta, found)
 }
 
+// paginateFound returns the page of at most limit snaps starting at the
+// given cursor, together with the cursor of the following page, if any. A
+// limit of zero means no pagination. The store does not support cursors, so
+// the cursor is an offset in the single page of results it returns for the
+// search, after the filters applied locally, and each page repeats the
+// search: snaps beyond the results of the store cannot be reached.
+func paginateFound(found []*snap.Info, cursor string, limit int) (page []*snap.Info, nextCursor string, err error) {
+	start := 0
+	if cursor != "" {
+		start, err = strconv.Atoi(cursor)
+		if err != nil || start < 0 || start > len(found) {
+			return nil, "", fmt.Errorf("invalid cursor %q", cursor)
+		}
+	}
+	if limit == 0 || start+limit >= len(found) {
+		return found[start:], "", nil
+	}
+	end := start + limit
+	return found[start:end], strconv.Itoa(end), nil
+}
+
 func findOne(c *Command, r *http.Request, user *auth.UserState, name string) Response {
 	if err := snap.ValidateName(name); err != nil {
 		return BadRequest(err.Error())
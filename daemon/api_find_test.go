@@ -242,6 +242,121 @@ func (s *findSuite) TestFindScope(c *check.C) {
 	})
 }
 
+func (s *findSuite) TestFindFilters(c *check.C) {
+	s.daemon(c)
+
+	s.rsnaps = []*snap.Info{}
+
+	req, err := http.NewRequest("GET", "/v2/find?q=foo&publisher=acme&verified=true&confinement=strict&architecture=arm64&license=MIT&base=core20&sort=name", nil)
+	c.Assert(err, check.IsNil)
+
+	_ = s.req(c, req, nil).(*daemon.Resp)
+
+	c.Check(s.storeSearch, check.DeepEquals, store.Search{
+		Query:             "foo",
+		Publisher:         "acme",
+		VerifiedPublisher: true,
+		Confinement:       "strict",
+		Architecture:      "arm64",
+		License:           "MIT",
+		Base:              "core20",
+		Sort:              "name",
+	})
+}
+
+func (s *findSuite) TestFindInvalidFilters(c *check.C) {
+	s.daemon(c)
+
+	for _, t := range []struct {
+		query    string
+		storeErr error
+		msg      string
+	}{
+		{"verified=maybe", nil, `invalid value for 'verified': "maybe"`},
+		{"limit=0", nil, `invalid value for 'limit': "0"`},
+		{"limit=x", nil, `invalid value for 'limit': "x"`},
+		{"cursor=x", nil, `invalid cursor "x"`},
+		{"cursor=10", nil, `invalid cursor "10"`},
+		{"sort=size", store.ErrInvalidSort, `invalid sort order`},
+		{"confinement=loose", store.ErrInvalidConfinement, `invalid confinement`},
+	} {
+		s.rsnaps = []*snap.Info{}
+		s.err = t.storeErr
+
+		req, err := http.NewRequest("GET", "/v2/find?q=foo&"+t.query, nil)
+		c.Assert(err, check.IsNil)
+
+		rsp := s.req(c, req, nil).(*daemon.Resp)
+		c.Check(rsp.Type, check.Equals, daemon.ResponseTypeError, check.Commentf(t.query))
+		c.Check(rsp.Status, check.Equals, 400)
+		c.Check(rsp.Result.(*daemon.ErrorResult).Message, check.Equals, t.msg)
+	}
+}
+
+func (s *findSuite) TestFindPagination(c *check.C) {
+	s.daemon(c)
+
+	for _, name := range []string{"one", "two", "three", "four", "five"} {
+		s.rsnaps = append(s.rsnaps, &snap.Info{SideInfo: snap.SideInfo{RealName: name}})
+	}
+
+	for _, t := range []struct {
+		query      string
+		names      []string
+		nextCursor string
+	}{
+		{"", []string{"one", "two", "three", "four", "five"}, ""},
+		{"&limit=2", []string{"one", "two"}, "2"},
+		{"&limit=2&cursor=2", []string{"three", "four"}, "4"},
+		{"&limit=2&cursor=4", []string{"five"}, ""},
+		{"&limit=5", []string{"one", "two", "three", "four", "five"}, ""},
+		{"&cursor=3", []string{"four", "five"}, ""},
+	} {
+		req, err := http.NewRequest("GET", "/v2/find?q=foo"+t.query, nil)
+		c.Assert(err, check.IsNil)
+
+		rsp := s.req(c, req, nil).(*daemon.Resp)
+		c.Assert(rsp.Type, check.Equals, daemon.ResponseTypeSync)
+
+		snaps := snapList(rsp.Result)
+		var names []string
+		for _, sn := range snaps {
+			names = append(names, sn["name"].(string))
+		}
+		c.Check(names, check.DeepEquals, t.names, check.Commentf(t.query))
+		c.Check(rsp.NextCursor, check.Equals, t.nextCursor)
+	}
+}
+
+func (s *findSuite) TestFindPaginationLocallyFiltered(c *check.C) {
+	s.daemon(c)
+
+	// the results as filtered by the store
+	for _, name := range []string{"one", "two", "three"} {
+		s.rsnaps = append(s.rsnaps, &snap.Info{SideInfo: snap.SideInfo{RealName: name}})
+	}
+
+	for _, filter := range []string{"verified=true", "license=MIT", "base=core20"} {
+		req, err := http.NewRequest("GET", "/v2/find?q=foo&limit=2&"+filter, nil)
+		c.Assert(err, check.IsNil)
+		rsp := s.req(c, req, nil).(*daemon.Resp)
+		c.Assert(rsp.Type, check.Equals, daemon.ResponseTypeSync)
+		c.Check(snapList(rsp.Result), check.HasLen, 2)
+		c.Check(rsp.NextCursor, check.Equals, "2")
+
+		// the remaining results can be reached
+		req, err = http.NewRequest("GET", "/v2/find?q=foo&limit=2&cursor=2&"+filter, nil)
+		c.Assert(err, check.IsNil)
+		rsp = s.req(c, req, nil).(*daemon.Resp)
+		c.Assert(rsp.Type, check.Equals, daemon.ResponseTypeSync)
+		snaps := snapList(rsp.Result)
+		c.Assert(snaps, check.HasLen, 1)
+		c.Check(snaps[0]["name"], check.Equals, "three")
+		c.Check(rsp.NextCursor, check.Equals, "")
+		c.Check(s.storeSearch.LocallyFiltered(), check.Equals, true)
+	}
+}
+
 func (s *findSuite) TestFindCommonID(c *check.C) {
 	s.daemon(c)
 
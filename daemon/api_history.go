@@ -0,0 +1,48 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"net/http"
+
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/snapstate"
+)
+
+var historyCmd = &Command{
+	Path:   "/v2/history",
+	UserOK: true,
+	GET:    getHistory,
+}
+
+var snapstateHistory = snapstate.History
+
+func getHistory(c *Command, r *http.Request, user *auth.UserState) Response {
+	snapName := r.URL.Query().Get("snap")
+
+	entries, err := snapstateHistory(snapName)
+	if err != nil {
+		return InternalError("cannot get snap history: %v", err)
+	}
+	if entries == nil {
+		entries = []*snapstate.HistoryEntry{}
+	}
+	return SyncResponse(entries, nil)
+}
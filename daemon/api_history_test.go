@@ -0,0 +1,102 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/snap"
+)
+
+var _ = check.Suite(&historySuite{})
+
+type historySuite struct{}
+
+func (s *historySuite) TestHistory(c *check.C) {
+	entries := []*snapstate.HistoryEntry{{
+		Snap:         "foo",
+		Action:       "refresh",
+		FromRevision: snap.R(1),
+		ToRevision:   snap.R(2),
+		Channel:      "stable",
+		Trigger:      "auto",
+		Time:         time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Outcome:      "done",
+		ChangeID:     "42",
+	}}
+	var snapName string
+	restore := daemon.MockSnapstateHistory(func(name string) ([]*snapstate.HistoryEntry, error) {
+		snapName = name
+		return entries, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/history?snap=foo", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := daemon.HistoryCmd.GET(daemon.HistoryCmd, req, nil)
+	c.Check(rsp, check.DeepEquals, &daemon.Resp{
+		Status: 200,
+		Type:   "sync",
+		Result: entries,
+	})
+	c.Check(snapName, check.Equals, "foo")
+}
+
+func (s *historySuite) TestHistoryEmpty(c *check.C) {
+	restore := daemon.MockSnapstateHistory(func(name string) ([]*snapstate.HistoryEntry, error) {
+		c.Check(name, check.Equals, "")
+		return nil, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/history", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := daemon.HistoryCmd.GET(daemon.HistoryCmd, req, nil)
+	c.Check(rsp, check.DeepEquals, &daemon.Resp{
+		Status: 200,
+		Type:   "sync",
+		Result: []*snapstate.HistoryEntry{},
+	})
+}
+
+func (s *historySuite) TestHistoryError(c *check.C) {
+	restore := daemon.MockSnapstateHistory(func(name string) ([]*snapstate.HistoryEntry, error) {
+		return nil, errors.New("boom")
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/history", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := daemon.HistoryCmd.GET(daemon.HistoryCmd, req, nil)
+	c.Check(rsp, check.DeepEquals, &daemon.Resp{
+		Status: 500,
+		Type:   "error",
+		Result: &daemon.ErrorResult{Message: "cannot get snap history: boom"},
+	})
+}
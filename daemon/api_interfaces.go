@@ -25,8 +25,10 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/state"
@@ -68,10 +70,19 @@ func getInterfaces(c *Command, r *http.Request, user *auth.UserState) Response {
 	opts := &interfaces.InfoOptions{
 		Names:     names,
 		Doc:       q.Get("doc") == "true",
+		Attrs:     q.Get("attrs") == "true",
 		Plugs:     q.Get("plugs") == "true",
 		Slots:     q.Get("slots") == "true",
 		Connected: pselect == "connected",
 	}
+	var hotplugDevices map[string]*hotplug.HotplugDeviceInfo
+	if q.Get("hotplug") == "true" {
+		var err error
+		hotplugDevices, err = c.d.overlord.InterfaceManager().HotplugDevices()
+		if err != nil {
+			return InternalError("cannot obtain hotplug devices: %v", err)
+		}
+	}
 	// Query the interface repository (this returns []*interface.Info).
 	infos := c.d.overlord.InterfaceManager().Repository().Info(opts)
 	infoJSONs := make([]*interfaceJSON, 0, len(infos))
@@ -89,19 +100,28 @@ func getInterfaces(c *Command, r *http.Request, user *auth.UserState) Response {
 		}
 		slots := make([]*slotJSON, 0, len(info.Slots))
 		for _, slot := range info.Slots {
-			slots = append(slots, &slotJSON{
+			sj := &slotJSON{
 				Snap:  slot.Snap.InstanceName(),
 				Name:  slot.Name,
 				Attrs: slot.Attrs,
 				Label: slot.Label,
-			})
+			}
+			if hotplugDevices != nil && slot.HotplugKey != "" {
+				sj.Hotplug = &hotplugJSON{Key: slot.HotplugKey}
+				if devinfo, ok := hotplugDevices[slot.Name]; ok {
+					sj.Hotplug.Device = devinfo.Data
+				}
+			}
+			slots = append(slots, sj)
 		}
 		infoJSONs = append(infoJSONs, &interfaceJSON{
-			Name:    info.Name,
-			Summary: info.Summary,
-			DocURL:  info.DocURL,
-			Plugs:   plugs,
-			Slots:   slots,
+			Name:      info.Name,
+			Summary:   info.Summary,
+			DocURL:    info.DocURL,
+			PlugAttrs: info.PlugAttrs,
+			SlotAttrs: info.SlotAttrs,
+			Plugs:     plugs,
+			Slots:     slots,
 		})
 	}
 	return SyncResponse(infoJSONs, nil)
@@ -139,6 +159,20 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 	if len(a.Plugs) == 0 || len(a.Slots) == 0 {
 		return BadRequest("at least one plug and slot is required")
 	}
+	if a.DryRun && a.Action != "connect" {
+		return BadRequest("dry-run is only supported when connecting")
+	}
+	var duration time.Duration
+	if a.For != "" {
+		if a.Action != "connect" {
+			return BadRequest("a connection duration is only supported when connecting")
+		}
+		var err error
+		duration, err = time.ParseDuration(a.For)
+		if err != nil || duration <= 0 {
+			return BadRequest("invalid connection duration %q", a.For)
+		}
+	}
 
 	var summary string
 	var err error
@@ -162,11 +196,19 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 		var connRef *interfaces.ConnRef
 		repo := c.d.overlord.InterfaceManager().Repository()
 		connRef, err = repo.ResolveConnect(a.Plugs[0].Snap, a.Plugs[0].Name, a.Slots[0].Snap, a.Slots[0].Name)
+		if err == nil && a.DryRun {
+			return connectDryRun(c.d.overlord.InterfaceManager(), connRef)
+		}
 		if err == nil {
 			var ts *state.TaskSet
 			affected = snapNamesFromConns([]*interfaces.ConnRef{connRef})
 			summary = fmt.Sprintf("Connect %s:%s to %s:%s", connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
-			ts, err = ifacestate.Connect(st, connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
+			if duration > 0 {
+				expiry := time.Now().Add(duration)
+				ts, err = ifacestate.ConnectUntil(st, connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name, expiry)
+			} else {
+				ts, err = ifacestate.Connect(st, connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
+			}
 			if _, ok := err.(*ifacestate.ErrAlreadyConnected); ok {
 				change := newChange(st, a.Action+"-snap", summary, nil, affected)
 				change.SetStatus(state.DoneStatus)
@@ -217,6 +259,22 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 	return AsyncResponse(nil, &Meta{Change: change.ID()})
 }
 
+// connectDryRun returns how the security profiles of the affected snaps
+// would change if the plug and slot of the reference were connected.
+func connectDryRun(ifaceMgr *ifacestate.InterfaceManager, connRef *interfaces.ConnRef) Response {
+	changes, err := ifaceMgr.ConnectDryRun(connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
+	if _, ok := err.(*ifacestate.ErrAlreadyConnected); ok {
+		changes, err = nil, nil
+	}
+	if err != nil {
+		return errToResponse(err, nil, BadRequest, "%v")
+	}
+	if changes == nil {
+		changes = []*ifacestate.ProfileChange{}
+	}
+	return SyncResponse(changes, nil)
+}
+
 func snapNamesFromConns(conns []*interfaces.ConnRef) []string {
 	m := make(map[string]bool)
 	for _, conn := range conns {
@@ -26,6 +26,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -36,6 +37,8 @@ import (
 	"github.com/snapcore/snapd/interfaces/ifacetest"
 	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
 )
 
 var _ = check.Suite(&interfacesSuite{})
@@ -171,6 +174,128 @@ func (s *interfacesSuite) TestConnectPlugSuccess(c *check.C) {
 	}})
 }
 
+func (s *interfacesSuite) TestConnectPlugDryRun(c *check.C) {
+	d := s.daemon(c)
+	mockProfilesBackend(c, d)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	action := &client.InterfaceAction{
+		Action: "connect",
+		DryRun: true,
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rsp := s.req(c, req, nil).(*daemon.Resp)
+	c.Assert(rsp.Type, check.Equals, daemon.ResponseTypeSync)
+	c.Check(rsp.Result, check.DeepEquals, []*ifacestate.ProfileChange{
+		{Snap: "consumer", Backend: "profiles", Path: "/profiles/snap.consumer.app", Old: "\n", New: "connected-plug\n"},
+	})
+
+	// nothing was connected
+	repo := d.Overlord().InterfaceManager().Repository()
+	c.Check(repo.Interfaces().Connections, check.HasLen, 0)
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Changes(), check.HasLen, 0)
+}
+
+func (s *interfacesSuite) TestDisconnectDryRunUnsupported(c *check.C) {
+	s.daemon(c)
+
+	action := &client.InterfaceAction{
+		Action: "disconnect",
+		DryRun: true,
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rsp := s.req(c, req, nil).(*daemon.Resp)
+	c.Check(rsp.Status, check.Equals, 400)
+	c.Check(rsp.ErrorResult().Message, check.Equals, "dry-run is only supported when connecting")
+}
+
+func (s *interfacesSuite) TestConnectPlugFor(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	d.Overlord().Loop()
+	defer d.Overlord().Stop()
+
+	action := &client.InterfaceAction{
+		Action: "connect",
+		For:    "2h",
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	before := time.Now()
+	rsp := s.req(c, req, nil).(*daemon.Resp)
+	after := time.Now()
+	c.Assert(rsp.Type, check.Equals, daemon.ResponseTypeAsync)
+
+	st := d.Overlord().State()
+	st.Lock()
+	chg := st.Change(rsp.Change)
+	st.Unlock()
+	c.Assert(chg, check.NotNil)
+
+	<-chg.Ready()
+
+	st.Lock()
+	defer st.Unlock()
+	c.Assert(chg.Err(), check.IsNil)
+	connStates, err := ifacestate.ConnectionStates(st)
+	c.Assert(err, check.IsNil)
+	expiry := connStates["consumer:plug producer:slot"].Expiry
+	c.Check(expiry.Before(before.Add(2*time.Hour)), check.Equals, false)
+	c.Check(expiry.After(after.Add(2*time.Hour)), check.Equals, false)
+}
+
+func (s *interfacesSuite) TestConnectForErrors(c *check.C) {
+	s.daemon(c)
+
+	for _, t := range []struct {
+		action string
+		dur    string
+		err    string
+	}{
+		{"connect", "forever", `invalid connection duration "forever"`},
+		{"connect", "-1h", `invalid connection duration "-1h"`},
+		{"disconnect", "1h", "a connection duration is only supported when connecting"},
+	} {
+		action := &client.InterfaceAction{
+			Action: t.action,
+			For:    t.dur,
+			Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}},
+			Slots:  []client.Slot{{Snap: "producer", Name: "slot"}},
+		}
+		text, err := json.Marshal(action)
+		c.Assert(err, check.IsNil)
+		req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+		c.Assert(err, check.IsNil)
+		rsp := s.req(c, req, nil).(*daemon.Resp)
+		c.Check(rsp.Status, check.Equals, 400)
+		c.Check(rsp.ErrorResult().Message, check.Equals, t.err)
+	}
+}
+
 func (s *interfacesSuite) TestConnectPlugFailureInterfaceMismatch(c *check.C) {
 	d := s.daemon(c)
 
@@ -1053,3 +1178,142 @@ func (s *interfacesSuite) TestInterfacesModern(c *check.C) {
 		"type":        "sync",
 	})
 }
+
+func (s *interfacesSuite) TestInterfacesHotplug(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	coreInfo := s.mockSnap(c, `name: core
+version: 1
+type: os
+`)
+	s.mockSnap(c, producerYaml)
+
+	repo := d.Overlord().InterfaceManager().Repository()
+	c.Assert(repo.AddSlot(&snap.SlotInfo{
+		Snap:       coreInfo,
+		Name:       "serial-ft232r-a50285bi",
+		Interface:  "test",
+		HotplugKey: "1234",
+	}), check.IsNil)
+
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("hotplug-slots", map[string]interface{}{
+		"serial-ft232r-a50285bi": map[string]interface{}{
+			"name":        "serial-ft232r-a50285bi",
+			"interface":   "test",
+			"hotplug-key": "1234",
+			"device-info": map[string]interface{}{
+				"data": map[string]string{"DEVPATH": "/sys/foo", "DEVNAME": "/dev/ttyUSB0", "SUBSYSTEM": "tty"},
+			},
+		}})
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/interfaces?select=all&slots=true&names=test&hotplug=true", nil)
+	c.Assert(err, check.IsNil)
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+	var body map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &body)
+	c.Check(err, check.IsNil)
+	c.Check(body["result"], check.DeepEquals, []interface{}{
+		map[string]interface{}{
+			"name": "test",
+			"slots": []interface{}{
+				map[string]interface{}{
+					"snap": "core",
+					"slot": "serial-ft232r-a50285bi",
+					"hotplug": map[string]interface{}{
+						"key": "1234",
+						"device": map[string]interface{}{
+							"DEVPATH":   "/sys/foo",
+							"DEVNAME":   "/dev/ttyUSB0",
+							"SUBSYSTEM": "tty",
+						},
+					},
+				},
+				map[string]interface{}{
+					"snap":  "producer",
+					"slot":  "slot",
+					"label": "label",
+					"attrs": map[string]interface{}{
+						"key": "value",
+					},
+				},
+			},
+		},
+	})
+
+	// without the hotplug query parameter no device information is sent
+	req, err = http.NewRequest("GET", "/v2/interfaces?select=all&slots=true&names=test", nil)
+	c.Assert(err, check.IsNil)
+	rec = httptest.NewRecorder()
+	s.req(c, req, nil).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+	c.Check(rec.Body.String(), check.Not(testutil.Contains), "hotplug")
+}
+
+func (s *interfacesSuite) TestInterfacesAttrs(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{
+		InterfaceName: "test",
+		InterfaceStaticInfo: interfaces.StaticInfo{
+			Summary: "test summary",
+			PlugAttrs: interfaces.AttrSchema{
+				"target": {Type: interfaces.AttrString, Required: true, Path: interfaces.AttrPathClean},
+			},
+			SlotAttrs: interfaces.AttrSchema{
+				"source": {Type: interfaces.AttrMap, Attrs: interfaces.AttrSchema{
+					"mode": {Type: interfaces.AttrString, Enum: []string{"ro", "rw"}},
+				}},
+			},
+		},
+	})
+	defer restore()
+
+	s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/interfaces?select=all&names=test&attrs=true", nil)
+	c.Assert(err, check.IsNil)
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+	var body map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &body)
+	c.Check(err, check.IsNil)
+	c.Check(body["result"], check.DeepEquals, []interface{}{
+		map[string]interface{}{
+			"name":    "test",
+			"summary": "test summary",
+			"plug-attrs": map[string]interface{}{
+				"target": map[string]interface{}{
+					"type":     "string",
+					"required": true,
+					"path":     "clean",
+				},
+			},
+			"slot-attrs": map[string]interface{}{
+				"source": map[string]interface{}{
+					"type": "map",
+					"attrs": map[string]interface{}{
+						"mode": map[string]interface{}{
+							"type": "string",
+							"enum": []interface{}{"ro", "rw"},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	// without the attrs query parameter the schema is not sent
+	req, err = http.NewRequest("GET", "/v2/interfaces?select=all&names=test", nil)
+	c.Assert(err, check.IsNil)
+	rec = httptest.NewRecorder()
+	s.req(c, req, nil).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+	c.Check(rec.Body.String(), check.Not(testutil.Contains), "plug-attrs")
+}
@@ -20,7 +20,10 @@
 package daemon
 
 import (
+	"time"
+
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/snap"
 )
 
 // plugJSON aids in marshaling snap.PlugInfo into JSON.
@@ -45,21 +48,35 @@ type slotJSON struct {
 	Label     string                 `json:"label,omitempty"`
 	// Connections are synthesized, they are not on the original type.
 	Connections []interfaces.PlugRef `json:"connections,omitempty"`
+	// Hotplug is only set for hotplug slots, when requested.
+	Hotplug *hotplugJSON `json:"hotplug,omitempty"`
+}
+
+// hotplugJSON describes the device backing a hotplug slot.
+type hotplugJSON struct {
+	Key snap.HotplugKey `json:"key"`
+	// Device holds the udev properties of the device, as last seen by
+	// the udev monitor.
+	Device map[string]string `json:"device,omitempty"`
 }
 
 // interfaceJSON aids in marshaling interfaces.Info into JSON.
 type interfaceJSON struct {
-	Name    string      `json:"name,omitempty"`
-	Summary string      `json:"summary,omitempty"`
-	DocURL  string      `json:"doc-url,omitempty"`
-	Plugs   []*plugJSON `json:"plugs,omitempty"`
-	Slots   []*slotJSON `json:"slots,omitempty"`
+	Name      string                `json:"name,omitempty"`
+	Summary   string                `json:"summary,omitempty"`
+	DocURL    string                `json:"doc-url,omitempty"`
+	PlugAttrs interfaces.AttrSchema `json:"plug-attrs,omitempty"`
+	SlotAttrs interfaces.AttrSchema `json:"slot-attrs,omitempty"`
+	Plugs     []*plugJSON           `json:"plugs,omitempty"`
+	Slots     []*slotJSON           `json:"slots,omitempty"`
 }
 
 // interfaceAction is an action performed on the interface system.
 type interfaceAction struct {
 	Action string     `json:"action"`
 	Forget bool       `json:"forget,omitempty"`
+	DryRun bool       `json:"dry-run,omitempty"`
+	For    string     `json:"for,omitempty"`
 	Plugs  []plugJSON `json:"plugs,omitempty"`
 	Slots  []slotJSON `json:"slots,omitempty"`
 }
@@ -74,6 +91,8 @@ type connectionJSON struct {
 	Gadget    bool                   `json:"gadget,omitempty"`
 	SlotAttrs map[string]interface{} `json:"slot-attrs,omitempty"`
 	PlugAttrs map[string]interface{} `json:"plug-attrs,omitempty"`
+	// Expiry is set for time-limited connections.
+	Expiry *time.Time `json:"expiry,omitempty"`
 }
 
 // legacyConnectionsJSON aids in marshaling legacy connections into JSON.
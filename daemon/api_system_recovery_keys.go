@@ -20,18 +20,23 @@
 package daemon
 
 import (
+	"encoding/json"
 	"net/http"
 	"path/filepath"
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/secboot"
 )
 
 var systemRecoveryKeysCmd = &Command{
 	Path:     "/v2/system-recovery-keys",
 	GET:      getSystemRecoveryKeys,
+	POST:     postSystemRecoveryKeys,
 	RootOnly: true,
 }
 
@@ -52,3 +57,65 @@ func getSystemRecoveryKeys(c *Command, r *http.Request, user *auth.UserState) Re
 
 	return SyncResponse(&rsp, nil)
 }
+
+type recoveryKeysAction struct {
+	Action     string `json:"action"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+var (
+	devicestateRotateRecoveryKeys = devicestate.RotateRecoveryKeys
+	devicestateAddPassphrase      = devicestate.AddPassphrase
+	devicestateRemovePassphrase   = devicestate.RemovePassphrase
+)
+
+func postSystemRecoveryKeys(c *Command, r *http.Request, user *auth.UserState) Response {
+	var action recoveryKeysAction
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&action); err != nil {
+		return BadRequest("cannot decode request body into a recovery keys action: %v", err)
+	}
+	if decoder.More() {
+		return BadRequest("extra content found in request body")
+	}
+
+	switch action.Action {
+	case "rotate":
+		if action.Passphrase != "" {
+			return BadRequest("passphrase cannot be used with the %q action", action.Action)
+		}
+	case "add-passphrase", "remove-passphrase":
+		if action.Passphrase == "" {
+			return BadRequest("passphrase is required for the %q action", action.Action)
+		}
+	case "":
+		return BadRequest("recovery keys action is missing")
+	default:
+		return BadRequest("unsupported recovery keys action %q", action.Action)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	// the keyslots are changed by a change as cryptsetup is slow by design
+	var chg *state.Change
+	var err error
+	switch action.Action {
+	case "rotate":
+		chg, err = devicestateRotateRecoveryKeys(st)
+	case "add-passphrase":
+		chg, err = devicestateAddPassphrase(st, action.Passphrase)
+	case "remove-passphrase":
+		chg, err = devicestateRemovePassphrase(st, action.Passphrase)
+	}
+	if err != nil {
+		if cce, ok := err.(*snapstate.ChangeConflictError); ok {
+			return SnapChangeConflict(cce)
+		}
+		return BadRequest(err.Error())
+	}
+	ensureStateSoon(st)
+
+	return AsyncResponse(nil, &Meta{Change: chg.ID()})
+}
@@ -21,18 +21,25 @@ package daemon_test
 
 import (
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/testutil"
 )
 
 var _ = Suite(&recoveryKeysSuite{})
@@ -90,3 +97,119 @@ func (s *recoveryKeysSuite) TestSystemGetRecoveryAsUserErrors(c *C) {
 	s.serveHTTP(c, rec, req)
 	c.Assert(rec.Code, Equals, 401)
 }
+
+func (s *recoveryKeysSuite) mockRecoveryKeysActions(c *C, err error) *[]string {
+	var calls []string
+	newChange := func(st *state.State, kind string) (*state.Change, error) {
+		if err != nil {
+			return nil, err
+		}
+		return st.NewChange(kind, "..."), nil
+	}
+	restore := daemon.MockDevicestateRecoveryKeys(func(st *state.State) (*state.Change, error) {
+		calls = append(calls, "rotate")
+		return newChange(st, "rotate-recovery-keys")
+	}, func(st *state.State, passphrase string) (*state.Change, error) {
+		calls = append(calls, "add "+passphrase)
+		return newChange(st, "add-passphrase")
+	}, func(st *state.State, passphrase string) (*state.Change, error) {
+		calls = append(calls, "remove "+passphrase)
+		return newChange(st, "remove-passphrase")
+	})
+	s.AddCleanup(restore)
+	return &calls
+}
+
+func (s *recoveryKeysSuite) postRecoveryKeys(c *C, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("POST", "/v2/system-recovery-keys", strings.NewReader(body))
+	c.Assert(err, IsNil)
+	req.RemoteAddr = "pid=100;uid=0;socket=;"
+	rec := httptest.NewRecorder()
+	s.serveHTTP(c, rec, req)
+	return rec
+}
+
+func (s *recoveryKeysSuite) TestSystemPostRecoveryKeysHappy(c *C) {
+	d := s.daemon(c)
+	st := d.Overlord().State()
+	calls := s.mockRecoveryKeysActions(c, nil)
+
+	soon := 0
+	_, restore := daemon.MockEnsureStateSoon(func(st *state.State) {
+		soon++
+	})
+	defer restore()
+
+	for _, tc := range []struct {
+		body, kind string
+	}{
+		{`{"action":"rotate"}`, "rotate-recovery-keys"},
+		{`{"action":"add-passphrase","passphrase":"open sesame"}`, "add-passphrase"},
+		{`{"action":"remove-passphrase","passphrase":"open sesame"}`, "remove-passphrase"},
+	} {
+		rec := s.postRecoveryKeys(c, tc.body)
+		c.Check(rec.Code, Equals, 202, Commentf("body %q", tc.body))
+
+		var rspBody map[string]interface{}
+		c.Assert(json.Unmarshal(rec.Body.Bytes(), &rspBody), IsNil)
+		st.Lock()
+		chg := st.Change(rspBody["change"].(string))
+		st.Unlock()
+		c.Assert(chg, NotNil)
+		c.Check(chg.Kind(), Equals, tc.kind)
+	}
+	c.Check(*calls, DeepEquals, []string{"rotate", "add open sesame", "remove open sesame"})
+	c.Check(soon, Equals, 3)
+}
+
+func (s *recoveryKeysSuite) TestSystemPostRecoveryKeysBadRequest(c *C) {
+	s.daemon(c)
+	calls := s.mockRecoveryKeysActions(c, nil)
+
+	for _, tc := range []struct {
+		body, err string
+	}{
+		{`{"action":"rotate"`, "cannot decode request body into a recovery keys action: unexpected EOF"},
+		{`{"action":"rotate"}{}`, "extra content found in request body"},
+		{`{}`, "recovery keys action is missing"},
+		{`{"action":"foo"}`, `unsupported recovery keys action "foo"`},
+		{`{"action":"rotate","passphrase":"x"}`, `passphrase cannot be used with the "rotate" action`},
+		{`{"action":"add-passphrase"}`, `passphrase is required for the "add-passphrase" action`},
+		{`{"action":"remove-passphrase"}`, `passphrase is required for the "remove-passphrase" action`},
+	} {
+		rec := s.postRecoveryKeys(c, tc.body)
+		c.Check(rec.Code, Equals, 400, Commentf("body %q", tc.body))
+		c.Check(rec.Body.String(), testutil.Contains, fmt.Sprintf(`"message":%q`, tc.err))
+	}
+	c.Check(*calls, HasLen, 0)
+}
+
+func (s *recoveryKeysSuite) TestSystemPostRecoveryKeysErrors(c *C) {
+	s.daemon(c)
+	s.mockRecoveryKeysActions(c, devicestate.ErrNoEncryptedVolumes)
+
+	rec := s.postRecoveryKeys(c, `{"action":"rotate"}`)
+	c.Check(rec.Code, Equals, 400)
+	c.Check(rec.Body.String(), testutil.Contains, `"message":"no encrypted volumes with recovery keys"`)
+
+	s.mockRecoveryKeysActions(c, &snapstate.ChangeConflictError{
+		ChangeKind: "rotate-recovery-keys",
+		Message:    "cannot manage encryption keys, another change is managing them",
+	})
+	rec = s.postRecoveryKeys(c, `{"action":"add-passphrase","passphrase":"open sesame"}`)
+	c.Check(rec.Code, Equals, 409)
+	c.Check(rec.Body.String(), testutil.Contains, `"message":"cannot manage encryption keys, another change is managing them"`)
+}
+
+func (s *recoveryKeysSuite) TestSystemPostRecoveryKeysAsUserErrors(c *C) {
+	s.daemon(c)
+	calls := s.mockRecoveryKeysActions(c, nil)
+
+	req, err := http.NewRequest("POST", "/v2/system-recovery-keys", strings.NewReader(`{"action":"rotate"}`))
+	c.Assert(err, IsNil)
+	req.RemoteAddr = "pid=100;uid=1000;socket=;"
+	rec := httptest.NewRecorder()
+	s.serveHTTP(c, rec, req)
+	c.Check(rec.Code, Equals, 401)
+	c.Check(*calls, HasLen, 0)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/snap"
 )
 
@@ -101,6 +102,13 @@ func getSystems(c *Command, r *http.Request, user *auth.UserState) Response {
 type systemActionRequest struct {
 	Action string `json:"action"`
 	client.SystemAction
+
+	// Label is the label of the recovery system to create
+	Label string `json:"label,omitempty"`
+
+	// DiscardIdentity requests a factory reset to not preserve the
+	// device identity
+	DiscardIdentity bool `json:"discard-identity,omitempty"`
 }
 
 func postSystemsAction(c *Command, r *http.Request, user *auth.UserState) Response {
@@ -119,6 +127,8 @@ func postSystemsAction(c *Command, r *http.Request, user *auth.UserState) Respon
 		return postSystemActionDo(c, systemLabel, &req)
 	case "reboot":
 		return postSystemActionReboot(c, systemLabel, &req)
+	case "create":
+		return postSystemActionCreate(c, systemLabel, &req)
 	default:
 		return BadRequest("unsupported action %q", req.Action)
 	}
@@ -137,12 +147,35 @@ func handleSystemActionErr(err error, systemLabel string) Response {
 	return InternalError(err.Error())
 }
 
+var (
+	devicestateCreateRecoverySystem             = devicestate.CreateRecoverySystem
+	devicestateSetFactoryResetPreservesIdentity = devicestate.SetFactoryResetPreservesIdentity
+)
+
+// prepareFactoryReset records whether the device identity is to be
+// preserved when the requested mode is factory-reset.
+func prepareFactoryReset(req *systemActionRequest) Response {
+	if req.Mode != "factory-reset" {
+		if req.DiscardIdentity {
+			return BadRequest("discarding the device identity is only supported in factory-reset mode")
+		}
+		return nil
+	}
+	if err := devicestateSetFactoryResetPreservesIdentity(!req.DiscardIdentity); err != nil {
+		return InternalError("cannot prepare factory reset: %v", err)
+	}
+	return nil
+}
+
 // wrapped for unit tests
 var deviceManagerReboot = func(dm *devicestate.DeviceManager, systemLabel, mode string) error {
 	return dm.Reboot(systemLabel, mode)
 }
 
 func postSystemActionReboot(c *Command, systemLabel string, req *systemActionRequest) Response {
+	if rsp := prepareFactoryReset(req); rsp != nil {
+		return rsp
+	}
 	dm := c.d.overlord.DeviceManager()
 	if err := deviceManagerReboot(dm, systemLabel, req.Mode); err != nil {
 		return handleSystemActionErr(err, systemLabel)
@@ -158,6 +191,10 @@ func postSystemActionDo(c *Command, systemLabel string, req *systemActionRequest
 		return BadRequest("system action requires the mode to be provided")
 	}
 
+	if rsp := prepareFactoryReset(req); rsp != nil {
+		return rsp
+	}
+
 	sa := devicestate.SystemAction{
 		Title: req.Title,
 		Mode:  req.Mode,
@@ -167,3 +204,31 @@ func postSystemActionDo(c *Command, systemLabel string, req *systemActionRequest
 	}
 	return SyncResponse(nil, nil)
 }
+
+func postSystemActionCreate(c *Command, systemLabel string, req *systemActionRequest) Response {
+	label := req.Label
+	if label == "" {
+		label = systemLabel
+	}
+	if label == "" {
+		return BadRequest("system action requires the label of the system to create")
+	}
+	if systemLabel != "" && systemLabel != label {
+		return BadRequest("system label %q does not match the label of the system to create %q", systemLabel, label)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	chg, err := devicestateCreateRecoverySystem(st, label)
+	if err != nil {
+		if cce, ok := err.(*snapstate.ChangeConflictError); ok {
+			return SnapChangeConflict(cce)
+		}
+		return BadRequest("cannot create recovery system %q: %v", label, err)
+	}
+	ensureStateSoon(st)
+
+	return AsyncResponse(nil, &Meta{Change: chg.ID()})
+}
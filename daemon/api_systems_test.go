@@ -42,6 +42,7 @@ import (
 	"github.com/snapcore/snapd/overlord/assertstate/assertstatetest"
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/seed"
 	"github.com/snapcore/snapd/seed/seedtest"
@@ -183,6 +184,7 @@ func (s *systemsSuite) TestSystemsGetSome(c *check.C) {
 				Actions: []client.SystemAction{
 					{Title: "Reinstall", Mode: "install"},
 					{Title: "Recover", Mode: "recover"},
+					{Title: "Factory reset", Mode: "factory-reset"},
 					{Title: "Run normally", Mode: "run"},
 				},
 			},
@@ -638,6 +640,80 @@ func (s *systemsSuite) TestSystemRebootHappy(c *check.C) {
 	}
 }
 
+func (s *systemsSuite) TestSystemRebootFactoryReset(c *check.C) {
+	s.daemon(c)
+
+	for _, tc := range []struct {
+		body     string
+		preserve bool
+	}{
+		{`{"action":"reboot","mode":"factory-reset"}`, true},
+		{`{"action":"reboot","mode":"factory-reset","discard-identity":false}`, true},
+		{`{"action":"reboot","mode":"factory-reset","discard-identity":true}`, false},
+	} {
+		var calls []string
+		restore := daemon.MockDevicestateSetFactoryResetPreservesIdentity(func(preserve bool) error {
+			calls = append(calls, fmt.Sprintf("preserve:%v", preserve))
+			return nil
+		})
+		defer restore()
+		restore = daemon.MockDeviceManagerReboot(func(dm *devicestate.DeviceManager, systemLabel, mode string) error {
+			calls = append(calls, "reboot:"+mode)
+			return nil
+		})
+		defer restore()
+
+		req, err := http.NewRequest("POST", "/v2/systems/20200101", strings.NewReader(tc.body))
+		c.Assert(err, check.IsNil)
+		req.RemoteAddr = "pid=100;uid=0;socket=;"
+
+		rec := httptest.NewRecorder()
+		s.serveHTTP(c, rec, req)
+		c.Check(rec.Code, check.Equals, 200)
+		// the choice is recorded before rebooting
+		c.Check(calls, check.DeepEquals, []string{fmt.Sprintf("preserve:%v", tc.preserve), "reboot:factory-reset"})
+	}
+}
+
+func (s *systemsSuite) TestSystemRebootFactoryResetErrors(c *check.C) {
+	s.daemon(c)
+
+	for _, tc := range []struct {
+		body             string
+		preserveErr      error
+		expectedHttpCode int
+		expectedErr      string
+	}{
+		{`{"action":"reboot","mode":"recover","discard-identity":true}`, nil, 400, "discarding the device identity is only supported in factory-reset mode"},
+		{`{"action":"do","mode":"install","discard-identity":true}`, nil, 400, "discarding the device identity is only supported in factory-reset mode"},
+		{`{"action":"reboot","mode":"factory-reset"}`, fmt.Errorf("boom"), 500, "cannot prepare factory reset: boom"},
+	} {
+		restore := daemon.MockDevicestateSetFactoryResetPreservesIdentity(func(preserve bool) error {
+			return tc.preserveErr
+		})
+		defer restore()
+		restore = daemon.MockDeviceManagerReboot(func(dm *devicestate.DeviceManager, systemLabel, mode string) error {
+			c.Errorf("unexpected reboot")
+			return nil
+		})
+		defer restore()
+
+		req, err := http.NewRequest("POST", "/v2/systems/20200101", strings.NewReader(tc.body))
+		c.Assert(err, check.IsNil)
+		req.RemoteAddr = "pid=100;uid=0;socket=;"
+
+		rec := httptest.NewRecorder()
+		s.serveHTTP(c, rec, req)
+		c.Check(rec.Code, check.Equals, tc.expectedHttpCode)
+
+		var rspBody map[string]interface{}
+		err = json.Unmarshal(rec.Body.Bytes(), &rspBody)
+		c.Check(err, check.IsNil)
+		result := rspBody["result"].(map[string]interface{})
+		c.Check(result["message"], check.Equals, tc.expectedErr)
+	}
+}
+
 func (s *systemsSuite) TestSystemRebootUnhappy(c *check.C) {
 	s.daemon(c)
 
@@ -676,3 +752,104 @@ func (s *systemsSuite) TestSystemRebootUnhappy(c *check.C) {
 		c.Check(result["message"], check.Equals, tc.expectedErr)
 	}
 }
+
+func (s *systemsSuite) TestSystemCreateHappy(c *check.C) {
+	d := s.daemon(c)
+	st := d.Overlord().State()
+
+	soon := 0
+	_, restore := daemon.MockEnsureStateSoon(func(st *state.State) {
+		soon++
+	})
+	defer restore()
+
+	for _, tc := range []struct {
+		url, body string
+	}{
+		{"/v2/systems", `{"action":"create","label":"20261018"}`},
+		{"/v2/systems/20261018", `{"action":"create"}`},
+		{"/v2/systems/20261018", `{"action":"create","label":"20261018"}`},
+	} {
+		var gotLabel string
+		restore := daemon.MockDevicestateCreateRecoverySystem(func(st *state.State, label string) (*state.Change, error) {
+			gotLabel = label
+			return st.NewChange("create-recovery-system", "..."), nil
+		})
+		defer restore()
+
+		req, err := http.NewRequest("POST", tc.url, strings.NewReader(tc.body))
+		c.Assert(err, check.IsNil)
+		req.RemoteAddr = "pid=100;uid=0;socket=;"
+
+		rec := httptest.NewRecorder()
+		s.serveHTTP(c, rec, req)
+		c.Check(rec.Code, check.Equals, 202, check.Commentf("%s", rec.Body.String()))
+		c.Check(gotLabel, check.Equals, "20261018")
+
+		var rspBody map[string]interface{}
+		err = json.Unmarshal(rec.Body.Bytes(), &rspBody)
+		c.Assert(err, check.IsNil)
+		st.Lock()
+		chg := st.Change(rspBody["change"].(string))
+		st.Unlock()
+		c.Assert(chg, check.NotNil)
+		c.Check(chg.Kind(), check.Equals, "create-recovery-system")
+	}
+	c.Check(soon, check.Equals, 3)
+}
+
+func (s *systemsSuite) TestSystemCreateErrors(c *check.C) {
+	s.daemon(c)
+
+	for _, tc := range []struct {
+		url, body        string
+		createErr        error
+		expectedHttpCode int
+		expectedErr      string
+	}{
+		{"/v2/systems", `{"action":"create"}`, nil, 400, "system action requires the label of the system to create"},
+		{"/v2/systems/20261018", `{"action":"create","label":"other"}`, nil, 400, `system label "20261018" does not match the label of the system to create "other"`},
+		{"/v2/systems", `{"action":"create","label":"20261018"}`, fmt.Errorf("boom"), 400, `cannot create recovery system "20261018": boom`},
+		{"/v2/systems", `{"action":"create","label":"20261018"}`, &snapstate.ChangeConflictError{Snap: "pc-kernel", ChangeKind: "refresh"}, 409, `snap "pc-kernel" has "refresh" change in progress`},
+	} {
+		restore := daemon.MockDevicestateCreateRecoverySystem(func(st *state.State, label string) (*state.Change, error) {
+			if tc.createErr == nil {
+				c.Fatalf("unexpected call")
+			}
+			return nil, tc.createErr
+		})
+		defer restore()
+
+		req, err := http.NewRequest("POST", tc.url, strings.NewReader(tc.body))
+		c.Assert(err, check.IsNil)
+		req.RemoteAddr = "pid=100;uid=0;socket=;"
+
+		rec := httptest.NewRecorder()
+		s.serveHTTP(c, rec, req)
+		c.Check(rec.Code, check.Equals, tc.expectedHttpCode)
+
+		var rspBody map[string]interface{}
+		err = json.Unmarshal(rec.Body.Bytes(), &rspBody)
+		c.Check(err, check.IsNil)
+		result := rspBody["result"].(map[string]interface{})
+		c.Check(result["message"], check.Equals, tc.expectedErr)
+	}
+}
+
+func (s *systemsSuite) TestSystemCreateNeedsRoot(c *check.C) {
+	s.daemon(c)
+
+	restore := daemon.MockDevicestateCreateRecoverySystem(func(st *state.State, label string) (*state.Change, error) {
+		c.Fatalf("creating a recovery system should not get called")
+		return nil, nil
+	})
+	defer restore()
+
+	req, err := http.NewRequest("POST", "/v2/systems", strings.NewReader(`{"action":"create","label":"20261018"}`))
+	c.Assert(err, check.IsNil)
+	req.RemoteAddr = "pid=100;uid=1000;socket=;"
+
+	rec := httptest.NewRecorder()
+	s.serveHTTP(c, rec, req)
+	c.Check(rec.Code, check.Equals, 401)
+}
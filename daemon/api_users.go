@@ -334,6 +334,7 @@ func createUser(c *Command, createData postUserCreateData) Response {
 
 	var model *asserts.Model
 	var serial *asserts.Serial
+	var systemMode string
 	createKnown := createData.Known
 	if createKnown {
 		var err error
@@ -349,12 +350,13 @@ func createUser(c *Command, createData postUserCreateData) Response {
 		if err != nil && err != state.ErrNoState {
 			return InternalError("cannot create user: cannot get serial: %v", err)
 		}
+		systemMode = c.d.overlord.DeviceManager().SystemMode()
 	}
 
 	// special case: the user requested the creation of all known
 	// system-users
 	if createData.Email == "" && createKnown {
-		return createAllKnownSystemUsers(st, model, serial, &createData)
+		return createAllKnownSystemUsers(st, model, serial, systemMode, &createData)
 	}
 	if createData.Email == "" {
 		return BadRequest("cannot create user: 'email' field is empty")
@@ -363,7 +365,7 @@ func createUser(c *Command, createData postUserCreateData) Response {
 	var username string
 	var opts *osutil.AddUserOptions
 	if createKnown {
-		username, opts, err = getUserDetailsFromAssertion(st, model, serial, createData.Email)
+		username, opts, err = getUserDetailsFromAssertion(st, model, serial, systemMode, createData.Email)
 	} else {
 		username, opts, err = getUserDetailsFromStore(getStore(c), createData.Email)
 	}
@@ -382,6 +384,11 @@ func createUser(c *Command, createData postUserCreateData) Response {
 	if err := setupLocalUser(c.d.overlord.State(), username, createData.Email); err != nil {
 		return InternalError("%s", err)
 	}
+	if systemMode == "recover" {
+		// let the device manager enable remote access to the
+		// recovery system if the model allows it
+		st.EnsureBefore(0)
+	}
 
 	result := userResponseData{
 		Username: username,
@@ -413,7 +420,7 @@ func getUserDetailsFromStore(theStore snapstate.StoreService, email string) (str
 	return v.Username, opts, nil
 }
 
-func createAllKnownSystemUsers(st *state.State, modelAs *asserts.Model, serialAs *asserts.Serial, createData *postUserCreateData) Response {
+func createAllKnownSystemUsers(st *state.State, modelAs *asserts.Model, serialAs *asserts.Serial, systemMode string, createData *postUserCreateData) Response {
 	var createdUsers []userResponseData
 	headers := map[string]string{
 		"brand-id": modelAs.BrandID(),
@@ -431,7 +438,7 @@ func createAllKnownSystemUsers(st *state.State, modelAs *asserts.Model, serialAs
 		email := as.(*asserts.SystemUser).Email()
 		// we need to use getUserDetailsFromAssertion as this verifies
 		// the assertion against the current brand/model/time
-		username, opts, err := getUserDetailsFromAssertion(st, modelAs, serialAs, email)
+		username, opts, err := getUserDetailsFromAssertion(st, modelAs, serialAs, systemMode, email)
 		if err != nil {
 			logger.Noticef("ignoring system-user assertion for %q: %s", email, err)
 			continue
@@ -456,11 +463,15 @@ func createAllKnownSystemUsers(st *state.State, modelAs *asserts.Model, serialAs
 			SSHKeys:  opts.SSHKeys,
 		})
 	}
+	if systemMode == "recover" && len(createdUsers) > 0 {
+		// see createUser
+		st.EnsureBefore(0)
+	}
 
 	return SyncResponse(createdUsers, nil)
 }
 
-func getUserDetailsFromAssertion(st *state.State, modelAs *asserts.Model, serialAs *asserts.Serial, email string) (string, *osutil.AddUserOptions, error) {
+func getUserDetailsFromAssertion(st *state.State, modelAs *asserts.Model, serialAs *asserts.Serial, systemMode, email string) (string, *osutil.AddUserOptions, error) {
 	errorPrefix := fmt.Sprintf("cannot add system-user %q: ", email)
 
 	st.Lock()
@@ -504,6 +515,12 @@ func getUserDetailsFromAssertion(st *state.State, modelAs *asserts.Model, serial
 		}
 	}
 
+	// recovery-only users are ephemeral, they exist only for the
+	// lifetime of recover mode
+	if su.RecoveryOnly() && systemMode != "recover" {
+		return "", nil, fmt.Errorf(errorPrefix+"assertion only valid in recover mode, not %q", systemMode)
+	}
+
 	if !su.ValidAt(time.Now()) {
 		return "", nil, fmt.Errorf(errorPrefix + "assertion not valid anymore")
 	}
@@ -31,6 +31,7 @@ import (
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/assertstate/assertstatetest"
@@ -502,6 +503,21 @@ var unknownUser = map[string]interface{}{
 	"until":        time.Now().Add(24 * 30 * time.Hour).Format(time.RFC3339),
 }
 
+var recoveryUser = map[string]interface{}{
+	"format":        "2",
+	"authority-id":  "my-brand",
+	"brand-id":      "my-brand",
+	"email":         "recovery@bar.com",
+	"series":        []interface{}{"16", "18"},
+	"models":        []interface{}{"my-model"},
+	"name":          "Recovery Guy",
+	"username":      "recoveryguy",
+	"password":      "$6$salt$hash",
+	"recovery-only": "true",
+	"since":         time.Now().Format(time.RFC3339),
+	"until":         time.Now().Add(24 * 30 * time.Hour).Format(time.RFC3339),
+}
+
 func (s *userSuite) TestGetUserDetailsFromAssertionHappy(c *check.C) {
 	s.makeSystemUsers(c, []map[string]interface{}{goodUser})
 
@@ -514,7 +530,7 @@ func (s *userSuite) TestGetUserDetailsFromAssertionHappy(c *check.C) {
 
 	// ensure that if we query the details from the assert DB we get
 	// the expected user
-	username, opts, err := daemon.GetUserDetailsFromAssertion(st, model, nil, "foo@bar.com")
+	username, opts, err := daemon.GetUserDetailsFromAssertion(st, model, nil, "run", "foo@bar.com")
 	c.Check(username, check.Equals, "guy")
 	c.Check(opts, check.DeepEquals, &osutil.AddUserOptions{
 		Gecos:    "foo@bar.com,Boring Guy",
@@ -523,6 +539,76 @@ func (s *userSuite) TestGetUserDetailsFromAssertionHappy(c *check.C) {
 	c.Check(err, check.IsNil)
 }
 
+func (s *userSuite) TestGetUserDetailsFromAssertionRecoveryOnly(c *check.C) {
+	s.makeSystemUsers(c, []map[string]interface{}{recoveryUser})
+
+	st := s.d.Overlord().State()
+
+	st.Lock()
+	model, err := s.d.Overlord().DeviceManager().Model()
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+
+	_, _, err = daemon.GetUserDetailsFromAssertion(st, model, nil, "run", "recovery@bar.com")
+	c.Check(err, check.ErrorMatches, `cannot add system-user "recovery@bar.com": assertion only valid in recover mode, not "run"`)
+
+	username, opts, err := daemon.GetUserDetailsFromAssertion(st, model, nil, "recover", "recovery@bar.com")
+	c.Assert(err, check.IsNil)
+	c.Check(username, check.Equals, "recoveryguy")
+	c.Check(opts, check.DeepEquals, &osutil.AddUserOptions{
+		Gecos:    "recovery@bar.com,Recovery Guy",
+		Password: "$6$salt$hash",
+	})
+}
+
+func (s *userSuite) TestPostCreateUserFromAssertionRecoveryOnlyRunMode(c *check.C) {
+	s.makeSystemUsers(c, []map[string]interface{}{recoveryUser})
+
+	buf := bytes.NewBufferString(`{"email": "recovery@bar.com","known":true}`)
+	req, err := http.NewRequest("POST", "/v2/create-user", buf)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.req(c, req, nil).(*daemon.Resp)
+	c.Check(rsp.Type, check.Equals, daemon.ResponseTypeError)
+	c.Check(rsp.Result.(*daemon.ErrorResult).Message, check.Matches, `cannot add system-user "recovery@bar.com": assertion only valid in recover mode, not "run"`)
+}
+
+func (s *userSuite) TestPostCreateUserFromAssertionAllKnownRecoverMode(c *check.C) {
+	m := boot.Modeenv{
+		Mode:           "recover",
+		RecoverySystem: "20191127",
+	}
+	c.Assert(m.WriteTo(""), check.IsNil)
+	// start again so that the system mode is picked up
+	s.resetDaemon()
+	s.daemonWithStore(c, s)
+
+	s.makeSystemUsers(c, []map[string]interface{}{goodUser, recoveryUser})
+	created := map[string]bool{}
+	defer daemon.MockOsutilAddUser(func(username string, opts *osutil.AddUserOptions) error {
+		c.Check(username == "guy" || username == "recoveryguy", check.Equals, true, check.Commentf("unexpected username %q", username))
+		created[username] = true
+		return nil
+	})()
+	defer daemon.MockUserLookup(func(username string) (*user.User, error) {
+		if created[username] {
+			return s.trivialUserLookup(username)
+		}
+		return nil, fmt.Errorf("not created yet")
+	})()
+
+	buf := bytes.NewBufferString(`{"known":true}`)
+	req, err := http.NewRequest("POST", "/v2/create-user", buf)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.req(c, req, nil).(*daemon.Resp)
+	c.Check(rsp.Type, check.Equals, daemon.ResponseTypeSync)
+	c.Check(created, check.DeepEquals, map[string]bool{
+		"guy":         true,
+		"recoveryguy": true,
+	})
+}
+
 // FIXME: These tests all look similar, with small deltas. Would be
 // nice to transform them into a table that is just the deltas, and
 // run on a loop.
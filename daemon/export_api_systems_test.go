@@ -21,6 +21,7 @@ package daemon
 
 import (
 	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/state"
 )
 
 func MockDeviceManagerReboot(f func(*devicestate.DeviceManager, string, string) error) (restore func()) {
@@ -34,3 +35,19 @@ func MockDeviceManagerReboot(f func(*devicestate.DeviceManager, string, string)
 type (
 	SystemsResponse = systemsResponse
 )
+
+func MockDevicestateCreateRecoverySystem(f func(*state.State, string) (*state.Change, error)) (restore func()) {
+	old := devicestateCreateRecoverySystem
+	devicestateCreateRecoverySystem = f
+	return func() {
+		devicestateCreateRecoverySystem = old
+	}
+}
+
+func MockDevicestateSetFactoryResetPreservesIdentity(f func(bool) error) (restore func()) {
+	old := devicestateSetFactoryResetPreservesIdentity
+	devicestateSetFactoryResetPreservesIdentity = f
+	return func() {
+		devicestateSetFactoryResetPreservesIdentity = old
+	}
+}
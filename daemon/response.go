@@ -109,6 +109,7 @@ func (r *resp) addWarningsToMeta(count int, stamp time.Time) {
 type Meta struct {
 	Sources           []string   `json:"sources,omitempty"`
 	SuggestedCurrency string     `json:"suggested-currency,omitempty"`
+	NextCursor        string     `json:"next-cursor,omitempty"`
 	Change            string     `json:"change,omitempty"`
 	WarningTimestamp  *time.Time `json:"warning-timestamp,omitempty"`
 	WarningCount      int        `json:"warning-count,omitempty"`
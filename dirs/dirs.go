@@ -46,6 +46,8 @@ var (
 	SnapConfineAppArmorDir    string
 	SnapSeccompBase           string
 	SnapSeccompDir            string
+	SnapLandlockDir           string
+	SnapNftablesDir           string
 	SnapMountPolicyDir        string
 	SnapUdevRulesDir          string
 	SnapKModModulesDir        string
@@ -123,6 +125,8 @@ var (
 
 	SnapshotsDir string
 
+	SnapHistoryDir string
+
 	ErrtrackerDbDir string
 	SysfsDir        string
 
@@ -322,6 +326,8 @@ func SetRootDir(rootdir string) {
 	SnapDownloadCacheDir = filepath.Join(rootdir, snappyDir, "cache")
 	SnapSeccompBase = filepath.Join(rootdir, snappyDir, "seccomp")
 	SnapSeccompDir = filepath.Join(SnapSeccompBase, "bpf")
+	SnapLandlockDir = filepath.Join(rootdir, snappyDir, "landlock")
+	SnapNftablesDir = filepath.Join(rootdir, snappyDir, "nftables")
 	SnapMountPolicyDir = filepath.Join(rootdir, snappyDir, "mount")
 	SnapMetaDir = filepath.Join(rootdir, snappyDir, "meta")
 	SnapdMaintenanceFile = filepath.Join(rootdir, snappyDir, "maintenance.json")
@@ -450,6 +456,8 @@ func SetRootDir(rootdir string) {
 
 	SnapshotsDir = filepath.Join(rootdir, snappyDir, "snapshots")
 
+	SnapHistoryDir = filepath.Join(rootdir, snappyDir, "history")
+
 	ErrtrackerDbDir = filepath.Join(rootdir, snappyDir, "errtracker.db")
 	SysfsDir = filepath.Join(rootdir, "/sys")
 
@@ -35,6 +35,7 @@ import (
 	"github.com/snapcore/snapd/gadget/edition"
 	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/metautil"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/naming"
 	"github.com/snapcore/snapd/strutil"
@@ -95,6 +96,54 @@ type Info struct {
 	Defaults map[string]map[string]interface{} `yaml:"defaults,omitempty"`
 
 	Connections []Connection `yaml:"connections"`
+
+	// KernelCmdline declares the kernel command line arguments that the
+	// system can be configured to append.
+	KernelCmdline KernelCmdline `yaml:"kernel-cmdline,omitempty"`
+}
+
+// KernelCmdline carries the gadget constraints on the kernel command line.
+type KernelCmdline struct {
+	// Allow lists the kernel command line arguments that may be appended
+	// through system options, either as "param", "param=value" or
+	// "param=*" which matches any value of the parameter.
+	Allow []string `yaml:"allow,omitempty"`
+}
+
+// AllowsArg returns whether the given kernel command line argument is
+// allowed by the gadget.
+func (k *KernelCmdline) AllowsArg(arg string) bool {
+	for _, allowed := range k.Allow {
+		if strings.HasSuffix(allowed, "=*") {
+			if strings.HasPrefix(arg, strings.TrimSuffix(allowed, "*")) {
+				return true
+			}
+			continue
+		}
+		if arg == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func validateKernelCmdline(k *KernelCmdline) error {
+	for _, allowed := range k.Allow {
+		args, err := osutil.KernelCommandLineSplit(allowed)
+		if err != nil {
+			return fmt.Errorf("invalid allowed kernel command line argument %q: %v", allowed, err)
+		}
+		if len(args) != 1 || args[0] != allowed {
+			return fmt.Errorf("invalid allowed kernel command line argument %q: expected a single argument", allowed)
+		}
+		if strings.HasPrefix(allowed, "snapd_") {
+			return fmt.Errorf("invalid allowed kernel command line argument %q: reserved for snapd", allowed)
+		}
+		if strings.Contains(strings.TrimSuffix(allowed, "=*"), "*") {
+			return fmt.Errorf("invalid allowed kernel command line argument %q: only a trailing \"=*\" wildcard is supported", allowed)
+		}
+	}
+	return nil
 }
 
 // Volume defines the structure and content for the image to be written into a
@@ -328,6 +377,10 @@ func InfoFromGadgetYaml(gadgetYaml []byte, model Model) (*Info, error) {
 		}
 	}
 
+	if err := validateKernelCmdline(&gi.KernelCmdline); err != nil {
+		return nil, err
+	}
+
 	if len(gi.Volumes) == 0 && classicOrUnconstrained(model) {
 		// volumes can be left out on classic
 		// can still specify defaults though
@@ -344,10 +397,10 @@ func InfoFromGadgetYaml(gadgetYaml []byte, model Model) (*Info, error) {
 		switch v.Bootloader {
 		case "":
 			// pass
-		case "grub", "u-boot", "android-boot", "lk":
+		case "grub", "u-boot", "android-boot", "lk", "systemd-boot":
 			bootloadersFound += 1
 		default:
-			return nil, errors.New("bootloader must be one of grub, u-boot, android-boot or lk")
+			return nil, errors.New("bootloader must be one of grub, u-boot, android-boot, lk or systemd-boot")
 		}
 	}
 	switch {
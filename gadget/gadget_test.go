@@ -733,7 +733,7 @@ volumes:
 	c.Assert(err, IsNil)
 
 	_, err = gadget.ReadInfo(s.dir, nil)
-	c.Assert(err, ErrorMatches, "bootloader must be one of grub, u-boot, android-boot or lk")
+	c.Assert(err, ErrorMatches, "bootloader must be one of grub, u-boot, android-boot, lk or systemd-boot")
 }
 
 func (s *gadgetYamlTestSuite) TestReadGadgetYamlEmptyBootloader(c *C) {
@@ -800,6 +800,61 @@ connections:
 	}
 }
 
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlKernelCmdline(c *C) {
+	mockGadgetYaml := []byte(`
+kernel-cmdline:
+  allow:
+    - isolcpus=*
+    - nosmt
+    - console=ttyS0,115200
+`)
+	err := ioutil.WriteFile(s.gadgetYamlPath, mockGadgetYaml, 0644)
+	c.Assert(err, IsNil)
+
+	ginfo, err := gadget.ReadInfo(s.dir, nil)
+	c.Assert(err, IsNil)
+	c.Check(ginfo.KernelCmdline.Allow, DeepEquals, []string{"isolcpus=*", "nosmt", "console=ttyS0,115200"})
+
+	for _, tc := range []struct {
+		arg     string
+		allowed bool
+	}{
+		{"isolcpus=1-3", true},
+		{"isolcpus=", true},
+		{"isolcpus", false},
+		{"nosmt", true},
+		{"nosmt=force", false},
+		{"console=ttyS0,115200", true},
+		{"console=tty1", false},
+		{"panic=-1", false},
+	} {
+		c.Check(ginfo.KernelCmdline.AllowsArg(tc.arg), Equals, tc.allowed, Commentf("%q", tc.arg))
+	}
+}
+
+func (s *gadgetYamlTestSuite) TestReadGadgetYamlKernelCmdlineInvalid(c *C) {
+	for _, tc := range []struct {
+		allow string
+		err   string
+	}{
+		{`"foo bar"`, `invalid allowed kernel command line argument "foo bar": expected a single argument`},
+		{`"foo=\"bar"`, `invalid allowed kernel command line argument "foo=\\"bar": .*`},
+		{`snapd_recovery_mode=*`, `invalid allowed kernel command line argument "snapd_recovery_mode=\*": reserved for snapd`},
+		{`foo=b*`, `invalid allowed kernel command line argument "foo=b\*": only a trailing "=\*" wildcard is supported`},
+	} {
+		mockGadgetYaml := fmt.Sprintf(`
+kernel-cmdline:
+  allow:
+    - %s
+`, tc.allow)
+		err := ioutil.WriteFile(s.gadgetYamlPath, []byte(mockGadgetYaml), 0644)
+		c.Assert(err, IsNil)
+
+		_, err = gadget.ReadInfo(s.dir, nil)
+		c.Check(err, ErrorMatches, tc.err)
+	}
+}
+
 func (s *gadgetYamlTestSuite) TestReadGadgetYamlVolumeUpdate(c *C) {
 	err := ioutil.WriteFile(s.gadgetYamlPath, mockVolumeUpdateGadgetYaml, 0644)
 	c.Assert(err, IsNil)
@@ -28,6 +28,7 @@ var (
 	EnsureLayoutCompatibility = ensureLayoutCompatibility
 	DeviceFromRole            = deviceFromRole
 	NewEncryptedDevice        = newEncryptedDevice
+	ReleaseFilesystem         = releaseFilesystem
 )
 
 func MockSecbootFormatEncryptedDevice(f func(key secboot.EncryptionKey, label, node string) error) (restore func()) {
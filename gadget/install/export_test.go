@@ -36,6 +36,7 @@ var (
 	EnsureNodesExist        = ensureNodesExist
 
 	CreatedDuringInstall = createdDuringInstall
+	InstalledPartitions  = installedPartitions
 )
 
 func MockContentMountpoint(new string) (restore func()) {
@@ -24,10 +24,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/gadget"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/secboot"
 )
 
@@ -97,11 +99,8 @@ func Run(model gadget.Model, gadgetRoot, device string, options Options, observe
 	// at this point we removed any existing partition, nuke any
 	// of the existing sealed key files placed outside of the
 	// encrypted partitions (LP: #1879338)
-	sealedKeyFiles, _ := filepath.Glob(filepath.Join(boot.InitramfsSeedEncryptionKeyDir, "*.sealed-key"))
-	for _, keyFile := range sealedKeyFiles {
-		if err := os.Remove(keyFile); err != nil && !os.IsNotExist(err) {
-			return nil, fmt.Errorf("cannot cleanup obsolete key file: %v", keyFile)
-		}
+	if err := removeObsoleteSealedKeys(); err != nil {
+		return nil, err
 	}
 
 	created, err := createMissingPartitions(diskLayout, lv)
@@ -109,24 +108,6 @@ func Run(model gadget.Model, gadgetRoot, device string, options Options, observe
 		return nil, fmt.Errorf("cannot create the partitions: %v", err)
 	}
 
-	makeKeySet := func() (*EncryptionKeySet, error) {
-		key, err := secboot.NewEncryptionKey()
-		if err != nil {
-			return nil, fmt.Errorf("cannot create encryption key: %v", err)
-		}
-
-		rkey, err := secboot.NewRecoveryKey()
-		if err != nil {
-			return nil, fmt.Errorf("cannot create recovery key: %v", err)
-		}
-		return &EncryptionKeySet{
-			Key:         key,
-			RecoveryKey: rkey,
-		}, nil
-	}
-	roleNeedsEncryption := func(role string) bool {
-		return role == gadget.SystemData || role == gadget.SystemSave
-	}
 	var keysForRoles map[string]*EncryptionKeySet
 
 	for _, part := range created {
@@ -136,48 +117,193 @@ func Run(model gadget.Model, gadgetRoot, device string, options Options, observe
 		}
 		logger.Noticef("created new partition %v for structure %v (size %v) %s",
 			part.Node, part, part.Size.IECString(), roleFmt)
-		if options.Encrypt && roleNeedsEncryption(part.Role) {
-			keys, err := makeKeySet()
-			if err != nil {
-				return nil, err
-			}
-			logger.Noticef("encrypting partition device %v", part.Node)
-			dataPart, err := newEncryptedDevice(&part, keys.Key, part.Label)
-			if err != nil {
-				return nil, err
+		keys, err := installOnePartition(&part, gadgetRoot, options, observer)
+		if err != nil {
+			return nil, err
+		}
+		if keys != nil {
+			if keysForRoles == nil {
+				keysForRoles = map[string]*EncryptionKeySet{}
 			}
+			keysForRoles[part.Role] = keys
+		}
+	}
 
-			if err := dataPart.AddRecoveryKey(keys.Key, keys.RecoveryKey); err != nil {
-				return nil, err
-			}
+	return &InstalledSystemSideData{
+		KeysForRoles: keysForRoles,
+	}, nil
+}
 
-			// update the encrypted device node
-			part.Node = dataPart.Node
+// FactoryReset re-creates the filesystems of the ubuntu-boot, ubuntu-save and
+// ubuntu-data partitions of a device that was installed before, using fresh
+// encryption keys. The ubuntu-seed partition and any other partitions are left
+// untouched.
+func FactoryReset(model gadget.Model, gadgetRoot, device string, options Options, observer gadget.ContentObserver) (*InstalledSystemSideData, error) {
+	logger.Noticef("resetting the system to factory state")
+	logger.Noticef("        gadget data from: %v", gadgetRoot)
+	if options.Encrypt {
+		logger.Noticef("        encryption: on")
+	}
+	if gadgetRoot == "" {
+		return nil, fmt.Errorf("cannot use empty gadget root directory")
+	}
+
+	lv, err := gadget.LaidOutVolumeFromGadget(gadgetRoot, model)
+	if err != nil {
+		return nil, fmt.Errorf("cannot layout the volume: %v", err)
+	}
+
+	if device == "" {
+		device, err = deviceFromRole(lv, gadget.SystemSeed)
+		if err != nil {
+			return nil, fmt.Errorf("cannot find device to reset partitions on: %v", err)
+		}
+	}
+
+	diskLayout, err := gadget.OnDiskVolumeFromDevice(device)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %v partitions: %v", device, err)
+	}
+
+	if err := ensureLayoutCompatibility(lv, diskLayout); err != nil {
+		return nil, fmt.Errorf("gadget and %v partition table not compatible: %v", device, err)
+	}
+
+	existing, err := installedPartitions(lv, diskLayout)
+	if err != nil {
+		return nil, err
+	}
+	// the keys sealed during the previous install cannot be used anymore
+	if err := removeObsoleteSealedKeys(); err != nil {
+		return nil, err
+	}
+
+	var keysForRoles map[string]*EncryptionKeySet
+
+	for _, part := range existing {
+		if err := releaseFilesystem(&part, boot.InitramfsRunMntDir); err != nil {
+			return nil, err
+		}
+		logger.Noticef("resetting partition %v for structure %v (size %v) role %v",
+			part.Node, part, part.Size.IECString(), part.Role)
+		keys, err := installOnePartition(&part, gadgetRoot, options, observer)
+		if err != nil {
+			return nil, err
+		}
+		if keys != nil {
 			if keysForRoles == nil {
 				keysForRoles = map[string]*EncryptionKeySet{}
 			}
 			keysForRoles[part.Role] = keys
-			logger.Noticef("encrypted device %v", part.Node)
+		}
+	}
+
+	return &InstalledSystemSideData{
+		KeysForRoles: keysForRoles,
+	}, nil
+}
+
+func removeObsoleteSealedKeys() error {
+	sealedKeyFiles, _ := filepath.Glob(filepath.Join(boot.InitramfsSeedEncryptionKeyDir, "*.sealed-key"))
+	// so are the markers of passphrases added to the previous partitions
+	passphraseFiles, _ := filepath.Glob(filepath.Join(boot.InitramfsSeedEncryptionKeyDir, "*.passphrase"))
+	for _, keyFile := range append(sealedKeyFiles, passphraseFiles...) {
+		if err := os.Remove(keyFile); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot cleanup obsolete key file: %v", keyFile)
+		}
+	}
+	return nil
+}
+
+func makeKeySet() (*EncryptionKeySet, error) {
+	key, err := secboot.NewEncryptionKey()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create encryption key: %v", err)
+	}
+
+	rkey, err := secboot.NewRecoveryKey()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create recovery key: %v", err)
+	}
+	return &EncryptionKeySet{
+		Key:         key,
+		RecoveryKey: rkey,
+	}, nil
+}
+
+func roleNeedsEncryption(role string) bool {
+	return role == gadget.SystemData || role == gadget.SystemSave
+}
+
+// installOnePartition encrypts the partition if needed, creates its
+// filesystem and writes its content. The encryption keys are returned when
+// the partition was encrypted.
+func installOnePartition(part *gadget.OnDiskStructure, gadgetRoot string, options Options, observer gadget.ContentObserver) (*EncryptionKeySet, error) {
+	var keys *EncryptionKeySet
+	if options.Encrypt && roleNeedsEncryption(part.Role) {
+		var err error
+		keys, err = makeKeySet()
+		if err != nil {
+			return nil, err
+		}
+		logger.Noticef("encrypting partition device %v", part.Node)
+		dataPart, err := newEncryptedDevice(part, keys.Key, part.Label)
+		if err != nil {
+			return nil, err
 		}
 
-		if err := makeFilesystem(&part); err != nil {
+		if err := dataPart.AddRecoveryKey(keys.Key, keys.RecoveryKey); err != nil {
 			return nil, err
 		}
 
-		if err := writeContent(&part, gadgetRoot, observer); err != nil {
+		// update the encrypted device node
+		part.Node = dataPart.Node
+		logger.Noticef("encrypted device %v", part.Node)
+	}
+
+	if err := makeFilesystem(part); err != nil {
+		return nil, err
+	}
+
+	if err := writeContent(part, gadgetRoot, observer); err != nil {
+		return nil, err
+	}
+
+	if options.Mount && part.Label != "" && part.HasFilesystem() {
+		if err := mountFilesystem(part, boot.InitramfsRunMntDir); err != nil {
 			return nil, err
 		}
+	}
+	return keys, nil
+}
 
-		if options.Mount && part.Label != "" && part.HasFilesystem() {
-			if err := mountFilesystem(&part, boot.InitramfsRunMntDir); err != nil {
-				return nil, err
+// releaseFilesystem unmounts the filesystem of an on-disk structure if it is
+// still mounted under the given base directory from a previous boot, and
+// closes the encrypted device backing it, if any.
+func releaseFilesystem(ds *gadget.OnDiskStructure, baseMntPoint string) error {
+	if ds.Label == "" {
+		return nil
+	}
+	mountpoint := filepath.Join(baseMntPoint, ds.Label)
+	mounts, err := osutil.LoadMountInfo()
+	if err != nil {
+		return fmt.Errorf("cannot load mount information: %v", err)
+	}
+	for _, mnt := range mounts {
+		if mnt.MountDir != mountpoint {
+			continue
+		}
+		logger.Noticef("releasing %v mounted at %v", mnt.MountSource, mountpoint)
+		if err := sysUnmount(mountpoint, 0); err != nil {
+			return fmt.Errorf("cannot unmount %q: %v", mountpoint, err)
+		}
+		if strings.HasPrefix(mnt.MountSource, "/dev/mapper/") {
+			if err := cryptsetupClose(filepath.Base(mnt.MountSource)); err != nil {
+				return fmt.Errorf("cannot close encrypted device %q: %v", mnt.MountSource, err)
 			}
 		}
 	}
-
-	return &InstalledSystemSideData{
-		KeysForRoles: keysForRoles,
-	}, nil
+	return nil
 }
 
 // isCreatableAtInstall returns whether the gadget structure would be created at
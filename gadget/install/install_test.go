@@ -31,6 +31,7 @@ import (
 	"github.com/snapcore/snapd/gadget"
 	"github.com/snapcore/snapd/gadget/install"
 	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -60,6 +61,42 @@ func (s *installSuite) TestInstallRunError(c *C) {
 	c.Check(sys, IsNil)
 }
 
+func (s *installSuite) TestFactoryResetError(c *C) {
+	sys, err := install.FactoryReset(nil, "", "", install.Options{}, nil)
+	c.Assert(err, ErrorMatches, "cannot use empty gadget root directory")
+	c.Check(sys, IsNil)
+}
+
+func (s *installSuite) TestReleaseFilesystem(c *C) {
+	restore := osutil.MockMountInfo(`
+130 30 42:1 / /run/mnt/ubuntu-boot rw,relatime shared:54 - ext4 /dev/vda3 rw
+131 30 253:0 / /run/mnt/ubuntu-data rw,relatime shared:55 - ext4 /dev/mapper/ubuntu-data-random rw
+`)
+	defer restore()
+	var unmounted []string
+	restore = install.MockSysUnmount(func(target string, flags int) error {
+		unmounted = append(unmounted, target)
+		return nil
+	})
+	defer restore()
+	cmdCryptsetup := testutil.MockCommand(c, "cryptsetup", "")
+	defer cmdCryptsetup.Restore()
+
+	for _, label := range []string{"ubuntu-boot", "ubuntu-data", "ubuntu-save"} {
+		ds := &gadget.OnDiskStructure{
+			LaidOutStructure: gadget.LaidOutStructure{
+				VolumeStructure: &gadget.VolumeStructure{Label: label},
+			},
+		}
+		err := install.ReleaseFilesystem(ds, "/run/mnt")
+		c.Assert(err, IsNil)
+	}
+	c.Check(unmounted, DeepEquals, []string{"/run/mnt/ubuntu-boot", "/run/mnt/ubuntu-data"})
+	c.Check(cmdCryptsetup.Calls(), DeepEquals, [][]string{
+		{"cryptsetup", "close", "ubuntu-data-random"},
+	})
+}
+
 const mockGadgetYaml = `volumes:
   pc:
     bootloader: grub
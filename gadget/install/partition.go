@@ -307,3 +307,33 @@ func createdDuringInstall(lv *gadget.LaidOutVolume, layout *gadget.OnDiskVolume)
 	}
 	return created
 }
+
+// installedPartitions returns the on disk structures of the partitions that
+// were created during a previous install, described using their gadget
+// structures. It is an error if any of them is missing from the disk.
+func installedPartitions(lv *gadget.LaidOutVolume, layout *gadget.OnDiskVolume) ([]gadget.OnDiskStructure, error) {
+	var installed []gadget.OnDiskStructure
+	for _, gs := range lv.LaidOutStructure {
+		switch gs.Role {
+		case gadget.SystemSave, gadget.SystemData, gadget.SystemBoot:
+		default:
+			continue
+		}
+		found := false
+		for _, s := range layout.Structure {
+			if s.StartOffset == gs.StartOffset {
+				installed = append(installed, gadget.OnDiskStructure{
+					LaidOutStructure: gs,
+					Node:             s.Node,
+					Size:             s.Size,
+				})
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("cannot find partition for structure %v with role %v", gs, gs.Role)
+		}
+	}
+	return installed, nil
+}
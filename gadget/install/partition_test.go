@@ -478,8 +478,7 @@ const gptGadgetContentWithSave = `volumes:
         size: 1200M
 `
 
-func (s *partitionTestSuite) TestCreatedDuringInstallGPT(c *C) {
-	cmdLsblk := testutil.MockCommand(c, "lsblk", `
+const mockLsblkScriptGPTWithSave = `
 case $3 in
 	/dev/node1)
 		echo '{ "blockdevices": [ {"fstype":"ext4", "label":null} ] }'
@@ -498,9 +497,9 @@ case $3 in
 		exit 1
 		;;
 esac
-`)
-	defer cmdLsblk.Restore()
-	cmdSfdisk := testutil.MockCommand(c, "sfdisk", `
+`
+
+const mockSfdiskScriptGPTWithSave = `
 echo '{
   "partitiontable": {
     "label": "gpt",
@@ -545,7 +544,12 @@ echo '{
      ]
   }
 }'
-`)
+`
+
+func (s *partitionTestSuite) TestCreatedDuringInstallGPT(c *C) {
+	cmdLsblk := testutil.MockCommand(c, "lsblk", mockLsblkScriptGPTWithSave)
+	defer cmdLsblk.Restore()
+	cmdSfdisk := testutil.MockCommand(c, "sfdisk", mockSfdiskScriptGPTWithSave)
 	defer cmdSfdisk.Restore()
 
 	err := makeMockGadget(s.gadgetRoot, gptGadgetContentWithSave)
@@ -561,6 +565,34 @@ echo '{
 	c.Check(list, DeepEquals, []string{"/dev/node3", "/dev/node4"})
 }
 
+func (s *partitionTestSuite) TestInstalledPartitionsGPT(c *C) {
+	cmdLsblk := testutil.MockCommand(c, "lsblk", mockLsblkScriptGPTWithSave)
+	defer cmdLsblk.Restore()
+	cmdSfdisk := testutil.MockCommand(c, "sfdisk", mockSfdiskScriptGPTWithSave)
+	defer cmdSfdisk.Restore()
+
+	err := makeMockGadget(s.gadgetRoot, gptGadgetContentWithSave)
+	c.Assert(err, IsNil)
+	pv, err := gadget.LaidOutVolumeFromGadget(s.gadgetRoot, uc20mod)
+	c.Assert(err, IsNil)
+
+	dl, err := gadget.OnDiskVolumeFromDevice("node")
+	c.Assert(err, IsNil)
+
+	installed, err := install.InstalledPartitions(pv, dl)
+	c.Assert(err, IsNil)
+	c.Assert(installed, HasLen, 2)
+	c.Check(installed[0].Node, Equals, "/dev/node3")
+	c.Check(installed[0].Role, Equals, gadget.SystemSave)
+	c.Check(installed[1].Node, Equals, "/dev/node4")
+	c.Check(installed[1].Role, Equals, gadget.SystemData)
+
+	// a partition missing from the disk is an error
+	dl.Structure = dl.Structure[:3]
+	_, err = install.InstalledPartitions(pv, dl)
+	c.Assert(err, ErrorMatches, `cannot find partition for structure .* with role system-data`)
+}
+
 // this is an mbr gadget like the pi, but doesn't have the amd64 mbr structure
 // so it's probably not representative, but still useful for unit tests here
 const mbrGadgetContentWithSave = `volumes:
@@ -20,14 +20,18 @@
 package httputil
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/snapcore/snapd/logger"
@@ -73,6 +77,45 @@ func (e *ExtraSSLCertsFromDir) Certs() ([]*CertData, error) {
 	return extraCerts, nil
 }
 
+// TLSParams holds additional TLS parameters applied to the connections
+// made by the httputil.Client.
+type TLSParams struct {
+	// PinnedPublicKeys are SHA256 hashes of DER encoded subject public
+	// key infos, if set at least one certificate in the chain presented
+	// by any of PinnedHosts must have a matching public key.
+	PinnedPublicKeys [][]byte
+	// PinnedHosts are the names of the hosts PinnedPublicKeys apply to.
+	PinnedHosts []string
+	// ClientCertificate if set is presented to servers asking for a
+	// client certificate.
+	ClientCertificate *tls.Certificate
+}
+
+// ExtraTLSParams is an interface that provides a way to add extra
+// TLS parameters to the httputil.Client.
+type ExtraTLSParams interface {
+	TLSParams() (*TLSParams, error)
+}
+
+// ParsePinnedPublicKeys parses a comma separated list of pinned public
+// keys, each given as the base64 encoded SHA256 hash of the subject public
+// key info, optionally prefixed by "sha256/".
+func ParsePinnedPublicKeys(pins string) ([][]byte, error) {
+	var hashes [][]byte
+	for _, pin := range strings.Split(pins, ",") {
+		pin = strings.TrimSpace(pin)
+		if pin == "" {
+			continue
+		}
+		hash, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(pin, "sha256/"))
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid pinned public key %q: must be a base64 encoded SHA256 hash", pin)
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
 // dialTLS holds a tls.Config that is used by the dialTLS.dialTLS()
 // function.
 type dialTLS struct {
@@ -102,6 +145,65 @@ func (d *dialTLS) dialTLS(network, addr string) (net.Conn, error) {
 	return tls.Dial(network, addr, d.conf)
 }
 
+// setupExtraTLSParams makes the given tls.Config present the client
+// certificate and verify the pinned public keys of the extra TLS
+// parameters. This is done as part of the TLS handshake so that it also
+// applies to connections tunnelled through a proxy.
+func setupExtraTLSParams(conf *tls.Config, extraTLSParams ExtraTLSParams) {
+	conf.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		params, err := extraTLSParams.TLSParams()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get TLS parameters: %v", err)
+		}
+		if params == nil || params.ClientCertificate == nil {
+			// no certificate is sent
+			return &tls.Certificate{}, nil
+		}
+		return params.ClientCertificate, nil
+	}
+	setupPinnedPublicKeysVerification(conf, extraTLSParams)
+}
+
+// verifyPinnedHosts checks the certificates presented by the server
+// against the pinned public keys if the server is one of the pinned
+// hosts. When the server name is not known, as is the case for IP
+// addresses, the pinned hosts the certificate is valid for are used.
+func verifyPinnedHosts(params *TLSParams, serverName string, certs []*x509.Certificate) error {
+	if params == nil || len(params.PinnedPublicKeys) == 0 || len(certs) == 0 {
+		return nil
+	}
+	for _, host := range params.PinnedHosts {
+		if serverName != "" && !strings.EqualFold(host, serverName) {
+			continue
+		}
+		if serverName == "" && certs[0].VerifyHostname(host) != nil {
+			continue
+		}
+		if err := verifyPinnedPublicKeys(certs, params.PinnedPublicKeys); err != nil {
+			return fmt.Errorf("cannot verify connection to %s: %v", host, err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// verifyPinnedPublicKeys checks that at least one of the certificates
+// has a public key matching one of the pins, if any.
+func verifyPinnedPublicKeys(certs []*x509.Certificate, pins [][]byte) error {
+	if len(pins) == 0 {
+		return nil
+	}
+	for _, cert := range certs {
+		hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		for _, pin := range pins {
+			if bytes.Equal(hash[:], pin) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no certificate matches the pinned public keys")
+}
+
 // addLocalSSLCertificates() is an internal helper that is called by
 // dialTLS to add an extra certificates.
 func (d *dialTLS) addLocalSSLCertificates() (err error) {
@@ -148,7 +250,8 @@ type ClientOptions struct {
 	Proxy              func(*http.Request) (*url.URL, error)
 	ProxyConnectHeader http.Header
 
-	ExtraSSLCerts ExtraSSLCerts
+	ExtraSSLCerts  ExtraSSLCerts
+	ExtraTLSParams ExtraTLSParams
 }
 
 // NewHTTPCLient returns a new http.Client with a LoggedTransport, a
@@ -163,13 +266,23 @@ func NewHTTPClient(opts *ClientOptions) *http.Client {
 		transport.Proxy = opts.Proxy
 	}
 	transport.ProxyConnectHeader = opts.ProxyConnectHeader
+	tlsConfig := opts.TLSConfig
+	if opts.ExtraTLSParams != nil {
+		if tlsConfig != nil {
+			tlsConfig = tlsConfig.Clone()
+		} else {
+			tlsConfig = &tls.Config{}
+		}
+		setupExtraTLSParams(tlsConfig, opts.ExtraTLSParams)
+	}
 	// Remember the original ClientOptions.TLSConfig when making
 	// tls connection.
-	// Note that we only set TLSClientConfig here because it's extracted
-	// by the cmd/snap-repair/runner_test.go
-	transport.TLSClientConfig = opts.TLSConfig
+	// Note that TLSClientConfig is also used for the connections
+	// tunnelled through a proxy, which do not go through DialTLS, and it
+	// is extracted by the cmd/snap-repair/runner_test.go
+	transport.TLSClientConfig = tlsConfig
 	dialTLS := &dialTLS{
-		conf:          opts.TLSConfig,
+		conf:          tlsConfig,
 		extraSSLCerts: opts.ExtraSSLCerts,
 	}
 	transport.DialTLS = dialTLS.dialTLS
@@ -23,10 +23,13 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/base64"
 	"encoding/pem"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"math/big"
@@ -283,3 +286,209 @@ func (s *tlsSuite) TestClientMaxTLS12Ok(c *check.C) {
 	// fine and expected.
 	c.Assert(err, check.ErrorMatches, ".* certificate signed by unknown authority")
 }
+
+type tlsParams struct {
+	params *httputil.TLSParams
+	err    error
+}
+
+func (p *tlsParams) TLSParams() (*httputil.TLSParams, error) {
+	return p.params, p.err
+}
+
+func (s *tlsSuite) serverPin(c *check.C) []byte {
+	cert, err := tls.LoadX509KeyPair(s.certpath, s.keypath)
+	c.Assert(err, check.IsNil)
+	x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
+	c.Assert(err, check.IsNil)
+	hash := sha256.Sum256(x509Cert.RawSubjectPublicKeyInfo)
+	return hash[:]
+}
+
+func (s *tlsSuite) TestClientPinnedPublicKeyMatches(c *check.C) {
+	cli := httputil.NewHTTPClient(&httputil.ClientOptions{
+		ExtraSSLCerts: &httputil.ExtraSSLCertsFromDir{
+			Dir: dirs.SnapdStoreSSLCertsDir,
+		},
+		ExtraTLSParams: &tlsParams{params: &httputil.TLSParams{
+			PinnedPublicKeys: [][]byte{make([]byte, 32), s.serverPin(c)},
+			PinnedHosts:      []string{"127.0.0.1"},
+		}},
+	})
+	res, err := cli.Get(s.srv.URL)
+	c.Assert(err, check.IsNil)
+	c.Assert(res.StatusCode, check.Equals, 200)
+}
+
+func (s *tlsSuite) TestClientPinnedPublicKeyMismatch(c *check.C) {
+	cli := httputil.NewHTTPClient(&httputil.ClientOptions{
+		ExtraSSLCerts: &httputil.ExtraSSLCertsFromDir{
+			Dir: dirs.SnapdStoreSSLCertsDir,
+		},
+		ExtraTLSParams: &tlsParams{params: &httputil.TLSParams{
+			PinnedPublicKeys: [][]byte{make([]byte, 32)},
+			PinnedHosts:      []string{"127.0.0.1"},
+		}},
+	})
+	_, err := cli.Get(s.srv.URL)
+	c.Assert(err, check.ErrorMatches, ".* cannot verify connection to 127.0.0.1: no certificate matches the pinned public keys")
+}
+
+func (s *tlsSuite) TestClientPinnedPublicKeyMismatchServerName(c *check.C) {
+	cli := httputil.NewHTTPClient(&httputil.ClientOptions{
+		ExtraSSLCerts: &httputil.ExtraSSLCertsFromDir{
+			Dir: dirs.SnapdStoreSSLCertsDir,
+		},
+		ExtraTLSParams: &tlsParams{params: &httputil.TLSParams{
+			PinnedPublicKeys: [][]byte{make([]byte, 32)},
+			PinnedHosts:      []string{"localhost"},
+		}},
+	})
+	u := mustParse(c, s.srv.URL)
+	u.Host = "localhost:" + u.Port()
+	_, err := cli.Get(u.String())
+	c.Assert(err, check.ErrorMatches, ".* cannot verify connection to localhost: no certificate matches the pinned public keys")
+}
+
+func (s *tlsSuite) TestClientPinnedPublicKeyOtherHost(c *check.C) {
+	cli := httputil.NewHTTPClient(&httputil.ClientOptions{
+		ExtraSSLCerts: &httputil.ExtraSSLCertsFromDir{
+			Dir: dirs.SnapdStoreSSLCertsDir,
+		},
+		ExtraTLSParams: &tlsParams{params: &httputil.TLSParams{
+			PinnedPublicKeys: [][]byte{make([]byte, 32)},
+			PinnedHosts:      []string{"api.snapcraft.io"},
+		}},
+	})
+	// the pins do not apply to this host
+	res, err := cli.Get(s.srv.URL)
+	c.Assert(err, check.IsNil)
+	c.Assert(res.StatusCode, check.Equals, 200)
+}
+
+func (s *tlsSuite) TestClientPinnedPublicKeyThroughProxy(c *check.C) {
+	var connected []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, check.Equals, "CONNECT")
+		connected = append(connected, r.Host)
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(502)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go func() {
+			defer upstream.Close()
+			io.Copy(upstream, conn)
+		}()
+		go func() {
+			defer conn.Close()
+			io.Copy(conn, upstream)
+		}()
+	}))
+	s.AddCleanup(proxy.Close)
+
+	cert, err := tls.LoadX509KeyPair(s.certpath, s.keypath)
+	c.Assert(err, check.IsNil)
+	x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
+	c.Assert(err, check.IsNil)
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(x509Cert)
+
+	for _, t := range []struct {
+		pin []byte
+		err string
+	}{
+		{s.serverPin(c), ""},
+		{make([]byte, 32), ".* cannot verify connection to 127.0.0.1: no certificate matches the pinned public keys"},
+	} {
+		cli := httputil.NewHTTPClient(&httputil.ClientOptions{
+			TLSConfig: &tls.Config{RootCAs: rootCAs},
+			Proxy: func(*http.Request) (*url.URL, error) {
+				return url.Parse(proxy.URL)
+			},
+			ExtraTLSParams: &tlsParams{params: &httputil.TLSParams{
+				PinnedPublicKeys: [][]byte{t.pin},
+				PinnedHosts:      []string{"127.0.0.1"},
+			}},
+		})
+		res, err := cli.Get(s.srv.URL)
+		if t.err == "" {
+			c.Assert(err, check.IsNil)
+			c.Check(res.StatusCode, check.Equals, 200)
+			res.Body.Close()
+		} else {
+			c.Check(err, check.ErrorMatches, t.err)
+		}
+	}
+	c.Check(connected, check.HasLen, 2)
+}
+
+func (s *tlsSuite) TestClientTLSParamsError(c *check.C) {
+	cli := httputil.NewHTTPClient(&httputil.ClientOptions{
+		ExtraSSLCerts: &httputil.ExtraSSLCertsFromDir{
+			Dir: dirs.SnapdStoreSSLCertsDir,
+		},
+		ExtraTLSParams: &tlsParams{err: fmt.Errorf("boom")},
+	})
+	_, err := cli.Get(s.srv.URL)
+	c.Assert(err, check.ErrorMatches, ".* cannot get TLS parameters: boom")
+}
+
+func (s *tlsSuite) TestClientCertificate(c *check.C) {
+	var peerCerts []*x509.Certificate
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peerCerts = r.TLS.PeerCertificates
+		io.WriteString(w, `all good`)
+	}))
+	cert, err := tls.LoadX509KeyPair(s.certpath, s.keypath)
+	c.Assert(err, check.IsNil)
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAnyClientCert,
+	}
+	srv.StartTLS()
+	s.AddCleanup(srv.Close)
+
+	opts := &httputil.ClientOptions{
+		ExtraSSLCerts: &httputil.ExtraSSLCertsFromDir{
+			Dir: dirs.SnapdStoreSSLCertsDir,
+		},
+	}
+
+	// no client certificate, the server refuses the connection
+	_, err = httputil.NewHTTPClient(opts).Get(srv.URL)
+	c.Assert(err, check.NotNil)
+
+	opts.ExtraTLSParams = &tlsParams{params: &httputil.TLSParams{
+		ClientCertificate: &cert,
+	}}
+	res, err := httputil.NewHTTPClient(opts).Get(srv.URL)
+	c.Assert(err, check.IsNil)
+	c.Assert(res.StatusCode, check.Equals, 200)
+	c.Assert(peerCerts, check.HasLen, 1)
+	c.Check(peerCerts[0].Raw, check.DeepEquals, cert.Certificate[0])
+}
+
+func (s *clientSuite) TestParsePinnedPublicKeys(c *check.C) {
+	hash := sha256.Sum256([]byte("spki"))
+	encoded := base64.StdEncoding.EncodeToString(hash[:])
+
+	pins, err := httputil.ParsePinnedPublicKeys("")
+	c.Assert(err, check.IsNil)
+	c.Check(pins, check.HasLen, 0)
+
+	pins, err = httputil.ParsePinnedPublicKeys("sha256/" + encoded + ", " + encoded)
+	c.Assert(err, check.IsNil)
+	c.Check(pins, check.DeepEquals, [][]byte{hash[:], hash[:]})
+
+	_, err = httputil.ParsePinnedPublicKeys("sha256/AAAA")
+	c.Check(err, check.ErrorMatches, `invalid pinned public key "sha256/AAAA": must be a base64 encoded SHA256 hash`)
+	_, err = httputil.ParsePinnedPublicKeys("not base64!")
+	c.Check(err, check.ErrorMatches, `invalid pinned public key "not base64!": must be a base64 encoded SHA256 hash`)
+}
@@ -19,6 +19,18 @@
 
 package httputil
 
+import (
+	"net"
+)
+
 var (
 	GetFlags = (*LoggedTransport).getFlags
 )
+
+func MockPACLookupIP(f func(host string) ([]net.IP, error)) (restore func()) {
+	old := pacLookupIP
+	pacLookupIP = f
+	return func() {
+		pacLookupIP = old
+	}
+}
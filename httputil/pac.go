@@ -0,0 +1,678 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httputil
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PAC is a proxy auto-configuration script.
+//
+// Only a minimal subset of JavaScript is supported: the script must
+// consist of a single FindProxyForURL(url, host) function whose body
+// is made of (possibly nested) if/else statements and return
+// statements. Conditions can use string literals, the url and host
+// parameters, comparisons, the !, && and || operators and the standard
+// PAC helpers isPlainHostName, dnsDomainIs, localHostOrDomainIs,
+// shExpMatch, dnsDomainLevels and isInNet.
+type PAC struct {
+	urlParam  string
+	hostParam string
+	body      []pacStmt
+}
+
+// ParsePAC parses the given proxy auto-configuration script.
+func ParsePAC(script []byte) (*PAC, error) {
+	toks, err := pacTokenize(string(script))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse proxy auto-configuration: %v", err)
+	}
+	p := &pacParser{toks: toks}
+	pac, err := p.parseScript()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse proxy auto-configuration: %v", err)
+	}
+	return pac, nil
+}
+
+// FindProxyForURL evaluates the script for the given URL and returns
+// its result, e.g. "PROXY proxy.internal:3128; DIRECT".
+func (pac *PAC) FindProxyForURL(u *url.URL) (string, error) {
+	env := &pacEnv{
+		vars: map[string]pacValue{
+			pac.urlParam:  u.String(),
+			pac.hostParam: strings.ToLower(u.Hostname()),
+		},
+	}
+	res, returned, err := env.execBlock(pac.body)
+	if err != nil {
+		return "", fmt.Errorf("cannot evaluate proxy auto-configuration: %v", err)
+	}
+	if !returned {
+		return "", fmt.Errorf("cannot evaluate proxy auto-configuration: FindProxyForURL did not return a value")
+	}
+	s, ok := res.(string)
+	if !ok {
+		return "", fmt.Errorf("cannot evaluate proxy auto-configuration: FindProxyForURL returned %v instead of a string", res)
+	}
+	return s, nil
+}
+
+// ProxyFromPACResult returns the first usable proxy from the result of
+// a proxy auto-configuration script. It returns nil for DIRECT.
+func ProxyFromPACResult(result string) (*url.URL, error) {
+	for _, entry := range strings.Split(result, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "DIRECT":
+			return nil, nil
+		case "PROXY", "HTTP":
+			if len(fields) == 2 {
+				return &url.URL{Scheme: "http", Host: fields[1]}, nil
+			}
+		case "HTTPS":
+			if len(fields) == 2 {
+				return &url.URL{Scheme: "https", Host: fields[1]}, nil
+			}
+		}
+		// SOCKS and malformed entries are not supported, try the
+		// next one
+	}
+	return nil, fmt.Errorf("no supported proxy in proxy auto-configuration result %q", result)
+}
+
+// tokenizer
+
+type pacTokenKind int
+
+const (
+	pacTokIdent pacTokenKind = iota
+	pacTokString
+	pacTokNumber
+	pacTokPunct
+	pacTokEOF
+)
+
+type pacToken struct {
+	kind pacTokenKind
+	text string
+}
+
+var pacPuncts = []string{"===", "!==", "==", "!=", "<=", ">=", "<", ">", "&&", "||", "(", ")", "{", "}", ";", ",", "!"}
+
+func pacTokenize(src string) ([]pacToken, error) {
+	var toks []pacToken
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(src[i+1:], src[i])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			toks = append(toks, pacToken{pacTokString, src[i+1 : i+1+end]})
+			i += end + 2
+		case c == '_' || c == '$' || unicode.IsLetter(c):
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] == '$' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			toks = append(toks, pacToken{pacTokIdent, src[i:j]})
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(src) && unicode.IsDigit(rune(src[j])) {
+				j++
+			}
+			toks = append(toks, pacToken{pacTokNumber, src[i:j]})
+			i = j
+		default:
+			found := false
+			for _, p := range pacPuncts {
+				if strings.HasPrefix(src[i:], p) {
+					toks = append(toks, pacToken{pacTokPunct, p})
+					i += len(p)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+		}
+	}
+	return append(toks, pacToken{kind: pacTokEOF}), nil
+}
+
+// parser
+
+type pacValue interface{}
+
+type pacExpr func(env *pacEnv) (pacValue, error)
+
+type pacStmt struct {
+	// either a return statement
+	ret pacExpr
+	// or a conditional
+	cond      pacExpr
+	then      []pacStmt
+	otherwise []pacStmt
+	// or a plain block
+	block []pacStmt
+}
+
+type pacParser struct {
+	toks []pacToken
+	pos  int
+}
+
+func (p *pacParser) peek() pacToken {
+	return p.toks[p.pos]
+}
+
+func (p *pacParser) next() pacToken {
+	tok := p.toks[p.pos]
+	if tok.kind != pacTokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *pacParser) isPunct(text string) bool {
+	tok := p.peek()
+	return tok.kind == pacTokPunct && tok.text == text
+}
+
+func (p *pacParser) isKeyword(kw string) bool {
+	tok := p.peek()
+	return tok.kind == pacTokIdent && tok.text == kw
+}
+
+func (p *pacParser) expectPunct(text string) error {
+	if !p.isPunct(text) {
+		return p.unexpected(fmt.Sprintf("%q", text))
+	}
+	p.next()
+	return nil
+}
+
+func (p *pacParser) expectIdent() (string, error) {
+	tok := p.peek()
+	if tok.kind != pacTokIdent {
+		return "", p.unexpected("identifier")
+	}
+	p.next()
+	return tok.text, nil
+}
+
+func (p *pacParser) unexpected(expected string) error {
+	tok := p.peek()
+	if tok.kind == pacTokEOF {
+		return fmt.Errorf("expected %s, got end of script", expected)
+	}
+	return fmt.Errorf("expected %s, got %q", expected, tok.text)
+}
+
+func (p *pacParser) parseScript() (*PAC, error) {
+	if !p.isKeyword("function") {
+		return nil, p.unexpected(`"function"`)
+	}
+	p.next()
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	if name != "FindProxyForURL" {
+		return nil, fmt.Errorf("unsupported function %q, only FindProxyForURL can be defined", name)
+	}
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	urlParam, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct(","); err != nil {
+		return nil, err
+	}
+	hostParam, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct(")"); err != nil {
+		return nil, err
+	}
+	body, err := p.parseBlock()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != pacTokEOF {
+		return nil, p.unexpected("end of script")
+	}
+	return &PAC{urlParam: urlParam, hostParam: hostParam, body: body}, nil
+}
+
+func (p *pacParser) parseBlock() ([]pacStmt, error) {
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+	var stmts []pacStmt
+	for !p.isPunct("}") {
+		if p.peek().kind == pacTokEOF {
+			return nil, p.unexpected(`"}"`)
+		}
+		stmt, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		if stmt != nil {
+			stmts = append(stmts, *stmt)
+		}
+	}
+	p.next()
+	return stmts, nil
+}
+
+func (p *pacParser) parseStmt() (*pacStmt, error) {
+	switch {
+	case p.isPunct(";"):
+		p.next()
+		return nil, nil
+	case p.isPunct("{"):
+		block, err := p.parseBlock()
+		if err != nil {
+			return nil, err
+		}
+		return &pacStmt{block: block}, nil
+	case p.isKeyword("return"):
+		p.next()
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.isPunct(";") {
+			p.next()
+		}
+		return &pacStmt{ret: expr}, nil
+	case p.isKeyword("if"):
+		p.next()
+		if err := p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		cond, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		then, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		stmt := &pacStmt{cond: cond}
+		if then != nil {
+			stmt.then = []pacStmt{*then}
+		}
+		if p.isKeyword("else") {
+			p.next()
+			otherwise, err := p.parseStmt()
+			if err != nil {
+				return nil, err
+			}
+			if otherwise != nil {
+				stmt.otherwise = []pacStmt{*otherwise}
+			}
+		}
+		return stmt, nil
+	}
+	return nil, p.unexpected("statement")
+}
+
+func (p *pacParser) parseExpr() (pacExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env *pacEnv) (pacValue, error) {
+			v, err := l(env)
+			if err != nil || pacTruthy(v) {
+				return v, err
+			}
+			return right(env)
+		}
+	}
+	return left, nil
+}
+
+func (p *pacParser) parseAnd() (pacExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("&&") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env *pacEnv) (pacValue, error) {
+			v, err := l(env)
+			if err != nil || !pacTruthy(v) {
+				return v, err
+			}
+			return right(env)
+		}
+	}
+	return left, nil
+}
+
+func (p *pacParser) parseUnary() (pacExpr, error) {
+	if p.isPunct("!") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(env *pacEnv) (pacValue, error) {
+			v, err := operand(env)
+			if err != nil {
+				return nil, err
+			}
+			return !pacTruthy(v), nil
+		}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *pacParser) parseComparison() (pacExpr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "===", "!=", "!==", "<", "<=", ">", ">="} {
+		if !p.isPunct(op) {
+			continue
+		}
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return func(env *pacEnv) (pacValue, error) {
+			l, err := left(env)
+			if err != nil {
+				return nil, err
+			}
+			r, err := right(env)
+			if err != nil {
+				return nil, err
+			}
+			return pacCompare(op, l, r)
+		}, nil
+	}
+	return left, nil
+}
+
+func (p *pacParser) parsePrimary() (pacExpr, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case pacTokString:
+		p.next()
+		return func(*pacEnv) (pacValue, error) { return tok.text, nil }, nil
+	case pacTokNumber:
+		p.next()
+		n, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return func(*pacEnv) (pacValue, error) { return n, nil }, nil
+	case pacTokIdent:
+		p.next()
+		switch tok.text {
+		case "true", "false":
+			b := tok.text == "true"
+			return func(*pacEnv) (pacValue, error) { return b, nil }, nil
+		}
+		if !p.isPunct("(") {
+			name := tok.text
+			return func(env *pacEnv) (pacValue, error) {
+				v, ok := env.vars[name]
+				if !ok {
+					return nil, fmt.Errorf("undefined variable %q", name)
+				}
+				return v, nil
+			}, nil
+		}
+		fn, ok := pacBuiltins[tok.text]
+		if !ok {
+			return nil, fmt.Errorf("unsupported function %q", tok.text)
+		}
+		p.next()
+		var args []pacExpr
+		for !p.isPunct(")") {
+			if len(args) > 0 {
+				if err := p.expectPunct(","); err != nil {
+					return nil, err
+				}
+			}
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		p.next()
+		if len(args) != fn.nargs {
+			return nil, fmt.Errorf("%s expects %d arguments, got %d", tok.text, fn.nargs, len(args))
+		}
+		return func(env *pacEnv) (pacValue, error) {
+			vals := make([]string, len(args))
+			for i, arg := range args {
+				v, err := arg(env)
+				if err != nil {
+					return nil, err
+				}
+				vals[i] = fmt.Sprint(v)
+			}
+			return fn.call(vals), nil
+		}, nil
+	case pacTokPunct:
+		if tok.text == "(" {
+			p.next()
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(")"); err != nil {
+				return nil, err
+			}
+			return expr, nil
+		}
+	}
+	return nil, p.unexpected("expression")
+}
+
+// evaluation
+
+type pacEnv struct {
+	vars map[string]pacValue
+}
+
+func (env *pacEnv) execBlock(stmts []pacStmt) (res pacValue, returned bool, err error) {
+	for _, stmt := range stmts {
+		switch {
+		case stmt.ret != nil:
+			v, err := stmt.ret(env)
+			return v, true, err
+		case stmt.cond != nil:
+			v, err := stmt.cond(env)
+			if err != nil {
+				return nil, false, err
+			}
+			branch := stmt.otherwise
+			if pacTruthy(v) {
+				branch = stmt.then
+			}
+			res, returned, err = env.execBlock(branch)
+		default:
+			res, returned, err = env.execBlock(stmt.block)
+		}
+		if returned || err != nil {
+			return res, returned, err
+		}
+	}
+	return nil, false, nil
+}
+
+func pacTruthy(v pacValue) bool {
+	switch x := v.(type) {
+	case bool:
+		return x
+	case string:
+		return x != ""
+	case int:
+		return x != 0
+	}
+	return false
+}
+
+func pacCompare(op string, l, r pacValue) (pacValue, error) {
+	switch op {
+	case "==", "===":
+		return l == r, nil
+	case "!=", "!==":
+		return l != r, nil
+	}
+	li, lok := l.(int)
+	ri, rok := r.(int)
+	if !lok || !rok {
+		return nil, fmt.Errorf("cannot compare %v and %v with %s", l, r, op)
+	}
+	switch op {
+	case "<":
+		return li < ri, nil
+	case "<=":
+		return li <= ri, nil
+	case ">":
+		return li > ri, nil
+	default:
+		return li >= ri, nil
+	}
+}
+
+type pacBuiltin struct {
+	nargs int
+	call  func(args []string) pacValue
+}
+
+// pacLookupIP is used by isInNet to resolve host names.
+var pacLookupIP = net.LookupIP
+
+var pacBuiltins = map[string]pacBuiltin{
+	"isPlainHostName": {1, func(args []string) pacValue {
+		return !strings.Contains(args[0], ".")
+	}},
+	"dnsDomainIs": {2, func(args []string) pacValue {
+		return strings.HasSuffix(strings.ToLower(args[0]), strings.ToLower(args[1]))
+	}},
+	"localHostOrDomainIs": {2, func(args []string) pacValue {
+		host, hostdom := strings.ToLower(args[0]), strings.ToLower(args[1])
+		if host == hostdom {
+			return true
+		}
+		return !strings.Contains(host, ".") && strings.HasPrefix(hostdom, host+".")
+	}},
+	"shExpMatch": {2, func(args []string) pacValue {
+		return shExpMatch(args[0], args[1])
+	}},
+	"dnsDomainLevels": {1, func(args []string) pacValue {
+		return strings.Count(args[0], ".")
+	}},
+	"isInNet": {3, func(args []string) pacValue {
+		ip := net.ParseIP(args[0])
+		if ip == nil {
+			ips, err := pacLookupIP(args[0])
+			if err != nil || len(ips) == 0 {
+				return false
+			}
+			ip = ips[0]
+		}
+		pattern := net.ParseIP(args[1]).To4()
+		mask := net.ParseIP(args[2]).To4()
+		if pattern == nil || mask == nil || ip.To4() == nil {
+			return false
+		}
+		ipMask := net.IPv4Mask(mask[0], mask[1], mask[2], mask[3])
+		return ip.To4().Mask(ipMask).Equal(pattern.Mask(ipMask))
+	}},
+}
+
+// shExpMatch matches str against a shell expression where * matches
+// any sequence of characters (including /) and ? any single character.
+func shExpMatch(str, shexp string) bool {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range shexp {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	matched, err := regexp.MatchString(expr.String(), str)
+	return err == nil && matched
+}
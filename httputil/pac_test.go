@@ -0,0 +1,152 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httputil_test
+
+import (
+	"net"
+	"net/url"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/httputil"
+)
+
+type pacSuite struct{}
+
+var _ = Suite(&pacSuite{})
+
+const testPAC = `
+// proxy auto-configuration for the store
+function FindProxyForURL(url, host) {
+	/* local things go direct */
+	if (isPlainHostName(host) || dnsDomainIs(host, ".internal"))
+		return "DIRECT";
+
+	if (shExpMatch(host, "*.cdn.snapcraftcontent.com")) {
+		return "PROXY cdn-proxy:3128; DIRECT";
+	} else if (host == 'api.snapcraft.io' && !shExpMatch(url, "http:*")) {
+		return "HTTPS api-proxy:443";
+	}
+
+	if (isInNet(host, "10.0.0.0", "255.0.0.0"))
+		return "DIRECT";
+	if (dnsDomainLevels(host) > 0 && localHostOrDomainIs(host, "www.example.com"))
+		return "PROXY www-proxy:8080";
+
+	return "SOCKS socks:1080; PROXY default-proxy:3128";
+}
+`
+
+func (s *pacSuite) findProxy(c *C, pac *httputil.PAC, rawurl string) string {
+	u, err := url.Parse(rawurl)
+	c.Assert(err, IsNil)
+	res, err := pac.FindProxyForURL(u)
+	c.Assert(err, IsNil)
+	return res
+}
+
+func (s *pacSuite) TestFindProxyForURL(c *C) {
+	restore := httputil.MockPACLookupIP(func(host string) ([]net.IP, error) {
+		if host == "ten.example.com" {
+			return []net.IP{net.ParseIP("10.1.2.3")}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host}
+	})
+	defer restore()
+
+	pac, err := httputil.ParsePAC([]byte(testPAC))
+	c.Assert(err, IsNil)
+
+	for _, t := range []struct {
+		url      string
+		expected string
+	}{
+		{"http://localhost/", "DIRECT"},
+		{"https://store.internal/api", "DIRECT"},
+		{"https://a.cdn.snapcraftcontent.com/foo", "PROXY cdn-proxy:3128; DIRECT"},
+		{"https://api.snapcraft.io/v2/snaps/find", "HTTPS api-proxy:443"},
+		{"http://api.snapcraft.io/v2/snaps/find", "SOCKS socks:1080; PROXY default-proxy:3128"},
+		{"http://10.2.3.4/", "DIRECT"},
+		{"http://ten.example.com/", "DIRECT"},
+		{"http://www.example.com/", "PROXY www-proxy:8080"},
+		{"http://foo.example.com/", "SOCKS socks:1080; PROXY default-proxy:3128"},
+	} {
+		c.Check(s.findProxy(c, pac, t.url), Equals, t.expected, Commentf(t.url))
+	}
+}
+
+func (s *pacSuite) TestFindProxyForURLOtherParamNames(c *C) {
+	pac, err := httputil.ParsePAC([]byte(`function FindProxyForURL(u, h) { if (h === "foo.com") { return "DIRECT" } return "PROXY p:1" }`))
+	c.Assert(err, IsNil)
+
+	c.Check(s.findProxy(c, pac, "http://foo.com"), Equals, "DIRECT")
+	c.Check(s.findProxy(c, pac, "http://bar.com"), Equals, "PROXY p:1")
+}
+
+func (s *pacSuite) TestFindProxyForURLNoReturn(c *C) {
+	pac, err := httputil.ParsePAC([]byte(`function FindProxyForURL(url, host) { if (false) return "DIRECT"; }`))
+	c.Assert(err, IsNil)
+
+	_, err = pac.FindProxyForURL(&url.URL{Scheme: "http", Host: "foo"})
+	c.Check(err, ErrorMatches, "cannot evaluate proxy auto-configuration: FindProxyForURL did not return a value")
+}
+
+func (s *pacSuite) TestParsePACErrors(c *C) {
+	for _, t := range []struct {
+		script string
+		err    string
+	}{
+		{``, `expected "function", got end of script`},
+		{`function foo(url, host) {}`, `unsupported function "foo", only FindProxyForURL can be defined`},
+		{`function FindProxyForURL(url) {}`, `expected ",", got "\)"`},
+		{`function FindProxyForURL(url, host) { return "DIRECT"`, `expected "}", got end of script`},
+		{`function FindProxyForURL(url, host) { var x; }`, `expected statement, got "var"`},
+		{`function FindProxyForURL(url, host) { return dnsResolve(host); }`, `unsupported function "dnsResolve"`},
+		{`function FindProxyForURL(url, host) { return shExpMatch(host); }`, `shExpMatch expects 2 arguments, got 1`},
+		{`function FindProxyForURL(url, host) { return "DIRECT; }`, `unterminated string`},
+		{`function FindProxyForURL(url, host) { /* return "DIRECT"; }`, `unterminated comment`},
+		{`function FindProxyForURL(url, host) { return host + "foo"; }`, `unexpected character '\+'`},
+		{`function FindProxyForURL(url, host) {} extra`, `expected end of script, got "extra"`},
+	} {
+		_, err := httputil.ParsePAC([]byte(t.script))
+		c.Check(err, ErrorMatches, "cannot parse proxy auto-configuration: "+t.err, Commentf(t.script))
+	}
+}
+
+func (s *pacSuite) TestProxyFromPACResult(c *C) {
+	for _, t := range []struct {
+		result   string
+		expected *url.URL
+	}{
+		{"DIRECT", nil},
+		{"PROXY proxy:3128", &url.URL{Scheme: "http", Host: "proxy:3128"}},
+		{"HTTP proxy:3128; DIRECT", &url.URL{Scheme: "http", Host: "proxy:3128"}},
+		{"HTTPS proxy:443", &url.URL{Scheme: "https", Host: "proxy:443"}},
+		{"SOCKS socks:1080; DIRECT", nil},
+		{" socks5 socks:1080 ; proxy p:1", &url.URL{Scheme: "http", Host: "p:1"}},
+	} {
+		proxy, err := httputil.ProxyFromPACResult(t.result)
+		c.Assert(err, IsNil, Commentf(t.result))
+		c.Check(proxy, DeepEquals, t.expected, Commentf(t.result))
+	}
+
+	_, err := httputil.ProxyFromPACResult("SOCKS socks:1080")
+	c.Check(err, ErrorMatches, `no supported proxy in proxy auto-configuration result "SOCKS socks:1080"`)
+}
@@ -0,0 +1,39 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+// +build go1.15
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httputil
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// setupPinnedPublicKeysVerification makes the given tls.Config verify
+// the pinned public keys of the hosts they apply to once the handshake
+// is done, whether the connection goes through a proxy or not.
+func setupPinnedPublicKeysVerification(conf *tls.Config, extraTLSParams ExtraTLSParams) {
+	conf.VerifyConnection = func(cs tls.ConnectionState) error {
+		params, err := extraTLSParams.TLSParams()
+		if err != nil {
+			return fmt.Errorf("cannot get TLS parameters: %v", err)
+		}
+		return verifyPinnedHosts(params, cs.ServerName, cs.PeerCertificates)
+	}
+}
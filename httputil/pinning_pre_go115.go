@@ -0,0 +1,55 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+// +build !go1.15
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httputil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// setupPinnedPublicKeysVerification makes the given tls.Config verify
+// the pinned public keys of the hosts they apply to during the
+// handshake, whether the connection goes through a proxy or not.
+//
+// Without tls.Config.VerifyConnection the name of the server is not
+// known here, the pins apply when the certificate presented by the
+// server is valid for any of the pinned hosts.
+func setupPinnedPublicKeysVerification(conf *tls.Config, extraTLSParams ExtraTLSParams) {
+	conf.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		params, err := extraTLSParams.TLSParams()
+		if err != nil {
+			return fmt.Errorf("cannot get TLS parameters: %v", err)
+		}
+		if params == nil || len(params.PinnedPublicKeys) == 0 {
+			return nil
+		}
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("cannot parse server certificate: %v", err)
+			}
+			certs = append(certs, cert)
+		}
+		return verifyPinnedHosts(params, "", certs)
+	}
+}
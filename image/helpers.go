@@ -40,6 +40,7 @@ import (
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/snapasserts"
+	"github.com/snapcore/snapd/httputil"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/auth"
@@ -184,6 +185,10 @@ func (tac toolingStoreContext) StoreID(fallback string) (string, error) {
 	return fallback, nil
 }
 
+func (tac toolingStoreContext) TLSParams() (*httputil.TLSParams, error) {
+	return nil, nil
+}
+
 func (tac toolingStoreContext) UpdateDeviceAuth(_ *auth.DeviceState, newSessionMacaroon string) (*auth.DeviceState, error) {
 	return nil, fmt.Errorf("internal error: no device state in tools")
 }
@@ -34,6 +34,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/snapcore/snapd/osutil"
 )
@@ -66,22 +67,36 @@ const (
 	// skipKernelLoad tells apparmor_parser not to load profiles into the kernel. The use
 	// case of this is when in pre-seeding mode.
 	skipKernelLoad aaParserFlags = 1 << iota
+
+	// singleJob tells apparmor_parser to compile profiles one at a time. This is
+	// used when several instances of apparmor_parser run in parallel.
+	singleJob aaParserFlags = 1 << iota
 )
 
 var runtimeNumCPU = runtime.NumCPU
 
-func maybeSetNumberOfJobs() string {
+// numberOfJobs returns the number of profiles that may be compiled at
+// the same time. Do not use all CPUs as this may have negative impact when
+// booting.
+func numberOfJobs() int {
 	cpus := runtimeNumCPU()
-	// Do not use all CPUs as this may have negative impact when booting. Note, -j0 has special meaning
-	// so we don't want to pass it to apparmor parser.
-	if cpus > 1 {
-		if cpus == 2 {
-			// systems with only two CPUs, spare 1
-			return fmt.Sprintf("-j%d", cpus-1)
-		} else {
-			// otherwise spare 2
-			return fmt.Sprintf("-j%d", cpus-2)
-		}
+	switch {
+	case cpus > 2:
+		// spare 2
+		return cpus - 2
+	case cpus == 2:
+		// systems with only two CPUs, spare 1
+		return 1
+	default:
+		return 0
+	}
+}
+
+func maybeSetNumberOfJobs() string {
+	// Note, -j0 has special meaning so we don't want to pass it to apparmor
+	// parser.
+	if jobs := numberOfJobs(); jobs > 0 {
+		return fmt.Sprintf("-j%d", jobs)
 	}
 	return ""
 }
@@ -97,7 +112,9 @@ func loadProfiles(fnames []string, cacheDir string, flags aaParserFlags) error {
 
 	// Use no-expr-simplify since expr-simplify is actually slower on armhf (LP: #1383858)
 	args := []string{"--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s", cacheDir)}
-	if flags&conserveCPU != 0 {
+	if flags&singleJob != 0 {
+		args = append(args, "-j1")
+	} else if flags&conserveCPU != 0 {
 		if jobArg := maybeSetNumberOfJobs(); jobArg != "" {
 			args = append(args, jobArg)
 		}
@@ -122,6 +139,39 @@ func loadProfiles(fnames []string, cacheDir string, flags aaParserFlags) error {
 	return nil
 }
 
+// loadProfileGroups loads groups of apparmor profiles, running up to
+// numberOfJobs() instances of apparmor_parser in parallel, one per group.
+// The returned list contains the error of loading each group, nil on success.
+func loadProfileGroups(groups [][]string, cacheDir string, flags aaParserFlags) []error {
+	errs := make([]error, len(groups))
+	jobs := numberOfJobs()
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > 1 {
+		flags |= singleJob
+	}
+
+	var wg sync.WaitGroup
+	work := make(chan int)
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range work {
+				errs[idx] = loadProfiles(groups[idx], cacheDir, flags)
+			}
+		}()
+	}
+	for idx := range groups {
+		work <- idx
+	}
+	close(work)
+	wg.Wait()
+
+	return errs
+}
+
 // unloadProfiles is meant to remove the named profiles from the running
 // kernel and then remove any cache files. Importantly, we can only unload
 // profiles when we are sure there are no lingering processes from the snap
@@ -180,12 +230,28 @@ var profilesPath = realProfilesPath
 // Snappy manages apparmor profiles named "snap.*". Other profiles might exist on
 // the system (via snappy dimension) and those are filtered-out.
 func LoadedProfiles() ([]string, error) {
+	names, err := loadedProfileNames()
+	if err != nil {
+		return nil, err
+	}
+	var profiles []string
+	for _, name := range names {
+		if strings.HasPrefix(name, "snap.") {
+			profiles = append(profiles, name)
+		}
+	}
+	return profiles, nil
+}
+
+// loadedProfileNames returns the names of all the profiles loaded into the
+// kernel.
+func loadedProfileNames() ([]string, error) {
 	file, err := os.Open(profilesPath)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
-	var profiles []string
+	var names []string
 	for {
 		var name, mode string
 		n, err := fmt.Fscanf(file, "%s %s\n", &name, &mode)
@@ -198,9 +264,7 @@ func LoadedProfiles() ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
-		if strings.HasPrefix(name, "snap.") {
-			profiles = append(profiles, name)
-		}
+		names = append(names, name)
 	}
-	return profiles, nil
+	return names, nil
 }
@@ -24,6 +24,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	. "gopkg.in/check.v1"
@@ -249,3 +250,95 @@ func (s *appArmorSuite) TestMaybeSetNumberOfJobs(c *C) {
 	cpus = 1
 	c.Check(apparmor.MaybeSetNumberOfJobs(), Equals, "")
 }
+
+func (s *appArmorSuite) TestLoadProfileGroups(c *C) {
+	restore := apparmor.MockRuntimeNumCPU(func() int { return 4 })
+	defer restore()
+
+	// parser calls run in parallel, record the arguments of each call
+	// next to the last profile it was given
+	cmd := testutil.MockCommand(c, "apparmor_parser", `
+for profile; do :; done
+echo "$@" > "$profile.args"
+case "$profile" in
+	*broken*) echo "cannot compile $profile"; exit 1;;
+esac
+`)
+	defer cmd.Restore()
+
+	d := c.MkDir()
+	groups := [][]string{
+		{filepath.Join(d, "snap.foo.app"), filepath.Join(d, "snap-update-ns.foo")},
+		{filepath.Join(d, "snap.broken.app")},
+		{filepath.Join(d, "snap.bar.app")},
+	}
+	errs := apparmor.LoadProfileGroups(groups, apparmor_sandbox.CacheDir, 0)
+	c.Assert(errs, HasLen, 3)
+	c.Check(errs[0], IsNil)
+	c.Check(errs[1], ErrorMatches, "cannot load apparmor profiles: exit status 1\napparmor_parser output:\ncannot compile .*/snap.broken.app\n")
+	c.Check(errs[2], IsNil)
+
+	for _, group := range groups {
+		last := group[len(group)-1]
+		c.Check(last+".args", testutil.FileEquals, "--replace --write-cache -O no-expr-simplify --cache-loc=/var/cache/apparmor -j1 --quiet "+strings.Join(group, " ")+"\n")
+	}
+}
+
+func (s *appArmorSuite) TestLoadProfileGroupsSingleCPU(c *C) {
+	restore := apparmor.MockRuntimeNumCPU(func() int { return 1 })
+	defer restore()
+
+	cmd := testutil.MockCommand(c, "apparmor_parser", "")
+	defer cmd.Restore()
+
+	errs := apparmor.LoadProfileGroups([][]string{{"/path/to/snap.foo.app"}, {"/path/to/snap.bar.app"}}, apparmor_sandbox.CacheDir, 0)
+	c.Check(errs, DeepEquals, []error{nil, nil})
+	// one group at a time, without limiting the parser jobs
+	c.Check(cmd.Calls(), DeepEquals, [][]string{
+		{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", "--cache-loc=/var/cache/apparmor", "--quiet", "/path/to/snap.foo.app"},
+		{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", "--cache-loc=/var/cache/apparmor", "--quiet", "/path/to/snap.bar.app"},
+	})
+}
+
+func (s *appArmorSuite) TestFilterLoadedProfiles(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+
+	d := c.MkDir()
+	foo := filepath.Join(d, "snap.foo.app")
+	bar := filepath.Join(d, "snap.bar.app")
+	baz := filepath.Join(d, "snap.baz.app")
+	for _, p := range []string{foo, bar, baz} {
+		c.Assert(ioutil.WriteFile(p, []byte("# profile of "+filepath.Base(p)), 0644), IsNil)
+	}
+
+	// nothing was recorded yet
+	c.Assert(ioutil.WriteFile(s.profilesFilename, []byte("snap.foo.app (enforce)\nsnap.bar.app (enforce)\n"), 0644), IsNil)
+	c.Check(apparmor.FilterLoadedProfiles([]string{foo, bar, baz}), DeepEquals, []string{foo, bar, baz})
+
+	apparmor.RecordLoadedProfiles([]string{foo, bar, baz}, nil)
+	c.Check(filepath.Join(dirs.SnapRunDir, "apparmor/loaded-profiles.json"), testutil.FilePresent)
+
+	// baz is not known to the kernel, eg. it was unloaded behind our back
+	c.Check(apparmor.FilterLoadedProfiles([]string{foo, bar, baz}), DeepEquals, []string{baz})
+
+	// bar changed since it was loaded
+	c.Assert(ioutil.WriteFile(bar, []byte("# new profile of snap.bar.app"), 0644), IsNil)
+	c.Check(apparmor.FilterLoadedProfiles([]string{foo, bar, baz}), DeepEquals, []string{bar, baz})
+
+	// foo is no longer recorded as loaded
+	apparmor.RecordLoadedProfiles(nil, []string{"snap.foo.app"})
+	c.Check(apparmor.FilterLoadedProfiles([]string{foo, bar, baz}), DeepEquals, []string{foo, bar, baz})
+}
+
+func (s *appArmorSuite) TestFilterLoadedProfilesNoKernelProfiles(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+
+	foo := filepath.Join(c.MkDir(), "snap.foo.app")
+	c.Assert(ioutil.WriteFile(foo, []byte("# profile"), 0644), IsNil)
+	apparmor.RecordLoadedProfiles([]string{foo}, nil)
+
+	// the list of profiles in the kernel cannot be read
+	c.Check(apparmor.FilterLoadedProfiles([]string{foo}), DeepEquals, []string{foo})
+}
@@ -65,6 +65,7 @@ var (
 	isRootWritableOverlay = osutil.IsRootWritableOverlay
 	kernelFeatures        = apparmor_sandbox.KernelFeatures
 	parserFeatures        = apparmor_sandbox.ParserFeatures
+	parserMtime           = apparmor_sandbox.ParserMtime
 )
 
 // Backend is responsible for maintaining apparmor profiles for snaps and parts of snapd.
@@ -334,7 +335,8 @@ type profilePathsResults struct {
 	removed   []string
 }
 
-func (b *Backend) prepareProfiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (prof *profilePathsResults, err error) {
+// snapSpecification returns the apparmor specification of the given snap.
+func (b *Backend) snapSpecification(snapInfo *snap.Info, repo *interfaces.Repository) (*Specification, error) {
 	snapName := snapInfo.InstanceName()
 	spec, err := repo.SnapSpecification(b.Name(), snapName)
 	if err != nil {
@@ -347,6 +349,16 @@ func (b *Backend) prepareProfiles(snapInfo *snap.Info, opts interfaces.Confineme
 	// Add snippets derived from the layout definition.
 	spec.(*Specification).AddLayout(snapInfo)
 
+	return spec.(*Specification), nil
+}
+
+func (b *Backend) prepareProfiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (prof *profilePathsResults, err error) {
+	snapName := snapInfo.InstanceName()
+	spec, err := b.snapSpecification(snapInfo, repo)
+	if err != nil {
+		return nil, err
+	}
+
 	// core on classic is special
 	if snapName == "core" && release.OnClassic && apparmor_sandbox.ProbedLevel() != apparmor_sandbox.Unsupported {
 		if err := b.setupSnapConfineReexec(snapInfo); err != nil {
@@ -381,7 +393,7 @@ func (b *Backend) prepareProfiles(snapInfo *snap.Info, opts interfaces.Confineme
 	}
 
 	// Get the files that this snap should have
-	content, err := b.deriveContent(spec.(*Specification), snapInfo, opts)
+	content, err := b.deriveContent(spec, snapInfo, opts)
 	if err != nil {
 		return nil, fmt.Errorf("cannot obtain expected security files for snap %q: %s", snapName, err)
 	}
@@ -421,6 +433,20 @@ func (b *Backend) prepareProfiles(snapInfo *snap.Info, opts interfaces.Confineme
 	return &profilePathsResults{changed: changedPaths, removed: removedPaths, unchanged: unchangedPaths}, nil
 }
 
+// Profiles returns the apparmor profiles of the given snap, as Setup would
+// write them, without writing nor loading them.
+func (b *Backend) Profiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string][]byte, error) {
+	spec, err := b.snapSpecification(snapInfo, repo)
+	if err != nil {
+		return nil, err
+	}
+	content, err := b.deriveContent(spec, snapInfo, opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain expected security files for snap %q: %s", snapInfo.InstanceName(), err)
+	}
+	return interfaces.ProfilesFromContent(dirs.SnapAppArmorDir, content)
+}
+
 // Setup creates and loads apparmor profiles specific to a given snap.
 // The snap can be in developer mode to make security violations non-fatal to
 // the offending application process.
@@ -445,8 +471,12 @@ func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions,
 	timings.Run(tm, "load-profiles[changed]", fmt.Sprintf("load changed security profiles of snap %q", snapInfo.InstanceName()), func(nesttm timings.Measurer) {
 		errReloadChanged = loadProfiles(prof.changed, apparmor_sandbox.CacheDir, aaFlags)
 	})
+	if errReloadChanged == nil {
+		b.profilesLoaded(prof.changed)
+	}
 
-	// Load all unchanged profiles anyway. This ensures those are correct in
+	// Load all unchanged profiles anyway, unless they are known to be in the
+	// kernel with the same content already. This ensures those are correct in
 	// the kernel even if the files on disk were not changed. We rely on
 	// apparmor cache to make this performant.
 	var errReloadOther error
@@ -454,10 +484,15 @@ func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions,
 	if b.preseed {
 		aaFlags |= skipKernelLoad
 	}
+	unchanged := b.profilesToReload(prof.unchanged)
 	timings.Run(tm, "load-profiles[unchanged]", fmt.Sprintf("load unchanged security profiles of snap %q", snapInfo.InstanceName()), func(nesttm timings.Measurer) {
-		errReloadOther = loadProfiles(prof.unchanged, apparmor_sandbox.CacheDir, aaFlags)
+		errReloadOther = loadProfiles(unchanged, apparmor_sandbox.CacheDir, aaFlags)
 	})
+	if errReloadOther == nil {
+		b.profilesLoaded(unchanged)
+	}
 	errUnload := unloadProfiles(prof.removed, apparmor_sandbox.CacheDir)
+	b.profilesUnloaded(prof.removed)
 	if errReloadChanged != nil {
 		return errReloadChanged
 	}
@@ -467,6 +502,32 @@ func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions,
 	return errUnload
 }
 
+// profilesToReload returns the given unchanged profiles that need to be
+// loaded into the kernel again.
+func (b *Backend) profilesToReload(unchanged []string) []string {
+	if b.preseed {
+		return unchanged
+	}
+	return filterLoadedProfiles(unchanged)
+}
+
+// profilesLoaded records the given profiles as loaded into the kernel.
+func (b *Backend) profilesLoaded(paths []string) {
+	if b.preseed {
+		return
+	}
+	recordLoadedProfiles(paths, nil)
+}
+
+// profilesUnloaded records the given profiles as no longer loaded into the
+// kernel.
+func (b *Backend) profilesUnloaded(names []string) {
+	if b.preseed {
+		return
+	}
+	recordLoadedProfiles(nil, names)
+}
+
 // SetupMany creates and loads apparmor profiles for multiple snaps.
 // The snaps can be in developer mode to make security violations non-fatal to
 // the offending application process.
@@ -475,7 +536,9 @@ func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions,
 //
 // This method is useful mainly for regenerating profiles.
 func (b *Backend) SetupMany(snaps []*snap.Info, confinement func(snapName string) interfaces.ConfinementOptions, repo *interfaces.Repository, tm timings.Measurer) []error {
-	var allChangedPaths, allUnchangedPaths, allRemovedPaths []string
+	var changedGroups [][]string
+	var changedSnaps []*snap.Info
+	var allUnchangedPaths, allRemovedPaths []string
 	var fallback bool
 	for _, snapInfo := range snaps {
 		opts := confinement(snapInfo.InstanceName())
@@ -484,35 +547,53 @@ func (b *Backend) SetupMany(snaps []*snap.Info, confinement func(snapName string
 			fallback = true
 			break
 		}
-		allChangedPaths = append(allChangedPaths, prof.changed...)
+		if len(prof.changed) > 0 {
+			changedGroups = append(changedGroups, prof.changed)
+			changedSnaps = append(changedSnaps, snapInfo)
+		}
 		allUnchangedPaths = append(allUnchangedPaths, prof.unchanged...)
 		allRemovedPaths = append(allRemovedPaths, prof.removed...)
 	}
 
+	// snaps whose changed profiles failed to load, those are re-tried one
+	// by one
+	var failed []*snap.Info
 	if !fallback {
+		// Changed profiles are compiled by a bounded pool of
+		// apparmor_parser processes, one snap at a time, so that a snap
+		// with broken profiles does not affect the others.
 		aaFlags := skipReadCache | conserveCPU
 		if b.preseed {
 			aaFlags |= skipKernelLoad
 		}
-		var errReloadChanged error
+		var errsReloadChanged []error
 		timings.Run(tm, "load-profiles[changed-many]", fmt.Sprintf("load changed security profiles of %d snaps", len(snaps)), func(nesttm timings.Measurer) {
-			errReloadChanged = loadProfiles(allChangedPaths, apparmor_sandbox.CacheDir, aaFlags)
+			errsReloadChanged = loadProfileGroups(changedGroups, apparmor_sandbox.CacheDir, aaFlags)
 		})
+		for i, err := range errsReloadChanged {
+			if err != nil {
+				logger.Noticef("failed to reload changed profiles of snap %q: %s", changedSnaps[i].InstanceName(), err)
+				failed = append(failed, changedSnaps[i])
+				continue
+			}
+			b.profilesLoaded(changedGroups[i])
+		}
 
 		aaFlags = conserveCPU
 		if b.preseed {
 			aaFlags |= skipKernelLoad
 		}
+		unchanged := b.profilesToReload(allUnchangedPaths)
 		var errReloadOther error
 		timings.Run(tm, "load-profiles[unchanged-many]", fmt.Sprintf("load unchanged security profiles %d snaps", len(snaps)), func(nesttm timings.Measurer) {
-			errReloadOther = loadProfiles(allUnchangedPaths, apparmor_sandbox.CacheDir, aaFlags)
+			errReloadOther = loadProfiles(unchanged, apparmor_sandbox.CacheDir, aaFlags)
 		})
+		if errReloadOther == nil {
+			b.profilesLoaded(unchanged)
+		}
 
 		errUnload := unloadProfiles(allRemovedPaths, apparmor_sandbox.CacheDir)
-		if errReloadChanged != nil {
-			logger.Noticef("failed to batch-reload changed profiles: %s", errReloadChanged)
-			fallback = true
-		}
+		b.profilesUnloaded(allRemovedPaths)
 		if errReloadOther != nil {
 			logger.Noticef("failed to batch-reload unchanged profiles: %s", errReloadOther)
 			fallback = true
@@ -524,13 +605,16 @@ func (b *Backend) SetupMany(snaps []*snap.Info, confinement func(snapName string
 	}
 
 	var errors []error
-	// if an error was encountered when processing all profiles at once, re-try them one by one
+	// if an error was encountered when processing all profiles at once,
+	// re-try them one by one, otherwise re-try only the snaps whose changed
+	// profiles failed to load
 	if fallback {
-		for _, snapInfo := range snaps {
-			opts := confinement(snapInfo.InstanceName())
-			if err := b.Setup(snapInfo, opts, repo, tm); err != nil {
-				errors = append(errors, fmt.Errorf("cannot setup profiles for snap %q: %s", snapInfo.InstanceName(), err))
-			}
+		failed = snaps
+	}
+	for _, snapInfo := range failed {
+		opts := confinement(snapInfo.InstanceName())
+		if err := b.Setup(snapInfo, opts, repo, tm); err != nil {
+			errors = append(errors, fmt.Errorf("cannot setup profiles for snap %q: %s", snapInfo.InstanceName(), err))
 		}
 	}
 	return errors
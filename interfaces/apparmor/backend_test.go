@@ -300,6 +300,67 @@ func (s *backendSuite) TestProfilesAreAlwaysLoaded(c *C) {
 	}
 }
 
+func (s *backendSuite) TestUnchangedLoadedProfilesAreSkipped(c *C) {
+	profiles := filepath.Join(c.MkDir(), "profiles")
+	apparmor.MockProfilesPath(&s.BaseTest, profiles)
+	c.Assert(ioutil.WriteFile(profiles, []byte("snap-update-ns.samba (enforce)\nsnap.samba.smbd (enforce)\n"), 0644), IsNil)
+
+	for _, opts := range testedConfinementOpts {
+		snapInfo := s.InstallSnap(c, opts, "", ifacetest.SambaYamlV1, 1)
+		s.parserCmd.ForgetCalls()
+		// the profiles were loaded when installing the snap and are
+		// still in the kernel
+		err := s.Backend.Setup(snapInfo, opts, s.Repo, s.meas)
+		c.Assert(err, IsNil)
+		c.Check(s.parserCmd.Calls(), HasLen, 0)
+
+		// a profile that changed on disk behind our back is loaded again
+		profile := filepath.Join(dirs.SnapAppArmorDir, "snap.samba.smbd")
+		c.Assert(os.Remove(profile), IsNil)
+		err = s.Backend.Setup(snapInfo, opts, s.Repo, s.meas)
+		c.Assert(err, IsNil)
+		c.Check(s.parserCmd.Calls(), DeepEquals, [][]string{
+			{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s/var/cache/apparmor", s.RootDir), "--skip-read-cache", "--quiet", profile},
+		})
+		s.RemoveSnap(c, snapInfo)
+	}
+}
+
+func (s *backendSuite) TestLoadedProfilesAreReloadedWithNewParser(c *C) {
+	profiles := filepath.Join(c.MkDir(), "profiles")
+	apparmor.MockProfilesPath(&s.BaseTest, profiles)
+	c.Assert(ioutil.WriteFile(profiles, []byte("snap-update-ns.samba (enforce)\nsnap.samba.smbd (enforce)\n"), 0644), IsNil)
+
+	opts := interfaces.ConfinementOptions{}
+	snapInfo := s.InstallSnap(c, opts, "", ifacetest.SambaYamlV1, 1)
+	defer s.RemoveSnap(c, snapInfo)
+	updateNSProfile := filepath.Join(dirs.SnapAppArmorDir, "snap-update-ns.samba")
+	profile := filepath.Join(dirs.SnapAppArmorDir, "snap.samba.smbd")
+
+	// the parser was updated
+	restore := apparmor.MockParserMtime(func() int64 { return 1234 })
+	defer restore()
+	s.parserCmd.ForgetCalls()
+	err := s.Backend.Setup(snapInfo, opts, s.Repo, s.meas)
+	c.Assert(err, IsNil)
+	c.Check(s.parserCmd.Calls(), HasLen, 1)
+	c.Check(s.parserCmd.Calls()[0][len(s.parserCmd.Calls()[0])-2:], DeepEquals, []string{updateNSProfile, profile})
+
+	// the parser supports new features
+	restore = apparmor.MockParserFeatures(func() ([]string, error) { return []string{"new-feature"}, nil })
+	defer restore()
+	s.parserCmd.ForgetCalls()
+	err = s.Backend.Setup(snapInfo, opts, s.Repo, s.meas)
+	c.Assert(err, IsNil)
+	c.Check(s.parserCmd.Calls(), HasLen, 1)
+
+	// nothing changed since
+	s.parserCmd.ForgetCalls()
+	err = s.Backend.Setup(snapInfo, opts, s.Repo, s.meas)
+	c.Assert(err, IsNil)
+	c.Check(s.parserCmd.Calls(), HasLen, 0)
+}
+
 func (s *backendSuite) TestRemovingSnapRemovesAndUnloadsProfiles(c *C) {
 	for _, opts := range testedConfinementOpts {
 		snapInfo := s.InstallSnap(c, opts, "", ifacetest.SambaYamlV1, 1)
@@ -473,15 +534,21 @@ func (s *backendSuite) TestSetupManyProfilesWithChanged(c *C) {
 		c.Assert(ioutil.WriteFile(snap1AAprofile, []byte("# an outdated profile"), 0644), IsNil)
 		c.Assert(ioutil.WriteFile(snap2AAprofile, []byte("# an outdated profile"), 0644), IsNil)
 
+		// with two CPUs the changed profiles are loaded one snap at a
+		// time, keeping the order of parser calls predictable
+		restore := apparmor.MockRuntimeNumCPU(func() int { return 2 })
 		setupManyInterface, ok := s.Backend.(interfaces.SecurityBackendSetupMany)
 		c.Assert(ok, Equals, true)
 		err := setupManyInterface.SetupMany([]*snap.Info{snapInfo1, snapInfo2}, func(snapName string) interfaces.ConfinementOptions { return opts }, s.Repo, s.meas)
+		restore()
 		c.Assert(err, IsNil)
 
-		// expect two batch executions - one for changed profiles, second for unchanged profiles.
+		// expect one execution per snap with changed profiles, then a
+		// batch execution for unchanged profiles.
 		c.Check(s.parserCmd.Calls(), DeepEquals, [][]string{
-			{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s/var/cache/apparmor", s.RootDir), "-j97", "--skip-read-cache", "--quiet", snap1AAprofile, snap2AAprofile},
-			{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s/var/cache/apparmor", s.RootDir), "-j97", "--quiet", snap1nsProfile, snap2nsProfile},
+			{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s/var/cache/apparmor", s.RootDir), "-j1", "--skip-read-cache", "--quiet", snap1AAprofile},
+			{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s/var/cache/apparmor", s.RootDir), "-j1", "--skip-read-cache", "--quiet", snap2AAprofile},
+			{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s/var/cache/apparmor", s.RootDir), "-j1", "--quiet", snap1nsProfile, snap2nsProfile},
 		})
 		s.RemoveSnap(c, snapInfo1)
 		s.RemoveSnap(c, snapInfo2)
@@ -2335,15 +2402,21 @@ func (s *backendSuite) TestSetupManyInPreseedMode(c *C) {
 		c.Assert(ioutil.WriteFile(snap1AAprofile, []byte("# an outdated profile"), 0644), IsNil)
 		c.Assert(ioutil.WriteFile(snap2AAprofile, []byte("# an outdated profile"), 0644), IsNil)
 
+		// with two CPUs the changed profiles are loaded one snap at a
+		// time, keeping the order of parser calls predictable
+		restore := apparmor.MockRuntimeNumCPU(func() int { return 2 })
 		setupManyInterface, ok := s.Backend.(interfaces.SecurityBackendSetupMany)
 		c.Assert(ok, Equals, true)
 		err := setupManyInterface.SetupMany([]*snap.Info{snapInfo1, snapInfo2}, func(snapName string) interfaces.ConfinementOptions { return opts }, s.Repo, s.meas)
+		restore()
 		c.Assert(err, IsNil)
 
-		// expect two batch executions - one for changed profiles, second for unchanged profiles.
+		// expect one execution per snap with changed profiles, then a
+		// batch execution for unchanged profiles.
 		c.Check(s.parserCmd.Calls(), DeepEquals, [][]string{
-			{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s/var/cache/apparmor", s.RootDir), "-j97", "--skip-kernel-load", "--skip-read-cache", "--quiet", snap1AAprofile, snap2AAprofile},
-			{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s/var/cache/apparmor", s.RootDir), "-j97", "--skip-kernel-load", "--quiet", snap1nsProfile, snap2nsProfile},
+			{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s/var/cache/apparmor", s.RootDir), "-j1", "--skip-kernel-load", "--skip-read-cache", "--quiet", snap1AAprofile},
+			{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s/var/cache/apparmor", s.RootDir), "-j1", "--skip-kernel-load", "--skip-read-cache", "--quiet", snap2AAprofile},
+			{"apparmor_parser", "--replace", "--write-cache", "-O", "no-expr-simplify", fmt.Sprintf("--cache-loc=%s/var/cache/apparmor", s.RootDir), "-j1", "--skip-kernel-load", "--quiet", snap1nsProfile, snap2nsProfile},
 		})
 		s.RemoveSnap(c, snapInfo1)
 		s.RemoveSnap(c, snapInfo2)
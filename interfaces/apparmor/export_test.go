@@ -32,6 +32,9 @@ var (
 	SnapConfineFromSnapProfile      = snapConfineFromSnapProfile
 	DowngradeConfinement            = downgradeConfinement
 	LoadProfiles                    = loadProfiles
+	LoadProfileGroups               = loadProfileGroups
+	FilterLoadedProfiles            = filterLoadedProfiles
+	RecordLoadedProfiles            = recordLoadedProfiles
 	UnloadProfiles                  = unloadProfiles
 	MaybeSetNumberOfJobs            = maybeSetNumberOfJobs
 	DefaultCoreRuntimeTemplateRules = defaultCoreRuntimeTemplateRules
@@ -103,6 +106,14 @@ func MockKernelFeatures(f func() ([]string, error)) (resture func()) {
 	}
 }
 
+func MockParserMtime(f func() int64) (restore func()) {
+	old := parserMtime
+	parserMtime = f
+	return func() {
+		parserMtime = old
+	}
+}
+
 func MockParserFeatures(f func() ([]string, error)) (resture func()) {
 	old := parserFeatures
 	parserFeatures = f
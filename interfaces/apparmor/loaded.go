@@ -0,0 +1,167 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package apparmor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+)
+
+// loadedProfilesMu serializes updates of the loaded profiles file, as
+// profiles of different snaps may be set up concurrently.
+var loadedProfilesMu sync.Mutex
+
+// loadedProfilesFile returns the path of the file recording the content
+// hashes of the profiles loaded into the kernel by snapd. The file is kept
+// in /run so that it does not outlive the kernel the profiles were loaded
+// into.
+func loadedProfilesFile() string {
+	return filepath.Join(dirs.SnapRunDir, "apparmor", "loaded-profiles.json")
+}
+
+func readLoadedProfileHashes() map[string]string {
+	hashes := make(map[string]string)
+	data, err := ioutil.ReadFile(loadedProfilesFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Noticef("cannot read loaded apparmor profiles: %v", err)
+		}
+		return hashes
+	}
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		logger.Noticef("cannot decode loaded apparmor profiles: %v", err)
+		return make(map[string]string)
+	}
+	return hashes
+}
+
+// parserEnvironment returns a description of the apparmor parser and of the
+// kernel the profiles are compiled for. A profile loaded by a different
+// parser, or with different features, may be compiled differently.
+func parserEnvironment() []byte {
+	kFeatures, _ := kernelFeatures()
+	pFeatures, _ := parserFeatures()
+	return []byte(fmt.Sprintf("kernel-features: %s\nparser-features: %s\nparser-mtime: %d\n",
+		strings.Join(kFeatures, " "), strings.Join(pFeatures, " "), parserMtime()))
+}
+
+// hashProfileFiles returns the hashes of the given profile files, indexed by
+// profile name. The hashes cover the content of the files and the parser
+// environment.
+func hashProfileFiles(paths []string) (map[string]string, error) {
+	env := parserEnvironment()
+	hashes := make(map[string]string, len(paths))
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		h.Write(env)
+		h.Write(content)
+		hashes[filepath.Base(path)] = hex.EncodeToString(h.Sum(nil))
+	}
+	return hashes, nil
+}
+
+// recordLoadedProfiles updates the content hashes of the profiles loaded
+// into the kernel, with the given profile files that were just loaded and
+// the names of the profiles that were just removed.
+func recordLoadedProfiles(loadedPaths []string, removedNames []string) {
+	if len(loadedPaths) == 0 && len(removedNames) == 0 {
+		return
+	}
+	loaded, err := hashProfileFiles(loadedPaths)
+	if err != nil {
+		logger.Noticef("cannot record loaded apparmor profiles: %v", err)
+		return
+	}
+
+	loadedProfilesMu.Lock()
+	defer loadedProfilesMu.Unlock()
+
+	hashes := readLoadedProfileHashes()
+	for name, hash := range loaded {
+		hashes[name] = hash
+	}
+	for _, name := range removedNames {
+		delete(hashes, name)
+	}
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		logger.Noticef("cannot record loaded apparmor profiles: %v", err)
+		return
+	}
+	fname := loadedProfilesFile()
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		logger.Noticef("cannot record loaded apparmor profiles: %v", err)
+		return
+	}
+	if err := osutil.AtomicWriteFile(fname, data, 0644, 0); err != nil {
+		logger.Noticef("cannot record loaded apparmor profiles: %v", err)
+	}
+}
+
+// filterLoadedProfiles returns the profile files out of the given ones that
+// may need loading into the kernel. Profiles that snapd loaded earlier with
+// the same content and parser environment and that the kernel still knows
+// about are left out.
+func filterLoadedProfiles(paths []string) []string {
+	if len(paths) == 0 {
+		return paths
+	}
+	names, err := loadedProfileNames()
+	if err != nil {
+		return paths
+	}
+	inKernel := make(map[string]bool, len(names))
+	for _, name := range names {
+		inKernel[name] = true
+	}
+	current, err := hashProfileFiles(paths)
+	if err != nil {
+		return paths
+	}
+
+	loadedProfilesMu.Lock()
+	recorded := readLoadedProfileHashes()
+	loadedProfilesMu.Unlock()
+
+	toLoad := make([]string, 0, len(paths))
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if inKernel[name] && recorded[name] != "" && recorded[name] == current[name] {
+			continue
+		}
+		toLoad = append(toLoad, path)
+	}
+	return toLoad
+}
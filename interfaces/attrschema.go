@@ -0,0 +1,187 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/strutil"
+)
+
+// AttrType is the type of the value of a plug or slot attribute.
+type AttrType string
+
+const (
+	// AttrString is a string attribute.
+	AttrString AttrType = "string"
+	// AttrBool is a boolean attribute.
+	AttrBool AttrType = "bool"
+	// AttrInt is an integer attribute.
+	AttrInt AttrType = "int"
+	// AttrStringList is an attribute holding a list of strings.
+	AttrStringList AttrType = "list"
+	// AttrMap is an attribute holding a map, with keys described by the
+	// Attrs of its AttrSpec.
+	AttrMap AttrType = "map"
+)
+
+// AttrPath is a constraint on string attributes holding paths.
+type AttrPath string
+
+const (
+	// AttrPathClean requires a clean path that does not point outside of
+	// the directory it is relative to.
+	AttrPathClean AttrPath = "clean"
+	// AttrPathAbsolute requires a clean absolute path.
+	AttrPathAbsolute AttrPath = "absolute"
+)
+
+// AttrSpec describes a single plug or slot attribute.
+type AttrSpec struct {
+	Type     AttrType `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	// Enum lists the values allowed for a string attribute, any value
+	// is allowed if empty.
+	Enum []string `json:"enum,omitempty"`
+	// Path constrains the strings of a string or list attribute holding
+	// paths.
+	Path AttrPath `json:"path,omitempty"`
+	// Attrs describes the keys of a map attribute.
+	Attrs AttrSchema `json:"attrs,omitempty"`
+}
+
+// AttrSchema describes the attributes accepted by the plugs or the slots of
+// an interface, indexed by attribute name.
+type AttrSchema map[string]AttrSpec
+
+// Validate checks the given static attributes against the schema. Attributes
+// not in the schema are ignored, snaps in the wild may carry attributes that
+// were never used by the interface.
+func (schema AttrSchema) Validate(attrs map[string]interface{}) error {
+	return schema.validate(attrs, "", false)
+}
+
+// ValidateStrict is like Validate but also rejects attributes not in the
+// schema, typically misspelled ones.
+func (schema AttrSchema) ValidateStrict(attrs map[string]interface{}) error {
+	return schema.validate(attrs, "", true)
+}
+
+func (schema AttrSchema) validate(attrs map[string]interface{}, prefix string, strict bool) error {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		spec, ok := schema[name]
+		if !ok {
+			if strict {
+				return fmt.Errorf("unknown attribute %q", prefix+name)
+			}
+			continue
+		}
+		if err := spec.validate(attrs[name], prefix+name, strict); err != nil {
+			return err
+		}
+	}
+
+	required := make([]string, 0, len(schema))
+	for name, spec := range schema {
+		if _, ok := attrs[name]; spec.Required && !ok {
+			required = append(required, name)
+		}
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		return fmt.Errorf("required attribute %q is missing", prefix+required[0])
+	}
+	return nil
+}
+
+func (spec *AttrSpec) validate(value interface{}, name string, strict bool) error {
+	switch spec.Type {
+	case AttrString:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("attribute %q must be a string", name)
+		}
+		if len(spec.Enum) > 0 && !strutil.ListContains(spec.Enum, s) {
+			return fmt.Errorf("attribute %q must be one of %s", name, strutil.Quoted(spec.Enum))
+		}
+		return spec.validatePath(s, name)
+	case AttrBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("attribute %q must be a boolean", name)
+		}
+	case AttrInt:
+		switch value.(type) {
+		case int, int64:
+		default:
+			return fmt.Errorf("attribute %q must be an integer", name)
+		}
+	case AttrStringList:
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("attribute %q must be a list of strings", name)
+		}
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("attribute %q must be a list of strings", name)
+			}
+			if err := spec.validatePath(s, name); err != nil {
+				return err
+			}
+		}
+	case AttrMap:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("attribute %q must be a map", name)
+		}
+		if spec.Attrs != nil {
+			return spec.Attrs.validate(m, name+".", strict)
+		}
+	default:
+		return fmt.Errorf("internal error: attribute %q has unknown type %q", name, spec.Type)
+	}
+	return nil
+}
+
+func (spec *AttrSpec) validatePath(path, name string) error {
+	switch spec.Path {
+	case "":
+		return nil
+	case AttrPathClean:
+		if filepath.Clean(path) != path || path == ".." || strings.HasPrefix(path, "../") {
+			return fmt.Errorf("attribute %q path is not clean: %q", name, path)
+		}
+	case AttrPathAbsolute:
+		if filepath.Clean(path) != path || !filepath.IsAbs(path) {
+			return fmt.Errorf("attribute %q must be a clean absolute path: %q", name, path)
+		}
+	default:
+		return fmt.Errorf("internal error: attribute %q has unknown path constraint %q", name, spec.Path)
+	}
+	return nil
+}
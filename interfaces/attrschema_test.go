@@ -0,0 +1,109 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	. "github.com/snapcore/snapd/interfaces"
+)
+
+type attrSchemaSuite struct{}
+
+var _ = Suite(&attrSchemaSuite{})
+
+var testSchema = AttrSchema{
+	"name":    {Type: AttrString, Required: true},
+	"mode":    {Type: AttrString, Enum: []string{"ro", "rw"}},
+	"target":  {Type: AttrString, Path: AttrPathClean},
+	"device":  {Type: AttrString, Path: AttrPathAbsolute},
+	"enabled": {Type: AttrBool},
+	"count":   {Type: AttrInt},
+	"paths":   {Type: AttrStringList, Path: AttrPathClean},
+	"source": {Type: AttrMap, Attrs: AttrSchema{
+		"read": {Type: AttrStringList, Required: true},
+	}},
+	"extra": {Type: AttrMap},
+}
+
+func (s *attrSchemaSuite) TestValidateHappy(c *C) {
+	for _, attrs := range []map[string]interface{}{
+		{"name": "foo"},
+		{
+			"name":    "foo",
+			"mode":    "ro",
+			"target":  "$SNAP/foo",
+			"device":  "/dev/ttyS0",
+			"enabled": true,
+			"count":   int64(3),
+			"paths":   []interface{}{"a", "b/c"},
+			"source":  map[string]interface{}{"read": []interface{}{"d"}},
+			"extra":   map[string]interface{}{"anything": 1},
+		},
+		{"name": "foo", "count": 3},
+	} {
+		c.Check(testSchema.Validate(attrs), IsNil, Commentf("%v", attrs))
+	}
+}
+
+func (s *attrSchemaSuite) TestValidateUnhappy(c *C) {
+	for _, t := range []struct {
+		attrs map[string]interface{}
+		err   string
+	}{
+		{map[string]interface{}{}, `required attribute "name" is missing`},
+		{map[string]interface{}{"name": 1}, `attribute "name" must be a string`},
+		{map[string]interface{}{"name": "foo", "mode": "wo"}, `attribute "mode" must be one of "ro", "rw"`},
+		{map[string]interface{}{"name": "foo", "target": "../foo"}, `attribute "target" path is not clean: "../foo"`},
+		{map[string]interface{}{"name": "foo", "target": "foo//bar"}, `attribute "target" path is not clean: "foo//bar"`},
+		{map[string]interface{}{"name": "foo", "device": "dev/ttyS0"}, `attribute "device" must be a clean absolute path: "dev/ttyS0"`},
+		{map[string]interface{}{"name": "foo", "enabled": "yes"}, `attribute "enabled" must be a boolean`},
+		{map[string]interface{}{"name": "foo", "count": "3"}, `attribute "count" must be an integer`},
+		{map[string]interface{}{"name": "foo", "paths": "a"}, `attribute "paths" must be a list of strings`},
+		{map[string]interface{}{"name": "foo", "paths": []interface{}{1}}, `attribute "paths" must be a list of strings`},
+		{map[string]interface{}{"name": "foo", "paths": []interface{}{"/a/.."}}, `attribute "paths" path is not clean: "/a/.."`},
+		{map[string]interface{}{"name": "foo", "source": "a"}, `attribute "source" must be a map`},
+		{map[string]interface{}{"name": "foo", "source": map[string]interface{}{}}, `required attribute "source.read" is missing`},
+	} {
+		c.Check(testSchema.Validate(t.attrs), ErrorMatches, t.err, Commentf("%v", t.attrs))
+		c.Check(testSchema.ValidateStrict(t.attrs), ErrorMatches, t.err, Commentf("%v", t.attrs))
+	}
+}
+
+func (s *attrSchemaSuite) TestValidateUnknownAttrs(c *C) {
+	for _, t := range []struct {
+		attrs map[string]interface{}
+		err   string
+	}{
+		{map[string]interface{}{"name": "foo", "taget": "foo"}, `unknown attribute "taget"`},
+		{map[string]interface{}{"name": "foo", "source": map[string]interface{}{"read": []interface{}{}, "rwite": 1}}, `unknown attribute "source.rwite"`},
+	} {
+		c.Check(testSchema.Validate(t.attrs), IsNil, Commentf("%v", t.attrs))
+		c.Check(testSchema.ValidateStrict(t.attrs), ErrorMatches, t.err, Commentf("%v", t.attrs))
+	}
+}
+
+func (s *attrSchemaSuite) TestValidateInternalErrors(c *C) {
+	schema := AttrSchema{"foo": {Type: "float"}}
+	c.Check(schema.Validate(map[string]interface{}{"foo": 1.0}), ErrorMatches, `internal error: attribute "foo" has unknown type "float"`)
+
+	schema = AttrSchema{"foo": {Type: AttrString, Path: "relative"}}
+	c.Check(schema.Validate(map[string]interface{}{"foo": "bar"}), ErrorMatches, `internal error: attribute "foo" has unknown path constraint "relative"`)
+}
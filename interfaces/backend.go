@@ -20,6 +20,10 @@
 package interfaces
 
 import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/timings"
 )
@@ -111,3 +115,30 @@ type SecurityBackendSetupMany interface {
 	// on errors of individual snaps.
 	SetupMany(snaps []*snap.Info, confinement func(snapName string) ConfinementOptions, repo *Repository, tm timings.Measurer) []error
 }
+
+// SecurityBackendProfiles interface may be implemented by backends that can
+// render the security artefacts of a snap without writing them to disk.
+type SecurityBackendProfiles interface {
+	// Profiles returns the content of the files Setup would write for the
+	// given snap, indexed by their path.
+	Profiles(snapInfo *snap.Info, opts ConfinementOptions, repo *Repository) (map[string][]byte, error)
+}
+
+// ProfilesFromContent returns the content of the given files, as computed
+// for osutil.EnsureDirState in the given directory, indexed by their path.
+func ProfilesFromContent(dir string, content map[string]osutil.FileState) (map[string][]byte, error) {
+	profiles := make(map[string][]byte, len(content))
+	for name, fileState := range content {
+		r, _, _, err := fileState.State()
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		profiles[filepath.Join(dir, name)] = data
+	}
+	return profiles, nil
+}
@@ -26,11 +26,14 @@ import (
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/dbus"
 	"github.com/snapcore/snapd/interfaces/kmod"
+	"github.com/snapcore/snapd/interfaces/landlock"
 	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/interfaces/nftables"
 	"github.com/snapcore/snapd/interfaces/seccomp"
 	"github.com/snapcore/snapd/interfaces/systemd"
 	"github.com/snapcore/snapd/interfaces/udev"
 	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
+	landlock_sandbox "github.com/snapcore/snapd/sandbox/landlock"
 )
 
 var All []interfaces.SecurityBackend = backends()
@@ -45,6 +48,7 @@ func backends() []interfaces.SecurityBackend {
 		&udev.Backend{},
 		&mount.Backend{},
 		&kmod.Backend{},
+		&nftables.Backend{},
 	}
 
 	// TODO use something like:
@@ -69,9 +73,17 @@ func backends() []interfaces.SecurityBackend {
 	//
 	// When some features are missing the backend will generate more permissive
 	// profiles that keep applications operational, in forced-devmode.
+	//
+	// Without AppArmor, the landlock backend is enabled if the kernel
+	// supports it so that snaps are still restricted in what they can
+	// access on the filesystem.
 	switch apparmor_sandbox.ProbedLevel() {
 	case apparmor_sandbox.Partial, apparmor_sandbox.Full:
 		all = append(all, &apparmor.Backend{})
+	default:
+		if landlock_sandbox.ABI() > 0 {
+			all = append(all, &landlock.Backend{})
+		}
 	}
 	return all
 }
@@ -24,6 +24,7 @@ import (
 
 	"github.com/snapcore/snapd/interfaces/backends"
 	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
+	landlock_sandbox "github.com/snapcore/snapd/sandbox/landlock"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -55,6 +56,35 @@ func (s *backendsSuite) TestIsAppArmorEnabled(c *C) {
 	}
 }
 
+func (s *backendsSuite) TestIsLandlockEnabled(c *C) {
+	for _, t := range []struct {
+		level   apparmor_sandbox.LevelType
+		abi     int
+		enabled bool
+	}{
+		{apparmor_sandbox.Unsupported, 0, false},
+		{apparmor_sandbox.Unsupported, 1, true},
+		{apparmor_sandbox.Unusable, 3, true},
+		{apparmor_sandbox.Partial, 3, false},
+		{apparmor_sandbox.Full, 3, false},
+	} {
+		restore := apparmor_sandbox.MockLevel(t.level)
+		defer restore()
+		restore = landlock_sandbox.MockABI(t.abi)
+		defer restore()
+
+		var names []string
+		for _, backend := range backends.Backends() {
+			names = append(names, string(backend.Name()))
+		}
+		if t.enabled {
+			c.Check(names, testutil.Contains, "landlock", Commentf("%v", t))
+		} else {
+			c.Check(names, Not(testutil.Contains), "landlock", Commentf("%v", t))
+		}
+	}
+}
+
 func (s *backendsSuite) TestEssentialOrdering(c *C) {
 	restore := apparmor_sandbox.MockLevel(apparmor_sandbox.Full)
 	defer restore()
@@ -74,3 +104,16 @@ func (s *backendsSuite) TestEssentialOrdering(c *C) {
 	c.Assert(sdIndex, testutil.IntNotEqual, -1)
 	c.Assert(sdIndex, testutil.IntLessThan, aaIndex)
 }
+
+func (s *backendsSuite) TestNftablesAlwaysEnabled(c *C) {
+	for _, level := range []apparmor_sandbox.LevelType{apparmor_sandbox.Unsupported, apparmor_sandbox.Full} {
+		restore := apparmor_sandbox.MockLevel(level)
+		defer restore()
+
+		var names []string
+		for _, backend := range backends.Backends() {
+			names = append(names, string(backend.Name()))
+		}
+		c.Check(names, testutil.Contains, "nftables")
+	}
+}
@@ -29,6 +29,7 @@ import (
 
 func init() {
 	snap.SanitizePlugsSlots = SanitizePlugsSlots
+	snap.ValidatePlugsSlotsAttrs = ValidatePlugsSlotsAttrs
 }
 
 var (
@@ -56,6 +57,57 @@ func registerIface(iface interfaces.Interface) {
 	allInterfaces[iface.Name()] = iface
 }
 
+// ValidatePlugsSlotsAttrs checks the attributes of the plugs and slots of the
+// given snap against the attribute schema of their interfaces, if any. Unlike
+// SanitizePlugsSlots, which drops plugs and slots with bad attributes, it
+// fails on the first one. Attributes unknown to the interfaces are only
+// rejected if strict is set.
+func ValidatePlugsSlotsAttrs(snapInfo *snap.Info, strict bool) error {
+	plugNames := make([]string, 0, len(snapInfo.Plugs))
+	for plugName := range snapInfo.Plugs {
+		plugNames = append(plugNames, plugName)
+	}
+	sort.Strings(plugNames)
+	for _, plugName := range plugNames {
+		plugInfo := snapInfo.Plugs[plugName]
+		iface, ok := allInterfaces[plugInfo.Interface]
+		if !ok {
+			continue
+		}
+		if schema := interfaces.StaticInfoOf(iface).PlugAttrs; schema != nil {
+			if err := validateAttrs(schema, plugInfo.Attrs, strict); err != nil {
+				return fmt.Errorf("invalid plug %q (interface %q): %v", plugName, plugInfo.Interface, err)
+			}
+		}
+	}
+
+	slotNames := make([]string, 0, len(snapInfo.Slots))
+	for slotName := range snapInfo.Slots {
+		slotNames = append(slotNames, slotName)
+	}
+	sort.Strings(slotNames)
+	for _, slotName := range slotNames {
+		slotInfo := snapInfo.Slots[slotName]
+		iface, ok := allInterfaces[slotInfo.Interface]
+		if !ok {
+			continue
+		}
+		if schema := interfaces.StaticInfoOf(iface).SlotAttrs; schema != nil {
+			if err := validateAttrs(schema, slotInfo.Attrs, strict); err != nil {
+				return fmt.Errorf("invalid slot %q (interface %q): %v", slotName, slotInfo.Interface, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateAttrs(schema interfaces.AttrSchema, attrs map[string]interface{}, strict bool) error {
+	if strict {
+		return schema.ValidateStrict(attrs)
+	}
+	return schema.Validate(attrs)
+}
+
 func SanitizePlugsSlots(snapInfo *snap.Info) {
 	var badPlugs []string
 	var badSlots []string
@@ -73,6 +125,14 @@ func SanitizePlugsSlots(snapInfo *snap.Info) {
 			badPlugs = append(badPlugs, plugName)
 			continue
 		}
+		// Check attributes before the interface gets to normalize them
+		if schema := interfaces.StaticInfoOf(iface).PlugAttrs; schema != nil {
+			if err := schema.Validate(plugInfo.Attrs); err != nil {
+				snapInfo.BadInterfaces[plugName] = err.Error()
+				badPlugs = append(badPlugs, plugName)
+				continue
+			}
+		}
 		if err := interfaces.BeforePreparePlug(iface, plugInfo); err != nil {
 			snapInfo.BadInterfaces[plugName] = err.Error()
 			badPlugs = append(badPlugs, plugName)
@@ -93,6 +153,14 @@ func SanitizePlugsSlots(snapInfo *snap.Info) {
 			badSlots = append(badSlots, slotName)
 			continue
 		}
+		// Check attributes before the interface gets to normalize them
+		if schema := interfaces.StaticInfoOf(iface).SlotAttrs; schema != nil {
+			if err := schema.Validate(slotInfo.Attrs); err != nil {
+				snapInfo.BadInterfaces[slotName] = err.Error()
+				badSlots = append(badSlots, slotName)
+				continue
+			}
+		}
 		if err := interfaces.BeforePrepareSlot(iface, slotInfo); err != nil {
 			snapInfo.BadInterfaces[slotName] = err.Error()
 			badSlots = append(badSlots, slotName)
@@ -131,9 +199,14 @@ func SanitizePlugsSlots(snapInfo *snap.Info) {
 
 func MockInterface(iface interfaces.Interface) func() {
 	name := iface.Name()
+	old, ok := allInterfaces[name]
 	allInterfaces[name] = iface
 	return func() {
-		delete(allInterfaces, name)
+		if ok {
+			allInterfaces[name] = old
+		} else {
+			delete(allInterfaces, name)
+		}
 	}
 }
 
@@ -354,6 +354,49 @@ func (s *AllSuite) TestSanitizeErrorsOnInvalidPlugInterface(c *C) {
 	c.Assert(snapInfo.Slots, HasLen, 0)
 }
 
+const testAttrsYaml = `name: testsnap
+version: 0
+plugs:
+  plug:
+    interface: iface
+    plug-attr: foo
+  other:
+    interface: other-iface
+    whatever: 1
+slots:
+  slot:
+    interface: iface
+    slot-attr: true
+`
+
+func (s *AllSuite) TestValidatePlugsSlotsAttrs(c *C) {
+	restore := builtin.MockInterfaces(map[string]interfaces.Interface{
+		"iface": &ifacetest.TestInterface{InterfaceName: "iface", InterfaceStaticInfo: interfaces.StaticInfo{
+			PlugAttrs: interfaces.AttrSchema{"plug-attr": {Type: interfaces.AttrString}},
+			SlotAttrs: interfaces.AttrSchema{"slot-attr": {Type: interfaces.AttrBool}},
+		}},
+		// interfaces without a schema accept any attributes
+		"other-iface": &ifacetest.TestInterface{InterfaceName: "other-iface"},
+	})
+	defer restore()
+
+	snapInfo := snaptest.MockInfo(c, testAttrsYaml, nil)
+	c.Check(builtin.ValidatePlugsSlotsAttrs(snapInfo, false), IsNil)
+	c.Check(builtin.ValidatePlugsSlotsAttrs(snapInfo, true), IsNil)
+	c.Check(snap.Validate(snapInfo), IsNil)
+
+	// unknown attributes are only rejected in strict mode
+	snapInfo.Plugs["plug"].Attrs["plug-atr"] = "typo"
+	c.Check(builtin.ValidatePlugsSlotsAttrs(snapInfo, false), IsNil)
+	c.Check(snap.Validate(snapInfo), IsNil)
+	c.Check(builtin.ValidatePlugsSlotsAttrs(snapInfo, true), ErrorMatches, `invalid plug "plug" \(interface "iface"\): unknown attribute "plug-atr"`)
+	delete(snapInfo.Plugs["plug"].Attrs, "plug-atr")
+
+	snapInfo.Slots["slot"].Attrs["slot-attr"] = "true"
+	c.Check(snap.Validate(snapInfo), ErrorMatches, `invalid slot "slot" \(interface "iface"\): attribute "slot-attr" must be a boolean`)
+	c.Check(builtin.ValidatePlugsSlotsAttrs(snapInfo, false), ErrorMatches, `invalid slot "slot" \(interface "iface"\): attribute "slot-attr" must be a boolean`)
+}
+
 func (s *AllSuite) TestUnexpectedSpecSignatures(c *C) {
 	type funcSig struct {
 		name string
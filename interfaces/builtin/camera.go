@@ -19,6 +19,18 @@
 
 package builtin
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/hotplug"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+)
+
 const cameraSummary = `allows access to all cameras`
 
 const cameraBaseDeclarationSlots = `
@@ -51,13 +63,103 @@ const cameraConnectedPlugAppArmor = `
 /sys/devices/pci**/usb*/**/video4linux/** r,
 `
 
+// cameraHotplugConnectedPlugAppArmor is used for the slots of hotplugged
+// cameras, granting access to the device node of the slot only.
+const cameraHotplugConnectedPlugAppArmor = `
+# Access to a specific hotplugged camera
+%s rw,
+
+# Allow detection of cameras. Leaks plugged in USB device info
+/sys/bus/usb/devices/ r,
+/sys/devices/pci**/usb*/**/busnum r,
+/sys/devices/pci**/usb*/**/devnum r,
+/sys/devices/pci**/usb*/**/idVendor r,
+/sys/devices/pci**/usb*/**/idProduct r,
+/sys/devices/pci**/usb*/**/interface r,
+/sys/devices/pci**/usb*/**/modalias r,
+/sys/devices/pci**/usb*/**/speed r,
+/run/udev/data/c81:[0-9]* r, # video4linux (/dev/video*, etc)
+/run/udev/data/+usb:* r,
+/sys/class/video4linux/ r,
+/sys/devices/pci**/usb*/**/video4linux/** r,
+`
+
 var cameraConnectedPlugUDev = []string{
 	`KERNEL=="video[0-9]*"`,
 	`KERNEL=="vchiq"`,
 }
 
+// Pattern to match the device nodes of hotplugged cameras.
+var cameraDeviceNodePattern = regexp.MustCompile("^/dev/video[0-9]+$")
+
+// cameraInterface is the type for the camera interface. The implicit slot
+// of the system grants access to all cameras, while the hotplug slots
+// created for USB cameras grant access to a single device.
+type cameraInterface struct {
+	commonInterface
+}
+
+func (iface *cameraInterface) BeforePrepareSlot(slot *snap.SlotInfo) error {
+	path, ok := slot.Attrs["path"]
+	if !ok {
+		return nil
+	}
+	if s, ok := path.(string); !ok || !cameraDeviceNodePattern.MatchString(s) {
+		return fmt.Errorf("camera path attribute must be a valid device node")
+	}
+	return nil
+}
+
+func (iface *cameraInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	var path string
+	if err := slot.Attr("path", &path); err != nil {
+		return iface.commonInterface.AppArmorConnectedPlug(spec, plug, slot)
+	}
+	spec.AddSnippet(fmt.Sprintf(cameraHotplugConnectedPlugAppArmor, path))
+	return nil
+}
+
+func (iface *cameraInterface) UDevConnectedPlug(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	var path string
+	if err := slot.Attr("path", &path); err != nil {
+		return iface.commonInterface.UDevConnectedPlug(spec, plug, slot)
+	}
+	spec.TagDevice(fmt.Sprintf(`SUBSYSTEM=="video4linux", KERNEL=="%s"`, strings.TrimPrefix(path, "/dev/")))
+	return nil
+}
+
+func (iface *cameraInterface) HotplugDeviceDetected(di *hotplug.HotplugDeviceInfo) (*hotplug.ProposedSlot, error) {
+	bus, _ := di.Attribute("ID_BUS")
+	if di.Subsystem() != "video4linux" || bus != "usb" || !cameraDeviceNodePattern.MatchString(di.DeviceName()) {
+		return nil, nil
+	}
+	// USB video class devices expose metadata nodes next to the capture
+	// ones, only the latter are interesting.
+	if caps, _ := di.Attribute("ID_V4L_CAPABILITIES"); !strings.Contains(caps, ":capture:") {
+		return nil, nil
+	}
+
+	slot := hotplug.ProposedSlot{
+		Name: usbHotplugSlotName("camera", di, ""),
+		Attrs: map[string]interface{}{
+			"path": di.DeviceName(),
+		},
+	}
+	if vendor, ok := di.Attribute("ID_VENDOR_ID"); ok {
+		slot.Attrs["usb-vendor"] = vendor
+	}
+	if product, ok := di.Attribute("ID_MODEL_ID"); ok {
+		slot.Attrs["usb-product"] = product
+	}
+	return &slot, nil
+}
+
+func (iface *cameraInterface) HotplugKey(di *hotplug.HotplugDeviceInfo) (snap.HotplugKey, error) {
+	return usbHotplugKey(di)
+}
+
 func init() {
-	registerIface(&commonInterface{
+	registerIface(&cameraInterface{commonInterface{
 		name:                  "camera",
 		summary:               cameraSummary,
 		implicitOnCore:        true,
@@ -65,5 +167,5 @@ func init() {
 		baseDeclarationSlots:  cameraBaseDeclarationSlots,
 		connectedPlugAppArmor: cameraConnectedPlugAppArmor,
 		connectedPlugUDev:     cameraConnectedPlugUDev,
-	})
+	}})
 }
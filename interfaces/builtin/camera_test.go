@@ -25,6 +25,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
@@ -56,6 +57,15 @@ slots:
   camera:
 `
 
+const cameraHotplugCoreYaml = `name: core
+version: 0
+type: os
+slots:
+  camera-hd-webcam-c920:
+    interface: camera
+    path: /dev/video2
+`
+
 func (s *CameraInterfaceSuite) SetUpTest(c *C) {
 	s.plug, s.plugInfo = MockConnectedPlug(c, cameraConsumerYaml, nil, "camera")
 	s.slot, s.slotInfo = MockConnectedSlot(c, cameraCoreYaml, nil, "camera")
@@ -103,6 +113,81 @@ func (s *CameraInterfaceSuite) TestAutoConnect(c *C) {
 	c.Assert(s.iface.AutoConnect(s.plugInfo, s.slotInfo), Equals, true)
 }
 
+func (s *CameraInterfaceSuite) TestSanitizeHotplugSlot(c *C) {
+	_, slotInfo := MockConnectedSlot(c, cameraHotplugCoreYaml, nil, "camera-hd-webcam-c920")
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, slotInfo), IsNil)
+
+	slotInfo.Attrs["path"] = "/dev/vchiq"
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, slotInfo), ErrorMatches, "camera path attribute must be a valid device node")
+}
+
+func (s *CameraInterfaceSuite) TestHotplugSlotAppArmorSpec(c *C) {
+	slot, _ := MockConnectedSlot(c, cameraHotplugCoreYaml, nil, "camera-hd-webcam-c920")
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, slot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "/dev/video2 rw,")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), Not(testutil.Contains), "/dev/video[0-9]* rw")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), Not(testutil.Contains), "/dev/vchiq")
+}
+
+func (s *CameraInterfaceSuite) TestHotplugSlotUDevSpec(c *C) {
+	slot, _ := MockConnectedSlot(c, cameraHotplugCoreYaml, nil, "camera-hd-webcam-c920")
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, slot), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 2)
+	c.Check(spec.Snippets(), testutil.Contains, `# camera
+SUBSYSTEM=="video4linux", KERNEL=="video2", TAG+="snap_consumer_app"`)
+}
+
+func (s *CameraInterfaceSuite) TestHotplugDeviceDetected(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	env := map[string]string{"DEVPATH": "/sys/foo/video4linux/video2", "DEVNAME": "/dev/video2", "ID_VENDOR_ID": "046d", "ID_MODEL_ID": "082d", "ID_MODEL": "HD_Pro_Webcam_C920", "ID_SERIAL_SHORT": "8A5F3B2F", "ID_V4L_CAPABILITIES": ":capture:", "ACTION": "add", "SUBSYSTEM": "video4linux", "ID_BUS": "usb"}
+	di, err := hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot, DeepEquals, &hotplug.ProposedSlot{
+		Name:  "camera-hd-pro-webcam-c920-8a5f3b2f",
+		Attrs: map[string]interface{}{"path": "/dev/video2", "usb-vendor": "046d", "usb-product": "082d"},
+	})
+
+	// metadata nodes are ignored
+	env["ID_V4L_CAPABILITIES"] = ":"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	proposedSlot, err = hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot, IsNil)
+
+	// as are non-USB cameras
+	env["ID_V4L_CAPABILITIES"] = ":capture:"
+	delete(env, "ID_BUS")
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	proposedSlot, err = hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot, IsNil)
+}
+
+func (s *CameraInterfaceSuite) TestHotplugKey(c *C) {
+	keyHandler := s.iface.(hotplug.HotplugKeyHandler)
+	env := map[string]string{"DEVPATH": "/sys/foo/video4linux/video2", "DEVNAME": "/dev/video2", "ID_VENDOR_ID": "046d", "ID_MODEL_ID": "082d", "ID_PATH": "pci-0000:00:14.0-usb-0:2:1.0", "SUBSYSTEM": "video4linux", "ID_BUS": "usb"}
+	di, err := hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	key1, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(key1, Not(Equals), snap.HotplugKey(""))
+
+	// without a serial number, the USB port identifies the device
+	env["ID_PATH"] = "pci-0000:00:14.0-usb-0:3:1.0"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	key2, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(key2, Not(Equals), key1)
+}
+
 func (s *CameraInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
@@ -61,9 +61,27 @@ func (iface *contentInterface) StaticInfo() interfaces.StaticInfo {
 	return interfaces.StaticInfo{
 		Summary:              contentSummary,
 		BaseDeclarationSlots: contentBaseDeclarationSlots,
+		PlugAttrs:            contentPlugAttrs,
+		SlotAttrs:            contentSlotAttrs,
 	}
 }
 
+var contentPlugAttrs = interfaces.AttrSchema{
+	"content":          {Type: interfaces.AttrString},
+	"target":           {Type: interfaces.AttrString, Required: true, Path: interfaces.AttrPathClean},
+	"default-provider": {Type: interfaces.AttrString},
+}
+
+var contentSlotAttrs = interfaces.AttrSchema{
+	"content": {Type: interfaces.AttrString},
+	"read":    {Type: interfaces.AttrStringList, Path: interfaces.AttrPathClean},
+	"write":   {Type: interfaces.AttrStringList, Path: interfaces.AttrPathClean},
+	"source": {Type: interfaces.AttrMap, Attrs: interfaces.AttrSchema{
+		"read":  {Type: interfaces.AttrStringList, Path: interfaces.AttrPathClean},
+		"write": {Type: interfaces.AttrStringList, Path: interfaces.AttrPathClean},
+	}},
+}
+
 func cleanSubPath(path string) bool {
 	return filepath.Clean(path) == path && path != ".." && !strings.HasPrefix(path, "../")
 }
@@ -1021,3 +1021,69 @@ apps:
 `
 	c.Assert(apparmorSpec.SnippetForTag("snap.producer.app"), Equals, expected)
 }
+
+func (s *ContentSuite) TestAttrsSchemaTypo(c *C) {
+	const mockSnapYaml = `name: content-plug-snap
+version: 1.0
+plugs:
+ content-plug:
+  interface: content
+  content: mycont
+  taget: import
+`
+	info, err := snap.InfoFromSnapYaml([]byte(mockSnapYaml))
+	c.Assert(err, IsNil)
+	c.Check(info.Plugs, HasLen, 0)
+	c.Check(snap.BadInterfacesSummary(info), Equals, `snap "content-plug-snap" has bad plugs or slots: content-plug (required attribute "target" is missing)`)
+
+	info = snaptest.MockInfo(c, mockSnapYaml, nil)
+	c.Check(snap.Validate(info), ErrorMatches, `invalid plug "content-plug" \(interface "content"\): required attribute "target" is missing`)
+	// the misspelled attribute is reported when checking strictly
+	c.Check(builtin.ValidatePlugsSlotsAttrs(info, true), ErrorMatches, `invalid plug "content-plug" \(interface "content"\): unknown attribute "taget"`)
+}
+
+func (s *ContentSuite) TestAttrsSchemaUnknownAttr(c *C) {
+	const mockSnapYaml = `name: content-plug-snap
+version: 1.0
+plugs:
+ content-plug:
+  interface: content
+  content: mycont
+  target: import
+  unused: foo
+`
+	// unknown attributes are tolerated on installed snaps
+	info, err := snap.InfoFromSnapYaml([]byte(mockSnapYaml))
+	c.Assert(err, IsNil)
+	c.Check(info.Plugs, HasLen, 1)
+	c.Check(info.BadInterfaces, HasLen, 0)
+	c.Check(snap.Validate(info), IsNil)
+
+	c.Check(builtin.ValidatePlugsSlotsAttrs(info, true), ErrorMatches, `invalid plug "content-plug" \(interface "content"\): unknown attribute "unused"`)
+}
+
+func (s *ContentSuite) TestAttrsSchema(c *C) {
+	const mockSnapYaml = `name: content-snap
+version: 1.0
+plugs:
+ content-plug:
+  interface: content
+  target: $SNAP/import
+  default-provider: provider
+slots:
+ content-slot:
+  interface: content
+  source:
+   read: [lib, share]
+   write: [1]
+`
+	info := snaptest.MockInfo(c, mockSnapYaml, nil)
+	c.Check(snap.Validate(info), ErrorMatches, `invalid slot "content-slot" \(interface "content"\): attribute "source.write" must be a list of strings`)
+
+	delete(info.Slots["content-slot"].Attrs, "source")
+	c.Check(snap.Validate(info), IsNil)
+
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Check(si.PlugAttrs["target"], DeepEquals, interfaces.AttrSpec{Type: interfaces.AttrString, Required: true, Path: interfaces.AttrPathClean})
+	c.Check(si.SlotAttrs["source"].Attrs["read"], DeepEquals, interfaces.AttrSpec{Type: interfaces.AttrStringList, Path: interfaces.AttrPathClean})
+}
@@ -0,0 +1,516 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+const customDeviceSummary = `provides access to custom devices specified via the gadget snap`
+
+// The plug and the slot are matched by their "custom-device" attribute,
+// which defaults to their name.
+const customDeviceBaseDeclarationPlugs = `
+  custom-device:
+    deny-auto-connection: true
+    allow-connection:
+      plug-attributes:
+        custom-device: $SLOT(custom-device)
+`
+
+const customDeviceBaseDeclarationSlots = `
+  custom-device:
+    allow-installation:
+      slot-snap-type:
+        - gadget
+    deny-auto-connection: true
+`
+
+// customDeviceInterface lets gadget snaps describe the device nodes,
+// sysfs files and udev matches of a device without a dedicated builtin
+// interface, e.g.:
+//
+//	slots:
+//	  imx-serial:
+//	    interface: custom-device
+//	    devices: [/dev/ttymxc*]
+//	    files:
+//	      read: [/sys/class/tty/ttymxc*/dev]
+//	    udev-tagging:
+//	      - kernel: ttymxc*
+//	        subsystem: tty
+//
+// Device nodes and sysfs files are visible as-is in the mount namespace
+// of snaps. The ones that can only be read are in addition bind mounted
+// read-only onto themselves, when they are designated without wildcards.
+type customDeviceInterface struct {
+	commonInterface
+}
+
+// customDeviceDenied lists device nodes that a custom-device slot
+// cannot grant access to, as they give control over memory, storage or
+// security devices of the system.
+var customDeviceDenied = []string{
+	"/dev/mem",
+	"/dev/kmem",
+	"/dev/port",
+	"/dev/sd",
+	"/dev/hd",
+	"/dev/vd",
+	"/dev/xvd",
+	"/dev/nvme",
+	"/dev/mmcblk",
+	"/dev/loop",
+	"/dev/dm-",
+	"/dev/mapper/",
+	"/dev/md",
+	"/dev/tpm",
+	"/dev/kvm",
+}
+
+// customDeviceFilesDenied lists the sysfs hierarchies that a
+// custom-device slot cannot grant access to.
+var customDeviceFilesDenied = []string{
+	"/sys/kernel/security/",
+	"/sys/kernel/debug/",
+	"/sys/firmware/",
+	"/sys/fs/cgroup/",
+	"/sys/power/",
+}
+
+var customDeviceUDevKey = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// validateCustomDevicePath checks that the path is absolute and clean
+// and only uses the "*" wildcard, which is understood in the same way
+// by AppArmor and filepath.Match.
+func validateCustomDevicePath(path string) error {
+	if !strings.HasPrefix(path, "/") || filepath.Clean(path) != path {
+		return fmt.Errorf("%q must be an absolute and clean path", path)
+	}
+	if err := apparmor.ValidateNoAppArmorRegexp(strings.Replace(path, "*", "", -1)); err != nil {
+		return err
+	}
+	if strings.Contains(path, "**") {
+		return fmt.Errorf(`%q cannot contain "**"`, path)
+	}
+	if strings.ContainsAny(path, "@$,\n") {
+		return fmt.Errorf("%q contains a reserved character", path)
+	}
+	return nil
+}
+
+// overlapsDenied returns whether the path, whose wildcards can match
+// anything, can designate one of the denied paths.
+func overlapsDenied(path string, denied []string) bool {
+	literal := path
+	wildcard := false
+	if i := strings.IndexByte(path, '*'); i >= 0 {
+		literal = path[:i]
+		wildcard = true
+	}
+	for _, d := range denied {
+		if strings.HasPrefix(literal, d) || strings.HasPrefix(d+"/", literal+"/") {
+			return true
+		}
+		if wildcard && strings.HasPrefix(d, literal) {
+			return true
+		}
+	}
+	return false
+}
+
+func (iface *customDeviceInterface) validateDevices(attr string, paths []string) error {
+	for _, path := range paths {
+		if err := validateCustomDevicePath(path); err != nil {
+			return fmt.Errorf("%q attribute: %v", attr, err)
+		}
+		if !strings.HasPrefix(path, "/dev/") || len(path) == len("/dev/") {
+			return fmt.Errorf("%q attribute: %q must be a device node under /dev/", attr, path)
+		}
+		if strings.HasPrefix(path, "/dev/*") {
+			return fmt.Errorf("%q attribute: %q is too broad", attr, path)
+		}
+		if overlapsDenied(path, customDeviceDenied) {
+			return fmt.Errorf("%q attribute: %q is not allowed", attr, path)
+		}
+	}
+	return nil
+}
+
+func (iface *customDeviceInterface) validateFiles(attr string, paths []string) error {
+	for _, path := range paths {
+		if err := validateCustomDevicePath(path); err != nil {
+			return fmt.Errorf("%q attribute: %v", attr, err)
+		}
+		if !strings.HasPrefix(path, "/sys/") || strings.HasPrefix(path, "/sys/*") {
+			return fmt.Errorf("%q attribute: %q must be a specific path under /sys/", attr, path)
+		}
+		if overlapsDenied(path, customDeviceFilesDenied) {
+			return fmt.Errorf("%q attribute: %q is not allowed", attr, path)
+		}
+	}
+	return nil
+}
+
+// customDeviceUDevRule is an entry of the "udev-tagging" attribute.
+type customDeviceUDevRule struct {
+	kernel      string
+	subsystem   string
+	attributes  map[string]string
+	environment map[string]string
+}
+
+func (r *customDeviceUDevRule) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `KERNEL=="%s"`, r.kernel)
+	if r.subsystem != "" {
+		fmt.Fprintf(&buf, `, SUBSYSTEM=="%s"`, r.subsystem)
+	}
+	for _, k := range sortedKeys(r.attributes) {
+		fmt.Fprintf(&buf, `, ATTRS{%s}=="%s"`, k, r.attributes[k])
+	}
+	for _, k := range sortedKeys(r.environment) {
+		fmt.Fprintf(&buf, `, ENV{%s}=="%s"`, k, r.environment[k])
+	}
+	return buf.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validateUDevValue(what, value string) error {
+	if value == "" || strings.ContainsAny(value, "\"\n\\") {
+		return fmt.Errorf("invalid %s %q", what, value)
+	}
+	return nil
+}
+
+func parseUDevStringMap(what string, raw interface{}) (map[string]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a map of strings", what)
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok || !customDeviceUDevKey.MatchString(k) {
+			return nil, fmt.Errorf("%s must be a map of strings", what)
+		}
+		if err := validateUDevValue(what+" value", s); err != nil {
+			return nil, err
+		}
+		result[k] = s
+	}
+	return result, nil
+}
+
+// udevRules returns the rules of the "udev-tagging" attribute, or rules
+// matching the device nodes by name if it is not set. The kernel name of a
+// device node is the base name of its path, also for nodes in a
+// subdirectory of /dev like /dev/input/event0, so the kernel names must
+// match the base name of one of the given device nodes.
+func (iface *customDeviceInterface) udevRules(attrs interfaces.Attrer, devices []string) ([]*customDeviceUDevRule, error) {
+	value, ok := attrs.Lookup("udev-tagging")
+	if !ok {
+		rules := make([]*customDeviceUDevRule, 0, len(devices))
+		for _, dev := range devices {
+			rules = append(rules, &customDeviceUDevRule{kernel: filepath.Base(dev)})
+		}
+		return rules, nil
+	}
+	raw, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf(`"udev-tagging" attribute must be a list of maps`)
+	}
+
+	rules := make([]*customDeviceUDevRule, 0, len(raw))
+	for _, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(`"udev-tagging" attribute must be a list of maps`)
+		}
+		var r customDeviceUDevRule
+		for key, value := range m {
+			var err error
+			switch key {
+			case "kernel":
+				r.kernel, _ = value.(string)
+				err = validateUDevValue("kernel name", r.kernel)
+			case "subsystem":
+				r.subsystem, _ = value.(string)
+				err = validateUDevValue("subsystem", r.subsystem)
+			case "attributes":
+				r.attributes, err = parseUDevStringMap("attributes", value)
+			case "environment":
+				r.environment, err = parseUDevStringMap("environment", value)
+			default:
+				err = fmt.Errorf("unknown key %q", key)
+			}
+			if err != nil {
+				return nil, fmt.Errorf(`"udev-tagging" attribute: %v`, err)
+			}
+		}
+		if r.kernel == "" {
+			return nil, fmt.Errorf(`"udev-tagging" attribute: kernel name is required`)
+		}
+		matched := false
+		for _, dev := range devices {
+			if ok, _ := filepath.Match(filepath.Base(dev), r.kernel); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf(`"udev-tagging" attribute: kernel name %q does not match any of the devices`, r.kernel)
+		}
+		rules = append(rules, &r)
+	}
+	return rules, nil
+}
+
+// customDeviceAttrs are the attributes of a custom-device slot.
+type customDeviceAttrs struct {
+	devices     []string
+	readDevices []string
+	readFiles   []string
+	writeFiles  []string
+}
+
+func stringListAttr(attrs interfaces.Attrer, name string) ([]string, error) {
+	value, ok := attrs.Lookup(name)
+	if !ok {
+		return nil, nil
+	}
+	raw, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%q attribute must be a list of strings", name)
+	}
+	list := make([]string, 0, len(raw))
+	for _, item := range raw {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%q attribute must be a list of strings", name)
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+func (iface *customDeviceInterface) readAttrs(attrs interfaces.Attrer) (*customDeviceAttrs, error) {
+	var a customDeviceAttrs
+	var err error
+	if a.devices, err = stringListAttr(attrs, "devices"); err != nil {
+		return nil, err
+	}
+	if a.readDevices, err = stringListAttr(attrs, "read-devices"); err != nil {
+		return nil, err
+	}
+	if value, ok := attrs.Lookup("files"); ok {
+		files, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(`"files" attribute must be a map`)
+		}
+		for key := range files {
+			if key != "read" && key != "write" {
+				return nil, fmt.Errorf(`"files" attribute: unknown key %q`, key)
+			}
+		}
+	}
+	if a.readFiles, err = stringListAttr(attrs, "files.read"); err != nil {
+		return nil, err
+	}
+	if a.writeFiles, err = stringListAttr(attrs, "files.write"); err != nil {
+		return nil, err
+	}
+
+	if err := iface.validateDevices("devices", a.devices); err != nil {
+		return nil, err
+	}
+	if err := iface.validateDevices("read-devices", a.readDevices); err != nil {
+		return nil, err
+	}
+	if err := iface.validateFiles("files.read", a.readFiles); err != nil {
+		return nil, err
+	}
+	if err := iface.validateFiles("files.write", a.writeFiles); err != nil {
+		return nil, err
+	}
+	if len(a.devices)+len(a.readDevices) == 0 {
+		return nil, fmt.Errorf(`"devices" or "read-devices" attribute must list at least one device`)
+	}
+	return &a, nil
+}
+
+func (iface *customDeviceInterface) validateName(attrs map[string]interface{}, defaultName string) error {
+	name, ok := attrs["custom-device"]
+	if !ok {
+		attrs["custom-device"] = defaultName
+		return nil
+	}
+	s, ok := name.(string)
+	if !ok || snap.ValidatePlugName(s) != nil {
+		return fmt.Errorf(`"custom-device" attribute must be a valid name`)
+	}
+	return nil
+}
+
+func (iface *customDeviceInterface) BeforePreparePlug(plug *snap.PlugInfo) error {
+	if plug.Attrs == nil {
+		plug.Attrs = make(map[string]interface{})
+	}
+	if err := iface.validateName(plug.Attrs, plug.Name); err != nil {
+		return fmt.Errorf("cannot add custom-device plug: %v", err)
+	}
+	return nil
+}
+
+func (iface *customDeviceInterface) BeforePrepareSlot(slot *snap.SlotInfo) error {
+	if slot.Attrs == nil {
+		slot.Attrs = make(map[string]interface{})
+	}
+	if err := iface.validateName(slot.Attrs, slot.Name); err != nil {
+		return fmt.Errorf("cannot add custom-device slot: %v", err)
+	}
+	a, err := iface.readAttrs(slot)
+	if err != nil {
+		return fmt.Errorf("cannot add custom-device slot: %v", err)
+	}
+	if _, err := iface.udevRules(slot, append(a.devices, a.readDevices...)); err != nil {
+		return fmt.Errorf("cannot add custom-device slot: %v", err)
+	}
+	return nil
+}
+
+// readOnlyMounts returns the read-only device nodes and sysfs files that
+// can be bind mounted, that is the ones without wildcards that cannot be
+// written to through the slot.
+func (a *customDeviceAttrs) readOnlyMounts() []string {
+	var paths []string
+	for _, path := range append(a.readDevices, a.readFiles...) {
+		if strings.Contains(path, "*") {
+			continue
+		}
+		if strutil.ListContains(a.devices, path) || strutil.ListContains(a.writeFiles, path) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func (iface *customDeviceInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	a, err := iface.readAttrs(slot)
+	if err != nil {
+		return err
+	}
+
+	// Generate rules to allow snap-update-ns to do its thing
+	emit := spec.AddUpdateNSf
+	for _, path := range a.readOnlyMounts() {
+		emit("  # Read-only access to %s\n", path)
+		emit("  mount options=(bind) %s -> %s,\n", path, path)
+		emit("  remount options=(bind, ro) %s,\n", path)
+		emit("  umount %s,\n\n", path)
+	}
+	name := slot.Name()
+	_ = slot.Attr("custom-device", &name)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Description: Can access the %q custom device.\n", name)
+	for _, path := range a.devices {
+		fmt.Fprintf(&buf, "%s rwk,\n", path)
+	}
+	for _, path := range a.readDevices {
+		fmt.Fprintf(&buf, "%s r,\n", path)
+	}
+	for _, path := range a.readFiles {
+		fmt.Fprintf(&buf, "%s r,\n", path)
+	}
+	for _, path := range a.writeFiles {
+		fmt.Fprintf(&buf, "%s rw,\n", path)
+	}
+	spec.AddSnippet(buf.String())
+	return nil
+}
+
+func (iface *customDeviceInterface) MountConnectedPlug(spec *mount.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	a, err := iface.readAttrs(slot)
+	if err != nil {
+		return err
+	}
+	for _, path := range a.readOnlyMounts() {
+		// the device may not be present at the time
+		err := spec.AddMountEntry(osutil.MountEntry{
+			Name:    path,
+			Dir:     path,
+			Options: []string{"bind", "ro", osutil.XSnapdIgnoreMissing()},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (iface *customDeviceInterface) UDevConnectedPlug(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	a, err := iface.readAttrs(slot)
+	if err != nil {
+		return err
+	}
+	rules, err := iface.udevRules(slot, append(a.devices, a.readDevices...))
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		spec.TagDevice(r.String())
+	}
+	return nil
+}
+
+func init() {
+	registerIface(&customDeviceInterface{commonInterface{
+		name:                 "custom-device",
+		summary:              customDeviceSummary,
+		baseDeclarationPlugs: customDeviceBaseDeclarationPlugs,
+		baseDeclarationSlots: customDeviceBaseDeclarationSlots,
+	}})
+}
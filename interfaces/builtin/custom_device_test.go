@@ -0,0 +1,273 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type CustomDeviceInterfaceSuite struct {
+	iface    interfaces.Interface
+	slotInfo *snap.SlotInfo
+	slot     *interfaces.ConnectedSlot
+	plugInfo *snap.PlugInfo
+	plug     *interfaces.ConnectedPlug
+}
+
+var _ = Suite(&CustomDeviceInterfaceSuite{
+	iface: builtin.MustInterface("custom-device"),
+})
+
+const customDeviceConsumerYaml = `name: consumer
+version: 0
+apps:
+  app:
+    plugs: [imx-serial]
+plugs:
+  imx-serial:
+    interface: custom-device
+`
+
+const customDeviceGadgetYaml = `name: gadget
+version: 0
+type: gadget
+slots:
+  imx-serial:
+    interface: custom-device
+    devices:
+      - /dev/ttymxc*
+    read-devices:
+      - /dev/imx-info
+    files:
+      read: [/sys/class/tty/ttymxc*/dev]
+      write: [/sys/devices/platform/imx/power/control]
+    udev-tagging:
+      - kernel: ttymxc*
+        subsystem: tty
+        attributes:
+          idVendor: "0a5c"
+        environment:
+          ID_SERIAL: imx
+`
+
+func (s *CustomDeviceInterfaceSuite) SetUpTest(c *C) {
+	s.plug, s.plugInfo = MockConnectedPlug(c, customDeviceConsumerYaml, nil, "imx-serial")
+	s.slot, s.slotInfo = MockConnectedSlot(c, customDeviceGadgetYaml, nil, "imx-serial")
+}
+
+func (s *CustomDeviceInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "custom-device")
+}
+
+func (s *CustomDeviceInterfaceSuite) TestSanitizeDefaultsName(c *C) {
+	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
+	c.Check(s.plugInfo.Attrs["custom-device"], Equals, "imx-serial")
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.slotInfo), IsNil)
+	c.Check(s.slotInfo.Attrs["custom-device"], Equals, "imx-serial")
+}
+
+func (s *CustomDeviceInterfaceSuite) TestSanitizePlugInvalidName(c *C) {
+	info := snaptest.MockInfo(c, `name: consumer
+version: 0
+plugs:
+  serial:
+    interface: custom-device
+    custom-device: "-bad-"
+`, nil)
+	c.Check(interfaces.BeforePreparePlug(s.iface, info.Plugs["serial"]), ErrorMatches,
+		`cannot add custom-device plug: "custom-device" attribute must be a valid name`)
+}
+
+func (s *CustomDeviceInterfaceSuite) TestSanitizeSlotErrors(c *C) {
+	for _, t := range []struct {
+		attrs string
+		err   string
+	}{
+		{``, `"devices" or "read-devices" attribute must list at least one device`},
+		{`devices: /dev/foo`, `"devices" attribute must be a list of strings`},
+		{`devices: [/dev/foo/../mem]`, `"devices" attribute: "/dev/foo/../mem" must be an absolute and clean path`},
+		{`devices: ["/dev/foo?"]`, `"devices" attribute: "/dev/foo\?" contains a reserved apparmor char from .*`},
+		{`devices: ["/dev/foo/**"]`, `"devices" attribute: "/dev/foo/\*\*" cannot contain "\*\*"`},
+		{`devices: [/sys/foo]`, `"devices" attribute: "/sys/foo" must be a device node under /dev/`},
+		{`devices: ["/dev/*"]`, `"devices" attribute: "/dev/\*" is too broad`},
+		{`devices: [/dev/sda1]`, `"devices" attribute: "/dev/sda1" is not allowed`},
+		{`read-devices: ["/dev/s*"]`, `"read-devices" attribute: "/dev/s\*" is not allowed`},
+		{`devices: [/dev/mapper]`, `"devices" attribute: "/dev/mapper" is not allowed`},
+		{`devices: [/dev/mem]`, `"devices" attribute: "/dev/mem" is not allowed`},
+		{"devices: [/dev/foo]\nfiles: [/sys/foo]", `"files" attribute must be a map`},
+		{"devices: [/dev/foo]\nfiles: {exec: [/sys/foo]}", `"files" attribute: unknown key "exec"`},
+		{"devices: [/dev/foo]\nfiles: {read: [/etc/shadow]}", `"files.read" attribute: "/etc/shadow" must be a specific path under /sys/`},
+		{"devices: [/dev/foo]\nfiles: {write: [/sys/kernel/security/apparmor/policy]}", `"files.write" attribute: "/sys/kernel/security/apparmor/policy" is not allowed`},
+		{"devices: [/dev/foo]\nfiles: {write: [\"/sys/f*/efi\"]}", `"files.write" attribute: "/sys/f\*/efi" is not allowed`},
+		{"devices: [/dev/foo]\nudev-tagging: foo", `"udev-tagging" attribute must be a list of maps`},
+		{"devices: [/dev/foo]\nudev-tagging: [{subsystem: tty}]", `"udev-tagging" attribute: kernel name is required`},
+		{"devices: [/dev/foo]\nudev-tagging: [{kernel: bar}]", `"udev-tagging" attribute: kernel name "bar" does not match any of the devices`},
+		{"devices: [/dev/foo]\nudev-tagging: [{kernel: foo, run: /bin/sh}]", `"udev-tagging" attribute: unknown key "run"`},
+		{"devices: [/dev/foo]\nudev-tagging: [{kernel: foo, subsystem: 'tty\", RUN+=\"/bin/sh'}]", `"udev-tagging" attribute: invalid subsystem .*`},
+		{"devices: [/dev/foo]\nudev-tagging: [{kernel: foo, attributes: {'a}': b}}]", `"udev-tagging" attribute: attributes must be a map of strings`},
+	} {
+		yaml := "name: gadget\nversion: 0\ntype: gadget\nslots:\n  dev:\n    interface: custom-device\n"
+		for _, line := range strings.Split(t.attrs, "\n") {
+			if line != "" {
+				yaml += "    " + line + "\n"
+			}
+		}
+		info := snaptest.MockInfo(c, yaml, nil)
+		err := interfaces.BeforePrepareSlot(s.iface, info.Slots["dev"])
+		c.Check(err, ErrorMatches, "cannot add custom-device slot: "+t.err, Commentf(t.attrs))
+	}
+}
+
+func (s *CustomDeviceInterfaceSuite) TestAppArmorSpec(c *C) {
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	c.Check(spec.SnippetForTag("snap.consumer.app"), Equals, `# Description: Can access the "imx-serial" custom device.
+/dev/ttymxc* rwk,
+/dev/imx-info r,
+/sys/class/tty/ttymxc*/dev r,
+/sys/devices/platform/imx/power/control rw,
+`)
+}
+
+func (s *CustomDeviceInterfaceSuite) TestAppArmorSpecUpdateNS(c *C) {
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Check(strings.Join(spec.UpdateNS(), ""), Equals, `  # Read-only access to /dev/imx-info
+  mount options=(bind) /dev/imx-info -> /dev/imx-info,
+  remount options=(bind, ro) /dev/imx-info,
+  umount /dev/imx-info,
+
+`)
+}
+
+func (s *CustomDeviceInterfaceSuite) TestMountSpec(c *C) {
+	spec := &mount.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	// only the read-only paths without wildcards are mounted
+	c.Check(spec.MountEntries(), DeepEquals, []osutil.MountEntry{{
+		Name:    "/dev/imx-info",
+		Dir:     "/dev/imx-info",
+		Options: []string{"bind", "ro", "x-snapd.ignore-missing"},
+	}})
+	c.Check(spec.UserMountEntries(), HasLen, 0)
+}
+
+func (s *CustomDeviceInterfaceSuite) TestMountSpecReadFiles(c *C) {
+	slot, _ := MockConnectedSlot(c, `name: gadget
+version: 0
+type: gadget
+slots:
+  imx-serial:
+    interface: custom-device
+    devices: [/dev/ttymxc0]
+    read-devices: [/dev/ttymxc0, /dev/imx-*]
+    files:
+      read: [/sys/class/tty/ttymxc0/dev, /sys/devices/platform/imx/power/control]
+      write: [/sys/devices/platform/imx/power/control]
+`, nil, "imx-serial")
+	spec := &mount.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, slot), IsNil)
+	// paths that can be written to are not mounted read-only
+	c.Check(spec.MountEntries(), DeepEquals, []osutil.MountEntry{{
+		Name:    "/sys/class/tty/ttymxc0/dev",
+		Dir:     "/sys/class/tty/ttymxc0/dev",
+		Options: []string{"bind", "ro", "x-snapd.ignore-missing"},
+	}})
+}
+
+func (s *CustomDeviceInterfaceSuite) TestUDevSpec(c *C) {
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 2)
+	c.Check(spec.Snippets(), testutil.Contains, `# custom-device
+KERNEL=="ttymxc*", SUBSYSTEM=="tty", ATTRS{idVendor}=="0a5c", ENV{ID_SERIAL}=="imx", TAG+="snap_consumer_app"`)
+}
+
+func (s *CustomDeviceInterfaceSuite) TestUDevSpecDefaultRules(c *C) {
+	slot, _ := MockConnectedSlot(c, `name: gadget
+version: 0
+type: gadget
+slots:
+  imx-serial:
+    interface: custom-device
+    devices: [/dev/ttymxc*]
+    read-devices: [/dev/imx-info]
+`, nil, "imx-serial")
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, slot), IsNil)
+	c.Check(spec.Snippets(), testutil.Contains, "# custom-device\nKERNEL==\"ttymxc*\", TAG+=\"snap_consumer_app\"")
+	c.Check(spec.Snippets(), testutil.Contains, "# custom-device\nKERNEL==\"imx-info\", TAG+=\"snap_consumer_app\"")
+}
+
+func (s *CustomDeviceInterfaceSuite) TestUDevSpecNestedDevices(c *C) {
+	slot, _ := MockConnectedSlot(c, `name: gadget
+version: 0
+type: gadget
+slots:
+  input:
+    interface: custom-device
+    devices: [/dev/input/event*, /dev/bus/usb/001/002]
+`, nil, "input")
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, slot), IsNil)
+	// the kernel name is the base name of the device node
+	c.Check(spec.Snippets(), testutil.Contains, "# custom-device\nKERNEL==\"event*\", TAG+=\"snap_consumer_app\"")
+	c.Check(spec.Snippets(), testutil.Contains, "# custom-device\nKERNEL==\"002\", TAG+=\"snap_consumer_app\"")
+
+	slot, _ = MockConnectedSlot(c, `name: gadget
+version: 0
+type: gadget
+slots:
+  input:
+    interface: custom-device
+    devices: [/dev/input/event*]
+    udev-tagging:
+      - kernel: event*
+        subsystem: input
+`, nil, "input")
+	spec = &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, slot), IsNil)
+	c.Check(spec.Snippets(), testutil.Contains, "# custom-device\nKERNEL==\"event*\", SUBSYSTEM==\"input\", TAG+=\"snap_consumer_app\"")
+}
+
+func (s *CustomDeviceInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, false)
+	c.Assert(si.Summary, Equals, `provides access to custom devices specified via the gadget snap`)
+	c.Assert(si.BaseDeclarationPlugs, testutil.Contains, "custom-device: $SLOT(custom-device)")
+}
+
+func (s *CustomDeviceInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
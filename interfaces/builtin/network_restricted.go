@@ -0,0 +1,225 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/nftables"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+)
+
+const networkRestrictedSummary = `allows access to specific network destinations`
+
+// The allowed destinations are chosen by the snap, installing the plug
+// needs to be granted by the snap declaration, typically with
+// constraints on the "destinations" attribute.
+const networkRestrictedBaseDeclarationPlugs = `
+  network-restricted:
+    allow-installation: false
+    deny-auto-connection: true
+`
+
+const networkRestrictedBaseDeclarationSlots = `
+  network-restricted:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+// networkRestrictedInterface allows services to reach the network like
+// the network interface, but only the destinations listed in the
+// "destinations" attribute of the plug, e.g.:
+//
+//	destinations:
+//	  - address: 192.0.2.0/24
+//	    protocol: tcp
+//	    ports: [443, 8000-8080]
+//
+// The destinations are enforced by the nftables backend, which can only
+// restrict system services, so the plug can only be used by them.
+type networkRestrictedInterface struct {
+	commonInterface
+}
+
+func (iface *networkRestrictedInterface) BeforePreparePlug(plug *snap.PlugInfo) error {
+	if len(plug.Hooks) > 0 {
+		return fmt.Errorf("cannot add network-restricted plug: can only be used by system services, not by hooks")
+	}
+	for _, app := range plug.Apps {
+		if !app.IsService() || app.DaemonScope != snap.SystemDaemon {
+			return fmt.Errorf("cannot add network-restricted plug: can only be used by system services, not by %q", app.Name)
+		}
+	}
+	dests, err := parseNetworkDestinations(plug.Attrs["destinations"])
+	if err != nil {
+		return fmt.Errorf("cannot add network-restricted plug: %v", err)
+	}
+	if len(dests) == 0 {
+		return fmt.Errorf(`cannot add network-restricted plug: "destinations" attribute must list at least one destination`)
+	}
+	return nil
+}
+
+func (iface *networkRestrictedInterface) NftablesConnectedPlug(spec *nftables.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	var attr []interface{}
+	if err := plug.Attr("destinations", &attr); err != nil {
+		return err
+	}
+	dests, err := parseNetworkDestinations(attr)
+	if err != nil {
+		return fmt.Errorf("cannot connect plug %s: %v", plug.Name(), err)
+	}
+	for _, dest := range dests {
+		spec.AddDestination(dest)
+	}
+	return nil
+}
+
+func (iface *networkRestrictedInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	spec.AddSnippet(networkConnectedPlugAppArmor)
+	return nil
+}
+
+func (iface *networkRestrictedInterface) SecCompConnectedPlug(spec *seccomp.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	spec.AddSnippet(networkConnectedPlugSecComp)
+	return nil
+}
+
+func parseNetworkDestinations(attr interface{}) ([]nftables.Destination, error) {
+	list, ok := attr.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf(`"destinations" attribute must be a list of destinations`)
+	}
+	dests := make([]nftables.Destination, 0, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("destination #%d must be a map", i)
+		}
+		dest, err := parseNetworkDestination(m)
+		if err != nil {
+			return nil, fmt.Errorf("invalid destination #%d: %v", i, err)
+		}
+		dests = append(dests, dest)
+	}
+	return dests, nil
+}
+
+func parseNetworkDestination(m map[string]interface{}) (nftables.Destination, error) {
+	var dest nftables.Destination
+	for key := range m {
+		switch key {
+		case "address", "protocol", "ports":
+		default:
+			return dest, fmt.Errorf("unknown key %q", key)
+		}
+	}
+
+	address, ok := m["address"].(string)
+	if !ok || address == "" {
+		return dest, fmt.Errorf(`"address" must be an IP address or a network in CIDR notation`)
+	}
+	if strings.Contains(address, "/") {
+		_, network, err := net.ParseCIDR(address)
+		if err != nil {
+			return dest, fmt.Errorf("invalid network %q", address)
+		}
+		dest.Network = network
+	} else {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return dest, fmt.Errorf("invalid IP address %q", address)
+		}
+		bits := 128
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 32
+		}
+		dest.Network = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	}
+
+	if raw, ok := m["protocol"]; ok {
+		proto, _ := raw.(string)
+		if proto != "tcp" && proto != "udp" {
+			return dest, fmt.Errorf(`"protocol" must be either "tcp" or "udp"`)
+		}
+		dest.Protocol = proto
+	}
+
+	if raw, ok := m["ports"]; ok {
+		ports, ok := raw.([]interface{})
+		if !ok || len(ports) == 0 {
+			return dest, fmt.Errorf(`"ports" must be a list of ports or port ranges`)
+		}
+		for _, p := range ports {
+			r, err := parsePortRange(p)
+			if err != nil {
+				return dest, err
+			}
+			dest.Ports = append(dest.Ports, r)
+		}
+	}
+	return dest, nil
+}
+
+func parsePortRange(p interface{}) (nftables.PortRange, error) {
+	var r nftables.PortRange
+	switch v := p.(type) {
+	case int64:
+		r.First, r.Last = int(v), int(v)
+	case int:
+		r.First, r.Last = v, v
+	case string:
+		first, last := v, v
+		if i := strings.IndexByte(v, '-'); i >= 0 {
+			first, last = v[:i], v[i+1:]
+		}
+		var err1, err2 error
+		r.First, err1 = strconv.Atoi(first)
+		r.Last, err2 = strconv.Atoi(last)
+		if err1 != nil || err2 != nil {
+			return r, fmt.Errorf("invalid port range %q", v)
+		}
+	default:
+		return r, fmt.Errorf("invalid port %v", p)
+	}
+	if r.First < 1 || r.Last > 65535 || r.First > r.Last {
+		return r, fmt.Errorf("invalid port range %v", p)
+	}
+	return r, nil
+}
+
+func init() {
+	registerIface(&networkRestrictedInterface{commonInterface{
+		name:                 "network-restricted",
+		summary:              networkRestrictedSummary,
+		implicitOnCore:       true,
+		implicitOnClassic:    true,
+		baseDeclarationPlugs: networkRestrictedBaseDeclarationPlugs,
+		baseDeclarationSlots: networkRestrictedBaseDeclarationSlots,
+	}})
+}
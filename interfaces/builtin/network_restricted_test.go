@@ -0,0 +1,244 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	"net"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/nftables"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type NetworkRestrictedInterfaceSuite struct {
+	iface    interfaces.Interface
+	slotInfo *snap.SlotInfo
+	slot     *interfaces.ConnectedSlot
+	plugInfo *snap.PlugInfo
+	plug     *interfaces.ConnectedPlug
+}
+
+var _ = Suite(&NetworkRestrictedInterfaceSuite{
+	iface: builtin.MustInterface("network-restricted"),
+})
+
+const netRestrictedConsumerYaml = `name: consumer
+version: 0
+apps:
+  svc:
+    daemon: simple
+    plugs: [egress]
+plugs:
+  egress:
+    interface: network-restricted
+    destinations:
+      - address: 192.0.2.0/24
+        protocol: tcp
+        ports: [443, 8000-8080]
+      - address: 2001:db8::1
+`
+
+const netRestrictedCoreYaml = `name: core
+version: 0
+type: os
+slots:
+  network-restricted:
+`
+
+func (s *NetworkRestrictedInterfaceSuite) SetUpTest(c *C) {
+	s.plug, s.plugInfo = MockConnectedPlug(c, netRestrictedConsumerYaml, nil, "egress")
+	s.slot, s.slotInfo = MockConnectedSlot(c, netRestrictedCoreYaml, nil, "network-restricted")
+}
+
+func (s *NetworkRestrictedInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "network-restricted")
+}
+
+func (s *NetworkRestrictedInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.slotInfo), IsNil)
+}
+
+func (s *NetworkRestrictedInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
+}
+
+func (s *NetworkRestrictedInterfaceSuite) TestSanitizePlugErrors(c *C) {
+	for _, t := range []struct {
+		yaml string
+		err  string
+	}{{`
+apps:
+  svc:
+    daemon: simple
+    plugs: [egress]
+plugs:
+  egress:
+    interface: network-restricted`, `"destinations" attribute must be a list of destinations`,
+	}, {`
+apps:
+  svc:
+    daemon: simple
+    plugs: [egress]
+plugs:
+  egress:
+    interface: network-restricted
+    destinations: []`, `"destinations" attribute must list at least one destination`,
+	}, {`
+apps:
+  svc:
+    daemon: simple
+    plugs: [egress]
+plugs:
+  egress:
+    interface: network-restricted
+    destinations: [foo]`, `destination #0 must be a map`,
+	}, {`
+apps:
+  svc:
+    daemon: simple
+    plugs: [egress]
+plugs:
+  egress:
+    interface: network-restricted
+    destinations:
+      - address: 192.0.2.300`, `invalid destination #0: invalid IP address "192.0.2.300"`,
+	}, {`
+apps:
+  svc:
+    daemon: simple
+    plugs: [egress]
+plugs:
+  egress:
+    interface: network-restricted
+    destinations:
+      - address: 192.0.2.0/33`, `invalid destination #0: invalid network "192.0.2.0/33"`,
+	}, {`
+apps:
+  svc:
+    daemon: simple
+    plugs: [egress]
+plugs:
+  egress:
+    interface: network-restricted
+    destinations:
+      - address: 192.0.2.1
+        protocol: icmp`, `invalid destination #0: "protocol" must be either "tcp" or "udp"`,
+	}, {`
+apps:
+  svc:
+    daemon: simple
+    plugs: [egress]
+plugs:
+  egress:
+    interface: network-restricted
+    destinations:
+      - address: 192.0.2.1
+        ports: [8080-80]`, `invalid destination #0: invalid port range 8080-80`,
+	}, {`
+apps:
+  svc:
+    daemon: simple
+    plugs: [egress]
+plugs:
+  egress:
+    interface: network-restricted
+    destinations:
+      - address: 192.0.2.1
+        ports: [0]`, `invalid destination #0: invalid port range 0`,
+	}, {`
+apps:
+  svc:
+    daemon: simple
+    plugs: [egress]
+plugs:
+  egress:
+    interface: network-restricted
+    destinations:
+      - address: 192.0.2.1
+        host: example.com`, `invalid destination #0: unknown key "host"`,
+	}, {`
+apps:
+  cli:
+    plugs: [egress]
+plugs:
+  egress:
+    interface: network-restricted
+    destinations:
+      - address: 192.0.2.1`, `can only be used by system services, not by "cli"`,
+	}, {`
+hooks:
+  configure:
+plugs:
+  egress:
+    interface: network-restricted
+    destinations:
+      - address: 192.0.2.1`, `can only be used by system services, not by hooks`,
+	}} {
+		info := snaptest.MockInfo(c, "name: consumer\nversion: 0\n"+t.yaml, nil)
+		plug := info.Plugs["egress"]
+		c.Check(interfaces.BeforePreparePlug(s.iface, plug), ErrorMatches, "cannot add network-restricted plug: "+t.err, Commentf(t.yaml))
+	}
+}
+
+func (s *NetworkRestrictedInterfaceSuite) TestNftablesSpec(c *C) {
+	spec := &nftables.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.svc"})
+	c.Check(spec.Destinations("snap.consumer.svc"), DeepEquals, []nftables.Destination{{
+		Network:  &net.IPNet{IP: net.IP{192, 0, 2, 0}, Mask: net.CIDRMask(24, 32)},
+		Protocol: "tcp",
+		Ports:    []nftables.PortRange{{First: 443, Last: 443}, {First: 8000, Last: 8080}},
+	}, {
+		Network: &net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(128, 128)},
+	}})
+}
+
+func (s *NetworkRestrictedInterfaceSuite) TestAppArmorSpec(c *C) {
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.svc"})
+	c.Check(spec.SnippetForTag("snap.consumer.svc"), testutil.Contains, "#include <abstractions/nameservice>\n")
+}
+
+func (s *NetworkRestrictedInterfaceSuite) TestSecCompSpec(c *C) {
+	spec := &seccomp.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.svc"})
+	c.Check(spec.SnippetForTag("snap.consumer.svc"), testutil.Contains, "socket AF_NETLINK - NETLINK_ROUTE\n")
+}
+
+func (s *NetworkRestrictedInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, true)
+	c.Assert(si.ImplicitOnClassic, Equals, true)
+	c.Assert(si.Summary, Equals, `allows access to specific network destinations`)
+	c.Assert(si.BaseDeclarationPlugs, testutil.Contains, "allow-installation: false")
+}
+
+func (s *NetworkRestrictedInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
@@ -19,6 +19,18 @@
 
 package builtin
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/hotplug"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/snap"
+)
+
 const rawusbSummary = `allows raw access to all USB devices`
 
 const rawusbBaseDeclarationSlots = `
@@ -60,14 +72,96 @@ const rawusbConnectedPlugSecComp = `
 socket AF_NETLINK - NETLINK_KOBJECT_UEVENT
 `
 
+// rawusbHotplugConnectedPlugAppArmor is used for the slots of hotplugged
+// USB devices, granting raw access to the device node of the slot only.
+const rawusbHotplugConnectedPlugAppArmor = `
+# Description: Allow raw access to a specific hotplugged USB device.
+%s rw,
+
+# Allow detection of usb devices. Leaks plugged in USB device info
+/sys/bus/usb/devices/ r,
+/sys/devices/pci**/usb[0-9]** r,
+/sys/devices/platform/{sbc,soc}/*.usb/usb[0-9]** r,
+
+/run/udev/data/+usb:* r,
+`
+
 var rawusbConnectedPlugUDev = []string{
 	`SUBSYSTEM=="usb"`,
 	`SUBSYSTEM=="usbmisc"`,
 	`SUBSYSTEM=="tty", ENV{ID_BUS}=="usb"`,
 }
 
+// Pattern to match the device nodes of hotplugged USB devices.
+var rawusbDeviceNodePattern = regexp.MustCompile("^/dev/bus/usb/[0-9]{3}/[0-9]{3}$")
+
+// rawusbInterface is the type for the raw-usb interface. The implicit slot
+// of the system grants access to all USB devices, while the hotplug slots
+// grant access to a single device.
+type rawusbInterface struct {
+	commonInterface
+}
+
+func (iface *rawusbInterface) BeforePrepareSlot(slot *snap.SlotInfo) error {
+	path, ok := slot.Attrs["path"]
+	if !ok {
+		return nil
+	}
+	if s, ok := path.(string); !ok || !rawusbDeviceNodePattern.MatchString(s) {
+		return fmt.Errorf("raw-usb path attribute must be a valid device node")
+	}
+	return nil
+}
+
+func (iface *rawusbInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	var path string
+	if err := slot.Attr("path", &path); err != nil {
+		return iface.commonInterface.AppArmorConnectedPlug(spec, plug, slot)
+	}
+	spec.AddSnippet(fmt.Sprintf(rawusbHotplugConnectedPlugAppArmor, path))
+	return nil
+}
+
+func (iface *rawusbInterface) UDevConnectedPlug(spec *udev.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	var path string
+	if err := slot.Attr("path", &path); err != nil {
+		return iface.commonInterface.UDevConnectedPlug(spec, plug, slot)
+	}
+	spec.TagDevice(fmt.Sprintf(`SUBSYSTEM=="usb", ENV{DEVNAME}=="%s"`, path))
+	return nil
+}
+
+func (iface *rawusbInterface) HotplugDeviceDetected(di *hotplug.HotplugDeviceInfo) (*hotplug.ProposedSlot, error) {
+	if di.Subsystem() != "usb" || di.DeviceType() != "usb_device" || !rawusbDeviceNodePattern.MatchString(di.DeviceName()) {
+		return nil, nil
+	}
+	// Hubs (interface class 09) are part of the USB topology rather than
+	// devices the user would want to hand over to a snap.
+	if ifaces, _ := di.Attribute("ID_USB_INTERFACES"); strings.Contains(ifaces, ":09") {
+		return nil, nil
+	}
+
+	slot := hotplug.ProposedSlot{
+		Name: usbHotplugSlotName("usb", di, ""),
+		Attrs: map[string]interface{}{
+			"path": di.DeviceName(),
+		},
+	}
+	if vendor, ok := di.Attribute("ID_VENDOR_ID"); ok {
+		slot.Attrs["usb-vendor"] = vendor
+	}
+	if product, ok := di.Attribute("ID_MODEL_ID"); ok {
+		slot.Attrs["usb-product"] = product
+	}
+	return &slot, nil
+}
+
+func (iface *rawusbInterface) HotplugKey(di *hotplug.HotplugDeviceInfo) (snap.HotplugKey, error) {
+	return usbHotplugKey(di)
+}
+
 func init() {
-	registerIface(&commonInterface{
+	registerIface(&rawusbInterface{commonInterface{
 		name:                  "raw-usb",
 		summary:               rawusbSummary,
 		implicitOnCore:        true,
@@ -76,5 +170,5 @@ func init() {
 		connectedPlugAppArmor: rawusbConnectedPlugAppArmor,
 		connectedPlugSecComp:  rawusbConnectedPlugSecComp,
 		connectedPlugUDev:     rawusbConnectedPlugUDev,
-	})
+	}})
 }
@@ -25,6 +25,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/seccomp"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
@@ -57,6 +58,15 @@ slots:
   raw-usb:
 `
 
+const rawusbHotplugCoreYaml = `name: core
+version: 0
+type: os
+slots:
+  usb-dfu-bootloader:
+    interface: raw-usb
+    path: /dev/bus/usb/001/004
+`
+
 func (s *RawUsbInterfaceSuite) SetUpTest(c *C) {
 	s.plug, s.plugInfo = MockConnectedPlug(c, rawusbConsumerYaml, nil, "raw-usb")
 	s.slot, s.slotInfo = MockConnectedSlot(c, rawusbCoreYaml, nil, "raw-usb")
@@ -113,6 +123,62 @@ func (s *RawUsbInterfaceSuite) TestAutoConnect(c *C) {
 	c.Assert(s.iface.AutoConnect(s.plugInfo, s.slotInfo), Equals, true)
 }
 
+func (s *RawUsbInterfaceSuite) TestSanitizeHotplugSlot(c *C) {
+	_, slotInfo := MockConnectedSlot(c, rawusbHotplugCoreYaml, nil, "usb-dfu-bootloader")
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, slotInfo), IsNil)
+
+	slotInfo.Attrs["path"] = "/dev/ttyUSB0"
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, slotInfo), ErrorMatches, "raw-usb path attribute must be a valid device node")
+}
+
+func (s *RawUsbInterfaceSuite) TestHotplugSlotAppArmorSpec(c *C) {
+	slot, _ := MockConnectedSlot(c, rawusbHotplugCoreYaml, nil, "usb-dfu-bootloader")
+	spec := &apparmor.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, slot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "/dev/bus/usb/001/004 rw,")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), Not(testutil.Contains), "/dev/bus/usb/[0-9][0-9][0-9]/[0-9][0-9][0-9] rw,")
+}
+
+func (s *RawUsbInterfaceSuite) TestHotplugSlotUDevSpec(c *C) {
+	slot, _ := MockConnectedSlot(c, rawusbHotplugCoreYaml, nil, "usb-dfu-bootloader")
+	spec := &udev.Specification{}
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, slot), IsNil)
+	c.Assert(spec.Snippets(), HasLen, 2)
+	c.Check(spec.Snippets(), testutil.Contains, `# raw-usb
+SUBSYSTEM=="usb", ENV{DEVNAME}=="/dev/bus/usb/001/004", TAG+="snap_consumer_app"`)
+}
+
+func (s *RawUsbInterfaceSuite) TestHotplugDeviceDetected(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	env := map[string]string{"DEVPATH": "/sys/devices/pci0000:00/0000:00:14.0/usb1/1-2", "DEVNAME": "/dev/bus/usb/001/004", "DEVTYPE": "usb_device", "ID_VENDOR_ID": "0483", "ID_MODEL_ID": "df11", "ID_MODEL_FROM_DATABASE": "STM Device in DFU Mode", "ID_USB_INTERFACES": ":fe0102:", "ACTION": "add", "SUBSYSTEM": "usb"}
+	di, err := hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot, DeepEquals, &hotplug.ProposedSlot{
+		Name:  "usb-stm-device-in-dfu-mo",
+		Attrs: map[string]interface{}{"path": "/dev/bus/usb/001/004", "usb-vendor": "0483", "usb-product": "df11"},
+	})
+
+	// hubs are ignored
+	env["ID_USB_INTERFACES"] = ":090000:"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	proposedSlot, err = hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot, IsNil)
+
+	// as are USB interfaces
+	env["ID_USB_INTERFACES"] = ":fe0102:"
+	env["DEVTYPE"] = "usb_interface"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	proposedSlot, err = hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot, IsNil)
+}
+
 func (s *RawUsbInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
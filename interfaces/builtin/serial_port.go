@@ -216,7 +216,15 @@ func (iface *serialPortInterface) HotplugDeviceDetected(di *hotplug.HotplugDevic
 		return nil, nil
 	}
 
+	// Multi-port adapters expose one tty per USB interface, the interface
+	// number is part of the name of all but the first port.
+	var suffix string
+	if num := usbInterfaceNum(di); num > 0 {
+		suffix = fmt.Sprintf("if%d", num)
+	}
+
 	slot := hotplug.ProposedSlot{
+		Name: usbHotplugSlotName("serial", di, suffix),
 		Attrs: map[string]interface{}{
 			"path": di.DeviceName(),
 		},
@@ -230,6 +238,31 @@ func (iface *serialPortInterface) HotplugDeviceDetected(di *hotplug.HotplugDevic
 	return &slot, nil
 }
 
+// usbInterfaceNum returns the number of the USB interface backing the
+// device, or 0 if unknown.
+func usbInterfaceNum(di *hotplug.HotplugDeviceInfo) int64 {
+	ifaceNum, ok := di.Attribute("ID_USB_INTERFACE_NUM")
+	if !ok {
+		return 0
+	}
+	num, err := strconv.ParseInt(ifaceNum, 16, 64)
+	if err != nil {
+		return 0
+	}
+	return num
+}
+
+// HotplugKey returns a key derived from the identity of the USB device and
+// the USB interface for all but the first port of multi-port adapters, so
+// that each port gets a distinct slot. The first port keeps the default key,
+// which existing slots of serial-port devices were created with.
+func (iface *serialPortInterface) HotplugKey(di *hotplug.HotplugDeviceInfo) (snap.HotplugKey, error) {
+	if usbInterfaceNum(di) == 0 {
+		return "", nil
+	}
+	return usbHotplugKey(di, "ID_USB_INTERFACE_NUM")
+}
+
 func slotDeviceAttrEqual(di *hotplug.HotplugDeviceInfo, devinfoAttribute string, slotAttributeValue int64) bool {
 	var attr string
 	var ok bool
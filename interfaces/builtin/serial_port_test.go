@@ -587,7 +587,69 @@ func (s *SerialPortInterfaceSuite) TestHotplugDeviceDetected(c *C) {
 	c.Assert(err, IsNil)
 	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
 	c.Assert(err, IsNil)
-	c.Assert(proposedSlot, DeepEquals, &hotplug.ProposedSlot{Attrs: map[string]interface{}{"path": "/dev/ttyUSB0", "usb-vendor": "1234", "usb-product": "5678"}})
+	c.Assert(proposedSlot, DeepEquals, &hotplug.ProposedSlot{Name: "serial-1234-5678", Attrs: map[string]interface{}{"path": "/dev/ttyUSB0", "usb-vendor": "1234", "usb-product": "5678"}})
+}
+
+func (s *SerialPortInterfaceSuite) TestHotplugDeviceDetectedSlotName(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	env := map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/ttyUSB3", "ID_VENDOR_ID": "0403", "ID_MODEL_ID": "6001", "ID_MODEL": "FT232R_USB_UART", "ID_SERIAL_SHORT": "XA50285BI", "ID_USB_INTERFACE_NUM": "00", "ACTION": "add", "SUBSYSTEM": "tty", "ID_BUS": "usb"}
+	di, err := hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot.Name, Equals, "serial-ft232r-usb-uart-a50285bi")
+
+	// the second port of a multi-port adapter
+	env["ID_USB_INTERFACE_NUM"] = "01"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	proposedSlot, err = hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot.Name, Equals, "serial-ft232r-usb-uart-a50285bi-if1")
+	_, err = proposedSlot.Clean()
+	c.Check(err, IsNil)
+}
+
+func (s *SerialPortInterfaceSuite) TestHotplugKey(c *C) {
+	keyHandler := s.iface.(hotplug.HotplugKeyHandler)
+	env := map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/ttyUSB0", "ID_VENDOR_ID": "0403", "ID_MODEL_ID": "6001", "ID_SERIAL_SHORT": "A50285BI", "ID_USB_INTERFACE_NUM": "00", "SUBSYSTEM": "tty", "ID_BUS": "usb"}
+	di, err := hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	// the first port uses the default key
+	key, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(key, Equals, snap.HotplugKey(""))
+
+	// other ports of the same adapter get their own keys
+	env["ID_USB_INTERFACE_NUM"] = "01"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	key1, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(key1, Not(Equals), snap.HotplugKey(""))
+
+	// the key doesn't depend on the device node
+	env["DEVNAME"] = "/dev/ttyUSB1"
+	env["DEVPATH"] = "/sys/foo/baz"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	key2, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(key2, Equals, key1)
+
+	env["ID_USB_INTERFACE_NUM"] = "02"
+	di, err = hotplug.NewHotplugDeviceInfo(env)
+	c.Assert(err, IsNil)
+	key3, err := keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(key3, Not(Equals), key1)
+
+	// no key is provided for devices that cannot be identified
+	di, err = hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo/bar", "DEVNAME": "/dev/ttyUSB0", "ID_USB_INTERFACE_NUM": "01", "SUBSYSTEM": "tty", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+	key, err = keyHandler.HotplugKey(di)
+	c.Assert(err, IsNil)
+	c.Check(key, Equals, snap.HotplugKey(""))
 }
 
 func (s *SerialPortInterfaceSuite) TestHotplugDeviceDetectedNotSerialPort(c *C) {
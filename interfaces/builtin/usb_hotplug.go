@@ -0,0 +1,137 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces/hotplug"
+	"github.com/snapcore/snapd/snap"
+)
+
+// maxUsbHotplugModelLen limits the length of the model part of the names of
+// hotplug slots created for USB devices.
+const maxUsbHotplugModelLen = 20
+
+// maxUsbHotplugSerialLen limits the length of the serial number part of the
+// names of hotplug slots created for USB devices.
+const maxUsbHotplugSerialLen = 8
+
+// usbHotplugNamePart turns an arbitrary device attribute into a fragment of
+// a slot name: letters are lowercased, digits are kept and any sequence of
+// other characters becomes a single dash.
+func usbHotplugNamePart(s string) string {
+	var out []rune
+	dash := false
+	for _, c := range strings.ToLower(s) {
+		switch {
+		case (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9'):
+			if dash && len(out) > 0 {
+				out = append(out, '-')
+			}
+			dash = false
+			out = append(out, c)
+		default:
+			dash = true
+		}
+	}
+	return string(out)
+}
+
+// usbHotplugSlotName returns the name of the hotplug slot for the given USB
+// device. The name is derived from the model and the serial number of the
+// device, so that it stays the same when the device is unplugged and
+// plugged again, e.g. "serial-ft232r-usb-uart-a50285bi". The prefix and
+// the optional suffix are provided by the interface. An empty name is
+// returned for devices without a vendor or model identifier, leaving it to
+// the hotplug subsystem to pick one.
+func usbHotplugSlotName(prefix string, di *hotplug.HotplugDeviceInfo, suffix string) string {
+	vendorID, _ := di.Attribute("ID_VENDOR_ID")
+	modelID, _ := di.Attribute("ID_MODEL_ID")
+	if vendorID == "" || modelID == "" {
+		return ""
+	}
+
+	var model string
+	for _, attr := range []string{"ID_MODEL_FROM_DATABASE", "ID_MODEL"} {
+		if val, ok := di.Attribute(attr); ok {
+			if model = usbHotplugNamePart(val); model != "" {
+				break
+			}
+		}
+	}
+	if model == "" {
+		model = usbHotplugNamePart(vendorID + "-" + modelID)
+	}
+	if len(model) > maxUsbHotplugModelLen {
+		model = strings.TrimRight(model[:maxUsbHotplugModelLen], "-")
+	}
+
+	parts := []string{prefix, model}
+	if serial, ok := di.Attribute("ID_SERIAL_SHORT"); ok {
+		serial = strings.Replace(usbHotplugNamePart(serial), "-", "", -1)
+		if len(serial) > maxUsbHotplugSerialLen {
+			serial = serial[len(serial)-maxUsbHotplugSerialLen:]
+		}
+		if serial != "" {
+			parts = append(parts, serial)
+		}
+	}
+	if suffix != "" {
+		parts = append(parts, suffix)
+	}
+	return strings.Join(parts, "-")
+}
+
+// usbHotplugKey returns the hotplug key of the given USB device. The key is
+// computed from the vendor and model identifiers and the serial number of
+// the device. For devices without a serial number the physical port the
+// device is plugged into is used instead. Additional attributes, such as
+// the interface number of multi-port serial adapters, can be passed to tell
+// apart several devices exposed by the same USB device. An empty key is
+// returned for devices without a vendor or model identifier, in which case
+// the hotplug subsystem falls back to the default key.
+func usbHotplugKey(di *hotplug.HotplugDeviceInfo, extraAttrs ...string) (snap.HotplugKey, error) {
+	vendorID, _ := di.Attribute("ID_VENDOR_ID")
+	modelID, _ := di.Attribute("ID_MODEL_ID")
+	if vendorID == "" || modelID == "" {
+		return "", nil
+	}
+
+	attrs := []string{"ID_VENDOR_ID", "ID_MODEL_ID"}
+	if serial, ok := di.Attribute("ID_SERIAL_SHORT"); ok && serial != "" {
+		attrs = append(attrs, "ID_SERIAL_SHORT")
+	} else {
+		attrs = append(attrs, "ID_PATH")
+	}
+	attrs = append(attrs, extraAttrs...)
+
+	key := sha256.New()
+	for _, attr := range attrs {
+		val, _ := di.Attribute(attr)
+		key.Write([]byte(attr))
+		key.Write([]byte{0})
+		key.Write([]byte(val))
+		key.Write([]byte{0})
+	}
+	return snap.HotplugKey(fmt.Sprintf("%x", key.Sum(nil))), nil
+}
@@ -100,11 +100,13 @@ type Interfaces struct {
 
 // Info holds information about a given interface and its instances.
 type Info struct {
-	Name    string
-	Summary string
-	DocURL  string
-	Plugs   []*snap.PlugInfo
-	Slots   []*snap.SlotInfo
+	Name      string
+	Summary   string
+	DocURL    string
+	PlugAttrs AttrSchema
+	SlotAttrs AttrSchema
+	Plugs     []*snap.PlugInfo
+	Slots     []*snap.SlotInfo
 }
 
 // ConnRef holds information about plug and slot reference that form a particular connection.
@@ -195,6 +197,11 @@ type StaticInfo struct {
 	BaseDeclarationPlugs string
 	// BaseDeclarationSlots defines an optional extension to the base-declaration assertion relevant for this interface.
 	BaseDeclarationSlots string
+
+	// PlugAttrs optionally describes the attributes of plugs of this interface.
+	PlugAttrs AttrSchema `json:"plug-attrs,omitempty"`
+	// SlotAttrs optionally describes the attributes of slots of this interface.
+	SlotAttrs AttrSchema `json:"slot-attrs,omitempty"`
 }
 
 // StaticInfoOf returns the static-info of the given interface.
@@ -238,6 +245,10 @@ const (
 	SecurityKMod SecuritySystem = "kmod"
 	// SecuritySystemd identifies the systemd services security system
 	SecuritySystemd SecuritySystem = "systemd"
+	// SecurityLandlock identifies the Landlock security system
+	SecurityLandlock SecuritySystem = "landlock"
+	// SecurityNftables identifies the nftables network filtering system
+	SecurityNftables SecuritySystem = "nftables"
 )
 
 var isValidBusName = regexp.MustCompile(`^[a-zA-Z_-][a-zA-Z0-9_-]*(\.[a-zA-Z_-][a-zA-Z0-9_-]*)+$`).MatchString
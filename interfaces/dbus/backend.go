@@ -174,6 +174,21 @@ func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions,
 	return nil
 }
 
+// Profiles returns the dbus configuration files of the given snap, as
+// Setup would write them, without writing them.
+func (b *Backend) Profiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string][]byte, error) {
+	snapName := snapInfo.InstanceName()
+	spec, err := repo.SnapSpecification(b.Name(), snapName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain dbus specification for snap %q: %s", snapName, err)
+	}
+	content, err := b.deriveContent(spec.(*Specification), snapInfo)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain expected DBus configuration files for snap %q: %s", snapName, err)
+	}
+	return interfaces.ProfilesFromContent(dirs.SnapDBusSystemPolicyDir, content)
+}
+
 // Remove removes dbus configuration files of a given snap.
 //
 // This method should be called after removing a snap.
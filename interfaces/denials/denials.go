@@ -0,0 +1,252 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package denials collects the AppArmor and seccomp denials logged by
+// the kernel for snaps and suggests the interfaces whose plugs would
+// allow the denied accesses.
+package denials
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap/naming"
+)
+
+// Denial is an aggregation of the identical denials logged for a snap
+// application or hook.
+type Denial struct {
+	Snap string `json:"snap"`
+	// App is the name of the application, or "hook.<name>" for hooks,
+	// it is empty if it could not be determined.
+	App string `json:"app,omitempty"`
+	// Class is one of file, capability, network, dbus, signal, ptrace
+	// or other for AppArmor denials, or syscall for seccomp denials.
+	Class     string `json:"class"`
+	Operation string `json:"operation,omitempty"`
+	// Target is what was being accessed: a path, a capability, a
+	// network family and socket type, a D-Bus member, a syscall, etc.
+	Target string `json:"target"`
+	// Permissions is the requested access, for file, D-Bus and
+	// signal denials.
+	Permissions string    `json:"permissions,omitempty"`
+	Count       int       `json:"count"`
+	FirstSeen   time.Time `json:"first-seen"`
+	LastSeen    time.Time `json:"last-seen"`
+	// SuggestedPlugs lists the interfaces whose plugs would allow
+	// the access, see Suggest.
+	SuggestedPlugs []string `json:"suggested-plugs,omitempty"`
+
+	family   string
+	sockType string
+}
+
+func (d *Denial) key() string {
+	return strings.Join([]string{d.Snap, d.App, d.Class, d.Operation, d.Target, d.Permissions}, "\x00")
+}
+
+var osutilStreamCommand = osutil.StreamCommand
+
+// journalctl returns the kernel and audit log entries of the current
+// boot, as JSON.
+var journalctl = func() (io.ReadCloser, error) {
+	return osutilStreamCommand("journalctl", "-o", "json", "--no-pager", "-q", "-b", "_TRANSPORT=kernel", "+", "_TRANSPORT=audit")
+}
+
+type journalEntry struct {
+	Message   json.RawMessage `json:"MESSAGE"`
+	Timestamp string          `json:"__REALTIME_TIMESTAMP"`
+}
+
+func (e *journalEntry) message() string {
+	var msg string
+	if err := json.Unmarshal(e.Message, &msg); err == nil {
+		return msg
+	}
+	// journald encodes messages with non-printable characters as
+	// arrays of bytes
+	var raw []byte
+	if err := json.Unmarshal(e.Message, &raw); err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+func (e *journalEntry) time() time.Time {
+	usec, err := strconv.ParseInt(e.Timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, usec*int64(time.Microsecond))
+}
+
+// Collect returns the denials logged in the current boot for the given
+// snap, or for all snaps if snapName is empty, in the order they were
+// first seen.
+func Collect(snapName string) ([]*Denial, error) {
+	r, err := journalctl()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read kernel log: %v", err)
+	}
+	defer r.Close()
+
+	var denials []*Denial
+	seen := make(map[string]*Denial)
+	dec := json.NewDecoder(r)
+	for {
+		var e journalEntry
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("cannot decode kernel log: %v", err)
+		}
+		d := parseDenial(e.message())
+		if d == nil || (snapName != "" && d.Snap != snapName) {
+			continue
+		}
+		t := e.time()
+		if prev := seen[d.key()]; prev != nil {
+			prev.Count++
+			prev.LastSeen = t
+			continue
+		}
+		d.Count = 1
+		d.FirstSeen = t
+		d.LastSeen = t
+		seen[d.key()] = d
+		denials = append(denials, d)
+	}
+	sort.SliceStable(denials, func(i, j int) bool {
+		return denials[i].FirstSeen.Before(denials[j].FirstSeen)
+	})
+	return denials, nil
+}
+
+var auditFieldRegexp = regexp.MustCompile(`([a-z_]+)=("[^"]*"|[^ ]*)`)
+
+func auditFields(msg string) map[string]string {
+	fields := make(map[string]string)
+	for _, m := range auditFieldRegexp.FindAllStringSubmatch(msg, -1) {
+		if _, ok := fields[m[1]]; ok {
+			// keep the first occurrence
+			continue
+		}
+		fields[m[1]] = strings.Trim(m[2], `"`)
+	}
+	return fields
+}
+
+// parseDenial parses a kernel log message, it returns nil if the
+// message is not an AppArmor or seccomp denial for a snap.
+func parseDenial(msg string) *Denial {
+	switch {
+	case strings.Contains(msg, `apparmor="DENIED"`):
+		return parseAppArmorDenial(auditFields(msg))
+	case strings.Contains(msg, "type=1326"):
+		return parseSeccompDenial(auditFields(msg))
+	}
+	return nil
+}
+
+// setSnapApp sets the snap and app of the denial from the given
+// security tag, it returns false if the tag is not one of a snap.
+func (d *Denial) setSnapApp(tag string) bool {
+	parsed, err := naming.ParseSecurityTag(tag)
+	if err != nil {
+		return false
+	}
+	d.Snap = parsed.InstanceName()
+	switch t := parsed.(type) {
+	case naming.AppSecurityTag:
+		d.App = t.AppName()
+	case naming.HookSecurityTag:
+		d.App = "hook." + t.HookName()
+	}
+	return true
+}
+
+func parseAppArmorDenial(fields map[string]string) *Denial {
+	d := &Denial{Operation: fields["operation"]}
+	tag := fields["profile"]
+	if !strings.HasPrefix(tag, "snap.") {
+		tag = fields["label"]
+	}
+	if !d.setSnapApp(tag) {
+		return nil
+	}
+
+	switch {
+	case fields["capname"] != "":
+		d.Class = "capability"
+		d.Target = fields["capname"]
+	case fields["family"] != "":
+		d.Class = "network"
+		d.family = fields["family"]
+		d.sockType = fields["sock_type"]
+		d.Target = strings.TrimSpace(d.family + " " + d.sockType)
+	case strings.HasPrefix(d.Operation, "dbus_"):
+		d.Class = "dbus"
+		d.Target = fmt.Sprintf("%s %s.%s", fields["bus"], fields["interface"], fields["member"])
+		d.Permissions = fields["mask"]
+	case d.Operation == "signal":
+		d.Class = "signal"
+		d.Target = fields["peer"]
+		d.Permissions = fields["signal"]
+	case d.Operation == "ptrace":
+		d.Class = "ptrace"
+		d.Target = fields["peer"]
+	case fields["name"] != "":
+		d.Class = "file"
+		d.Target = fields["name"]
+		d.Permissions = fields["requested_mask"]
+	default:
+		d.Class = "other"
+	}
+	return d
+}
+
+// seccompRetAllow is the SECCOMP_RET_ALLOW action, logged in complain
+// mode.
+const seccompRetAllow = "0x7fff0000"
+
+func parseSeccompDenial(fields map[string]string) *Denial {
+	if fields["syscall"] == "" || fields["code"] == seccompRetAllow {
+		return nil
+	}
+	d := &Denial{Class: "syscall"}
+	if !d.setSnapApp(fields["subj"]) {
+		// without AppArmor the subject is not a snap security tag,
+		// use the executable path to find the snap
+		exe := strings.Split(strings.TrimPrefix(fields["exe"], "/"), "/")
+		if len(exe) < 3 || exe[0] != "snap" {
+			return nil
+		}
+		d.Snap = exe[1]
+	}
+	d.Target = syscallName(fields["arch"], fields["syscall"])
+	return d
+}
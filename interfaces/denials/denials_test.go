@@ -0,0 +1,188 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces/denials"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type denialsSuite struct{}
+
+var _ = Suite(&denialsSuite{})
+
+var kernelLog = []string{
+	`audit: type=1400 audit(1760000000.000:100): apparmor="DENIED" operation="open" profile="snap.foo.app" name="/dev/video0" pid=1234 comm="app" requested_mask="wr" denied_mask="wr" fsuid=1000 ouid=0`,
+	`audit: type=1400 audit(1760000001.000:101): apparmor="DENIED" operation="capable" profile="snap.foo.app" pid=1234 comm="app" capability=12  capname="net_admin"`,
+	`audit: type=1400 audit(1760000002.000:102): apparmor="DENIED" operation="open" profile="snap.bar.bar" name="/etc/shadow" pid=1235 comm="bar" requested_mask="r" denied_mask="r" fsuid=0 ouid=0`,
+	`audit: type=1326 audit(1760000003.000:103): auid=4294967295 uid=0 gid=0 ses=4294967295 subj=snap.foo.hook.configure pid=1236 comm="configure" exe="/snap/foo/1/bin/configure" sig=0 arch=c000003e syscall=165 compat=0 ip=0x7f code=0x50000`,
+	`audit: type=1400 audit(1760000004.000:104): apparmor="DENIED" operation="open" profile="snap.foo.app" name="/dev/video0" pid=1234 comm="app" requested_mask="wr" denied_mask="wr" fsuid=1000 ouid=0`,
+	`audit: type=1400 audit(1760000005.000:105): apparmor="ALLOWED" operation="open" profile="snap.foo.app" name="/etc/hosts" pid=1234 comm="app" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0`,
+	`audit: type=1326 audit(1760000006.000:106): auid=4294967295 uid=0 gid=0 ses=4294967295 subj=unconfined pid=1237 comm="app" exe="/snap/foo/1/bin/app" sig=0 arch=c000003e syscall=9999 compat=0 ip=0x7f code=0x50000`,
+	`audit: type=1400 audit(1760000007.000:107): apparmor="DENIED" operation="create" profile="snap.foo.app" pid=1234 comm="app" family="netlink" sock_type="raw" protocol=0 requested_mask="create" denied_mask="create"`,
+	`usb 1-1: new high-speed USB device number 2 using xhci_hcd`,
+}
+
+func mockKernelLog(lines []string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		var buf bytes.Buffer
+		for i, line := range lines {
+			fmt.Fprintf(&buf, `{"MESSAGE": %q, "__REALTIME_TIMESTAMP": "%d"}`+"\n", line, (1760000000+int64(i))*1000000)
+		}
+		return ioutil.NopCloser(strings.NewReader(buf.String())), nil
+	}
+}
+
+func (s *denialsSuite) TestCollect(c *C) {
+	restore := denials.MockJournalctl(mockKernelLog(kernelLog))
+	defer restore()
+
+	ds, err := denials.Collect("foo")
+	c.Assert(err, IsNil)
+	t := func(i int64) time.Time { return time.Unix(1760000000+i, 0) }
+	c.Assert(ds, HasLen, 5)
+	c.Check(ds[:4], DeepEquals, []*denials.Denial{{
+		Snap:        "foo",
+		App:         "app",
+		Class:       "file",
+		Operation:   "open",
+		Target:      "/dev/video0",
+		Permissions: "wr",
+		Count:       2,
+		FirstSeen:   t(0),
+		LastSeen:    t(4),
+	}, {
+		Snap:      "foo",
+		App:       "app",
+		Class:     "capability",
+		Operation: "capable",
+		Target:    "net_admin",
+		Count:     1,
+		FirstSeen: t(1),
+		LastSeen:  t(1),
+	}, {
+		Snap:      "foo",
+		App:       "hook.configure",
+		Class:     "syscall",
+		Target:    "mount",
+		Count:     1,
+		FirstSeen: t(3),
+		LastSeen:  t(3),
+	}, {
+		Snap:      "foo",
+		Class:     "syscall",
+		Target:    "syscall 9999",
+		Count:     1,
+		FirstSeen: t(6),
+		LastSeen:  t(6),
+	}})
+	c.Check(ds[4].App, Equals, "app")
+	c.Check(ds[4].Class, Equals, "network")
+	c.Check(ds[4].Operation, Equals, "create")
+	c.Check(ds[4].Target, Equals, "netlink raw")
+	c.Check(ds[4].FirstSeen, Equals, t(7))
+}
+
+func (s *denialsSuite) TestCollectAll(c *C) {
+	restore := denials.MockJournalctl(mockKernelLog(kernelLog))
+	defer restore()
+
+	ds, err := denials.Collect("")
+	c.Assert(err, IsNil)
+	c.Assert(ds, HasLen, 6)
+	c.Check(ds[2].Snap, Equals, "bar")
+	c.Check(ds[2].Target, Equals, "/etc/shadow")
+}
+
+func (s *denialsSuite) TestCollectError(c *C) {
+	restore := denials.MockJournalctl(func() (io.ReadCloser, error) {
+		return nil, fmt.Errorf("boom")
+	})
+	defer restore()
+
+	_, err := denials.Collect("foo")
+	c.Check(err, ErrorMatches, "cannot read kernel log: boom")
+
+	restore = denials.MockJournalctl(func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("garbage")), nil
+	})
+	defer restore()
+
+	_, err = denials.Collect("foo")
+	c.Check(err, ErrorMatches, "cannot decode kernel log: .*")
+}
+
+func (s *denialsSuite) TestSuggest(c *C) {
+	restore := denials.MockJournalctl(mockKernelLog(kernelLog))
+	defer restore()
+
+	info := snaptest.MockInfo(c, `name: foo
+version: 1
+apps:
+  app:
+hooks:
+  configure:
+`, nil)
+
+	ds, err := denials.Collect("foo")
+	c.Assert(err, IsNil)
+	denials.Suggest(info, ds)
+
+	c.Check(ds[0].SuggestedPlugs, DeepEquals, []string{"camera"})
+	c.Check(ds[1].SuggestedPlugs, testutil.Contains, "network-control")
+	c.Check(ds[2].SuggestedPlugs, testutil.Contains, "fuse-support")
+	c.Check(ds[3].SuggestedPlugs, HasLen, 0)
+	c.Check(ds[4].SuggestedPlugs, testutil.Contains, "network-observe")
+}
+
+func (s *denialsSuite) TestGlobRegexp(c *C) {
+	for _, t := range []struct {
+		glob    string
+		path    string
+		matches bool
+	}{
+		{"/dev/video[0-9]*", "/dev/video0", true},
+		{"/dev/video[0-9]*", "/dev/video0/foo", false},
+		{"/sys/devices/**", "/sys/devices/pci0000:00/foo", true},
+		{"/etc/{passwd,group}", "/etc/group", true},
+		{"/etc/{passwd,group}", "/etc/shadow", false},
+		{"/run/foo?", "/run/foo1", true},
+		{"/var/lib/foo{,/**}", "/var/lib/foo", true},
+		{"/var/lib/foo{,/**}", "/var/lib/foo/bar/baz", true},
+		{"/a.b", "/axb", false},
+	} {
+		re, err := denials.GlobRegexp(t.glob)
+		c.Assert(err, IsNil)
+		c.Check(re.MatchString(t.path), Equals, t.matches, Commentf("%s %s", t.glob, t.path))
+	}
+}
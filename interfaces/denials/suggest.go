@@ -0,0 +1,318 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+)
+
+// policy is the subset of the AppArmor and seccomp policy granted by
+// the plug of an interface that is relevant to match denials.
+type policy struct {
+	files        []fileRule
+	capabilities map[string]bool
+	network      []networkRule
+	syscalls     map[string]bool
+}
+
+type fileRule struct {
+	path  *regexp.Regexp
+	perms string
+}
+
+type networkRule struct {
+	// empty if the rule allows any
+	family   string
+	sockType string
+}
+
+func (p *policy) allows(d *Denial) bool {
+	switch d.Class {
+	case "file":
+		for _, r := range p.files {
+			if r.path.MatchString(d.Target) && permsAllow(r.perms, d.Permissions) {
+				return true
+			}
+		}
+	case "capability":
+		return p.capabilities[d.Target]
+	case "network":
+		for _, r := range p.network {
+			if (r.family == "" || r.family == d.family) && (r.sockType == "" || r.sockType == d.sockType) {
+				return true
+			}
+		}
+	case "syscall":
+		return p.syscalls[d.Target]
+	}
+	return false
+}
+
+// permsAllow returns whether the AppArmor file rule permissions allow
+// the requested access mask as logged in denials.
+func permsAllow(rulePerms, requested string) bool {
+	if requested == "" {
+		return false
+	}
+	for _, p := range requested {
+		switch p {
+		case 'c', 'd', 'a':
+			// create, delete and append are implied by write
+			if !strings.ContainsRune(rulePerms, p) && !strings.ContainsRune(rulePerms, 'w') {
+				return false
+			}
+		case 'x':
+			if !strings.ContainsAny(rulePerms, "xX") {
+				return false
+			}
+		default:
+			if !strings.ContainsRune(rulePerms, p) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Suggest sets the SuggestedPlugs of the given denials of the given
+// snap to the interfaces, with a slot implicitly provided by the
+// system, whose plug policy would allow the denied access.
+func Suggest(info *snap.Info, denials []*Denial) {
+	var policies []*policy
+	var names []string
+	for _, iface := range builtin.Interfaces() {
+		p := plugPolicy(info, iface)
+		if p == nil {
+			continue
+		}
+		policies = append(policies, p)
+		names = append(names, iface.Name())
+	}
+
+	for _, d := range denials {
+		d.SuggestedPlugs = nil
+		for i, p := range policies {
+			if p.allows(d) {
+				d.SuggestedPlugs = append(d.SuggestedPlugs, names[i])
+			}
+		}
+	}
+}
+
+// plugPolicy returns the policy granted to the given snap by a plug of
+// the given interface connected to the system, without attributes.
+// It returns nil if such a plug or slot is not possible.
+func plugPolicy(info *snap.Info, iface interfaces.Interface) *policy {
+	si := interfaces.StaticInfoOf(iface)
+	if !si.ImplicitOnCore && !si.ImplicitOnClassic {
+		return nil
+	}
+
+	plugInfo := &snap.PlugInfo{
+		Snap:      info,
+		Name:      iface.Name(),
+		Interface: iface.Name(),
+		Apps:      info.Apps,
+		Hooks:     info.Hooks,
+	}
+	if err := interfaces.BeforePreparePlug(iface, plugInfo); err != nil {
+		return nil
+	}
+	slotInfo := &snap.SlotInfo{
+		Snap:      &snap.Info{SuggestedName: "core", SnapType: snap.TypeOS},
+		Name:      iface.Name(),
+		Interface: iface.Name(),
+	}
+	if err := interfaces.BeforePrepareSlot(iface, slotInfo); err != nil {
+		return nil
+	}
+	plug := interfaces.NewConnectedPlug(plugInfo, nil, map[string]interface{}{})
+	slot := interfaces.NewConnectedSlot(slotInfo, nil, map[string]interface{}{})
+
+	var snippets []string
+	aaSpec := &apparmor.Specification{}
+	if aaSpec.AddConnectedPlug(iface, plug, slot) == nil && aaSpec.AddPermanentPlug(iface, plugInfo) == nil {
+		for _, tagSnippets := range aaSpec.Snippets() {
+			snippets = append(snippets, tagSnippets...)
+		}
+	}
+	p := &policy{
+		capabilities: make(map[string]bool),
+		syscalls:     make(map[string]bool),
+	}
+	for _, snippet := range snippets {
+		p.addAppArmorSnippet(info, snippet)
+	}
+
+	seccompSpec := &seccomp.Specification{}
+	if seccompSpec.AddConnectedPlug(iface, plug, slot) == nil && seccompSpec.AddPermanentPlug(iface, plugInfo) == nil {
+		for _, tagSnippets := range seccompSpec.Snippets() {
+			for _, snippet := range tagSnippets {
+				p.addSeccompSnippet(snippet)
+			}
+		}
+	}
+	return p
+}
+
+func snippetLines(snippet string) []string {
+	var lines []string
+	for _, line := range strings.Split(snippet, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func (p *policy) addAppArmorSnippet(info *snap.Info, snippet string) {
+	for _, line := range snippetLines(snippet) {
+		if !strings.HasSuffix(line, ",") {
+			// not a complete rule
+			continue
+		}
+		fields := strings.Fields(strings.TrimSuffix(line, ","))
+		for len(fields) > 0 && (fields[0] == "audit" || fields[0] == "owner" || fields[0] == "allow") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || fields[0] == "deny" {
+			continue
+		}
+
+		switch kind := fields[0]; {
+		case kind == "capability":
+			for _, c := range fields[1:] {
+				p.capabilities[c] = true
+			}
+		case kind == "network":
+			r := networkRule{}
+			if len(fields) > 1 {
+				r.family = fields[1]
+			}
+			if len(fields) > 2 {
+				r.sockType = fields[2]
+			}
+			p.network = append(p.network, r)
+		case strings.HasPrefix(kind, "/") || strings.HasPrefix(kind, "@{") || strings.HasPrefix(kind, `"`):
+			if len(fields) < 2 {
+				continue
+			}
+			glob := expandAppArmorVariables(info, strings.Trim(kind, `"`))
+			re, err := globRegexp(doubleSlashRegexp.ReplaceAllString(glob, "/"))
+			if err != nil {
+				continue
+			}
+			p.files = append(p.files, fileRule{path: re, perms: fields[1]})
+		}
+	}
+}
+
+func (p *policy) addSeccompSnippet(snippet string) {
+	for _, line := range snippetLines(snippet) {
+		syscall := strings.Fields(line)[0]
+		if strings.HasPrefix(syscall, "@") || strings.HasPrefix(syscall, "~") {
+			continue
+		}
+		p.syscalls[syscall] = true
+	}
+}
+
+var (
+	appArmorVariableRegexp = regexp.MustCompile(`@\{[A-Za-z_]+\}`)
+	doubleSlashRegexp      = regexp.MustCompile(`//+`)
+)
+
+// expandAppArmorVariables expands the AppArmor variables defined by the
+// snap policy templates into equivalent globs.
+func expandAppArmorVariables(info *snap.Info, path string) string {
+	return appArmorVariableRegexp.ReplaceAllStringFunc(path, func(v string) string {
+		switch v {
+		case "@{PROC}":
+			return "/proc"
+		case "@{HOME}":
+			return "{/home/*,/root}"
+		case "@{SNAP_NAME}":
+			return info.SnapName()
+		case "@{SNAP_INSTANCE_NAME}":
+			return info.InstanceName()
+		case "@{SNAP_COMMON}":
+			return "/var/snap/" + info.InstanceName() + "/common"
+		case "@{INSTALL_DIR}":
+			return "{/snap,/var/lib/snapd/snap}"
+		}
+		return "*"
+	})
+}
+
+// globRegexp converts an AppArmor glob to an anchored regular
+// expression.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var buf bytes.Buffer
+	buf.WriteString("^")
+	depth := 0
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				buf.WriteString(".*")
+				i++
+			} else {
+				buf.WriteString("[^/]*")
+			}
+		case '?':
+			buf.WriteString("[^/]")
+		case '{':
+			buf.WriteString("(?:")
+			depth++
+		case '}':
+			buf.WriteString(")")
+			depth--
+		case ',':
+			if depth > 0 {
+				buf.WriteString("|")
+			} else {
+				buf.WriteString(",")
+			}
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				buf.WriteString(`\[`)
+				continue
+			}
+			buf.WriteString(glob[i : i+end+1])
+			i += end
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	buf.WriteString("$")
+	return regexp.Compile(buf.String())
+}
@@ -0,0 +1,141 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// audit architectures, see linux/audit.h
+const (
+	auditArchX86_64  = "c000003e"
+	auditArchAarch64 = "c00000b7"
+)
+
+// syscallNames maps the numbers of the syscalls that are usually
+// denied by the default seccomp template to their names, per audit
+// architecture.
+var syscallNames = map[string]map[int]string{
+	auditArchX86_64: {
+		16:  "ioctl",
+		41:  "socket",
+		42:  "connect",
+		49:  "bind",
+		62:  "kill",
+		90:  "chmod",
+		91:  "fchmod",
+		92:  "chown",
+		93:  "fchown",
+		94:  "lchown",
+		101: "ptrace",
+		105: "setuid",
+		106: "setgid",
+		113: "setreuid",
+		114: "setregid",
+		116: "setgroups",
+		117: "setresuid",
+		119: "setresgid",
+		122: "setfsuid",
+		123: "setfsgid",
+		133: "mknod",
+		141: "setpriority",
+		144: "sched_setscheduler",
+		149: "mlock",
+		151: "mlockall",
+		155: "pivot_root",
+		157: "prctl",
+		160: "setrlimit",
+		161: "chroot",
+		163: "acct",
+		164: "settimeofday",
+		165: "mount",
+		166: "umount2",
+		167: "swapon",
+		168: "swapoff",
+		169: "reboot",
+		170: "sethostname",
+		171: "setdomainname",
+		172: "iopl",
+		173: "ioperm",
+		175: "init_module",
+		176: "delete_module",
+		179: "quotactl",
+		227: "clock_settime",
+		246: "kexec_load",
+		248: "add_key",
+		250: "keyctl",
+		251: "ioprio_set",
+		259: "mknodat",
+		260: "fchownat",
+		272: "unshare",
+		298: "perf_event_open",
+		303: "name_to_handle_at",
+		304: "open_by_handle_at",
+		308: "setns",
+		310: "process_vm_readv",
+		311: "process_vm_writev",
+		313: "finit_module",
+		321: "bpf",
+	},
+	auditArchAarch64: {
+		29:  "ioctl",
+		33:  "mknodat",
+		39:  "umount2",
+		40:  "mount",
+		41:  "pivot_root",
+		51:  "chroot",
+		54:  "fchownat",
+		55:  "fchown",
+		97:  "unshare",
+		105: "init_module",
+		106: "delete_module",
+		117: "ptrace",
+		140: "setpriority",
+		142: "reboot",
+		144: "setgid",
+		146: "setuid",
+		147: "setresuid",
+		149: "setresgid",
+		159: "setgroups",
+		161: "sethostname",
+		162: "setdomainname",
+		167: "prctl",
+		198: "socket",
+		200: "bind",
+		203: "connect",
+		219: "keyctl",
+		241: "perf_event_open",
+		268: "setns",
+		273: "finit_module",
+		280: "bpf",
+	},
+}
+
+// syscallName returns the name of the syscall with the given number
+// for the given audit architecture, or "syscall <number>" if unknown.
+func syscallName(arch, number string) string {
+	if n, err := strconv.Atoi(number); err == nil {
+		if name := syscallNames[arch][n]; name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("syscall %s", number)
+}
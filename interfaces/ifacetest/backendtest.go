@@ -65,6 +65,7 @@ func (s *BackendSuite) SetUpTest(c *C) {
 func (s *BackendSuite) TearDownTest(c *C) {
 	dirs.SetRootDir("/")
 	s.restoreSanitize()
+	s.BaseTest.TearDownTest(c)
 }
 
 // Tests for Setup() and Remove()
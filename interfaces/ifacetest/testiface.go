@@ -26,6 +26,7 @@ import (
 	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/kmod"
 	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/interfaces/nftables"
 	"github.com/snapcore/snapd/interfaces/seccomp"
 	"github.com/snapcore/snapd/interfaces/systemd"
 	"github.com/snapcore/snapd/interfaces/udev"
@@ -103,6 +104,13 @@ type TestInterface struct {
 	SystemdConnectedSlotCallback func(spec *systemd.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error
 	SystemdPermanentPlugCallback func(spec *systemd.Specification, plug *snap.PlugInfo) error
 	SystemdPermanentSlotCallback func(spec *systemd.Specification, slot *snap.SlotInfo) error
+
+	// Support for interacting with the nftables backend.
+
+	NftablesConnectedPlugCallback func(spec *nftables.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error
+	NftablesConnectedSlotCallback func(spec *nftables.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error
+	NftablesPermanentPlugCallback func(spec *nftables.Specification, plug *snap.PlugInfo) error
+	NftablesPermanentSlotCallback func(spec *nftables.Specification, slot *snap.SlotInfo) error
 }
 
 // TestHotplugInterface is an interface for various kinds of tests
@@ -434,3 +442,33 @@ func (t *TestHotplugInterface) HandledByGadget(deviceInfo *hotplug.HotplugDevice
 	}
 	return false
 }
+
+// Support for interacting with the nftables backend.
+
+func (t *TestInterface) NftablesConnectedPlug(spec *nftables.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if t.NftablesConnectedPlugCallback != nil {
+		return t.NftablesConnectedPlugCallback(spec, plug, slot)
+	}
+	return nil
+}
+
+func (t *TestInterface) NftablesConnectedSlot(spec *nftables.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	if t.NftablesConnectedSlotCallback != nil {
+		return t.NftablesConnectedSlotCallback(spec, plug, slot)
+	}
+	return nil
+}
+
+func (t *TestInterface) NftablesPermanentPlug(spec *nftables.Specification, plug *snap.PlugInfo) error {
+	if t.NftablesPermanentPlugCallback != nil {
+		return t.NftablesPermanentPlugCallback(spec, plug)
+	}
+	return nil
+}
+
+func (t *TestInterface) NftablesPermanentSlot(spec *nftables.Specification, slot *snap.SlotInfo) error {
+	if t.NftablesPermanentSlotCallback != nil {
+		return t.NftablesPermanentSlotCallback(spec, slot)
+	}
+	return nil
+}
@@ -99,6 +99,18 @@ func (b *Backend) Setup(snapInfo *snap.Info, confinement interfaces.ConfinementO
 	return nil
 }
 
+// Profiles returns the modules config file of the given snap, as Setup
+// would write it, without writing it nor loading the modules.
+func (b *Backend) Profiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string][]byte, error) {
+	snapName := snapInfo.InstanceName()
+	spec, err := repo.SnapSpecification(b.Name(), snapName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain kmod specification for snap %q: %s", snapName, err)
+	}
+	content, _ := deriveContent(spec.(*Specification), snapInfo)
+	return interfaces.ProfilesFromContent(dirs.SnapKModModulesDir, content)
+}
+
 // Remove removes modules config file specific to a given snap.
 //
 // This method should be called after removing a snap.
@@ -32,6 +32,7 @@ import (
 	"github.com/snapcore/snapd/interfaces/kmod"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/timings"
 )
 
@@ -140,3 +141,22 @@ func (s *backendSuite) TestSecurityIsStable(c *C) {
 func (s *backendSuite) TestSandboxFeatures(c *C) {
 	c.Assert(s.Backend.SandboxFeatures(), DeepEquals, []string{"mediated-modprobe"})
 }
+
+func (s *backendSuite) TestProfiles(c *C) {
+	s.Iface.KModPermanentSlotCallback = func(spec *kmod.Specification, slot *snap.SlotInfo) error {
+		spec.AddModule("module1")
+		return nil
+	}
+	snapInfo := snaptest.MockInfo(c, ifacetest.SambaYamlV1, nil)
+	c.Assert(s.Repo.AddSnap(snapInfo), IsNil)
+
+	profiles, err := s.Backend.(interfaces.SecurityBackendProfiles).Profiles(snapInfo, interfaces.ConfinementOptions{}, s.Repo)
+	c.Assert(err, IsNil)
+	path := filepath.Join(dirs.SnapKModModulesDir, "snap.samba.conf")
+	c.Check(profiles, DeepEquals, map[string][]byte{
+		path: []byte("# This file is automatically generated.\nmodule1\n"),
+	})
+	// nothing was written nor loaded
+	c.Check(path, testutil.FileAbsent)
+	c.Check(s.modprobeCmd.Calls(), HasLen, 0)
+}
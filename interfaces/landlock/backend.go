@@ -0,0 +1,173 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package landlock implements a security backend confining the
+// applications and hooks of snaps with Landlock rulesets.
+//
+// It is meant for systems without AppArmor, where it restricts the
+// filesystem hierarchies snaps can access. The rulesets are derived
+// from the AppArmor file rules of the interfaces, see Specification,
+// and are written to /var/lib/snapd/landlock/<security-tag>.json.
+// snap-exec applies them before executing the application or hook.
+// Snaps in devmode or classic snaps are not confined.
+package landlock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/sandbox/landlock"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/timings"
+)
+
+// Backend is responsible for maintaining Landlock rulesets for snaps.
+type Backend struct{}
+
+// Initialize does nothing.
+func (b *Backend) Initialize(*interfaces.SecurityBackendOptions) error {
+	return nil
+}
+
+// Name returns the name of the backend.
+func (b *Backend) Name() interfaces.SecuritySystem {
+	return interfaces.SecurityLandlock
+}
+
+// Setup creates the Landlock rulesets of the applications and hooks of
+// the given snap and removes stale ones.
+func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository, tm timings.Measurer) error {
+	snapName := snapInfo.InstanceName()
+	content, err := b.deriveContent(snapInfo, opts, repo)
+	if err != nil {
+		return err
+	}
+
+	dir := dirs.SnapLandlockDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("cannot create directory for landlock rulesets %q: %s", dir, err)
+	}
+	glob := interfaces.SecurityTagGlob(snapName) + ".json"
+	if _, _, err := osutil.EnsureDirState(dir, glob, content); err != nil {
+		return fmt.Errorf("cannot synchronize landlock rulesets for snap %q: %s", snapName, err)
+	}
+	return nil
+}
+
+// Profiles returns the Landlock rulesets of the given snap, as Setup
+// would write them.
+func (b *Backend) Profiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string][]byte, error) {
+	content, err := b.deriveContent(snapInfo, opts, repo)
+	if err != nil {
+		return nil, err
+	}
+	return interfaces.ProfilesFromContent(dirs.SnapLandlockDir, content)
+}
+
+// Remove removes the Landlock rulesets of the given snap.
+func (b *Backend) Remove(snapName string) error {
+	glob := interfaces.SecurityTagGlob(snapName) + ".json"
+	_, _, err := osutil.EnsureDirState(dirs.SnapLandlockDir, glob, nil)
+	if err != nil {
+		return fmt.Errorf("cannot synchronize landlock rulesets for snap %q: %s", snapName, err)
+	}
+	return nil
+}
+
+// NewSpecification returns a new landlock specification.
+func (b *Backend) NewSpecification() interfaces.Specification {
+	return &Specification{}
+}
+
+// SandboxFeatures returns the Landlock features supported by the
+// kernel.
+func (b *Backend) SandboxFeatures() []string {
+	return landlock.Features()
+}
+
+func (b *Backend) deriveContent(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string]osutil.FileState, error) {
+	if opts.DevMode || (opts.Classic && !opts.JailMode) {
+		// Landlock has no complain mode
+		return nil, nil
+	}
+
+	snapName := snapInfo.InstanceName()
+	s, err := repo.SnapSpecification(b.Name(), snapName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain landlock specification for snap %q: %s", snapName, err)
+	}
+	spec := s.(*Specification)
+	spec.AddLayout(snapInfo)
+
+	var tags []string
+	for _, app := range snapInfo.Apps {
+		tags = append(tags, app.SecurityTag())
+	}
+	for _, hook := range snapInfo.Hooks {
+		tags = append(tags, hook.SecurityTag())
+	}
+
+	content := make(map[string]osutil.FileState, len(tags))
+	for _, tag := range tags {
+		rules := append(baseRules(snapInfo), spec.Rules(snapInfo, tag)...)
+		data, err := json.Marshal(newRuleset(rules))
+		if err != nil {
+			return nil, err
+		}
+		content[tag+".json"] = &osutil.MemoryFileState{
+			Content: append(data, '\n'),
+			Mode:    0644,
+		}
+	}
+	return content, nil
+}
+
+// newRuleset merges the accesses of the rules with the same path.
+func newRuleset(rules []rule) *landlock.Ruleset {
+	accessByPath := make(map[string]map[landlock.Access]bool)
+	for _, r := range rules {
+		if accessByPath[r.path] == nil {
+			accessByPath[r.path] = make(map[landlock.Access]bool)
+		}
+		for _, a := range r.access {
+			accessByPath[r.path][a] = true
+		}
+	}
+
+	rs := &landlock.Ruleset{Rules: make([]landlock.Rule, 0, len(accessByPath))}
+	for path, accessSet := range accessByPath {
+		r := landlock.Rule{Path: path}
+		// keep a stable order
+		for _, a := range []landlock.Access{landlock.AccessRead, landlock.AccessWrite, landlock.AccessExecute, landlock.AccessList} {
+			if accessSet[a] {
+				r.Access = append(r.Access, a)
+			}
+		}
+		rs.Rules = append(rs.Rules, r)
+	}
+	sort.Slice(rs.Rules, func(i, j int) bool {
+		return rs.Rules[i].Path < rs.Rules[j].Path
+	})
+	return rs
+}
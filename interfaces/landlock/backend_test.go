@@ -0,0 +1,147 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/landlock"
+	"github.com/snapcore/snapd/osutil"
+	landlock_sandbox "github.com/snapcore/snapd/sandbox/landlock"
+	"github.com/snapcore/snapd/snap"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type backendSuite struct {
+	ifacetest.BackendSuite
+}
+
+var _ = Suite(&backendSuite{})
+
+func (s *backendSuite) SetUpTest(c *C) {
+	s.Backend = &landlock.Backend{}
+	s.BackendSuite.SetUpTest(c)
+	c.Assert(s.Repo.AddBackend(s.Backend), IsNil)
+}
+
+func (s *backendSuite) TestName(c *C) {
+	c.Check(s.Backend.Name(), Equals, interfaces.SecurityLandlock)
+}
+
+func (s *backendSuite) loadRuleset(c *C, path string) map[string][]landlock_sandbox.Access {
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	var rs landlock_sandbox.Ruleset
+	c.Assert(json.Unmarshal(data, &rs), IsNil)
+	rules := make(map[string][]landlock_sandbox.Access, len(rs.Rules))
+	for _, r := range rs.Rules {
+		rules[r.Path] = r.Access
+	}
+	return rules
+}
+
+func (s *backendSuite) TestInstallingSnapWritesRulesets(c *C) {
+	s.Iface.AppArmorPermanentSlotCallback = func(spec *apparmor.Specification, slot *snap.SlotInfo) error {
+		spec.AddSnippet("/dev/ttyUSB[0-9]* rw,\nowner @{HOME}/.config/foo/** rw,\ncapability net_admin,")
+		return nil
+	}
+	snapInfo := s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 1)
+
+	path := filepath.Join(dirs.SnapLandlockDir, "snap.samba.smbd.json")
+	rules := s.loadRuleset(c, path)
+	c.Check(rules["/dev"], DeepEquals, []landlock_sandbox.Access{landlock_sandbox.AccessRead, landlock_sandbox.AccessWrite, landlock_sandbox.AccessList})
+	c.Check(rules["$SNAP_REAL_HOME/.config/foo"], DeepEquals, []landlock_sandbox.Access{landlock_sandbox.AccessRead, landlock_sandbox.AccessWrite})
+	c.Check(rules["/var/snap/samba"], DeepEquals, []landlock_sandbox.Access{landlock_sandbox.AccessRead, landlock_sandbox.AccessWrite, landlock_sandbox.AccessExecute})
+	c.Check(rules["/etc"], DeepEquals, []landlock_sandbox.Access{landlock_sandbox.AccessRead})
+
+	s.RemoveSnap(c, snapInfo)
+	c.Check(osutil.FileExists(path), Equals, false)
+}
+
+func (s *backendSuite) TestDevAndSysCanOnlyBeListed(c *C) {
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 1)
+
+	rules := s.loadRuleset(c, filepath.Join(dirs.SnapLandlockDir, "snap.samba.smbd.json"))
+	listOnly := []landlock_sandbox.Access{landlock_sandbox.AccessList}
+	c.Check(rules["/dev"], DeepEquals, listOnly)
+	c.Check(rules["/sys"], DeepEquals, listOnly)
+	c.Check(rules["/dev/null"], DeepEquals, []landlock_sandbox.Access{landlock_sandbox.AccessRead, landlock_sandbox.AccessWrite})
+	c.Check(rules["/sys/devices/system/cpu"], DeepEquals, []landlock_sandbox.Access{landlock_sandbox.AccessRead})
+}
+
+func (s *backendSuite) TestInstallingSnapWithInstanceKey(c *C) {
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "samba_foo", ifacetest.SambaYamlV1, 1)
+
+	rules := s.loadRuleset(c, filepath.Join(dirs.SnapLandlockDir, "snap.samba_foo.smbd.json"))
+	snapMountDir := dirs.StripRootDir(dirs.SnapMountDir)
+	c.Check(rules[filepath.Join(snapMountDir, "samba")], NotNil)
+	c.Check(rules[filepath.Join(snapMountDir, "samba_foo")], NotNil)
+	c.Check(rules["/var/snap/samba_foo"], NotNil)
+	c.Check(rules["/var/snap/samba"], IsNil)
+}
+
+func (s *backendSuite) TestUnconfinedSnapsHaveNoRulesets(c *C) {
+	for _, opts := range []interfaces.ConfinementOptions{
+		{DevMode: true},
+		{Classic: true},
+	} {
+		snapInfo := s.InstallSnap(c, opts, "", ifacetest.SambaYamlV1, 1)
+		c.Check(osutil.FileExists(filepath.Join(dirs.SnapLandlockDir, "snap.samba.smbd.json")), Equals, false, Commentf("%+v", opts))
+		s.RemoveSnap(c, snapInfo)
+	}
+
+	s.InstallSnap(c, interfaces.ConfinementOptions{Classic: true, JailMode: true}, "", ifacetest.SambaYamlV1, 1)
+	c.Check(osutil.FileExists(filepath.Join(dirs.SnapLandlockDir, "snap.samba.smbd.json")), Equals, true)
+}
+
+func (s *backendSuite) TestProfiles(c *C) {
+	snapInfo := s.InstallSnap(c, interfaces.ConfinementOptions{}, "", ifacetest.SambaYamlV1, 1)
+
+	backend := s.Backend.(interfaces.SecurityBackendProfiles)
+	profiles, err := backend.Profiles(snapInfo, interfaces.ConfinementOptions{}, s.Repo)
+	c.Assert(err, IsNil)
+	path := filepath.Join(dirs.SnapLandlockDir, "snap.samba.smbd.json")
+	c.Assert(profiles, HasLen, 1)
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Check(string(profiles[path]), Equals, string(data))
+}
+
+func (s *backendSuite) TestSandboxFeatures(c *C) {
+	restore := landlock_sandbox.MockABI(0)
+	c.Check(s.Backend.SandboxFeatures(), IsNil)
+	restore()
+
+	restore = landlock_sandbox.MockABI(3)
+	defer restore()
+	c.Check(s.Backend.SandboxFeatures(), DeepEquals, []string{"abi:3", "refer", "truncate"})
+}
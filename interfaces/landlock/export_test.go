@@ -0,0 +1,45 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock
+
+import (
+	"github.com/snapcore/snapd/sandbox/landlock"
+	"github.com/snapcore/snapd/snap"
+)
+
+var (
+	BaseRules    = baseRules
+	RulePath     = rulePath
+	ExpandBraces = expandBraces
+)
+
+// RulesFromAppArmor returns the Landlock rules translated from the given
+// AppArmor rule, as path to accesses.
+func RulesFromAppArmor(si *snap.Info, line string) map[string][]landlock.Access {
+	rules := rulesFromAppArmor(si, line)
+	if rules == nil {
+		return nil
+	}
+	m := make(map[string][]landlock.Access, len(rules))
+	for _, r := range rules {
+		m[r.path] = r.access
+	}
+	return m
+}
@@ -0,0 +1,212 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/snapcore/snapd/sandbox/landlock"
+	"github.com/snapcore/snapd/snap"
+)
+
+// rule is a Landlock rule as derived from an AppArmor file rule.
+type rule struct {
+	path   string
+	access []landlock.Access
+}
+
+func snippetLines(snippet string) []string {
+	var lines []string
+	for _, line := range strings.Split(snippet, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// rulesFromAppArmor translates an AppArmor file rule into Landlock
+// rules. Landlock rules apply to whole hierarchies and cannot express
+// patterns: the paths are cut before the first component with a
+// pattern that is not resolvable when the ruleset is applied, which
+// makes the translated rules coarser than the original ones.
+func rulesFromAppArmor(si *snap.Info, line string) []rule {
+	if !strings.HasSuffix(line, ",") {
+		// not a complete rule
+		return nil
+	}
+	rest := strings.TrimSpace(strings.TrimSuffix(line, ","))
+	for {
+		qualifier := strings.Fields(rest)
+		if len(qualifier) == 0 || (qualifier[0] != "audit" && qualifier[0] != "owner" && qualifier[0] != "allow") {
+			break
+		}
+		rest = strings.TrimSpace(rest[len(qualifier[0]):])
+	}
+	var path string
+	if strings.HasPrefix(rest, `"`) {
+		// quoted paths can contain spaces
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return nil
+		}
+		path, rest = rest[1:end+1], rest[end+2:]
+	} else {
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil
+		}
+		path, rest = fields[0], rest[len(fields[0]):]
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return nil
+	}
+	if !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "@{") {
+		return nil
+	}
+	access := accessFromPerms(fields[0])
+	if len(access) == 0 {
+		return nil
+	}
+
+	var rules []rule
+	for _, glob := range expandBraces(expandAppArmorVariables(si, path)) {
+		if p := rulePath(glob); p != "" {
+			rules = append(rules, rule{path: p, access: access})
+		}
+	}
+	return rules
+}
+
+// accessFromPerms translates AppArmor file permissions.
+func accessFromPerms(perms string) []landlock.Access {
+	var access []landlock.Access
+	if strings.ContainsRune(perms, 'r') {
+		access = append(access, landlock.AccessRead)
+	}
+	if strings.ContainsAny(perms, "wa") {
+		access = append(access, landlock.AccessWrite)
+	}
+	if strings.ContainsAny(perms, "mxX") {
+		access = append(access, landlock.AccessExecute)
+	}
+	return access
+}
+
+var appArmorVariableRegexp = regexp.MustCompile(`@\{[A-Za-z_]+\}`)
+
+// expandAppArmorVariables expands the AppArmor variables defined by the
+// snap policy templates to their value in the mount namespace of the
+// snap, or to patterns.
+func expandAppArmorVariables(si *snap.Info, path string) string {
+	return appArmorVariableRegexp.ReplaceAllStringFunc(path, func(v string) string {
+		switch v {
+		case "@{HOME}":
+			return "$SNAP_REAL_HOME"
+		case "@{PROC}":
+			return "/proc"
+		case "@{SNAP_NAME}":
+			return si.SnapName()
+		case "@{SNAP_INSTANCE_NAME}":
+			return si.InstanceName()
+		case "@{SNAP_REVISION}":
+			return si.Revision.String()
+		case "@{INSTALL_DIR}":
+			return snapMountDir()
+		}
+		return "*"
+	})
+}
+
+// expandBraces expands the AppArmor alternations of the pattern.
+func expandBraces(glob string) []string {
+	start := strings.IndexByte(glob, '{')
+	if start < 0 {
+		return []string{glob}
+	}
+	// find the matching closing brace and the top level commas
+	depth := 0
+	commas := []int{}
+	end := -1
+	for i := start; i < len(glob) && end < 0; i++ {
+		switch glob[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		}
+	}
+	if end < 0 {
+		// unbalanced, leave as is
+		return []string{glob}
+	}
+	var expanded []string
+	prev := start + 1
+	for _, i := range append(commas, end) {
+		alternative := glob[:start] + glob[prev:i] + glob[end+1:]
+		expanded = append(expanded, expandBraces(alternative)...)
+		prev = i + 1
+	}
+	return expanded
+}
+
+// rulePath returns the path of the Landlock rule matching the given
+// pattern, or an empty string if there is none. Patterns are kept in
+// intermediate components, they are resolved when the ruleset is
+// applied, but the components from the last one or from a recursive
+// one are dropped so that the rule also applies to the entries
+// created later. Rules for listing a directory are ignored as Landlock
+// rules for a directory apply to everything beneath it, and so are
+// the ones that would apply to the whole filesystem without the
+// pattern asking for it.
+func rulePath(glob string) string {
+	if strings.HasSuffix(glob, "/") && !strings.ContainsAny(glob, "*?[") {
+		return ""
+	}
+	components := strings.Split(filepath.Clean(glob), "/")
+	kept := components[:0]
+	for i, component := range components {
+		if strings.Contains(component, "**") || (i == len(components)-1 && strings.ContainsAny(component, "*?[")) {
+			break
+		}
+		kept = append(kept, component)
+	}
+	path := strings.Join(kept, "/")
+	if path == "" || path == "/" {
+		if !strings.HasPrefix(glob, "/**") {
+			return ""
+		}
+		path = "/"
+	}
+	return path
+}
@@ -0,0 +1,89 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces/landlock"
+	landlock_sandbox "github.com/snapcore/snapd/sandbox/landlock"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+type rulesSuite struct {
+	info *snap.Info
+}
+
+var _ = Suite(&rulesSuite{})
+
+func (s *rulesSuite) SetUpTest(c *C) {
+	s.info = snaptest.MockInfo(c, "name: foo\nversion: 1\napps:\n app:\n", &snap.SideInfo{Revision: snap.R(7)})
+}
+
+var (
+	r   = landlock_sandbox.AccessRead
+	w   = landlock_sandbox.AccessWrite
+	x   = landlock_sandbox.AccessExecute
+	rw  = []landlock_sandbox.Access{r, w}
+	ro  = []landlock_sandbox.Access{r}
+	rwx = []landlock_sandbox.Access{r, w, x}
+)
+
+func (s *rulesSuite) TestRulesFromAppArmor(c *C) {
+	snapMountDir := dirs.StripRootDir(dirs.SnapMountDir)
+	for _, t := range []struct {
+		line  string
+		rules map[string][]landlock_sandbox.Access
+	}{
+		{"/etc/foo.conf r,", map[string][]landlock_sandbox.Access{"/etc/foo.conf": ro}},
+		{"owner /run/foo/** rw,", map[string][]landlock_sandbox.Access{"/run/foo": rw}},
+		{"audit owner /run/bar/{,**} rwk,", map[string][]landlock_sandbox.Access{"/run/bar": rw}},
+		{`"/var/lib/foo bar/" r,`, nil},
+		{`owner "/var/lib/foo bar/*" rw,`, map[string][]landlock_sandbox.Access{"/var/lib/foo bar": rw}},
+		{"/dev/ttyUSB[0-9]* rw,", map[string][]landlock_sandbox.Access{"/dev": rw}},
+		{"@{HOME}/.config/foo/ r,", nil},
+		{"owner @{HOME}/.config/foo/** rwmix,", map[string][]landlock_sandbox.Access{"$SNAP_REAL_HOME/.config/foo": rwx}},
+		{"@{PROC}/@{pid}/mounts r,", map[string][]landlock_sandbox.Access{"/proc/*/mounts": ro}},
+		{"/{,usr/}lib/foo/ r,", nil},
+		{"/{,usr/}lib/foo/* r,", map[string][]landlock_sandbox.Access{"/lib/foo": ro, "/usr/lib/foo": ro}},
+		{"@{INSTALL_DIR}/@{SNAP_NAME}/@{SNAP_REVISION}/bin/foo ix,", map[string][]landlock_sandbox.Access{snapMountDir + "/foo/7/bin/foo": {x}}},
+		{"/** r,", map[string][]landlock_sandbox.Access{"/": ro}},
+		{"/* r,", nil},
+		// not file rules
+		{"deny /etc/shadow r,", nil},
+		{"capability sys_admin,", nil},
+		{"network inet stream,", nil},
+		{"dbus (send) bus=system,", nil},
+		{"/usr/bin/foo Px -> foo,", map[string][]landlock_sandbox.Access{"/usr/bin/foo": {x}}},
+		{"/etc/foo k,", nil},
+		{"/etc/foo r", nil},
+	} {
+		c.Check(landlock.RulesFromAppArmor(s.info, t.line), DeepEquals, t.rules, Commentf(t.line))
+	}
+}
+
+func (s *rulesSuite) TestExpandBraces(c *C) {
+	c.Check(landlock.ExpandBraces("/foo"), DeepEquals, []string{"/foo"})
+	c.Check(landlock.ExpandBraces("/{a,b}/{c,d{e,f}}"), DeepEquals, []string{"/a/c", "/a/de", "/a/df", "/b/c", "/b/de", "/b/df"})
+	c.Check(landlock.ExpandBraces("/{,usr/}lib"), DeepEquals, []string{"/lib", "/usr/lib"})
+	c.Check(landlock.ExpandBraces("/{a,b"), DeepEquals, []string{"/{a,b"})
+}
@@ -0,0 +1,73 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock
+
+import (
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/snap"
+)
+
+// Specification collects the filesystem accesses granted to the
+// applications and hooks of a snap by the interfaces.
+//
+// Interfaces do not describe Landlock rules on their own: the
+// specification gathers the AppArmor snippets of the interfaces and
+// translates their file rules into Landlock rules.
+type Specification struct {
+	appArmor apparmor.Specification
+}
+
+// AddLayout records the accesses needed to use the layouts of the snap.
+func (spec *Specification) AddLayout(si *snap.Info) {
+	spec.appArmor.AddLayout(si)
+}
+
+// Rules returns the Landlock rules granted by the interfaces to the
+// given security tag of the snap.
+func (spec *Specification) Rules(si *snap.Info, tag string) []rule {
+	var rules []rule
+	for _, line := range snippetLines(spec.appArmor.SnippetForTag(tag)) {
+		rules = append(rules, rulesFromAppArmor(si, line)...)
+	}
+	return rules
+}
+
+// Implementation of methods required by interfaces.Specification
+
+// AddConnectedPlug records landlock-specific side-effects of having a connected plug.
+func (spec *Specification) AddConnectedPlug(iface interfaces.Interface, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	return spec.appArmor.AddConnectedPlug(iface, plug, slot)
+}
+
+// AddConnectedSlot records landlock-specific side-effects of having a connected slot.
+func (spec *Specification) AddConnectedSlot(iface interfaces.Interface, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	return spec.appArmor.AddConnectedSlot(iface, plug, slot)
+}
+
+// AddPermanentPlug records landlock-specific side-effects of having a plug.
+func (spec *Specification) AddPermanentPlug(iface interfaces.Interface, plug *snap.PlugInfo) error {
+	return spec.appArmor.AddPermanentPlug(iface, plug)
+}
+
+// AddPermanentSlot records landlock-specific side-effects of having a slot.
+func (spec *Specification) AddPermanentSlot(iface interfaces.Interface, slot *snap.SlotInfo) error {
+	return spec.appArmor.AddPermanentSlot(iface, slot)
+}
@@ -0,0 +1,99 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock
+
+import (
+	"path/filepath"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/sandbox/landlock"
+	"github.com/snapcore/snapd/snap"
+)
+
+var (
+	listOnly     = []landlock.Access{landlock.AccessList}
+	readOnly     = []landlock.Access{landlock.AccessRead}
+	readExecute  = []landlock.Access{landlock.AccessRead, landlock.AccessExecute}
+	readWrite    = []landlock.Access{landlock.AccessRead, landlock.AccessWrite}
+	readWriteExe = []landlock.Access{landlock.AccessRead, landlock.AccessWrite, landlock.AccessExecute}
+)
+
+// snapMountDir returns the directory where snaps are mounted, as seen
+// from the mount namespace of snaps.
+func snapMountDir() string {
+	return dirs.StripRootDir(dirs.SnapMountDir)
+}
+
+// baseRules returns the rules granted to all the applications and
+// hooks of a snap, the equivalent of the AppArmor default template:
+// the base snap and the snap itself can be read and executed, the data
+// directories of the snap can be written and, as in /dev and /sys only
+// some files can be accessed, the entries there can be listed.
+func baseRules(si *snap.Info) []rule {
+	rules := []rule{
+		{"/bin", readExecute},
+		{"/sbin", readExecute},
+		{"/lib", readExecute},
+		{"/lib32", readExecute},
+		{"/lib64", readExecute},
+		{"/libx32", readExecute},
+		{"/usr", readExecute},
+		{"/etc", readOnly},
+		{"/proc", readOnly},
+		{"/run", readOnly},
+		// listing applies to the whole hierarchies, while AppArmor
+		// only allows listing some directories of /sys
+		{"/sys", listOnly},
+		{"/sys/devices/system/cpu", readOnly},
+		{"/sys/devices/system/node", readOnly},
+		{"/sys/devices/virtual/tty/console/active", readOnly},
+		{"/sys/devices/virtual/tty/tty*/active", readOnly},
+		{"/sys/fs/cgroup/memory/memory.limit_in_bytes", readOnly},
+		{"/sys/fs/cgroup/memory/user.slice/memory.limit_in_bytes", readOnly},
+		{"/sys/fs/cgroup/cpu,cpuacct/cpu.cfs_*_us", readOnly},
+		{"/sys/fs/cgroup/cpu,cpuacct/cpu.shares", readOnly},
+		{"/sys/fs/cgroup/cpu,cpuacct/user.slice/cpu.cfs_*_us", readOnly},
+		{"/sys/fs/cgroup/cpu,cpuacct/user.slice/cpu.shares", readOnly},
+		{"/sys/kernel/mm/transparent_hugepage", readOnly},
+		{"/sys/module/apparmor/parameters/enabled", readOnly},
+		{"/dev", listOnly},
+		{"/var/lib/snapd", readOnly},
+		{filepath.Join(snapMountDir(), si.SnapName()), readExecute},
+		{filepath.Join(dirs.StripRootDir(dirs.SnapDataDir), si.InstanceName()), readWriteExe},
+		{filepath.Join("$SNAP_REAL_HOME", dirs.UserHomeSnapDir, si.InstanceName()), readWriteExe},
+		{"$XDG_RUNTIME_DIR", readWrite},
+		// the snap has private /tmp and /dev/shm
+		{"/tmp", readWriteExe},
+		{"/var/tmp", readWrite},
+		{"/dev/shm", readWrite},
+		{"/dev/null", readWrite},
+		{"/dev/zero", readWrite},
+		{"/dev/full", readWrite},
+		{"/dev/random", readWrite},
+		{"/dev/urandom", readWrite},
+		{"/dev/tty", readWrite},
+		{"/dev/ptmx", readWrite},
+		{"/dev/pts", readWrite},
+	}
+	if si.InstanceKey != "" {
+		rules = append(rules, rule{filepath.Join(snapMountDir(), si.InstanceName()), readExecute})
+	}
+	return rules
+}
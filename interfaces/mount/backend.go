@@ -81,6 +81,19 @@ func (b *Backend) Setup(snapInfo *snap.Info, confinement interfaces.ConfinementO
 	return nil
 }
 
+// Profiles returns the mount profiles of the given snap, as Setup would
+// write them, without writing them nor updating the mount namespace.
+func (b *Backend) Profiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string][]byte, error) {
+	snapName := snapInfo.InstanceName()
+	spec, err := repo.SnapSpecification(b.Name(), snapName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain mount security snippets for snap %q: %s", snapName, err)
+	}
+	spec.(*Specification).AddOvername(snapInfo)
+	spec.(*Specification).AddLayout(snapInfo)
+	return interfaces.ProfilesFromContent(dirs.SnapMountPolicyDir, deriveContent(spec.(*Specification), snapInfo))
+}
+
 // Remove removes mount configuration files of a given snap.
 //
 // This method should be called after removing a snap.
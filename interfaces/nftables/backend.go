@@ -0,0 +1,362 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package nftables implements a security backend restricting the
+// network destinations the services of snaps can reach.
+//
+// Interfaces such as network-restricted describe the allowed
+// destinations, see Specification. For each restricted service the
+// backend writes an nftables table to
+// /var/lib/snapd/nftables/<security-tag>.nft which drops the egress
+// traffic of the processes in the cgroup of the service unless it
+// goes to one of the destinations, or to the loopback interface.
+//
+// nft resolves the cgroup of the service when the table is loaded, so
+// the backend also installs a systemd drop-in for the service which
+// loads the table before it is started. Running services get their
+// tables loaded immediately. The tables of removed services or snaps
+// are deleted.
+//
+// Only system services can be restricted and the unified cgroup
+// hierarchy is required. Snaps in devmode or classic snaps are not
+// restricted.
+package nftables
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/sandbox/cgroup"
+	"github.com/snapcore/snapd/snap"
+	sysd "github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/timings"
+)
+
+// nftPath is where nft is expected on the host, used in the service
+// drop-ins.
+const nftPath = "/usr/sbin/nft"
+
+// dropInName is the name of the systemd drop-in loading the table of
+// a service.
+const dropInName = "snapd-nftables.conf"
+
+// Backend is responsible for maintaining the nftables tables restricting
+// the network egress of snap services.
+type Backend struct {
+	preseed bool
+}
+
+// Initialize does nothing.
+func (b *Backend) Initialize(opts *interfaces.SecurityBackendOptions) error {
+	if opts != nil && opts.Preseed {
+		b.preseed = true
+	}
+	return nil
+}
+
+// Name returns the name of the backend.
+func (b *Backend) Name() interfaces.SecuritySystem {
+	return interfaces.SecurityNftables
+}
+
+// Setup writes the nftables tables and systemd drop-ins of the
+// restricted services of the given snap, loads the tables of the
+// running ones and removes stale tables.
+//
+// If the method fails it should be re-tried (with a sensible strategy) by the caller.
+func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository, tm timings.Measurer) error {
+	snapName := snapInfo.InstanceName()
+	tables, dropIns, err := b.deriveContent(snapInfo, opts, repo)
+	if err != nil {
+		return err
+	}
+
+	dir := dirs.SnapNftablesDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("cannot create directory for nftables tables %q: %s", dir, err)
+	}
+	glob := interfaces.SecurityTagGlob(snapName) + ".nft"
+	changed, removed, err := osutil.EnsureDirState(dir, glob, tables)
+	if err != nil {
+		return fmt.Errorf("cannot synchronize nftables tables for snap %q: %s", snapName, err)
+	}
+	if err := b.ensureDropIns(snapName, dropIns); err != nil {
+		return err
+	}
+
+	for _, name := range removed {
+		b.deleteTable(strings.TrimSuffix(name, ".nft"))
+	}
+	for _, name := range changed {
+		tag := strings.TrimSuffix(name, ".nft")
+		if !b.isRunning(tag) {
+			// the drop-in loads the table when the service starts
+			continue
+		}
+		if err := b.loadTable(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Profiles returns the nftables tables and the systemd drop-ins of the
+// given snap, as Setup would write them, without loading them.
+func (b *Backend) Profiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string][]byte, error) {
+	tables, dropIns, err := b.deriveContent(snapInfo, opts, repo)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := interfaces.ProfilesFromContent(dirs.SnapNftablesDir, tables)
+	if err != nil {
+		return nil, err
+	}
+	for dir, content := range dropIns {
+		dropInProfiles, err := interfaces.ProfilesFromContent(dir, map[string]osutil.FileState{dropInName: content})
+		if err != nil {
+			return nil, err
+		}
+		for path, data := range dropInProfiles {
+			profiles[path] = data
+		}
+	}
+	return profiles, nil
+}
+
+// Remove deletes the nftables tables and the systemd drop-ins of the
+// given snap.
+//
+// If the method fails it should be re-tried (with a sensible strategy) by the caller.
+func (b *Backend) Remove(snapName string) error {
+	glob := interfaces.SecurityTagGlob(snapName) + ".nft"
+	_, removed, err := osutil.EnsureDirState(dirs.SnapNftablesDir, glob, nil)
+	if err != nil {
+		return fmt.Errorf("cannot synchronize nftables tables for snap %q: %s", snapName, err)
+	}
+	for _, name := range removed {
+		b.deleteTable(strings.TrimSuffix(name, ".nft"))
+	}
+	return b.ensureDropIns(snapName, nil)
+}
+
+// NewSpecification returns a new nftables specification.
+func (b *Backend) NewSpecification() interfaces.Specification {
+	return &Specification{}
+}
+
+// SandboxFeatures returns the list of network filtering features
+// supported by snapd.
+func (b *Backend) SandboxFeatures() []string {
+	return []string{"egress-destinations"}
+}
+
+// ensureDropIns synchronizes the systemd drop-ins of the services of
+// the given snap with the given ones, indexed by drop-in directory,
+// and reloads systemd if any of them changed.
+func (b *Backend) ensureDropIns(snapName string, dropIns map[string]osutil.FileState) error {
+	glob := interfaces.SecurityTagGlob(snapName) + ".service.d"
+	existing, err := filepath.Glob(filepath.Join(dirs.SnapServicesDir, glob))
+	if err != nil {
+		return err
+	}
+	dirSet := make(map[string]bool, len(existing)+len(dropIns))
+	for _, dir := range existing {
+		dirSet[dir] = true
+	}
+	for dir := range dropIns {
+		dirSet[dir] = true
+	}
+
+	reload := false
+	for dir := range dirSet {
+		var content map[string]osutil.FileState
+		if dropIn := dropIns[dir]; dropIn != nil {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("cannot create directory for systemd drop-ins %q: %s", dir, err)
+			}
+			content = map[string]osutil.FileState{dropInName: dropIn}
+		}
+		changed, removed, err := osutil.EnsureDirState(dir, dropInName, content)
+		if err != nil {
+			return fmt.Errorf("cannot synchronize systemd drop-ins for snap %q: %s", snapName, err)
+		}
+		if len(changed) > 0 || len(removed) > 0 {
+			reload = true
+		}
+	}
+	if reload && !b.preseed {
+		systemd := sysd.New(sysd.SystemMode, &dummyReporter{})
+		if err := systemd.DaemonReload(); err != nil {
+			logger.Noticef("cannot reload systemd state: %s", err)
+		}
+	}
+	return nil
+}
+
+// isRunning returns whether the cgroup of the service with the given
+// security tag exists.
+func (b *Backend) isRunning(tag string) bool {
+	if b.preseed {
+		return false
+	}
+	return osutil.IsDirectory(filepath.Join(dirs.GlobalRootDir, "/sys/fs/cgroup", serviceCgroup(tag)))
+}
+
+func (b *Backend) loadTable(path string) error {
+	if output, err := exec.Command("nft", "-f", path).CombinedOutput(); err != nil {
+		return fmt.Errorf("cannot load nftables table %q: %v", path, osutil.OutputErr(output, err))
+	}
+	return nil
+}
+
+func (b *Backend) deleteTable(tag string) {
+	if b.preseed {
+		return
+	}
+	// the table is not loaded if the service never ran
+	if output, err := exec.Command("nft", "delete", "table", "inet", tag).CombinedOutput(); err != nil {
+		logger.Debugf("cannot delete nftables table %q: %v", tag, osutil.OutputErr(output, err))
+	}
+}
+
+func (b *Backend) deriveContent(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (tables map[string]osutil.FileState, dropIns map[string]osutil.FileState, err error) {
+	if opts.DevMode || (opts.Classic && !opts.JailMode) {
+		// nftables has no complain mode
+		return nil, nil, nil
+	}
+
+	snapName := snapInfo.InstanceName()
+	s, err := repo.SnapSpecification(b.Name(), snapName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot obtain nftables specification for snap %q: %s", snapName, err)
+	}
+	spec := s.(*Specification)
+	tags := spec.SecurityTags()
+	if len(tags) == 0 {
+		return nil, nil, nil
+	}
+	if !cgroup.IsUnified() {
+		return nil, nil, fmt.Errorf("cannot restrict the network egress of snap %q: the unified cgroup hierarchy is not in use", snapName)
+	}
+
+	services := make(map[string]*snap.AppInfo)
+	for _, app := range snapInfo.Apps {
+		if app.IsService() && app.DaemonScope == snap.SystemDaemon {
+			services[app.SecurityTag()] = app
+		}
+	}
+
+	tables = make(map[string]osutil.FileState, len(tags))
+	dropIns = make(map[string]osutil.FileState, len(tags))
+	for _, tag := range tags {
+		app := services[tag]
+		if app == nil {
+			return nil, nil, fmt.Errorf("cannot restrict the network egress of %q: only system services can be restricted", tag)
+		}
+		name := tag + ".nft"
+		tables[name] = &osutil.MemoryFileState{
+			Content: tableContent(tag, spec.Destinations(tag)),
+			Mode:    0644,
+		}
+		dropIns[app.ServiceFile()+".d"] = &osutil.MemoryFileState{
+			Content: []byte(fmt.Sprintf("[Service]\nExecStartPre=+%s -f %s\n", nftPath, filepath.Join(dirs.SnapNftablesDir, name))),
+			Mode:    0644,
+		}
+	}
+	return tables, dropIns, nil
+}
+
+// serviceCgroup returns the path of the cgroup of the system service
+// with the given security tag, relative to the root of the unified
+// hierarchy.
+func serviceCgroup(tag string) string {
+	return "system.slice/" + tag + ".service"
+}
+
+// tableContent returns the nftables table, named after the security
+// tag, dropping the egress traffic of the service not going to the
+// given destinations. The table is replaced atomically when loaded.
+func tableContent(tag string, dests []Destination) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# This file is automatically generated.\n")
+	fmt.Fprintf(&buf, "table inet %s\n", tag)
+	fmt.Fprintf(&buf, "delete table inet %s\n", tag)
+	fmt.Fprintf(&buf, "table inet %s {\n", tag)
+	buf.WriteString("\tchain egress {\n")
+	buf.WriteString("\t\ttype filter hook output priority 0; policy accept;\n")
+	fmt.Fprintf(&buf, "\t\tsocket cgroupv2 level 2 %q jump restrict\n", serviceCgroup(tag))
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tchain restrict {\n")
+	buf.WriteString("\t\toif \"lo\" accept\n")
+	for _, dest := range dests {
+		fmt.Fprintf(&buf, "\t\t%s accept\n", destinationMatch(dest))
+	}
+	buf.WriteString("\t\treject with icmpx type admin-prohibited\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// destinationMatch returns the nftables expression matching the
+// traffic going to the destination.
+func destinationMatch(dest Destination) string {
+	family := "ip"
+	if dest.Network.IP.To4() == nil {
+		family = "ip6"
+	}
+	match := fmt.Sprintf("%s daddr %s", family, dest.Network)
+
+	proto := dest.Protocol
+	if len(dest.Ports) == 0 {
+		if proto != "" {
+			match += " meta l4proto " + proto
+		}
+		return match
+	}
+
+	ports := make([]string, len(dest.Ports))
+	for i, r := range dest.Ports {
+		if r.First == r.Last {
+			ports[i] = fmt.Sprintf("%d", r.First)
+		} else {
+			ports[i] = fmt.Sprintf("%d-%d", r.First, r.Last)
+		}
+	}
+	portSet := ports[0]
+	if len(ports) > 1 {
+		portSet = "{ " + strings.Join(ports, ", ") + " }"
+	}
+	if proto == "" {
+		return match + " meta l4proto { tcp, udp } th dport " + portSet
+	}
+	return match + " " + proto + " dport " + portSet
+}
+
+type dummyReporter struct{}
+
+func (dr *dummyReporter) Notify(msg string) {
+}
@@ -0,0 +1,254 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package nftables_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/nftables"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/sandbox/cgroup"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type backendSuite struct {
+	ifacetest.BackendSuite
+
+	nftCmd         *testutil.MockCmd
+	systemctlCalls [][]string
+}
+
+var _ = Suite(&backendSuite{})
+
+const sambaYaml = `
+name: samba
+version: 1
+developer: acme
+apps:
+    smbd:
+        daemon: simple
+        slots: [slot]
+    cli:
+slots:
+    slot:
+        interface: iface
+`
+
+const sambaTable = `# This file is automatically generated.
+table inet snap.samba.smbd
+delete table inet snap.samba.smbd
+table inet snap.samba.smbd {
+	chain egress {
+		type filter hook output priority 0; policy accept;
+		socket cgroupv2 level 2 "system.slice/snap.samba.smbd.service" jump restrict
+	}
+	chain restrict {
+		oif "lo" accept
+		ip daddr 192.0.2.0/24 tcp dport 443 accept
+		reject with icmpx type admin-prohibited
+	}
+}
+`
+
+func (s *backendSuite) SetUpTest(c *C) {
+	s.Backend = &nftables.Backend{}
+	s.BackendSuite.SetUpTest(c)
+	c.Assert(s.Repo.AddBackend(s.Backend), IsNil)
+
+	s.AddCleanup(cgroup.MockVersion(cgroup.V2, nil))
+	s.nftCmd = testutil.MockCommand(c, "nft", "")
+	s.AddCleanup(s.nftCmd.Restore)
+	s.systemctlCalls = nil
+	s.AddCleanup(systemd.MockSystemctl(func(args ...string) ([]byte, error) {
+		s.systemctlCalls = append(s.systemctlCalls, args)
+		return nil, nil
+	}))
+
+	_, network, err := net.ParseCIDR("192.0.2.0/24")
+	c.Assert(err, IsNil)
+	s.Iface.NftablesPermanentSlotCallback = func(spec *nftables.Specification, slot *snap.SlotInfo) error {
+		spec.AddDestination(nftables.Destination{
+			Network:  network,
+			Protocol: "tcp",
+			Ports:    []nftables.PortRange{{First: 443, Last: 443}},
+		})
+		return nil
+	}
+}
+
+func (s *backendSuite) TearDownTest(c *C) {
+	s.BackendSuite.TearDownTest(c)
+}
+
+// mockSnap adds the slots of the snap to the repository without
+// setting up the snap.
+func (s *backendSuite) mockSnap(c *C, snapYaml string) *snap.Info {
+	snapInfo := snaptest.MockInfo(c, snapYaml, &snap.SideInfo{Revision: snap.R(1)})
+	for _, slotInfo := range snapInfo.Slots {
+		c.Assert(s.Repo.AddSlot(slotInfo), IsNil)
+	}
+	return snapInfo
+}
+
+func (s *backendSuite) TestName(c *C) {
+	c.Check(s.Backend.Name(), Equals, interfaces.SecurityNftables)
+}
+
+func (s *backendSuite) TestInstallingSnapWritesTablesAndDropIns(c *C) {
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", sambaYaml, 1)
+
+	c.Check(filepath.Join(dirs.SnapNftablesDir, "snap.samba.smbd.nft"), testutil.FileEquals, sambaTable)
+	c.Check(filepath.Join(dirs.SnapNftablesDir, "snap.samba.cli.nft"), testutil.FileAbsent)
+	dropIn := filepath.Join(dirs.SnapServicesDir, "snap.samba.smbd.service.d", "snapd-nftables.conf")
+	c.Check(dropIn, testutil.FileEquals, "[Service]\nExecStartPre=+/usr/sbin/nft -f "+
+		filepath.Join(dirs.SnapNftablesDir, "snap.samba.smbd.nft")+"\n")
+	c.Check(s.systemctlCalls, DeepEquals, [][]string{{"daemon-reload"}})
+	// the service is not running, the table is loaded when it starts
+	c.Check(s.nftCmd.Calls(), HasLen, 0)
+}
+
+func (s *backendSuite) TestInstallingSnapLoadsTableOfRunningService(c *C) {
+	c.Assert(os.MkdirAll(filepath.Join(dirs.GlobalRootDir, "/sys/fs/cgroup/system.slice/snap.samba.smbd.service"), 0755), IsNil)
+
+	snapInfo := s.InstallSnap(c, interfaces.ConfinementOptions{}, "", sambaYaml, 1)
+	c.Check(s.nftCmd.Calls(), DeepEquals, [][]string{
+		{"nft", "-f", filepath.Join(dirs.SnapNftablesDir, "snap.samba.smbd.nft")},
+	})
+
+	// nothing is loaded again when nothing changed
+	s.nftCmd.ForgetCalls()
+	s.systemctlCalls = nil
+	c.Assert(s.Backend.Setup(snapInfo, interfaces.ConfinementOptions{}, s.Repo, nil), IsNil)
+	c.Check(s.nftCmd.Calls(), HasLen, 0)
+	c.Check(s.systemctlCalls, HasLen, 0)
+}
+
+func (s *backendSuite) TestLoadingTableFails(c *C) {
+	c.Assert(os.MkdirAll(filepath.Join(dirs.GlobalRootDir, "/sys/fs/cgroup/system.slice/snap.samba.smbd.service"), 0755), IsNil)
+	cmd := testutil.MockCommand(c, "nft", "echo 'Error: syntax error'; exit 1")
+	defer cmd.Restore()
+
+	snapInfo := s.mockSnap(c, sambaYaml)
+	err := s.Backend.Setup(snapInfo, interfaces.ConfinementOptions{}, s.Repo, nil)
+	c.Check(err, ErrorMatches, `cannot load nftables table ".*/snap.samba.smbd.nft": Error: syntax error`)
+}
+
+func (s *backendSuite) TestRemovingSnapDeletesTablesAndDropIns(c *C) {
+	snapInfo := s.InstallSnap(c, interfaces.ConfinementOptions{}, "", sambaYaml, 1)
+	s.systemctlCalls = nil
+
+	s.RemoveSnap(c, snapInfo)
+	c.Check(filepath.Join(dirs.SnapNftablesDir, "snap.samba.smbd.nft"), testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapServicesDir, "snap.samba.smbd.service.d", "snapd-nftables.conf"), testutil.FileAbsent)
+	c.Check(s.nftCmd.Calls(), DeepEquals, [][]string{
+		{"nft", "delete", "table", "inet", "snap.samba.smbd"},
+	})
+	c.Check(s.systemctlCalls, DeepEquals, [][]string{{"daemon-reload"}})
+}
+
+func (s *backendSuite) TestUnrestrictedSnapHasNoTables(c *C) {
+	s.Iface.NftablesPermanentSlotCallback = nil
+	s.InstallSnap(c, interfaces.ConfinementOptions{}, "", sambaYaml, 1)
+	c.Check(filepath.Join(dirs.SnapNftablesDir, "snap.samba.smbd.nft"), testutil.FileAbsent)
+	c.Check(s.systemctlCalls, HasLen, 0)
+}
+
+func (s *backendSuite) TestDevModeSnapsAreNotRestricted(c *C) {
+	for _, opts := range []interfaces.ConfinementOptions{{DevMode: true}, {Classic: true}} {
+		snapInfo := s.InstallSnap(c, opts, "", sambaYaml, 1)
+		c.Check(filepath.Join(dirs.SnapNftablesDir, "snap.samba.smbd.nft"), testutil.FileAbsent)
+		s.RemoveSnap(c, snapInfo)
+	}
+}
+
+func (s *backendSuite) TestOnlySystemServicesCanBeRestricted(c *C) {
+	snapInfo := s.mockSnap(c, ifacetest.SambaYamlV1)
+	err := s.Backend.Setup(snapInfo, interfaces.ConfinementOptions{}, s.Repo, nil)
+	c.Check(err, ErrorMatches, `cannot restrict the network egress of "snap.samba.smbd": only system services can be restricted`)
+}
+
+func (s *backendSuite) TestRequiresUnifiedCgroup(c *C) {
+	restore := cgroup.MockVersion(cgroup.V1, nil)
+	defer restore()
+
+	snapInfo := s.mockSnap(c, sambaYaml)
+	err := s.Backend.Setup(snapInfo, interfaces.ConfinementOptions{}, s.Repo, nil)
+	c.Check(err, ErrorMatches, `cannot restrict the network egress of snap "samba": the unified cgroup hierarchy is not in use`)
+}
+
+func (s *backendSuite) TestProfiles(c *C) {
+	snapInfo := s.mockSnap(c, sambaYaml)
+	backend := s.Backend.(interfaces.SecurityBackendProfiles)
+	profiles, err := backend.Profiles(snapInfo, interfaces.ConfinementOptions{}, s.Repo)
+	c.Assert(err, IsNil)
+	tablePath := filepath.Join(dirs.SnapNftablesDir, "snap.samba.smbd.nft")
+	c.Check(profiles, DeepEquals, map[string][]byte{
+		tablePath: []byte(sambaTable),
+		filepath.Join(dirs.SnapServicesDir, "snap.samba.smbd.service.d", "snapd-nftables.conf"): []byte("[Service]\nExecStartPre=+/usr/sbin/nft -f " + tablePath + "\n"),
+	})
+	// nothing was written nor loaded
+	c.Check(osutil.FileExists(tablePath), Equals, false)
+	c.Check(s.nftCmd.Calls(), HasLen, 0)
+}
+
+func (s *backendSuite) TestSandboxFeatures(c *C) {
+	c.Check(s.Backend.SandboxFeatures(), DeepEquals, []string{"egress-destinations"})
+}
+
+func (s *backendSuite) TestDestinationMatch(c *C) {
+	mustNet := func(cidr string) *net.IPNet {
+		_, network, err := net.ParseCIDR(cidr)
+		c.Assert(err, IsNil)
+		return network
+	}
+	for _, t := range []struct {
+		dest  nftables.Destination
+		match string
+	}{
+		{nftables.Destination{Network: mustNet("10.0.0.0/8")}, "ip daddr 10.0.0.0/8"},
+		{nftables.Destination{Network: mustNet("2001:db8::/32"), Protocol: "udp"}, "ip6 daddr 2001:db8::/32 meta l4proto udp"},
+		{nftables.Destination{
+			Network: mustNet("192.0.2.1/32"),
+			Ports:   []nftables.PortRange{{First: 53, Last: 53}},
+		}, "ip daddr 192.0.2.1/32 meta l4proto { tcp, udp } th dport 53"},
+		{nftables.Destination{
+			Network:  mustNet("192.0.2.1/32"),
+			Protocol: "tcp",
+			Ports:    []nftables.PortRange{{First: 443, Last: 443}, {First: 8000, Last: 8080}},
+		}, "ip daddr 192.0.2.1/32 tcp dport { 443, 8000-8080 }"},
+	} {
+		c.Check(nftables.DestinationMatch(t.dest), Equals, t.match)
+	}
+}
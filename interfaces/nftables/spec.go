@@ -0,0 +1,150 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package nftables
+
+import (
+	"net"
+	"sort"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/snap"
+)
+
+// PortRange is an inclusive range of ports.
+type PortRange struct {
+	First int
+	Last  int
+}
+
+// Destination is a network destination that the processes of a
+// security tag are allowed to reach.
+type Destination struct {
+	// Network is the IPv4 or IPv6 network of the destination.
+	Network *net.IPNet
+	// Protocol is either "tcp" or "udp", both are allowed when it is
+	// empty.
+	Protocol string
+	// Ports are the allowed destination ports, any port is allowed
+	// when there are none.
+	Ports []PortRange
+}
+
+// Specification assists in collecting the network destinations the
+// applications of a snap are restricted to.
+//
+// Only the security tags with destinations are restricted, the egress
+// traffic of the others is left alone.
+type Specification struct {
+	// scope for various Add{...}Destination functions
+	securityTags []string
+
+	destinations map[string][]Destination
+}
+
+func (spec *Specification) setScope(securityTags []string) (restore func()) {
+	spec.securityTags = securityTags
+	return func() {
+		spec.securityTags = nil
+	}
+}
+
+// AddDestination allows the security tags in scope to reach the given
+// destination, and restricts them to the destinations added this way.
+func (spec *Specification) AddDestination(dest Destination) {
+	if len(spec.securityTags) == 0 {
+		return
+	}
+	if spec.destinations == nil {
+		spec.destinations = make(map[string][]Destination)
+	}
+	for _, tag := range spec.securityTags {
+		spec.destinations[tag] = append(spec.destinations[tag], dest)
+	}
+}
+
+// SecurityTags returns the sorted security tags that are restricted.
+func (spec *Specification) SecurityTags() []string {
+	tags := make([]string, 0, len(spec.destinations))
+	for tag := range spec.destinations {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Destinations returns the destinations allowed for the given security
+// tag.
+func (spec *Specification) Destinations(tag string) []Destination {
+	return spec.destinations[tag]
+}
+
+// Implementation of methods required by interfaces.Specification
+
+// AddConnectedPlug records nftables-specific side-effects of having a connected plug.
+func (spec *Specification) AddConnectedPlug(iface interfaces.Interface, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	type definer interface {
+		NftablesConnectedPlug(spec *Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error
+	}
+	if iface, ok := iface.(definer); ok {
+		restore := spec.setScope(plug.SecurityTags())
+		defer restore()
+		return iface.NftablesConnectedPlug(spec, plug, slot)
+	}
+	return nil
+}
+
+// AddConnectedSlot records nftables-specific side-effects of having a connected slot.
+func (spec *Specification) AddConnectedSlot(iface interfaces.Interface, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	type definer interface {
+		NftablesConnectedSlot(spec *Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error
+	}
+	if iface, ok := iface.(definer); ok {
+		restore := spec.setScope(slot.SecurityTags())
+		defer restore()
+		return iface.NftablesConnectedSlot(spec, plug, slot)
+	}
+	return nil
+}
+
+// AddPermanentPlug records nftables-specific side-effects of having a plug.
+func (spec *Specification) AddPermanentPlug(iface interfaces.Interface, plug *snap.PlugInfo) error {
+	type definer interface {
+		NftablesPermanentPlug(spec *Specification, plug *snap.PlugInfo) error
+	}
+	if iface, ok := iface.(definer); ok {
+		restore := spec.setScope(plug.SecurityTags())
+		defer restore()
+		return iface.NftablesPermanentPlug(spec, plug)
+	}
+	return nil
+}
+
+// AddPermanentSlot records nftables-specific side-effects of having a slot.
+func (spec *Specification) AddPermanentSlot(iface interfaces.Interface, slot *snap.SlotInfo) error {
+	type definer interface {
+		NftablesPermanentSlot(spec *Specification, slot *snap.SlotInfo) error
+	}
+	if iface, ok := iface.(definer); ok {
+		restore := spec.setScope(slot.SecurityTags())
+		defer restore()
+		return iface.NftablesPermanentSlot(spec, slot)
+	}
+	return nil
+}
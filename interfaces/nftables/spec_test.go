@@ -0,0 +1,103 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package nftables_test
+
+import (
+	"net"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/nftables"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+type specSuite struct {
+	iface *ifacetest.TestInterface
+	spec  *nftables.Specification
+	plug  *interfaces.ConnectedPlug
+	slot  *interfaces.ConnectedSlot
+}
+
+var _ = Suite(&specSuite{})
+
+var specDest = nftables.Destination{
+	Network: &net.IPNet{IP: net.IPv4(192, 0, 2, 1).To4(), Mask: net.CIDRMask(32, 32)},
+}
+
+func (s *specSuite) SetUpTest(c *C) {
+	s.iface = &ifacetest.TestInterface{
+		InterfaceName: "test",
+		NftablesConnectedPlugCallback: func(spec *nftables.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AddDestination(specDest)
+			return nil
+		},
+	}
+	s.spec = &nftables.Specification{}
+	plugSnap := snaptest.MockInfo(c, `name: consumer
+version: 0
+apps:
+  svc:
+    daemon: simple
+    plugs: [plug]
+  other:
+plugs:
+  plug:
+    interface: test
+`, nil)
+	slotSnap := snaptest.MockInfo(c, `name: producer
+version: 0
+apps:
+  app:
+slots:
+  slot:
+    interface: test
+`, nil)
+	s.plug = interfaces.NewConnectedPlug(plugSnap.Plugs["plug"], nil, nil)
+	s.slot = interfaces.NewConnectedSlot(slotSnap.Slots["slot"], nil, nil)
+}
+
+func (s *specSuite) TestAddDestinationScope(c *C) {
+	var r interfaces.Specification = s.spec
+	c.Assert(r.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(r.AddConnectedSlot(s.iface, s.plug, s.slot), IsNil)
+
+	c.Check(s.spec.SecurityTags(), DeepEquals, []string{"snap.consumer.svc"})
+	c.Check(s.spec.Destinations("snap.consumer.svc"), DeepEquals, []nftables.Destination{specDest})
+	c.Check(s.spec.Destinations("snap.consumer.other"), HasLen, 0)
+}
+
+func (s *specSuite) TestAddDestinationWithoutScope(c *C) {
+	s.spec.AddDestination(specDest)
+	c.Check(s.spec.SecurityTags(), HasLen, 0)
+}
+
+func (s *specSuite) TestPermanentSnippets(c *C) {
+	s.iface.NftablesPermanentSlotCallback = func(spec *nftables.Specification, slot *snap.SlotInfo) error {
+		spec.AddDestination(specDest)
+		return nil
+	}
+	var r interfaces.Specification = s.spec
+	c.Assert(r.AddPermanentPlug(s.iface, s.plug.Snap().Plugs["plug"]), IsNil)
+	c.Assert(r.AddPermanentSlot(s.iface, s.slot.Snap().Slots["slot"]), IsNil)
+	c.Check(s.spec.SecurityTags(), DeepEquals, []string{"snap.producer.app"})
+}
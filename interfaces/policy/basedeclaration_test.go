@@ -597,6 +597,7 @@ var (
 		"core-support":            {"core"},
 		"cups":                    {"app"},
 		"cups-control":            {"app", "core"},
+		"custom-device":           {"gadget"},
 		"dbus":                    {"app"},
 		"docker-support":          {"core"},
 		"dummy":                   {"app"},
@@ -709,6 +710,7 @@ func (s *baseDeclSuite) TestPlugInstallation(c *C) {
 		"kubernetes-support":    true,
 		"lxd-support":           true,
 		"multipass-support":     true,
+		"network-restricted":    true,
 		"packagekit-control":    true,
 		"personal-files":        true,
 		"snapd-control":         true,
@@ -755,6 +757,7 @@ func (s *baseDeclSuite) TestConnection(c *C) {
 	noconnect := map[string]bool{
 		"content":          true,
 		"cups":             true,
+		"custom-device":    true,
 		"docker":           true,
 		"fwupd":            true,
 		"location-control": true,
@@ -934,6 +937,7 @@ func (s *baseDeclSuite) TestSanity(c *C) {
 		"audio-playback":        true,
 		"classic-support":       true,
 		"core-support":          true,
+		"custom-device":         true,
 		"docker-support":        true,
 		"greengrass-support":    true,
 		"gpio-control":          true,
@@ -941,6 +945,7 @@ func (s *baseDeclSuite) TestSanity(c *C) {
 		"kubernetes-support":    true,
 		"lxd-support":           true,
 		"multipass-support":     true,
+		"network-restricted":    true,
 		"packagekit-control":    true,
 		"personal-files":        true,
 		"snapd-control":         true,
@@ -967,6 +972,39 @@ func (s *baseDeclSuite) TestSanity(c *C) {
 	}
 }
 
+func (s *baseDeclSuite) TestConnectionCustomDevice(c *C) {
+	// the plug and slot can be connected only if their custom-device
+	// attributes match
+	slotYaml := `name: gadget-snap
+version: 0
+type: gadget
+slots:
+  serial:
+    interface: custom-device
+    custom-device: imx-serial
+    devices: [/dev/ttymxc*]
+`
+	cand := s.connectCand(c, "serial", slotYaml, `name: plug-snap
+version: 0
+plugs:
+  serial:
+    interface: custom-device
+    custom-device: imx-serial
+`)
+	c.Check(cand.Check(), IsNil)
+	_, err := cand.CheckAutoConnect()
+	c.Check(err, NotNil)
+
+	cand = s.connectCand(c, "serial", slotYaml, `name: plug-snap
+version: 0
+plugs:
+  serial:
+    interface: custom-device
+    custom-device: other
+`)
+	c.Check(cand.Check(), ErrorMatches, `connection not allowed by plug rule of interface "custom-device"`)
+}
+
 func (s *baseDeclSuite) TestConnectionContent(c *C) {
 	// we let connect explicitly as long as content matches (or is absent on both sides)
 
@@ -0,0 +1,128 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package policy
+
+import (
+	"github.com/snapcore/snapd/asserts"
+)
+
+// RuleSource identifies the declaration rule that decided about a
+// connection.
+type RuleSource struct {
+	// Assertion is either "snap-declaration" or "base-declaration".
+	Assertion string `json:"assertion"`
+	// SnapID, SnapName and Revision are set for snap-declaration rules.
+	SnapID   string `json:"snap-id,omitempty"`
+	SnapName string `json:"snap-name,omitempty"`
+	Revision int    `json:"revision,omitempty"`
+	// Side is either "plug" or "slot".
+	Side      string `json:"side"`
+	Interface string `json:"interface"`
+}
+
+// ConstraintCheck is the outcome of checking one alternative of the
+// deny or allow constraints of a rule.
+type ConstraintCheck struct {
+	// Constraint is the name of the constraints in the rule, e.g.
+	// deny-auto-connection or allow-connection.
+	Constraint string `json:"constraint"`
+	// Alternative is the index of the alternative in the constraints.
+	Alternative int  `json:"alternative"`
+	Matched     bool `json:"matched"`
+	// Error is what did not match in the alternative.
+	Error string `json:"error,omitempty"`
+}
+
+// Explanation describes how the declaration rules were evaluated to
+// allow or not a connection or an auto-connection.
+type Explanation struct {
+	// Kind is either "connection" or "auto-connection".
+	Kind    string `json:"kind"`
+	Allowed bool   `json:"allowed"`
+	// Rule is the rule that was evaluated, it is nil if no declaration
+	// has a rule for the interface.
+	Rule   *RuleSource        `json:"rule,omitempty"`
+	Checks []*ConstraintCheck `json:"checks,omitempty"`
+	// Error is why the connection is not allowed.
+	Error string `json:"error,omitempty"`
+}
+
+// Explain evaluates the connection rules, or the auto-connection
+// rules if autoConnect is set, the same way Check and CheckAutoConnect
+// do, and returns how they were evaluated.
+func (connc *ConnectCandidate) Explain(autoConnect bool) *Explanation {
+	kind := "connection"
+	if autoConnect {
+		kind = "auto-connection"
+	}
+	ex := &Explanation{Kind: kind}
+	connc.trace = ex
+	defer func() {
+		connc.trace = nil
+		connc.traceConstraint = ""
+	}()
+
+	if _, err := connc.check(kind); err != nil {
+		ex.Error = err.Error()
+	} else {
+		ex.Allowed = true
+	}
+	return ex
+}
+
+func (connc *ConnectCandidate) traceSnapRule(decl *asserts.SnapDeclaration, side string) {
+	if connc.trace == nil {
+		return
+	}
+	connc.trace.Rule = &RuleSource{
+		Assertion: "snap-declaration",
+		SnapID:    decl.SnapID(),
+		SnapName:  decl.SnapName(),
+		Revision:  decl.Revision(),
+		Side:      side,
+		Interface: connc.Plug.Interface(),
+	}
+}
+
+func (connc *ConnectCandidate) traceBaseRule(side string) {
+	if connc.trace == nil {
+		return
+	}
+	connc.trace.Rule = &RuleSource{
+		Assertion: "base-declaration",
+		Side:      side,
+		Interface: connc.Plug.Interface(),
+	}
+}
+
+func (connc *ConnectCandidate) traceCheck(alternative int, err error) {
+	if connc.trace == nil {
+		return
+	}
+	check := &ConstraintCheck{
+		Constraint:  connc.traceConstraint,
+		Alternative: alternative,
+		Matched:     err == nil,
+	}
+	if err != nil {
+		check.Error = err.Error()
+	}
+	connc.trace.Checks = append(connc.trace.Checks, check)
+}
@@ -0,0 +1,103 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package policy_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/policy"
+)
+
+func (s *policySuite) TestExplainAutoConnectAlternatives(c *C) {
+	cand := policy.ConnectCandidate{
+		Plug:            interfaces.NewConnectedPlug(s.plugSnap.Plugs["auto-plug-or-p2-s1"], nil, nil),
+		Slot:            interfaces.NewConnectedSlot(s.slotSnap.Slots["auto-plug-or-p2-s1"], nil, nil),
+		BaseDeclaration: s.baseDecl,
+	}
+
+	baseRule := &policy.RuleSource{
+		Assertion: "base-declaration",
+		Side:      "plug",
+		Interface: "auto-plug-or",
+	}
+	ex := cand.Explain(true)
+	c.Check(ex, DeepEquals, &policy.Explanation{
+		Kind: "auto-connection",
+		Rule: baseRule,
+		Checks: []*policy.ConstraintCheck{
+			{Constraint: "deny-auto-connection", Alternative: 0, Error: "not allowed"},
+			{Constraint: "allow-auto-connection", Alternative: 0, Error: `attribute "p" value "P2" does not match ^(P1)$`},
+			{Constraint: "allow-auto-connection", Alternative: 1, Error: `attribute "s" value "S1" does not match ^(S2)$`},
+		},
+		Error: `auto-connection not allowed by plug rule of interface "auto-plug-or"`,
+	})
+
+	// the connection itself is allowed
+	ex = cand.Explain(false)
+	c.Check(ex, DeepEquals, &policy.Explanation{
+		Kind:    "connection",
+		Allowed: true,
+		Rule:    baseRule,
+		Checks: []*policy.ConstraintCheck{
+			{Constraint: "deny-connection", Alternative: 0, Error: "not allowed"},
+			{Constraint: "allow-connection", Alternative: 0, Matched: true},
+		},
+	})
+
+	// explaining does not affect checking
+	c.Check(cand.Check(), IsNil)
+}
+
+func (s *policySuite) TestExplainSnapDeclaration(c *C) {
+	cand := policy.ConnectCandidate{
+		Plug:                interfaces.NewConnectedPlug(s.plugSnap.Plugs["auto-snap-plug-deny"], nil, nil),
+		Slot:                interfaces.NewConnectedSlot(s.slotSnap.Slots["auto-snap-plug-deny"], nil, nil),
+		PlugSnapDeclaration: s.plugDecl,
+		SlotSnapDeclaration: s.slotDecl,
+		BaseDeclaration:     s.baseDecl,
+	}
+
+	ex := cand.Explain(true)
+	c.Check(ex, DeepEquals, &policy.Explanation{
+		Kind: "auto-connection",
+		Rule: &policy.RuleSource{
+			Assertion: "snap-declaration",
+			SnapID:    "plugsnapidididididididididididid",
+			SnapName:  "plug-snap",
+			Side:      "plug",
+			Interface: "auto-snap-plug-deny",
+		},
+		Checks: []*policy.ConstraintCheck{
+			{Constraint: "deny-auto-connection", Alternative: 0, Matched: true},
+		},
+		Error: `auto-connection denied by plug rule of interface "auto-snap-plug-deny" for "plug-snap" snap`,
+	})
+}
+
+func (s *policySuite) TestExplainNoRule(c *C) {
+	cand := policy.ConnectCandidate{
+		Plug:            interfaces.NewConnectedPlug(s.plugSnap.Plugs["random"], nil, nil),
+		Slot:            interfaces.NewConnectedSlot(s.slotSnap.Slots["random"], nil, nil),
+		BaseDeclaration: s.baseDecl,
+	}
+
+	c.Check(cand.Explain(true), DeepEquals, &policy.Explanation{Kind: "auto-connection", Allowed: true})
+}
@@ -172,8 +172,9 @@ func checkPlugConnectionConstraints1(connc *ConnectCandidate, constraints *asser
 func checkPlugConnectionAltConstraints(connc *ConnectCandidate, altConstraints []*asserts.PlugConnectionConstraints) (*asserts.PlugConnectionConstraints, error) {
 	var firstErr error
 	// OR of constraints
-	for _, constraints := range altConstraints {
+	for i, constraints := range altConstraints {
 		err := checkPlugConnectionConstraints1(connc, constraints)
+		connc.traceCheck(i, err)
 		if err == nil {
 			return constraints, nil
 		}
@@ -222,8 +223,9 @@ func checkSlotConnectionConstraints1(connc *ConnectCandidate, constraints *asser
 func checkSlotConnectionAltConstraints(connc *ConnectCandidate, altConstraints []*asserts.SlotConnectionConstraints) (*asserts.SlotConnectionConstraints, error) {
 	var firstErr error
 	// OR of constraints
-	for _, constraints := range altConstraints {
+	for i, constraints := range altConstraints {
 		err := checkSlotConnectionConstraints1(connc, constraints)
+		connc.traceCheck(i, err)
 		if err == nil {
 			return constraints, nil
 		}
@@ -130,6 +130,10 @@ type ConnectCandidate struct {
 
 	Model *asserts.Model
 	Store *asserts.Store
+
+	// trace and traceConstraint are used by Explain
+	trace           *Explanation
+	traceConstraint string
 }
 
 func nestedGet(which string, attrs interfaces.Attrer, path string) (interface{}, error) {
@@ -187,10 +191,12 @@ func (connc *ConnectCandidate) checkPlugRule(kind string, rule *asserts.PlugRule
 		denyConst = rule.DenyAutoConnection
 		allowConst = rule.AllowAutoConnection
 	}
+	connc.traceConstraint = "deny-" + kind
 	if _, err := checkPlugConnectionAltConstraints(connc, denyConst); err == nil {
 		return nil, fmt.Errorf("%s denied by plug rule of interface %q%s", kind, connc.Plug.Interface(), context)
 	}
 
+	connc.traceConstraint = "allow-" + kind
 	allowedConstraints, err := checkPlugConnectionAltConstraints(connc, allowConst)
 	if err != nil {
 		return nil, fmt.Errorf("%s not allowed by plug rule of interface %q%s", kind, connc.Plug.Interface(), context)
@@ -209,10 +215,12 @@ func (connc *ConnectCandidate) checkSlotRule(kind string, rule *asserts.SlotRule
 		denyConst = rule.DenyAutoConnection
 		allowConst = rule.AllowAutoConnection
 	}
+	connc.traceConstraint = "deny-" + kind
 	if _, err := checkSlotConnectionAltConstraints(connc, denyConst); err == nil {
 		return nil, fmt.Errorf("%s denied by slot rule of interface %q%s", kind, connc.Plug.Interface(), context)
 	}
 
+	connc.traceConstraint = "allow-" + kind
 	allowedConstraints, err := checkSlotConnectionAltConstraints(connc, allowConst)
 	if err != nil {
 		return nil, fmt.Errorf("%s not allowed by slot rule of interface %q%s", kind, connc.Plug.Interface(), context)
@@ -234,18 +242,22 @@ func (connc *ConnectCandidate) check(kind string) (interfaces.SideArity, error)
 
 	if plugDecl := connc.PlugSnapDeclaration; plugDecl != nil {
 		if rule := plugDecl.PlugRule(iface); rule != nil {
+			connc.traceSnapRule(plugDecl, "plug")
 			return connc.checkPlugRule(kind, rule, true)
 		}
 	}
 	if slotDecl := connc.SlotSnapDeclaration; slotDecl != nil {
 		if rule := slotDecl.SlotRule(iface); rule != nil {
+			connc.traceSnapRule(slotDecl, "slot")
 			return connc.checkSlotRule(kind, rule, true)
 		}
 	}
 	if rule := baseDecl.PlugRule(iface); rule != nil {
+		connc.traceBaseRule("plug")
 		return connc.checkPlugRule(kind, rule, false)
 	}
 	if rule := baseDecl.SlotRule(iface); rule != nil {
+		connc.traceBaseRule("slot")
 		return connc.checkSlotRule(kind, rule, false)
 	}
 	return nil, nil
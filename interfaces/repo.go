@@ -61,6 +61,47 @@ func NewRepository() *Repository {
 	return repo
 }
 
+// Copy returns a copy of the repository, that can be modified without
+// affecting the original, e.g. to compute the effects of a connection.
+func (r *Repository) Copy() *Repository {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	repo := NewRepository()
+	for name, iface := range r.ifaces {
+		repo.ifaces[name] = iface
+	}
+	for name, iface := range r.hotplugIfaces {
+		repo.hotplugIfaces[name] = iface
+	}
+	for snapName, plugs := range r.plugs {
+		repo.plugs[snapName] = make(map[string]*snap.PlugInfo, len(plugs))
+		for name, plug := range plugs {
+			repo.plugs[snapName][name] = plug
+		}
+	}
+	for snapName, slots := range r.slots {
+		repo.slots[snapName] = make(map[string]*snap.SlotInfo, len(slots))
+		for name, slot := range slots {
+			repo.slots[snapName][name] = slot
+		}
+	}
+	for slot, plugs := range r.slotPlugs {
+		repo.slotPlugs[slot] = make(map[*snap.PlugInfo]*Connection, len(plugs))
+		for plug, conn := range plugs {
+			repo.slotPlugs[slot][plug] = conn
+		}
+	}
+	for plug, slots := range r.plugSlots {
+		repo.plugSlots[plug] = make(map[*snap.SlotInfo]*Connection, len(slots))
+		for slot, conn := range slots {
+			repo.plugSlots[plug][slot] = conn
+		}
+	}
+	repo.backends = append(repo.backends, r.backends...)
+	return repo
+}
+
 // Interface returns an interface with a given name.
 func (r *Repository) Interface(interfaceName string) Interface {
 	r.m.Lock()
@@ -119,12 +160,14 @@ func (r *Repository) AllHotplugInterfaces() map[string]Interface {
 //
 // Names: return just this subset if non-empty.
 // Doc: return documentation.
+// Attrs: return the schema of plug and slot attributes.
 // Plugs: return information about plugs.
 // Slots: return information about slots.
 // Connected: only consider interfaces with at least one connection.
 type InfoOptions struct {
 	Names     []string
 	Doc       bool
+	Attrs     bool
 	Plugs     bool
 	Slots     bool
 	Connected bool
@@ -142,6 +185,10 @@ func (r *Repository) interfaceInfo(iface Interface, opts *InfoOptions) *Info {
 		// Collect documentation URL
 		ii.DocURL = si.DocURL
 	}
+	if opts != nil && opts.Attrs {
+		ii.PlugAttrs = si.PlugAttrs
+		ii.SlotAttrs = si.SlotAttrs
+	}
 	if opts != nil && opts.Plugs {
 		// Collect all plugs of this interface type.
 		for _, snapName := range sortedSnapNamesWithPlugs(r.plugs) {
@@ -1003,6 +1003,24 @@ func (s *RepositorySuite) TestConnectSucceeds(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *RepositorySuite) TestCopyIsIndependent(c *C) {
+	c.Assert(s.testRepo.AddPlug(s.plug), IsNil)
+	c.Assert(s.testRepo.AddSlot(s.slot), IsNil)
+
+	repo := s.testRepo.Copy()
+	connRef := NewConnRef(s.plug, s.slot)
+	_, err := repo.Connect(connRef, nil, nil, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(repo.Interfaces().Connections, DeepEquals, []*ConnRef{connRef})
+	// the original repository is not affected
+	c.Check(s.testRepo.Interfaces().Connections, HasLen, 0)
+
+	// and vice versa
+	c.Assert(s.testRepo.RemovePlug(s.plug.Snap.InstanceName(), s.plug.Name), IsNil)
+	c.Check(repo.Plug(s.plug.Snap.InstanceName(), s.plug.Name), Equals, s.plug)
+	c.Check(repo.Interface(s.iface.Name()), Equals, s.iface)
+}
+
 // Tests for Repository.Disconnect() and DisconnectAll()
 
 // Disconnect fails if any argument is empty
@@ -1864,7 +1882,12 @@ func (s *RepositorySuite) TestInfo(c *C) {
 
 	// Add some test interfaces.
 	i1 := &ifacetest.TestInterface{InterfaceName: "i1", InterfaceStaticInfo: StaticInfo{Summary: "i1 summary", DocURL: "http://example.com/i1"}}
-	i2 := &ifacetest.TestInterface{InterfaceName: "i2", InterfaceStaticInfo: StaticInfo{Summary: "i2 summary", DocURL: "http://example.com/i2"}}
+	i2 := &ifacetest.TestInterface{InterfaceName: "i2", InterfaceStaticInfo: StaticInfo{
+		Summary:   "i2 summary",
+		DocURL:    "http://example.com/i2",
+		PlugAttrs: AttrSchema{"plug-attr": {Type: AttrString}},
+		SlotAttrs: AttrSchema{"slot-attr": {Type: AttrBool, Required: true}},
+	}}
 	i3 := &ifacetest.TestInterface{InterfaceName: "i3", InterfaceStaticInfo: StaticInfo{Summary: "i3 summary", DocURL: "http://example.com/i3"}}
 	c.Assert(r.AddInterface(i1), IsNil)
 	c.Assert(r.AddInterface(i2), IsNil)
@@ -1945,6 +1968,15 @@ apps:
 		{Name: "i2", Summary: "i2 summary", DocURL: "http://example.com/i2"},
 	})
 
+	// We can ask for the schema of attributes.
+	infos = r.Info(&InfoOptions{Names: []string{"i2"}, Attrs: true})
+	c.Assert(infos, DeepEquals, []*Info{{
+		Name:      "i2",
+		Summary:   "i2 summary",
+		PlugAttrs: AttrSchema{"plug-attr": {Type: AttrString}},
+		SlotAttrs: AttrSchema{"slot-attr": {Type: AttrBool, Required: true}},
+	}})
+
 	// We can ask for a list of plugs.
 	infos = r.Info(&InfoOptions{Names: []string{"i2"}, Plugs: true})
 	c.Assert(infos, DeepEquals, []*Info{
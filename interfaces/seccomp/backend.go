@@ -289,6 +289,21 @@ func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions,
 	return parallelCompile(b.snapSeccomp, changed)
 }
 
+// Profiles returns the seccomp profile sources of the given snap, as Setup
+// would write them, without writing nor compiling them.
+func (b *Backend) Profiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string][]byte, error) {
+	snapName := snapInfo.InstanceName()
+	spec, err := repo.SnapSpecification(b.Name(), snapName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain seccomp specification for snap %q: %s", snapName, err)
+	}
+	content, err := b.deriveContent(spec.(*Specification), opts, snapInfo)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain expected security files for snap %q: %s", snapName, err)
+	}
+	return interfaces.ProfilesFromContent(dirs.SnapSeccompDir, content)
+}
+
 // Remove removes seccomp profiles of a given snap.
 func (b *Backend) Remove(snapName string) error {
 	glob := interfaces.SecurityTagGlob(snapName)
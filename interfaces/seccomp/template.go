@@ -210,6 +210,13 @@ ioprio_get
 
 ipc
 kill
+
+# Landlock can only further restrict the calling thread, snap-exec uses it to
+# confine snaps on systems without AppArmor.
+landlock_add_rule
+landlock_create_ruleset
+landlock_restrict_self
+
 link
 linkat
 
@@ -36,6 +36,7 @@ import (
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/sandbox/apparmor"
 	"github.com/snapcore/snapd/sandbox/cgroup"
+	"github.com/snapcore/snapd/sandbox/landlock"
 	"github.com/snapcore/snapd/sandbox/seccomp"
 	"github.com/snapcore/snapd/snapdtool"
 )
@@ -78,10 +79,11 @@ type systemKey struct {
 	SecCompActions         []string `json:"seccomp-features"`
 	SeccompCompilerVersion string   `json:"seccomp-compiler-version"`
 	CgroupVersion          string   `json:"cgroup-version"`
+	LandlockFeatures       []string `json:"landlock-features"`
 }
 
 // IMPORTANT: when adding/removing/changing inputs bump this
-const systemKeyVersion = 10
+const systemKeyVersion = 11
 
 var (
 	isHomeUsingNFS        = osutil.IsHomeUsingNFS
@@ -156,6 +158,10 @@ func generateSystemKey() (*systemKey, error) {
 	}
 	sk.CgroupVersion = strconv.FormatInt(int64(cgv), 10)
 
+	// Add landlock-features, the landlock rulesets are only enforced
+	// when landlock is available
+	sk.LandlockFeatures = landlock.Features()
+
 	return sk, nil
 }
 
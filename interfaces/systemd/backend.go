@@ -111,6 +111,17 @@ func (b *Backend) Setup(snapInfo *snap.Info, confinement interfaces.ConfinementO
 	return errEnsure
 }
 
+// Profiles returns the systemd services of the given snap, as Setup would
+// write them, without writing nor starting them.
+func (b *Backend) Profiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string][]byte, error) {
+	snapName := snapInfo.InstanceName()
+	spec, err := repo.SnapSpecification(b.Name(), snapName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain systemd services for snap %q: %s", snapName, err)
+	}
+	return interfaces.ProfilesFromContent(dirs.SnapServicesDir, deriveContent(spec.(*Specification), snapInfo))
+}
+
 // Remove disables, stops and removes systemd services of a given snap.
 func (b *Backend) Remove(snapName string) error {
 	var systemd sysd.Systemd
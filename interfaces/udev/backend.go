@@ -75,7 +75,7 @@ func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions,
 	if err != nil {
 		return fmt.Errorf("cannot obtain udev specification for snap %q: %s", snapName, err)
 	}
-	content := b.deriveContent(spec.(*Specification), snapInfo)
+	rules := b.rulesFileContent(spec.(*Specification), snapInfo, opts)
 	subsystemTriggers := spec.(*Specification).TriggeredSubsystems()
 
 	dir := dirs.SnapUdevRulesDir
@@ -85,7 +85,7 @@ func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions,
 
 	rulesFilePath := snapRulesFilePath(snapInfo.InstanceName())
 
-	if len(content) == 0 {
+	if rules == nil {
 		// Make sure that the rules file gets removed when we don't have any
 		// content and exists.
 		err = os.Remove(rulesFilePath)
@@ -100,22 +100,8 @@ func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions,
 		return nil
 	}
 
-	var buffer bytes.Buffer
-	buffer.WriteString("# This file is automatically generated.\n")
-	if (opts.DevMode || opts.Classic) && !opts.JailMode {
-		buffer.WriteString("# udev tagging/device cgroups disabled with non-strict mode snaps\n")
-	}
-	for _, snippet := range content {
-		if (opts.DevMode || opts.Classic) && !opts.JailMode {
-			buffer.WriteRune('#')
-			snippet = strings.Replace(snippet, "\n", "\n#", -1)
-		}
-		buffer.WriteString(snippet)
-		buffer.WriteByte('\n')
-	}
-
 	rulesFileState := &osutil.MemoryFileState{
-		Content: buffer.Bytes(),
+		Content: rules,
 		Mode:    0644,
 	}
 
@@ -135,6 +121,45 @@ func (b *Backend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions,
 	return b.reloadRules(subsystemTriggers)
 }
 
+// rulesFileContent returns the content of the udev rules file of the given
+// snap, or nil if it has no udev rules.
+func (b *Backend) rulesFileContent(spec *Specification, snapInfo *snap.Info, opts interfaces.ConfinementOptions) []byte {
+	content := b.deriveContent(spec, snapInfo)
+	if len(content) == 0 {
+		return nil
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("# This file is automatically generated.\n")
+	if (opts.DevMode || opts.Classic) && !opts.JailMode {
+		buffer.WriteString("# udev tagging/device cgroups disabled with non-strict mode snaps\n")
+	}
+	for _, snippet := range content {
+		if (opts.DevMode || opts.Classic) && !opts.JailMode {
+			buffer.WriteRune('#')
+			snippet = strings.Replace(snippet, "\n", "\n#", -1)
+		}
+		buffer.WriteString(snippet)
+		buffer.WriteByte('\n')
+	}
+	return buffer.Bytes()
+}
+
+// Profiles returns the udev rules of the given snap, as Setup would write
+// them, without writing them nor reloading udev.
+func (b *Backend) Profiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string][]byte, error) {
+	snapName := snapInfo.InstanceName()
+	spec, err := repo.SnapSpecification(b.Name(), snapName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain udev specification for snap %q: %s", snapName, err)
+	}
+	profiles := make(map[string][]byte)
+	if rules := b.rulesFileContent(spec.(*Specification), snapInfo, opts); rules != nil {
+		profiles[snapRulesFilePath(snapName)] = rules
+	}
+	return profiles, nil
+}
+
 // Remove removes udev rules specific to a given snap.
 // If any of the rules are removed then udev database is reloaded.
 //
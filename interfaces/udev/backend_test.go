@@ -32,6 +32,7 @@ import (
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/sandbox/cgroup"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/timings"
 )
@@ -533,3 +534,21 @@ func (s *backendSuite) TestSandboxFeatures(c *C) {
 		"tagging",
 	})
 }
+
+func (s *backendSuite) TestProfiles(c *C) {
+	s.Iface.UDevPermanentSlotCallback = func(spec *udev.Specification, slot *snap.SlotInfo) error {
+		spec.AddSnippet("dummy")
+		return nil
+	}
+	snapInfo := snaptest.MockInfo(c, ifacetest.SambaYamlV1, nil)
+	c.Assert(s.Repo.AddSnap(snapInfo), IsNil)
+
+	profiles, err := s.Backend.(interfaces.SecurityBackendProfiles).Profiles(snapInfo, interfaces.ConfinementOptions{}, s.Repo)
+	c.Assert(err, IsNil)
+	fname := filepath.Join(dirs.SnapUdevRulesDir, "70-snap.samba.rules")
+	c.Assert(profiles, HasLen, 1)
+	c.Check(string(profiles[fname]), Equals, "# This file is automatically generated.\ndummy\n")
+	// nothing was written nor reloaded
+	c.Check(fname, testutil.FileAbsent)
+	c.Check(s.udevadmCmd.Calls(), HasLen, 0)
+}
@@ -19,7 +19,11 @@
 
 package configcore
 
-import "github.com/snapcore/snapd/osutil/sys"
+import (
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/osutil/sys"
+	"github.com/snapcore/snapd/overlord/state"
+)
 
 var (
 	UpdatePiConfig       = updatePiConfig
@@ -48,3 +52,11 @@ func MockChownPath(f func(string, sys.UserID, sys.GroupID) error) func() {
 		sysChownPath = old
 	}
 }
+
+func MockDeviceGadgetKernelCmdline(f func(*state.State) (*gadget.KernelCmdline, error)) func() {
+	old := deviceGadgetKernelCmdline
+	deviceGadgetKernelCmdline = f
+	return func() {
+		deviceGadgetKernelCmdline = old
+	}
+}
@@ -0,0 +1,100 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+// +build !nomanagers
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package configcore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+const kernelCmdlineAppendOpt = "system.kernel.cmdline-append"
+
+func init() {
+	// add supported configuration of this module
+	supportedConfigurations["core."+kernelCmdlineAppendOpt] = true
+}
+
+var deviceGadgetKernelCmdline = deviceGadgetKernelCmdlineImpl
+
+// deviceGadgetKernelCmdlineImpl returns the kernel command line constraints
+// declared by the gadget of a UC20+ device.
+func deviceGadgetKernelCmdlineImpl(st *state.State) (*gadget.KernelCmdline, error) {
+	deviceCtx, err := devicestate.DeviceCtx(st, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if deviceCtx.Model().Grade() == asserts.ModelGradeUnset {
+		return nil, fmt.Errorf("only supported on UC20+ systems")
+	}
+	info, err := snapstate.GadgetInfo(st, deviceCtx)
+	if err != nil {
+		return nil, err
+	}
+	// no constraints enforced: those should have been checked before already
+	gi, err := gadget.ReadInfo(info.MountDir(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &gi.KernelCmdline, nil
+}
+
+// validateKernelCmdlineAppend checks that the kernel command line arguments
+// to append are well formed and allowed by the gadget. The arguments are
+// applied by the device manager, which takes care of resealing the
+// encryption keys and rebooting.
+func validateKernelCmdlineAppend(tr config.Conf) error {
+	cmdline, err := coreCfg(tr, kernelCmdlineAppendOpt)
+	if err != nil {
+		return err
+	}
+	if cmdline == "" {
+		return nil
+	}
+	args, err := osutil.KernelCommandLineSplit(cmdline)
+	if err != nil {
+		return fmt.Errorf("cannot set %q: %v", kernelCmdlineAppendOpt, err)
+	}
+
+	st := tr.State()
+	st.Lock()
+	defer st.Unlock()
+
+	constraints, err := deviceGadgetKernelCmdline(st)
+	if err != nil {
+		return fmt.Errorf("cannot set %q: %v", kernelCmdlineAppendOpt, err)
+	}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "snapd_") {
+			return fmt.Errorf("cannot set %q: argument %q is reserved for snapd", kernelCmdlineAppendOpt, arg)
+		}
+		if !constraints.AllowsArg(arg) {
+			return fmt.Errorf("cannot set %q: argument %q is not allowed by the gadget", kernelCmdlineAppendOpt, arg)
+		}
+	}
+	return nil
+}
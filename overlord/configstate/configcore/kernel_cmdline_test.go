@@ -0,0 +1,108 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore_test
+
+import (
+	"fmt"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/gadget"
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+type kernelCmdlineSuite struct {
+	configcoreSuite
+
+	constraintsCalls int
+	constraintsErr   error
+}
+
+var _ = Suite(&kernelCmdlineSuite{})
+
+func (s *kernelCmdlineSuite) SetUpTest(c *C) {
+	s.configcoreSuite.SetUpTest(c)
+
+	s.constraintsCalls = 0
+	s.constraintsErr = nil
+	s.AddCleanup(configcore.MockDeviceGadgetKernelCmdline(func(st *state.State) (*gadget.KernelCmdline, error) {
+		s.constraintsCalls++
+		if s.constraintsErr != nil {
+			return nil, s.constraintsErr
+		}
+		return &gadget.KernelCmdline{
+			Allow: []string{"isolcpus=*", "nosmt"},
+		}, nil
+	}))
+}
+
+func (s *kernelCmdlineSuite) TestConfigureKernelCmdlineAppendHappy(c *C) {
+	err := configcore.Run(&mockConf{
+		state: s.state,
+		changes: map[string]interface{}{
+			"system.kernel.cmdline-append": "isolcpus=1-3 nosmt",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.constraintsCalls, Equals, 1)
+
+	// resetting is always possible
+	err = configcore.Run(&mockConf{
+		state: s.state,
+		changes: map[string]interface{}{
+			"system.kernel.cmdline-append": "",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.constraintsCalls, Equals, 1)
+}
+
+func (s *kernelCmdlineSuite) TestConfigureKernelCmdlineAppendUnhappy(c *C) {
+	for _, tc := range []struct {
+		cmdline string
+		err     string
+	}{
+		{`isolcpus=1 nosmt=force`, `cannot set "system.kernel.cmdline-append": argument "nosmt=force" is not allowed by the gadget`},
+		{`panic=-1`, `cannot set "system.kernel.cmdline-append": argument "panic=-1" is not allowed by the gadget`},
+		{`snapd_recovery_mode=run`, `cannot set "system.kernel.cmdline-append": argument "snapd_recovery_mode=run" is reserved for snapd`},
+		{`isolcpus="1`, `cannot set "system.kernel.cmdline-append": unbalanced quoting`},
+	} {
+		err := configcore.Run(&mockConf{
+			state: s.state,
+			changes: map[string]interface{}{
+				"system.kernel.cmdline-append": tc.cmdline,
+			},
+		})
+		c.Check(err, ErrorMatches, tc.err, Commentf("%q", tc.cmdline))
+	}
+}
+
+func (s *kernelCmdlineSuite) TestConfigureKernelCmdlineAppendNotSupported(c *C) {
+	s.constraintsErr = fmt.Errorf("only supported on UC20+ systems")
+
+	err := configcore.Run(&mockConf{
+		state: s.state,
+		changes: map[string]interface{}{
+			"system.kernel.cmdline-append": "nosmt",
+		},
+	})
+	c.Assert(err, ErrorMatches, `cannot set "system.kernel.cmdline-append": only supported on UC20\+ systems`)
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/configstate/proxyconf"
 )
 
 var proxyConfigKeys = map[string]bool{
@@ -47,6 +48,8 @@ func init() {
 	supportedConfigurations["core.proxy.ftp"] = true
 	supportedConfigurations["core.proxy.no-proxy"] = true
 	supportedConfigurations["core.proxy.store"] = true
+	supportedConfigurations["core.proxy.pac-url"] = true
+	supportedConfigurations["core.proxy.rules"] = true
 }
 
 func etcEnvironment() string {
@@ -120,3 +123,24 @@ func validateProxyStore(tr config.Conf) error {
 	}
 	return err
 }
+
+func validateProxyAutoConfig(tr config.Conf) error {
+	pacURL, err := coreCfg(tr, "proxy.pac-url")
+	if err != nil {
+		return err
+	}
+	if pacURL != "" {
+		if err := proxyconf.ValidatePACURL(pacURL); err != nil {
+			return fmt.Errorf("cannot set proxy.pac-url: %v", err)
+		}
+	}
+
+	rules, err := coreCfg(tr, "proxy.rules")
+	if err != nil {
+		return err
+	}
+	if _, err := proxyconf.ParseRules(rules); err != nil {
+		return fmt.Errorf("cannot set proxy.rules: %v", err)
+	}
+	return nil
+}
@@ -199,3 +199,41 @@ func (s *proxySuite) TestConfigureProxyStoreNoURL(c *C) {
 	err = configcore.Run(conf)
 	c.Check(err, ErrorMatches, `cannot set proxy.store to "foo" with a matching store assertion with url unset`)
 }
+
+func (s *proxySuite) TestConfigureProxyAutoConfig(c *C) {
+	err := configcore.Run(&mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"proxy.pac-url": "http://wpad.internal/proxy.pac",
+			"proxy.rules":   "*.cdn.snapcraftcontent.com=http://cdn-proxy:3128,api.snapcraft.io=DIRECT",
+		},
+	})
+	c.Check(err, IsNil)
+
+	err = configcore.Run(&mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"proxy.pac-url": "file:///etc/proxy.pac",
+		},
+	})
+	c.Check(err, IsNil)
+}
+
+func (s *proxySuite) TestConfigureProxyAutoConfigUnhappy(c *C) {
+	for _, t := range []struct {
+		key, value, err string
+	}{
+		{"proxy.pac-url", "ftp://wpad.internal/proxy.pac", `cannot set proxy.pac-url: unsupported URL scheme "ftp"`},
+		{"proxy.pac-url", "http:///proxy.pac", `cannot set proxy.pac-url: missing host in "http:///proxy.pac"`},
+		{"proxy.pac-url", "file:proxy.pac", `cannot set proxy.pac-url: path in "file:proxy.pac" must be absolute`},
+		{"proxy.rules", "foo.com", `cannot set proxy.rules: invalid proxy rule "foo.com": .*`},
+	} {
+		err := configcore.Run(&mockConf{
+			state: s.state,
+			conf: map[string]interface{}{
+				t.key: t.value,
+			},
+		})
+		c.Check(err, ErrorMatches, t.err, Commentf(t.value))
+	}
+}
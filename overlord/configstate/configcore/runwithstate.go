@@ -53,6 +53,9 @@ func init() {
 	addWithStateHandler(validateRefreshSchedule, nil, validateOnly)
 	addWithStateHandler(validateRefreshRateLimit, nil, validateOnly)
 	addWithStateHandler(validateAutomaticSnapshotsExpiration, nil, validateOnly)
+	addWithStateHandler(validateStoreTLSSettings, nil, validateOnly)
+	addWithStateHandler(validateProxyAutoConfig, nil, validateOnly)
+	addWithStateHandler(validateKernelCmdlineAppend, nil, validateOnly)
 }
 
 type withStateHandler struct {
@@ -0,0 +1,56 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+// +build !nomanagers
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+import (
+	"fmt"
+
+	"github.com/snapcore/snapd/httputil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+)
+
+func init() {
+	// add supported configuration of this module
+	supportedConfigurations["core.store.pinned-public-keys"] = true
+	supportedConfigurations["core.store.client-certificate"] = true
+}
+
+func validateStoreTLSSettings(tr config.Conf) error {
+	pins, err := coreCfg(tr, "store.pinned-public-keys")
+	if err != nil {
+		return err
+	}
+	if _, err := httputil.ParsePinnedPublicKeys(pins); err != nil {
+		return fmt.Errorf("cannot set store.pinned-public-keys: %v", err)
+	}
+
+	clientCert, err := coreCfg(tr, "store.client-certificate")
+	if err != nil {
+		return err
+	}
+	switch clientCert {
+	case "", "device":
+		// ok
+	default:
+		return fmt.Errorf(`store.client-certificate can only be set to "device"`)
+	}
+	return nil
+}
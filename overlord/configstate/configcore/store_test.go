@@ -0,0 +1,66 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore_test
+
+import (
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+)
+
+type storeSuite struct {
+	configcoreSuite
+}
+
+var _ = Suite(&storeSuite{})
+
+func (s *storeSuite) TestConfigureStoreTLSHappy(c *C) {
+	pin := "sha256/" + strings.Repeat("A", 43) + "="
+	err := configcore.Run(&mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"store.pinned-public-keys": pin + "," + pin,
+			"store.client-certificate": "device",
+		},
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *storeSuite) TestConfigureStorePinnedPublicKeysInvalid(c *C) {
+	err := configcore.Run(&mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"store.pinned-public-keys": "sha256/foo",
+		},
+	})
+	c.Assert(err, ErrorMatches, `cannot set store.pinned-public-keys: invalid pinned public key "sha256/foo": must be a base64 encoded SHA256 hash`)
+}
+
+func (s *storeSuite) TestConfigureStoreClientCertificateInvalid(c *C) {
+	err := configcore.Run(&mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"store.client-certificate": "foo",
+		},
+	})
+	c.Assert(err, ErrorMatches, `store.client-certificate can only be set to "device"`)
+}
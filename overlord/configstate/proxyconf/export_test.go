@@ -0,0 +1,46 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package proxyconf
+
+import (
+	"time"
+)
+
+func MockPACRefreshInterval(d time.Duration) (restore func()) {
+	old := pacRefreshInterval
+	pacRefreshInterval = d
+	return func() {
+		pacRefreshInterval = old
+	}
+}
+
+func MockPACRetryInterval(d time.Duration) (restore func()) {
+	old := pacRetryInterval
+	pacRetryInterval = d
+	return func() {
+		pacRetryInterval = old
+	}
+}
+
+// WaitPACFetch waits for any background fetch of the PAC script to be
+// done.
+func (p *ProxySettings) WaitPACFetch() {
+	p.pacFetching.Wait()
+}
@@ -21,28 +21,98 @@ package proxyconf
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/snapcore/snapd/httputil"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/state"
 )
 
+var (
+	// pacRefreshInterval is how long a fetched PAC script is used
+	// before being fetched again.
+	pacRefreshInterval = 1 * time.Hour
+	// pacRetryInterval is how long to wait before fetching a PAC
+	// script again after a failure.
+	pacRetryInterval = 1 * time.Minute
+	// maxPACSize is the maximum size of a PAC script.
+	maxPACSize int64 = 1024 * 1024
+
+	pacHTTPClient = &http.Client{
+		Timeout: 30 * time.Second,
+		// the PAC script itself is always fetched directly
+		Transport: &http.Transport{Proxy: nil},
+	}
+)
+
 type ProxySettings struct {
 	st *state.State
+
+	mu          sync.Mutex
+	pacURL      string
+	pac         *httputil.PAC
+	pacFetched  time.Time
+	pacFailed   bool
+	pacFetching sync.WaitGroup
+	pacInFlight bool
 }
 
 func New(st *state.State) *ProxySettings {
 	return &ProxySettings{st: st}
 }
 
+// Conf returns the proxy to use for the given request. The
+// proxy.rules are consulted first, then the proxy.pac-url script,
+// then the proxy.{http,https} settings and finally the environment.
+// The PAC script is fetched in the background, until it was fetched
+// successfully it is not consulted.
 func (p *ProxySettings) Conf(req *http.Request) (*url.URL, error) {
 	p.st.Lock()
 	tr := config.NewTransaction(p.st)
 	p.st.Unlock()
 
+	var rules string
+	err := tr.Get("core", "proxy.rules", &rules)
+	if err != nil && !config.IsNoOption(err) {
+		return nil, err
+	}
+	if rules != "" {
+		parsed, err := ParseRules(rules)
+		if err != nil {
+			return nil, err
+		}
+		if proxy, ok := parsed.match(req.URL.Hostname()); ok {
+			return proxy, nil
+		}
+	}
+
+	var pacURL string
+	err = tr.Get("core", "proxy.pac-url", &pacURL)
+	if err != nil && !config.IsNoOption(err) {
+		return nil, err
+	}
+	if pacURL != "" {
+		if pac := p.pacScript(pacURL); pac != nil {
+			// like browsers do, do not expose the path and query,
+			// which may carry credentials, to the script
+			u := &url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: "/"}
+			res, err := pac.FindProxyForURL(u)
+			if err != nil {
+				return nil, err
+			}
+			return httputil.ProxyFromPACResult(res)
+		}
+	}
+
 	var proxy string
-	err := tr.Get("core", fmt.Sprintf("proxy.%s", req.URL.Scheme), &proxy)
+	err = tr.Get("core", fmt.Sprintf("proxy.%s", req.URL.Scheme), &proxy)
 	if proxy == "" || config.IsNoOption(err) {
 		return http.ProxyFromEnvironment(req)
 	}
@@ -55,3 +125,170 @@ func (p *ProxySettings) Conf(req *http.Request) (*url.URL, error) {
 	}
 	return url, nil
 }
+
+// pacScript returns the parsed PAC script at the given URL if it was
+// fetched already, or nil otherwise. The script is fetched in the
+// background if it was not fetched yet or the cached copy is too old.
+// If refetching fails the cached copy keeps being used.
+func (p *ProxySettings) pacScript(pacURL string) *httputil.PAC {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pacURL != pacURL {
+		p.pacURL = pacURL
+		p.pac = nil
+		p.pacFetched = time.Time{}
+	}
+	interval := pacRefreshInterval
+	if p.pacFailed {
+		interval = pacRetryInterval
+	}
+	if !p.pacInFlight && time.Since(p.pacFetched) >= interval {
+		p.pacInFlight = true
+		p.pacFetching.Add(1)
+		go p.fetchPACInBackground(pacURL)
+	}
+	return p.pac
+}
+
+func (p *ProxySettings) fetchPACInBackground(pacURL string) {
+	defer p.pacFetching.Done()
+
+	pac, err := fetchPAC(pacURL)
+	if err != nil {
+		logger.Noticef("%v", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pacInFlight = false
+	if p.pacURL != pacURL {
+		// the setting changed in the meantime
+		return
+	}
+	p.pacFetched = time.Now()
+	p.pacFailed = err != nil
+	if err == nil {
+		p.pac = pac
+	}
+}
+
+func fetchPAC(pacURL string) (*httputil.PAC, error) {
+	u, err := url.Parse(pacURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse proxy auto-configuration URL: %v", err)
+	}
+
+	var script []byte
+	switch u.Scheme {
+	case "file":
+		script, err = ioutil.ReadFile(u.Path)
+	case "http", "https":
+		var resp *http.Response
+		resp, err = pacHTTPClient.Get(pacURL)
+		if err != nil {
+			break
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+			break
+		}
+		script, err = ioutil.ReadAll(io.LimitReader(resp.Body, maxPACSize+1))
+		if err == nil && int64(len(script)) > maxPACSize {
+			err = fmt.Errorf("script is larger than %d bytes", maxPACSize)
+		}
+	default:
+		err = fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch proxy auto-configuration from %s: %v", pacURL, err)
+	}
+
+	return httputil.ParsePAC(script)
+}
+
+// ValidatePACURL checks that the given proxy auto-configuration URL
+// is of a supported kind.
+func ValidatePACURL(pacURL string) error {
+	u, err := url.Parse(pacURL)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("missing host in %q", pacURL)
+		}
+	case "file":
+		if !strings.HasPrefix(u.Path, "/") {
+			return fmt.Errorf("path in %q must be absolute", pacURL)
+		}
+	default:
+		return fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	return nil
+}
+
+type rule struct {
+	pattern string
+	// proxy is nil for DIRECT
+	proxy *url.URL
+}
+
+// Rules is a parsed list of per-host proxy rules.
+type Rules []rule
+
+// ParseRules parses a list of per-host proxy rules as set via
+// proxy.rules. Rules are separated by commas or whitespace and have the
+// form <host-pattern>=<proxy-url>, or <host-pattern>=DIRECT to bypass
+// any proxy. A host pattern is either a host name, a host name prefixed
+// with "*." to match any of its subdomains, or "*" to match any host.
+// The first matching rule wins.
+func ParseRules(rules string) (Rules, error) {
+	var parsed Rules
+	entries := strings.FieldsFunc(rules, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	for _, entry := range entries {
+		idx := strings.IndexRune(entry, '=')
+		if idx <= 0 || idx == len(entry)-1 {
+			return nil, fmt.Errorf("invalid proxy rule %q: must be of the form <host-pattern>=<proxy>", entry)
+		}
+		pattern := strings.ToLower(entry[:idx])
+		if pattern != "*" && strings.Contains(strings.TrimPrefix(pattern, "*."), "*") {
+			return nil, fmt.Errorf("invalid proxy rule %q: unsupported host pattern %q", entry, pattern)
+		}
+		r := rule{pattern: pattern}
+		target := entry[idx+1:]
+		if strings.ToUpper(target) != "DIRECT" {
+			proxy, err := url.Parse(target)
+			if err != nil || proxy.Host == "" || (proxy.Scheme != "http" && proxy.Scheme != "https") {
+				return nil, fmt.Errorf("invalid proxy rule %q: proxy must be DIRECT or an http(s) URL", entry)
+			}
+			r.proxy = proxy
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+// match returns the proxy for the given host from the first matching
+// rule, ok is false if no rule matches.
+func (rules Rules) match(host string) (proxy *url.URL, ok bool) {
+	host = strings.ToLower(host)
+	for _, r := range rules {
+		switch {
+		case r.pattern == "*":
+		case strings.HasPrefix(r.pattern, "*."):
+			if !strings.HasSuffix(host, r.pattern[1:]) {
+				continue
+			}
+		case r.pattern != host:
+			continue
+		}
+		return r.proxy, true
+	}
+	return nil, false
+}
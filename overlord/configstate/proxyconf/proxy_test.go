@@ -20,12 +20,17 @@
 package proxyconf_test
 
 import (
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/configstate/proxyconf"
 	"github.com/snapcore/snapd/overlord/state"
@@ -72,3 +77,269 @@ func (s *proxyconfSuite) TestProxySettings(c *C) {
 		Host:   "some-proxy:3128",
 	})
 }
+
+func setConf(c *C, st *state.State, key string, value interface{}) {
+	st.Lock()
+	defer st.Unlock()
+	tr := config.NewTransaction(st)
+	c.Assert(tr.Set("core", key, value), IsNil)
+	tr.Commit()
+}
+
+func proxyFor(c *C, proxyConf *proxyconf.ProxySettings, rawurl string) *url.URL {
+	req, err := http.NewRequest("GET", rawurl, nil)
+	c.Assert(err, IsNil)
+	proxy, err := proxyConf.Conf(req)
+	c.Assert(err, IsNil)
+	return proxy
+}
+
+func (s *proxyconfSuite) TestProxySettingsRules(c *C) {
+	st := state.New(nil)
+
+	setConf(c, st, "proxy.http", "http://some-proxy:3128")
+	setConf(c, st, "proxy.rules", "*.cdn.snapcraftcontent.com=http://cdn-proxy:3128, api.snapcraft.io=DIRECT\nlocal.internal=https://local-proxy")
+
+	proxyConf := proxyconf.New(st)
+	c.Check(proxyFor(c, proxyConf, "http://a.cdn.snapcraftcontent.com/foo"), DeepEquals, &url.URL{
+		Scheme: "http",
+		Host:   "cdn-proxy:3128",
+	})
+	c.Check(proxyFor(c, proxyConf, "http://API.snapcraft.io/v2"), IsNil)
+	c.Check(proxyFor(c, proxyConf, "http://local.internal"), DeepEquals, &url.URL{
+		Scheme: "https",
+		Host:   "local-proxy",
+	})
+	// no rule matches, fallback to proxy.http
+	c.Check(proxyFor(c, proxyConf, "http://example.com"), DeepEquals, &url.URL{
+		Scheme: "http",
+		Host:   "some-proxy:3128",
+	})
+}
+
+func (s *proxyconfSuite) TestParseRules(c *C) {
+	_, err := proxyconf.ParseRules("foo.com=DIRECT *=http://proxy:3128, *.bar.com=https://proxy")
+	c.Check(err, IsNil)
+
+	for _, t := range []struct {
+		rules string
+		err   string
+	}{
+		{"foo.com", `invalid proxy rule "foo.com": must be of the form <host-pattern>=<proxy>`},
+		{"=DIRECT", `invalid proxy rule "=DIRECT": must be of the form <host-pattern>=<proxy>`},
+		{"foo.com=", `invalid proxy rule "foo.com=": must be of the form <host-pattern>=<proxy>`},
+		{"foo*.com=DIRECT", `invalid proxy rule "foo\*.com=DIRECT": unsupported host pattern "foo\*.com"`},
+		{"*foo.com=DIRECT", `invalid proxy rule "\*foo.com=DIRECT": unsupported host pattern "\*foo.com"`},
+		{"foo.com=socks5://proxy:1080", `invalid proxy rule "foo.com=socks5://proxy:1080": proxy must be DIRECT or an http\(s\) URL`},
+		{"foo.com=proxy:3128", `invalid proxy rule "foo.com=proxy:3128": proxy must be DIRECT or an http\(s\) URL`},
+	} {
+		_, err := proxyconf.ParseRules(t.rules)
+		c.Check(err, ErrorMatches, t.err, Commentf(t.rules))
+	}
+}
+
+const testPAC = `function FindProxyForURL(url, host) {
+	if (dnsDomainIs(host, ".snapcraftcontent.com"))
+		return "PROXY cdn-proxy:3128";
+	return "DIRECT";
+}`
+
+func (s *proxyconfSuite) TestProxySettingsPAC(c *C) {
+	st := state.New(nil)
+
+	fetched := 0
+	pacServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		w.Write([]byte(testPAC))
+	}))
+	defer pacServer.Close()
+
+	setConf(c, st, "proxy.http", "http://some-proxy:3128")
+	setConf(c, st, "proxy.rules", "api.snapcraft.io=http://api-proxy:3128")
+	setConf(c, st, "proxy.pac-url", pacServer.URL+"/proxy.pac")
+
+	proxyConf := proxyconf.New(st)
+	// the script is fetched in the background, proxy.http is used
+	// in the meantime
+	c.Check(proxyFor(c, proxyConf, "http://a.cdn.snapcraftcontent.com/foo"), DeepEquals, &url.URL{
+		Scheme: "http",
+		Host:   "some-proxy:3128",
+	})
+	proxyConf.WaitPACFetch()
+	c.Check(fetched, Equals, 1)
+
+	c.Check(proxyFor(c, proxyConf, "https://a.cdn.snapcraftcontent.com/foo"), DeepEquals, &url.URL{
+		Scheme: "http",
+		Host:   "cdn-proxy:3128",
+	})
+	// the PAC result wins over proxy.http
+	c.Check(proxyFor(c, proxyConf, "http://example.com"), IsNil)
+	// the rules win over the PAC result
+	c.Check(proxyFor(c, proxyConf, "http://api.snapcraft.io"), DeepEquals, &url.URL{
+		Scheme: "http",
+		Host:   "api-proxy:3128",
+	})
+	// the script is cached
+	proxyConf.WaitPACFetch()
+	c.Check(fetched, Equals, 1)
+
+	// and refetched when it gets too old
+	restore := proxyconf.MockPACRefreshInterval(0)
+	defer restore()
+	proxyFor(c, proxyConf, "http://example.com")
+	proxyConf.WaitPACFetch()
+	c.Check(fetched, Equals, 2)
+
+	// a cached copy is used if refetching fails
+	pacServer.Close()
+	c.Check(proxyFor(c, proxyConf, "https://a.cdn.snapcraftcontent.com/foo"), DeepEquals, &url.URL{
+		Scheme: "http",
+		Host:   "cdn-proxy:3128",
+	})
+	proxyConf.WaitPACFetch()
+	c.Check(proxyFor(c, proxyConf, "https://a.cdn.snapcraftcontent.com/foo"), DeepEquals, &url.URL{
+		Scheme: "http",
+		Host:   "cdn-proxy:3128",
+	})
+}
+
+func (s *proxyconfSuite) TestProxySettingsPACFile(c *C) {
+	st := state.New(nil)
+
+	pacFile := filepath.Join(c.MkDir(), "proxy.pac")
+	c.Assert(ioutil.WriteFile(pacFile, []byte(testPAC), 0644), IsNil)
+	setConf(c, st, "proxy.pac-url", "file://"+pacFile)
+
+	proxyConf := proxyconf.New(st)
+	proxyFor(c, proxyConf, "https://a.cdn.snapcraftcontent.com/foo")
+	proxyConf.WaitPACFetch()
+	c.Check(proxyFor(c, proxyConf, "https://a.cdn.snapcraftcontent.com/foo"), DeepEquals, &url.URL{
+		Scheme: "http",
+		Host:   "cdn-proxy:3128",
+	})
+}
+
+func (s *proxyconfSuite) TestProxySettingsPACURLStripped(c *C) {
+	st := state.New(nil)
+
+	pacFile := filepath.Join(c.MkDir(), "proxy.pac")
+	c.Assert(ioutil.WriteFile(pacFile, []byte(`function FindProxyForURL(url, host) {
+	if (url == "https://api.snapcraft.io/")
+		return "PROXY stripped:3128";
+	return "PROXY not-stripped:3128";
+}`), 0644), IsNil)
+	setConf(c, st, "proxy.pac-url", "file://"+pacFile)
+
+	proxyConf := proxyconf.New(st)
+	proxyFor(c, proxyConf, "https://api.snapcraft.io")
+	proxyConf.WaitPACFetch()
+	c.Check(proxyFor(c, proxyConf, "https://api.snapcraft.io/v2/snaps/find?q=secret"), DeepEquals, &url.URL{
+		Scheme: "http",
+		Host:   "stripped:3128",
+	})
+}
+
+func (s *proxyconfSuite) TestProxySettingsPACErrors(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	st := state.New(nil)
+
+	fetched := 0
+	pacServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(404)
+			return
+		}
+		w.Write([]byte("function foo() {}"))
+	}))
+	defer pacServer.Close()
+
+	os.Setenv("http_proxy", "http://env-proxy:3128")
+	defer os.Unsetenv("http_proxy")
+	req, err := http.NewRequest("GET", "http://example.com", nil)
+	c.Assert(err, IsNil)
+	expected, err := http.ProxyFromEnvironment(req)
+	c.Assert(err, IsNil)
+
+	proxyConf := proxyconf.New(st)
+
+	// without a fetched script the environment is used
+	setConf(c, st, "proxy.pac-url", pacServer.URL+"/missing")
+	proxy, err := proxyConf.Conf(req)
+	c.Assert(err, IsNil)
+	c.Check(proxy, DeepEquals, expected)
+	proxyConf.WaitPACFetch()
+	c.Check(logbuf.String(), Matches, `(?s).*cannot fetch proxy auto-configuration from .*/missing: unexpected status code 404\n`)
+	proxy, err = proxyConf.Conf(req)
+	c.Assert(err, IsNil)
+	c.Check(proxy, DeepEquals, expected)
+	proxyConf.WaitPACFetch()
+	// not retried right away
+	c.Check(fetched, Equals, 1)
+
+	setConf(c, st, "proxy.pac-url", pacServer.URL+"/bad")
+	proxy, err = proxyConf.Conf(req)
+	c.Assert(err, IsNil)
+	c.Check(proxy, DeepEquals, expected)
+	proxyConf.WaitPACFetch()
+	c.Check(logbuf.String(), Matches, `(?s).*cannot parse proxy auto-configuration: unsupported function "foo", .*`)
+	c.Check(fetched, Equals, 2)
+
+	// retried later
+	restore = proxyconf.MockPACRetryInterval(0)
+	defer restore()
+	_, err = proxyConf.Conf(req)
+	c.Assert(err, IsNil)
+	proxyConf.WaitPACFetch()
+	c.Check(fetched, Equals, 3)
+}
+
+func (s *proxyconfSuite) TestProxySettingsPACStandInProxy(c *C) {
+	st := state.New(nil)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("direct"))
+	}))
+	defer backend.Close()
+
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a proxy gets the absolute URL in the request line
+		proxied = append(proxied, r.URL.String())
+		w.Write([]byte("proxied"))
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	c.Assert(err, IsNil)
+
+	pacFile := filepath.Join(c.MkDir(), "proxy.pac")
+	c.Assert(ioutil.WriteFile(pacFile, []byte(`function FindProxyForURL(url, host) {
+	if (host == "store.example.com")
+		return "PROXY `+proxyURL.Host+`";
+	return "DIRECT";
+}`), 0644), IsNil)
+	setConf(c, st, "proxy.pac-url", "file://"+pacFile)
+
+	proxyConf := proxyconf.New(st)
+	proxyFor(c, proxyConf, "http://store.example.com")
+	proxyConf.WaitPACFetch()
+	client := &http.Client{Transport: &http.Transport{Proxy: proxyConf.Conf}}
+
+	for _, t := range []struct {
+		url      string
+		expected string
+	}{
+		{"http://store.example.com/api/v1/snaps/search", "proxied"},
+		{backend.URL, "direct"},
+	} {
+		resp, err := client.Get(t.url)
+		c.Assert(err, IsNil)
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		c.Assert(err, IsNil)
+		c.Check(string(body), Equals, t.expected)
+	}
+	c.Check(proxied, DeepEquals, []string{"http://store.example.com/api/v1/snaps/search"})
+}
@@ -0,0 +1,190 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate
+
+import (
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/healthstate"
+	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/strutil"
+)
+
+const checkBootHealthHook = "check-boot-health"
+
+var (
+	// bootHealthGracePeriod is how long snaps reporting that they are
+	// waiting are given to become healthy after booting a new kernel or
+	// base, before the boot is considered failed.
+	bootHealthGracePeriod = 5 * time.Minute
+	// bootHealthRetryInterval is the delay before the check-boot-health
+	// hooks of snaps reporting that they are waiting are run again.
+	bootHealthRetryInterval = 30 * time.Second
+)
+
+// bootHealthSnaps returns the names of the installed snaps that declare the
+// check-boot-health hook, sorted.
+func bootHealthSnaps(st *state.State) ([]string, error) {
+	all, err := snapstate.All(st)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name, snapst := range all {
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			logger.Noticef("cannot check for %s hook of snap %q: %v", checkBootHealthHook, name, err)
+			continue
+		}
+		if info.Hooks[checkBootHealthHook] != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// checkBootHealth runs the check-boot-health hooks of snaps when a new kernel
+// or base is being tried. It returns true once the boot can be marked as
+// successful, that is when no snap with the hook is installed, no try boot is
+// happening or all the snaps reported being healthy. When a snap reports an
+// error, a reboot is requested without marking the boot as successful so that
+// the previous kernel and base are used again.
+func (m *DeviceManager) checkBootHealth(deviceCtx snapstate.DeviceContext) (ok bool, err error) {
+	if m.bootHealthFailed {
+		// waiting for the reboot
+		return false, nil
+	}
+
+	var chg *state.Change
+	if m.bootHealthChangeID != "" {
+		chg = m.state.Change(m.bootHealthChangeID)
+	}
+	if chg == nil {
+		names, err := bootHealthSnaps(m.state)
+		if err != nil {
+			return false, err
+		}
+		if len(names) == 0 {
+			return true, nil
+		}
+		trying, err := boot.IsTryingBoot(deviceCtx)
+		if err != nil {
+			// do not prevent marking the boot as successful
+			logger.Noticef("cannot check for a try boot, not checking boot health: %v", err)
+			return true, nil
+		}
+		if !trying {
+			return true, nil
+		}
+		m.bootHealthStarted = timeNow()
+		return false, m.spawnBootHealthChange(names)
+	}
+
+	if !chg.Status().Ready() {
+		// check again soon
+		m.state.EnsureBefore(bootHealthRetryInterval)
+		return false, nil
+	}
+
+	waiting, unhealthy, err := bootHealthOutcome(m.state, chg)
+	if err != nil {
+		return false, err
+	}
+	now := timeNow()
+	if len(waiting) > 0 && now.Sub(m.bootHealthStarted) >= bootHealthGracePeriod {
+		logger.Noticef("snaps %s did not become healthy in %v", strutil.Quoted(waiting), bootHealthGracePeriod)
+		unhealthy = append(unhealthy, waiting...)
+		waiting = nil
+	}
+	if len(unhealthy) > 0 {
+		sort.Strings(unhealthy)
+		m.bootHealthFailed = true
+		m.state.Warnf("snaps %s are not healthy after booting a new kernel or base, rebooting to revert to the previous ones", strutil.Quoted(unhealthy))
+		m.state.RequestRestart(state.RestartSystem)
+		return false, nil
+	}
+	if len(waiting) > 0 {
+		if m.bootHealthRetryAt.IsZero() {
+			m.bootHealthRetryAt = now.Add(bootHealthRetryInterval)
+		}
+		if now.Before(m.bootHealthRetryAt) {
+			m.state.EnsureBefore(m.bootHealthRetryAt.Sub(now))
+			return false, nil
+		}
+		m.bootHealthRetryAt = time.Time{}
+		return false, m.spawnBootHealthChange(waiting)
+	}
+	return true, nil
+}
+
+func (m *DeviceManager) spawnBootHealthChange(names []string) error {
+	chg := m.state.NewChange("check-boot-health", i18n.G("Check boot health of snaps"))
+	for _, name := range names {
+		info, err := snapstate.CurrentInfo(m.state, name)
+		if err != nil {
+			return err
+		}
+		chg.AddTask(healthstate.BootHook(m.state, name, info.Revision))
+	}
+	m.bootHealthChangeID = chg.ID()
+	m.state.EnsureBefore(0)
+	return nil
+}
+
+// bootHealthOutcome returns the snaps of the given check-boot-health change
+// that reported that they are waiting or that are not healthy. Only health
+// reported since the change was created is taken into account, snaps which
+// reported nothing are considered healthy.
+func bootHealthOutcome(st *state.State, chg *state.Change) (waiting, unhealthy []string, err error) {
+	for _, t := range chg.Tasks() {
+		var hooksup hookstate.HookSetup
+		if err := t.Get("hook-setup", &hooksup); err != nil {
+			return nil, nil, err
+		}
+		name := hooksup.Snap
+		if t.Status() == state.ErrorStatus {
+			logger.Noticef("%s hook of snap %q failed", checkBootHealthHook, name)
+			unhealthy = append(unhealthy, name)
+			continue
+		}
+		health, err := healthstate.Get(st, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if health == nil || health.Timestamp.Before(chg.SpawnTime()) {
+			continue
+		}
+		switch health.Status {
+		case healthstate.ErrorStatus, healthstate.BlockedStatus:
+			logger.Noticef("snap %q is not healthy: %s", name, health.Message)
+			unhealthy = append(unhealthy, name)
+		case healthstate.WaitingStatus:
+			waiting = append(waiting, name)
+		}
+	}
+	return waiting, unhealthy, nil
+}
@@ -20,10 +20,16 @@
 package devicestate
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -76,16 +82,32 @@ type DeviceManager struct {
 
 	cachedKeypairMgr asserts.KeypairManager
 
+	// the TLS client certificate for the device key is cached for the
+	// serial assertion it was produced for until close to its expiry
+	cachedClientCert       *tls.Certificate
+	cachedClientCertSerial *asserts.Serial
+
 	// newStore can make new stores for remodeling
 	newStore func(storecontext.DeviceBackend) snapstate.StoreService
 
 	bootOkRan            bool
 	bootRevisionsUpdated bool
 
+	bootHealthChangeID string
+	bootHealthStarted  time.Time
+	bootHealthRetryAt  time.Time
+	bootHealthFailed   bool
+
+	bootHistoryRecorded bool
+
+	recoveryRemoteAccessRan bool
+
 	ensureSeedInConfigRan bool
 
 	ensureInstalledRan bool
 
+	ensureFactoryResetRan bool
+
 	cloudInitAlreadyRestricted           bool
 	cloudInitErrorAttemptStart           *time.Time
 	cloudInitEnabledInactiveAttemptStart *time.Time
@@ -133,6 +155,8 @@ func Manager(s *state.State, hookManager *hookstate.HookManager, runner *state.T
 	runner.AddHandler("mark-preseeded", m.doMarkPreseeded, nil)
 	runner.AddHandler("mark-seeded", m.doMarkSeeded, nil)
 	runner.AddHandler("setup-run-system", m.doSetupRunSystem, nil)
+	runner.AddHandler("factory-reset-run-system", m.doFactoryResetRunSystem, nil)
+	runner.AddHandler("update-kernel-command-line", m.doUpdateKernelCommandLine, nil)
 	runner.AddHandler("prepare-remodeling", m.doPrepareRemodeling, nil)
 	runner.AddCleanup("prepare-remodeling", m.cleanupRemodel)
 	// this *must* always run last and finalizes a remodel
@@ -146,6 +170,11 @@ func Manager(s *state.State, hookManager *hookstate.HookManager, runner *state.T
 	// unless a new gadget update is deployed.
 	runner.AddHandler("update-gadget-assets", m.doUpdateGadgetAssets, nil)
 
+	runner.AddHandler("create-recovery-system", m.doCreateRecoverySystem, m.undoCreateRecoverySystem)
+	runner.AddHandler("rotate-recovery-keys", m.doRotateRecoveryKeys, nil)
+	runner.AddHandler("add-passphrase", m.doAddPassphrase, nil)
+	runner.AddHandler("remove-passphrase", m.doRemovePassphrase, nil)
+
 	runner.AddBlocked(gadgetUpdateBlocked)
 
 	// wire FDE kernel hook support into boot
@@ -314,6 +343,73 @@ func ensureOperationalAttempts(st *state.State) int {
 	return cur
 }
 
+// restoreSerialFromSave restores the device identity from the serial
+// assertion kept in the save assertion database, provided the matching
+// device key is also available there. This is the case after a factory
+// reset that preserved the content of ubuntu-save.
+func (m *DeviceManager) restoreSerialFromSave(device *auth.DeviceState) (restored bool, err error) {
+	if !m.saveAvailable || device.KeyID != "" {
+		return false, nil
+	}
+	var serial *asserts.Serial
+	err = m.withSaveAssertDB(func(savedb *asserts.Database) error {
+		serials, err := savedb.FindMany(asserts.SerialType, map[string]string{
+			"brand-id": device.Brand,
+			"model":    device.Model,
+		})
+		if asserts.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = m.withKeypairMgr(func(keypairMgr asserts.KeypairManager) error {
+			for _, a := range serials {
+				candidate := a.(*asserts.Serial)
+				if _, err := keypairMgr.Get(candidate.DeviceKey().ID()); err == nil {
+					serial = candidate
+					return nil
+				}
+			}
+			return nil
+		})
+		if err != nil || serial == nil {
+			return err
+		}
+		// import the serial and its prerequisites into the system
+		// assertion database
+		retrieve := func(ref *asserts.Ref) (asserts.Assertion, error) {
+			return ref.Resolve(savedb.Find)
+		}
+		b := asserts.NewBatch(nil)
+		err = b.Fetch(assertstate.DB(m.state), retrieve, func(f asserts.Fetcher) error {
+			return f.Save(serial)
+		})
+		if err != nil {
+			return err
+		}
+		return assertstate.AddBatch(m.state, b, nil)
+	})
+	if err == errNoSaveSupport {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot restore serial from device save assertion database: %v", err)
+	}
+	if serial == nil {
+		return false, nil
+	}
+
+	logger.Noticef("restored device serial %q from ubuntu-save", serial.Serial())
+	device.KeyID = serial.DeviceKey().ID()
+	device.Serial = serial.Serial()
+	if err := m.setDevice(device); err != nil {
+		return false, err
+	}
+	m.markRegistered()
+	return true, nil
+}
+
 // ensureOperationalShouldBackoff returns whether we should abstain from
 // further become-operational tentatives while its backoff interval is
 // not expired.
@@ -411,6 +507,18 @@ func (m *DeviceManager) ensureOperational() error {
 		return nil
 	}
 
+	if seeded {
+		// the device identity might have been preserved across a
+		// factory reset
+		restored, err := m.restoreSerialFromSave(device)
+		if err != nil {
+			return err
+		}
+		if restored {
+			return nil
+		}
+	}
+
 	var storeID, gadget string
 	model, err := m.Model()
 	if err != nil && err != state.ErrNoState {
@@ -597,6 +705,36 @@ func (m *DeviceManager) ensureSeeded() error {
 func (m *DeviceManager) ResetBootOk() {
 	m.bootOkRan = false
 	m.bootRevisionsUpdated = false
+	m.bootHealthChangeID = ""
+	m.bootHealthFailed = false
+}
+
+var bootRecordBoot = boot.RecordBoot
+
+// ensureBootHistory records the current boot in the boot history, once the
+// model is known.
+func (m *DeviceManager) ensureBootHistory() error {
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	if release.OnClassic || m.bootHistoryRecorded {
+		return nil
+	}
+
+	deviceCtx, err := DeviceCtx(m.state, nil, nil)
+	if err == state.ErrNoState {
+		// try again once seeded
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := bootRecordBoot(deviceCtx, m.SystemMode()); err != nil {
+		// the boot history is informational only, do not retry
+		logger.Noticef("cannot record boot in boot history: %v", err)
+	}
+	m.bootHistoryRecorded = true
+	return nil
 }
 
 func (m *DeviceManager) ensureBootOk() error {
@@ -618,6 +756,15 @@ func (m *DeviceManager) ensureBootOk() error {
 			return err
 		}
 		if err == nil {
+			// snaps with a check-boot-health hook need to be healthy
+			// after trying a new kernel or base
+			ok, err := m.checkBootHealth(deviceCtx)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
 			if err := boot.MarkBootSuccessful(deviceCtx); err != nil {
 				return err
 			}
@@ -635,6 +782,97 @@ func (m *DeviceManager) ensureBootOk() error {
 	return nil
 }
 
+// ensureKernelCommandLine makes sure that the extra kernel command line
+// arguments set in the system options are in use, by trying them on the next
+// boot. When the arguments were already tried but are not in use, booting
+// with them failed and the option is reverted to the arguments in use.
+func (m *DeviceManager) ensureKernelCommandLine() error {
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	if release.OnClassic {
+		return nil
+	}
+
+	// the outcome of trying new arguments is only known once the boot
+	// has been marked as successful
+	if m.SystemMode() != "run" || !m.bootOkRan {
+		return nil
+	}
+
+	var seeded bool
+	err := m.state.Get("seeded", &seeded)
+	if err != nil && err != state.ErrNoState {
+		return err
+	}
+	if !seeded {
+		return nil
+	}
+
+	deviceCtx, err := DeviceCtx(m.state, nil, nil)
+	if err == state.ErrNoState {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !deviceCtx.HasModeenv() {
+		return nil
+	}
+
+	if m.changeInFlight("update-kernel-command-line") {
+		return nil
+	}
+
+	wanted, err := wantedKernelCmdlineAppend(m.state)
+	if err != nil {
+		return err
+	}
+	current, pending, err := bootCommandLineAppend(deviceCtx)
+	if err != nil {
+		return err
+	}
+	if pending {
+		// wait for the reboot
+		return nil
+	}
+
+	var tried string
+	err = m.state.Get(kernelCmdlineAppendTriedKey, &tried)
+	if err != nil && err != state.ErrNoState {
+		return err
+	}
+	triedBefore := err == nil
+
+	if wanted == current {
+		if triedBefore {
+			m.state.Set(kernelCmdlineAppendTriedKey, nil)
+		}
+		return nil
+	}
+	if triedBefore && tried == wanted {
+		// the arguments were tried already, but booting with them
+		// or setting them up failed
+		m.state.Set(kernelCmdlineAppendTriedKey, nil)
+		tr := config.NewTransaction(m.state)
+		if err := tr.Set("core", kernelCmdlineAppendOpt, current); err != nil {
+			return err
+		}
+		tr.Commit()
+		m.state.Warnf("cannot use kernel command line arguments %q, reverted to %q", wanted, current)
+		return nil
+	}
+
+	updateCmdline := m.state.NewTask("update-kernel-command-line", fmt.Sprintf(i18n.G("Update kernel command line arguments to %q"), wanted))
+	updateCmdline.Set("cmdline-append", wanted)
+
+	chg := m.state.NewChange("update-kernel-command-line", i18n.G("Update kernel command line"))
+	chg.AddAll(state.NewTaskSet(updateCmdline))
+	m.state.EnsureBefore(0)
+
+	return nil
+}
+
 func (m *DeviceManager) ensureCloudInitRestricted() error {
 	m.state.Lock()
 	defer m.state.Unlock()
@@ -832,6 +1070,45 @@ func (m *DeviceManager) ensureInstalled() error {
 	return nil
 }
 
+func (m *DeviceManager) ensureFactoryReset() error {
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	if release.OnClassic {
+		return nil
+	}
+
+	if m.ensureFactoryResetRan {
+		return nil
+	}
+
+	if m.SystemMode() != "factory-reset" {
+		return nil
+	}
+
+	var seeded bool
+	err := m.state.Get("seeded", &seeded)
+	if err != nil && err != state.ErrNoState {
+		return err
+	}
+	if !seeded {
+		return nil
+	}
+
+	if m.changeInFlight("factory-reset") {
+		return nil
+	}
+
+	m.ensureFactoryResetRan = true
+
+	factoryResetRunSystem := m.state.NewTask("factory-reset-run-system", i18n.G("Perform factory reset of the system"))
+
+	chg := m.state.NewChange("factory-reset", i18n.G("Perform factory reset"))
+	chg.AddAll(state.NewTaskSet(factoryResetRunSystem))
+
+	return nil
+}
+
 var timeNow = time.Now
 
 // StartOfOperationTime returns the time when snapd started operating,
@@ -881,6 +1158,18 @@ func markSeededInConfig(st *state.State) error {
 	return nil
 }
 
+// ensureStoreTLSParams resolves again the TLS parameters of the store
+// contexts, so that changes to the store TLS configuration, a serial
+// that was just acquired and the renewal of the device client
+// certificate are picked up without TLS handshakes needing the state
+// lock.
+func (m *DeviceManager) ensureStoreTLSParams() {
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	storecontext.UpdateTLSParams(m.state)
+}
+
 func (m *DeviceManager) ensureSeedInConfig() error {
 	m.state.Lock()
 	defer m.state.Unlock()
@@ -949,10 +1238,20 @@ func (m *DeviceManager) Ensure() error {
 			errs = append(errs, err)
 		}
 
+		m.ensureStoreTLSParams()
+
+		if err := m.ensureBootHistory(); err != nil {
+			errs = append(errs, err)
+		}
+
 		if err := m.ensureBootOk(); err != nil {
 			errs = append(errs, err)
 		}
 
+		if err := m.ensureKernelCommandLine(); err != nil {
+			errs = append(errs, err)
+		}
+
 		if err := m.ensureSeedInConfig(); err != nil {
 			errs = append(errs, err)
 		}
@@ -960,6 +1259,14 @@ func (m *DeviceManager) Ensure() error {
 		if err := m.ensureInstalled(); err != nil {
 			errs = append(errs, err)
 		}
+
+		if err := m.ensureFactoryReset(); err != nil {
+			errs = append(errs, err)
+		}
+
+		if err := m.ensureRecoveryRemoteAccess(); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	if len(errs) > 0 {
@@ -1130,10 +1437,12 @@ var defaultSystemActions = []SystemAction{
 var currentSystemActions = []SystemAction{
 	{Title: "Reinstall", Mode: "install"},
 	{Title: "Recover", Mode: "recover"},
+	{Title: "Factory reset", Mode: "factory-reset"},
 	{Title: "Run normally", Mode: "run"},
 }
 var recoverSystemActions = []SystemAction{
 	{Title: "Reinstall", Mode: "install"},
+	{Title: "Factory reset", Mode: "factory-reset"},
 	{Title: "Run normally", Mode: "run"},
 }
 
@@ -1286,8 +1595,9 @@ func (m *DeviceManager) switchToSystemAndMode(systemLabel, mode string, sameSyst
 			sameSystemAndMode()
 			return nil
 		}
-	case "install":
-		// requesting system actions in install mode does not make sense atm
+	case "install", "factory-reset":
+		// requesting system actions in install or factory-reset mode does
+		// not make sense atm
 		//
 		// TODO:UC20: maybe factory hooks will be able to something like
 		// this?
@@ -1361,6 +1671,90 @@ func (scb storeContextBackend) SignDeviceSessionRequest(serial *asserts.Serial,
 	return a.(*asserts.DeviceSessionRequest), nil
 }
 
+const (
+	deviceClientCertValidity = 24 * time.Hour
+	// a new certificate is produced once the cached one gets this close
+	// to its expiry
+	deviceClientCertRenewBefore = time.Hour
+)
+
+// DeviceKeyCertificate produces a short-lived self-signed TLS client
+// certificate for the device key, identifying the device by the given
+// serial assertion. The certificate is reused until close to its expiry.
+func (scb storeContextBackend) DeviceKeyCertificate(serial *asserts.Serial) (*tls.Certificate, error) {
+	if serial == nil {
+		// shouldn't happen, but be safe
+		return nil, fmt.Errorf("internal error: cannot produce a client certificate without a serial")
+	}
+
+	m := scb.DeviceManager
+	if cert := m.cachedClientCert; cert != nil && sameAssertion(m.cachedClientCertSerial, serial) &&
+		timeNow().Before(cert.Leaf.NotAfter.Add(-deviceClientCertRenewBefore)) {
+		return cert, nil
+	}
+
+	cert, err := m.newDeviceKeyCertificate(serial)
+	if err != nil {
+		return nil, err
+	}
+	m.cachedClientCert = cert
+	m.cachedClientCertSerial = serial
+	return cert, nil
+}
+
+func sameAssertion(a, b asserts.Assertion) bool {
+	_, sigA := a.Signature()
+	_, sigB := b.Signature()
+	return bytes.Equal(sigA, sigB)
+}
+
+func (m *DeviceManager) newDeviceKeyCertificate(serial *asserts.Serial) (*tls.Certificate, error) {
+	privKey, err := m.keyPair()
+	if err == state.ErrNoState {
+		return nil, fmt.Errorf("internal error: inconsistent state with serial but no device key")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := asserts.CryptoSigner(privKey)
+	if err != nil {
+		return nil, err
+	}
+
+	certSerial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := timeNow()
+	tmpl := &x509.Certificate{
+		SerialNumber: certSerial,
+		Subject: pkix.Name{
+			CommonName:         serial.Serial(),
+			Organization:       []string{serial.BrandID()},
+			OrganizationalUnit: []string{serial.Model()},
+		},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(deviceClientCertValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, signer.Public(), signer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create device client certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse device client certificate: %v", err)
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  signer,
+		Leaf:        leaf,
+	}, nil
+}
+
 func (m *DeviceManager) StoreContextBackend() storecontext.Backend {
 	return storeContextBackend{m}
 }
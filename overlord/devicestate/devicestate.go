@@ -24,13 +24,16 @@ package devicestate
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
 
 	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/gadget"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/netutil"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/configstate/config"
@@ -39,6 +42,7 @@ import (
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/release"
+	"github.com/snapcore/snapd/seed"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/naming"
 )
@@ -637,3 +641,52 @@ func Remodeling(st *state.State) bool {
 	}
 	return false
 }
+
+// CreateRecoverySystem creates a change to create a new recovery system with
+// the given label, out of the currently installed snaps of the device model
+// and their assertions. The new system is added to the current recovery
+// systems of the device.
+func CreateRecoverySystem(st *state.State, label string) (*state.Change, error) {
+	if err := seed.ValidateUC20SeedSystemLabel(label); err != nil {
+		return nil, err
+	}
+	deviceCtx, err := DeviceCtx(st, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	model := deviceCtx.Model()
+	if model.Grade() == asserts.ModelGradeUnset {
+		return nil, fmt.Errorf("cannot create recovery systems on a non Ubuntu Core 20 device")
+	}
+	if !deviceCtx.RunMode() {
+		return nil, fmt.Errorf("cannot create recovery systems outside of run mode")
+	}
+	systemDir := filepath.Join(boot.InitramfsUbuntuSeedDir, "systems", label)
+	if osutil.FileExists(systemDir) {
+		return nil, fmt.Errorf("recovery system %q already exists", label)
+	}
+
+	for _, chg := range st.Changes() {
+		if !chg.IsReady() && chg.Kind() == "create-recovery-system" {
+			return nil, &snapstate.ChangeConflictError{
+				ChangeKind: "create-recovery-system",
+				Message:    "cannot create a recovery system, another one is being created",
+			}
+		}
+	}
+	snaps := modelSnapNames(model)
+	if err := snapstate.CheckChangeConflictMany(st, snaps, ""); err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf(i18n.G("Create recovery system with label %q"), label)
+	chg := st.NewChange("create-recovery-system", summary)
+	create := st.NewTask("create-recovery-system", summary)
+	create.Set("recovery-system-setup", &recoverySystemSetup{
+		Label:     label,
+		Directory: systemDir,
+		Snaps:     snaps,
+	})
+	chg.AddTask(create)
+	return chg, nil
+}
@@ -0,0 +1,378 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate_test
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/healthstate"
+	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+type deviceMgrBootHealthSuite struct {
+	deviceMgrBaseSuite
+
+	now time.Time
+}
+
+var _ = Suite(&deviceMgrBootHealthSuite{})
+
+func (s *deviceMgrBootHealthSuite) SetUpTest(c *C) {
+	s.deviceMgrBaseSuite.SetUpTest(c)
+
+	s.setPCModelInState(c)
+	devicestate.SetSystemMode(s.mgr, "run")
+
+	s.now = time.Now()
+	s.AddCleanup(devicestate.MockTimeNow(func() time.Time { return s.now }))
+	s.AddCleanup(devicestate.MockBootHealthTimings(5*time.Minute, 30*time.Second))
+
+	// trying core_2
+	s.bootloader.SetBootVars(map[string]string{
+		"snap_mode":     boot.TryingStatus,
+		"snap_core":     "core_1.snap",
+		"snap_try_core": "core_2.snap",
+		"snap_kernel":   "pc-kernel_1.snap",
+	})
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	siCore1 := &snap.SideInfo{RealName: "core", Revision: snap.R(1)}
+	siCore2 := &snap.SideInfo{RealName: "core", Revision: snap.R(2)}
+	snapstate.Set(s.state, "core", &snapstate.SnapState{
+		SnapType: "os",
+		Active:   true,
+		Sequence: []*snap.SideInfo{siCore1, siCore2},
+		Current:  siCore2.Revision,
+	})
+}
+
+func (s *deviceMgrBootHealthSuite) mockSnap(c *C, name, yaml string) {
+	si := &snap.SideInfo{RealName: name, Revision: snap.R(7)}
+	snaptest.MockSnap(c, yaml, si)
+	snapstate.Set(s.state, name, &snapstate.SnapState{
+		SnapType: "app",
+		Active:   true,
+		Sequence: []*snap.SideInfo{si},
+		Current:  si.Revision,
+	})
+}
+
+func (s *deviceMgrBootHealthSuite) mockBootHealthSnap(c *C, name string) {
+	s.mockSnap(c, name, "name: "+name+"\nversion: 1.0\nhooks:\n  check-boot-health:\n")
+}
+
+func (s *deviceMgrBootHealthSuite) ensureBootOk(c *C) {
+	s.state.Unlock()
+	defer s.state.Lock()
+	c.Assert(devicestate.EnsureBootOk(s.mgr), IsNil)
+}
+
+func (s *deviceMgrBootHealthSuite) bootMarked(c *C) bool {
+	m, err := s.bootloader.GetBootVars("snap_mode", "snap_core")
+	c.Assert(err, IsNil)
+	if m["snap_mode"] == boot.TryingStatus {
+		return false
+	}
+	c.Check(m["snap_core"], Equals, "core_2.snap")
+	return true
+}
+
+func (s *deviceMgrBootHealthSuite) bootHealthChanges() []*state.Change {
+	var chgs []*state.Change
+	for _, chg := range s.state.Changes() {
+		if chg.Kind() == "check-boot-health" {
+			chgs = append(chgs, chg)
+		}
+	}
+	sort.Slice(chgs, func(i, j int) bool {
+		idi, _ := strconv.Atoi(chgs[i].ID())
+		idj, _ := strconv.Atoi(chgs[j].ID())
+		return idi < idj
+	})
+	return chgs
+}
+
+// finishChange completes the hook tasks of the change and records the
+// given health of their snaps
+func (s *deviceMgrBootHealthSuite) finishChange(c *C, chg *state.Change, status map[string]healthstate.HealthStatus) {
+	hs := map[string]*healthstate.HealthState{}
+	for _, t := range chg.Tasks() {
+		var hooksup hookstate.HookSetup
+		c.Assert(t.Get("hook-setup", &hooksup), IsNil)
+		t.SetStatus(state.DoneStatus)
+		if st, ok := status[hooksup.Snap]; ok {
+			hs[hooksup.Snap] = &healthstate.HealthState{
+				Revision:  hooksup.Revision,
+				Timestamp: time.Now(),
+				Status:    st,
+			}
+		}
+	}
+	s.state.Set("health", hs)
+}
+
+func (s *deviceMgrBootHealthSuite) TestNoBootHealthSnaps(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.mockSnap(c, "other-app", "name: other-app\nversion: 1.0\n")
+
+	s.ensureBootOk(c)
+
+	c.Check(s.bootHealthChanges(), HasLen, 0)
+	c.Check(s.bootMarked(c), Equals, true)
+}
+
+func (s *deviceMgrBootHealthSuite) TestNotTrying(c *C) {
+	s.bootloader.SetBootVars(map[string]string{
+		"snap_mode":     "",
+		"snap_core":     "core_2.snap",
+		"snap_try_core": "",
+		"snap_kernel":   "pc-kernel_1.snap",
+	})
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.mockBootHealthSnap(c, "critical-app")
+
+	s.ensureBootOk(c)
+
+	c.Check(s.bootHealthChanges(), HasLen, 0)
+	c.Check(s.bootMarked(c), Equals, true)
+}
+
+func (s *deviceMgrBootHealthSuite) TestTryStatusUnreadable(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	s.bootloader.SetBootVars(map[string]string{
+		"snap_mode":     "",
+		"snap_core":     "core_2.snap",
+		"snap_try_core": "",
+		"snap_kernel":   "",
+	})
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.mockBootHealthSnap(c, "critical-app")
+
+	s.ensureBootOk(c)
+
+	// the boot health is not checked, which does not prevent
+	// marking the boot as successful
+	c.Check(s.bootHealthChanges(), HasLen, 0)
+	c.Check(s.bootMarked(c), Equals, true)
+	c.Check(logbuf.String(), Matches, `(?s).*cannot check for a try boot, not checking boot health: cannot get name and revision of kernel \(snap_kernel\): boot variable unset\n`)
+}
+
+func (s *deviceMgrBootHealthSuite) TestHealthy(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.mockBootHealthSnap(c, "critical-app")
+	s.mockBootHealthSnap(c, "other-critical-app")
+	s.mockSnap(c, "other-app", "name: other-app\nversion: 1.0\n")
+
+	s.ensureBootOk(c)
+
+	chgs := s.bootHealthChanges()
+	c.Assert(chgs, HasLen, 1)
+	chg := chgs[0]
+	c.Check(chg.Summary(), Equals, "Check boot health of snaps")
+	tasks := chg.Tasks()
+	c.Assert(tasks, HasLen, 2)
+	c.Check(tasks[0].Summary(), Equals, `Run boot health check of "critical-app" snap`)
+	c.Check(tasks[1].Summary(), Equals, `Run boot health check of "other-critical-app" snap`)
+	var hooksup hookstate.HookSetup
+	c.Assert(tasks[0].Get("hook-setup", &hooksup), IsNil)
+	c.Check(hooksup.Hook, Equals, "check-boot-health")
+	c.Check(hooksup.Revision, Equals, snap.R(7))
+
+	// not marked while the hooks run
+	c.Check(s.bootMarked(c), Equals, false)
+	s.ensureBootOk(c)
+	c.Check(s.bootMarked(c), Equals, false)
+
+	// no report is fine too
+	s.finishChange(c, chg, map[string]healthstate.HealthStatus{
+		"critical-app": healthstate.OkayStatus,
+	})
+	s.ensureBootOk(c)
+
+	c.Check(s.bootMarked(c), Equals, true)
+	c.Check(s.bootHealthChanges(), HasLen, 1)
+	c.Check(s.restartRequests, HasLen, 0)
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+}
+
+func (s *deviceMgrBootHealthSuite) testUnhealthy(c *C, status healthstate.HealthStatus) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.mockBootHealthSnap(c, "critical-app")
+	s.mockBootHealthSnap(c, "other-critical-app")
+
+	s.ensureBootOk(c)
+	chgs := s.bootHealthChanges()
+	c.Assert(chgs, HasLen, 1)
+
+	s.finishChange(c, chgs[0], map[string]healthstate.HealthStatus{
+		"critical-app":       healthstate.OkayStatus,
+		"other-critical-app": status,
+	})
+	s.ensureBootOk(c)
+
+	c.Check(s.bootMarked(c), Equals, false)
+	c.Check(s.restartRequests, DeepEquals, []state.RestartType{state.RestartSystem})
+	warns := s.state.AllWarnings()
+	c.Assert(warns, HasLen, 1)
+	c.Check(warns[0].String(), Equals, `snaps "other-critical-app" are not healthy after booting a new kernel or base, rebooting to revert to the previous ones`)
+
+	// nothing happens anymore until the reboot
+	s.ensureBootOk(c)
+	c.Check(s.bootMarked(c), Equals, false)
+	c.Check(s.restartRequests, HasLen, 1)
+	c.Check(s.bootHealthChanges(), HasLen, 1)
+}
+
+func (s *deviceMgrBootHealthSuite) TestUnhealthyError(c *C) {
+	s.testUnhealthy(c, healthstate.ErrorStatus)
+}
+
+func (s *deviceMgrBootHealthSuite) TestUnhealthyBlocked(c *C) {
+	s.testUnhealthy(c, healthstate.BlockedStatus)
+}
+
+func (s *deviceMgrBootHealthSuite) TestHookFailed(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.mockBootHealthSnap(c, "critical-app")
+
+	s.ensureBootOk(c)
+	chgs := s.bootHealthChanges()
+	c.Assert(chgs, HasLen, 1)
+	chgs[0].Tasks()[0].SetStatus(state.ErrorStatus)
+
+	s.ensureBootOk(c)
+
+	c.Check(s.bootMarked(c), Equals, false)
+	c.Check(s.restartRequests, DeepEquals, []state.RestartType{state.RestartSystem})
+}
+
+func (s *deviceMgrBootHealthSuite) TestStaleHealthIgnored(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.mockBootHealthSnap(c, "critical-app")
+	// reported before the boot
+	s.state.Set("health", map[string]*healthstate.HealthState{
+		"critical-app": {
+			Revision:  snap.R(7),
+			Timestamp: time.Now().Add(-time.Hour),
+			Status:    healthstate.ErrorStatus,
+		},
+	})
+
+	s.ensureBootOk(c)
+	chgs := s.bootHealthChanges()
+	c.Assert(chgs, HasLen, 1)
+	chgs[0].Tasks()[0].SetStatus(state.DoneStatus)
+
+	s.ensureBootOk(c)
+
+	c.Check(s.bootMarked(c), Equals, true)
+	c.Check(s.restartRequests, HasLen, 0)
+}
+
+func (s *deviceMgrBootHealthSuite) TestWaitingThenHealthy(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.mockBootHealthSnap(c, "critical-app")
+	s.mockBootHealthSnap(c, "other-critical-app")
+
+	s.ensureBootOk(c)
+	chgs := s.bootHealthChanges()
+	c.Assert(chgs, HasLen, 1)
+	s.finishChange(c, chgs[0], map[string]healthstate.HealthStatus{
+		"critical-app":       healthstate.OkayStatus,
+		"other-critical-app": healthstate.WaitingStatus,
+	})
+
+	// the check is not retried right away
+	s.ensureBootOk(c)
+	c.Check(s.bootHealthChanges(), HasLen, 1)
+	s.now = s.now.Add(10 * time.Second)
+	s.ensureBootOk(c)
+	c.Check(s.bootHealthChanges(), HasLen, 1)
+
+	// but after the retry interval, only for the waiting snap
+	s.now = s.now.Add(30 * time.Second)
+	s.ensureBootOk(c)
+	chgs = s.bootHealthChanges()
+	c.Assert(chgs, HasLen, 2)
+	tasks := chgs[1].Tasks()
+	c.Assert(tasks, HasLen, 1)
+	c.Check(tasks[0].Summary(), Equals, `Run boot health check of "other-critical-app" snap`)
+	c.Check(s.bootMarked(c), Equals, false)
+
+	s.finishChange(c, chgs[1], map[string]healthstate.HealthStatus{
+		"other-critical-app": healthstate.OkayStatus,
+	})
+	s.ensureBootOk(c)
+
+	c.Check(s.bootMarked(c), Equals, true)
+	c.Check(s.restartRequests, HasLen, 0)
+}
+
+func (s *deviceMgrBootHealthSuite) TestWaitingGracePeriodExpires(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.mockBootHealthSnap(c, "critical-app")
+
+	s.ensureBootOk(c)
+	for i := 0; i < 30; i++ {
+		chgs := s.bootHealthChanges()
+		chg := chgs[len(chgs)-1]
+		if chg.Status().Ready() {
+			// retry pending
+			s.now = s.now.Add(30 * time.Second)
+		} else {
+			s.finishChange(c, chg, map[string]healthstate.HealthStatus{
+				"critical-app": healthstate.WaitingStatus,
+			})
+		}
+		s.ensureBootOk(c)
+		if len(s.restartRequests) > 0 {
+			break
+		}
+	}
+
+	c.Check(s.bootMarked(c), Equals, false)
+	c.Check(s.restartRequests, DeepEquals, []state.RestartType{state.RestartSystem})
+	c.Check(s.bootHealthChanges(), HasLen, 10)
+}
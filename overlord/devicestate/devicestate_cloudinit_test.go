@@ -11,6 +11,7 @@ import (
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/auth"
@@ -39,6 +40,9 @@ func (s *cloudInitBaseSuite) SetUpTest(c *C) {
 	// actually want the default function used to be the real one
 	s.restoreCloudInitStatusRestore()
 
+	// the boot history is not relevant for the checks of the logs here
+	s.AddCleanup(devicestate.MockBootRecordBoot(func(boot.Device, string) error { return nil }))
+
 	r := release.MockOnClassic(false)
 	defer r()
 
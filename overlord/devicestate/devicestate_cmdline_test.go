@@ -0,0 +1,254 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate_test
+
+import (
+	"fmt"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/devicestate/devicestatetest"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+type deviceMgrKernelCmdlineSuite struct {
+	deviceMgrBaseSuite
+
+	currentArgs string
+	pending     bool
+
+	setTryCalls []string
+	setTryErr   error
+}
+
+var _ = Suite(&deviceMgrKernelCmdlineSuite{})
+
+func (s *deviceMgrKernelCmdlineSuite) SetUpTest(c *C) {
+	s.deviceMgrBaseSuite.SetUpTest(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.makeModelAssertionInState(c, "canonical", "pc-20", map[string]interface{}{
+		"architecture": "amd64",
+		// UC20
+		"grade": "dangerous",
+		"base":  "core20",
+		"snaps": []interface{}{
+			map[string]interface{}{
+				"name":            "pc-kernel",
+				"id":              snaptest.AssertedSnapID("pc-kernel"),
+				"type":            "kernel",
+				"default-channel": "20",
+			},
+			map[string]interface{}{
+				"name":            "pc",
+				"id":              snaptest.AssertedSnapID("pc"),
+				"type":            "gadget",
+				"default-channel": "20",
+			},
+		},
+	})
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand:  "canonical",
+		Model:  "pc-20",
+		Serial: "serialserialserial",
+	})
+	s.state.Set("seeded", true)
+	devicestate.SetSystemMode(s.mgr, "run")
+	devicestate.SetBootOkRan(s.mgr, true)
+
+	s.currentArgs = ""
+	s.pending = false
+	s.setTryCalls = nil
+	s.setTryErr = nil
+	s.AddCleanup(devicestate.MockBootCommandLineAppend(func(dev boot.Device) (string, bool, error) {
+		c.Check(dev.HasModeenv(), Equals, true)
+		return s.currentArgs, s.pending, nil
+	}))
+	s.AddCleanup(devicestate.MockBootSetTryCommandLineAppend(func(dev boot.Device, args string) (bool, error) {
+		c.Check(dev.HasModeenv(), Equals, true)
+		s.setTryCalls = append(s.setTryCalls, args)
+		if s.setTryErr != nil {
+			return false, s.setTryErr
+		}
+		s.pending = true
+		return true, nil
+	}))
+}
+
+func (s *deviceMgrKernelCmdlineSuite) setCmdlineAppend(c *C, cmdline string) {
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "system.kernel.cmdline-append", cmdline), IsNil)
+	tr.Commit()
+}
+
+func (s *deviceMgrKernelCmdlineSuite) cmdlineAppend(c *C) string {
+	var cmdline string
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.GetMaybe("core", "system.kernel.cmdline-append", &cmdline), IsNil)
+	return cmdline
+}
+
+// ensureKernelCommandLine runs the ensure step with the state lock held by
+// the test released, as the step takes the lock itself.
+func (s *deviceMgrKernelCmdlineSuite) ensureKernelCommandLine(c *C) {
+	s.state.Unlock()
+	defer s.state.Lock()
+	c.Assert(devicestate.EnsureKernelCommandLine(s.mgr), IsNil)
+}
+
+func (s *deviceMgrKernelCmdlineSuite) findChange(kind string) *state.Change {
+	for _, chg := range s.state.Changes() {
+		if chg.Kind() == kind {
+			return chg
+		}
+	}
+	return nil
+}
+
+func (s *deviceMgrKernelCmdlineSuite) TestEnsureKernelCommandLineTriesNewArgs(c *C) {
+	s.state.Lock()
+	s.setCmdlineAppend(c, "isolcpus=1   nosmt")
+	s.ensureKernelCommandLine(c)
+
+	chg := s.findChange("update-kernel-command-line")
+	c.Assert(chg, NotNil)
+	tsks := chg.Tasks()
+	c.Assert(tsks, HasLen, 1)
+	c.Check(tsks[0].Kind(), Equals, "update-kernel-command-line")
+	c.Check(tsks[0].Summary(), Equals, `Update kernel command line arguments to "isolcpus=1 nosmt"`)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Err(), IsNil)
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+	c.Check(s.setTryCalls, DeepEquals, []string{"isolcpus=1 nosmt"})
+	c.Check(s.restartRequests, DeepEquals, []state.RestartType{state.RestartSystem})
+	var tried string
+	c.Assert(s.state.Get("kernel-cmdline-append-tried", &tried), IsNil)
+	c.Check(tried, Equals, "isolcpus=1 nosmt")
+
+	// nothing happens while the change is pending a reboot
+	s.ensureKernelCommandLine(c)
+	c.Check(s.state.Changes(), HasLen, 1)
+}
+
+func (s *deviceMgrKernelCmdlineSuite) TestEnsureKernelCommandLineCommitted(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setCmdlineAppend(c, "isolcpus=1")
+	s.state.Set("kernel-cmdline-append-tried", "isolcpus=1")
+	// the boot with new arguments was successful
+	s.currentArgs = "isolcpus=1"
+
+	s.ensureKernelCommandLine(c)
+	c.Check(s.state.Changes(), HasLen, 0)
+	var tried string
+	c.Check(s.state.Get("kernel-cmdline-append-tried", &tried), Equals, state.ErrNoState)
+	c.Check(s.cmdlineAppend(c), Equals, "isolcpus=1")
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+}
+
+func (s *deviceMgrKernelCmdlineSuite) TestEnsureKernelCommandLineRolledBack(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setCmdlineAppend(c, "isolcpus=2")
+	s.state.Set("kernel-cmdline-append-tried", "isolcpus=2")
+	// the boot with new arguments failed
+	s.currentArgs = "isolcpus=1"
+
+	s.ensureKernelCommandLine(c)
+	c.Check(s.state.Changes(), HasLen, 0)
+	c.Check(s.setTryCalls, HasLen, 0)
+	var tried string
+	c.Check(s.state.Get("kernel-cmdline-append-tried", &tried), Equals, state.ErrNoState)
+	// the option was reverted
+	c.Check(s.cmdlineAppend(c), Equals, "isolcpus=1")
+	warns := s.state.AllWarnings()
+	c.Assert(warns, HasLen, 1)
+	c.Check(warns[0].String(), Equals, `cannot use kernel command line arguments "isolcpus=2", reverted to "isolcpus=1"`)
+
+	// and nothing else happens
+	s.ensureKernelCommandLine(c)
+	c.Check(s.state.Changes(), HasLen, 0)
+}
+
+func (s *deviceMgrKernelCmdlineSuite) TestEnsureKernelCommandLineSetupError(c *C) {
+	s.setTryErr = fmt.Errorf("boom")
+
+	s.state.Lock()
+	s.setCmdlineAppend(c, "nosmt")
+	s.ensureKernelCommandLine(c)
+	chg := s.findChange("update-kernel-command-line")
+	c.Assert(chg, NotNil)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(chg.Status(), Equals, state.ErrorStatus)
+	c.Check(chg.Err(), ErrorMatches, `(?s).*cannot update the kernel command line: boom.*`)
+	c.Check(s.restartRequests, HasLen, 0)
+	// the option got reverted by the ensure loop
+	c.Check(s.cmdlineAppend(c), Equals, "")
+	c.Check(s.setTryCalls, DeepEquals, []string{"nosmt"})
+	c.Check(s.state.AllWarnings(), HasLen, 1)
+}
+
+func (s *deviceMgrKernelCmdlineSuite) TestEnsureKernelCommandLineNothingToDo(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.currentArgs = "isolcpus=1"
+	s.setCmdlineAppend(c, "isolcpus=1")
+	s.ensureKernelCommandLine(c)
+	c.Check(s.state.Changes(), HasLen, 0)
+
+	// a change is pending a reboot
+	s.setCmdlineAppend(c, "nosmt")
+	s.pending = true
+	s.ensureKernelCommandLine(c)
+	c.Check(s.state.Changes(), HasLen, 0)
+	s.pending = false
+
+	// boot not marked successful yet
+	devicestate.SetBootOkRan(s.mgr, false)
+	s.ensureKernelCommandLine(c)
+	c.Check(s.state.Changes(), HasLen, 0)
+	devicestate.SetBootOkRan(s.mgr, true)
+
+	// not in run mode
+	devicestate.SetSystemMode(s.mgr, "recover")
+	s.ensureKernelCommandLine(c)
+	c.Check(s.state.Changes(), HasLen, 0)
+
+	c.Check(s.setTryCalls, HasLen, 0)
+}
@@ -0,0 +1,350 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil/disks"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+const (
+	dataNode = "/dev/disk/by-partuuid/data-partuuid"
+	saveNode = "/dev/disk/by-partuuid/save-partuuid"
+)
+
+type deviceMgrFDEKeysSuite struct {
+	deviceMgrBaseSuite
+
+	// keyslots of the fake LUKS2 containers
+	keyslots map[string][]string
+	calls    []string
+
+	failCheck string
+}
+
+var _ = Suite(&deviceMgrFDEKeysSuite{})
+
+func (s *deviceMgrFDEKeysSuite) SetUpTest(c *C) {
+	s.deviceMgrBaseSuite.SetUpTest(c)
+
+	s.state.Lock()
+	s.state.Set("seeded", true)
+	s.state.Unlock()
+	devicestate.SetSystemMode(s.mgr, "run")
+	devicestate.SetBootOkRan(s.mgr, true)
+
+	c.Assert(os.MkdirAll(dirs.SnapFDEDir, 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dirs.SnapFDEDir, "recovery.key"), []byte("data-recovery-00"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dirs.SnapFDEDir, "reinstall.key"), []byte("save-recovery-00"), 0600), IsNil)
+
+	s.keyslots = map[string][]string{
+		dataNode: {"data-sealed-key", "data-recovery-00"},
+		saveNode: {"save-key", "save-recovery-00"},
+	}
+	s.calls = nil
+	s.failCheck = ""
+
+	disk := &disks.MockDiskMapping{
+		FilesystemLabelToPartUUID: map[string]string{
+			"ubuntu-data-enc": "data-partuuid",
+			"ubuntu-save-enc": "save-partuuid",
+		},
+		DiskHasPartitions: true,
+		DevNum:            "fdeDisk",
+	}
+	s.AddCleanup(disks.MockMountPointDisksToPartitionMapping(map[disks.Mountpoint]*disks.MockDiskMapping{
+		{Mountpoint: boot.InitramfsDataDir, IsDecryptedDevice: true}:       disk,
+		{Mountpoint: boot.InitramfsUbuntuSaveDir, IsDecryptedDevice: true}: disk,
+	}))
+
+	s.AddCleanup(devicestate.MockSecbootLUKS2ContainerKeys(s.addKey, s.checkKey, s.removeKey))
+}
+
+func (s *deviceMgrFDEKeysSuite) slot(node string, key []byte) int {
+	for i, k := range s.keyslots[node] {
+		if k == string(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *deviceMgrFDEKeysSuite) addKey(node string, existingKey, newKey []byte) error {
+	s.calls = append(s.calls, fmt.Sprintf("add %s", node))
+	if s.slot(node, existingKey) == -1 {
+		return fmt.Errorf("no key available with this passphrase")
+	}
+	s.keyslots[node] = append(s.keyslots[node], string(newKey))
+	return nil
+}
+
+func (s *deviceMgrFDEKeysSuite) checkKey(node string, key []byte) error {
+	s.calls = append(s.calls, fmt.Sprintf("check %s", node))
+	if s.failCheck == node {
+		return fmt.Errorf("mock check failure")
+	}
+	if s.slot(node, key) == -1 {
+		return fmt.Errorf("no key available with this passphrase")
+	}
+	return nil
+}
+
+func (s *deviceMgrFDEKeysSuite) removeKey(node string, key []byte) error {
+	s.calls = append(s.calls, fmt.Sprintf("remove %s", node))
+	i := s.slot(node, key)
+	if i == -1 {
+		return fmt.Errorf("no key available with this passphrase")
+	}
+	s.keyslots[node] = append(s.keyslots[node][:i], s.keyslots[node][i+1:]...)
+	return nil
+}
+
+// runChange creates a change with the given function and runs it, it returns
+// the error of the change.
+func (s *deviceMgrFDEKeysSuite) runChange(c *C, newChange func(st *state.State) (*state.Change, error)) error {
+	s.state.Lock()
+	chg, err := newChange(s.state)
+	s.state.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.IsReady(), Equals, true)
+	return chg.Err()
+}
+
+func (s *deviceMgrFDEKeysSuite) rotate(c *C) error {
+	return s.runChange(c, devicestate.RotateRecoveryKeys)
+}
+
+func (s *deviceMgrFDEKeysSuite) addPassphrase(c *C, passphrase string) error {
+	return s.runChange(c, func(st *state.State) (*state.Change, error) {
+		return devicestate.AddPassphrase(st, passphrase)
+	})
+}
+
+func (s *deviceMgrFDEKeysSuite) removePassphrase(c *C, passphrase string) error {
+	return s.runChange(c, func(st *state.State) (*state.Change, error) {
+		return devicestate.RemovePassphrase(st, passphrase)
+	})
+}
+
+func (s *deviceMgrFDEKeysSuite) readKey(c *C, name string) string {
+	key, err := ioutil.ReadFile(filepath.Join(dirs.SnapFDEDir, name))
+	c.Assert(err, IsNil)
+	return string(key)
+}
+
+func (s *deviceMgrFDEKeysSuite) TestRotateRecoveryKeys(c *C) {
+	err := s.rotate(c)
+	c.Assert(err, IsNil)
+
+	dataKey := s.readKey(c, "recovery.key")
+	saveKey := s.readKey(c, "reinstall.key")
+	c.Check(dataKey, HasLen, 16)
+	c.Check(dataKey, Not(Equals), "data-recovery-00")
+	c.Check(saveKey, HasLen, 16)
+	c.Check(saveKey, Not(Equals), "save-recovery-00")
+	c.Check(filepath.Join(dirs.SnapFDEDir, "recovery.key.new"), testutil.FileAbsent)
+	c.Check(filepath.Join(dirs.SnapFDEDir, "reinstall.key.new"), testutil.FileAbsent)
+
+	// the old keys are gone, the unlock keys are kept
+	c.Check(s.keyslots, DeepEquals, map[string][]string{
+		dataNode: {"data-sealed-key", dataKey},
+		saveNode: {"save-key", saveKey},
+	})
+	c.Check(s.calls, DeepEquals, []string{
+		"add " + dataNode, "check " + dataNode, "remove " + dataNode,
+		"add " + saveNode, "check " + saveNode, "remove " + saveNode,
+	})
+}
+
+func (s *deviceMgrFDEKeysSuite) TestRotateRecoveryKeysVerifyFails(c *C) {
+	s.failCheck = saveNode
+
+	err := s.rotate(c)
+	c.Assert(err, ErrorMatches, `(?s).*\(cannot verify new recovery key of ubuntu-save: mock check failure\)`)
+
+	// ubuntu-data was rotated
+	dataKey := s.readKey(c, "recovery.key")
+	c.Check(s.keyslots[dataNode], DeepEquals, []string{"data-sealed-key", dataKey})
+	// the old key of ubuntu-save is still there
+	c.Check(s.readKey(c, "reinstall.key"), Equals, "save-recovery-00")
+	newSaveKey := s.readKey(c, "reinstall.key.new")
+	c.Check(s.keyslots[saveNode], DeepEquals, []string{"save-key", "save-recovery-00", newSaveKey})
+
+	// the next attempt drops the leftover key
+	s.failCheck = ""
+	s.calls = nil
+	err = s.rotate(c)
+	c.Assert(err, IsNil)
+	saveKey := s.readKey(c, "reinstall.key")
+	c.Check(saveKey, Not(Equals), newSaveKey)
+	c.Check(s.keyslots[saveNode], DeepEquals, []string{"save-key", saveKey})
+	c.Check(filepath.Join(dirs.SnapFDEDir, "reinstall.key.new"), testutil.FileAbsent)
+}
+
+func (s *deviceMgrFDEKeysSuite) TestRotateRecoveryKeysCompletesInterrupted(c *C) {
+	// the rotation got interrupted after the old key was removed
+	c.Assert(ioutil.WriteFile(filepath.Join(dirs.SnapFDEDir, "recovery.key.new"), []byte("data-recovery-01"), 0600), IsNil)
+	s.keyslots[dataNode] = []string{"data-sealed-key", "data-recovery-01"}
+
+	err := s.rotate(c)
+	c.Assert(err, IsNil)
+
+	dataKey := s.readKey(c, "recovery.key")
+	c.Check(dataKey, Not(Equals), "data-recovery-01")
+	c.Check(s.keyslots[dataNode], DeepEquals, []string{"data-sealed-key", dataKey})
+}
+
+func (s *deviceMgrFDEKeysSuite) TestRotateRecoveryKeysNoEncryption(c *C) {
+	c.Assert(os.RemoveAll(dirs.SnapFDEDir), IsNil)
+
+	err := s.rotate(c)
+	c.Assert(err, Equals, devicestate.ErrNoEncryptedVolumes)
+	c.Check(s.calls, HasLen, 0)
+}
+
+func (s *deviceMgrFDEKeysSuite) TestRotateRecoveryKeysNotRunMode(c *C) {
+	devicestate.SetSystemMode(s.mgr, "recover")
+
+	err := s.rotate(c)
+	c.Assert(err, ErrorMatches, `cannot manage encryption keys in "recover" mode`)
+	c.Check(s.calls, HasLen, 0)
+}
+
+func (s *deviceMgrFDEKeysSuite) TestRotateRecoveryKeysChange(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	chg, err := devicestate.RotateRecoveryKeys(s.state)
+	c.Assert(err, IsNil)
+	c.Check(chg.Kind(), Equals, "rotate-recovery-keys")
+	c.Check(chg.Summary(), Equals, "Rotate recovery keys")
+	tsks := chg.Tasks()
+	c.Assert(tsks, HasLen, 1)
+	c.Check(tsks[0].Kind(), Equals, "rotate-recovery-keys")
+
+	// only one change can manage the keys at a time
+	_, err = devicestate.AddPassphrase(s.state, "open sesame")
+	c.Assert(err, ErrorMatches, "cannot manage encryption keys, another change is managing them")
+	c.Check(err, FitsTypeOf, &snapstate.ChangeConflictError{})
+}
+
+func (s *deviceMgrFDEKeysSuite) passphraseMarkers() []string {
+	return []string{
+		filepath.Join(boot.InitramfsSeedEncryptionKeyDir, "ubuntu-data.passphrase"),
+		filepath.Join(boot.InitramfsSeedEncryptionKeyDir, "ubuntu-save.passphrase"),
+	}
+}
+
+func (s *deviceMgrFDEKeysSuite) TestAddRemovePassphrase(c *C) {
+	err := s.addPassphrase(c, "open sesame")
+	c.Assert(err, IsNil)
+	c.Check(s.keyslots, DeepEquals, map[string][]string{
+		dataNode: {"data-sealed-key", "data-recovery-00", "open sesame"},
+		saveNode: {"save-key", "save-recovery-00", "open sesame"},
+	})
+	// the passphrase is asked for when booting
+	for _, marker := range s.passphraseMarkers() {
+		c.Check(marker, testutil.FilePresent)
+	}
+
+	// only one passphrase can be added
+	err = s.addPassphrase(c, "other")
+	c.Assert(err, ErrorMatches, `(?s).*\(cannot add passphrase to ubuntu-data: a passphrase was added already\)`)
+
+	err = s.removePassphrase(c, "open sesame")
+	c.Assert(err, IsNil)
+	c.Check(s.keyslots, DeepEquals, map[string][]string{
+		dataNode: {"data-sealed-key", "data-recovery-00"},
+		saveNode: {"save-key", "save-recovery-00"},
+	})
+	for _, marker := range s.passphraseMarkers() {
+		c.Check(marker, testutil.FileAbsent)
+	}
+
+	err = s.removePassphrase(c, "open sesame")
+	c.Assert(err, ErrorMatches, `(?s).*\(cannot remove passphrase from ubuntu-data: no key available with this passphrase\)`)
+
+	err = s.addPassphrase(c, "data-recovery-00")
+	c.Assert(err, ErrorMatches, `(?s).*\(cannot add passphrase to ubuntu-data: passphrase is already in use\)`)
+}
+
+func (s *deviceMgrFDEKeysSuite) TestPassphraseNotInState(c *C) {
+	s.state.Lock()
+	chg, err := devicestate.AddPassphrase(s.state, "open sesame")
+	c.Assert(err, IsNil)
+	data, err := json.Marshal(s.state)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Not(testutil.Contains), "open sesame")
+	// the passphrase is lost on restart
+	s.state.Cache(devicestate.PassphraseCacheKey(chg.Tasks()[0]), nil)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Err(), ErrorMatches, `(?s).*\(cannot use passphrase: it is not available anymore, please retry\)`)
+	c.Check(s.calls, HasLen, 0)
+}
+
+func (s *deviceMgrFDEKeysSuite) TestAddPassphraseUndo(c *C) {
+	s.failCheck = saveNode
+
+	err := s.addPassphrase(c, "open sesame")
+	c.Assert(err, ErrorMatches, `(?s).*\(cannot verify passphrase of ubuntu-save: mock check failure\)`)
+	c.Check(s.keyslots, DeepEquals, map[string][]string{
+		dataNode: {"data-sealed-key", "data-recovery-00"},
+		saveNode: {"save-key", "save-recovery-00"},
+	})
+	for _, marker := range s.passphraseMarkers() {
+		c.Check(marker, testutil.FileAbsent)
+	}
+}
+
+func (s *deviceMgrFDEKeysSuite) TestPassphraseErrors(c *C) {
+	err := s.addPassphrase(c, "")
+	c.Assert(err, ErrorMatches, "cannot use an empty passphrase")
+	err = s.removePassphrase(c, "")
+	c.Assert(err, ErrorMatches, "cannot use an empty passphrase")
+
+	// the recovery key is never removed
+	err = s.removePassphrase(c, "data-recovery-00")
+	c.Assert(err, ErrorMatches, `(?s).*\(cannot remove the recovery key of ubuntu-data as a passphrase\)`)
+	c.Check(s.calls, HasLen, 0)
+}
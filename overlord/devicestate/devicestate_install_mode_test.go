@@ -70,6 +70,15 @@ func (s *deviceMgrInstallModeSuite) findInstallSystem() *state.Change {
 	return nil
 }
 
+func (s *deviceMgrInstallModeSuite) findFactoryReset() *state.Change {
+	for _, chg := range s.state.Changes() {
+		if chg.Kind() == "factory-reset" {
+			return chg
+		}
+	}
+	return nil
+}
+
 func (s *deviceMgrInstallModeSuite) SetUpTest(c *C) {
 	s.deviceMgrBaseSuite.SetUpTest(c)
 
@@ -1079,3 +1088,157 @@ func (s *deviceMgrInstallModeSuite) TestInstallCheckEncryptedErrorsLogsHook(c *C
 	c.Check(err, IsNil)
 	c.Check(logbuf.String(), Matches, "(?s).*: not encrypting device storage as querying kernel fde-setup hook did not succeed:.*\n")
 }
+
+func (s *deviceMgrInstallModeSuite) mockFactoryResetChange(c *C) {
+	restore := release.MockOnClassic(false)
+	s.AddCleanup(restore)
+
+	restore = devicestate.MockInstallRun(func(mod gadget.Model, gadgetRoot, device string, options install.Options, _ gadget.ContentObserver) (*install.InstalledSystemSideData, error) {
+		c.Errorf("unexpected call to install.Run")
+		return nil, fmt.Errorf("unexpected call")
+	})
+	s.AddCleanup(restore)
+	restore = devicestate.MockBootMakeBootable(func(model *asserts.Model, rootdir string, bootWith *boot.BootableSet, seal *boot.TrustedAssetsInstallObserver) error {
+		c.Check(bootWith.RecoverySystemDir, Equals, "/systems/20191218")
+		return nil
+	})
+	s.AddCleanup(restore)
+
+	modeenv := boot.Modeenv{
+		Mode:           "factory-reset",
+		RecoverySystem: "20191218",
+	}
+	c.Assert(modeenv.WriteTo(""), IsNil)
+
+	s.state.Lock()
+	s.makeMockInstalledPcGadget(c, "dangerous", "")
+	devicestate.SetSystemMode(s.mgr, "factory-reset")
+	s.state.Unlock()
+
+	// normally done by snap-bootstrap
+	err := os.MkdirAll(boot.InitramfsUbuntuBootDir, 0755)
+	c.Assert(err, IsNil)
+
+	s.settle(c)
+}
+
+func (s *deviceMgrInstallModeSuite) TestFactoryResetPreservesDeviceIdentity(c *C) {
+	// the device identity is kept in ubuntu-save
+	saveDeviceDir := filepath.Join(boot.InitramfsUbuntuSaveDir, "device")
+	for _, p := range []string{"private-keys-v1/device-key", "asserts-v0/serial", "fde/marker"} {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(saveDeviceDir, p)), 0755)
+		c.Assert(err, IsNil)
+		err = ioutil.WriteFile(filepath.Join(saveDeviceDir, p), []byte(p), 0600)
+		c.Assert(err, IsNil)
+	}
+
+	factoryResetCalled := 0
+	restore := devicestate.MockInstallFactoryReset(func(mod gadget.Model, gadgetRoot, device string, options install.Options, _ gadget.ContentObserver) (*install.InstalledSystemSideData, error) {
+		// ensure we can grab the lock here, i.e. that it's not taken
+		s.state.Lock()
+		s.state.Unlock()
+
+		c.Check(gadgetRoot, Equals, filepath.Join(dirs.SnapMountDir, "/pc/1"))
+		c.Check(options, DeepEquals, install.Options{Mount: true})
+		factoryResetCalled++
+		// ubuntu-save gets re-created
+		c.Assert(os.RemoveAll(boot.InitramfsUbuntuSaveDir), IsNil)
+		c.Assert(os.MkdirAll(boot.InitramfsUbuntuSaveDir, 0755), IsNil)
+		return &install.InstalledSystemSideData{}, nil
+	})
+	defer restore()
+
+	s.mockFactoryResetChange(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	factoryReset := s.findFactoryReset()
+	c.Assert(factoryReset, NotNil)
+	c.Check(factoryReset.Err(), IsNil)
+	c.Check(factoryReset.Status(), Equals, state.DoneStatus)
+	c.Check(factoryResetCalled, Equals, 1)
+	// no install-system change in factory-reset mode
+	c.Check(s.findInstallSystem(), IsNil)
+	c.Check(s.restartRequests, DeepEquals, []state.RestartType{state.RestartSystemNow})
+
+	// the device key and assertions were carried over
+	c.Check(filepath.Join(saveDeviceDir, "private-keys-v1/device-key"), testutil.FileEquals, "private-keys-v1/device-key")
+	c.Check(filepath.Join(saveDeviceDir, "asserts-v0/serial"), testutil.FileEquals, "asserts-v0/serial")
+	// but not the old FDE data
+	c.Check(filepath.Join(saveDeviceDir, "fde/marker"), testutil.FileAbsent)
+	// and the backup is gone
+	c.Check(filepath.Join(dirs.SnapRunDir, "factory-reset/device"), testutil.FileAbsent)
+
+	c.Check(filepath.Join(boot.InitramfsUbuntuBootDir, "device/model"), testutil.FilePresent)
+}
+
+func (s *deviceMgrInstallModeSuite) TestFactoryResetDiscardsDeviceIdentity(c *C) {
+	saveDeviceDir := filepath.Join(boot.InitramfsUbuntuSaveDir, "device")
+	err := os.MkdirAll(filepath.Join(saveDeviceDir, "private-keys-v1"), 0755)
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(filepath.Join(saveDeviceDir, "private-keys-v1/device-key"), nil, 0600)
+	c.Assert(err, IsNil)
+	// the identity is not to be preserved
+	c.Assert(devicestate.SetFactoryResetPreservesIdentity(false), IsNil)
+
+	restore := devicestate.MockInstallFactoryReset(func(mod gadget.Model, gadgetRoot, device string, options install.Options, _ gadget.ContentObserver) (*install.InstalledSystemSideData, error) {
+		// nothing was backed up
+		c.Check(filepath.Join(dirs.SnapRunDir, "factory-reset/device"), testutil.FileAbsent)
+		// ubuntu-save gets re-created
+		c.Assert(os.RemoveAll(boot.InitramfsUbuntuSaveDir), IsNil)
+		c.Assert(os.MkdirAll(boot.InitramfsUbuntuSaveDir, 0755), IsNil)
+		return &install.InstalledSystemSideData{}, nil
+	})
+	defer restore()
+
+	s.mockFactoryResetChange(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	factoryReset := s.findFactoryReset()
+	c.Assert(factoryReset, NotNil)
+	c.Check(factoryReset.Err(), IsNil)
+	c.Check(factoryReset.Status(), Equals, state.DoneStatus)
+	c.Check(saveDeviceDir, testutil.FileAbsent)
+}
+
+func (s *deviceMgrInstallModeSuite) TestSetFactoryResetPreservesIdentity(c *C) {
+	marker := filepath.Join(boot.InitramfsUbuntuSaveDir, "device/factory-reset-discard-identity")
+
+	// no ubuntu-save, nothing to preserve
+	c.Assert(devicestate.SetFactoryResetPreservesIdentity(false), IsNil)
+	c.Check(marker, testutil.FileAbsent)
+	c.Assert(devicestate.SetFactoryResetPreservesIdentity(true), IsNil)
+
+	c.Assert(os.MkdirAll(filepath.Dir(marker), 0755), IsNil)
+	c.Assert(devicestate.SetFactoryResetPreservesIdentity(false), IsNil)
+	c.Check(marker, testutil.FilePresent)
+	// the request is idempotent
+	c.Assert(devicestate.SetFactoryResetPreservesIdentity(false), IsNil)
+	c.Check(marker, testutil.FilePresent)
+
+	c.Assert(devicestate.SetFactoryResetPreservesIdentity(true), IsNil)
+	c.Check(marker, testutil.FileAbsent)
+}
+
+func (s *deviceMgrInstallModeSuite) TestFactoryResetTaskErrors(c *C) {
+	// ubuntu-save is not available
+	restore := devicestate.MockInstallFactoryReset(func(mod gadget.Model, gadgetRoot, device string, options install.Options, _ gadget.ContentObserver) (*install.InstalledSystemSideData, error) {
+		return nil, fmt.Errorf("The horror, The horror")
+	})
+	defer restore()
+
+	s.mockFactoryResetChange(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	factoryReset := s.findFactoryReset()
+	c.Assert(factoryReset, NotNil)
+	c.Check(factoryReset.Err(), ErrorMatches, `(?ms)cannot perform the following tasks:
+- Perform factory reset of the system \(cannot perform factory reset: The horror, The horror\)`)
+	// no restart request on failure
+	c.Check(s.restartRequests, HasLen, 0)
+}
@@ -0,0 +1,184 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/devicestate/devicestatetest"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type deviceMgrRecoveryAccessSuite struct {
+	deviceMgrBaseSuite
+
+	sshCanary  string
+	systemctls [][]string
+}
+
+var _ = Suite(&deviceMgrRecoveryAccessSuite{})
+
+func (s *deviceMgrRecoveryAccessSuite) SetUpTest(c *C) {
+	s.deviceMgrBaseSuite.SetUpTest(c)
+
+	s.sshCanary = filepath.Join(dirs.GlobalRootDir, "/etc/ssh/sshd_not_to_be_run")
+	c.Assert(os.MkdirAll(filepath.Dir(s.sshCanary), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(s.sshCanary, nil, 0644), IsNil)
+
+	s.systemctls = nil
+	s.AddCleanup(systemd.MockSystemctl(func(args ...string) ([]byte, error) {
+		s.systemctls = append(s.systemctls, args)
+		return nil, nil
+	}))
+
+	devicestate.SetSystemMode(s.mgr, "recover")
+}
+
+func (s *deviceMgrRecoveryAccessSuite) setUC20ModelInState(c *C, remoteAccess string) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	headers := map[string]interface{}{
+		"architecture": "amd64",
+		"grade":        "dangerous",
+		"base":         "core20",
+		"snaps": []interface{}{
+			map[string]interface{}{
+				"name":            "pc-kernel",
+				"id":              snaptest.AssertedSnapID("pc-kernel"),
+				"type":            "kernel",
+				"default-channel": "20",
+			},
+			map[string]interface{}{
+				"name":            "pc",
+				"id":              snaptest.AssertedSnapID("pc"),
+				"type":            "gadget",
+				"default-channel": "20",
+			},
+		},
+	}
+	if remoteAccess != "" {
+		headers["recovery-remote-access"] = remoteAccess
+	}
+	s.makeModelAssertionInState(c, "canonical", "pc-20", headers)
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand: "canonical",
+		Model: "pc-20",
+	})
+	s.state.Set("seeded", true)
+}
+
+func (s *deviceMgrRecoveryAccessSuite) addUser(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	_, err := auth.NewUser(s.state, "guy", "foo@bar.com", "", nil)
+	c.Assert(err, IsNil)
+}
+
+func (s *deviceMgrRecoveryAccessSuite) TestStartsSSH(c *C) {
+	s.setUC20ModelInState(c, "true")
+
+	// no users yet
+	c.Assert(devicestate.EnsureRecoveryRemoteAccess(s.mgr), IsNil)
+	c.Check(s.sshCanary, testutil.FilePresent)
+	c.Check(s.systemctls, HasLen, 0)
+
+	s.addUser(c)
+	c.Assert(devicestate.EnsureRecoveryRemoteAccess(s.mgr), IsNil)
+	c.Check(s.sshCanary, testutil.FileAbsent)
+	c.Check(s.systemctls, DeepEquals, [][]string{
+		{"unmask", "ssh.service"},
+		{"start", "ssh.service"},
+	})
+
+	// only once
+	c.Assert(devicestate.EnsureRecoveryRemoteAccess(s.mgr), IsNil)
+	c.Check(s.systemctls, HasLen, 2)
+}
+
+func (s *deviceMgrRecoveryAccessSuite) TestNotAllowedByModelUnset(c *C) {
+	s.testNotAllowedByModel(c, "")
+}
+
+func (s *deviceMgrRecoveryAccessSuite) TestNotAllowedByModelFalse(c *C) {
+	s.testNotAllowedByModel(c, "false")
+}
+
+func (s *deviceMgrRecoveryAccessSuite) testNotAllowedByModel(c *C, remoteAccess string) {
+	s.setUC20ModelInState(c, remoteAccess)
+	s.addUser(c)
+
+	c.Assert(devicestate.EnsureRecoveryRemoteAccess(s.mgr), IsNil)
+	c.Check(s.sshCanary, testutil.FilePresent)
+	c.Check(s.systemctls, HasLen, 0)
+}
+
+func (s *deviceMgrRecoveryAccessSuite) TestNotInRunMode(c *C) {
+	devicestate.SetSystemMode(s.mgr, "run")
+	s.setUC20ModelInState(c, "true")
+	s.addUser(c)
+
+	c.Assert(devicestate.EnsureRecoveryRemoteAccess(s.mgr), IsNil)
+	c.Check(s.sshCanary, testutil.FilePresent)
+	c.Check(s.systemctls, HasLen, 0)
+}
+
+func (s *deviceMgrRecoveryAccessSuite) TestNotSeeded(c *C) {
+	s.setUC20ModelInState(c, "true")
+	s.addUser(c)
+	s.state.Lock()
+	s.state.Set("seeded", false)
+	s.state.Unlock()
+
+	c.Assert(devicestate.EnsureRecoveryRemoteAccess(s.mgr), IsNil)
+	c.Check(s.sshCanary, testutil.FilePresent)
+	c.Check(s.systemctls, HasLen, 0)
+}
+
+func (s *deviceMgrRecoveryAccessSuite) TestStartError(c *C) {
+	s.setUC20ModelInState(c, "true")
+	s.addUser(c)
+
+	restore := systemd.MockSystemctl(func(args ...string) ([]byte, error) {
+		s.systemctls = append(s.systemctls, args)
+		if args[0] == "start" {
+			return nil, fmt.Errorf("boom")
+		}
+		return nil, nil
+	})
+	defer restore()
+
+	err := devicestate.EnsureRecoveryRemoteAccess(s.mgr)
+	c.Check(err, ErrorMatches, `cannot start SSH in recover mode: boom`)
+
+	// retried
+	c.Check(devicestate.EnsureRecoveryRemoteAccess(s.mgr), NotNil)
+	c.Check(s.systemctls, HasLen, 4)
+}
@@ -20,6 +20,7 @@
 package devicestate_test
 
 import (
+	"crypto/x509"
 	"fmt"
 	"net"
 	"net/http"
@@ -1541,6 +1542,195 @@ func (s *deviceMgrSerialSuite) TestStoreContextBackendDeviceSessionRequestParams
 	c.Check(sessReq.Nonce(), Equals, "NONCE-1")
 }
 
+func (s *deviceMgrSerialSuite) TestStoreContextBackendDeviceKeyCertificate(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	// set model as seeding would
+	s.makeModelAssertionInState(c, "canonical", "pc", map[string]interface{}{
+		"architecture": "amd64",
+		"kernel":       "pc-kernel",
+		"gadget":       "pc",
+	})
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand: "canonical",
+		Model: "pc",
+	})
+
+	scb := s.mgr.StoreContextBackend()
+
+	_, err := scb.DeviceKeyCertificate(nil)
+	c.Check(err, ErrorMatches, "internal error: cannot produce a client certificate without a serial")
+
+	encDevKey, err := asserts.EncodePublicKey(devKey.PublicKey())
+	c.Check(err, IsNil)
+	seriala, err := s.storeSigning.Sign(asserts.SerialType, map[string]interface{}{
+		"brand-id":            "canonical",
+		"model":               "pc",
+		"serial":              "8989",
+		"device-key":          string(encDevKey),
+		"device-key-sha3-384": devKey.PublicKey().ID(),
+		"timestamp":           time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	serial := seriala.(*asserts.Serial)
+
+	_, err = scb.DeviceKeyCertificate(serial)
+	c.Check(err, ErrorMatches, "internal error: inconsistent state with serial but no device key")
+
+	devicestate.KeypairManager(s.mgr).Put(devKey)
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand:  "canonical",
+		Model:  "pc",
+		Serial: "8989",
+		KeyID:  devKey.PublicKey().ID(),
+	})
+
+	cert, err := scb.DeviceKeyCertificate(serial)
+	c.Assert(err, IsNil)
+	c.Assert(cert.Certificate, HasLen, 1)
+
+	x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
+	c.Assert(err, IsNil)
+	c.Check(x509Cert.Subject.CommonName, Equals, "8989")
+	c.Check(x509Cert.Subject.Organization, DeepEquals, []string{"canonical"})
+	c.Check(x509Cert.Subject.OrganizationalUnit, DeepEquals, []string{"pc"})
+	c.Check(x509Cert.ExtKeyUsage, DeepEquals, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth})
+	// self-signed with the device key
+	c.Check(x509Cert.CheckSignature(x509Cert.SignatureAlgorithm, x509Cert.RawTBSCertificate, x509Cert.Signature), IsNil)
+	signer, err := asserts.CryptoSigner(devKey)
+	c.Assert(err, IsNil)
+	c.Check(x509Cert.PublicKey, DeepEquals, signer.Public())
+	c.Check(x509Cert.NotAfter.Sub(x509Cert.NotBefore), Equals, 25*time.Hour)
+}
+
+func (s *deviceMgrSerialSuite) TestStoreContextBackendDeviceKeyCertificateCached(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.makeModelAssertionInState(c, "canonical", "pc", map[string]interface{}{
+		"architecture": "amd64",
+		"kernel":       "pc-kernel",
+		"gadget":       "pc",
+	})
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand: "canonical",
+		Model: "pc",
+	})
+
+	encDevKey, err := asserts.EncodePublicKey(devKey.PublicKey())
+	c.Check(err, IsNil)
+	makeSerial := func(serialNum string) *asserts.Serial {
+		seriala, err := s.storeSigning.Sign(asserts.SerialType, map[string]interface{}{
+			"brand-id":            "canonical",
+			"model":               "pc",
+			"serial":              serialNum,
+			"device-key":          string(encDevKey),
+			"device-key-sha3-384": devKey.PublicKey().ID(),
+			"timestamp":           time.Now().Format(time.RFC3339),
+		}, nil, "")
+		c.Assert(err, IsNil)
+		return seriala.(*asserts.Serial)
+	}
+	serial := makeSerial("8989")
+
+	devicestate.KeypairManager(s.mgr).Put(devKey)
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand:  "canonical",
+		Model:  "pc",
+		Serial: "8989",
+		KeyID:  devKey.PublicKey().ID(),
+	})
+
+	now := time.Now()
+	restore := devicestate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	scb := s.mgr.StoreContextBackend()
+	cert1, err := scb.DeviceKeyCertificate(serial)
+	c.Assert(err, IsNil)
+
+	// reused
+	now = now.Add(22 * time.Hour)
+	cert2, err := scb.DeviceKeyCertificate(serial)
+	c.Assert(err, IsNil)
+	c.Check(cert2, Equals, cert1)
+
+	// renewed when close to expiry
+	now = now.Add(90 * time.Minute)
+	cert3, err := scb.DeviceKeyCertificate(serial)
+	c.Assert(err, IsNil)
+	c.Check(cert3, Not(Equals), cert1)
+	c.Check(cert3.Certificate[0], Not(DeepEquals), cert1.Certificate[0])
+
+	// renewed for a different serial
+	cert4, err := scb.DeviceKeyCertificate(makeSerial("9090"))
+	c.Assert(err, IsNil)
+	c.Check(cert4, Not(Equals), cert3)
+	x509Cert, err := x509.ParseCertificate(cert4.Certificate[0])
+	c.Assert(err, IsNil)
+	c.Check(x509Cert.Subject.CommonName, Equals, "9090")
+}
+
+func (s *deviceMgrSerialSuite) TestEnsureStoreTLSParams(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.makeModelAssertionInState(c, "canonical", "pc", map[string]interface{}{
+		"architecture": "amd64",
+		"kernel":       "pc-kernel",
+		"gadget":       "pc",
+	})
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand: "canonical",
+		Model: "pc",
+	})
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "store.client-certificate", "device"), IsNil)
+	tr.Commit()
+
+	storeCtx := storecontext.New(s.state, s.mgr.StoreContextBackend())
+
+	// no serial yet
+	params, err := storeCtx.TLSParams()
+	c.Assert(err, IsNil)
+	c.Check(params, IsNil)
+
+	// as done by device registration
+	encDevKey, err := asserts.EncodePublicKey(devKey.PublicKey())
+	c.Check(err, IsNil)
+	seriala, err := s.storeSigning.Sign(asserts.SerialType, map[string]interface{}{
+		"brand-id":            "canonical",
+		"model":               "pc",
+		"serial":              "8989",
+		"device-key":          string(encDevKey),
+		"device-key-sha3-384": devKey.PublicKey().ID(),
+		"timestamp":           time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	assertstatetest.AddMany(s.state, seriala)
+	devicestate.KeypairManager(s.mgr).Put(devKey)
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand:  "canonical",
+		Model:  "pc",
+		Serial: "8989",
+		KeyID:  devKey.PublicKey().ID(),
+	})
+
+	s.state.Unlock()
+	devicestate.EnsureStoreTLSParams(s.mgr)
+	// the parameters are then available without the state lock, as
+	// needed during TLS handshakes
+	params, err = storeCtx.TLSParams()
+	s.state.Lock()
+	c.Assert(err, IsNil)
+	c.Assert(params, NotNil)
+	c.Assert(params.ClientCertificate, NotNil)
+	x509Cert, err := x509.ParseCertificate(params.ClientCertificate.Certificate[0])
+	c.Assert(err, IsNil)
+	c.Check(x509Cert.Subject.CommonName, Equals, "8989")
+}
+
 func (s *deviceMgrSerialSuite) TestStoreContextBackendProxyStore(c *C) {
 	mockServer := s.mockServer(c, "", nil)
 	defer mockServer.Close()
@@ -2013,3 +2203,94 @@ func (s *deviceMgrSerialSuite) TestFullDeviceRegistrationUC20Happy(c *C) {
 	})
 	c.Assert(err, IsNil)
 }
+
+func (s *deviceMgrSerialSuite) TestDeviceRegistrationUC20RestoredFromSave(c *C) {
+	defer sysdb.InjectTrusted([]asserts.Assertion{s.storeSigning.TrustedKey})()
+
+	// the device identity was carried over in ubuntu-save by a
+	// factory reset
+	savedKey, _ := assertstest.GenerateKey(testKeyLength)
+	encDevKey, err := asserts.EncodePublicKey(savedKey.PublicKey())
+	c.Assert(err, IsNil)
+	seriala, err := s.storeSigning.Sign(asserts.SerialType, map[string]interface{}{
+		"brand-id":            "canonical",
+		"model":               "pc-20",
+		"serial":              "8989",
+		"device-key":          string(encDevKey),
+		"device-key-sha3-384": savedKey.PublicKey().ID(),
+		"timestamp":           time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	savedb, err := sysdb.OpenAt(dirs.SnapDeviceSaveDir)
+	c.Assert(err, IsNil)
+	c.Assert(savedb.Add(seriala), IsNil)
+	keypairMgr, err := asserts.OpenFSKeypairManager(dirs.SnapDeviceSaveDir)
+	c.Assert(err, IsNil)
+	c.Assert(keypairMgr.Put(savedKey), IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.makeModelAssertionInState(c, "canonical", "pc-20", map[string]interface{}{
+		"architecture": "amd64",
+		// UC20
+		"base": "core20",
+		"snaps": []interface{}{
+			map[string]interface{}{
+				"name":            "pc-kernel",
+				"id":              snaptest.AssertedSnapID("oc-kernel"),
+				"type":            "kernel",
+				"default-channel": "20",
+			},
+			map[string]interface{}{
+				"name":            "pc",
+				"id":              snaptest.AssertedSnapID("pc"),
+				"type":            "gadget",
+				"default-channel": "20",
+			},
+		},
+	})
+
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand: "canonical",
+		Model: "pc-20",
+	})
+
+	// save is available
+	devicestate.SetSaveAvailable(s.mgr, true)
+
+	// avoid full seeding
+	s.seeding()
+
+	devicestatetest.MockGadget(c, s.state, "pc", snap.R(2), nil)
+	// mark it as seeded
+	s.state.Set("seeded", true)
+	// skip boot ok logic
+	devicestate.SetBootOkRan(s.mgr, true)
+
+	s.state.Unlock()
+	s.settle(c)
+	s.state.Lock()
+
+	// no registration took place
+	c.Check(s.findBecomeOperationalChange(), IsNil)
+
+	device, err := devicestatetest.Device(s.state)
+	c.Assert(err, IsNil)
+	c.Check(device.Serial, Equals, "8989")
+	c.Check(device.KeyID, Equals, savedKey.PublicKey().ID())
+
+	select {
+	case <-s.mgr.Registered():
+	default:
+		c.Fatal("should have been marked registered")
+	}
+
+	// the serial is now in the system assertion database
+	_, err = s.db.Find(asserts.SerialType, map[string]string{
+		"brand-id": "canonical",
+		"model":    "pc-20",
+		"serial":   "8989",
+	})
+	c.Assert(err, IsNil)
+}
@@ -23,6 +23,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -36,12 +37,14 @@ import (
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/overlord/devicestate/devicestatetest"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/seed"
 	"github.com/snapcore/snapd/seed/seedtest"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/testutil"
 )
 
 type mockedSystemSeed struct {
@@ -241,6 +244,7 @@ var defaultSystemActions []devicestate.SystemAction = []devicestate.SystemAction
 var currentSystemActions []devicestate.SystemAction = []devicestate.SystemAction{
 	{Title: "Reinstall", Mode: "install"},
 	{Title: "Recover", Mode: "recover"},
+	{Title: "Factory reset", Mode: "factory-reset"},
 	{Title: "Run normally", Mode: "run"},
 }
 
@@ -508,7 +512,7 @@ func (s *deviceMgrSystemsSuite) TestRequestModeRunInstallForRecover(c *C) {
 	})
 	s.state.Unlock()
 
-	s.testRequestModeWithRestart(c, []string{"install", "run"}, s.mockedSystemSeeds[0].label)
+	s.testRequestModeWithRestart(c, []string{"install", "factory-reset", "run"}, s.mockedSystemSeeds[0].label)
 }
 
 func (s *deviceMgrSystemsSuite) TestRequestModeInstallRecoverForCurrent(c *C) {
@@ -530,7 +534,7 @@ func (s *deviceMgrSystemsSuite) TestRequestModeInstallRecoverForCurrent(c *C) {
 	})
 	s.state.Unlock()
 
-	s.testRequestModeWithRestart(c, []string{"install", "recover"}, s.mockedSystemSeeds[0].label)
+	s.testRequestModeWithRestart(c, []string{"install", "recover", "factory-reset"}, s.mockedSystemSeeds[0].label)
 }
 
 func (s *deviceMgrSystemsSuite) TestRequestModeErrInBoot(c *C) {
@@ -827,3 +831,236 @@ func (s *deviceMgrSystemsSuite) TestRebootUnhappy(c *C) {
 	}
 	c.Check(s.logbuf.String(), Equals, "")
 }
+
+type deviceMgrCreateRecoverySystemSuite struct {
+	deviceMgrBaseSuite
+}
+
+var _ = Suite(&deviceMgrCreateRecoverySystemSuite{})
+
+func (s *deviceMgrCreateRecoverySystemSuite) SetUpTest(c *C) {
+	s.deviceMgrBaseSuite.SetUpTest(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.makeModelAssertionInState(c, "canonical", "pc-20", map[string]interface{}{
+		"architecture": "amd64",
+		// UC20
+		"grade": "dangerous",
+		"base":  "core20",
+		"snaps": []interface{}{
+			map[string]interface{}{
+				"name":            "pc-kernel",
+				"id":              snaptest.AssertedSnapID("pc-kernel"),
+				"type":            "kernel",
+				"default-channel": "20",
+			},
+			map[string]interface{}{
+				"name":            "pc",
+				"id":              snaptest.AssertedSnapID("pc"),
+				"type":            "gadget",
+				"default-channel": "20",
+			},
+		},
+	})
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand:  "canonical",
+		Model:  "pc-20",
+		Serial: "serialserialserial",
+	})
+	s.state.Set("seeded", true)
+	devicestate.SetSystemMode(s.mgr, "run")
+	devicestate.SetBootOkRan(s.mgr, true)
+
+	m := boot.Modeenv{
+		Mode:                   "run",
+		CurrentRecoverySystems: []string{"20200101"},
+	}
+	c.Assert(m.WriteTo(""), IsNil)
+
+	// a snap that is shared with the existing recovery system
+	c.Assert(os.MkdirAll(filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps"), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/pc_1.snap"), nil, 0644), IsNil)
+}
+
+func (s *deviceMgrCreateRecoverySystemSuite) mockCreateSystem(c *C, err error) (called *int) {
+	called = new(int)
+	restore := devicestate.MockCreateSystemForModelFromValidatedSnaps(func(model *asserts.Model, label, seedDir string, db asserts.RODatabase, getInfo func(string) (*snap.Info, bool, error), copySnapFile func(src, dst string) error) error {
+		*called++
+		c.Check(model.Model(), Equals, "pc-20")
+		c.Check(label, Equals, "20261018")
+		c.Check(seedDir, Equals, boot.InitramfsUbuntuSeedDir)
+		c.Check(db, NotNil)
+		_, present, infoErr := getInfo("pc-kernel")
+		c.Check(infoErr, IsNil)
+		c.Check(present, Equals, false)
+
+		c.Assert(os.MkdirAll(filepath.Join(seedDir, "systems", label), 0755), IsNil)
+		src := filepath.Join(c.MkDir(), "pc-kernel_2.snap")
+		c.Assert(ioutil.WriteFile(src, []byte("kernel"), 0644), IsNil)
+		newSnap := filepath.Join(seedDir, "snaps/pc-kernel_2.snap")
+		c.Assert(copySnapFile(src, newSnap), IsNil)
+		c.Check(newSnap, testutil.FileEquals, "kernel")
+
+		// the new file is tracked right away
+		var create *state.Task
+		for _, t := range s.state.Tasks() {
+			if t.Kind() == "create-recovery-system" {
+				create = t
+			}
+		}
+		c.Assert(create, NotNil)
+		var setup map[string]interface{}
+		c.Assert(create.Get("recovery-system-setup", &setup), IsNil)
+		c.Check(setup["new-snap-files"], DeepEquals, []interface{}{newSnap})
+		return err
+	})
+	s.AddCleanup(restore)
+	return called
+}
+
+func (s *deviceMgrCreateRecoverySystemSuite) currentRecoverySystems(c *C) []string {
+	m, err := boot.ReadModeenv("")
+	c.Assert(err, IsNil)
+	return m.CurrentRecoverySystems
+}
+
+func (s *deviceMgrCreateRecoverySystemSuite) TestCreateRecoverySystemHappy(c *C) {
+	called := s.mockCreateSystem(c, nil)
+
+	s.state.Lock()
+	chg, err := devicestate.CreateRecoverySystem(s.state, "20261018")
+	c.Assert(err, IsNil)
+	c.Check(chg.Kind(), Equals, "create-recovery-system")
+	c.Check(chg.Summary(), Equals, `Create recovery system with label "20261018"`)
+	tsks := chg.Tasks()
+	c.Assert(tsks, HasLen, 1)
+	c.Check(tsks[0].Kind(), Equals, "create-recovery-system")
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Err(), IsNil)
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+	c.Check(*called, Equals, 1)
+	c.Check(s.currentRecoverySystems(c), DeepEquals, []string{"20200101", "20261018"})
+	c.Check(filepath.Join(boot.InitramfsUbuntuSeedDir, "systems/20261018"), testutil.FilePresent)
+	c.Check(filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/pc-kernel_2.snap"), testutil.FilePresent)
+}
+
+func (s *deviceMgrCreateRecoverySystemSuite) TestCreateRecoverySystemCopyErrorNoPartialFile(c *C) {
+	newSnap := filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/pc-kernel_2.snap")
+	restore := devicestate.MockCreateSystemForModelFromValidatedSnaps(func(model *asserts.Model, label, seedDir string, db asserts.RODatabase, getInfo func(string) (*snap.Info, bool, error), copySnapFile func(src, dst string) error) error {
+		// reading a directory fails after the destination was
+		// opened
+		err := copySnapFile(c.MkDir(), newSnap)
+		c.Check(err, ErrorMatches, "unable to copy .*")
+		c.Check(newSnap, testutil.FileAbsent)
+		return err
+	})
+	defer restore()
+
+	s.state.Lock()
+	chg, err := devicestate.CreateRecoverySystem(s.state, "20261018")
+	c.Assert(err, IsNil)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(chg.Status(), Equals, state.ErrorStatus)
+	// no temporary file is left behind
+	names, err := filepath.Glob(filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/*"))
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/pc_1.snap")})
+}
+
+func (s *deviceMgrCreateRecoverySystemSuite) TestCreateRecoverySystemUndo(c *C) {
+	s.mockCreateSystem(c, nil)
+
+	s.state.Lock()
+	chg, err := devicestate.CreateRecoverySystem(s.state, "20261018")
+	c.Assert(err, IsNil)
+	create := chg.Tasks()[0]
+	terr := s.state.NewTask("error-trigger", "provoking total undo")
+	terr.WaitFor(create)
+	chg.AddTask(terr)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(chg.Status(), Equals, state.ErrorStatus)
+	c.Check(create.Status(), Equals, state.UndoneStatus)
+	c.Check(s.currentRecoverySystems(c), DeepEquals, []string{"20200101"})
+	c.Check(filepath.Join(boot.InitramfsUbuntuSeedDir, "systems/20261018"), testutil.FileAbsent)
+	c.Check(filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/pc-kernel_2.snap"), testutil.FileAbsent)
+	// snaps shared with other systems are kept
+	c.Check(filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/pc_1.snap"), testutil.FilePresent)
+}
+
+func (s *deviceMgrCreateRecoverySystemSuite) TestCreateRecoverySystemErrorCleansUp(c *C) {
+	s.mockCreateSystem(c, fmt.Errorf("boom"))
+
+	s.state.Lock()
+	chg, err := devicestate.CreateRecoverySystem(s.state, "20261018")
+	c.Assert(err, IsNil)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(chg.Status(), Equals, state.ErrorStatus)
+	c.Check(chg.Err(), ErrorMatches, `(?s).*cannot create recovery system "20261018": boom.*`)
+	c.Check(s.currentRecoverySystems(c), DeepEquals, []string{"20200101"})
+	c.Check(filepath.Join(boot.InitramfsUbuntuSeedDir, "systems/20261018"), testutil.FileAbsent)
+	c.Check(filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/pc-kernel_2.snap"), testutil.FileAbsent)
+	c.Check(filepath.Join(boot.InitramfsUbuntuSeedDir, "snaps/pc_1.snap"), testutil.FilePresent)
+}
+
+func (s *deviceMgrCreateRecoverySystemSuite) TestCreateRecoverySystemErrors(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := devicestate.CreateRecoverySystem(s.state, "Not-Valid")
+	c.Check(err, ErrorMatches, `invalid seed system label: "Not-Valid"`)
+
+	c.Assert(os.MkdirAll(filepath.Join(boot.InitramfsUbuntuSeedDir, "systems/20200101"), 0755), IsNil)
+	_, err = devicestate.CreateRecoverySystem(s.state, "20200101")
+	c.Check(err, ErrorMatches, `recovery system "20200101" already exists`)
+
+	devicestate.SetSystemMode(s.mgr, "recover")
+	_, err = devicestate.CreateRecoverySystem(s.state, "20261018")
+	c.Check(err, ErrorMatches, `cannot create recovery systems outside of run mode`)
+	devicestate.SetSystemMode(s.mgr, "run")
+
+	chg := s.state.NewChange("create-recovery-system", "...")
+	chg.AddTask(s.state.NewTask("create-recovery-system", "..."))
+	_, err = devicestate.CreateRecoverySystem(s.state, "20261018")
+	c.Check(err, ErrorMatches, `cannot create a recovery system, another one is being created`)
+	c.Check(err, FitsTypeOf, &snapstate.ChangeConflictError{})
+	chg.SetStatus(state.DoneStatus)
+
+	// a snap of the model is being refreshed
+	chg = s.state.NewChange("refresh", "...")
+	t := s.state.NewTask("link-snap", "...")
+	t.Set("snap-setup", &snapstate.SnapSetup{SideInfo: &snap.SideInfo{RealName: "pc-kernel"}})
+	chg.AddTask(t)
+	_, err = devicestate.CreateRecoverySystem(s.state, "20261018")
+	c.Check(err, ErrorMatches, `snap "pc-kernel" has "refresh" change in progress`)
+}
+
+func (s *deviceMgrCreateRecoverySystemSuite) TestCreateRecoverySystemNotUC20(c *C) {
+	s.setPCModelInState(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := devicestate.CreateRecoverySystem(s.state, "20261018")
+	c.Check(err, ErrorMatches, `cannot create recovery systems on a non Ubuntu Core 20 device`)
+}
@@ -492,6 +492,60 @@ func (s *deviceMgrSuite) TestDeviceManagerEnsureBootOkNotRunAgain(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *deviceMgrSuite) TestDeviceManagerEnsureBootHistory(c *C) {
+	var modes []string
+	restore := devicestate.MockBootRecordBoot(func(dev boot.Device, mode string) error {
+		c.Check(dev.RunMode(), Equals, true)
+		modes = append(modes, mode)
+		return nil
+	})
+	defer restore()
+
+	// no model yet
+	err := devicestate.EnsureBootHistory(s.mgr)
+	c.Assert(err, IsNil)
+	c.Check(modes, HasLen, 0)
+
+	s.setPCModelInState(c)
+	err = devicestate.EnsureBootHistory(s.mgr)
+	c.Assert(err, IsNil)
+	c.Check(modes, DeepEquals, []string{"run"})
+
+	// only recorded once
+	err = devicestate.EnsureBootHistory(s.mgr)
+	c.Assert(err, IsNil)
+	c.Check(modes, DeepEquals, []string{"run"})
+}
+
+func (s *deviceMgrSuite) TestDeviceManagerEnsureBootHistoryErrorIgnored(c *C) {
+	s.setPCModelInState(c)
+	n := 0
+	restore := devicestate.MockBootRecordBoot(func(dev boot.Device, mode string) error {
+		n++
+		return fmt.Errorf("boom")
+	})
+	defer restore()
+
+	err := devicestate.EnsureBootHistory(s.mgr)
+	c.Assert(err, IsNil)
+	err = devicestate.EnsureBootHistory(s.mgr)
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 1)
+}
+
+func (s *deviceMgrSuite) TestDeviceManagerEnsureBootHistorySkippedOnClassic(c *C) {
+	s.setPCModelInState(c)
+	release.OnClassic = true
+	restore := devicestate.MockBootRecordBoot(func(dev boot.Device, mode string) error {
+		c.Fatalf("unexpected call")
+		return nil
+	})
+	defer restore()
+
+	err := devicestate.EnsureBootHistory(s.mgr)
+	c.Assert(err, IsNil)
+}
+
 func (s *deviceMgrSuite) TestDeviceManagerEnsureBootOkError(c *C) {
 	s.setPCModelInState(c)
 
@@ -32,6 +32,7 @@ import (
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/overlord/storecontext"
+	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/sysconfig"
 	"github.com/snapcore/snapd/timings"
 )
@@ -172,6 +173,41 @@ func SetBootOkRan(m *DeviceManager, b bool) {
 	m.bootOkRan = b
 }
 
+func MockBootHealthTimings(gracePeriod, retryInterval time.Duration) (restore func()) {
+	oldGracePeriod := bootHealthGracePeriod
+	oldRetryInterval := bootHealthRetryInterval
+	bootHealthGracePeriod = gracePeriod
+	bootHealthRetryInterval = retryInterval
+	return func() {
+		bootHealthGracePeriod = oldGracePeriod
+		bootHealthRetryInterval = oldRetryInterval
+	}
+}
+
+func EnsureKernelCommandLine(m *DeviceManager) error {
+	return m.ensureKernelCommandLine()
+}
+
+func EnsureStoreTLSParams(m *DeviceManager) {
+	m.ensureStoreTLSParams()
+}
+
+func MockBootCommandLineAppend(f func(dev boot.Device) (string, bool, error)) (restore func()) {
+	old := bootCommandLineAppend
+	bootCommandLineAppend = f
+	return func() {
+		bootCommandLineAppend = old
+	}
+}
+
+func MockBootSetTryCommandLineAppend(f func(dev boot.Device, args string) (bool, error)) (restore func()) {
+	old := bootSetTryCommandLineAppend
+	bootSetTryCommandLineAppend = f
+	return func() {
+		bootSetTryCommandLineAppend = old
+	}
+}
+
 func StartTime() time.Time {
 	return startTime
 }
@@ -248,6 +284,24 @@ func MockSecbootCheckKeySealingSupported(f func() error) (restore func()) {
 	}
 }
 
+func PassphraseCacheKey(t *state.Task) interface{} {
+	return passphraseCacheKey{t.ID()}
+}
+
+func MockSecbootLUKS2ContainerKeys(add func(node string, existingKey, newKey []byte) error, check func(node string, key []byte) error, remove func(node string, key []byte) error) (restore func()) {
+	oldAdd := secbootAddLUKS2ContainerKey
+	oldCheck := secbootCheckLUKS2ContainerKey
+	oldRemove := secbootRemoveLUKS2ContainerKey
+	secbootAddLUKS2ContainerKey = add
+	secbootCheckLUKS2ContainerKey = check
+	secbootRemoveLUKS2ContainerKey = remove
+	return func() {
+		secbootAddLUKS2ContainerKey = oldAdd
+		secbootCheckLUKS2ContainerKey = oldCheck
+		secbootRemoveLUKS2ContainerKey = oldRemove
+	}
+}
+
 func MockHttputilNewHTTPClient(f func(opts *httputil.ClientOptions) *http.Client) (restore func()) {
 	old := httputilNewHTTPClient
 	httputilNewHTTPClient = f
@@ -272,6 +326,14 @@ func MockInstallRun(f func(model gadget.Model, gadgetRoot, device string, option
 	}
 }
 
+func MockInstallFactoryReset(f func(model gadget.Model, gadgetRoot, device string, options install.Options, observer gadget.ContentObserver) (*install.InstalledSystemSideData, error)) (restore func()) {
+	old := installFactoryReset
+	installFactoryReset = f
+	return func() {
+		installFactoryReset = old
+	}
+}
+
 func MockCloudInitStatus(f func() (sysconfig.CloudInitState, error)) (restore func()) {
 	old := cloudInitStatus
 	cloudInitStatus = f
@@ -303,3 +365,29 @@ func DeviceManagerCheckEncryption(mgr *DeviceManager, st *state.State, deviceCtx
 func DeviceManagerCheckFDEFeatures(mgr *DeviceManager, st *state.State) error {
 	return mgr.checkFDEFeatures(st)
 }
+
+func MockCreateSystemForModelFromValidatedSnaps(f func(model *asserts.Model, label, seedDir string, db asserts.RODatabase, getInfo func(name string) (*snap.Info, bool, error), copySnapFile func(src, dst string) error) error) (restore func()) {
+	old := createSystemForModelFromValidatedSnaps
+	createSystemForModelFromValidatedSnaps = func(model *asserts.Model, label, seedDir string, db asserts.RODatabase, getInfo getSnapInfoFunc, copySnapFile copySnapFileFunc) error {
+		return f(model, label, seedDir, db, getInfo, copySnapFile)
+	}
+	return func() {
+		createSystemForModelFromValidatedSnaps = old
+	}
+}
+
+func EnsureBootHistory(m *DeviceManager) error {
+	return m.ensureBootHistory()
+}
+
+func MockBootRecordBoot(f func(dev boot.Device, mode string) error) (restore func()) {
+	old := bootRecordBoot
+	bootRecordBoot = f
+	return func() {
+		bootRecordBoot = old
+	}
+}
+
+func EnsureRecoveryRemoteAccess(m *DeviceManager) error {
+	return m.ensureRecoveryRemoteAccess()
+}
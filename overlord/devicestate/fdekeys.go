@@ -0,0 +1,350 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/osutil/disks"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/secboot"
+)
+
+var (
+	secbootAddLUKS2ContainerKey    = secboot.AddLUKS2ContainerKey
+	secbootCheckLUKS2ContainerKey  = secboot.CheckLUKS2ContainerKey
+	secbootRemoveLUKS2ContainerKey = secboot.RemoveLUKS2ContainerKey
+)
+
+// ErrNoEncryptedVolumes is returned when managing the keys of the encrypted
+// volumes of a device that does not use encryption.
+var ErrNoEncryptedVolumes = errors.New("no encrypted volumes with recovery keys")
+
+// fdeKeysMu serializes the changes to the keyslots of the encrypted volumes,
+// the state lock is not held while cryptsetup runs as it is slow by design.
+var fdeKeysMu sync.Mutex
+
+// encryptedVolume is an encrypted volume along with the file holding its
+// recovery key.
+type encryptedVolume struct {
+	name            string
+	node            string
+	recoveryKeyFile string
+}
+
+// encryptedVolumes returns the encrypted ubuntu-data and, if present,
+// ubuntu-save volumes of a device in run mode.
+func encryptedVolumes() ([]*encryptedVolume, error) {
+	candidates := []struct {
+		name, mountPoint, keyFile string
+	}{
+		{"ubuntu-data", boot.InitramfsDataDir, "recovery.key"},
+		{"ubuntu-save", boot.InitramfsUbuntuSaveDir, "reinstall.key"},
+	}
+	var vols []*encryptedVolume
+	for _, cand := range candidates {
+		keyFile := filepath.Join(dirs.SnapFDEDir, cand.keyFile)
+		if !osutil.FileExists(keyFile) {
+			continue
+		}
+		disk, err := disks.DiskFromMountPoint(cand.mountPoint, &disks.Options{IsDecryptedDevice: true})
+		if err != nil {
+			return nil, fmt.Errorf("cannot find disk of %s: %v", cand.name, err)
+		}
+		partUUID, err := disk.FindMatchingPartitionUUIDWithFsLabel(cand.name + "-enc")
+		if err != nil {
+			return nil, fmt.Errorf("cannot find encrypted partition of %s: %v", cand.name, err)
+		}
+		vols = append(vols, &encryptedVolume{
+			name:            cand.name,
+			node:            filepath.Join("/dev/disk/by-partuuid", partUUID),
+			recoveryKeyFile: keyFile,
+		})
+	}
+	if len(vols) == 0 {
+		return nil, ErrNoEncryptedVolumes
+	}
+	return vols, nil
+}
+
+func lockedEncryptedVolumes() ([]*encryptedVolume, func(), error) {
+	fdeKeysMu.Lock()
+	vols, err := encryptedVolumes()
+	if err != nil {
+		fdeKeysMu.Unlock()
+		return nil, nil, err
+	}
+	return vols, fdeKeysMu.Unlock, nil
+}
+
+// passphraseMarker returns the file recording on ubuntu-seed that a passphrase
+// was added to the volume, so that it is asked for when booting.
+func passphraseMarker(vol *encryptedVolume) string {
+	return filepath.Join(boot.InitramfsSeedEncryptionKeyDir, vol.name+".passphrase")
+}
+
+// fdeKeysChangeKinds are the kinds of the changes managing the keys of the
+// encrypted volumes, only one of them can be in progress at a time.
+var fdeKeysChangeKinds = map[string]bool{
+	"rotate-recovery-keys": true,
+	"add-passphrase":       true,
+	"remove-passphrase":    true,
+}
+
+// passphraseCacheKey is the key of the passphrase used by a task in the state
+// cache, the passphrase is never stored in the state itself.
+type passphraseCacheKey struct {
+	taskID string
+}
+
+func newFDEKeysChange(st *state.State, kind, summary, passphrase string) (*state.Change, error) {
+	if mode := deviceMgr(st).SystemMode(); mode != "run" {
+		return nil, fmt.Errorf("cannot manage encryption keys in %q mode", mode)
+	}
+	if !osutil.FileExists(filepath.Join(dirs.SnapFDEDir, "recovery.key")) && !osutil.FileExists(filepath.Join(dirs.SnapFDEDir, "reinstall.key")) {
+		return nil, ErrNoEncryptedVolumes
+	}
+	for _, chg := range st.Changes() {
+		if !chg.IsReady() && fdeKeysChangeKinds[chg.Kind()] {
+			return nil, &snapstate.ChangeConflictError{
+				ChangeKind: chg.Kind(),
+				Message:    "cannot manage encryption keys, another change is managing them",
+			}
+		}
+	}
+
+	chg := st.NewChange(kind, summary)
+	t := st.NewTask(kind, summary)
+	if passphrase != "" {
+		st.Cache(passphraseCacheKey{t.ID()}, passphrase)
+	}
+	chg.AddTask(t)
+	return chg, nil
+}
+
+// RotateRecoveryKeys creates a change replacing the recovery keys of the
+// encrypted volumes with newly generated ones.
+func RotateRecoveryKeys(st *state.State) (*state.Change, error) {
+	return newFDEKeysChange(st, "rotate-recovery-keys", i18n.G("Rotate recovery keys"), "")
+}
+
+// AddPassphrase creates a change adding a keyslot unlocked by the given
+// passphrase to each of the encrypted volumes, as an alternative to the TPM
+// sealed keys and the recovery keys. The passphrase is kept in memory only.
+func AddPassphrase(st *state.State, passphrase string) (*state.Change, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("cannot use an empty passphrase")
+	}
+	return newFDEKeysChange(st, "add-passphrase", i18n.G("Add passphrase to encrypted volumes"), passphrase)
+}
+
+// RemovePassphrase creates a change removing the keyslot unlocked by the given
+// passphrase from each of the encrypted volumes. The passphrase is kept in
+// memory only.
+func RemovePassphrase(st *state.State, passphrase string) (*state.Change, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("cannot use an empty passphrase")
+	}
+	return newFDEKeysChange(st, "remove-passphrase", i18n.G("Remove passphrase from encrypted volumes"), passphrase)
+}
+
+// rotateRecoveryKeys replaces the recovery keys of the encrypted volumes with
+// newly generated ones. For each volume, the new key is stored and added to
+// the volume first, the old key is removed only once the new key has been
+// verified to unlock the volume.
+func rotateRecoveryKeys() error {
+	vols, unlock, err := lockedEncryptedVolumes()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, vol := range vols {
+		if err := rotateRecoveryKey(vol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rotateRecoveryKey(vol *encryptedVolume) error {
+	newKeyFile := vol.recoveryKeyFile + ".new"
+	if err := finishRecoveryKeyRotation(vol, newKeyFile); err != nil {
+		return err
+	}
+
+	oldKey, err := secboot.RecoveryKeyFromFile(vol.recoveryKeyFile)
+	if err != nil {
+		return err
+	}
+	newKey, err := secboot.NewRecoveryKey()
+	if err != nil {
+		return fmt.Errorf("cannot create recovery key: %v", err)
+	}
+	// store the new key before it is added, so that it cannot get lost
+	if err := newKey.Save(newKeyFile); err != nil {
+		return fmt.Errorf("cannot store new recovery key of %s: %v", vol.name, err)
+	}
+	if err := secbootAddLUKS2ContainerKey(vol.node, oldKey[:], newKey[:]); err != nil {
+		os.Remove(newKeyFile)
+		return fmt.Errorf("cannot add new recovery key to %s: %v", vol.name, err)
+	}
+	if err := secbootCheckLUKS2ContainerKey(vol.node, newKey[:]); err != nil {
+		// the old key is kept, the new one is dropped the next time
+		return fmt.Errorf("cannot verify new recovery key of %s: %v", vol.name, err)
+	}
+	if err := secbootRemoveLUKS2ContainerKey(vol.node, oldKey[:]); err != nil {
+		return fmt.Errorf("cannot remove old recovery key of %s: %v", vol.name, err)
+	}
+	if err := os.Rename(newKeyFile, vol.recoveryKeyFile); err != nil {
+		return err
+	}
+	logger.Noticef("rotated recovery key of %s", vol.name)
+	return nil
+}
+
+// finishRecoveryKeyRotation deals with a new recovery key left behind by an
+// interrupted rotation. The rotation is completed if the old key has been
+// removed from the volume already, otherwise the new key is dropped.
+func finishRecoveryKeyRotation(vol *encryptedVolume, newKeyFile string) error {
+	if !osutil.FileExists(newKeyFile) {
+		return nil
+	}
+	newKey, err := secboot.RecoveryKeyFromFile(newKeyFile)
+	if err != nil {
+		return err
+	}
+	oldKey, err := secboot.RecoveryKeyFromFile(vol.recoveryKeyFile)
+	if err != nil {
+		return err
+	}
+	newKeyUsable := secbootCheckLUKS2ContainerKey(vol.node, newKey[:]) == nil
+	if err := secbootCheckLUKS2ContainerKey(vol.node, oldKey[:]); err != nil {
+		if !newKeyUsable {
+			return fmt.Errorf("cannot unlock %s with any of the recovery keys", vol.name)
+		}
+		logger.Noticef("completing interrupted rotation of recovery key of %s", vol.name)
+		return os.Rename(newKeyFile, vol.recoveryKeyFile)
+	}
+	if newKeyUsable {
+		logger.Noticef("dropping recovery key of %s from interrupted rotation", vol.name)
+		if err := secbootRemoveLUKS2ContainerKey(vol.node, newKey[:]); err != nil {
+			return fmt.Errorf("cannot remove stale recovery key of %s: %v", vol.name, err)
+		}
+	}
+	return os.Remove(newKeyFile)
+}
+
+// addPassphrase adds a keyslot unlocked by the given passphrase to each of the
+// encrypted volumes and records it on ubuntu-seed, so that the passphrase is
+// asked for when booting.
+func addPassphrase(passphrase string) error {
+	vols, unlock, err := lockedEncryptedVolumes()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, vol := range vols {
+		// only one passphrase is tracked for booting
+		if osutil.FileExists(passphraseMarker(vol)) {
+			return fmt.Errorf("cannot add passphrase to %s: a passphrase was added already", vol.name)
+		}
+		if secbootCheckLUKS2ContainerKey(vol.node, []byte(passphrase)) == nil {
+			return fmt.Errorf("cannot add passphrase to %s: passphrase is already in use", vol.name)
+		}
+	}
+	var done []*encryptedVolume
+	undo := func() {
+		for _, vol := range done {
+			if err := secbootRemoveLUKS2ContainerKey(vol.node, []byte(passphrase)); err != nil {
+				logger.Noticef("cannot remove passphrase from %s: %v", vol.name, err)
+			}
+			os.Remove(passphraseMarker(vol))
+		}
+	}
+	for _, vol := range vols {
+		rkey, err := secboot.RecoveryKeyFromFile(vol.recoveryKeyFile)
+		if err != nil {
+			undo()
+			return err
+		}
+		if err := secbootAddLUKS2ContainerKey(vol.node, rkey[:], []byte(passphrase)); err != nil {
+			undo()
+			return fmt.Errorf("cannot add passphrase to %s: %v", vol.name, err)
+		}
+		done = append(done, vol)
+		if err := secbootCheckLUKS2ContainerKey(vol.node, []byte(passphrase)); err != nil {
+			undo()
+			return fmt.Errorf("cannot verify passphrase of %s: %v", vol.name, err)
+		}
+		if err := os.MkdirAll(boot.InitramfsSeedEncryptionKeyDir, 0755); err != nil {
+			undo()
+			return err
+		}
+		if err := osutil.AtomicWriteFile(passphraseMarker(vol), nil, 0644, 0); err != nil {
+			undo()
+			return fmt.Errorf("cannot record passphrase of %s: %v", vol.name, err)
+		}
+	}
+	return nil
+}
+
+// removePassphrase removes the keyslot unlocked by the given passphrase from
+// each of the encrypted volumes. The recovery key of a volume is verified to
+// still unlock it before the passphrase is removed.
+func removePassphrase(passphrase string) error {
+	vols, unlock, err := lockedEncryptedVolumes()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, vol := range vols {
+		rkey, err := secboot.RecoveryKeyFromFile(vol.recoveryKeyFile)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(rkey[:], []byte(passphrase)) {
+			return fmt.Errorf("cannot remove the recovery key of %s as a passphrase", vol.name)
+		}
+		if err := secbootCheckLUKS2ContainerKey(vol.node, rkey[:]); err != nil {
+			return fmt.Errorf("cannot remove passphrase from %s: recovery key is not usable: %v", vol.name, err)
+		}
+		if err := secbootRemoveLUKS2ContainerKey(vol.node, []byte(passphrase)); err != nil {
+			return fmt.Errorf("cannot remove passphrase from %s: %v", vol.name, err)
+		}
+		if err := os.Remove(passphraseMarker(vol)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var (
+	bootCommandLineAppend       = boot.CommandLineAppend
+	bootSetTryCommandLineAppend = boot.SetTryCommandLineAppend
+)
+
+const (
+	kernelCmdlineAppendOpt = "system.kernel.cmdline-append"
+	// kernelCmdlineAppendTriedKey is the state key carrying the kernel
+	// command line arguments that were last tried, kept until the outcome
+	// is known
+	kernelCmdlineAppendTriedKey = "kernel-cmdline-append-tried"
+)
+
+// wantedKernelCmdlineAppend returns the kernel command line arguments to
+// append as set in the system options, in their canonical form.
+func wantedKernelCmdlineAppend(st *state.State) (string, error) {
+	var cmdline string
+	tr := config.NewTransaction(st)
+	if err := tr.GetMaybe("core", kernelCmdlineAppendOpt, &cmdline); err != nil {
+		return "", err
+	}
+	args, err := osutil.KernelCommandLineSplit(cmdline)
+	if err != nil {
+		return "", fmt.Errorf("cannot use %q: %v", kernelCmdlineAppendOpt, err)
+	}
+	return strings.Join(args, " "), nil
+}
+
+func (m *DeviceManager) doUpdateKernelCommandLine(t *state.Task, _ *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var cmdline string
+	if err := t.Get("cmdline-append", &cmdline); err != nil {
+		return err
+	}
+	deviceCtx, err := DeviceCtx(st, t, nil)
+	if err != nil {
+		return err
+	}
+
+	// record the attempt first, so that the outcome can be checked once
+	// the system comes back, whether the boot succeeded or not
+	st.Set(kernelCmdlineAppendTriedKey, cmdline)
+
+	// do not release the state lock, the modeenv is implicitly guarded by
+	// it
+	rebootRequired, err := bootSetTryCommandLineAppend(deviceCtx, cmdline)
+	if err != nil {
+		return fmt.Errorf("cannot update the kernel command line: %v", err)
+	}
+
+	t.SetStatus(state.DoneStatus)
+
+	if rebootRequired {
+		st.RequestRestart(state.RestartSystem)
+	}
+	return nil
+}
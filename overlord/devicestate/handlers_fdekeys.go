@@ -0,0 +1,62 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate
+
+import (
+	"fmt"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// taskPassphrase returns the passphrase used by the task and drops it from the
+// state cache. It is not available anymore once snapd was restarted.
+func taskPassphrase(t *state.Task) (string, error) {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+	passphrase, _ := st.Cached(passphraseCacheKey{t.ID()}).(string)
+	st.Cache(passphraseCacheKey{t.ID()}, nil)
+	if passphrase == "" {
+		return "", fmt.Errorf("cannot use passphrase: it is not available anymore, please retry")
+	}
+	return passphrase, nil
+}
+
+func (m *DeviceManager) doRotateRecoveryKeys(t *state.Task, _ *tomb.Tomb) error {
+	return rotateRecoveryKeys()
+}
+
+func (m *DeviceManager) doAddPassphrase(t *state.Task, _ *tomb.Tomb) error {
+	passphrase, err := taskPassphrase(t)
+	if err != nil {
+		return err
+	}
+	return addPassphrase(passphrase)
+}
+
+func (m *DeviceManager) doRemovePassphrase(t *state.Task, _ *tomb.Tomb) error {
+	passphrase, err := taskPassphrase(t)
+	if err != nil {
+		return err
+	}
+	return removePassphrase(passphrase)
+}
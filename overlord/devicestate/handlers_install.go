@@ -21,6 +21,7 @@ package devicestate
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -41,8 +42,9 @@ import (
 )
 
 var (
-	bootMakeBootable = boot.MakeBootable
-	installRun       = install.Run
+	bootMakeBootable    = boot.MakeBootable
+	installRun          = install.Run
+	installFactoryReset = install.FactoryReset
 
 	sysconfigConfigureTargetSystem = sysconfig.ConfigureTargetSystem
 )
@@ -91,6 +93,18 @@ func writeModel(model *asserts.Model, where string) error {
 }
 
 func (m *DeviceManager) doSetupRunSystem(t *state.Task, _ *tomb.Tomb) error {
+	return m.setupRunSystem(t, false)
+}
+
+func (m *DeviceManager) doFactoryResetRunSystem(t *state.Task, _ *tomb.Tomb) error {
+	return m.setupRunSystem(t, true)
+}
+
+// setupRunSystem creates the partitions and filesystems of the run system and
+// makes it bootable. When doing a factory reset the existing partitions are
+// reused, and the device identity kept in ubuntu-save is carried over to the
+// new system if it was available.
+func (m *DeviceManager) setupRunSystem(t *state.Task, factoryReset bool) error {
 	st := t.State()
 	st.Lock()
 	defer st.Unlock()
@@ -159,15 +173,43 @@ func (m *DeviceManager) doSetupRunSystem(t *state.Task, _ *tomb.Tomb) error {
 	}
 
 	var installedSystem *install.InstalledSystemSideData
-	// run the create partition code
-	logger.Noticef("create and deploy partitions")
-	func() {
-		st.Unlock()
-		defer st.Lock()
-		installedSystem, err = installRun(model, gadgetDir, "", bopts, installObserver)
-	}()
-	if err != nil {
-		return fmt.Errorf("cannot install system: %v", err)
+	if factoryReset {
+		identityBackupDir := filepath.Join(dirs.SnapRunDir, "factory-reset/device")
+		haveIdentity := false
+		if osutil.FileExists(factoryResetDiscardIdentityMarker()) {
+			logger.Noticef("device identity will not be preserved as requested")
+		} else {
+			haveIdentity, err = backupDeviceIdentity(identityBackupDir)
+			if err != nil {
+				return fmt.Errorf("cannot back up device identity: %v", err)
+			}
+		}
+		// reset the existing partitions
+		logger.Noticef("reset partitions")
+		func() {
+			st.Unlock()
+			defer st.Lock()
+			installedSystem, err = installFactoryReset(model, gadgetDir, "", bopts, installObserver)
+		}()
+		if err != nil {
+			return fmt.Errorf("cannot perform factory reset: %v", err)
+		}
+		if haveIdentity {
+			if err := restoreDeviceIdentity(identityBackupDir); err != nil {
+				return fmt.Errorf("cannot restore device identity: %v", err)
+			}
+		}
+	} else {
+		// run the create partition code
+		logger.Noticef("create and deploy partitions")
+		func() {
+			st.Unlock()
+			defer st.Lock()
+			installedSystem, err = installRun(model, gadgetDir, "", bopts, installObserver)
+		}()
+		if err != nil {
+			return fmt.Errorf("cannot install system: %v", err)
+		}
 	}
 
 	if trustedInstallObserver != nil {
@@ -239,6 +281,83 @@ func (m *DeviceManager) doSetupRunSystem(t *state.Task, _ *tomb.Tomb) error {
 	return nil
 }
 
+// factoryResetDiscardIdentityMarker returns the path of the marker in
+// ubuntu-save requesting the next factory reset to not preserve the device
+// identity.
+func factoryResetDiscardIdentityMarker() string {
+	return filepath.Join(boot.InitramfsUbuntuSaveDir, "device", "factory-reset-discard-identity")
+}
+
+// SetFactoryResetPreservesIdentity sets whether the next factory reset
+// preserves the device identity kept in ubuntu-save, which is the default.
+// The choice is recorded in ubuntu-save itself, as the state is not
+// available in factory-reset mode.
+func SetFactoryResetPreservesIdentity(preserve bool) error {
+	marker := factoryResetDiscardIdentityMarker()
+	if preserve {
+		if err := os.Remove(marker); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if !osutil.IsDirectory(filepath.Dir(marker)) {
+		// there is no identity to preserve
+		return nil
+	}
+	return osutil.AtomicWriteFile(marker, nil, 0600, 0)
+}
+
+// backupDeviceIdentity copies the device identity, that is the device key
+// and the save assertion database, from ubuntu-save to the given directory.
+// The FDE data is not copied as it is re-created with fresh keys. It returns
+// false if ubuntu-save or its device directory is not available.
+func backupDeviceIdentity(backupDir string) (bool, error) {
+	deviceDir := filepath.Join(boot.InitramfsUbuntuSaveDir, "device")
+	if !osutil.IsDirectory(deviceDir) {
+		logger.Noticef("ubuntu-save is not available, device identity will not be preserved")
+		return false, nil
+	}
+	if err := os.RemoveAll(backupDir); err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return false, err
+	}
+	if err := copyDirEntries(deviceDir, backupDir, "fde"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// restoreDeviceIdentity copies back the device identity from the given
+// directory to the new ubuntu-save and removes the backup.
+func restoreDeviceIdentity(backupDir string) error {
+	deviceDir := filepath.Join(boot.InitramfsUbuntuSaveDir, "device")
+	if err := os.MkdirAll(deviceDir, 0755); err != nil {
+		return err
+	}
+	if err := copyDirEntries(backupDir, deviceDir, ""); err != nil {
+		return err
+	}
+	return os.RemoveAll(backupDir)
+}
+
+func copyDirEntries(src, dst, skip string) error {
+	entries, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == skip {
+			continue
+		}
+		if err := osutil.CopyFile(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()), osutil.CopyFlagPreserveAll); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // writeMarkers writes markers containing the same secret to pair data and save.
 func writeMarkers() error {
 	// ensure directory for markers exists
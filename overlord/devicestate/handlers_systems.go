@@ -0,0 +1,163 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+var createSystemForModelFromValidatedSnaps = createSystemForModelFromValidatedSnapsImpl
+
+// recoverySystemSetup carries the details of a recovery system being created.
+type recoverySystemSetup struct {
+	// Label of the recovery system.
+	Label string `json:"label"`
+	// Directory of the recovery system.
+	Directory string `json:"directory"`
+	// Snaps are the names of the model snaps the system is made of.
+	Snaps []string `json:"snaps,omitempty"`
+	// NewSnapFiles are the snap files that were added to the seed while
+	// creating the system, and must be removed on undo.
+	NewSnapFiles []string `json:"new-snap-files,omitempty"`
+}
+
+func taskRecoverySystemSetup(t *state.Task) (*recoverySystemSetup, error) {
+	var setup recoverySystemSetup
+	if err := t.Get("recovery-system-setup", &setup); err != nil {
+		return nil, err
+	}
+	return &setup, nil
+}
+
+func recoverySystemAffectedSnaps(t *state.Task) ([]string, error) {
+	setup, err := taskRecoverySystemSetup(t)
+	if err != nil {
+		return nil, err
+	}
+	return setup.Snaps, nil
+}
+
+func init() {
+	snapstate.AddAffectedSnapsByAttr("recovery-system-setup", recoverySystemAffectedSnaps)
+}
+
+func removeRecoverySystemFiles(setup *recoverySystemSetup) error {
+	for _, fn := range setup.NewSnapFiles {
+		if err := os.Remove(fn); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot remove snap file %q: %v", fn, err)
+		}
+	}
+	if err := os.RemoveAll(setup.Directory); err != nil {
+		return fmt.Errorf("cannot remove recovery system %q: %v", setup.Label, err)
+	}
+	return nil
+}
+
+func (m *DeviceManager) doCreateRecoverySystem(t *state.Task, _ *tomb.Tomb) (err error) {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	deviceCtx, err := DeviceCtx(st, t, nil)
+	if err != nil {
+		return fmt.Errorf("cannot get device context: %v", err)
+	}
+	setup, err := taskRecoverySystemSetup(t)
+	if err != nil {
+		return err
+	}
+
+	getInfo := func(name string) (*snap.Info, bool, error) {
+		info, err := snapstate.CurrentInfo(st, name)
+		if err != nil {
+			if _, ok := err.(*snap.NotInstalledError); ok {
+				return nil, false, nil
+			}
+			return nil, false, err
+		}
+		return info, true, nil
+	}
+	copySnapFile := func(src, dst string) error {
+		// keep track of the new snap file upfront, so that it is
+		// removed on undo even if the copy gets interrupted
+		setup.NewSnapFiles = append(setup.NewSnapFiles, dst)
+		t.Set("recovery-system-setup", setup)
+		// copying a snap takes a while, do not keep the state locked,
+		// the model snaps are protected from changes by the conflict
+		// checks
+		st.Unlock()
+		defer st.Lock()
+		// the file is copied under a temporary name and renamed once
+		// complete, so that an interrupted copy is not mistaken for
+		// a snap already present in the seed
+		return osutil.AtomicWriteFileCopy(dst, src, 0)
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		// the task is not undone when it fails, clean up here
+		if cleanupErr := removeRecoverySystemFiles(setup); cleanupErr != nil {
+			logger.Noticef("cannot clean up recovery system %q: %v", setup.Label, cleanupErr)
+		}
+	}()
+
+	db := assertstate.DB(st)
+	if err := createSystemForModelFromValidatedSnaps(deviceCtx.Model(), setup.Label, boot.InitramfsUbuntuSeedDir, db, getInfo, copySnapFile); err != nil {
+		return fmt.Errorf("cannot create recovery system %q: %v", setup.Label, err)
+	}
+
+	if err := boot.AddRecoverySystem(deviceCtx, setup.Label); err != nil {
+		return fmt.Errorf("cannot add recovery system %q: %v", setup.Label, err)
+	}
+	return nil
+}
+
+func (m *DeviceManager) undoCreateRecoverySystem(t *state.Task, _ *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	deviceCtx, err := DeviceCtx(st, t, nil)
+	if err != nil {
+		return fmt.Errorf("cannot get device context: %v", err)
+	}
+	setup, err := taskRecoverySystemSetup(t)
+	if err != nil {
+		return err
+	}
+
+	if err := boot.DropRecoverySystem(deviceCtx, setup.Label); err != nil {
+		return fmt.Errorf("cannot drop recovery system %q: %v", setup.Label, err)
+	}
+	return removeRecoverySystemFiles(setup)
+}
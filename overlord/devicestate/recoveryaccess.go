@@ -0,0 +1,101 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package devicestate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/systemd"
+)
+
+const recoverySSHService = "ssh.service"
+
+// ensureRecoveryRemoteAccess starts SSH in recover mode once users were
+// created, typically from system-user assertions imported by snap
+// auto-import, when the model allows remote access to the recovery system.
+// Recover mode is ephemeral, so are the users and the SSH configuration.
+func (m *DeviceManager) ensureRecoveryRemoteAccess() error {
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	if m.recoveryRemoteAccessRan {
+		return nil
+	}
+	if m.SystemMode() != "recover" {
+		m.recoveryRemoteAccessRan = true
+		return nil
+	}
+
+	var seeded bool
+	err := m.state.Get("seeded", &seeded)
+	if err != nil && err != state.ErrNoState {
+		return err
+	}
+	if !seeded {
+		// try again once seeded
+		return nil
+	}
+
+	model, err := m.Model()
+	if err == state.ErrNoState {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !model.RecoveryRemoteAccess() {
+		m.recoveryRemoteAccessRan = true
+		return nil
+	}
+
+	users, err := auth.Users(m.state)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		// try again once users were created
+		return nil
+	}
+
+	// the canary is there unless SSH was enabled on the run system, in
+	// which case ubuntu-data was trusted and its configuration copied
+	sshCanary := filepath.Join(dirs.GlobalRootDir, "/etc/ssh/sshd_not_to_be_run")
+	if err := os.Remove(sshCanary); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot enable SSH in recover mode: %v", err)
+	}
+	sysd := systemd.New(systemd.SystemMode, progress.Null)
+	// ssh.service may have been masked by earlier versions of snapd,
+	// ignore errors as in core configuration
+	sysd.Unmask(recoverySSHService)
+	if err := sysd.Start(recoverySSHService); err != nil {
+		return fmt.Errorf("cannot start SSH in recover mode: %v", err)
+	}
+	logger.Noticef("started SSH in recover mode for %d users", len(users))
+
+	m.recoveryRemoteAccessRan = true
+	return nil
+}
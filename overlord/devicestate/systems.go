@@ -21,11 +21,19 @@ package devicestate
 
 import (
 	"fmt"
+	"path/filepath"
 
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/seed"
+	"github.com/snapcore/snapd/seed/seedwriter"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
 )
 
 func checkSystemRequestConflict(st *state.State, systemLabel string) error {
@@ -115,8 +123,8 @@ func currentSystemForMode(st *state.State, mode string) (*currentSystem, error)
 	case "run":
 		actions = currentSystemActions
 		system, err = currentSeededSystem(st)
-	case "install":
-		// there is no current system for install mode
+	case "install", "factory-reset":
+		// there is no current system for install or factory-reset mode
 		return nil, nil
 	case "recover":
 		actions = recoverSystemActions
@@ -176,3 +184,163 @@ func seededSystemFromModeenv() (*seededSystem, error) {
 	}
 	return seededSys, nil
 }
+
+type getSnapInfoFunc func(name string) (info *snap.Info, present bool, err error)
+
+// copySnapFileFunc copies a snap file that is new to the seed. The
+// destination must only appear once the copy is complete.
+type copySnapFileFunc func(src, dst string) error
+
+// modelSnapNames returns the names of the snaps a recovery system for the
+// given model is made of, essential snaps first.
+func modelSnapNames(model *asserts.Model) []string {
+	names := make([]string, 0, len(model.EssentialSnaps())+len(model.SnapsWithoutEssential())+1)
+	for _, sn := range model.EssentialSnaps() {
+		names = append(names, sn.SnapName())
+	}
+	// snapd is implicitly needed
+	if !strutil.ListContains(names, "snapd") {
+		names = append(names, "snapd")
+	}
+	for _, sn := range model.SnapsWithoutEssential() {
+		names = append(names, sn.SnapName())
+	}
+	return names
+}
+
+// createSystemForModelFromValidatedSnapsImpl writes a new recovery system with
+// the given label under seedDir, using the currently installed snaps of the
+// model as obtained with getInfo and the assertions from db. Snap files that
+// are not yet present in the seed are copied with copySnapFile, so that the
+// caller can keep track of them and remove them if something goes wrong
+// later. The recovery system is also made bootable.
+func createSystemForModelFromValidatedSnapsImpl(model *asserts.Model, label, seedDir string, db asserts.RODatabase, getInfo getSnapInfoFunc, copySnapFile copySnapFileFunc) error {
+	if model.Grade() == asserts.ModelGradeUnset {
+		return fmt.Errorf("cannot create a system for a non Ubuntu Core 20 model")
+	}
+
+	logger.Noticef("creating recovery system with label %q for %q", label, model.Model())
+
+	w, err := seedwriter.New(model, &seedwriter.Options{
+		SeedDir: seedDir,
+		Label:   label,
+	})
+	if err != nil {
+		return err
+	}
+
+	optional := make(map[string]bool)
+	for _, sn := range model.SnapsWithoutEssential() {
+		if sn.Presence == "optional" {
+			optional[sn.SnapName()] = true
+		}
+	}
+	names := modelSnapNames(model)
+	optionsSnaps := make([]*seedwriter.OptionsSnap, 0, len(names))
+	infos := make(map[string]*snap.Info, len(names))
+	for _, name := range names {
+		info, present, err := getInfo(name)
+		if err != nil {
+			return fmt.Errorf("cannot obtain information about snap %q: %v", name, err)
+		}
+		if !present {
+			if optional[name] {
+				continue
+			}
+			return fmt.Errorf("required snap %q is not installed", name)
+		}
+		optionsSnaps = append(optionsSnaps, &seedwriter.OptionsSnap{Path: info.MountFile()})
+		infos[info.MountFile()] = info
+	}
+
+	if err := w.SetOptionsSnaps(optionsSnaps); err != nil {
+		return err
+	}
+
+	newFetcher := func(save func(asserts.Assertion) error) asserts.Fetcher {
+		fromDB := func(ref *asserts.Ref) (asserts.Assertion, error) {
+			return ref.Resolve(db.Find)
+		}
+		return asserts.NewFetcher(db, fromDB, save)
+	}
+	f, err := w.Start(db, newFetcher)
+	if err != nil {
+		return err
+	}
+
+	localSnaps, err := w.LocalSnaps()
+	if err != nil {
+		return err
+	}
+	for _, sn := range localSnaps {
+		info, ok := infos[sn.Path]
+		if !ok {
+			return fmt.Errorf("internal error: no snap information for %q", sn.Path)
+		}
+		_, aRefs, err := seedwriter.DeriveSideInfo(sn.Path, f, db)
+		if err != nil && !asserts.IsNotFound(err) {
+			return err
+		}
+		if err := w.SetInfo(sn, info); err != nil {
+			return err
+		}
+		sn.ARefs = aRefs
+	}
+
+	if err := w.InfoDerived(); err != nil {
+		return err
+	}
+
+	for {
+		toDownload, err := w.SnapsToDownload()
+		if err != nil {
+			return err
+		}
+		if len(toDownload) > 0 {
+			names := make([]string, 0, len(toDownload))
+			for _, sn := range toDownload {
+				names = append(names, sn.SnapName())
+			}
+			return fmt.Errorf("cannot create a recovery system with snaps that are not installed: %s", strutil.Quoted(names))
+		}
+		complete, err := w.Downloaded()
+		if err != nil {
+			return err
+		}
+		if complete {
+			break
+		}
+	}
+
+	copySnap := func(name, src, dst string) error {
+		if osutil.FileExists(dst) {
+			// already in the seed, snaps are shared between
+			// recovery systems, the file is complete as copies
+			// are atomic
+			return nil
+		}
+		return copySnapFile(src, dst)
+	}
+	if err := w.SeedSnaps(copySnap); err != nil {
+		return err
+	}
+	if err := w.WriteMeta(); err != nil {
+		return err
+	}
+
+	bootSnaps, err := w.BootSnaps()
+	if err != nil {
+		return err
+	}
+	bootWith := &boot.RecoverySystemBootableSet{}
+	for _, sn := range bootSnaps {
+		if sn.Info.Type() == snap.TypeKernel {
+			bootWith.Kernel = sn.Info
+			bootWith.KernelPath = sn.Path
+		}
+	}
+	if err := boot.MakeRecoverySystemBootable(seedDir, filepath.Join("systems", label), bootWith); err != nil {
+		return fmt.Errorf("cannot make recovery system %q bootable: %v", label, err)
+	}
+	return nil
+}
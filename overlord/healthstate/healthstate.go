@@ -50,10 +50,22 @@ func init() {
 
 func Hook(st *state.State, snapName string, snapRev snap.Revision) *state.Task {
 	summary := fmt.Sprintf("Run health check of %q snap", snapName)
+	return hookTask(st, summary, snapName, snapRev, "check-health")
+}
+
+// BootHook returns a task running the check-boot-health hook of the snap,
+// the hook reports the health of the snap after booting a new kernel or base
+// using snapctl set-health.
+func BootHook(st *state.State, snapName string, snapRev snap.Revision) *state.Task {
+	summary := fmt.Sprintf("Run boot health check of %q snap", snapName)
+	return hookTask(st, summary, snapName, snapRev, "check-boot-health")
+}
+
+func hookTask(st *state.State, summary, snapName string, snapRev snap.Revision, hook string) *state.Task {
 	hooksup := &hookstate.HookSetup{
 		Snap:     snapName,
 		Revision: snapRev,
-		Hook:     "check-health",
+		Hook:     hook,
 		Optional: true,
 		Timeout:  checkTimeout,
 	}
@@ -98,7 +110,7 @@ type HealthState struct {
 }
 
 func Init(hookManager *hookstate.HookManager) {
-	hookManager.Register(regexp.MustCompile("^check-health$"), newHealthHandler)
+	hookManager.Register(regexp.MustCompile("^check-(boot-)?health$"), newHealthHandler)
 }
 
 func newHealthHandler(ctx *hookstate.Context) hookstate.Handler {
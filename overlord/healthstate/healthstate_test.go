@@ -102,18 +102,30 @@ const (
 )
 
 func (s *healthSuite) TestHealthNoHook(c *check.C) {
-	s.testHealth(c, noHook)
+	s.testHealth(c, noHook, "check-health")
 }
 
 func (s *healthSuite) TestHealthFailingHook(c *check.C) {
-	s.testHealth(c, badHook)
+	s.testHealth(c, badHook, "check-health")
 }
 
 func (s *healthSuite) TestHealth(c *check.C) {
-	s.testHealth(c, goodHook)
+	s.testHealth(c, goodHook, "check-health")
 }
 
-func (s *healthSuite) testHealth(c *check.C, cond healthHookTestCondition) {
+func (s *healthSuite) TestBootHealthNoHook(c *check.C) {
+	s.testHealth(c, noHook, "check-boot-health")
+}
+
+func (s *healthSuite) TestBootHealthFailingHook(c *check.C) {
+	s.testHealth(c, badHook, "check-boot-health")
+}
+
+func (s *healthSuite) TestBootHealth(c *check.C) {
+	s.testHealth(c, goodHook, "check-boot-health")
+}
+
+func (s *healthSuite) testHealth(c *check.C, cond healthHookTestCondition, hook string) {
 	var cmd *testutil.MockCmd
 	switch cond {
 	case badHook:
@@ -123,14 +135,19 @@ func (s *healthSuite) testHealth(c *check.C, cond healthHookTestCondition) {
 	}
 
 	if cond != noHook {
-		hookFn := filepath.Join(s.info.MountDir(), "meta", "hooks", "check-health")
+		hookFn := filepath.Join(s.info.MountDir(), "meta", "hooks", hook)
 		c.Assert(os.MkdirAll(filepath.Dir(hookFn), 0755), check.IsNil)
 		// the hook won't actually be called, but needs to exist
 		c.Assert(ioutil.WriteFile(hookFn, nil, 0755), check.IsNil)
 	}
 
 	s.state.Lock()
-	task := healthstate.Hook(s.state, "test-snap", snap.R(42))
+	var task *state.Task
+	if hook == "check-boot-health" {
+		task = healthstate.BootHook(s.state, "test-snap", snap.R(42))
+	} else {
+		task = healthstate.Hook(s.state, "test-snap", snap.R(42))
+	}
 	change := s.state.NewChange("kind", "summary")
 	change.AddTask(task)
 	s.state.Unlock()
@@ -145,7 +162,7 @@ func (s *healthSuite) testHealth(c *check.C, cond healthHookTestCondition) {
 
 	c.Check(hooksup, check.DeepEquals, hookstate.HookSetup{
 		Snap:        "test-snap",
-		Hook:        "check-health",
+		Hook:        hook,
 		Revision:    snap.R(42),
 		Optional:    true,
 		Timeout:     time.Second,
@@ -189,7 +206,7 @@ func (s *healthSuite) testHealth(c *check.C, cond healthHookTestCondition) {
 		}
 		com := check.Commentf("%s ⩼ %s ⩼ %s", t0.Format(time.StampNano), health.Timestamp.Format(time.StampNano), tf.Format(time.StampNano))
 		c.Check(health.Timestamp.After(t0) && health.Timestamp.Before(tf), check.Equals, true, com)
-		c.Check(cmd.Calls(), check.DeepEquals, [][]string{{"snap", "run", "--hook", "check-health", "-r", "42", "test-snap"}})
+		c.Check(cmd.Calls(), check.DeepEquals, [][]string{{"snap", "run", "--hook", hook, "-r", "42", "test-snap"}})
 	} else {
 		// no script -> no health
 		c.Assert(err, check.Equals, state.ErrNoState)
@@ -40,6 +40,12 @@ optionally provide a 'check-health' hook to better manage these calls, which is
 then called periodically and with increased frequency while the snap is
 "unhealthy". Any health regression will issue a warning to the user.
 
+On Ubuntu Core, a snap can also provide a 'check-boot-health' hook, which is
+called after booting a new kernel or base. The boot is only considered
+successful once the snap reports being healthy; if it reports an error or is
+blocked, or is still waiting after a grace period, the system reboots into the
+previous kernel and base.
+
 Note: the health is of the snap only, not of the apps it contains; it’s up to
       the snap developer to determine how the health of the individual apps is
       reflected in the overall health of the snap.
@@ -0,0 +1,105 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/snapstate"
+)
+
+var timeNow = time.Now
+
+// expiredConnRetry is how long to wait before trying again to
+// disconnect an expired connection that conflicted with another change.
+const expiredConnRetry = time.Minute
+
+// disconnectExpired creates changes disconnecting the time-limited
+// connections that expired, and a warning for each of them, then
+// schedules an Ensure for when the next connection expires.
+func (m *InterfaceManager) disconnectExpired() error {
+	st := m.state
+	st.Lock()
+	defer st.Unlock()
+
+	conns, err := getConns(st)
+	if err != nil {
+		return err
+	}
+
+	now := timeNow()
+	var next time.Time
+	scheduleAt := func(t time.Time) {
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+	for id, cstate := range conns {
+		if cstate.Expiry == nil || cstate.Undesired || cstate.HotplugGone {
+			continue
+		}
+		if cstate.Expiry.After(now) {
+			scheduleAt(*cstate.Expiry)
+			continue
+		}
+
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return err
+		}
+		conn, err := m.repo.Connection(connRef)
+		if err != nil {
+			// the plug or slot is not active, e.g. the snap is
+			// disabled, the connection is removed once it is back
+			continue
+		}
+		affected := []string{connRef.PlugRef.Snap, connRef.SlotRef.Snap}
+		if err := snapstate.CheckChangeConflictMany(st, affected, ""); err != nil {
+			if _, ok := err.(*snapstate.ChangeConflictError); ok {
+				// this includes a disconnect change created earlier
+				// and still in progress
+				scheduleAt(now.Add(expiredConnRetry))
+				continue
+			}
+			return err
+		}
+		ts, err := disconnectTasks(st, conn, disconnectOpts{})
+		if err != nil {
+			return err
+		}
+		summary := fmt.Sprintf(i18n.G("Disconnect %s:%s from %s:%s after the connection expired"),
+			connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
+		chg := st.NewChange("disconnect-snap", summary)
+		chg.AddAll(ts)
+		chg.Set("snap-names", affected)
+		chg.Set("api-data", map[string]interface{}{"snap-names": affected})
+		st.Warnf("connection %s expired on %s and is being disconnected", connRef.ID(), cstate.Expiry.Format(time.RFC3339))
+		logger.Noticef("Disconnecting expired connection %s", connRef.ID())
+	}
+
+	if !next.IsZero() {
+		st.EnsureBefore(next.Sub(now))
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+func (s *interfaceManagerSuite) TestConnectUntil(c *C) {
+	s.MockModel(c, nil)
+	s.mockIfaces(c, &ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	_ = s.manager(c)
+
+	expiry := time.Date(2026, 10, 18, 16, 0, 0, 0, time.UTC)
+	restore := ifacestate.MockTimeNow(func() time.Time { return expiry.Add(-time.Hour) })
+	defer restore()
+
+	s.state.Lock()
+	ts, err := ifacestate.ConnectUntil(s.state, "consumer", "plug", "producer", "slot", expiry)
+	c.Assert(err, IsNil)
+	chg := s.state.NewChange("connect", "...")
+	chg.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Err(), IsNil)
+
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns["consumer:plug producer:slot"].(map[string]interface{})["expiry"], Equals, "2026-10-18T16:00:00Z")
+
+	connStates, err := ifacestate.ConnectionStates(s.state)
+	c.Assert(err, IsNil)
+	c.Check(connStates["consumer:plug producer:slot"].Expiry.Equal(expiry), Equals, true)
+
+	// the connection has not expired yet
+	c.Check(s.state.Changes(), HasLen, 1)
+}
+
+func (s *interfaceManagerSuite) TestConnectUntilExistingConnection(c *C) {
+	s.mockExpiringConnection(c, "2026-10-18T16:00:00Z")
+	_ = s.manager(c)
+
+	expiry := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	s.state.Lock()
+	defer s.state.Unlock()
+	_, err := ifacestate.ConnectUntil(s.state, "consumer", "plug", "producer", "slot", expiry)
+	c.Assert(err, FitsTypeOf, &ifacestate.ErrAlreadyConnected{})
+
+	// the expiry was extended
+	connStates, err := ifacestate.ConnectionStates(s.state)
+	c.Assert(err, IsNil)
+	c.Check(connStates["consumer:plug producer:slot"].Expiry.Equal(expiry), Equals, true)
+	c.Check(s.state.Changes(), HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestConnectUntilExistingPermanentConnection(c *C) {
+	s.mockExpiringConnection(c, "")
+	_ = s.manager(c)
+
+	expiry := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	s.state.Lock()
+	defer s.state.Unlock()
+	_, err := ifacestate.ConnectUntil(s.state, "consumer", "plug", "producer", "slot", expiry)
+	c.Assert(err, ErrorMatches, `cannot limit the duration of connection consumer:plug producer:slot: already connected without a time limit`)
+
+	connStates, err := ifacestate.ConnectionStates(s.state)
+	c.Assert(err, IsNil)
+	c.Check(connStates["consumer:plug producer:slot"].Expiry.IsZero(), Equals, true)
+}
+
+func (s *interfaceManagerSuite) TestConnectExistingExpiringConnection(c *C) {
+	s.mockExpiringConnection(c, "2026-10-18T16:00:00Z")
+	_ = s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	_, err := ifacestate.Connect(s.state, "consumer", "plug", "producer", "slot")
+	c.Assert(err, FitsTypeOf, &ifacestate.ErrAlreadyConnected{})
+
+	// the connection is now permanent
+	connStates, err := ifacestate.ConnectionStates(s.state)
+	c.Assert(err, IsNil)
+	c.Check(connStates["consumer:plug producer:slot"].Expiry.IsZero(), Equals, true)
+	c.Check(s.state.Changes(), HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) mockExpiringConnection(c *C, expiry string) {
+	s.MockModel(c, nil)
+	s.mockIfaces(c, &ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	conn := map[string]interface{}{"interface": "test"}
+	if expiry != "" {
+		conn["expiry"] = expiry
+	}
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": conn,
+	})
+	s.state.Unlock()
+}
+
+func (s *interfaceManagerSuite) TestEnsureDisconnectsExpiredConnections(c *C) {
+	s.mockExpiringConnection(c, "2026-10-18T16:00:00Z")
+	restore := ifacestate.MockTimeNow(func() time.Time { return time.Date(2026, 10, 18, 16, 0, 1, 0, time.UTC) })
+	defer restore()
+
+	mgr := s.manager(c)
+	c.Assert(mgr.Ensure(), IsNil)
+
+	s.state.Lock()
+	changes := s.state.Changes()
+	c.Assert(changes, HasLen, 1)
+	chg := changes[0]
+	c.Check(chg.Kind(), Equals, "disconnect-snap")
+	c.Check(chg.Summary(), Equals, "Disconnect consumer:plug from producer:slot after the connection expired")
+	var snapNames []string
+	c.Assert(chg.Get("snap-names", &snapNames), IsNil)
+	c.Check(snapNames, DeepEquals, []string{"consumer", "producer"})
+
+	warnings := s.state.AllWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Check(warnings[0].String(), Equals, "connection consumer:plug producer:slot expired on 2026-10-18T16:00:00Z and is being disconnected")
+
+	// the change in progress is not duplicated
+	s.state.Unlock()
+	c.Assert(mgr.Ensure(), IsNil)
+	s.state.Lock()
+	c.Check(s.state.Changes(), HasLen, 1)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Err(), IsNil)
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, HasLen, 0)
+	c.Check(mgr.Repository().Interfaces().Connections, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestEnsureKeepsUnexpiredConnections(c *C) {
+	s.mockExpiringConnection(c, "2026-10-18T16:00:00Z")
+	restore := ifacestate.MockTimeNow(func() time.Time { return time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC) })
+	defer restore()
+
+	mgr := s.manager(c)
+	c.Assert(mgr.Ensure(), IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(s.state.Changes(), HasLen, 0)
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+	c.Check(mgr.Repository().Interfaces().Connections, DeepEquals, []*interfaces.ConnRef{
+		{PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"}, SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"}},
+	})
+}
+
+func (s *interfaceManagerSuite) TestEnsureExpiredConnectionConflict(c *C) {
+	s.mockExpiringConnection(c, "2026-10-18T16:00:00Z")
+	restore := ifacestate.MockTimeNow(func() time.Time { return time.Date(2026, 10, 18, 17, 0, 0, 0, time.UTC) })
+	defer restore()
+
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	other := s.state.NewChange("other-chg", "...")
+	t := s.state.NewTask("link-snap", "...")
+	t.Set("snap-setup", map[string]interface{}{"side-info": map[string]interface{}{"name": "producer"}})
+	other.AddTask(t)
+	s.state.Unlock()
+
+	c.Assert(mgr.Ensure(), IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(s.state.Changes(), HasLen, 1)
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+}
@@ -0,0 +1,108 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"fmt"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/policy"
+	"github.com/snapcore/snapd/overlord/snapstate"
+)
+
+// ConnectionExplanation explains whether a plug can be connected to a
+// slot, manually or automatically, according to the base declaration
+// and the snap declarations.
+type ConnectionExplanation struct {
+	Plug      interfaces.PlugRef `json:"plug"`
+	Slot      interfaces.SlotRef `json:"slot"`
+	Interface string             `json:"interface"`
+	// Connection and AutoConnection explain how the connection and
+	// auto-connection rules were evaluated.
+	Connection     *policy.Explanation `json:"connection"`
+	AutoConnection *policy.Explanation `json:"auto-connection"`
+	// InterfaceAutoConnect is whether the interface itself allows
+	// auto-connecting the plug and slot, besides the declarations.
+	InterfaceAutoConnect bool `json:"interface-auto-connect"`
+	// Notes are further details about the outcome.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// ExplainConnection evaluates the declaration rules for connecting the
+// given plug and slot, with tracing, and reports which rule was used,
+// which constraints matched or failed and from which assertion they
+// came.
+//
+// The state must be locked by the caller.
+func (m *InterfaceManager) ExplainConnection(plugSnap, plugName, slotSnap, slotName string) (*ConnectionExplanation, error) {
+	plugInfo := m.repo.Plug(plugSnap, plugName)
+	if plugInfo == nil {
+		return nil, fmt.Errorf("snap %q has no plug named %q", plugSnap, plugName)
+	}
+	slotInfo := m.repo.Slot(slotSnap, slotName)
+	if slotInfo == nil {
+		return nil, fmt.Errorf("snap %q has no slot named %q", slotSnap, slotName)
+	}
+
+	deviceCtx, err := snapstate.DeviceCtxFromState(m.state, nil)
+	if err != nil {
+		return nil, err
+	}
+	checker, err := newConnectChecker(m.state, deviceCtx)
+	if err != nil {
+		return nil, err
+	}
+	plug := interfaces.NewConnectedPlug(plugInfo, nil, nil)
+	slot := interfaces.NewConnectedSlot(slotInfo, nil, nil)
+	cand, err := checker.candidate(plug, slot)
+	if err != nil {
+		return nil, err
+	}
+
+	ex := &ConnectionExplanation{
+		Plug:           interfaces.PlugRef{Snap: plugSnap, Name: plugName},
+		Slot:           interfaces.SlotRef{Snap: slotSnap, Name: slotName},
+		Interface:      plugInfo.Interface,
+		Connection:     cand.Explain(false),
+		AutoConnection: cand.Explain(true),
+	}
+	if iface := m.repo.Interface(plugInfo.Interface); iface != nil && plugInfo.Interface == slotInfo.Interface {
+		ex.InterfaceAutoConnect = iface.AutoConnect(plugInfo, slotInfo)
+	}
+
+	for _, sn := range []struct {
+		name string
+		decl bool
+	}{
+		{plugSnap, cand.PlugSnapDeclaration != nil},
+		{slotSnap, cand.SlotSnapDeclaration != nil},
+	} {
+		if !sn.decl {
+			ex.Notes = append(ex.Notes, fmt.Sprintf("snap %q has no snap declaration, manual connections are not checked against the rules", sn.name))
+		}
+		if plugSnap == slotSnap {
+			break
+		}
+	}
+	if ex.AutoConnection.Allowed && !ex.InterfaceAutoConnect {
+		ex.Notes = append(ex.Notes, fmt.Sprintf("interface %q does not auto-connect this plug and slot", plugInfo.Interface))
+	}
+	return ex, nil
+}
@@ -0,0 +1,123 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/policy"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+)
+
+func (s *interfaceManagerSuite) mockExplainDeclarations(c *C) {
+	restore := assertstest.MockBuiltinBaseDeclaration([]byte(`
+type: base-declaration
+authority-id: canonical
+series: 16
+slots:
+  test:
+    allow-connection:
+      plug-publisher-id:
+        - $SLOT_PUBLISHER_ID
+    deny-auto-connection: true
+`))
+	s.AddCleanup(restore)
+	s.MockModel(c, nil)
+	s.mockIfaces(c, &ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+}
+
+func (s *interfaceManagerSuite) TestExplainConnection(c *C) {
+	s.mockExplainDeclarations(c)
+	s.MockSnapDecl(c, "consumer", "consumer-publisher", nil)
+	s.mockSnap(c, consumerYaml)
+	s.MockSnapDecl(c, "producer", "producer-publisher", nil)
+	s.mockSnap(c, producerYaml)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	ex, err := mgr.ExplainConnection("consumer", "plug", "producer", "slot")
+	c.Assert(err, IsNil)
+	baseRule := &policy.RuleSource{
+		Assertion: "base-declaration",
+		Side:      "slot",
+		Interface: "test",
+	}
+	c.Check(ex, DeepEquals, &ifacestate.ConnectionExplanation{
+		Plug:      interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		Slot:      interfaces.SlotRef{Snap: "producer", Name: "slot"},
+		Interface: "test",
+		Connection: &policy.Explanation{
+			Kind: "connection",
+			Rule: baseRule,
+			Checks: []*policy.ConstraintCheck{
+				{Constraint: "deny-connection", Alternative: 0, Error: "not allowed"},
+				{Constraint: "allow-connection", Alternative: 0, Error: "publisher id does not match"},
+			},
+			Error: `connection not allowed by slot rule of interface "test"`,
+		},
+		AutoConnection: &policy.Explanation{
+			Kind: "auto-connection",
+			Rule: baseRule,
+			Checks: []*policy.ConstraintCheck{
+				{Constraint: "deny-auto-connection", Alternative: 0, Matched: true},
+			},
+			Error: `auto-connection denied by slot rule of interface "test"`,
+		},
+		InterfaceAutoConnect: true,
+	})
+}
+
+func (s *interfaceManagerSuite) TestExplainConnectionWithoutDeclarations(c *C) {
+	s.mockExplainDeclarations(c)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	ex, err := mgr.ExplainConnection("consumer", "plug", "producer", "slot")
+	c.Assert(err, IsNil)
+	c.Check(ex.Connection.Allowed, Equals, false)
+	c.Check(ex.Notes, DeepEquals, []string{
+		`snap "consumer" has no snap declaration, manual connections are not checked against the rules`,
+		`snap "producer" has no snap declaration, manual connections are not checked against the rules`,
+	})
+}
+
+func (s *interfaceManagerSuite) TestExplainConnectionErrors(c *C) {
+	s.mockExplainDeclarations(c)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := mgr.ExplainConnection("consumer", "foo", "producer", "slot")
+	c.Check(err, ErrorMatches, `snap "consumer" has no plug named "foo"`)
+	_, err = mgr.ExplainConnection("consumer", "plug", "producer", "foo")
+	c.Check(err, ErrorMatches, `snap "producer" has no slot named "foo"`)
+}
@@ -171,3 +171,18 @@ func (m *InterfaceManager) TransitionConnectionsCoreMigration(st *state.State, o
 func (m *InterfaceManager) SetupSecurityByBackend(task *state.Task, snaps []*snap.Info, opts []interfaces.ConfinementOptions, tm timings.Measurer) error {
 	return m.setupSecurityByBackend(task, snaps, opts, tm)
 }
+
+func (m *InterfaceManager) RegenerateDeferredSecurityProfiles() {
+	m.startDeferredSecurityProfiles()
+	m.deferredProfiles.Wait()
+}
+
+func (m *InterfaceManager) WaitDeferredSecurityProfiles() {
+	m.deferredProfiles.Wait()
+}
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	old := timeNow
+	timeNow = f
+	return func() { timeNow = old }
+}
@@ -411,6 +411,10 @@ func (m *InterfaceManager) doConnect(task *state.Task, _ *tomb.Tomb) error {
 	if err := task.Get("delayed-setup-profiles", &delayedSetupProfiles); err != nil && err != state.ErrNoState {
 		return err
 	}
+	var expiry *time.Time
+	if err := task.Get("expiry", &expiry); err != nil && err != state.ErrNoState {
+		return err
+	}
 
 	deviceCtx, err := snapstate.DeviceCtx(st, task, nil)
 	if err != nil {
@@ -520,6 +524,7 @@ func (m *InterfaceManager) doConnect(task *state.Task, _ *tomb.Tomb) error {
 		Auto:             autoConnect,
 		ByGadget:         byGadget,
 		HotplugKey:       slot.HotplugKey,
+		Expiry:           expiry,
 	}
 	setConns(st, conns)
 
@@ -1692,10 +1697,12 @@ func (m *InterfaceManager) doHotplugAddSlot(task *state.Task, _ *tomb.Tomb) erro
 				Attrs:      proposedSlot.Attrs,
 				HotplugKey: hotplugKey,
 			}
-			return addHotplugSlot(st, m.repo, stateSlots, iface, newSlot)
+			return addHotplugSlot(st, m.repo, stateSlots, iface, newSlot, &devinfo)
 		}
 
 		// else - not gone, restored already by reloadConnections, but may need updating.
+		slot.DeviceInfo = &devinfo
+		setHotplugSlots(st, stateSlots)
 		if !reflect.DeepEqual(proposedSlot.Attrs, slot.StaticAttrs) {
 			ts := updateDevice(st, iface.Name(), hotplugKey, proposedSlot.Attrs)
 			snapstate.InjectTasks(task, ts)
@@ -1715,7 +1722,7 @@ func (m *InterfaceManager) doHotplugAddSlot(task *state.Task, _ *tomb.Tomb) erro
 		Attrs:      proposedSlot.Attrs,
 		HotplugKey: hotplugKey,
 	}
-	return addHotplugSlot(st, m.repo, stateSlots, iface, newSlot)
+	return addHotplugSlot(st, m.repo, stateSlots, iface, newSlot, &devinfo)
 }
 
 // doHotplugSeqWait returns Retry error if there is another change for same hotplug key and a lower sequence number.
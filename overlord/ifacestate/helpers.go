@@ -26,12 +26,14 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/backends"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/policy"
 	"github.com/snapcore/snapd/interfaces/utils"
 	"github.com/snapcore/snapd/jsonutil"
@@ -113,9 +115,6 @@ var writeSystemKey = interfaces.WriteSystemKey
 
 // regenerateAllSecurityProfiles will regenerate all security profiles.
 func (m *InterfaceManager) regenerateAllSecurityProfiles(tm timings.Measurer) error {
-	// Get all the security backends
-	securityBackends := m.repo.Backends()
-
 	// Get all the snap infos
 	snaps, err := snapsWithSecurityProfiles(m.state)
 	if err != nil {
@@ -147,8 +146,45 @@ func (m *InterfaceManager) regenerateAllSecurityProfiles(tm timings.Measurer) er
 		return confinementOptions(snapst.Flags)
 	}
 
-	// For each backend:
-	for _, backend := range securityBackends {
+	// Only the profiles of the snaps needed to boot the system are
+	// regenerated right away, the remaining ones are regenerated in the
+	// background from the first Ensure, once snapd is up. When preseeding
+	// Ensure does nothing, so regenerate everything.
+	var deferred []*snap.Info
+	if !m.preseed {
+		snaps, deferred = splitSnapsNeededAtBoot(snaps)
+	}
+
+	if !m.setupSecurityProfiles(snaps, confinementOpts, tm) {
+		shouldWriteSystemKey = false
+	}
+
+	if len(deferred) > 0 {
+		// the system key is written once all profiles are in place
+		m.deferredProfileSnaps = deferred
+		m.deferredWriteSystemKey = shouldWriteSystemKey
+		m.deferredProfilesMu.Lock()
+		m.deferredProfilesPending = true
+		m.deferredProfilesMu.Unlock()
+		return nil
+	}
+
+	if shouldWriteSystemKey {
+		if err := writeSystemKey(); err != nil {
+			logger.Noticef("cannot write system key: %v", err)
+		}
+	}
+	return nil
+}
+
+// setupSecurityProfiles sets up the security profiles of the given snaps for
+// all the security backends. It returns false if any of those failed.
+func (m *InterfaceManager) setupSecurityProfiles(snaps []*snap.Info, confinementOpts func(snapName string) interfaces.ConfinementOptions, tm timings.Measurer) bool {
+	if len(snaps) == 0 {
+		return true
+	}
+	ok := true
+	for _, backend := range m.repo.Backends() {
 		if backend.Name() == "" {
 			continue // Test backends have no name, skip them to simplify testing.
 		}
@@ -157,16 +193,89 @@ func (m *InterfaceManager) regenerateAllSecurityProfiles(tm timings.Measurer) er
 			for _, err := range errors {
 				logger.Noticef(err.Error())
 			}
-			shouldWriteSystemKey = false
+			ok = false
 		}
 	}
+	return ok
+}
+
+// deferredSecurityProfilesPending returns whether the security profiles
+// left out on startup are not regenerated yet.
+func (m *InterfaceManager) deferredSecurityProfilesPending() bool {
+	m.deferredProfilesMu.Lock()
+	defer m.deferredProfilesMu.Unlock()
+	return m.deferredProfilesPending
+}
+
+// startDeferredSecurityProfiles starts regenerating in the background the
+// security profiles of the snaps that were left out when regenerating all
+// security profiles on startup. Interface tasks are blocked until it is done.
+func (m *InterfaceManager) startDeferredSecurityProfiles() {
+	snaps := m.deferredProfileSnaps
+	if len(snaps) == 0 {
+		return
+	}
+	m.deferredProfileSnaps = nil
+
+	m.deferredProfiles.Add(1)
+	go func() {
+		defer m.deferredProfiles.Done()
+		m.regenerateDeferredSecurityProfiles(snaps)
+
+		m.deferredProfilesMu.Lock()
+		m.deferredProfilesPending = false
+		m.deferredProfilesMu.Unlock()
+		// let the blocked interface tasks run
+		m.state.EnsureBefore(0)
+	}()
+}
+
+// regenerateDeferredSecurityProfiles regenerates the security profiles of the
+// given snaps, left out when regenerating all security profiles on startup,
+// and writes the system key if all profiles are in place.
+func (m *InterfaceManager) regenerateDeferredSecurityProfiles(snaps []*snap.Info) {
+	perfTimings := timings.New(map[string]string{"ensure": "ifacemgr-deferred-profiles"})
 
+	// snap state is looked up upfront, so that the state is not kept
+	// locked while compiling profiles
+	m.state.Lock()
+	opts := make(map[string]interfaces.ConfinementOptions, len(snaps))
+	for _, snapInfo := range snaps {
+		var snapst snapstate.SnapState
+		if err := snapstate.Get(m.state, snapInfo.InstanceName(), &snapst); err != nil {
+			logger.Noticef("cannot get state of snap %q: %s", snapInfo.InstanceName(), err)
+		}
+		opts[snapInfo.InstanceName()] = confinementOptions(snapst.Flags)
+	}
+	m.state.Unlock()
+
+	confinementOpts := func(snapName string) interfaces.ConfinementOptions {
+		return opts[snapName]
+	}
+	shouldWriteSystemKey := m.setupSecurityProfiles(snaps, confinementOpts, perfTimings) && m.deferredWriteSystemKey
 	if shouldWriteSystemKey {
 		if err := writeSystemKey(); err != nil {
 			logger.Noticef("cannot write system key: %v", err)
 		}
 	}
-	return nil
+
+	m.state.Lock()
+	defer m.state.Unlock()
+	perfTimings.Save(m.state)
+}
+
+// splitSnapsNeededAtBoot splits the given snaps into the ones whose security
+// profiles are needed to boot the system, that is snaps providing the system
+// and snaps with services, and the remaining ones.
+func splitSnapsNeededAtBoot(snaps []*snap.Info) (needed, other []*snap.Info) {
+	for _, snapInfo := range snaps {
+		if snapInfo.Type() != snap.TypeApp || len(snapInfo.Services()) > 0 {
+			needed = append(needed, snapInfo)
+		} else {
+			other = append(other, snapInfo)
+		}
+	}
+	return needed, other
 }
 
 // renameCorePlugConnection renames one connection from "core-support" plug to
@@ -410,7 +519,7 @@ func (m *InterfaceManager) removeSnapSecurity(task *state.Task, instanceName str
 	return nil
 }
 
-func addHotplugSlot(st *state.State, repo *interfaces.Repository, stateSlots map[string]*HotplugSlotInfo, iface interfaces.Interface, slot *snap.SlotInfo) error {
+func addHotplugSlot(st *state.State, repo *interfaces.Repository, stateSlots map[string]*HotplugSlotInfo, iface interfaces.Interface, slot *snap.SlotInfo, devinfo *hotplug.HotplugDeviceInfo) error {
 	if slot.HotplugKey == "" {
 		return fmt.Errorf("internal error: cannot store slot %q, not a hotplug slot", slot.Name)
 	}
@@ -430,6 +539,7 @@ func addHotplugSlot(st *state.State, repo *interfaces.Repository, stateSlots map
 		StaticAttrs: slot.Attrs,
 		HotplugKey:  slot.HotplugKey,
 		HotplugGone: false,
+		DeviceInfo:  devinfo,
 	}
 	setHotplugSlots(st, stateSlots)
 	logger.Debugf("added hotplug slot %s:%s of interface %s, hotplug key %q", slot.Snap.InstanceName(), slot.Name, slot.Interface, slot.HotplugKey)
@@ -454,6 +564,9 @@ type connState struct {
 	// slots.
 	HotplugGone bool            `json:"hotplug-gone,omitempty"`
 	HotplugKey  snap.HotplugKey `json:"hotplug-key,omitempty"`
+	// Expiry is the time after which a time-limited connection is
+	// removed, see InterfaceManager.Ensure.
+	Expiry *time.Time `json:"expiry,omitempty"`
 }
 
 type gadgetConnect struct {
@@ -796,7 +909,9 @@ func newConnectChecker(s *state.State, deviceCtx snapstate.DeviceContext) (*conn
 	}, nil
 }
 
-func (c *connectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) (bool, error) {
+// candidate returns the policy candidate for connecting the given plug
+// and slot, with the declarations of their snaps if they have any.
+func (c *connectChecker) candidate(plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) (*policy.ConnectCandidate, error) {
 	modelAs := c.deviceCtx.Model()
 
 	var storeAs *asserts.Store
@@ -804,7 +919,7 @@ func (c *connectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.
 		var err error
 		storeAs, err = assertstate.Store(c.st, modelAs.Store())
 		if err != nil && !asserts.IsNotFound(err) {
-			return false, err
+			return nil, err
 		}
 	}
 
@@ -813,7 +928,7 @@ func (c *connectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.
 		var err error
 		plugDecl, err = assertstate.SnapDeclaration(c.st, plug.Snap().SnapID)
 		if err != nil {
-			return false, fmt.Errorf("cannot find snap declaration for %q: %v", plug.Snap().InstanceName(), err)
+			return nil, fmt.Errorf("cannot find snap declaration for %q: %v", plug.Snap().InstanceName(), err)
 		}
 	}
 
@@ -822,12 +937,11 @@ func (c *connectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.
 		var err error
 		slotDecl, err = assertstate.SnapDeclaration(c.st, slot.Snap().SnapID)
 		if err != nil {
-			return false, fmt.Errorf("cannot find snap declaration for %q: %v", slot.Snap().InstanceName(), err)
+			return nil, fmt.Errorf("cannot find snap declaration for %q: %v", slot.Snap().InstanceName(), err)
 		}
 	}
 
-	// check the connection against the declarations' rules
-	ic := policy.ConnectCandidate{
+	return &policy.ConnectCandidate{
 		Plug:                plug,
 		PlugSnapDeclaration: plugDecl,
 		Slot:                slot,
@@ -835,12 +949,20 @@ func (c *connectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.
 		BaseDeclaration:     c.baseDecl,
 		Model:               modelAs,
 		Store:               storeAs,
+	}, nil
+}
+
+func (c *connectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) (bool, error) {
+	// check the connection against the declarations' rules
+	ic, err := c.candidate(plug, slot)
+	if err != nil {
+		return false, err
 	}
 
 	// if either of plug or slot snaps don't have a declaration it
 	// means they were installed with "dangerous", so the security
 	// check should be skipped at this point.
-	if plugDecl != nil && slotDecl != nil {
+	if ic.PlugSnapDeclaration != nil && ic.SlotSnapDeclaration != nil {
 		if err := ic.Check(); err != nil {
 			return false, err
 		}
@@ -1206,6 +1328,9 @@ type HotplugSlotInfo struct {
 
 	// device was unplugged but has connections, so slot is remembered
 	HotplugGone bool `json:"hotplug-gone"`
+
+	// information about the device as last seen by the udev monitor
+	DeviceInfo *hotplug.HotplugDeviceInfo `json:"device-info,omitempty"`
 }
 
 func getHotplugSlots(st *state.State) (map[string]*HotplugSlotInfo, error) {
@@ -25,12 +25,14 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/ifacetest"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
@@ -247,6 +249,38 @@ func (s *helpersSuite) TestHotplugSlotInfo(c *C) {
 	c.Assert(slots, DeepEquals, defs)
 }
 
+func (s *helpersSuite) TestHotplugDevices(c *C) {
+	s.st.Lock()
+	defer s.st.Unlock()
+
+	devices, err := ifacestate.HotplugDevices(s.st)
+	c.Assert(err, IsNil)
+	c.Assert(devices, HasLen, 0)
+
+	devinfo, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/sys/foo", "DEVNAME": "/dev/ttyUSB0", "SUBSYSTEM": "tty"})
+	c.Assert(err, IsNil)
+	ifacestate.SetHotplugSlots(s.st, map[string]*ifacestate.HotplugSlotInfo{
+		"foo": {
+			Name:       "foo",
+			Interface:  "iface",
+			HotplugKey: "key",
+			DeviceInfo: devinfo,
+		},
+		// slots stored by older snapd carry no device information
+		"bar": {
+			Name:       "bar",
+			Interface:  "iface",
+			HotplugKey: "other-key",
+		},
+	})
+
+	devices, err = ifacestate.HotplugDevices(s.st)
+	c.Assert(err, IsNil)
+	c.Assert(devices, HasLen, 1)
+	c.Check(devices["foo"].DeviceName(), Equals, "/dev/ttyUSB0")
+	c.Check(devices["foo"].Subsystem(), Equals, "tty")
+}
+
 func (s *helpersSuite) TestFindConnsForHotplugKey(c *C) {
 	st := s.st
 	st.Lock()
@@ -411,6 +445,9 @@ apps:
 	c.Assert(err, IsNil)
 	err = mgr.StartUp()
 	c.Assert(err, IsNil)
+	// The profiles of the snap are regenerated once snapd is up.
+	c.Assert(mgr.Ensure(), IsNil)
+	mgr.WaitDeferredSecurityProfiles()
 
 	// Check that system key is not on disk.
 	c.Check(log.String(), Matches, `.*cannot regenerate BROKEN profiles\n.*FAILED.*\n`)
@@ -481,8 +518,127 @@ func (s *helpersSuite) TestProfileRegenerationSetupMany(c *C) {
 	err = mgr.StartUp()
 	c.Assert(err, IsNil)
 
+	// The snaps have no services, their profiles are regenerated once
+	// snapd is up.
+	c.Check(writeKey, Equals, false)
+	c.Check(setupManyCalls, Equals, 0)
+
+	c.Assert(mgr.Ensure(), IsNil)
+	mgr.WaitDeferredSecurityProfiles()
 	c.Check(writeKey, Equals, true)
 	c.Check(setupManyCalls, Equals, 1)
+
+	// Only once.
+	c.Assert(mgr.Ensure(), IsNil)
+	mgr.WaitDeferredSecurityProfiles()
+	c.Check(setupManyCalls, Equals, 1)
+}
+
+func (s *helpersSuite) TestProfileRegenerationServicesFirst(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+
+	var setupManySnaps [][]string
+	var writeKey bool
+	deferredStarted := make(chan struct{})
+	deferredRelease := make(chan struct{})
+
+	// Create a fake security backend
+	backend := &ifacetest.TestSecurityBackendSetupMany{
+		TestSecurityBackend: ifacetest.TestSecurityBackend{BackendName: "fake"},
+		SetupManyCallback: func(snaps []*snap.Info, confinement func(snapName string) interfaces.ConfinementOptions, repo *interfaces.Repository, tm timings.Measurer) []error {
+			var names []string
+			for _, snapInfo := range snaps {
+				names = append(names, snapInfo.InstanceName())
+			}
+			sort.Strings(names)
+			setupManySnaps = append(setupManySnaps, names)
+			if len(setupManySnaps) == 2 {
+				close(deferredStarted)
+				<-deferredRelease
+			}
+			return nil
+		},
+	}
+	restore := ifacestate.MockSecurityBackends([]interfaces.SecurityBackend{backend})
+	defer restore()
+
+	// Create a mock overlord, mainly to have state.
+	ovld := overlord.Mock()
+	st := ovld.State()
+
+	mockSnaps(c, st)
+	for _, yamlText := range []string{`
+name: svc
+version: 1
+apps:
+  daemon:
+    command: bin/daemon
+    daemon: simple
+`, `
+name: core20
+version: 1
+type: base
+`} {
+		snapInfo := snaptest.MockSnap(c, yamlText, &snap.SideInfo{Revision: snap.R(1)})
+		si := &snap.SideInfo{Revision: snap.R(1), RealName: snapInfo.SnapName()}
+		st.Lock()
+		snapstate.Set(st, snapInfo.InstanceName(), &snapstate.SnapState{
+			SnapType: string(snapInfo.Type()),
+			Sequence: []*snap.SideInfo{si},
+			Active:   true,
+			Current:  snap.R(1),
+		})
+		st.Unlock()
+	}
+
+	// Pretend that security profiles are out of date.
+	restore = ifacestate.MockProfilesNeedRegeneration(func() bool { return true })
+	defer restore()
+	restore = ifacestate.MockWriteSystemKey(func() error {
+		writeKey = true
+		return nil
+	})
+	defer restore()
+
+	// Construct and start up the interface manager.
+	mgr, err := ifacestate.Manager(st, nil, ovld.TaskRunner(), nil, nil)
+	c.Assert(err, IsNil)
+	err = mgr.StartUp()
+	c.Assert(err, IsNil)
+
+	// The base snap and snaps with services are set up on startup.
+	c.Check(setupManySnaps, DeepEquals, [][]string{{"core20", "svc"}})
+	c.Check(writeKey, Equals, false)
+
+	// The other snaps are set up in the background once snapd is up.
+	c.Assert(mgr.Ensure(), IsNil)
+	<-deferredStarted
+	c.Check(writeKey, Equals, false)
+
+	// Interface tasks wait for the profiles to be in place.
+	st.Lock()
+	chg := st.NewChange("connect", "...")
+	t := st.NewTask("connect", "...")
+	chg.AddTask(t)
+	st.Unlock()
+	runner := ovld.TaskRunner()
+	c.Assert(runner.Ensure(), IsNil)
+	runner.Wait()
+	st.Lock()
+	c.Check(t.Status(), Equals, state.DoStatus)
+	st.Unlock()
+
+	close(deferredRelease)
+	mgr.WaitDeferredSecurityProfiles()
+	c.Check(setupManySnaps, DeepEquals, [][]string{{"core20", "svc"}, {"bar", "foo"}})
+	c.Check(writeKey, Equals, true)
+
+	c.Assert(runner.Ensure(), IsNil)
+	runner.Wait()
+	st.Lock()
+	c.Check(t.Status(), Not(Equals), state.DoStatus)
+	st.Unlock()
 }
 
 func (s *helpersSuite) TestProfileRegenerationSetupManyFailsSystemKeyNotWritten(c *C) {
@@ -528,6 +684,8 @@ func (s *helpersSuite) TestProfileRegenerationSetupManyFailsSystemKeyNotWritten(
 	c.Assert(err, IsNil)
 	err = mgr.StartUp()
 	c.Assert(err, IsNil)
+	c.Assert(mgr.Ensure(), IsNil)
+	mgr.WaitDeferredSecurityProfiles()
 
 	// Check that system key is not on disk.
 	c.Check(writeKey, Equals, false)
@@ -677,11 +835,11 @@ func (s *helpersSuite) TestAddHotplugSlot(c *C) {
 		Attrs:      map[string]interface{}{"foo": "bar"},
 		HotplugKey: "key",
 	}
-	c.Assert(ifacestate.AddHotplugSlot(s.st, repo, stateSlots, iface, slot), IsNil)
+	c.Assert(ifacestate.AddHotplugSlot(s.st, repo, stateSlots, iface, slot, nil), IsNil)
 	c.Assert(beforePrepareSlotCalled, Equals, 1)
 
 	// same slot cannot be re-added to repo
-	c.Assert(ifacestate.AddHotplugSlot(s.st, repo, stateSlots, iface, slot), ErrorMatches, `cannot add hotplug slot "slot" for interface test: snap "core" has slots conflicting on name "slot"`)
+	c.Assert(ifacestate.AddHotplugSlot(s.st, repo, stateSlots, iface, slot, nil), ErrorMatches, `cannot add hotplug slot "slot" for interface test: snap "core" has slots conflicting on name "slot"`)
 
 	stateSlots, err = ifacestate.GetHotplugSlots(s.st)
 	c.Assert(err, IsNil)
@@ -721,9 +879,9 @@ func (s *helpersSuite) TestAddHotplugSlotValidationErrors(c *C) {
 		Interface: "test",
 	}
 	// hotplug key missing
-	c.Assert(ifacestate.AddHotplugSlot(s.st, repo, stateSlots, iface, slot), ErrorMatches, `internal error: cannot store slot "slot", not a hotplug slot`)
+	c.Assert(ifacestate.AddHotplugSlot(s.st, repo, stateSlots, iface, slot, nil), ErrorMatches, `internal error: cannot store slot "slot", not a hotplug slot`)
 	slot.HotplugKey = "key"
 
 	// sanitization failure
-	c.Assert(ifacestate.AddHotplugSlot(s.st, repo, stateSlots, iface, slot), ErrorMatches, `cannot sanitize hotplug slot \"slot\" for interface test: fail`)
+	c.Assert(ifacestate.AddHotplugSlot(s.st, repo, stateSlots, iface, slot, nil), ErrorMatches, `cannot sanitize hotplug slot \"slot\" for interface test: fail`)
 }
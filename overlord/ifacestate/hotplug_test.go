@@ -680,13 +680,14 @@ func (s *hotplugSuite) TestHotplugEnumerationDone(c *C) {
 			"hotplug-key":  "key-other-device",
 			"interface":    "test-a"}})
 
+	devInfoJSON := map[string]interface{}{"data": map[string]interface{}{"DEVPATH": "a/path", "ACTION": "add", "SUBSYSTEM": "foo"}}
 	var newHotplugSlots map[string]interface{}
 	c.Assert(st.Get("hotplug-slots", &newHotplugSlots), IsNil)
 	c.Check(newHotplugSlots, DeepEquals, map[string]interface{}{
 		"hotplugslot-a": map[string]interface{}{
-			"interface": "test-a", "hotplug-gone": false, "static-attrs": map[string]interface{}{"slot-a-attr1": "a", "path": di.DevicePath()}, "hotplug-key": "key-1", "name": "hotplugslot-a"},
+			"interface": "test-a", "hotplug-gone": false, "static-attrs": map[string]interface{}{"slot-a-attr1": "a", "path": di.DevicePath()}, "hotplug-key": "key-1", "name": "hotplugslot-a", "device-info": devInfoJSON},
 		"hotplugslot-b": map[string]interface{}{
-			"name": "hotplugslot-b", "hotplug-gone": false, "interface": "test-b", "hotplug-key": "key-2"},
+			"name": "hotplugslot-b", "hotplug-gone": false, "interface": "test-b", "hotplug-key": "key-2", "device-info": devInfoJSON},
 		"hotplugslot": map[string]interface{}{"name": "hotplugslot", "hotplug-gone": true, "interface": "test-a", "hotplug-key": "key-other-device"}})
 }
 
@@ -793,7 +794,8 @@ func (s *hotplugSuite) TestHotplugDeviceUpdate(c *C) {
 		"static-attrs": map[string]interface{}{"slot-a-attr1": "a", "path": di.DevicePath()},
 		"hotplug-key":  "key-1",
 		"name":         "hotplugslot-a",
-		"hotplug-gone": false})
+		"hotplug-gone": false,
+		"device-info":  map[string]interface{}{"data": map[string]interface{}{"DEVPATH": "a/path", "ACTION": "add", "SUBSYSTEM": "foo"}}})
 }
 
 func keyHelper(input string) snap.HotplugKey {
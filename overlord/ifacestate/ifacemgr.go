@@ -26,6 +26,7 @@ import (
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/backends"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
@@ -58,6 +59,19 @@ type InterfaceManager struct {
 	// maps sysfs path -> [(interface name, device key)...]
 	hotplugDevicePaths map[string][]deviceData
 
+	// snaps whose security profiles are regenerated in the background
+	// from the first Ensure rather than on startup, see
+	// regenerateAllSecurityProfiles
+	deferredProfileSnaps []*snap.Info
+	// whether the system key can be written once the deferred security
+	// profiles are regenerated
+	deferredWriteSystemKey bool
+	// set until the deferred security profiles are regenerated, interface
+	// tasks are blocked meanwhile
+	deferredProfilesMu      sync.Mutex
+	deferredProfilesPending bool
+	deferredProfiles        sync.WaitGroup
+
 	// extras
 	extraInterfaces []interfaces.Interface
 	extraBackends   []interfaces.SecurityBackend
@@ -119,6 +133,10 @@ func Manager(s *state.State, hookManager *hookstate.HookManager, runner *state.T
 			return false
 		}
 
+		if m.deferredSecurityProfilesPending() {
+			return true
+		}
+
 		for _, t := range running {
 			if taskKinds[t.Kind()] {
 				return true
@@ -183,11 +201,18 @@ func (m *InterfaceManager) StartUp() error {
 
 // Ensure implements StateManager.Ensure.
 func (m *InterfaceManager) Ensure() error {
-	// do not worry about udev monitor in preseeding mode
+	// do not worry about udev monitor or expired connections in
+	// preseeding mode
 	if m.preseed {
 		return nil
 	}
 
+	m.startDeferredSecurityProfiles()
+
+	if err := m.disconnectExpired(); err != nil {
+		logger.Noticef("Cannot disconnect expired connections: %v", err)
+	}
+
 	if m.udevMonitorDisabled {
 		return nil
 	}
@@ -216,9 +241,11 @@ func (m *InterfaceManager) Ensure() error {
 	return nil
 }
 
-// Stop implements StateStopper. It stops the udev monitor,
-// if running.
+// Stop implements StateStopper. It waits for the security profiles being
+// regenerated in the background and stops the udev monitor, if running.
 func (m *InterfaceManager) Stop() {
+	m.deferredProfiles.Wait()
+
 	m.udevMonMu.Lock()
 	udevMon := m.udevMon
 	m.udevMonMu.Unlock()
@@ -260,6 +287,9 @@ type ConnectionState struct {
 	StaticSlotAttrs  map[string]interface{}
 	DynamicSlotAttrs map[string]interface{}
 	HotplugGone      bool
+	// Expiry is the time after which the connection is removed, it is
+	// zero for connections without a time limit.
+	Expiry time.Time
 }
 
 // ConnectionStates return the state of connections stored in the state.
@@ -275,6 +305,10 @@ func ConnectionStates(st *state.State) (connStateByRef map[string]ConnectionStat
 
 	connStateByRef = make(map[string]ConnectionState, len(states))
 	for cref, cstate := range states {
+		var expiry time.Time
+		if cstate.Expiry != nil {
+			expiry = *cstate.Expiry
+		}
 		connStateByRef[cref] = ConnectionState{
 			Auto:             cstate.Auto,
 			ByGadget:         cstate.ByGadget,
@@ -285,6 +319,7 @@ func ConnectionStates(st *state.State) (connStateByRef map[string]ConnectionStat
 			StaticSlotAttrs:  cstate.StaticSlotAttrs,
 			DynamicSlotAttrs: cstate.DynamicSlotAttrs,
 			HotplugGone:      cstate.HotplugGone,
+			Expiry:           expiry,
 		}
 	}
 	return connStateByRef, nil
@@ -298,6 +333,33 @@ func (m *InterfaceManager) ConnectionStates() (connStateByRef map[string]Connect
 	return ConnectionStates(m.state)
 }
 
+// HotplugDevices returns the information about the devices backing the
+// hotplug slots known to the system, indexed by slot name. Slots of devices
+// that were unplugged but whose connections are remembered are included.
+// The state must be locked by the caller.
+func HotplugDevices(st *state.State) (map[string]*hotplug.HotplugDeviceInfo, error) {
+	slots, err := getHotplugSlots(st)
+	if err != nil {
+		return nil, err
+	}
+	devices := make(map[string]*hotplug.HotplugDeviceInfo, len(slots))
+	for name, slot := range slots {
+		if slot.DeviceInfo != nil {
+			devices[name] = slot.DeviceInfo
+		}
+	}
+	return devices, nil
+}
+
+// HotplugDevices returns the information about the devices backing the
+// hotplug slots tracked by the manager.
+func (m *InterfaceManager) HotplugDevices() (map[string]*hotplug.HotplugDeviceInfo, error) {
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	return HotplugDevices(m.state)
+}
+
 // ResolveDisconnect resolves potentially missing plug or slot names and
 // returns a list of fully populated connection references that can be
 // disconnected.
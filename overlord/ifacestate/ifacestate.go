@@ -82,12 +82,16 @@ func findSymmetricAutoconnectTask(st *state.State, plugSnap, slotSnap string, in
 type connectOpts struct {
 	ByGadget    bool
 	AutoConnect bool
+	// Expiry is the time after which the connection is removed, if set.
+	Expiry time.Time
 
 	DelayedSetupProfiles bool
 }
 
 // Connect returns a set of tasks for connecting an interface.
 //
+// If the interface is already connected until some time, the time limit
+// is removed and ErrAlreadyConnected is returned.
 func Connect(st *state.State, plugSnap, plugName, slotSnap, slotName string) (*state.TaskSet, error) {
 	if err := snapstate.CheckChangeConflictMany(st, []string{plugSnap, slotSnap}, ""); err != nil {
 		return nil, err
@@ -96,6 +100,36 @@ func Connect(st *state.State, plugSnap, plugName, slotSnap, slotName string) (*s
 	return connect(st, plugSnap, plugName, slotSnap, slotName, connectOpts{})
 }
 
+// ConnectUntil returns a set of tasks for connecting an interface until
+// the given time, after which the interface manager disconnects it.
+//
+// If the interface is already connected until some time, that time is
+// changed to the given one and ErrAlreadyConnected is returned. A
+// connection without a time limit cannot be limited.
+func ConnectUntil(st *state.State, plugSnap, plugName, slotSnap, slotName string, expiry time.Time) (*state.TaskSet, error) {
+	if err := snapstate.CheckChangeConflictMany(st, []string{plugSnap, slotSnap}, ""); err != nil {
+		return nil, err
+	}
+
+	conns, err := getConns(st)
+	if err != nil {
+		return nil, err
+	}
+	connRef := interfaces.ConnRef{PlugRef: interfaces.PlugRef{Snap: plugSnap, Name: plugName}, SlotRef: interfaces.SlotRef{Snap: slotSnap, Name: slotName}}
+	if conn, ok := conns[connRef.ID()]; ok && !conn.Undesired && !conn.HotplugGone {
+		if conn.Expiry == nil {
+			return nil, fmt.Errorf("cannot limit the duration of connection %s: already connected without a time limit", connRef.ID())
+		}
+		conn.Expiry = &expiry
+		setConns(st, conns)
+		// disconnectExpired schedules the next check
+		st.EnsureBefore(0)
+		return nil, &ErrAlreadyConnected{Connection: connRef}
+	}
+
+	return connect(st, plugSnap, plugName, slotSnap, slotName, connectOpts{Expiry: expiry})
+}
+
 func connect(st *state.State, plugSnap, plugName, slotSnap, slotName string, flags connectOpts) (*state.TaskSet, error) {
 	// TODO: Store the intent-to-connect in the state so that we automatically
 	// try to reconnect on reboot (reconnection can fail or can connect with
@@ -121,6 +155,12 @@ func connect(st *state.State, plugSnap, plugName, slotSnap, slotName string, fla
 	}
 	connRef := interfaces.ConnRef{PlugRef: interfaces.PlugRef{Snap: plugSnap, Name: plugName}, SlotRef: interfaces.SlotRef{Snap: slotSnap, Name: slotName}}
 	if conn, ok := conns[connRef.ID()]; ok && conn.Undesired == false && conn.HotplugGone == false {
+		if conn.Expiry != nil && flags.Expiry.IsZero() && !flags.AutoConnect && !flags.ByGadget {
+			// connecting again without a time limit makes the
+			// connection permanent
+			conn.Expiry = nil
+			setConns(st, conns)
+		}
 		return nil, &ErrAlreadyConnected{Connection: connRef}
 	}
 
@@ -208,6 +248,9 @@ func connect(st *state.State, plugSnap, plugName, slotSnap, slotName string, fla
 	if flags.DelayedSetupProfiles {
 		connectInterface.Set("delayed-setup-profiles", true)
 	}
+	if !flags.Expiry.IsZero() {
+		connectInterface.Set("expiry", flags.Expiry)
+	}
 
 	// Expose a copy of all plug and slot attributes coming from yaml to interface hooks. The hooks will be able
 	// to modify them but all attributes will be checked against assertions after the hooks are run.
@@ -37,6 +37,7 @@ import (
 	"github.com/snapcore/snapd/asserts/assertstest"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/builtin"
 	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/ifacetest"
 	"github.com/snapcore/snapd/logger"
@@ -314,6 +315,9 @@ func (s *interfaceManagerSuite) manager(c *C) *ifacestate.InterfaceManager {
 		s.o.AddManager(s.o.TaskRunner())
 
 		c.Assert(s.o.StartUp(), IsNil)
+		// complete the re-generation of security profiles deferred
+		// until snapd is up
+		mgr.RegenerateDeferredSecurityProfiles()
 
 		// ensure the re-generation of security profiles did not
 		// confuse the tests
@@ -2016,6 +2020,12 @@ func (s *interfaceManagerSuite) mockIfaces(c *C, ifaces ...interfaces.Interface)
 	s.extraIfaces = append(s.extraIfaces, ifaces...)
 }
 
+// mockContentIface replaces the builtin content interface with a test one
+// without an attribute schema, so that fixtures can use arbitrary attributes.
+func (s *interfaceManagerSuite) mockContentIface(c *C) {
+	s.AddCleanup(builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "content"}))
+}
+
 func (s *interfaceManagerSuite) mockSnap(c *C, yamlText string) *snap.Info {
 	return s.mockSnapInstance(c, "", yamlText)
 }
@@ -2899,6 +2909,8 @@ func (s *interfaceManagerSuite) TestDoSetupSnapSecurityKeepsExistingConnectionSt
 }
 
 func (s *interfaceManagerSuite) TestReloadingConnectionsOnStartupUpdatesStaticAttributes(c *C) {
+	s.mockContentIface(c)
+
 	// Put a connection in the state. The connection binds the two snaps we are
 	// adding below. The connection contains a copy of the static attributes
 	// but refers to the "old" values, in contrast to what the snaps define.
@@ -2983,6 +2995,8 @@ slots:
 
 // LP:#1825883; make sure static attributes in conns state are updated from the snap yaml on snap refresh (content interface only)
 func (s *interfaceManagerSuite) testDoSetupProfilesUpdatesStaticAttributes(c *C, snapNameToSetup string) {
+	s.mockContentIface(c)
+
 	// Put a connection in the state. The connection binds the two snaps we are
 	// adding below. The connection reflects the snaps as they are now, and
 	// carries no attribute data.
@@ -3164,6 +3178,8 @@ func (s *interfaceManagerSuite) TestDoSetupProfilesUpdatesStaticAttributesSlotSn
 }
 
 func (s *interfaceManagerSuite) TestUpdateStaticAttributesIgnoresContentMismatch(c *C) {
+	s.mockContentIface(c)
+
 	s.state.Lock()
 	s.state.Set("conns", map[string]interface{}{
 		"consumer:plug producer:slot": map[string]interface{}{
@@ -4230,6 +4246,7 @@ plugs:
 
 func (s *interfaceManagerSuite) TestManagerReloadsConnections(c *C) {
 	s.mockIfaces(c, &ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockContentIface(c)
 	var consumerYaml = `
 name: consumer
 version: 1
@@ -5405,7 +5422,14 @@ func (s *interfaceManagerSuite) TestStartupTimings(c *C) {
 
 	s.extraBackends = []interfaces.SecurityBackend{&ifacetest.TestSecurityBackend{BackendName: "fake"}}
 	s.mockIface(c, &ifacetest.TestInterface{InterfaceName: "test"})
-	s.mockSnap(c, consumerYaml)
+	// snaps with services have their profiles set up on startup
+	s.mockSnap(c, `name: service
+version: 1
+apps:
+  svc:
+    command: bin/svc
+    daemon: simple
+`)
 
 	oldDurationThreshold := timings.DurationThreshold
 	defer func() {
@@ -5431,7 +5455,7 @@ func (s *interfaceManagerSuite) TestStartupTimings(c *C) {
 	c.Assert(ok, Equals, true)
 	tm := timingsList[0].(map[string]interface{})
 	c.Check(tm["label"], Equals, "setup-security-backend")
-	c.Check(tm["summary"], Matches, `setup security backend "fake" for snap "consumer"`)
+	c.Check(tm["summary"], Matches, `setup security backend "fake" for snap "service"`)
 
 	tags, ok := allTimings[0]["tags"]
 	c.Assert(ok, Equals, true)
@@ -5484,6 +5508,7 @@ func (s *interfaceManagerSuite) TestAutoconnectSelf(c *C) {
 
 func (s *interfaceManagerSuite) TestAutoconnectForDefaultContentProvider(c *C) {
 	s.MockModel(c, nil)
+	s.mockContentIface(c)
 
 	restore := ifacestate.MockContentLinkRetryTimeout(5 * time.Millisecond)
 	defer restore()
@@ -7215,12 +7240,17 @@ func (s *interfaceManagerSuite) testHotplugAddNewSlot(c *C, devData map[string]s
 	var hotplugSlots map[string]interface{}
 	c.Assert(s.state.Get("hotplug-slots", &hotplugSlots), IsNil)
 	c.Assert(hotplugSlots, HasLen, 1)
+	devDataJSON := make(map[string]interface{}, len(devData))
+	for k, v := range devData {
+		devDataJSON[k] = v
+	}
 	c.Check(hotplugSlots[expectedName], DeepEquals, map[string]interface{}{
 		"name":         expectedName,
 		"interface":    "test",
 		"hotplug-key":  "1234",
 		"static-attrs": map[string]interface{}{"foo": "bar"},
 		"hotplug-gone": false,
+		"device-info":  map[string]interface{}{"data": devDataJSON},
 	})
 }
 
@@ -7260,7 +7290,8 @@ func (s *interfaceManagerSuite) TestHotplugAddGoneSlot(c *C) {
 	t.Set("interface", "test")
 	proposedSlot := hotplug.ProposedSlot{Name: "hotplugslot", Label: "", Attrs: map[string]interface{}{"foo": "bar"}}
 	t.Set("proposed-slot", proposedSlot)
-	t.Set("device-info", map[string]string{"DEVPATH": "/a", "NAME": "hdcamera"})
+	devinfo, _ := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/a", "NAME": "hdcamera"})
+	t.Set("device-info", devinfo)
 	chg.AddTask(t)
 
 	s.state.Unlock()
@@ -7285,6 +7316,7 @@ func (s *interfaceManagerSuite) TestHotplugAddGoneSlot(c *C) {
 			"hotplug-key":  "1234",
 			"static-attrs": map[string]interface{}{"foo": "bar"},
 			"hotplug-gone": false,
+			"device-info":  map[string]interface{}{"data": map[string]interface{}{"DEVPATH": "/a", "NAME": "hdcamera"}},
 		}})
 }
 
@@ -7346,6 +7378,7 @@ func (s *interfaceManagerSuite) TestHotplugAddSlotWithChangedAttrs(c *C) {
 			"hotplug-key":  "1234",
 			"static-attrs": map[string]interface{}{"foo": "newfoo"},
 			"hotplug-gone": false,
+			"device-info":  map[string]interface{}{"data": map[string]interface{}{"DEVPATH": "/a"}},
 		}})
 }
 
@@ -7990,6 +8023,8 @@ func (s *interfaceManagerSuite) TestDoSetupSnapSecurityAutoConnectsDeclBasedAnyS
 }
 
 func (s *interfaceManagerSuite) testDoSetupSnapSecurityAutoConnectsDeclBasedAnySlotsPerPlug(c *C, check func(map[string]interface{}, []*interfaces.ConnRef)) {
+	s.mockContentIface(c)
+
 	const theme1Yaml = `
 name: theme1
 version: 1
@@ -0,0 +1,275 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/snap"
+)
+
+// Profile is a security profile file as rendered by a security
+// backend for a snap.
+type Profile struct {
+	Backend interfaces.SecuritySystem `json:"backend"`
+	Path    string                    `json:"path"`
+	Content string                    `json:"content"`
+}
+
+// ProfileChange describes how a security profile file of a snap would
+// change. Old is empty for new files and New is empty for removed
+// ones.
+type ProfileChange struct {
+	Snap    string                    `json:"snap"`
+	Backend interfaces.SecuritySystem `json:"backend"`
+	Path    string                    `json:"path"`
+	Old     string                    `json:"old,omitempty"`
+	New     string                    `json:"new,omitempty"`
+}
+
+// Profiles returns the security profiles of the given snap as rendered
+// from the current state of the interfaces repository, without
+// touching the ones on disk. If appName is not empty only the profiles
+// relevant to that application are returned, and if backend is not
+// empty only the profiles of that security backend.
+//
+// The state must be locked by the caller.
+func (m *InterfaceManager) Profiles(snapName, appName string, backend interfaces.SecuritySystem) ([]*Profile, error) {
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(m.state, snapName, &snapst); err != nil {
+		return nil, err
+	}
+	snapInfo, err := snapst.CurrentInfo()
+	if err != nil {
+		return nil, err
+	}
+	if appName != "" && snapInfo.Apps[appName] == nil {
+		return nil, fmt.Errorf("snap %q has no app %q", snapName, appName)
+	}
+	if backend != "" && !m.hasBackend(backend) {
+		return nil, fmt.Errorf("unknown security backend %q", backend)
+	}
+
+	profiles, err := renderProfiles(m.repo, snapInfo, confinementOptions(snapst.Flags), backend)
+	if err != nil {
+		return nil, err
+	}
+	if appName == "" {
+		return profiles, nil
+	}
+	appTag := snapInfo.Apps[appName].SecurityTag()
+	filtered := profiles[:0]
+	for _, p := range profiles {
+		if tag := profileSecurityTag(snapInfo, p.Path); tag == "" || tag == appTag {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered, nil
+}
+
+func (m *InterfaceManager) hasBackend(name interfaces.SecuritySystem) bool {
+	for _, backend := range m.repo.Backends() {
+		if backend.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// renderProfiles renders the security profiles of the snap from the
+// given repository with all the backends supporting it, or only the
+// given one, sorted by backend and path.
+func renderProfiles(repo *interfaces.Repository, snapInfo *snap.Info, opts interfaces.ConfinementOptions, only interfaces.SecuritySystem) ([]*Profile, error) {
+	var profiles []*Profile
+	for _, backend := range repo.Backends() {
+		if only != "" && backend.Name() != only {
+			continue
+		}
+		renderer, ok := backend.(interfaces.SecurityBackendProfiles)
+		if !ok {
+			continue
+		}
+		content, err := renderer.Profiles(snapInfo, opts, repo)
+		if err != nil {
+			return nil, err
+		}
+		for path, data := range content {
+			profiles = append(profiles, &Profile{
+				Backend: backend.Name(),
+				Path:    path,
+				Content: string(data),
+			})
+		}
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Backend != profiles[j].Backend {
+			return profiles[i].Backend < profiles[j].Backend
+		}
+		return profiles[i].Path < profiles[j].Path
+	})
+	return profiles, nil
+}
+
+// profileSecurityTag returns the security tag of the application or
+// hook of the snap the profile file with the given path is specific to,
+// or an empty string if the file applies to the whole snap.
+func profileSecurityTag(snapInfo *snap.Info, path string) string {
+	var tags []string
+	for _, app := range snapInfo.Apps {
+		tags = append(tags, app.SecurityTag())
+	}
+	for _, hook := range snapInfo.Hooks {
+		tags = append(tags, hook.SecurityTag())
+	}
+	base := filepath.Base(path)
+	// the longest matching tag wins, so that hook tags are not
+	// mistaken for the tag of an app called "hook"
+	var match string
+	for _, tag := range tags {
+		if (base == tag || strings.HasPrefix(base, tag+".")) && len(tag) > len(match) {
+			match = tag
+		}
+	}
+	return match
+}
+
+// ConnectDryRun returns how the security profiles of the snaps
+// involved would change if the given plug and slot were connected,
+// without connecting them nor touching the profiles on disk. The
+// connection policy is checked but the interface hooks are not run.
+//
+// The state must be locked by the caller.
+func (m *InterfaceManager) ConnectDryRun(plugSnap, plugName, slotSnap, slotName string) ([]*ProfileChange, error) {
+	st := m.state
+	conns, err := getConns(st)
+	if err != nil {
+		return nil, err
+	}
+	connRef := &interfaces.ConnRef{PlugRef: interfaces.PlugRef{Snap: plugSnap, Name: plugName}, SlotRef: interfaces.SlotRef{Snap: slotSnap, Name: slotName}}
+	if conn, ok := conns[connRef.ID()]; ok && !conn.Undesired && !conn.HotplugGone {
+		return nil, &ErrAlreadyConnected{Connection: *connRef}
+	}
+
+	var plugSnapst, slotSnapst snapstate.SnapState
+	if err := snapstate.Get(st, plugSnap, &plugSnapst); err != nil {
+		return nil, err
+	}
+	if err := snapstate.Get(st, slotSnap, &slotSnapst); err != nil {
+		return nil, err
+	}
+	plugSnapInfo, err := plugSnapst.CurrentInfo()
+	if err != nil {
+		return nil, err
+	}
+	slotSnapInfo, err := slotSnapst.CurrentInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	plugStatic, slotStatic, err := initialConnectAttributes(st, plugSnapInfo, plugSnap, plugName, slotSnapInfo, slotSnap, slotName)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceCtx, err := snapstate.DeviceCtxFromState(st, nil)
+	if err != nil {
+		return nil, err
+	}
+	policyCheck, err := newConnectChecker(st, deviceCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := m.repo.Copy()
+	if _, err := repo.Connect(connRef, plugStatic, nil, slotStatic, nil, policyCheck.check); err != nil {
+		return nil, err
+	}
+
+	var changes []*ProfileChange
+	affected := []struct {
+		info  *snap.Info
+		flags snapstate.Flags
+	}{
+		{slotSnapInfo, slotSnapst.Flags},
+		{plugSnapInfo, plugSnapst.Flags},
+	}
+	for i, a := range affected {
+		if i > 0 && a.info.InstanceName() == affected[0].info.InstanceName() {
+			// plug and slot of the same snap
+			break
+		}
+		opts := confinementOptions(a.flags)
+		before, err := renderProfiles(m.repo, a.info, opts, "")
+		if err != nil {
+			return nil, err
+		}
+		after, err := renderProfiles(repo, a.info, opts, "")
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, profileChanges(a.info.InstanceName(), before, after)...)
+	}
+	return changes, nil
+}
+
+// profileChanges returns the changes between two sets of profiles of
+// a snap, as returned by renderProfiles.
+func profileChanges(snapName string, before, after []*Profile) []*ProfileChange {
+	type key struct {
+		backend interfaces.SecuritySystem
+		path    string
+	}
+	old := make(map[key]*Profile, len(before))
+	for _, p := range before {
+		old[key{p.Backend, p.Path}] = p
+	}
+
+	var changes []*ProfileChange
+	for _, p := range after {
+		k := key{p.Backend, p.Path}
+		prev := old[k]
+		delete(old, k)
+		if prev != nil && prev.Content == p.Content {
+			continue
+		}
+		change := &ProfileChange{Snap: snapName, Backend: p.Backend, Path: p.Path, New: p.Content}
+		if prev != nil {
+			change.Old = prev.Content
+		}
+		changes = append(changes, change)
+	}
+	for _, p := range before {
+		if old[key{p.Backend, p.Path}] != nil {
+			changes = append(changes, &ProfileChange{Snap: snapName, Backend: p.Backend, Path: p.Path, Old: p.Content})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Backend != changes[j].Backend {
+			return changes[i].Backend < changes[j].Backend
+		}
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
@@ -0,0 +1,195 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+// profilesBackend renders one profile per snap app or hook with the
+// snippets of the test specification, plus one for the whole snap,
+// if there are any snippets
+type profilesBackend struct {
+	ifacetest.TestSecurityBackend
+}
+
+func (b *profilesBackend) Profiles(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) (map[string][]byte, error) {
+	spec, err := repo.SnapSpecification(b.Name(), snapInfo.InstanceName())
+	if err != nil {
+		return nil, err
+	}
+	snippets := spec.(*ifacetest.Specification).Snippets
+	if len(snippets) == 0 {
+		return nil, nil
+	}
+	sort.Strings(snippets)
+	content := strings.Join(snippets, "\n") + "\n"
+	profiles := map[string][]byte{
+		fmt.Sprintf("/profiles/snap.%s.conf", snapInfo.InstanceName()): []byte(content),
+	}
+	for _, app := range snapInfo.Apps {
+		profiles["/profiles/"+app.SecurityTag()] = []byte(content)
+	}
+	for _, hook := range snapInfo.Hooks {
+		profiles["/profiles/"+hook.SecurityTag()] = []byte(content)
+	}
+	return profiles, nil
+}
+
+const profilesConsumerYaml = `
+name: consumer
+version: 1
+apps:
+ app:
+ other:
+hooks:
+ configure:
+plugs:
+ plug:
+  interface: test
+`
+
+const profilesProducerYaml = `
+name: producer
+version: 1
+slots:
+ slot:
+  interface: test
+`
+
+func (s *interfaceManagerSuite) mockProfilesBackend(c *C) {
+	s.mockIface(c, &ifacetest.TestInterface{
+		InterfaceName: "test",
+		TestPermanentPlugCallback: func(spec *ifacetest.Specification, plug *snap.PlugInfo) error {
+			spec.AddSnippet("permanent-plug")
+			return nil
+		},
+		TestConnectedPlugCallback: func(spec *ifacetest.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AddSnippet("connected-plug")
+			return nil
+		},
+		TestConnectedSlotCallback: func(spec *ifacetest.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AddSnippet("connected-slot")
+			return nil
+		},
+	})
+	s.mockSecBackend(c, &profilesBackend{ifacetest.TestSecurityBackend{BackendName: "profiles"}})
+	s.mockSecBackend(c, &ifacetest.TestSecurityBackend{BackendName: "other"})
+	s.mockSnap(c, profilesConsumerYaml)
+	s.mockSnap(c, profilesProducerYaml)
+}
+
+func (s *interfaceManagerSuite) TestProfiles(c *C) {
+	s.mockProfilesBackend(c)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	profiles, err := mgr.Profiles("consumer", "", "")
+	c.Assert(err, IsNil)
+	c.Check(profiles, DeepEquals, []*ifacestate.Profile{
+		{Backend: "profiles", Path: "/profiles/snap.consumer.app", Content: "permanent-plug\n"},
+		{Backend: "profiles", Path: "/profiles/snap.consumer.conf", Content: "permanent-plug\n"},
+		{Backend: "profiles", Path: "/profiles/snap.consumer.hook.configure", Content: "permanent-plug\n"},
+		{Backend: "profiles", Path: "/profiles/snap.consumer.other", Content: "permanent-plug\n"},
+	})
+
+	// the profiles of the other apps and hooks are filtered out
+	profiles, err = mgr.Profiles("consumer", "app", "profiles")
+	c.Assert(err, IsNil)
+	c.Check(profiles, DeepEquals, []*ifacestate.Profile{
+		{Backend: "profiles", Path: "/profiles/snap.consumer.app", Content: "permanent-plug\n"},
+		{Backend: "profiles", Path: "/profiles/snap.consumer.conf", Content: "permanent-plug\n"},
+	})
+
+	// backends not supporting rendering profiles are skipped
+	profiles, err = mgr.Profiles("consumer", "", "other")
+	c.Assert(err, IsNil)
+	c.Check(profiles, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestProfilesErrors(c *C) {
+	s.mockProfilesBackend(c)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := mgr.Profiles("consumer", "missing", "")
+	c.Check(err, ErrorMatches, `snap "consumer" has no app "missing"`)
+	_, err = mgr.Profiles("consumer", "", "missing")
+	c.Check(err, ErrorMatches, `unknown security backend "missing"`)
+	_, err = mgr.Profiles("missing", "", "")
+	c.Check(err, ErrorMatches, `no state entry for key`)
+}
+
+func (s *interfaceManagerSuite) TestConnectDryRun(c *C) {
+	s.MockModel(c, nil)
+	s.mockProfilesBackend(c)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	changes, err := mgr.ConnectDryRun("consumer", "plug", "producer", "slot")
+	c.Assert(err, IsNil)
+	c.Check(changes, DeepEquals, []*ifacestate.ProfileChange{
+		{Snap: "producer", Backend: "profiles", Path: "/profiles/snap.producer.conf", New: "connected-slot\n"},
+		{Snap: "consumer", Backend: "profiles", Path: "/profiles/snap.consumer.app", Old: "permanent-plug\n", New: "connected-plug\npermanent-plug\n"},
+		{Snap: "consumer", Backend: "profiles", Path: "/profiles/snap.consumer.conf", Old: "permanent-plug\n", New: "connected-plug\npermanent-plug\n"},
+		{Snap: "consumer", Backend: "profiles", Path: "/profiles/snap.consumer.hook.configure", Old: "permanent-plug\n", New: "connected-plug\npermanent-plug\n"},
+		{Snap: "consumer", Backend: "profiles", Path: "/profiles/snap.consumer.other", Old: "permanent-plug\n", New: "connected-plug\npermanent-plug\n"},
+	})
+
+	// nothing was connected
+	conns, err := mgr.Repository().Connected("consumer", "plug")
+	c.Assert(err, IsNil)
+	c.Check(conns, HasLen, 0)
+	var connsState map[string]interface{}
+	c.Check(s.state.Get("conns", &connsState), Equals, state.ErrNoState)
+}
+
+func (s *interfaceManagerSuite) TestConnectDryRunAlreadyConnected(c *C) {
+	s.MockModel(c, nil)
+	s.mockProfilesBackend(c)
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	s.state.Unlock()
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := mgr.ConnectDryRun("consumer", "plug", "producer", "slot")
+	c.Check(err, ErrorMatches, `already connected: "consumer:plug producer:slot"`)
+}
@@ -308,6 +308,7 @@ func (o *Overlord) newStoreWithContext(storeCtx store.DeviceAndAuthContext) snap
 
 // newStore can make new stores for use during remodeling.
 // The device backend will tie them to the remodeling device state.
+// The state lock must be held.
 func (o *Overlord) newStore(devBE storecontext.DeviceBackend) snapstate.StoreService {
 	scb := o.deviceMgr.StoreContextBackend()
 	stoCtx := storecontext.NewComposed(o.State(), devBE, scb, scb)
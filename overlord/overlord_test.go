@@ -146,7 +146,10 @@ func (ovs *overlordSuite) TestNewStore(c *C) {
 
 	devBE := o.DeviceManager().StoreContextBackend()
 
+	st := o.State()
+	st.Lock()
 	sto := o.NewStore(devBE)
+	st.Unlock()
 	c.Check(sto, FitsTypeOf, &store.Store{})
 	c.Check(sto.(*store.Store).CacheDownloads(), Equals, 5)
 }
@@ -302,3 +302,19 @@ func MockGenericRefreshCheck(fn func(info *snap.Info, canAppRunDuringRefresh fun
 func (m *autoRefresh) EnsureRefreshHoldAtLeast(d time.Duration) error {
 	return m.ensureRefreshHoldAtLeast(d)
 }
+
+func (m *SnapManager) RecordHistory() error {
+	return m.recordHistory()
+}
+
+func MockMaxHistoryFileSize(size int64) (restore func()) {
+	old := maxHistoryFileSize
+	maxHistoryFileSize = size
+	return func() { maxHistoryFileSize = old }
+}
+
+func MockHistoryAppend(f func(entries []*HistoryEntry) error) (restore func()) {
+	old := historyAppend
+	historyAppend = f
+	return func() { historyAppend = old }
+}
@@ -0,0 +1,302 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+// HistoryEntry records the outcome of an install, refresh, revert or
+// removal of a snap.
+type HistoryEntry struct {
+	Snap         string        `json:"snap"`
+	Action       string        `json:"action"`
+	FromRevision snap.Revision `json:"from-revision"`
+	ToRevision   snap.Revision `json:"to-revision"`
+	Channel      string        `json:"channel,omitempty"`
+	// Trigger is one of auto, manual, remodel or validation.
+	Trigger string    `json:"trigger"`
+	Time    time.Time `json:"time"`
+	// Outcome is one of done, undone, error or skipped.
+	Outcome  string `json:"outcome"`
+	ChangeID string `json:"change-id"`
+}
+
+// maxHistoryFileSize is the size after which the history file is
+// rotated, only one rotated file is kept.
+var maxHistoryFileSize int64 = 1024 * 1024
+
+func historyFile() string {
+	return filepath.Join(dirs.SnapHistoryDir, "history.json")
+}
+
+// History returns the recorded history for the given snap, or for all
+// snaps if snapName is empty, from oldest to newest.
+func History(snapName string) ([]*HistoryEntry, error) {
+	var entries []*HistoryEntry
+	for _, fn := range []string{historyFile() + ".1", historyFile()} {
+		fileEntries, err := readHistoryFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range fileEntries {
+			if snapName == "" || e.Snap == snapName {
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries, nil
+}
+
+func readHistoryFile(fn string) ([]*HistoryEntry, error) {
+	f, err := os.Open(fn)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []*HistoryEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e HistoryEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			// could be a partially written line, keep going
+			logger.Noticef("cannot decode snap history entry in %s: %v", fn, err)
+			continue
+		}
+		entries = append(entries, &e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read snap history: %v", err)
+	}
+	return entries, nil
+}
+
+// appendHistory appends the given entries to the history file,
+// rotating it first if it grew too big.
+var historyAppend = appendHistory
+
+func appendHistory(entries []*HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dirs.SnapHistoryDir, 0755); err != nil {
+		return err
+	}
+
+	fn := historyFile()
+	if fi, err := os.Stat(fn); err == nil && fi.Size() >= maxHistoryFileSize {
+		if err := os.Rename(fn, fn+".1"); err != nil {
+			return fmt.Errorf("cannot rotate snap history: %v", err)
+		}
+	}
+
+	f, err := os.OpenFile(fn, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var buf []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	if _, err := f.Write(buf); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func historyTrigger(chg *state.Change) string {
+	switch kind := chg.Kind(); {
+	case kind == "auto-refresh" || kind == "seed" || strings.HasPrefix(kind, "transition-"):
+		return "auto"
+	case kind == "remodel":
+		return "remodel"
+	case strings.Contains(kind, "validation"):
+		return "validation"
+	}
+	return "manual"
+}
+
+func historyOutcome(status state.Status) string {
+	switch status {
+	case state.DoneStatus:
+		return "done"
+	case state.UndoneStatus:
+		return "undone"
+	case state.ErrorStatus:
+		return "error"
+	}
+	return "skipped"
+}
+
+// changeHistory returns the history entries for the snaps installed,
+// refreshed, reverted or removed by the given ready change.
+func changeHistory(chg *state.Change) ([]*HistoryEntry, error) {
+	tasks := chg.Tasks()
+
+	// snaps that had their current revision unlinked for a refresh
+	// or that got discarded
+	refreshed := make(map[string]bool)
+	discarded := make(map[string]bool)
+	for _, t := range tasks {
+		switch t.Kind() {
+		case "unlink-current-snap", "discard-snap":
+			snapsup, err := TaskSnapSetup(t)
+			if err != nil {
+				return nil, err
+			}
+			if t.Kind() == "discard-snap" {
+				discarded[snapsup.InstanceName()] = true
+			} else {
+				refreshed[snapsup.InstanceName()] = true
+			}
+		}
+	}
+
+	trigger := historyTrigger(chg)
+	var entries []*HistoryEntry
+	for _, t := range tasks {
+		kind := t.Kind()
+		if kind != "link-snap" && kind != "unlink-snap" {
+			continue
+		}
+		snapsup, err := TaskSnapSetup(t)
+		if err != nil {
+			return nil, err
+		}
+		name := snapsup.InstanceName()
+		e := &HistoryEntry{
+			Snap:     name,
+			Channel:  snapsup.Channel,
+			Trigger:  trigger,
+			Time:     chg.ReadyTime(),
+			Outcome:  historyOutcome(t.Status()),
+			ChangeID: chg.ID(),
+		}
+		if kind == "unlink-snap" {
+			if !discarded[name] {
+				// disabling a snap, not removing it
+				continue
+			}
+			e.Action = "remove"
+			e.FromRevision = snapsup.Revision()
+			e.Channel = ""
+		} else {
+			e.ToRevision = snapsup.Revision()
+			// only set if the task ran
+			if err := t.Get("old-current", &e.FromRevision); err != nil && err != state.ErrNoState {
+				return nil, err
+			}
+			switch {
+			case snapsup.Revert:
+				e.Action = "revert"
+			case refreshed[name] || !e.FromRevision.Unset():
+				e.Action = "refresh"
+			default:
+				e.Action = "install"
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// recordHistory records the history of the ready changes not yet
+// recorded. The history file is written without holding the state lock.
+func (m *SnapManager) recordHistory() error {
+	m.state.Lock()
+	ready, entries, err := m.unrecordedHistory()
+	m.state.Unlock()
+	if err != nil {
+		return err
+	}
+	if len(ready) == 0 {
+		return nil
+	}
+
+	if err := historyAppend(entries); err != nil {
+		return fmt.Errorf("cannot record snap history: %v", err)
+	}
+
+	m.state.Lock()
+	defer m.state.Unlock()
+	for _, chg := range ready {
+		chg.Set("history-recorded", true)
+	}
+	return nil
+}
+
+// unrecordedHistory returns the ready changes whose history was not
+// recorded yet, in chronological order, together with their history
+// entries.
+func (m *SnapManager) unrecordedHistory() (ready []*state.Change, entries []*HistoryEntry, err error) {
+	for _, chg := range m.state.Changes() {
+		if !chg.IsReady() {
+			continue
+		}
+		var recorded bool
+		if err := chg.Get("history-recorded", &recorded); err != nil && err != state.ErrNoState {
+			return nil, nil, err
+		}
+		if !recorded {
+			ready = append(ready, chg)
+		}
+	}
+	// record in chronological order
+	sort.Slice(ready, func(i, j int) bool {
+		return ready[i].ReadyTime().Before(ready[j].ReadyTime())
+	})
+
+	for _, chg := range ready {
+		chgEntries, err := changeHistory(chg)
+		if err != nil {
+			// don't get stuck on this change
+			logger.Noticef("cannot record snap history for change %s: %v", chg.ID(), err)
+		}
+		entries = append(entries, chgEntries...)
+	}
+	return ready, entries, nil
+}
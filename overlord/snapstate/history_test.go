@@ -0,0 +1,249 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *snapmgrTestSuite) TestHistoryInstallAndRemove(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	chg := s.state.NewChange("install-snap", "install a snap")
+	opts := &snapstate.RevisionOptions{Channel: "some-channel"}
+	ts, err := snapstate.Install(context.Background(), s.state, "some-snap", opts, s.user.ID, snapstate.Flags{})
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	s.state.Unlock()
+	defer s.se.Stop()
+	s.settle(c)
+	s.state.Lock()
+	c.Assert(chg.Err(), IsNil)
+
+	chg = s.state.NewChange("remove-snap", "remove a snap")
+	ts, err = snapstate.Remove(s.state, "some-snap", snap.R(0), nil)
+	c.Assert(err, IsNil)
+	chg.AddAll(ts)
+
+	s.state.Unlock()
+	s.settle(c)
+	s.state.Lock()
+	c.Assert(chg.Err(), IsNil)
+
+	s.state.Unlock()
+	err = s.snapmgr.RecordHistory()
+	s.state.Lock()
+	c.Assert(err, IsNil)
+
+	entries, err := snapstate.History("some-snap")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+
+	c.Check(entries[0].Snap, Equals, "some-snap")
+	c.Check(entries[0].Action, Equals, "install")
+	c.Check(entries[0].FromRevision, Equals, snap.R(0))
+	c.Check(entries[0].ToRevision, Equals, snap.R(11))
+	c.Check(entries[0].Channel, Equals, "some-channel")
+	c.Check(entries[0].Trigger, Equals, "manual")
+	c.Check(entries[0].Outcome, Equals, "done")
+	c.Check(entries[0].Time.IsZero(), Equals, false)
+
+	c.Check(entries[1].Snap, Equals, "some-snap")
+	c.Check(entries[1].Action, Equals, "remove")
+	c.Check(entries[1].FromRevision, Equals, snap.R(11))
+	c.Check(entries[1].ToRevision, Equals, snap.R(0))
+	c.Check(entries[1].Trigger, Equals, "manual")
+	c.Check(entries[1].Outcome, Equals, "done")
+	c.Check(entries[1].ChangeID, Equals, chg.ID())
+
+	// changes are recorded only once
+	s.state.Unlock()
+	err = s.snapmgr.RecordHistory()
+	s.state.Lock()
+	c.Assert(err, IsNil)
+	entries, err = snapstate.History("")
+	c.Assert(err, IsNil)
+	c.Check(entries, HasLen, 2)
+}
+
+func (s *snapmgrTestSuite) TestHistoryFailedAutoRefresh(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapsup := &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "some-snap",
+			Revision: snap.R(12),
+		},
+		Channel: "stable",
+	}
+	chg := s.state.NewChange("auto-refresh", "auto-refresh a snap")
+	unlink := s.state.NewTask("unlink-current-snap", "")
+	unlink.Set("snap-setup", snapsup)
+	unlink.SetStatus(state.UndoneStatus)
+	chg.AddTask(unlink)
+	link := s.state.NewTask("link-snap", "")
+	link.Set("snap-setup-task", unlink.ID())
+	link.Set("old-current", snap.R(11))
+	link.SetStatus(state.ErrorStatus)
+	chg.AddTask(link)
+	// not linked yet
+	other := s.state.NewTask("link-snap", "")
+	other.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "other-snap",
+			Revision: snap.R(3),
+		},
+	})
+	chg.AddTask(other)
+
+	c.Assert(chg.IsReady(), Equals, false)
+	s.state.Unlock()
+	err := s.snapmgr.RecordHistory()
+	s.state.Lock()
+	c.Assert(err, IsNil)
+	// nothing yet
+	c.Check(filepath.Join(dirs.SnapHistoryDir, "history.json"), testutil.FileAbsent)
+
+	other.SetStatus(state.HoldStatus)
+	c.Assert(chg.IsReady(), Equals, true)
+	s.state.Unlock()
+	err = s.snapmgr.RecordHistory()
+	s.state.Lock()
+	c.Assert(err, IsNil)
+
+	entries, err := snapstate.History("")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Check(entries[0], DeepEquals, &snapstate.HistoryEntry{
+		Snap:         "some-snap",
+		Action:       "refresh",
+		FromRevision: snap.R(11),
+		ToRevision:   snap.R(12),
+		Channel:      "stable",
+		Trigger:      "auto",
+		Time:         entries[0].Time,
+		Outcome:      "error",
+		ChangeID:     chg.ID(),
+	})
+	c.Check(entries[1].Snap, Equals, "other-snap")
+	c.Check(entries[1].Action, Equals, "install")
+	c.Check(entries[1].Outcome, Equals, "skipped")
+}
+
+func (s *snapmgrTestSuite) TestHistoryRecordedWithoutStateLock(c *C) {
+	s.state.Lock()
+	chg := s.state.NewChange("refresh-snap", "refresh a snap")
+	link := s.state.NewTask("link-snap", "")
+	link.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "some-snap",
+			Revision: snap.R(2),
+		},
+	})
+	chg.AddTask(link)
+	link.SetStatus(state.DoneStatus)
+	s.state.Unlock()
+
+	appendErr := errors.New("boom")
+	appendCalls := 0
+	restore := snapstate.MockHistoryAppend(func(entries []*snapstate.HistoryEntry) error {
+		appendCalls++
+		c.Assert(entries, HasLen, 1)
+		c.Check(entries[0].Snap, Equals, "some-snap")
+		// the state is not locked while writing the history
+		s.state.Lock()
+		var recorded bool
+		err := chg.Get("history-recorded", &recorded)
+		s.state.Unlock()
+		c.Check(err, Equals, state.ErrNoState)
+		return appendErr
+	})
+	defer restore()
+
+	err := s.snapmgr.RecordHistory()
+	c.Assert(err, ErrorMatches, "cannot record snap history: boom")
+	c.Check(appendCalls, Equals, 1)
+
+	// tried again
+	appendErr = nil
+	err = s.snapmgr.RecordHistory()
+	c.Assert(err, IsNil)
+	c.Check(appendCalls, Equals, 2)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	var recorded bool
+	c.Assert(chg.Get("history-recorded", &recorded), IsNil)
+	c.Check(recorded, Equals, true)
+}
+
+func (s *snapmgrTestSuite) TestHistoryRotation(c *C) {
+	restore := snapstate.MockMaxHistoryFileSize(1)
+	defer restore()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	for _, name := range []string{"snap-a", "snap-b", "snap-c"} {
+		chg := s.state.NewChange("refresh-snap", "refresh "+name)
+		link := s.state.NewTask("link-snap", "")
+		link.Set("snap-setup", &snapstate.SnapSetup{
+			SideInfo: &snap.SideInfo{
+				RealName: name,
+				Revision: snap.R(2),
+			},
+		})
+		link.Set("old-current", snap.R(1))
+		chg.AddTask(link)
+		link.SetStatus(state.DoneStatus)
+
+		s.state.Unlock()
+		err := s.snapmgr.RecordHistory()
+		s.state.Lock()
+		c.Assert(err, IsNil)
+	}
+
+	// only the current and the previous file are kept
+	entries, err := snapstate.History("")
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Check(entries[0].Snap, Equals, "snap-b")
+	c.Check(entries[1].Snap, Equals, "snap-c")
+
+	data, err := ioutil.ReadFile(filepath.Join(dirs.SnapHistoryDir, "history.json.1"))
+	c.Assert(err, IsNil)
+	c.Check(strings.Count(string(data), "\n"), Equals, 1)
+	c.Check(string(data), testutil.Contains, `"snap":"snap-b"`)
+}
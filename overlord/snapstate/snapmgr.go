@@ -873,6 +873,7 @@ func (m *SnapManager) Ensure() error {
 		m.refreshHints.Ensure(),
 		m.catalogRefresh.Ensure(),
 		m.localInstallCleanup(),
+		m.recordHistory(),
 	}
 
 	//FIXME: use firstErr helper
@@ -21,12 +21,16 @@
 package storecontext
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/url"
 	"os"
+	"sync"
 
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/asserts/sysdb"
+	"github.com/snapcore/snapd/httputil"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/state"
@@ -66,6 +70,8 @@ type DeviceBackend interface {
 type DeviceSessionRequestSigner interface {
 	// SignDeviceSessionRequest produces a signed device-session-request with for given serial assertion and nonce.
 	SignDeviceSessionRequest(serial *asserts.Serial, nonce string) (*asserts.DeviceSessionRequest, error)
+	// DeviceKeyCertificate produces a TLS client certificate backed by the device key for the given serial assertion.
+	DeviceKeyCertificate(serial *asserts.Serial) (*tls.Certificate, error)
 }
 
 type ProxyStoreer interface {
@@ -80,29 +86,68 @@ type storeContext struct {
 	deviceBackend    DeviceBackend
 	sessionReqSigner DeviceSessionRequestSigner
 	proxyStoreer     ProxyStoreer
+
+	// the TLS parameters are resolved with the state lock held and
+	// cached, as they are needed during TLS handshakes which can
+	// happen while the state is locked
+	tlsMu        sync.Mutex
+	tlsParams    *httputil.TLSParams
+	tlsParamsErr error
 }
 
 var _ store.DeviceAndAuthContext = (*storeContext)(nil)
 
 // New returns a store.DeviceAndAuthContext using the given full-featured Backend.
+// The state lock must be held.
 func New(st *state.State, b Backend) store.DeviceAndAuthContext {
 	if b == nil {
 		panic("store context backend cannot be nil")
 	}
-	return NewComposed(st, b, b, b)
+	sc := newStoreContext(st, b, b, b)
+	st.Cache(storeContextKey{}, sc)
+	return sc
 }
 
 // NewComposed returns a store.DeviceAndAuthContext using the given backends.
+// The state lock must be held.
 func NewComposed(st *state.State, devb DeviceBackend, srqs DeviceSessionRequestSigner, pstoer ProxyStoreer) store.DeviceAndAuthContext {
 	if devb == nil || srqs == nil || pstoer == nil {
 		panic("store context composable backends cannot be nil")
 	}
-	return &storeContext{
+	sc := newStoreContext(st, devb, srqs, pstoer)
+	// only one remodel can be in progress, the store context of a
+	// previous one is not updated anymore
+	st.Cache(composedStoreContextKey{}, sc)
+	return sc
+}
+
+func newStoreContext(st *state.State, devb DeviceBackend, srqs DeviceSessionRequestSigner, pstoer ProxyStoreer) *storeContext {
+	sc := &storeContext{
 		state:            st,
 		deviceBackend:    devb,
 		sessionReqSigner: srqs,
 		proxyStoreer:     pstoer,
 	}
+	sc.updateTLSParams()
+	return sc
+}
+
+type storeContextKey struct{}
+
+type composedStoreContextKey struct{}
+
+// UpdateTLSParams resolves again the TLS parameters of the store
+// contexts of the given state, to pick up changes to the store TLS
+// configuration, the proxy store, a new device serial or the renewal
+// of the device client certificate. Of the contexts created with
+// NewComposed only the last one is updated. The state lock must be
+// held.
+func UpdateTLSParams(st *state.State) {
+	for _, key := range []interface{}{storeContextKey{}, composedStoreContextKey{}} {
+		if sc, ok := st.Cached(key).(*storeContext); ok {
+			sc.updateTLSParams()
+		}
+	}
 }
 
 // Device returns current device state.
@@ -264,3 +309,86 @@ func (sc *storeContext) CloudInfo() (*auth.CloudInfo, error) {
 
 	return nil, nil
 }
+
+// TLSParams returns the extra TLS parameters to use when talking to
+// the store, that is the pinned public keys and the client certificate
+// as configured via store.pinned-public-keys and
+// store.client-certificate. If a proxy store is set the pinned public
+// keys apply to its host. It returns nil if none are configured.
+// It does not take the state lock, the parameters are the ones resolved
+// the last time UpdateTLSParams was called.
+func (sc *storeContext) TLSParams() (*httputil.TLSParams, error) {
+	sc.tlsMu.Lock()
+	defer sc.tlsMu.Unlock()
+	return sc.tlsParams, sc.tlsParamsErr
+}
+
+func (sc *storeContext) updateTLSParams() {
+	params, err := sc.resolveTLSParams()
+
+	sc.tlsMu.Lock()
+	defer sc.tlsMu.Unlock()
+	if err != nil && (sc.tlsParamsErr == nil || sc.tlsParamsErr.Error() != err.Error()) {
+		logger.Noticef("cannot resolve store TLS parameters: %v", err)
+	}
+	sc.tlsParams = params
+	sc.tlsParamsErr = err
+}
+
+func (sc *storeContext) resolveTLSParams() (*httputil.TLSParams, error) {
+	tr := config.NewTransaction(sc.state)
+	var pins, clientCert string
+	if err := tr.Get("core", "store.pinned-public-keys", &pins); err != nil && !config.IsNoOption(err) {
+		return nil, err
+	}
+	if err := tr.Get("core", "store.client-certificate", &clientCert); err != nil && !config.IsNoOption(err) {
+		return nil, err
+	}
+
+	pinnedKeys, err := httputil.ParsePinnedPublicKeys(pins)
+	if err != nil {
+		return nil, err
+	}
+
+	var cert *tls.Certificate
+	switch clientCert {
+	case "":
+		// nothing to do
+	case "device":
+		serial, err := sc.deviceBackend.Serial()
+		if err != nil && err != state.ErrNoState {
+			return nil, err
+		}
+		// without a serial there is no device identity to present yet
+		if serial != nil {
+			cert, err = sc.sessionReqSigner.DeviceKeyCertificate(serial)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported store client certificate %q", clientCert)
+	}
+
+	if len(pinnedKeys) == 0 && cert == nil {
+		return nil, nil
+	}
+
+	// the proxy store replaces both the store and the assertions
+	// service, resolve its host now as the state lock is not available
+	// during TLS handshakes
+	var pinnedHosts []string
+	sto, err := sc.proxyStoreer.ProxyStore()
+	if err != nil && err != state.ErrNoState {
+		return nil, err
+	}
+	if sto != nil && sto.URL() != nil {
+		pinnedHosts = []string{sto.URL().Hostname()}
+	}
+
+	return &httputil.TLSParams{
+		PinnedPublicKeys:  pinnedKeys,
+		PinnedHosts:       pinnedHosts,
+		ClientCertificate: cert,
+	}, nil
+}
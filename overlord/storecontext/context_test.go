@@ -20,6 +20,7 @@
 package storecontext_test
 
 import (
+	"crypto/tls"
 	"errors"
 	"net/url"
 	"os"
@@ -58,6 +59,18 @@ func (s *storeCtxSuite) SetUpTest(c *C) {
 	s.state = state.New(nil)
 }
 
+func (s *storeCtxSuite) newStoreContext(b storecontext.Backend) store.DeviceAndAuthContext {
+	s.state.Lock()
+	defer s.state.Unlock()
+	return storecontext.New(s.state, b)
+}
+
+func (s *storeCtxSuite) newComposedStoreContext(devb storecontext.DeviceBackend, srqs storecontext.DeviceSessionRequestSigner, pstoer storecontext.ProxyStoreer) store.DeviceAndAuthContext {
+	s.state.Lock()
+	defer s.state.Unlock()
+	return storecontext.NewComposed(s.state, devb, srqs, pstoer)
+}
+
 func (s *storeCtxSuite) TestUpdateUserAuth(c *C) {
 	s.state.Lock()
 	user, _ := auth.NewUser(s.state, "username", "email@test.com", "macaroon", []string{"discharge"})
@@ -65,7 +78,7 @@ func (s *storeCtxSuite) TestUpdateUserAuth(c *C) {
 
 	newDischarges := []string{"updated-discharge"}
 
-	storeCtx := storecontext.New(s.state, &testBackend{nothing: true})
+	storeCtx := s.newStoreContext(&testBackend{nothing: true})
 	user, err := storeCtx.UpdateUserAuth(user, newDischarges)
 	c.Check(err, IsNil)
 
@@ -90,7 +103,7 @@ func (s *storeCtxSuite) TestUpdateUserAuthOtherUpdate(c *C) {
 
 	newDischarges := []string{"updated-discharge"}
 
-	storeCtx := storecontext.New(s.state, &testBackend{nothing: true})
+	storeCtx := s.newStoreContext(&testBackend{nothing: true})
 	// last discharges win
 	curUser, err := storeCtx.UpdateUserAuth(user, newDischarges)
 	c.Assert(err, IsNil)
@@ -122,13 +135,13 @@ func (s *storeCtxSuite) TestUpdateUserAuthInvalid(c *C) {
 		Macaroon: "macaroon",
 	}
 
-	storeCtx := storecontext.New(s.state, &testBackend{nothing: true})
+	storeCtx := s.newStoreContext(&testBackend{nothing: true})
 	_, err := storeCtx.UpdateUserAuth(user, nil)
 	c.Assert(err, Equals, auth.ErrInvalidUser)
 }
 
 func (s *storeCtxSuite) TestDeviceForNonExistent(c *C) {
-	storeCtx := storecontext.New(s.state, &testBackend{nothing: true})
+	storeCtx := s.newStoreContext(&testBackend{nothing: true})
 
 	device, err := storeCtx.Device()
 	c.Check(err, IsNil)
@@ -137,7 +150,7 @@ func (s *storeCtxSuite) TestDeviceForNonExistent(c *C) {
 
 func (s *storeCtxSuite) TestDevice(c *C) {
 	device := &auth.DeviceState{Brand: "some-brand"}
-	storeCtx := storecontext.New(s.state, &testBackend{device: device})
+	storeCtx := s.newStoreContext(&testBackend{device: device})
 
 	deviceFromState, err := storeCtx.Device()
 	c.Check(err, IsNil)
@@ -146,7 +159,7 @@ func (s *storeCtxSuite) TestDevice(c *C) {
 
 func (s *storeCtxSuite) TestUpdateDeviceAuth(c *C) {
 	device := &auth.DeviceState{}
-	storeCtx := storecontext.New(s.state, &testBackend{device: device})
+	storeCtx := s.newStoreContext(&testBackend{device: device})
 
 	sessionMacaroon := "the-device-macaroon"
 	device, err := storeCtx.UpdateDeviceAuth(device, sessionMacaroon)
@@ -165,7 +178,7 @@ func (s *storeCtxSuite) TestUpdateDeviceAuthOtherUpdate(c *C) {
 	otherUpdateDevice.KeyID = "KEYID"
 
 	b := &testBackend{device: &otherUpdateDevice}
-	storeCtx := storecontext.New(s.state, b)
+	storeCtx := s.newStoreContext(b)
 
 	// the global store refreshing sessions is now serialized
 	// and is a no-op in this case, but we do need not to overwrite
@@ -183,7 +196,7 @@ func (s *storeCtxSuite) TestUpdateDeviceAuthOtherUpdate(c *C) {
 }
 
 func (s *storeCtxSuite) TestStoreParamsFallback(c *C) {
-	storeCtx := storecontext.New(s.state, &testBackend{nothing: true})
+	storeCtx := s.newStoreContext(&testBackend{nothing: true})
 
 	storeID, err := storeCtx.StoreID("store-id")
 	c.Assert(err, IsNil)
@@ -196,7 +209,7 @@ func (s *storeCtxSuite) TestStoreParamsFallback(c *C) {
 }
 
 func (s *storeCtxSuite) TestStoreIDFromEnv(c *C) {
-	storeCtx := storecontext.New(s.state, &testBackend{nothing: true})
+	storeCtx := s.newStoreContext(&testBackend{nothing: true})
 
 	os.Setenv("UBUNTU_STORE_ID", "env-store-id")
 	defer os.Unsetenv("UBUNTU_STORE_ID")
@@ -206,7 +219,7 @@ func (s *storeCtxSuite) TestStoreIDFromEnv(c *C) {
 }
 
 func (s *storeCtxSuite) TestCloudInfo(c *C) {
-	storeCtx := storecontext.New(s.state, &testBackend{nothing: true})
+	storeCtx := s.newStoreContext(&testBackend{nothing: true})
 
 	cloud, err := storeCtx.CloudInfo()
 	c.Assert(err, IsNil)
@@ -344,6 +357,13 @@ func (b *testBackend) SignDeviceSessionRequest(serial *asserts.Serial, nonce str
 	return aReq.(*asserts.DeviceSessionRequest), nil
 }
 
+func (b *testBackend) DeviceKeyCertificate(serial *asserts.Serial) (*tls.Certificate, error) {
+	if b.nothing {
+		return nil, state.ErrNoState
+	}
+	return &tls.Certificate{Certificate: [][]byte{[]byte(serial.Serial())}}, nil
+}
+
 func (b *testBackend) ProxyStore() (*asserts.Store, error) {
 	if b.nothing {
 		return nil, state.ErrNoState
@@ -357,7 +377,7 @@ func (b *testBackend) ProxyStore() (*asserts.Store, error) {
 
 func (s *storeCtxSuite) TestMissingDeviceAssertions(c *C) {
 	// no assertions in state
-	storeCtx := storecontext.New(s.state, &testBackend{nothing: true})
+	storeCtx := s.newStoreContext(&testBackend{nothing: true})
 
 	_, err := storeCtx.DeviceSessionRequestParams("NONCE")
 	c.Check(err, Equals, store.ErrNoSerial)
@@ -374,7 +394,7 @@ func (s *storeCtxSuite) TestMissingDeviceAssertions(c *C) {
 
 func (s *storeCtxSuite) TestWithDeviceAssertions(c *C) {
 	// having assertions in state
-	storeCtx := storecontext.New(s.state, &testBackend{})
+	storeCtx := s.newStoreContext(&testBackend{})
 
 	params, err := storeCtx.DeviceSessionRequestParams("NONCE-1")
 	c.Assert(err, IsNil)
@@ -415,7 +435,7 @@ func (s *storeCtxSuite) TestWithDeviceAssertionsGenericClassicModel(c *C) {
 	r := sysdb.MockGenericClassicModel(model.(*asserts.Model))
 	defer r()
 	// having assertions in state
-	storeCtx := storecontext.New(s.state, &testBackend{})
+	storeCtx := s.newStoreContext(&testBackend{})
 
 	// for the generic classic model we continue to consider the env var
 	os.Setenv("UBUNTU_STORE_ID", "env-store-id")
@@ -432,7 +452,7 @@ func (s *storeCtxSuite) TestWithDeviceAssertionsGenericClassicModelNoEnvVar(c *C
 	r := sysdb.MockGenericClassicModel(model.(*asserts.Model))
 	defer r()
 	// having assertions in state
-	storeCtx := storecontext.New(s.state, &testBackend{})
+	storeCtx := s.newStoreContext(&testBackend{})
 
 	// for the generic classic model we continue to consider the env var
 	// but when the env var is unset we don't do anything wrong.
@@ -448,11 +468,15 @@ func (srqs testFailingDeviceSessionRequestSigner) SignDeviceSessionRequest(seria
 	return nil, errors.New("boom")
 }
 
+func (srqs testFailingDeviceSessionRequestSigner) DeviceKeyCertificate(serial *asserts.Serial) (*tls.Certificate, error) {
+	return nil, errors.New("boom")
+}
+
 func (s *storeCtxSuite) TestComposable(c *C) {
 	b := &testBackend{}
 	bNoSerial := &testBackend{noSerial: true}
 
-	storeCtx := storecontext.NewComposed(s.state, b, bNoSerial, b)
+	storeCtx := s.newComposedStoreContext(b, bNoSerial, b)
 
 	params, err := storeCtx.DeviceSessionRequestParams("NONCE-1")
 	c.Assert(err, IsNil)
@@ -461,12 +485,145 @@ func (s *storeCtxSuite) TestComposable(c *C) {
 	c.Check(strings.Contains(req, "nonce: NONCE-1\n"), Equals, true)
 	c.Check(strings.Contains(req, "serial: 9999\n"), Equals, true)
 
-	storeCtx = storecontext.NewComposed(s.state, bNoSerial, b, b)
+	storeCtx = s.newComposedStoreContext(bNoSerial, b, b)
 	params, err = storeCtx.DeviceSessionRequestParams("NONCE-1")
 	c.Assert(err, Equals, store.ErrNoSerial)
 
 	srqs := testFailingDeviceSessionRequestSigner{}
-	storeCtx = storecontext.NewComposed(s.state, b, srqs, b)
+	storeCtx = s.newComposedStoreContext(b, srqs, b)
 	params, err = storeCtx.DeviceSessionRequestParams("NONCE-1")
 	c.Assert(err, ErrorMatches, "boom")
 }
+
+func (s *storeCtxSuite) TestTLSParamsNothingConfigured(c *C) {
+	storeCtx := s.newStoreContext(&testBackend{})
+
+	params, err := storeCtx.TLSParams()
+	c.Assert(err, IsNil)
+	c.Check(params, IsNil)
+}
+
+func (s *storeCtxSuite) setCoreConfig(c *C, key string, value interface{}) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", key, value), IsNil)
+	tr.Commit()
+	storecontext.UpdateTLSParams(s.state)
+}
+
+func (s *storeCtxSuite) TestTLSParams(c *C) {
+	storeCtx := s.newStoreContext(&testBackend{})
+
+	pin := "sha256/" + strings.Repeat("A", 43) + "="
+	s.setCoreConfig(c, "store.pinned-public-keys", pin)
+	s.setCoreConfig(c, "store.client-certificate", "device")
+
+	// the parameters are available without the state lock
+	s.state.Lock()
+	params, err := storeCtx.TLSParams()
+	s.state.Unlock()
+	c.Assert(err, IsNil)
+	c.Assert(params, NotNil)
+	c.Check(params.PinnedPublicKeys, DeepEquals, [][]byte{make([]byte, 32)})
+	c.Assert(params.ClientCertificate, NotNil)
+	c.Check(params.ClientCertificate.Certificate, DeepEquals, [][]byte{[]byte("9999")})
+}
+
+func (s *storeCtxSuite) TestTLSParamsProxyStore(c *C) {
+	pin := "sha256/" + strings.Repeat("A", 43) + "="
+	s.setCoreConfig(c, "store.pinned-public-keys", pin)
+
+	storeCtx := s.newStoreContext(&testBackend{})
+
+	params, err := storeCtx.TLSParams()
+	c.Assert(err, IsNil)
+	c.Assert(params, NotNil)
+	c.Check(params.PinnedHosts, DeepEquals, []string{"foo.internal"})
+
+	// no proxy store
+	storeCtx = s.newStoreContext(&testBackend{nothing: true})
+
+	params, err = storeCtx.TLSParams()
+	c.Assert(err, IsNil)
+	c.Assert(params, NotNil)
+	c.Check(params.PinnedHosts, IsNil)
+}
+
+func (s *storeCtxSuite) TestTLSParamsOnlyLastComposedUpdated(c *C) {
+	b := &testBackend{}
+	storeCtx := s.newStoreContext(b)
+	composedCtx1 := s.newComposedStoreContext(b, b, b)
+	composedCtx2 := s.newComposedStoreContext(b, b, b)
+
+	s.setCoreConfig(c, "store.client-certificate", "device")
+
+	for _, sc := range []store.DeviceAndAuthContext{storeCtx, composedCtx2} {
+		params, err := sc.TLSParams()
+		c.Assert(err, IsNil)
+		c.Assert(params, NotNil)
+		c.Check(params.ClientCertificate, NotNil)
+	}
+
+	// the previous composed context is not kept around
+	params, err := composedCtx1.TLSParams()
+	c.Assert(err, IsNil)
+	c.Check(params, IsNil)
+}
+
+func (s *storeCtxSuite) TestTLSParamsResolvedOnCreation(c *C) {
+	s.setCoreConfig(c, "store.client-certificate", "device")
+
+	storeCtx := s.newStoreContext(&testBackend{})
+
+	params, err := storeCtx.TLSParams()
+	c.Assert(err, IsNil)
+	c.Assert(params, NotNil)
+	c.Check(params.ClientCertificate, NotNil)
+}
+
+func (s *storeCtxSuite) TestTLSParamsOnlyUpdatedOnRequest(c *C) {
+	storeCtx := s.newStoreContext(&testBackend{})
+
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "store.client-certificate", "device"), IsNil)
+	tr.Commit()
+	s.state.Unlock()
+
+	params, err := storeCtx.TLSParams()
+	c.Assert(err, IsNil)
+	c.Check(params, IsNil)
+
+	s.state.Lock()
+	storecontext.UpdateTLSParams(s.state)
+	s.state.Unlock()
+
+	params, err = storeCtx.TLSParams()
+	c.Assert(err, IsNil)
+	c.Assert(params, NotNil)
+	c.Check(params.ClientCertificate, NotNil)
+}
+func (s *storeCtxSuite) TestTLSParamsNoSerialYet(c *C) {
+	storeCtx := s.newStoreContext(&testBackend{noSerial: true})
+
+	s.setCoreConfig(c, "store.client-certificate", "device")
+
+	params, err := storeCtx.TLSParams()
+	c.Assert(err, IsNil)
+	c.Check(params, IsNil)
+}
+
+func (s *storeCtxSuite) TestTLSParamsErrors(c *C) {
+	b := &testBackend{}
+	storeCtx := s.newComposedStoreContext(b, testFailingDeviceSessionRequestSigner{}, b)
+
+	s.setCoreConfig(c, "store.client-certificate", "device")
+	_, err := storeCtx.TLSParams()
+	c.Check(err, ErrorMatches, "boom")
+
+	s.setCoreConfig(c, "store.client-certificate", "")
+	s.setCoreConfig(c, "store.pinned-public-keys", "foo")
+	_, err = storeCtx.TLSParams()
+	c.Check(err, ErrorMatches, `invalid pinned public key "foo": .*`)
+}
@@ -0,0 +1,236 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package landlock supports confining processes with Landlock, an
+// unprivileged access control facility of the Linux kernel restricting
+// the filesystem hierarchies a process can access.
+package landlock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Access is an abstract kind of access to a filesystem hierarchy, it is
+// translated into the Landlock access rights supported by the kernel.
+type Access string
+
+const (
+	// AccessRead allows reading files and listing directories.
+	AccessRead Access = "read"
+	// AccessWrite allows writing files and, beneath directories,
+	// creating, renaming and removing entries.
+	AccessWrite Access = "write"
+	// AccessExecute allows executing files.
+	AccessExecute Access = "execute"
+	// AccessList allows listing directories, without reading the files
+	// beneath them.
+	AccessList Access = "list"
+)
+
+// Rule grants access to a file or to the hierarchy beneath a directory.
+//
+// The path may start with $SNAP_REAL_HOME or $XDG_RUNTIME_DIR, expanded
+// from the environment when the ruleset is applied, and may contain
+// shell glob patterns matching existing directories.
+type Rule struct {
+	Path   string   `json:"path"`
+	Access []Access `json:"access"`
+}
+
+// Ruleset is the set of accesses allowed to a confined process, any
+// other filesystem access is denied.
+type Ruleset struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Landlock filesystem access rights, see linux/landlock.h
+const (
+	accessFsExecute    = 1 << 0
+	accessFsWriteFile  = 1 << 1
+	accessFsReadFile   = 1 << 2
+	accessFsReadDir    = 1 << 3
+	accessFsRemoveDir  = 1 << 4
+	accessFsRemoveFile = 1 << 5
+	accessFsMakeChar   = 1 << 6
+	accessFsMakeDir    = 1 << 7
+	accessFsMakeReg    = 1 << 8
+	accessFsMakeSock   = 1 << 9
+	accessFsMakeFifo   = 1 << 10
+	accessFsMakeBlock  = 1 << 11
+	accessFsMakeSym    = 1 << 12
+	// since ABI version 2
+	accessFsRefer = 1 << 13
+	// since ABI version 3
+	accessFsTruncate = 1 << 14
+)
+
+// fileAccessRights are the rights that apply to files, rules for files
+// can only grant those.
+const fileAccessRights = accessFsExecute | accessFsWriteFile | accessFsReadFile | accessFsTruncate
+
+// handledAccessRights returns the access rights known to the given ABI
+// version, the ones that can be restricted.
+func handledAccessRights(abi int) uint64 {
+	var rights uint64
+	if abi >= 1 {
+		rights |= accessFsExecute | accessFsWriteFile | accessFsReadFile |
+			accessFsReadDir | accessFsRemoveDir | accessFsRemoveFile |
+			accessFsMakeChar | accessFsMakeDir | accessFsMakeReg |
+			accessFsMakeSock | accessFsMakeFifo | accessFsMakeBlock |
+			accessFsMakeSym
+	}
+	if abi >= 2 {
+		rights |= accessFsRefer
+	}
+	if abi >= 3 {
+		rights |= accessFsTruncate
+	}
+	return rights
+}
+
+// accessRights translates the abstract accesses into the Landlock
+// access rights supported by the given ABI version.
+func accessRights(access []Access, isDir bool, abi int) uint64 {
+	var rights uint64
+	for _, a := range access {
+		switch a {
+		case AccessRead:
+			rights |= accessFsReadFile | accessFsReadDir
+		case AccessWrite:
+			rights |= accessFsWriteFile | accessFsRemoveDir | accessFsRemoveFile |
+				accessFsMakeChar | accessFsMakeDir | accessFsMakeReg |
+				accessFsMakeSock | accessFsMakeFifo | accessFsMakeBlock |
+				accessFsMakeSym | accessFsRefer | accessFsTruncate
+		case AccessExecute:
+			rights |= accessFsExecute
+		case AccessList:
+			rights |= accessFsReadDir
+		}
+	}
+	if !isDir {
+		rights &= fileAccessRights
+	}
+	return rights & handledAccessRights(abi)
+}
+
+var abiProber = &abiProbe{}
+
+type abiProbe struct {
+	abi  int
+	once sync.Once
+}
+
+// ABI returns the version of the Landlock ABI supported by the kernel,
+// or 0 if Landlock is not supported or disabled.
+func ABI() int {
+	abiProber.once.Do(func() {
+		abiProber.abi = probeABI()
+	})
+	return abiProber.abi
+}
+
+// Features returns the list of Landlock features supported by the
+// kernel, or nil if Landlock is not supported.
+func Features() []string {
+	abi := ABI()
+	if abi == 0 {
+		return nil
+	}
+	features := []string{fmt.Sprintf("abi:%d", abi)}
+	if abi >= 2 {
+		features = append(features, "refer")
+	}
+	if abi >= 3 {
+		features = append(features, "truncate")
+	}
+	return features
+}
+
+// LoadRuleset reads a ruleset from the given file.
+func LoadRuleset(path string) (*Ruleset, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rs Ruleset
+	if err := json.Unmarshal(data, &rs); err != nil {
+		return nil, fmt.Errorf("cannot decode landlock ruleset %q: %v", path, err)
+	}
+	return &rs, nil
+}
+
+// expandRulePath returns the existing paths matched by the path of a
+// rule, after expanding the environment variables it may refer to.
+func expandRulePath(path string) []string {
+	missing := false
+	path = os.Expand(path, func(name string) string {
+		switch name {
+		case "SNAP_REAL_HOME", "XDG_RUNTIME_DIR":
+			value := os.Getenv(name)
+			if value == "" {
+				missing = true
+			}
+			return value
+		}
+		missing = true
+		return ""
+	})
+	if missing {
+		return nil
+	}
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return nil
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// Restrict restricts the calling thread, and the processes it
+// executes, to the accesses allowed by the ruleset. Rights unknown to
+// the kernel Landlock ABI are left unrestricted and paths that do not
+// exist are ignored.
+//
+// Landlock domains apply to threads: the calling goroutine must be
+// locked to its OS thread, and that thread must be the one executing
+// the confined program. Restrict also sets the no_new_privs attribute
+// of the thread, as required by Landlock.
+func (rs *Ruleset) Restrict() error {
+	abi := ABI()
+	if abi == 0 {
+		return fmt.Errorf("cannot apply landlock ruleset: landlock is not supported by the kernel")
+	}
+	return restrictSelf(rs, abi)
+}
+
+// MockABI mocks the Landlock ABI version supported by the kernel.
+func MockABI(abi int) (restore func()) {
+	old := abiProber
+	abiProber = &abiProbe{abi: abi}
+	abiProber.once.Do(func() {})
+	return func() {
+		abiProber = old
+	}
+}
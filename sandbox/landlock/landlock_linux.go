@@ -0,0 +1,109 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Landlock syscalls, they share the same number on all architectures
+const (
+	sysLandlockCreateRuleset = 444
+	sysLandlockAddRule       = 445
+	sysLandlockRestrictSelf  = 446
+)
+
+const (
+	landlockCreateRulesetVersion = 1 << 0
+	landlockRulePathBeneath      = 1
+)
+
+type rulesetAttr struct {
+	handledAccessFs uint64
+}
+
+// pathBeneathAttr matches the packed struct landlock_path_beneath_attr,
+// only its first 12 bytes are read by the kernel.
+type pathBeneathAttr struct {
+	allowedAccess uint64
+	parentFd      int32
+}
+
+func probeABI() int {
+	abi, _, errno := syscall.Syscall(sysLandlockCreateRuleset, 0, 0, landlockCreateRulesetVersion)
+	if errno != 0 {
+		return 0
+	}
+	return int(abi)
+}
+
+func restrictSelf(rs *Ruleset, abi int) error {
+	attr := rulesetAttr{handledAccessFs: handledAccessRights(abi)}
+	fd, _, errno := syscall.Syscall(sysLandlockCreateRuleset, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("cannot create landlock ruleset: %v", errno)
+	}
+	defer syscall.Close(int(fd))
+
+	for _, rule := range rs.Rules {
+		for _, path := range expandRulePath(rule.Path) {
+			if err := addPathRule(int(fd), path, rule.Access, abi); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("cannot set no_new_privs: %v", err)
+	}
+	if _, _, errno := syscall.RawSyscall(sysLandlockRestrictSelf, fd, 0, 0); errno != 0 {
+		return fmt.Errorf("cannot enforce landlock ruleset: %v", errno)
+	}
+	return nil
+}
+
+func addPathRule(rulesetFd int, path string, access []Access, abi int) error {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		// the path may have disappeared since it was matched
+		return nil
+	}
+	defer unix.Close(fd)
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		return nil
+	}
+	attr := pathBeneathAttr{
+		allowedAccess: accessRights(access, st.Mode&unix.S_IFMT == unix.S_IFDIR, abi),
+		parentFd:      int32(fd),
+	}
+	if attr.allowedAccess == 0 {
+		return nil
+	}
+	_, _, errno := syscall.Syscall6(sysLandlockAddRule, uintptr(rulesetFd), landlockRulePathBeneath, uintptr(unsafe.Pointer(&attr)), 0, 0, 0)
+	if errno != 0 {
+		return fmt.Errorf("cannot add landlock rule for %q: %v", path, errno)
+	}
+	return nil
+}
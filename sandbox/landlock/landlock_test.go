@@ -0,0 +1,122 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package landlock_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/sandbox/landlock"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type landlockSuite struct{}
+
+var _ = Suite(&landlockSuite{})
+
+func (s *landlockSuite) TestFeatures(c *C) {
+	restore := landlock.MockABI(0)
+	defer restore()
+	c.Check(landlock.ABI(), Equals, 0)
+	c.Check(landlock.Features(), IsNil)
+
+	restore = landlock.MockABI(1)
+	defer restore()
+	c.Check(landlock.Features(), DeepEquals, []string{"abi:1"})
+
+	restore = landlock.MockABI(3)
+	defer restore()
+	c.Check(landlock.Features(), DeepEquals, []string{"abi:3", "refer", "truncate"})
+}
+
+func (s *landlockSuite) TestRestrictUnsupported(c *C) {
+	restore := landlock.MockABI(0)
+	defer restore()
+	rs := &landlock.Ruleset{}
+	c.Check(rs.Restrict(), ErrorMatches, "cannot apply landlock ruleset: landlock is not supported by the kernel")
+}
+
+func (s *landlockSuite) TestAccessRightsDegradeWithABI(c *C) {
+	write := []landlock.Access{landlock.AccessWrite}
+
+	// rights unknown to the ABI are dropped
+	c.Check(landlock.AccessRights(write, true, 1)&landlock.AccessFsRefer, Equals, uint64(0))
+	c.Check(landlock.AccessRights(write, true, 1)&landlock.AccessFsTruncate, Equals, uint64(0))
+	c.Check(landlock.AccessRights(write, true, 2)&landlock.AccessFsRefer, Not(Equals), uint64(0))
+	c.Check(landlock.AccessRights(write, true, 3)&landlock.AccessFsTruncate, Not(Equals), uint64(0))
+	for abi := 1; abi <= 3; abi++ {
+		rights := landlock.AccessRights(write, true, abi)
+		c.Check(rights&^landlock.HandledAccessRights(abi), Equals, uint64(0))
+	}
+	c.Check(landlock.HandledAccessRights(0), Equals, uint64(0))
+}
+
+func (s *landlockSuite) TestAccessRightsFiles(c *C) {
+	all := []landlock.Access{landlock.AccessRead, landlock.AccessWrite, landlock.AccessExecute}
+	c.Check(landlock.AccessRights(all, false, 3), Equals,
+		uint64(landlock.AccessFsExecute|landlock.AccessFsWriteFile|landlock.AccessFsReadFile|landlock.AccessFsTruncate))
+	c.Check(landlock.AccessRights([]landlock.Access{landlock.AccessRead}, true, 3), Equals,
+		uint64(landlock.AccessFsReadFile|landlock.AccessFsReadDir))
+	c.Check(landlock.AccessRights([]landlock.Access{"unknown"}, true, 3), Equals, uint64(0))
+}
+
+func (s *landlockSuite) TestAccessRightsList(c *C) {
+	list := []landlock.Access{landlock.AccessList}
+	c.Check(landlock.AccessRights(list, true, 3), Equals, uint64(landlock.AccessFsReadDir))
+	// nothing to list in files
+	c.Check(landlock.AccessRights(list, false, 3), Equals, uint64(0))
+}
+
+func (s *landlockSuite) TestLoadRuleset(c *C) {
+	path := filepath.Join(c.MkDir(), "snap.foo.app.json")
+	c.Assert(ioutil.WriteFile(path, []byte(`{"rules":[{"path":"/usr","access":["read","execute"]}]}`), 0644), IsNil)
+	rs, err := landlock.LoadRuleset(path)
+	c.Assert(err, IsNil)
+	c.Check(rs, DeepEquals, &landlock.Ruleset{Rules: []landlock.Rule{
+		{Path: "/usr", Access: []landlock.Access{landlock.AccessRead, landlock.AccessExecute}},
+	}})
+
+	c.Assert(ioutil.WriteFile(path, []byte(`garbage`), 0644), IsNil)
+	_, err = landlock.LoadRuleset(path)
+	c.Check(err, ErrorMatches, `cannot decode landlock ruleset ".*": .*`)
+}
+
+func (s *landlockSuite) TestExpandRulePath(c *C) {
+	home := c.MkDir()
+	c.Assert(os.MkdirAll(filepath.Join(home, "a", "data"), 0755), IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(home, "b", "data"), 0755), IsNil)
+	os.Setenv("SNAP_REAL_HOME", home)
+	defer os.Unsetenv("SNAP_REAL_HOME")
+	os.Unsetenv("XDG_RUNTIME_DIR")
+
+	c.Check(landlock.ExpandRulePath("$SNAP_REAL_HOME/*/data"), DeepEquals, []string{
+		filepath.Join(home, "a", "data"),
+		filepath.Join(home, "b", "data"),
+	})
+	c.Check(landlock.ExpandRulePath("$SNAP_REAL_HOME/missing"), HasLen, 0)
+	// unset and unknown variables disable the rule
+	c.Check(landlock.ExpandRulePath("$XDG_RUNTIME_DIR/foo"), IsNil)
+	c.Check(landlock.ExpandRulePath("$HOME"), IsNil)
+}
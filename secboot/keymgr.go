@@ -0,0 +1,93 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package secboot
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/snapcore/snapd/osutil"
+)
+
+// runCryptsetup runs cryptsetup passing key on its standard input. The
+// optional newKey is made available to cryptsetup as /dev/fd/3 so that no key
+// material ever touches the disk.
+func runCryptsetup(key, newKey []byte, args ...string) error {
+	cmd := exec.Command("cryptsetup", args...)
+	cmd.Stdin = bytes.NewReader(key)
+	if newKey != nil {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		// the key is much smaller than the pipe buffer, thus writing
+		// does not block
+		_, err = w.Write(newKey)
+		w.Close()
+		if err != nil {
+			return err
+		}
+		cmd.ExtraFiles = []*os.File{r}
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return osutil.OutputErr(output, err)
+	}
+	return nil
+}
+
+// AddLUKS2ContainerKey adds newKey to a new keyslot of the LUKS2 container on
+// the block device given by node. An existing key is needed to authorize the
+// operation.
+func AddLUKS2ContainerKey(node string, existingKey, newKey []byte) error {
+	if len(newKey) == 0 {
+		return fmt.Errorf("cannot add an empty key to %s", node)
+	}
+	err := runCryptsetup(existingKey, newKey,
+		"luksAddKey", "--type", "luks2", "--key-file", "-", node, "/dev/fd/3")
+	if err != nil {
+		return fmt.Errorf("cannot add key to %s: %v", node, err)
+	}
+	return nil
+}
+
+// CheckLUKS2ContainerKey checks that the key unlocks a keyslot of the LUKS2
+// container on the block device given by node.
+func CheckLUKS2ContainerKey(node string, key []byte) error {
+	err := runCryptsetup(key, nil,
+		"open", "--test-passphrase", "--type", "luks2", "--key-file", "-", node)
+	if err != nil {
+		return fmt.Errorf("cannot unlock %s with key: %v", node, err)
+	}
+	return nil
+}
+
+// RemoveLUKS2ContainerKey removes the keyslot unlocked by the key from the
+// LUKS2 container on the block device given by node.
+func RemoveLUKS2ContainerKey(node string, key []byte) error {
+	err := runCryptsetup(key, nil,
+		"luksRemoveKey", "--type", "luks2", "--key-file", "-", node)
+	if err != nil {
+		return fmt.Errorf("cannot remove key from %s: %v", node, err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+// +build !nosecboot
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package secboot_test
+
+import (
+	"fmt"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/secboot"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type keymgrSuite struct {
+	testutil.BaseTest
+
+	d string
+}
+
+var _ = Suite(&keymgrSuite{})
+
+func (s *keymgrSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.d = c.MkDir()
+}
+
+func (s *keymgrSuite) mockCryptsetup(c *C, fail bool) *testutil.MockCmd {
+	script := fmt.Sprintf(`
+cat > %[1]s/stdin
+if [ -e /dev/fd/3 ]; then
+    cat /dev/fd/3 > %[1]s/fd3
+fi
+`, s.d)
+	if fail {
+		script += "echo 'No key available with this passphrase.' >&2; exit 2\n"
+	}
+	cmd := testutil.MockCommand(c, "cryptsetup", script)
+	s.AddCleanup(cmd.Restore)
+	return cmd
+}
+
+func (s *keymgrSuite) TestAddKey(c *C) {
+	cmd := s.mockCryptsetup(c, false)
+
+	err := secboot.AddLUKS2ContainerKey("/dev/vda4", []byte("old-key"), []byte("new-key"))
+	c.Assert(err, IsNil)
+	c.Check(cmd.Calls(), DeepEquals, [][]string{
+		{"cryptsetup", "luksAddKey", "--type", "luks2", "--key-file", "-", "/dev/vda4", "/dev/fd/3"},
+	})
+	c.Check(filepath.Join(s.d, "stdin"), testutil.FileEquals, "old-key")
+	c.Check(filepath.Join(s.d, "fd3"), testutil.FileEquals, "new-key")
+}
+
+func (s *keymgrSuite) TestAddKeyErrors(c *C) {
+	cmd := s.mockCryptsetup(c, true)
+
+	err := secboot.AddLUKS2ContainerKey("/dev/vda4", []byte("old-key"), nil)
+	c.Assert(err, ErrorMatches, "cannot add an empty key to /dev/vda4")
+	c.Check(cmd.Calls(), HasLen, 0)
+
+	err = secboot.AddLUKS2ContainerKey("/dev/vda4", []byte("old-key"), []byte("new-key"))
+	c.Assert(err, ErrorMatches, "cannot add key to /dev/vda4: No key available with this passphrase.")
+}
+
+func (s *keymgrSuite) TestCheckKey(c *C) {
+	cmd := s.mockCryptsetup(c, false)
+
+	err := secboot.CheckLUKS2ContainerKey("/dev/vda4", []byte("some-key"))
+	c.Assert(err, IsNil)
+	c.Check(cmd.Calls(), DeepEquals, [][]string{
+		{"cryptsetup", "open", "--test-passphrase", "--type", "luks2", "--key-file", "-", "/dev/vda4"},
+	})
+	c.Check(filepath.Join(s.d, "stdin"), testutil.FileEquals, "some-key")
+	c.Check(filepath.Join(s.d, "fd3"), testutil.FileAbsent)
+}
+
+func (s *keymgrSuite) TestCheckKeyError(c *C) {
+	s.mockCryptsetup(c, true)
+
+	err := secboot.CheckLUKS2ContainerKey("/dev/vda4", []byte("some-key"))
+	c.Assert(err, ErrorMatches, "cannot unlock /dev/vda4 with key: No key available with this passphrase.")
+}
+
+func (s *keymgrSuite) TestRemoveKey(c *C) {
+	cmd := s.mockCryptsetup(c, false)
+
+	err := secboot.RemoveLUKS2ContainerKey("/dev/vda4", []byte("some-key"))
+	c.Assert(err, IsNil)
+	c.Check(cmd.Calls(), DeepEquals, [][]string{
+		{"cryptsetup", "luksRemoveKey", "--type", "luks2", "--key-file", "-", "/dev/vda4"},
+	})
+	c.Check(filepath.Join(s.d, "stdin"), testutil.FileEquals, "some-key")
+}
+
+func (s *keymgrSuite) TestRemoveKeyError(c *C) {
+	s.mockCryptsetup(c, true)
+
+	err := secboot.RemoveLUKS2ContainerKey("/dev/vda4", []byte("some-key"))
+	c.Assert(err, ErrorMatches, "cannot remove key from /dev/vda4: No key available with this passphrase.")
+}
@@ -102,6 +102,10 @@ type UnlockVolumeUsingSealedKeyOptions struct {
 	// AllowRecoveryKey when true indicates activation with the recovery key
 	// will be attempted if activation with the sealed key failed.
 	AllowRecoveryKey bool
+	// AllowPassphrase when true indicates activation with a passphrase added
+	// to the volume will be attempted if activation with the sealed key
+	// failed. The passphrase is asked for before the recovery key.
+	AllowPassphrase bool
 }
 
 // UnlockMethod is the method that was used to unlock a volume.
@@ -122,6 +126,9 @@ const (
 	UnlockedWithKey
 	// UnlockStatusUnknown indicates that the unlock status of the device is not clear.
 	UnlockStatusUnknown
+	// UnlockedWithPassphrase indicates that the device was unlocked by the
+	// user providing a passphrase added to the device at the prompt.
+	UnlockedWithPassphrase
 )
 
 // UnlockResult is the result of trying to unlock a volume.
@@ -141,6 +148,7 @@ type UnlockResult struct {
 	// - UnlockedWithRecoveryKey
 	// - UnlockedWithSealedKey
 	// - UnlockedWithKey
+	// - UnlockedWithPassphrase
 	UnlockMethod UnlockMethod
 }
 
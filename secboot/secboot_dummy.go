@@ -35,3 +35,7 @@ func SealKeys(keys []SealKeyRequest, params *SealKeysParams) error {
 func ResealKeys(params *ResealKeysParams) error {
 	return fmt.Errorf("build without secboot support")
 }
+
+func DescribePCRProtectionProfile(modelParams []*SealKeyModelParams) (string, error) {
+	return "", fmt.Errorf("build without secboot support")
+}
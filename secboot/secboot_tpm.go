@@ -30,6 +30,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/canonical/go-tpm2"
@@ -489,20 +490,27 @@ func unlockVolumeUsingSealedKeySecboot(name, sealedEncryptionKeyFile, sourceDevi
 	// and endorsement hierarchies, but the device will remain visible to the operating system.
 	tpmDeviceAvailable := tpmErr == nil && isTPMEnabled(tpm)
 
-	// if we don't have a tpm, and we allow using a recovery key, do that
-	// directly
-	if !tpmDeviceAvailable && opts.AllowRecoveryKey {
-		if err := UnlockEncryptedVolumeWithRecoveryKey(mapperName, sourceDevice); err != nil {
+	// if we don't have a tpm, and we allow using a passphrase or a recovery
+	// key, do that directly
+	if !tpmDeviceAvailable && (opts.AllowPassphrase || opts.AllowRecoveryKey) {
+		method, err := unlockEncryptedPartitionWithPassphraseOrRecoveryKey(mapperName, sourceDevice, opts)
+		res.UnlockMethod = method
+		if err != nil {
 			return res, err
 		}
 		res.FsDevice = targetDevice
-		res.UnlockMethod = UnlockedWithRecoveryKey
 		return res, nil
 	}
 
 	// otherwise we have a tpm and we should use the sealed key first, but
-	// this method will fallback to using the recovery key if enabled
-	method, err := unlockEncryptedPartitionWithSealedKey(tpm, mapperName, sourceDevice, sealedEncryptionKeyFile, "", opts.AllowRecoveryKey)
+	// this method will fallback to using the recovery key if enabled, unless
+	// the passphrase needs to be asked for before it
+	allowRecovery := opts.AllowRecoveryKey && !opts.AllowPassphrase
+	method, err := unlockEncryptedPartitionWithSealedKey(tpm, mapperName, sourceDevice, sealedEncryptionKeyFile, "", allowRecovery)
+	if method == NotUnlocked && opts.AllowPassphrase {
+		logger.Noticef("%v", err)
+		method, err = unlockEncryptedPartitionWithPassphraseOrRecoveryKey(mapperName, sourceDevice, opts)
+	}
 	res.UnlockMethod = method
 	if err == nil {
 		res.FsDevice = targetDevice
@@ -554,6 +562,61 @@ func UnlockEncryptedVolumeWithRecoveryKey(name, device string) error {
 	return nil
 }
 
+// passphraseTries is the number of times the user is asked for the
+// passphrase of an encrypted device.
+const passphraseTries = 3
+
+func askPassphrase(device string) (string, error) {
+	prompt := fmt.Sprintf("Please enter the passphrase for disk %s:", device)
+	cmd := exec.Command("systemd-ask-password", "--icon", "drive-harddisk", "--id", "snap-bootstrap:"+device, prompt)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", osutil.OutputErr(out, err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// UnlockEncryptedVolumeWithPassphrase prompts for a passphrase added to the
+// volume and uses it to open the volume. Entering an empty passphrase gives up
+// on it.
+func UnlockEncryptedVolumeWithPassphrase(name, device string) error {
+	for i := 0; i < passphraseTries; i++ {
+		passphrase, err := askPassphrase(device)
+		if err != nil {
+			return fmt.Errorf("cannot ask for the passphrase of encrypted device %q: %v", device, err)
+		}
+		if passphrase == "" {
+			break
+		}
+		err = unlockEncryptedPartitionWithKey(name, device, []byte(passphrase))
+		if err == nil {
+			return nil
+		}
+		logger.Noticef("cannot activate encrypted device %q with the passphrase: %v", device, err)
+	}
+	return fmt.Errorf("cannot unlock encrypted device %q with a passphrase", device)
+}
+
+// unlockEncryptedPartitionWithPassphraseOrRecoveryKey opens an encrypted device
+// with a passphrase or the recovery key, in that order, as allowed by the
+// options.
+func unlockEncryptedPartitionWithPassphraseOrRecoveryKey(name, device string, opts *UnlockVolumeUsingSealedKeyOptions) (UnlockMethod, error) {
+	if opts.AllowPassphrase {
+		err := UnlockEncryptedVolumeWithPassphrase(name, device)
+		if err == nil {
+			return UnlockedWithPassphrase, nil
+		}
+		if !opts.AllowRecoveryKey {
+			return NotUnlocked, err
+		}
+		logger.Noticef("%v, trying the recovery key", err)
+	}
+	if err := UnlockEncryptedVolumeWithRecoveryKey(name, device); err != nil {
+		return NotUnlocked, err
+	}
+	return UnlockedWithRecoveryKey, nil
+}
+
 func isActivatedWithRecoveryKey(err error) bool {
 	if err == nil {
 		return false
@@ -703,6 +766,20 @@ func ResealKeys(params *ResealKeysParams) error {
 	return sbUpdateKeyPCRProtectionPolicyMultiple(tpm, params.KeyFiles, authKey, pcrProfile)
 }
 
+// DescribePCRProtectionProfile returns a human readable description of the PCR
+// protection profile that sealing or resealing the keys with the given model
+// parameters would use.
+func DescribePCRProtectionProfile(modelParams []*SealKeyModelParams) (string, error) {
+	if len(modelParams) < 1 {
+		return "", fmt.Errorf("at least one set of model-specific parameters is required")
+	}
+	pcrProfile, err := buildPCRProtectionProfile(modelParams)
+	if err != nil {
+		return "", err
+	}
+	return pcrProfile.String(), nil
+}
+
 func buildPCRProtectionProfile(modelParams []*SealKeyModelParams) (*sb.PCRProtectionProfile, error) {
 	numModels := len(modelParams)
 	modelPCRProfiles := make([]*sb.PCRProtectionProfile, 0, numModels)
@@ -30,6 +30,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -556,6 +557,132 @@ func (s *secbootSuite) TestUnlockVolumeUsingSealedKeyIfEncrypted(c *C) {
 	}
 }
 
+func (s *secbootSuite) TestUnlockVolumeUsingSealedKeyIfEncryptedPassphrase(c *C) {
+	mockDisk := &disks.MockDiskMapping{
+		FilesystemLabelToPartUUID: map[string]string{
+			"name-enc": "enc-dev-partuuid",
+		},
+	}
+	devicePath := "/dev/disk/by-partuuid/enc-dev-partuuid"
+
+	restore := secboot.MockRandomKernelUUID(func() string {
+		return "random-uuid"
+	})
+	defer restore()
+
+	for idx, tc := range []struct {
+		tpmErr          error
+		sealedKeyWorks  bool
+		rkAllow         bool
+		answers         []string // the passphrases entered at the prompt
+		expAsked        int
+		expUnlockMethod secboot.UnlockMethod
+		err             string
+	}{
+		{
+			// the sealed key is used first
+			sealedKeyWorks: true, rkAllow: true,
+			expUnlockMethod: secboot.UnlockedWithSealedKey,
+		}, {
+			// the passphrase is asked for when the sealed key fails
+			rkAllow: true, answers: []string{"open sesame"},
+			expAsked: 1, expUnlockMethod: secboot.UnlockedWithPassphrase,
+		}, {
+			// the passphrase can be retried
+			rkAllow: true, answers: []string{"wrong", "open sesame"},
+			expAsked: 2, expUnlockMethod: secboot.UnlockedWithPassphrase,
+		}, {
+			// the recovery key is asked for after too many wrong passphrases
+			rkAllow: true, answers: []string{"wrong", "wrong", "wrong", "open sesame"},
+			expAsked: 3, expUnlockMethod: secboot.UnlockedWithRecoveryKey,
+		}, {
+			// an empty passphrase skips to the recovery key
+			rkAllow: true, answers: []string{""},
+			expAsked: 1, expUnlockMethod: secboot.UnlockedWithRecoveryKey,
+		}, {
+			// without a TPM the passphrase is asked for directly
+			tpmErr: sb.ErrNoTPM2Device, rkAllow: true, answers: []string{"open sesame"},
+			expAsked: 1, expUnlockMethod: secboot.UnlockedWithPassphrase,
+		}, {
+			// no recovery key to fall back to
+			tpmErr: sb.ErrNoTPM2Device, answers: []string{"wrong", "wrong", "wrong"},
+			expAsked: 3, expUnlockMethod: secboot.NotUnlocked,
+			err: `cannot unlock encrypted device ".*/enc-dev-partuuid" with a passphrase`,
+		},
+	} {
+		c.Logf("tc %v: %+v", idx, tc)
+		_, restoreConnect := mockSbTPMConnection(c, tc.tpmErr)
+		defer restoreConnect()
+		restore := secboot.MockIsTPMEnabled(func(tpm *sb.TPMConnection) bool {
+			return true
+		})
+		defer restore()
+
+		answersDir := c.MkDir()
+		answers := strings.Join(tc.answers, "\n") + "\n"
+		c.Assert(ioutil.WriteFile(filepath.Join(answersDir, "answers"), []byte(answers), 0644), IsNil)
+		askPassword := testutil.MockCommand(c, "systemd-ask-password", fmt.Sprintf(`
+n=$(cat %[1]s/n 2>/dev/null || echo 0)
+n=$((n+1))
+echo $n > %[1]s/n
+sed -n "${n}p" %[1]s/answers
+`, answersDir))
+		defer askPassword.Restore()
+
+		restore = secboot.MockSbActivateVolumeWithTPMSealedKey(func(tpm *sb.TPMConnection, volumeName, sourceDevicePath,
+			keyPath string, pinReader io.Reader, options *sb.ActivateVolumeOptions) (bool, error) {
+			// the recovery key is not asked for before the passphrase
+			c.Check(options.RecoveryKeyTries, Equals, 0)
+			if tc.sealedKeyWorks {
+				return true, nil
+			}
+			return false, errors.New("activation error")
+		})
+		defer restore()
+
+		restore = secboot.MockSbActivateVolumeWithKey(func(volumeName, sourceDevicePath string, key []byte,
+			options *sb.ActivateVolumeOptions) error {
+			c.Check(volumeName, Equals, "name-random-uuid")
+			c.Check(sourceDevicePath, Equals, devicePath)
+			if string(key) != "open sesame" {
+				return errors.New("wrong passphrase")
+			}
+			return nil
+		})
+		defer restore()
+
+		restore = secboot.MockSbActivateVolumeWithRecoveryKey(func(name, device string, keyReader io.Reader,
+			options *sb.ActivateVolumeOptions) error {
+			c.Check(tc.rkAllow, Equals, true)
+			return nil
+		})
+		defer restore()
+
+		opts := &secboot.UnlockVolumeUsingSealedKeyOptions{
+			AllowRecoveryKey: tc.rkAllow,
+			AllowPassphrase:  true,
+		}
+		unlockRes, err := secboot.UnlockVolumeUsingSealedKeyIfEncrypted(mockDisk, "name", "vanilla-keyfile", opts)
+		if tc.err == "" {
+			c.Assert(err, IsNil)
+			c.Check(unlockRes.FsDevice, Equals, "/dev/mapper/name-random-uuid")
+		} else {
+			c.Assert(err, ErrorMatches, tc.err)
+			c.Check(unlockRes.FsDevice, Equals, "")
+		}
+		c.Check(unlockRes.UnlockMethod, Equals, tc.expUnlockMethod)
+
+		calls := askPassword.Calls()
+		c.Check(calls, HasLen, tc.expAsked)
+		for _, call := range calls {
+			c.Check(call, DeepEquals, []string{
+				"systemd-ask-password", "--icon", "drive-harddisk", "--id", "snap-bootstrap:" + devicePath,
+				"Please enter the passphrase for disk " + devicePath + ":",
+			})
+		}
+	}
+}
+
 func (s *secbootSuite) TestEFIImageFromBootFile(c *C) {
 	tmpDir := c.MkDir()
 
@@ -1050,6 +1177,50 @@ func (s *secbootSuite) TestResealKey(c *C) {
 	}
 }
 
+func (s *secbootSuite) TestDescribePCRProtectionProfile(c *C) {
+	_, err := secboot.DescribePCRProtectionProfile(nil)
+	c.Assert(err, ErrorMatches, "at least one set of model-specific parameters is required")
+
+	mockEFI := bootloader.NewBootFile("", filepath.Join(c.MkDir(), "file.efi"), bootloader.RoleRecovery)
+	c.Assert(ioutil.WriteFile(mockEFI.Path, nil, 0644), IsNil)
+	modelParams := []*secboot.SealKeyModelParams{
+		{
+			EFILoadChains:  []*secboot.LoadChain{secboot.NewLoadChain(mockEFI)},
+			KernelCmdlines: []string{"cmdline"},
+			Model:          &asserts.Model{},
+		},
+	}
+
+	restore := secboot.MockSbAddEFISecureBootPolicyProfile(func(profile *sb.PCRProtectionProfile, params *sb.EFISecureBootPolicyProfileParams) error {
+		profile.AddPCRValue(tpm2.HashAlgorithmSHA256, 7, make([]byte, 32))
+		return nil
+	})
+	defer restore()
+	restore = secboot.MockSbAddEFIBootManagerProfile(func(profile *sb.PCRProtectionProfile, params *sb.EFIBootManagerProfileParams) error {
+		return nil
+	})
+	defer restore()
+	restore = secboot.MockSbAddSystemdEFIStubProfile(func(profile *sb.PCRProtectionProfile, params *sb.SystemdEFIStubProfileParams) error {
+		c.Check(params.KernelCmdlines, DeepEquals, []string{"cmdline"})
+		return nil
+	})
+	defer restore()
+	addSnapModelErr := errors.New("some error")
+	restore = secboot.MockSbAddSnapModelProfile(func(profile *sb.PCRProtectionProfile, params *sb.SnapModelProfileParams) error {
+		return addSnapModelErr
+	})
+	defer restore()
+
+	_, err = secboot.DescribePCRProtectionProfile(modelParams)
+	c.Assert(err, ErrorMatches, "cannot add snap model profile: some error")
+
+	addSnapModelErr = nil
+	desc, err := secboot.DescribePCRProtectionProfile(modelParams)
+	c.Assert(err, IsNil)
+	expected := sb.NewPCRProtectionProfile().AddPCRValue(tpm2.HashAlgorithmSHA256, 7, make([]byte, 32))
+	c.Check(desc, Equals, expected.String())
+}
+
 func (s *secbootSuite) TestSealKeyNoModelParams(c *C) {
 	myKeys := []secboot.SealKeyRequest{
 		{
@@ -123,6 +123,12 @@ type EssentialMetaLoaderSeed interface {
 	LoadEssentialMeta(essentialTypes []snap.Type, tm timings.Measurer) error
 }
 
+// ValidateUC20SeedSystemLabel checks whether the string is a valid Core 20
+// recovery system seed label.
+func ValidateUC20SeedSystemLabel(label string) error {
+	return internal.ValidateUC20SeedSystemLabel(label)
+}
+
 // Open returns a Seed implementation for the seed at seedDir.
 // label if not empty is used to identify a Core 20 recovery system seed.
 func Open(seedDir, label string) (Seed, error) {
@@ -1134,6 +1134,20 @@ var SanitizePlugsSlots = func(snapInfo *Info) {
 	panic("SanitizePlugsSlots function not set")
 }
 
+func MockValidatePlugsSlotsAttrs(f func(snapInfo *Info, strict bool) error) (restore func()) {
+	old := ValidatePlugsSlotsAttrs
+	ValidatePlugsSlotsAttrs = f
+	return func() { ValidatePlugsSlotsAttrs = old }
+}
+
+// ValidatePlugsSlotsAttrs checks the attributes of the plugs and slots of the
+// given snap against the schema declared by their interfaces. Attributes
+// unknown to the interfaces are only rejected if strict is set. It is set by
+// the interfaces/builtin package, attributes are not checked otherwise.
+var ValidatePlugsSlotsAttrs = func(snapInfo *Info, strict bool) error {
+	return nil
+}
+
 // ReadInfo reads the snap information for the installed snap with the given
 // name and given side-info.
 func ReadInfo(name string, si *SideInfo) (*Info, error) {
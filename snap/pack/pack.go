@@ -97,6 +97,11 @@ func debArchitecture(info *snap.Info) string {
 func CheckSkeleton(w io.Writer, sourceDir string) error {
 	info, err := loadAndValidate(sourceDir)
 	if err == nil {
+		// misspelled attributes are only an error when checking
+		// the snap being built
+		if err := snap.ValidatePlugsSlotsAttrs(info, true); err != nil {
+			return fmt.Errorf("cannot validate snap %q: %v", info.InstanceName(), err)
+		}
 		snap.SanitizePlugsSlots(info)
 		if len(info.BadInterfaces) > 0 {
 			fmt.Fprintln(w, snap.BadInterfacesSummary(info))
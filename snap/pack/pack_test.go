@@ -152,6 +152,24 @@ apps:
 	c.Check(buf.String(), Equals, "")
 }
 
+func (s *packSuite) TestCheckSkeletonReportsBadAttrs(c *C) {
+	var buf bytes.Buffer
+	sourceDir := makeExampleSnapSourceDir(c, `name: hello
+version: 0
+apps:
+ foo:
+  command: bin/hello-world
+plugs:
+ shared:
+  interface: content
+  target: $SNAP/shared
+  defualt-provider: other
+`)
+	err := pack.CheckSkeleton(&buf, sourceDir)
+	c.Assert(err, ErrorMatches, `cannot validate snap "hello": invalid plug "shared" \(interface "content"\): unknown attribute "defualt-provider"`)
+	c.Check(buf.String(), Equals, "")
+}
+
 func (s *packSuite) TestPackExcludesBackups(c *C) {
 	sourceDir := makeExampleSnapSourceDir(c, "{name: hello, version: 0}")
 	target := c.MkDir()
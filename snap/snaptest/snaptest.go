@@ -129,6 +129,8 @@ func MockInfo(c *check.C, yamlText string, sideInfo *snap.SideInfo) *snap.Info {
 
 	restoreSanitize := snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {})
 	defer restoreSanitize()
+	restoreValidateAttrs := snap.MockValidatePlugsSlotsAttrs(func(snapInfo *snap.Info, strict bool) error { return nil })
+	defer restoreValidateAttrs()
 	snapInfo, err := snap.InfoFromSnapYaml([]byte(yamlText))
 	c.Assert(err, check.IsNil)
 	if snapInfo.InstanceName() == "core" && snapInfo.Type() != snap.TypeOS {
@@ -155,6 +157,8 @@ func MockInvalidInfo(c *check.C, yamlText string, sideInfo *snap.SideInfo) *snap
 
 	restoreSanitize := snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {})
 	defer restoreSanitize()
+	restoreValidateAttrs := snap.MockValidatePlugsSlotsAttrs(func(snapInfo *snap.Info, strict bool) error { return nil })
+	defer restoreValidateAttrs()
 
 	snapInfo, err := snap.InfoFromSnapYaml([]byte(yamlText))
 	c.Assert(err, check.IsNil)
@@ -221,6 +225,8 @@ func MakeTestSnapInfoWithFiles(c *check.C, snapYamlContent string, files [][]str
 	PopulateDir(snapSource, files)
 	restoreSanitize := snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {})
 	defer restoreSanitize()
+	restoreValidateAttrs := snap.MockValidatePlugsSlotsAttrs(func(snapInfo *snap.Info, strict bool) error { return nil })
+	defer restoreValidateAttrs()
 
 	// Parse the yaml (we need the Name).
 	snapInfo, err := snap.InfoFromSnapYaml([]byte(snapYamlContent))
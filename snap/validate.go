@@ -368,6 +368,11 @@ func Validate(info *Info) error {
 		return err
 	}
 
+	// Ensure that plug and slot attributes match their interfaces.
+	if err := ValidatePlugsSlotsAttrs(info, false); err != nil {
+		return err
+	}
+
 	// Ensure that base field is valid
 	if err := ValidateBase(info); err != nil {
 		return err
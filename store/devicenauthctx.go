@@ -24,6 +24,7 @@ import (
 	"net/url"
 
 	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/httputil"
 	"github.com/snapcore/snapd/overlord/auth"
 )
 
@@ -41,6 +42,12 @@ type DeviceAndAuthContext interface {
 	ProxyStoreParams(defaultURL *url.URL) (proxyStoreID string, proxySroreURL *url.URL, err error)
 
 	CloudInfo() (*auth.CloudInfo, error)
+
+	// TLSParams returns the pinned public keys and client certificate
+	// to use for connections to the store, if any. The pinned hosts
+	// are set only if a proxy store is used, to its host. It must not
+	// need the state lock as it is called during TLS handshakes.
+	TLSParams() (*httputil.TLSParams, error)
 }
 
 // DeviceSessionRequestParams gathers the assertions and information to be sent to request a device session.
@@ -37,6 +37,12 @@ var (
 	// ErrInvalidScope is returned from Find when an invalid scope is requested.
 	ErrInvalidScope = errors.New("invalid scope")
 
+	// ErrInvalidSort is returned from Find when an invalid sort order is requested.
+	ErrInvalidSort = errors.New("invalid sort order")
+
+	// ErrInvalidConfinement is returned from Find when filtering by an invalid confinement is requested.
+	ErrInvalidConfinement = errors.New("invalid confinement")
+
 	// ErrSnapNotFound is returned when a snap can not be found
 	ErrSnapNotFound = errors.New("snap not found")
 
@@ -30,6 +30,7 @@ import (
 	"github.com/juju/ratelimit"
 	"gopkg.in/retry.v1"
 
+	"github.com/snapcore/snapd/httputil"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/progress"
 	"github.com/snapcore/snapd/snap"
@@ -195,6 +196,10 @@ func (sto *Store) SessionUnlock() {
 	sto.sessionMu.Unlock()
 }
 
+func (sto *Store) TLSParams() (*httputil.TLSParams, error) {
+	return storeTLSParams{sto}.TLSParams()
+}
+
 func (sto *Store) FindFields() []string {
 	return sto.findFields
 }
@@ -0,0 +1,216 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/snapcore/snapd/logger"
+)
+
+// RecordedRequest is a store request as recorded in SNAPD_STORE_RECORD
+// mode.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a store response as recorded in
+// SNAPD_STORE_RECORD mode.
+type RecordedResponse struct {
+	StatusCode int         `json:"status-code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// RecordedExchange is a request/response pair as recorded in
+// SNAPD_STORE_RECORD mode, with authentication macaroons and
+// credentials scrubbed.
+type RecordedExchange struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+const scrubbed = "SCRUBBED"
+
+var (
+	recordMu   sync.Mutex
+	recordNext int
+
+	macaroonAttrRegexp = regexp.MustCompile(`="[^"]*"`)
+)
+
+// ReadRecordedExchanges reads the exchanges recorded in the given
+// directory, in the order they were recorded.
+func ReadRecordedExchanges(dir string) ([]*RecordedExchange, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	exchanges := make([]*RecordedExchange, 0, len(names))
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		var exchange RecordedExchange
+		if err := json.Unmarshal(data, &exchange); err != nil {
+			return nil, fmt.Errorf("cannot decode recorded store exchange %s: %v", name, err)
+		}
+		exchanges = append(exchanges, &exchange)
+	}
+	return exchanges, nil
+}
+
+// maybeRecord records the given exchange if in SNAPD_STORE_RECORD mode
+// (s.recordDir is set). The response body is consumed and replaced by
+// an equivalent one, an error is returned only if reading it fails.
+// Failing to record is logged but otherwise ignored.
+func (s *Store) maybeRecord(req *http.Request, reqBody []byte, resp *http.Response) error {
+	dir := s.recordDir
+	if dir == "" {
+		return nil
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	exchange := &RecordedExchange{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: scrubHeader(req.Header),
+			Body:   string(scrubBody(reqBody)),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header),
+			Body:       string(scrubBody(respBody)),
+		},
+	}
+	if err := writeRecordedExchange(dir, exchange); err != nil {
+		logger.Noticef("cannot record store exchange: %v", err)
+	}
+	return nil
+}
+
+func writeRecordedExchange(dir string, exchange *RecordedExchange) error {
+	data, err := json.MarshalIndent(exchange, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	recordMu.Lock()
+	defer recordMu.Unlock()
+	for {
+		recordNext++
+		name := filepath.Join(dir, fmt.Sprintf("%06d-%s.json", recordNext, strings.ToLower(exchange.Request.Method)))
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			// left over from a previous run
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+}
+
+func scrubHeader(header http.Header) http.Header {
+	scrubbedHeader := make(http.Header, len(header))
+	for k, vs := range header {
+		scrubbedVs := make([]string, len(vs))
+		for i, v := range vs {
+			if strings.HasPrefix(v, "Macaroon ") {
+				v = macaroonAttrRegexp.ReplaceAllString(v, `="`+scrubbed+`"`)
+			}
+			scrubbedVs[i] = v
+		}
+		scrubbedHeader[k] = scrubbedVs
+	}
+	return scrubbedHeader
+}
+
+// scrubBody scrubs macaroons and credentials from JSON bodies, other
+// bodies are returned as is.
+func scrubBody(body []byte) []byte {
+	var v interface{}
+	if len(body) == 0 || json.Unmarshal(body, &v) != nil {
+		return body
+	}
+	if !scrubValue(v) {
+		return body
+	}
+	scrubbedBody, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return scrubbedBody
+}
+
+func scrubValue(v interface{}) (changed bool) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, elem := range x {
+			lk := strings.ToLower(k)
+			if strings.Contains(lk, "macaroon") || lk == "password" || lk == "otp" {
+				x[k] = scrubbed
+				changed = true
+				continue
+			}
+			if scrubValue(elem) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, elem := range x {
+			if scrubValue(elem) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,119 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type recordSuite struct {
+	baseStoreSuite
+}
+
+var _ = Suite(&recordSuite{})
+
+func (s *recordSuite) TestRecord(c *C) {
+	recordDir := filepath.Join(c.MkDir(), "record")
+	os.Setenv("SNAPD_STORE_RECORD", recordDir)
+	defer os.Unsetenv("SNAPD_STORE_RECORD")
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", sectionsPath)
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.WriteHeader(200)
+		io.WriteString(w, strings.Replace(MockSectionsJSON, `"_links"`, `"discharge_macaroon": "secret", "_links"`, 1))
+	}))
+	defer mockServer.Close()
+
+	serverURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: serverURL,
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device, storeID: "my-brand-store"}
+	sto := store.New(&cfg, dauthCtx)
+
+	// the response is still seen by the store
+	sections, err := sto.Sections(s.ctx, s.user)
+	c.Assert(err, IsNil)
+	c.Check(sections, DeepEquals, []string{"featured", "database"})
+
+	exchanges, err := store.ReadRecordedExchanges(recordDir)
+	c.Assert(err, IsNil)
+	c.Assert(exchanges, HasLen, 1)
+	c.Check(filepath.Join(recordDir, "000001-get.json"), testutil.FilePresent)
+
+	exchange := exchanges[0]
+	c.Check(exchange.Request.Method, Equals, "GET")
+	c.Check(exchange.Request.URL, Equals, mockServer.URL+sectionsPath)
+	c.Check(exchange.Request.Header.Get("X-Ubuntu-Store"), Equals, "my-brand-store")
+	c.Check(exchange.Request.Header.Get("Authorization"), Matches, `Macaroon root="SCRUBBED", discharge="SCRUBBED"`)
+	c.Check(exchange.Request.Header.Get("X-Device-Authorization"), Equals, `Macaroon root="SCRUBBED"`)
+	c.Check(exchange.Response.StatusCode, Equals, 200)
+	c.Check(exchange.Response.Header.Get("Content-Type"), Equals, "application/hal+json")
+
+	var body map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(exchange.Response.Body), &body), IsNil)
+	c.Check(body["discharge_macaroon"], Equals, "SCRUBBED")
+	c.Check(body["_embedded"], NotNil)
+
+	// no secrets anywhere
+	data, err := ioutil.ReadFile(filepath.Join(recordDir, "000001-get.json"))
+	c.Assert(err, IsNil)
+	c.Check(strings.Contains(string(data), s.user.StoreMacaroon), Equals, false)
+	c.Check(strings.Contains(string(data), s.device.SessionMacaroon), Equals, false)
+	c.Check(strings.Contains(string(data), "secret"), Equals, false)
+}
+
+func (s *recordSuite) TestRecordSkipsDownloads(c *C) {
+	recordDir := filepath.Join(c.MkDir(), "record")
+	os.Setenv("SNAPD_STORE_RECORD", recordDir)
+	defer os.Unsetenv("SNAPD_STORE_RECORD")
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// not application/octet-stream
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "snap-data")
+	}))
+	defer mockServer.Close()
+
+	sto := store.New(&store.Config{}, nil)
+	var buf SillyBuffer
+	err := store.Download(context.TODO(), "foo", "", mockServer.URL, nil, sto, &buf, 0, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(buf.String(), Equals, "snap-data")
+
+	exchanges, err := store.ReadRecordedExchanges(recordDir)
+	c.Assert(err, IsNil)
+	c.Check(exchanges, HasLen, 0)
+}
@@ -20,6 +20,11 @@
 package store
 
 import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/release"
 	"github.com/snapcore/snapd/snap"
 )
 
@@ -51,3 +56,93 @@ func infoFromStoreSearchResult(si *storeSearchResult) (*snap.Info, error) {
 	info.Channel = si.Revision.Channel
 	return info, nil
 }
+
+// Sort orders supported by Find.
+const (
+	// SortByName sorts results alphabetically by snap name.
+	SortByName = "name"
+	// SortByPopularity keeps the ranking of the store, which orders
+	// results by popularity.
+	SortByPopularity = "popularity"
+	// SortByLastUpdated sorts results by the time of their latest
+	// release, most recent first.
+	SortByLastUpdated = "last-updated"
+)
+
+func validateSearchFilters(search *Search) error {
+	switch search.Sort {
+	case "", SortByName, SortByPopularity, SortByLastUpdated:
+		// ok
+	default:
+		return ErrInvalidSort
+	}
+	switch snap.ConfinementType(search.Confinement) {
+	case "", snap.StrictConfinement, snap.ClassicConfinement, snap.DevModeConfinement:
+		// ok
+	default:
+		return ErrInvalidConfinement
+	}
+	return nil
+}
+
+// searchConfinement returns the value of the confinement query parameter
+// for the given search.
+func searchConfinement(search *Search) string {
+	if search.Confinement != "" {
+		return search.Confinement
+	}
+	if release.OnClassic {
+		return "strict,classic"
+	}
+	return "strict"
+}
+
+// sortSearchResults sorts the search results in place by the given
+// sort order.
+func sortSearchResults(results []*storeSearchResult, sortBy string) error {
+	switch sortBy {
+	case SortByName:
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Name < results[j].Name
+		})
+	case SortByLastUpdated:
+		createdAt := make(map[*storeSearchResult]time.Time, len(results))
+		for _, res := range results {
+			createdAtStr := res.Revision.CreatedAt
+			if createdAtStr == "" {
+				createdAtStr = res.Snap.CreatedAt
+			}
+			if createdAtStr == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, createdAtStr)
+			if err != nil {
+				return fmt.Errorf("cannot parse release time of snap %q: %v", res.Name, err)
+			}
+			createdAt[res] = t
+		}
+		sort.SliceStable(results, func(i, j int) bool {
+			return createdAt[results[i]].After(createdAt[results[j]])
+		})
+	}
+	return nil
+}
+
+// matchesSearchFilters returns whether the given snap satisfies the
+// filters of the search that are not handled by the store itself. The
+// publisher is checked as well as search v1 does not support it.
+func matchesSearchFilters(info *snap.Info, search *Search) bool {
+	if search.Publisher != "" && search.Publisher != info.Publisher.Username && search.Publisher != info.Publisher.ID {
+		return false
+	}
+	if search.VerifiedPublisher && info.Publisher.Validation != "verified" {
+		return false
+	}
+	if search.License != "" && search.License != info.License {
+		return false
+	}
+	if search.Base != "" && search.Base != info.Base {
+		return false
+	}
+	return true
+}
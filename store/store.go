@@ -31,6 +31,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -156,6 +157,10 @@ type Store struct {
 	proxyConnectHeader http.Header
 
 	userAgent string
+
+	// recordDir is where to record store exchanges to, if set via
+	// SNAPD_STORE_RECORD
+	recordDir string
 }
 
 var ErrTooManyRequests = errors.New("too many requests")
@@ -383,6 +388,7 @@ func New(cfg *Config, dauthCtx DeviceAndAuthContext) *Store {
 		proxy:              cfg.Proxy,
 		proxyConnectHeader: proxyConnectHeader,
 		userAgent:          userAgent,
+		recordDir:          os.Getenv("SNAPD_STORE_RECORD"),
 	}
 	store.client = store.newHTTPClient(&httputil.ClientOptions{
 		Timeout:    10 * time.Second,
@@ -428,9 +434,40 @@ func (s *Store) newHTTPClient(opts *httputil.ClientOptions) *http.Client {
 	opts.ExtraSSLCerts = &httputil.ExtraSSLCertsFromDir{
 		Dir: dirs.SnapdStoreSSLCertsDir,
 	}
+	if s.dauthCtx != nil {
+		opts.ExtraTLSParams = storeTLSParams{s}
+	}
 	return httputil.NewHTTPClient(opts)
 }
 
+// storeTLSParams provides the TLS parameters of the device and auth
+// context, with the pinned public keys applying only to the store and
+// assertions service hosts and not to any other host like the CDNs.
+// It is used during TLS handshakes and must not take the state lock,
+// the hosts of a proxy store are resolved by the context.
+type storeTLSParams struct {
+	s *Store
+}
+
+func (p storeTLSParams) TLSParams() (*httputil.TLSParams, error) {
+	params, err := p.s.dauthCtx.TLSParams()
+	if err != nil || params == nil {
+		return params, err
+	}
+	if len(params.PinnedHosts) != 0 {
+		// a proxy store is used
+		return params, nil
+	}
+	pinned := *params
+	for _, u := range []*url.URL{p.s.cfg.StoreBaseURL, p.s.cfg.AssertionsBaseURL} {
+		if u == nil {
+			continue
+		}
+		pinned.PinnedHosts = append(pinned.PinnedHosts, u.Hostname())
+	}
+	return &pinned, nil
+}
+
 func (s *Store) defaultSnapQuery() url.Values {
 	q := url.Values{}
 	if len(s.detailFields) != 0 {
@@ -711,6 +748,10 @@ type requestOptions struct {
 	//  - deviceAuthCustomStoreOnly: should be provided only in case
 	//    of a custom store
 	DeviceAuthNeed deviceAuthNeed
+
+	// NoRecord disables recording the request and its response in
+	// SNAPD_STORE_RECORD mode, it is set for snap downloads.
+	NoRecord bool
 }
 
 func (r *requestOptions) addHeader(k, v string) {
@@ -840,6 +881,11 @@ func (s *Store) doRequest(ctx context.Context, client *http.Client, reqOptions *
 		if err != nil {
 			return nil, err
 		}
+		if !reqOptions.NoRecord {
+			if err := s.maybeRecord(req, reqOptions.Data, resp); err != nil {
+				return nil, err
+			}
+		}
 
 		wwwAuth := resp.Header.Get("WWW-Authenticate")
 		if resp.StatusCode == 401 && authRefreshes < 4 {
@@ -1131,6 +1177,42 @@ type Search struct {
 	Category string
 	Private  bool
 	Scope    string
+
+	// Publisher restricts the results to snaps published by the
+	// account with the given username or account ID.
+	Publisher string
+	// Confinement restricts the results to snaps with the given
+	// confinement type.
+	Confinement string
+	// Architecture restricts the results to snaps available for the
+	// given architecture instead of the one of the system.
+	Architecture string
+
+	// VerifiedPublisher, License and Base are not supported by the
+	// store, they are applied to the results it returns, which are
+	// limited in number: matching snaps that the store did not return
+	// are missing, see LocallyFiltered. The same goes for Publisher
+	// with stores only supporting search v1.
+
+	// VerifiedPublisher restricts the results to snaps published by
+	// verified accounts.
+	VerifiedPublisher bool
+	// License restricts the results to snaps with the given SPDX
+	// license expression.
+	License string
+	// Base restricts the results to snaps using the given base.
+	Base string
+
+	// Sort is the order in which results are returned, one of
+	// SortByName, SortByPopularity or SortByLastUpdated; if empty the
+	// order of the store is kept.
+	Sort string
+}
+
+// LocallyFiltered returns whether the search uses filters that are not
+// supported by the store, in which case the results can be partial.
+func (search *Search) LocallyFiltered() bool {
+	return search.VerifiedPublisher || search.License != "" || search.Base != ""
 }
 
 // Find finds  (installable) snaps from the store, matching the
@@ -1152,9 +1234,23 @@ func (s *Store) Find(ctx context.Context, search *Search, user *auth.UserState)
 		return nil, ErrBadQuery
 	}
 
+	if err := validateSearchFilters(search); err != nil {
+		return nil, err
+	}
+
+	fields := s.findFields
+	if search.Sort == SortByLastUpdated && !strutil.ListContains(fields, "created-at") {
+		fields = append(fields[:len(fields):len(fields)], "created-at")
+	}
+
+	architecture := s.architecture
+	if search.Architecture != "" {
+		architecture = search.Architecture
+	}
+
 	q := url.Values{}
-	q.Set("fields", strings.Join(s.findFields, ","))
-	q.Set("architecture", s.architecture)
+	q.Set("fields", strings.Join(fields, ","))
+	q.Set("architecture", architecture)
 
 	if search.Private {
 		q.Set("private", "true")
@@ -1174,6 +1270,9 @@ func (s *Store) Find(ctx context.Context, search *Search, user *auth.UserState)
 	if search.Category != "" {
 		q.Set("category", search.Category)
 	}
+	if search.Publisher != "" {
+		q.Set("publisher", search.Publisher)
+	}
 
 	// with search v2 all risks are searched by default (same as scope=wide
 	// with v1) so we need to restrict channel if scope is not passed.
@@ -1183,11 +1282,7 @@ func (s *Store) Find(ctx context.Context, search *Search, user *auth.UserState)
 		return nil, ErrInvalidScope
 	}
 
-	if release.OnClassic {
-		q.Set("confinement", "strict,classic")
-	} else {
-		q.Set("confinement", "strict")
-	}
+	q.Set("confinement", searchConfinement(search))
 
 	u := s.endpointURL(findEndpPath, q)
 	reqOptions := &requestOptions{
@@ -1242,13 +1337,20 @@ func (s *Store) Find(ctx context.Context, search *Search, user *auth.UserState)
 		return nil, fmt.Errorf("received an unexpected content type (%q) when trying to search via %q", ct, resp.Request.URL)
 	}
 
-	snaps := make([]*snap.Info, len(searchData.Results))
-	for i, res := range searchData.Results {
+	if err := sortSearchResults(searchData.Results, search.Sort); err != nil {
+		return nil, err
+	}
+
+	snaps := make([]*snap.Info, 0, len(searchData.Results))
+	for _, res := range searchData.Results {
 		info, err := infoFromStoreSearchResult(res)
 		if err != nil {
 			return nil, err
 		}
-		snaps[i] = info
+		if !matchesSearchFilters(info, search) {
+			continue
+		}
+		snaps = append(snaps, info)
 	}
 
 	err = s.decorateOrders(snaps, user)
@@ -1289,11 +1391,7 @@ func (s *Store) findV1(ctx context.Context, search *Search, user *auth.UserState
 		q.Set("scope", search.Scope)
 	}
 
-	if release.OnClassic {
-		q.Set("confinement", "strict,classic")
-	} else {
-		q.Set("confinement", "strict")
-	}
+	q.Set("confinement", searchConfinement(search))
 
 	u := s.endpointURL(searchEndpPath, q)
 	reqOptions := &requestOptions{
@@ -1301,6 +1399,10 @@ func (s *Store) findV1(ctx context.Context, search *Search, user *auth.UserState
 		URL:    u,
 		Accept: halJsonContentType,
 	}
+	if search.Architecture != "" {
+		// search v1 takes the architecture from the headers only
+		reqOptions.addHeader(hdrSnapDeviceArchitecture[apiV1Endps], search.Architecture)
+	}
 
 	var searchData searchResults
 	resp, err := s.retryRequestDecodeJSON(ctx, reqOptions, user, &searchData, nil)
@@ -1316,9 +1418,25 @@ func (s *Store) findV1(ctx context.Context, search *Search, user *auth.UserState
 		return nil, fmt.Errorf("received an unexpected content type (%q) when trying to search via %q", ct, resp.Request.URL)
 	}
 
-	snaps := make([]*snap.Info, len(searchData.Payload.Packages))
-	for i, pkg := range searchData.Payload.Packages {
-		snaps[i] = infoFromRemote(pkg)
+	packages := searchData.Payload.Packages
+	switch search.Sort {
+	case SortByName:
+		sort.SliceStable(packages, func(i, j int) bool {
+			return packages[i].Name < packages[j].Name
+		})
+	case SortByLastUpdated:
+		sort.SliceStable(packages, func(i, j int) bool {
+			return packages[i].LastUpdated > packages[j].LastUpdated
+		})
+	}
+
+	snaps := make([]*snap.Info, 0, len(packages))
+	for _, pkg := range packages {
+		info := infoFromRemote(pkg)
+		if !matchesSearchFilters(info, search) {
+			continue
+		}
+		snaps = append(snaps, info)
 	}
 
 	err = s.decorateOrders(snaps, user)
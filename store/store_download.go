@@ -266,6 +266,8 @@ func downloadReqOpts(storeURL *url.URL, cdnHeader string, opts *DownloadOptions)
 		ExtraHeaders: map[string]string{},
 		// FIXME: use the new headers? with
 		// APILevel: apiV2Endps,
+		// snaps are not recorded, whatever their content type
+		NoRecord: true,
 	}
 	if cdnHeader != "" {
 		reqOptions.ExtraHeaders["Snap-CDN"] = cdnHeader
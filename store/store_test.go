@@ -22,6 +22,7 @@ package store_test
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -47,6 +48,7 @@ import (
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/httputil"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/release"
@@ -237,12 +239,15 @@ type testDauthContext struct {
 
 	user *auth.UserState
 
-	proxyStoreID  string
-	proxyStoreURL *url.URL
+	proxyStoreID            string
+	proxyStoreURL           *url.URL
+	proxyStoreParamsWitness func()
 
 	storeID string
 
 	cloudInfo *auth.CloudInfo
+
+	tlsParams *httputil.TLSParams
 }
 
 func (dac *testDauthContext) Device() (*auth.DeviceState, error) {
@@ -306,6 +311,9 @@ func (dac *testDauthContext) DeviceSessionRequestParams(nonce string) (*store.De
 }
 
 func (dac *testDauthContext) ProxyStoreParams(defaultURL *url.URL) (string, *url.URL, error) {
+	if dac.proxyStoreParamsWitness != nil {
+		dac.proxyStoreParamsWitness()
+	}
 	if dac.proxyStoreID != "" {
 		return dac.proxyStoreID, dac.proxyStoreURL, nil
 	}
@@ -316,6 +324,10 @@ func (dac *testDauthContext) CloudInfo() (*auth.CloudInfo, error) {
 	return dac.cloudInfo, nil
 }
 
+func (dac *testDauthContext) TLSParams() (*httputil.TLSParams, error) {
+	return dac.tlsParams, nil
+}
+
 func makeTestMacaroon() (*macaroon.Macaroon, error) {
 	m, err := macaroon.New([]byte("secret"), "some-id", "location")
 	if err != nil {
@@ -1779,6 +1791,75 @@ func (s *storeTestSuite) TestProxyStoreFromAuthContextURLFallback(c *C) {
 	c.Check(result.InstanceName(), Equals, "hello-world")
 }
 
+func (s *storeTestSuite) TestTLSParamsPinnedHosts(c *C) {
+	storeURL, err := url.Parse("https://api.example.com")
+	c.Assert(err, IsNil)
+	assertionsURL, err := url.Parse("https://assertions.example.com")
+	c.Assert(err, IsNil)
+	proxyStoreURL, err := url.Parse("https://proxy.example.com:8443")
+	c.Assert(err, IsNil)
+
+	cert := &tls.Certificate{}
+	dauthCtx := &testDauthContext{
+		c:      c,
+		device: s.device,
+		tlsParams: &httputil.TLSParams{
+			PinnedPublicKeys:  [][]byte{[]byte("pin")},
+			ClientCertificate: cert,
+		},
+	}
+	cfg := store.DefaultConfig()
+	cfg.StoreBaseURL = storeURL
+	sto := store.New(cfg, dauthCtx)
+
+	params, err := sto.TLSParams()
+	c.Assert(err, IsNil)
+	c.Check(params.PinnedPublicKeys, DeepEquals, [][]byte{[]byte("pin")})
+	c.Check(params.PinnedHosts, DeepEquals, []string{"api.example.com"})
+	c.Check(params.ClientCertificate, Equals, cert)
+
+	cfg.AssertionsBaseURL = assertionsURL
+	sto = store.New(cfg, dauthCtx)
+	params, err = sto.TLSParams()
+	c.Assert(err, IsNil)
+	c.Check(params.PinnedHosts, DeepEquals, []string{"api.example.com", "assertions.example.com"})
+
+	// the parameters of the context are left alone
+	c.Check(dauthCtx.tlsParams.PinnedHosts, IsNil)
+
+	// the context resolves the host of a proxy store, used for both
+	dauthCtx.proxyStoreID = "foo"
+	dauthCtx.proxyStoreURL = proxyStoreURL
+	dauthCtx.tlsParams.PinnedHosts = []string{"proxy.example.com"}
+	params, err = sto.TLSParams()
+	c.Assert(err, IsNil)
+	c.Check(params.PinnedHosts, DeepEquals, []string{"proxy.example.com"})
+}
+
+func (s *storeTestSuite) TestTLSParamsDoesNotAccessState(c *C) {
+	storeURL, err := url.Parse("https://api.example.com")
+	c.Assert(err, IsNil)
+
+	dauthCtx := &testDauthContext{
+		c:      c,
+		device: s.device,
+		tlsParams: &httputil.TLSParams{
+			PinnedPublicKeys: [][]byte{[]byte("pin")},
+		},
+		// ProxyStoreParams takes the state lock
+		proxyStoreParamsWitness: func() {
+			c.Fatalf("unexpected call to ProxyStoreParams")
+		},
+	}
+	cfg := store.DefaultConfig()
+	cfg.StoreBaseURL = storeURL
+	sto := store.New(cfg, dauthCtx)
+
+	params, err := sto.TLSParams()
+	c.Assert(err, IsNil)
+	c.Check(params.PinnedHosts, DeepEquals, []string{"api.example.com"})
+}
+
 func (s *storeTestSuite) TestInfoOopses(c *C) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assertRequest(c, r, "GET", infoPathPattern)
@@ -2596,6 +2677,153 @@ func (s *storeTestSuite) TestFindInvalidScope(c *C) {
 	c.Check(err, Equals, store.ErrInvalidScope)
 }
 
+func (s *storeTestSuite) TestFindInvalidSortAndConfinement(c *C) {
+	// filters are checked early in Find(), so the test covers both
+	// search v1 & v2
+	sto := store.New(&store.Config{StoreBaseURL: new(url.URL)}, nil)
+	_, err := sto.Find(s.ctx, &store.Search{Query: "foo", Sort: "size"}, nil)
+	c.Check(err, Equals, store.ErrInvalidSort)
+	_, err = sto.Find(s.ctx, &store.Search{Query: "foo", Confinement: "loose"}, nil)
+	c.Check(err, Equals, store.ErrInvalidConfinement)
+}
+
+const mockSearchFiltersJSONv2 = `{
+  "results": [
+    {
+      "name": "zed",
+      "snap-id": "zed-id",
+      "revision": {"revision": 1, "created-at": "2020-01-01T10:00:00Z", "channel": "stable"},
+      "snap": {"license": "MIT", "base": "core18", "publisher": {"id": "acme-id", "username": "acme", "display-name": "Acme", "validation": "verified"}}
+    },
+    {
+      "name": "alpha",
+      "snap-id": "alpha-id",
+      "revision": {"revision": 2, "created-at": "2020-03-01T10:00:00Z", "channel": "stable"},
+      "snap": {"license": "MIT", "base": "core20", "publisher": {"id": "acme-id", "username": "acme", "display-name": "Acme", "validation": "verified"}}
+    },
+    {
+      "name": "beta",
+      "snap-id": "beta-id",
+      "revision": {"revision": 3, "created-at": "2020-02-01T10:00:00Z", "channel": "stable"},
+      "snap": {"license": "GPL-3.0", "base": "core18", "publisher": {"id": "other-id", "username": "other", "display-name": "Other", "validation": "unproven"}}
+    }
+  ]
+}`
+
+func (s *storeTestSuite) TestFindV2FiltersAndSort(c *C) {
+	restore := release.MockOnClassic(false)
+	defer restore()
+
+	var query url.Values
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", findPath)
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		io.WriteString(w, mockSearchFiltersJSONv2)
+	}))
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+		FindFields:   []string{"abc", "def"},
+	}
+	sto := store.New(&cfg, nil)
+
+	names := func(snaps []*snap.Info) []string {
+		var names []string
+		for _, sn := range snaps {
+			names = append(names, sn.InstanceName())
+		}
+		return names
+	}
+
+	for _, t := range []struct {
+		search store.Search
+		names  []string
+	}{
+		{store.Search{Query: "foo"}, []string{"zed", "alpha", "beta"}},
+		{store.Search{Query: "foo", Sort: store.SortByPopularity}, []string{"zed", "alpha", "beta"}},
+		{store.Search{Query: "foo", Sort: store.SortByName}, []string{"alpha", "beta", "zed"}},
+		{store.Search{Query: "foo", Sort: store.SortByLastUpdated}, []string{"alpha", "beta", "zed"}},
+		{store.Search{Query: "foo", Publisher: "acme"}, []string{"zed", "alpha"}},
+		{store.Search{Query: "foo", Publisher: "other-id"}, []string{"beta"}},
+		{store.Search{Query: "foo", VerifiedPublisher: true, Sort: store.SortByName}, []string{"alpha", "zed"}},
+		{store.Search{Query: "foo", License: "GPL-3.0"}, []string{"beta"}},
+		{store.Search{Query: "foo", Base: "core18", Sort: store.SortByName}, []string{"beta", "zed"}},
+		{store.Search{Query: "foo", Base: "core22"}, nil},
+	} {
+		snaps, err := sto.Find(s.ctx, &t.search, nil)
+		c.Assert(err, IsNil)
+		c.Check(names(snaps), DeepEquals, t.names, Commentf("%+v", t.search))
+		c.Check(query.Get("architecture"), Equals, arch.DpkgArchitecture())
+		c.Check(query.Get("confinement"), Equals, "strict")
+		if t.search.Sort == store.SortByLastUpdated {
+			c.Check(query.Get("fields"), Equals, "abc,def,created-at")
+		} else {
+			c.Check(query.Get("fields"), Equals, "abc,def")
+		}
+	}
+
+	_, err := sto.Find(s.ctx, &store.Search{Query: "foo", Architecture: "arm64", Confinement: "classic"}, nil)
+	c.Assert(err, IsNil)
+	c.Check(query.Get("architecture"), Equals, "arm64")
+	c.Check(query.Get("confinement"), Equals, "classic")
+	c.Check(query.Get("publisher"), Equals, "")
+
+	// the publisher filter is passed to the store
+	_, err = sto.Find(s.ctx, &store.Search{Query: "foo", Publisher: "acme"}, nil)
+	c.Assert(err, IsNil)
+	c.Check(query.Get("publisher"), Equals, "acme")
+}
+
+func (s *storeTestSuite) TestFindV1Architecture(c *C) {
+	var archHeader string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, findPath) {
+			forceSearchV1(w)
+			return
+		}
+		assertRequest(c, r, "GET", searchPath)
+		archHeader = r.Header.Get("X-Ubuntu-Architecture")
+		w.Header().Set("Content-Type", "application/hal+json")
+		io.WriteString(w, "{}")
+	}))
+	c.Assert(mockServer, NotNil)
+	defer mockServer.Close()
+
+	mockServerURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: mockServerURL,
+	}
+	sto := store.New(&cfg, nil)
+
+	_, err := sto.Find(s.ctx, &store.Search{Query: "foo"}, nil)
+	c.Assert(err, IsNil)
+	c.Check(archHeader, Equals, arch.DpkgArchitecture())
+
+	_, err = sto.Find(s.ctx, &store.Search{Query: "foo", Architecture: "arm64"}, nil)
+	c.Assert(err, IsNil)
+	c.Check(archHeader, Equals, "arm64")
+}
+
+func (s *storeTestSuite) TestSearchLocallyFiltered(c *C) {
+	for _, t := range []struct {
+		search   store.Search
+		filtered bool
+	}{
+		{store.Search{Query: "foo"}, false},
+		{store.Search{Query: "foo", Publisher: "acme", Confinement: "strict", Architecture: "arm64", Sort: store.SortByName}, false},
+		{store.Search{Query: "foo", VerifiedPublisher: true}, true},
+		{store.Search{Query: "foo", License: "MIT"}, true},
+		{store.Search{Query: "foo", Base: "core20"}, true},
+	} {
+		c.Check(t.search.LocallyFiltered(), Equals, t.filtered, Commentf("%+v", t.search))
+	}
+}
+
 func (s *storeTestSuite) testFindFails(c *C, apiV1 bool) {
 	var v1Fallback, v2Hit bool
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,131 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package storetest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"github.com/snapcore/snapd/store"
+)
+
+// ReplayServer is an HTTP server serving back the store exchanges
+// recorded with SNAPD_STORE_RECORD.
+//
+// Requests are matched by method, path and query. Recorded responses
+// for the same request are served in the order they were recorded,
+// the last one being served again once all were used.
+type ReplayServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	exchanges map[string][]*store.RecordedExchange
+	served    map[string]int
+}
+
+// NewReplayServer starts a ReplayServer serving the exchanges recorded
+// in the given directory. It should be closed after use.
+func NewReplayServer(dir string) (*ReplayServer, error) {
+	exchanges, err := store.ReadRecordedExchanges(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(exchanges) == 0 {
+		return nil, fmt.Errorf("no recorded store exchanges in %s", dir)
+	}
+
+	rs := &ReplayServer{
+		exchanges: make(map[string][]*store.RecordedExchange),
+		served:    make(map[string]int),
+	}
+	for _, exchange := range exchanges {
+		u, err := url.Parse(exchange.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse recorded store request URL: %v", err)
+		}
+		key := replayKey(exchange.Request.Method, u)
+		rs.exchanges[key] = append(rs.exchanges[key], exchange)
+	}
+	rs.Server = httptest.NewServer(http.HandlerFunc(rs.serve))
+	return rs, nil
+}
+
+func replayKey(method string, u *url.URL) string {
+	return method + " " + u.EscapedPath() + "?" + u.Query().Encode()
+}
+
+func (rs *ReplayServer) serve(w http.ResponseWriter, r *http.Request) {
+	key := replayKey(r.Method, r.URL)
+
+	rs.mu.Lock()
+	exchanges := rs.exchanges[key]
+	if len(exchanges) == 0 {
+		rs.mu.Unlock()
+		http.Error(w, fmt.Sprintf("no recorded store exchange for %s", key), 501)
+		return
+	}
+	i := rs.served[key]
+	if i < len(exchanges)-1 {
+		rs.served[key]++
+	}
+	exchange := exchanges[i]
+	rs.mu.Unlock()
+
+	for k, vs := range exchange.Response.Header {
+		switch k {
+		case "Content-Length", "Transfer-Encoding", "Content-Encoding":
+			// the body is replayed as recorded, after decoding
+			continue
+		}
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(exchange.Response.StatusCode)
+	w.Write([]byte(exchange.Response.Body))
+}
+
+// StoreConfig returns a store configuration with the store and
+// assertions endpoints pointing to the replay server.
+func (rs *ReplayServer) StoreConfig() *store.Config {
+	u, err := url.Parse(rs.URL + "/")
+	if err != nil {
+		panic(err)
+	}
+	cfg := store.DefaultConfig()
+	cfg.StoreBaseURL = u
+	cfg.AssertionsBaseURL = u
+	return cfg
+}
+
+// NewReplayStore returns a store.Store, usable as a
+// snapstate.StoreService, talking to a ReplayServer serving the
+// exchanges recorded in the given directory. The server should be
+// closed after use.
+func NewReplayStore(dir string, dauthCtx store.DeviceAndAuthContext) (*store.Store, *ReplayServer, error) {
+	rs, err := NewReplayServer(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store.New(rs.StoreConfig(), dauthCtx), rs, nil
+}
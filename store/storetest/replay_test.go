@@ -0,0 +1,107 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package storetest_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/store/storetest"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type replaySuite struct{}
+
+var _ = Suite(&replaySuite{})
+
+func writeExchange(c *C, dir, name string, exchange *store.RecordedExchange) {
+	data, err := json.Marshal(exchange)
+	c.Assert(err, IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, name), data, 0644), IsNil)
+}
+
+func sectionsExchange(section string) *store.RecordedExchange {
+	return &store.RecordedExchange{
+		Request: store.RecordedRequest{
+			Method: "GET",
+			URL:    "https://api.snapcraft.io/api/v1/snaps/sections",
+		},
+		Response: store.RecordedResponse{
+			StatusCode: 200,
+			Header: http.Header{
+				"Content-Type":   {"application/hal+json"},
+				"Content-Length": {"1000"},
+			},
+			Body: `{"_embedded": {"clickindex:sections": [{"name": "` + section + `"}]}}`,
+		},
+	}
+}
+
+func (s *replaySuite) TestReplayStore(c *C) {
+	dir := c.MkDir()
+	writeExchange(c, dir, "000001-get.json", sectionsExchange("featured"))
+	writeExchange(c, dir, "000002-get.json", sectionsExchange("database"))
+
+	sto, rs, err := storetest.NewReplayStore(dir, nil)
+	c.Assert(err, IsNil)
+	defer rs.Close()
+
+	// responses are served in order, the last one being repeated
+	for _, expected := range []string{"featured", "database", "database"} {
+		sections, err := sto.Sections(context.TODO(), nil)
+		c.Assert(err, IsNil)
+		c.Check(sections, DeepEquals, []string{expected})
+	}
+}
+
+func (s *replaySuite) TestReplayServerUnknownRequest(c *C) {
+	dir := c.MkDir()
+	writeExchange(c, dir, "000001-get.json", sectionsExchange("featured"))
+
+	rs, err := storetest.NewReplayServer(dir)
+	c.Assert(err, IsNil)
+	defer rs.Close()
+
+	resp, err := http.Get(rs.URL + "/api/v1/snaps/sections?foo=bar")
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	c.Check(resp.StatusCode, Equals, 501)
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	c.Check(string(body), Equals, "no recorded store exchange for GET /api/v1/snaps/sections?foo=bar\n")
+}
+
+func (s *replaySuite) TestNewReplayServerErrors(c *C) {
+	dir := c.MkDir()
+	_, err := storetest.NewReplayServer(dir)
+	c.Check(err, ErrorMatches, "no recorded store exchanges in .*")
+
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "000001-get.json"), []byte("{"), 0644), IsNil)
+	_, err = storetest.NewReplayServer(dir)
+	c.Check(err, ErrorMatches, "cannot decode recorded store exchange .*/000001-get.json: .*")
+}